@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/namycodes/yanga-services/shared-lib/middleware"
+	"github.com/namycodes/yanga-services/shared-lib/utils"
+)
+
+// gatewayAuth validates a request's bearer token once, here, and passes
+// the caller on to the services as signed identity headers. Identity
+// headers the client sent itself are always dropped. Protected routes are
+// rejected without a valid token; public ones are forwarded anonymously.
+func gatewayAuth(jwtSecret, identitySecret string, protected bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			middleware.StripIdentityHeaders(r.Header)
+
+			userID, role, problem := authenticate(r, jwtSecret)
+			if problem != "" {
+				if protected {
+					utils.ErrorResponse(w, http.StatusUnauthorized, problem)
+					return
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			middleware.SignIdentity(r.Header, identitySecret, userID, role, time.Now())
+			ctx := context.WithValue(r.Context(), "user_id", userID)
+			ctx = context.WithValue(ctx, "role", role)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// authenticate returns the user and role from the request's bearer token,
+// or why it has none.
+func authenticate(r *http.Request, jwtSecret string) (string, string, string) {
+	authHeader := r.Header.Get("Authorization")
+	if authHeader == "" {
+		return "", "", "Authorization header required"
+	}
+
+	token, ok := middleware.BearerToken(authHeader)
+	if !ok {
+		return "", "", "Invalid authorization header format"
+	}
+
+	claims, err := utils.ValidateJWT(token, jwtSecret)
+	if err != nil {
+		return "", "", "Invalid or expired token"
+	}
+
+	userID, _ := claims["user_id"].(string)
+	role, _ := claims["role"].(string)
+	return userID, role, ""
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/gorilla/mux"
 	"github.com/namycodes/yanga-services/shared-lib/config"
 	"github.com/namycodes/yanga-services/shared-lib/middleware"
+	"github.com/namycodes/yanga-services/shared-lib/ratelimit"
 	httpSwagger "github.com/swaggo/http-swagger"
 )
 
@@ -41,6 +42,7 @@ func main() {
 	cfg := config.LoadConfig()
 
 	router := mux.NewRouter()
+	router.Use(middleware.RequestIDMiddleware)
 	router.Use(middleware.LoggingMiddleware)
 	router.Use(middleware.CORSMiddleware)
 
@@ -49,12 +51,18 @@ func main() {
 	tripServiceURL := getServiceURL("TRIP_SERVICE_URL", "http://localhost:8082")
 	driverServiceURL := getServiceURL("DRIVER_SERVICE_URL", "http://localhost:8083")
 	ratingServiceURL := getServiceURL("RATING_SERVICE_URL", "http://localhost:8084")
+	paymentServiceURL := getServiceURL("PAYMENT_SERVICE_URL", "http://localhost:8085")
+	notificationServiceURL := getServiceURL("NOTIFICATION_SERVICE_URL", "http://localhost:8086")
+	supportServiceURL := getServiceURL("SUPPORT_SERVICE_URL", "http://localhost:8087")
 
 	// Create reverse proxies
 	authProxy := createReverseProxy(authServiceURL)
 	tripProxy := createReverseProxy(tripServiceURL)
 	driverProxy := createReverseProxy(driverServiceURL)
 	ratingProxy := createReverseProxy(ratingServiceURL)
+	paymentProxy := createReverseProxy(paymentServiceURL)
+	notificationProxy := createReverseProxy(notificationServiceURL)
+	supportProxy := createReverseProxy(supportServiceURL)
 
 	// Health check
 	router.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
@@ -62,12 +70,33 @@ func main() {
 		w.Write([]byte(`{"status":"healthy","service":"api-gateway"}`))
 	}).Methods("GET")
 
+	// Tokens are validated here once; services trust the signed identity
+	// headers. Public routes are reachable without a token. Requests are
+	// rate limited by IP before their token is checked, and by user after.
+	limiter := newRateLimiter(cfg, ratelimit.New(cfg))
+	requireToken := gatewayAuth(cfg.JWTSecret, cfg.GatewayIdentitySecret, true)
+	allowAnonymous := gatewayAuth(cfg.JWTSecret, cfg.GatewayIdentitySecret, false)
+	protect := func(h http.Handler) http.Handler { return limiter.Before(requireToken(limiter.After(h))) }
+	public := func(h http.Handler) http.Handler { return limiter.Before(allowAnonymous(limiter.After(h))) }
+
 	// Route to services
-	router.PathPrefix("/api/v1/auth").Handler(authProxy)
-	router.PathPrefix("/api/v1/trips").Handler(tripProxy)
-	router.PathPrefix("/api/v1/ride-requests").Handler(tripProxy)
-	router.PathPrefix("/api/v1/drivers").Handler(driverProxy)
-	router.PathPrefix("/api/v1/ratings").Handler(ratingProxy)
+	router.PathPrefix("/api/v1/auth").Handler(public(authProxy))
+	router.PathPrefix("/api/v1/trips").Handler(protect(tripProxy))
+	router.PathPrefix("/api/v1/ride-requests").Handler(protect(tripProxy))
+	router.PathPrefix("/api/v1/promotions").Handler(protect(tripProxy))
+	router.PathPrefix("/api/v1/cancellation-policies").Handler(protect(tripProxy))
+	router.PathPrefix("/api/v1/places").Handler(protect(tripProxy))
+	router.PathPrefix("/api/v1/geofences").Handler(protect(tripProxy))
+	router.PathPrefix("/api/v1/airport-queues").Handler(protect(tripProxy))
+	router.PathPrefix("/api/v1/cities").Handler(protect(tripProxy))
+	router.PathPrefix("/api/v1/safety").Handler(protect(tripProxy))
+	router.PathPrefix("/api/v1/shared-trips").Handler(public(tripProxy))
+	router.PathPrefix("/api/v1/drivers").Handler(protect(driverProxy))
+	router.PathPrefix("/api/v1/ratings/driver/").Handler(public(ratingProxy))
+	router.PathPrefix("/api/v1/ratings").Handler(protect(ratingProxy))
+	router.PathPrefix("/api/v1/wallet").Handler(protect(paymentProxy))
+	router.PathPrefix("/api/v1/notifications").Handler(protect(notificationProxy))
+	router.PathPrefix("/api/v1/support").Handler(protect(supportProxy))
 
 	// Swagger documentation - aggregate from all services
 	router.PathPrefix("/swagger/").Handler(httpSwagger.Handler(
@@ -103,13 +132,13 @@ func main() {
 	
 	<div class="service">
 		<h3>Trip Service (Port 8082)</h3>
-		<p>Trip creation, management, and ride requests</p>
+		<p>Trip creation, management, fare quotes, cancellations, promotions, referrals and places</p>
 		<a href="/swagger/">View Documentation</a>
 	</div>
 	
 	<div class="service">
 		<h3>Driver Service (Port 8083)</h3>
-		<p>Driver profile, status, location, and trip operations</p>
+		<p>Driver profile, status, location, trip operations and performance metrics</p>
 		<a href="/swagger/">View Documentation</a>
 	</div>
 	
@@ -119,6 +148,24 @@ func main() {
 		<a href="/swagger/">View Documentation</a>
 	</div>
 	
+	<div class="service">
+		<h3>Payment Service (Port 8085)</h3>
+		<p>Rider wallets, ledger postings, refunds and reconciliation</p>
+		<a href="/swagger/">View Documentation</a>
+	</div>
+	
+	<div class="service">
+		<h3>Notification Service (Port 8086)</h3>
+		<p>Push, SMS and in-app notifications, preferences and device registration</p>
+		<a href="/swagger/">View Documentation</a>
+	</div>
+	
+	<div class="service">
+		<h3>Support Service (Port 8087)</h3>
+		<p>Support tickets with attachments, SLAs and refund resolutions</p>
+		<a href="/swagger/">View Documentation</a>
+	</div>
+	
 	<hr>
 	<p><strong>Health Check:</strong> <a href="/health">Check Gateway Status</a></p>
 </body>
@@ -141,6 +188,9 @@ func main() {
 		log.Printf("Trip Service: %s", tripServiceURL)
 		log.Printf("Driver Service: %s", driverServiceURL)
 		log.Printf("Rating Service: %s", ratingServiceURL)
+		log.Printf("Payment Service: %s", paymentServiceURL)
+		log.Printf("Notification Service: %s", notificationServiceURL)
+		log.Printf("Support Service: %s", supportServiceURL)
 		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 			log.Fatalf("Failed to start server: %v", err)
 		}
@@ -169,9 +219,15 @@ func createReverseProxy(targetURL string) http.Handler {
 
 	proxy := httputil.NewSingleHostReverseProxy(target)
 
+	// The gateway has already set the request ID on the response
+	proxy.ModifyResponse = func(resp *http.Response) error {
+		resp.Header.Del(middleware.HeaderRequestID)
+		return nil
+	}
+
 	// Custom error handler
 	proxy.ErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
-		log.Printf("Proxy error: %v", err)
+		log.Printf("Proxy error [%s]: %v", middleware.GetRequestID(r.Context()), err)
 		w.WriteHeader(http.StatusBadGateway)
 		w.Write([]byte(`{"error":"Service temporarily unavailable"}`))
 	}
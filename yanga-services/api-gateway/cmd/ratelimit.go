@@ -0,0 +1,476 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net"
+	"net/http"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/namycodes/yanga-services/shared-lib/config"
+	"github.com/namycodes/yanga-services/shared-lib/middleware"
+	"github.com/namycodes/yanga-services/shared-lib/ratelimit"
+	"github.com/namycodes/yanga-services/shared-lib/utils"
+)
+
+// What a limit or lockout counts requests by. Requests without the key,
+// such as anonymous ones for "user", aren't counted against it.
+const (
+	limitByIP    = "ip"
+	limitByUser  = "user"
+	limitByPhone = "phone"
+)
+
+// maxPhoneBody is how much of a request body is read for its phone number.
+const maxPhoneBody = 64 << 10
+
+// rateLimitPolicy applies to requests with one of Methods (any, when
+// empty) whose path matches Path, a path.Match pattern, or starts with
+// Prefix. The first policy matching a request applies to it; a policy
+// without limits exempts its requests.
+type rateLimitPolicy struct {
+	Name    string          `json:"name"`
+	Methods []string        `json:"methods"`
+	Path    string          `json:"path"`
+	Prefix  string          `json:"prefix"`
+	Limits  []rateLimitRule `json:"limits"`
+	Lockout *lockoutRule    `json:"lockout"`
+}
+
+// rateLimitRule is a token bucket of Burst requests (Requests, when 0)
+// refilled at Requests every Per.
+type rateLimitRule struct {
+	By       string   `json:"by"`
+	Requests int      `json:"requests"`
+	Per      duration `json:"per"`
+	Burst    int      `json:"burst"`
+}
+
+// limit is the bucket the rule describes.
+func (rule rateLimitRule) limit() ratelimit.Limit {
+	burst := rule.Burst
+	if burst == 0 {
+		burst = rule.Requests
+	}
+	return ratelimit.Limit{Requests: rule.Requests, Per: time.Duration(rule.Per), Burst: burst}
+}
+
+// lockoutRule refuses requests for Duration once MaxFailures of them have
+// been rejected with 401 within Window. A successful request starts the
+// count over.
+type lockoutRule struct {
+	By          string   `json:"by"`
+	MaxFailures int      `json:"max_failures"`
+	Window      duration `json:"window"`
+	Duration    duration `json:"duration"`
+}
+
+// duration reads a Go duration string such as "15m" from JSON.
+type duration time.Duration
+
+func (d *duration) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		return err
+	}
+	parsed, err := time.ParseDuration(text)
+	if err != nil {
+		return err
+	}
+	*d = duration(parsed)
+	return nil
+}
+
+// defaultRateLimitPolicies are used when no policy file is configured.
+// SOS alerts are never limited.
+var defaultRateLimitPolicies = []rateLimitPolicy{
+	{Name: "sos", Methods: []string{"POST"}, Path: "/api/v1/trips/*/sos"},
+	{
+		Name: "login", Methods: []string{"POST"}, Path: "/api/v1/auth/login",
+		Limits: []rateLimitRule{
+			{By: limitByIP, Requests: 10, Per: duration(time.Minute), Burst: 20},
+			{By: limitByPhone, Requests: 5, Per: duration(time.Minute)},
+		},
+		Lockout: &lockoutRule{By: limitByPhone, MaxFailures: 5, Window: duration(15 * time.Minute), Duration: duration(15 * time.Minute)},
+	},
+	{
+		Name: "verify-phone", Methods: []string{"POST"}, Path: "/api/v1/auth/verify-phone",
+		Limits: []rateLimitRule{
+			{By: limitByIP, Requests: 20, Per: duration(15 * time.Minute)},
+			{By: limitByPhone, Requests: 5, Per: duration(15 * time.Minute)},
+		},
+	},
+	{
+		Name: "resend-otp", Methods: []string{"POST"}, Path: "/api/v1/auth/resend-otp",
+		Limits: []rateLimitRule{
+			{By: limitByIP, Requests: 10, Per: duration(time.Hour)},
+			{By: limitByPhone, Requests: 3, Per: duration(15 * time.Minute)},
+		},
+	},
+	{
+		Name: "forgot-password", Methods: []string{"POST"}, Path: "/api/v1/auth/forgot-password",
+		Limits: []rateLimitRule{
+			{By: limitByIP, Requests: 10, Per: duration(time.Hour)},
+			{By: limitByPhone, Requests: 3, Per: duration(time.Hour)},
+		},
+	},
+	{
+		Name: "reset-password", Methods: []string{"POST"}, Path: "/api/v1/auth/reset-password",
+		Limits: []rateLimitRule{
+			{By: limitByIP, Requests: 10, Per: duration(time.Hour)},
+		},
+	},
+	{
+		Name: "register", Methods: []string{"POST"}, Path: "/api/v1/auth/register",
+		Limits: []rateLimitRule{
+			{By: limitByIP, Requests: 10, Per: duration(time.Hour)},
+			{By: limitByPhone, Requests: 3, Per: duration(time.Hour)},
+		},
+	},
+	{
+		Name: "auth", Prefix: "/api/v1/auth/",
+		Limits: []rateLimitRule{
+			{By: limitByIP, Requests: 30, Per: duration(time.Minute)},
+		},
+	},
+	{
+		Name: "api", Prefix: "/api/v1/",
+		Limits: []rateLimitRule{
+			{By: limitByIP, Requests: 600, Per: duration(time.Minute)},
+			{By: limitByUser, Requests: 120, Per: duration(time.Minute), Burst: 60},
+		},
+	},
+}
+
+// loadRateLimitPolicies reads policies from RATE_LIMIT_POLICY_PATH, a JSON
+// array, or returns the built-in ones when none is configured or it can't
+// be loaded.
+func loadRateLimitPolicies(cfg *config.Config) []rateLimitPolicy {
+	if cfg.RateLimitPolicyPath == "" {
+		return defaultRateLimitPolicies
+	}
+
+	data, err := os.ReadFile(cfg.RateLimitPolicyPath)
+	if err != nil {
+		log.Printf("Failed to read rate limit policies %s, using the built-in ones: %v", cfg.RateLimitPolicyPath, err)
+		return defaultRateLimitPolicies
+	}
+	var policies []rateLimitPolicy
+	if err := json.Unmarshal(data, &policies); err != nil {
+		log.Printf("Failed to parse rate limit policies %s, using the built-in ones: %v", cfg.RateLimitPolicyPath, err)
+		return defaultRateLimitPolicies
+	}
+	for _, policy := range policies {
+		if err := policy.validate(); err != nil {
+			log.Printf("Invalid rate limit policy %q in %s, using the built-in ones: %v", policy.Name, cfg.RateLimitPolicyPath, err)
+			return defaultRateLimitPolicies
+		}
+	}
+	log.Printf("Loaded rate limit policies %s: %d policies", cfg.RateLimitPolicyPath, len(policies))
+	return policies
+}
+
+func (p rateLimitPolicy) validate() error {
+	if p.Name == "" {
+		return errors.New("name is required")
+	}
+	if (p.Path == "") == (p.Prefix == "") {
+		return errors.New("exactly one of path and prefix is required")
+	}
+	if p.Path != "" {
+		if _, err := path.Match(p.Path, ""); err != nil {
+			return fmt.Errorf("path: %w", err)
+		}
+	}
+	for _, rule := range p.Limits {
+		if !validLimitKey(rule.By) {
+			return fmt.Errorf("unknown limit key %q", rule.By)
+		}
+		if err := rule.limit().Validate(); err != nil {
+			return err
+		}
+	}
+	if p.Lockout != nil {
+		if !validLimitKey(p.Lockout.By) {
+			return fmt.Errorf("unknown lockout key %q", p.Lockout.By)
+		}
+		if p.Lockout.MaxFailures <= 0 || time.Duration(p.Lockout.Window) < time.Millisecond || time.Duration(p.Lockout.Duration) < time.Millisecond {
+			return errors.New("lockout needs positive max_failures, and window and duration of at least 1ms")
+		}
+	}
+	return nil
+}
+
+func validLimitKey(by string) bool {
+	return by == limitByIP || by == limitByUser || by == limitByPhone
+}
+
+func (p rateLimitPolicy) matches(r *http.Request) bool {
+	if len(p.Methods) > 0 {
+		found := false
+		for _, method := range p.Methods {
+			if strings.EqualFold(method, r.Method) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if p.Prefix != "" {
+		return strings.HasPrefix(r.URL.Path, p.Prefix)
+	}
+	matched, _ := path.Match(p.Path, r.URL.Path)
+	return matched
+}
+
+func (p rateLimitPolicy) needsPhone() bool {
+	for _, rule := range p.Limits {
+		if rule.By == limitByPhone {
+			return true
+		}
+	}
+	return p.Lockout != nil && p.Lockout.By == limitByPhone
+}
+
+// rateLimiter applies the first matching policy to each request in two
+// steps around gatewayAuth. Before limits by IP and phone ahead of the token
+// check, so requests with bad or missing tokens are limited too; After
+// limits by user once gatewayAuth has identified them. Each step checks the
+// lockouts on the keys it knows. If the store fails, requests are let
+// through rather than refused.
+type rateLimiter struct {
+	store             ratelimit.Store
+	policies          []rateLimitPolicy
+	trustForwardedFor bool
+}
+
+func newRateLimiter(cfg *config.Config, store ratelimit.Store) *rateLimiter {
+	return &rateLimiter{
+		store:             store,
+		policies:          loadRateLimitPolicies(cfg),
+		trustForwardedFor: cfg.RateLimitTrustForwardedFor,
+	}
+}
+
+// Before limits requests by IP and phone. It runs ahead of gatewayAuth.
+func (l *rateLimiter) Before(next http.Handler) http.Handler {
+	return l.limit(next, func(r *http.Request, policy *rateLimitPolicy) map[string]string {
+		keys := map[string]string{limitByIP: l.clientIP(r)}
+		if policy.needsPhone() {
+			keys[limitByPhone] = requestPhone(r)
+		}
+		return keys
+	})
+}
+
+// After limits requests by user. It runs once gatewayAuth has identified
+// them; anonymous requests pass.
+func (l *rateLimiter) After(next http.Handler) http.Handler {
+	return l.limit(next, func(r *http.Request, policy *rateLimitPolicy) map[string]string {
+		return map[string]string{limitByUser: middleware.GetUserID(r.Context())}
+	})
+}
+
+// limit applies the limits and lockout of a request's policy that count by
+// the keys it is given.
+func (l *rateLimiter) limit(next http.Handler, requestKeys func(*http.Request, *rateLimitPolicy) map[string]string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		policy := l.match(r)
+		if policy == nil || (len(policy.Limits) == 0 && policy.Lockout == nil) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		keys := requestKeys(r, policy)
+
+		lockoutKey := ""
+		if policy.Lockout != nil && keys[policy.Lockout.By] != "" {
+			lockoutKey = policy.Name + ":" + policy.Lockout.By + ":" + keys[policy.Lockout.By]
+			lockedFor, err := l.store.LockedFor(r.Context(), "ratelimit:lockout:"+lockoutKey)
+			if err != nil {
+				log.Printf("Failed to check lockout for %s [%s]: %v", policy.Name, middleware.GetRequestID(r.Context()), err)
+			} else if lockedFor > 0 {
+				setRetryAfter(w, lockedFor)
+				utils.ErrorResponse(w, http.StatusTooManyRequests, "Too many failed attempts, try again later")
+				return
+			}
+		}
+
+		var tightest *ratelimit.Result
+		for _, rule := range policy.Limits {
+			value := keys[rule.By]
+			if value == "" {
+				continue
+			}
+			res, err := l.store.Take(r.Context(), "ratelimit:"+policy.Name+":"+rule.By+":"+value, rule.limit())
+			if err != nil {
+				log.Printf("Failed to check rate limit %s by %s [%s]: %v", policy.Name, rule.By, middleware.GetRequestID(r.Context()), err)
+				continue
+			}
+			if !res.Allowed {
+				setRateLimitHeaders(w, res)
+				setRetryAfter(w, res.RetryAfter)
+				utils.ErrorResponse(w, http.StatusTooManyRequests, "Too many requests, try again later")
+				return
+			}
+			if tightest == nil || res.Remaining*tightest.Limit < tightest.Remaining*res.Limit {
+				tightest = &res
+			}
+		}
+		if tightest != nil {
+			setRateLimitHeaders(w, *tightest)
+		}
+
+		if lockoutKey == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		rec := &statusRecorder{ResponseWriter: w}
+		next.ServeHTTP(rec, r)
+		l.recordOutcome(r, policy, lockoutKey, rec.status)
+	})
+}
+
+func (l *rateLimiter) match(r *http.Request) *rateLimitPolicy {
+	for i := range l.policies {
+		if l.policies[i].matches(r) {
+			return &l.policies[i]
+		}
+	}
+	return nil
+}
+
+// recordOutcome counts a rejected request towards a lockout, locking the
+// key once there have been too many, and forgets earlier failures after a
+// successful one.
+func (l *rateLimiter) recordOutcome(r *http.Request, policy *rateLimitPolicy, lockoutKey string, status int) {
+	ctx := r.Context()
+	failuresKey := "ratelimit:failures:" + lockoutKey
+
+	switch {
+	case status == http.StatusUnauthorized:
+		failures, err := l.store.Increment(ctx, failuresKey, time.Duration(policy.Lockout.Window))
+		if err != nil {
+			log.Printf("Failed to count failure for %s [%s]: %v", policy.Name, middleware.GetRequestID(ctx), err)
+			return
+		}
+		if failures < policy.Lockout.MaxFailures {
+			return
+		}
+		if err := l.store.Lock(ctx, "ratelimit:lockout:"+lockoutKey, time.Duration(policy.Lockout.Duration)); err != nil {
+			log.Printf("Failed to lock out %s [%s]: %v", policy.Name, middleware.GetRequestID(ctx), err)
+			return
+		}
+		if err := l.store.Clear(ctx, failuresKey); err != nil {
+			log.Printf("Failed to reset failures for %s [%s]: %v", policy.Name, middleware.GetRequestID(ctx), err)
+		}
+		log.Printf("Locked out %s by %s for %v after %d failures [%s]", policy.Name, policy.Lockout.By, time.Duration(policy.Lockout.Duration), failures, middleware.GetRequestID(ctx))
+	case status >= 200 && status < 300:
+		if err := l.store.Clear(ctx, failuresKey); err != nil {
+			log.Printf("Failed to reset failures for %s [%s]: %v", policy.Name, middleware.GetRequestID(ctx), err)
+		}
+	}
+}
+
+// clientIP is the address the request came from, or, behind a trusted
+// load balancer, the last address it added to X-Forwarded-For.
+func (l *rateLimiter) clientIP(r *http.Request) string {
+	if l.trustForwardedFor {
+		forwarded := strings.Split(r.Header.Get("X-Forwarded-For"), ",")
+		if last := strings.TrimSpace(forwarded[len(forwarded)-1]); last != "" {
+			return last
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// requestPhone reads the phone_number from a JSON request body, leaving
+// the body for the service to read again. Only digits and a leading '+'
+// are kept, so formatting can't dodge a limit.
+func requestPhone(r *http.Request) string {
+	if r.Body == nil {
+		return ""
+	}
+	head, err := io.ReadAll(io.LimitReader(r.Body, maxPhoneBody))
+	r.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(head), r.Body), r.Body}
+	if err != nil {
+		return ""
+	}
+
+	var body struct {
+		PhoneNumber string `json:"phone_number"`
+	}
+	if err := json.Unmarshal(head, &body); err != nil {
+		return ""
+	}
+
+	var phone strings.Builder
+	for i, c := range strings.TrimSpace(body.PhoneNumber) {
+		if (c >= '0' && c <= '9') || (c == '+' && i == 0) {
+			phone.WriteRune(c)
+		}
+	}
+	return phone.String()
+}
+
+// setRateLimitHeaders reports res, unless an earlier step already reported
+// a limit closer to running out.
+func setRateLimitHeaders(w http.ResponseWriter, res ratelimit.Result) {
+	limit, limitErr := strconv.Atoi(w.Header().Get("X-RateLimit-Limit"))
+	remaining, remainingErr := strconv.Atoi(w.Header().Get("X-RateLimit-Remaining"))
+	if limitErr == nil && remainingErr == nil && res.Remaining*limit > remaining*res.Limit {
+		return
+	}
+	w.Header().Set("X-RateLimit-Limit", strconv.Itoa(res.Limit))
+	w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(res.Remaining))
+	w.Header().Set("X-RateLimit-Reset", strconv.Itoa(int(math.Ceil(res.ResetAfter.Seconds()))))
+}
+
+// setRetryAfter tells the client to wait d, in whole seconds and at least
+// one.
+func setRetryAfter(w http.ResponseWriter, d time.Duration) {
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Max(1, math.Ceil(d.Seconds())))))
+}
+
+// statusRecorder remembers the status the upstream service responded with.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (rec *statusRecorder) WriteHeader(code int) {
+	if rec.status == 0 {
+		rec.status = code
+	}
+	rec.ResponseWriter.WriteHeader(code)
+}
+
+func (rec *statusRecorder) Write(b []byte) (int, error) {
+	if rec.status == 0 {
+		rec.status = http.StatusOK
+	}
+	return rec.ResponseWriter.Write(b)
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController.
+func (rec *statusRecorder) Unwrap() http.ResponseWriter {
+	return rec.ResponseWriter
+}
@@ -0,0 +1,21 @@
+-- Drop triggers
+DROP TRIGGER IF EXISTS ledger_entries_balanced ON ledger_entries;
+DROP TRIGGER IF EXISTS ledger_entries_append_only ON ledger_entries;
+DROP TRIGGER IF EXISTS ledger_transactions_append_only ON ledger_transactions;
+
+-- Drop functions
+DROP FUNCTION IF EXISTS check_ledger_transaction_balanced();
+DROP FUNCTION IF EXISTS prevent_ledger_mutation();
+
+-- Drop indexes
+DROP INDEX IF EXISTS idx_ledger_entries_account_id;
+DROP INDEX IF EXISTS idx_ledger_entries_transaction_id;
+DROP INDEX IF EXISTS idx_ledger_transactions_created_at;
+DROP INDEX IF EXISTS idx_ledger_transactions_trip_id;
+DROP INDEX IF EXISTS idx_ledger_accounts_owner_id;
+DROP INDEX IF EXISTS idx_ledger_accounts_system;
+
+-- Drop tables
+DROP TABLE IF EXISTS ledger_entries;
+DROP TABLE IF EXISTS ledger_transactions;
+DROP TABLE IF EXISTS ledger_accounts;
@@ -0,0 +1,74 @@
+-- Ledger accounts (rider wallets, driver earnings and platform system accounts)
+CREATE TABLE ledger_accounts (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    owner_id UUID REFERENCES users(id),
+    account_type VARCHAR(30) NOT NULL CHECK (account_type IN ('rider_wallet', 'driver_earnings', 'platform_cash', 'platform_revenue', 'platform_promotions')),
+    currency VARCHAR(3) NOT NULL DEFAULT 'KES',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(owner_id, account_type, currency)
+);
+
+-- Ledger transactions (one row per business event, e.g. a top-up or trip charge)
+CREATE TABLE ledger_transactions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    transaction_type VARCHAR(20) NOT NULL CHECK (transaction_type IN ('top_up', 'trip_charge', 'refund', 'promo_credit', 'transfer')),
+    idempotency_key VARCHAR(100) UNIQUE NOT NULL,
+    trip_id UUID REFERENCES trips(id),
+    reversed_transaction_id UUID REFERENCES ledger_transactions(id),
+    description TEXT,
+    created_by UUID REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Ledger entries (double-entry legs; debits and credits of a transaction must balance)
+CREATE TABLE ledger_entries (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    transaction_id UUID NOT NULL REFERENCES ledger_transactions(id),
+    account_id UUID NOT NULL REFERENCES ledger_accounts(id),
+    direction VARCHAR(6) NOT NULL CHECK (direction IN ('debit', 'credit')),
+    amount DECIMAL(12, 2) NOT NULL CHECK (amount > 0),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_ledger_accounts_system ON ledger_accounts(account_type, currency) WHERE owner_id IS NULL;
+CREATE INDEX idx_ledger_accounts_owner_id ON ledger_accounts(owner_id);
+CREATE INDEX idx_ledger_transactions_trip_id ON ledger_transactions(trip_id);
+CREATE INDEX idx_ledger_transactions_created_at ON ledger_transactions(created_at);
+CREATE INDEX idx_ledger_entries_transaction_id ON ledger_entries(transaction_id);
+CREATE INDEX idx_ledger_entries_account_id ON ledger_entries(account_id);
+
+-- The ledger is append-only: corrections are posted as new transactions
+CREATE OR REPLACE FUNCTION prevent_ledger_mutation()
+RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'ledger rows are append-only: % on % is not allowed', TG_OP, TG_TABLE_NAME;
+END;
+$$ language 'plpgsql';
+
+CREATE TRIGGER ledger_transactions_append_only BEFORE UPDATE OR DELETE ON ledger_transactions
+    FOR EACH ROW EXECUTE FUNCTION prevent_ledger_mutation();
+
+CREATE TRIGGER ledger_entries_append_only BEFORE UPDATE OR DELETE ON ledger_entries
+    FOR EACH ROW EXECUTE FUNCTION prevent_ledger_mutation();
+
+-- Debits and credits of every transaction must net to zero at commit time
+CREATE OR REPLACE FUNCTION check_ledger_transaction_balanced()
+RETURNS TRIGGER AS $$
+DECLARE
+    imbalance NUMERIC;
+BEGIN
+    SELECT COALESCE(SUM(CASE WHEN direction = 'debit' THEN amount ELSE -amount END), 0)
+      INTO imbalance
+      FROM ledger_entries
+     WHERE transaction_id = NEW.transaction_id;
+
+    IF imbalance <> 0 THEN
+        RAISE EXCEPTION 'ledger transaction % is unbalanced by %', NEW.transaction_id, imbalance;
+    END IF;
+    RETURN NULL;
+END;
+$$ language 'plpgsql';
+
+CREATE CONSTRAINT TRIGGER ledger_entries_balanced AFTER INSERT ON ledger_entries
+    DEFERRABLE INITIALLY DEFERRED
+    FOR EACH ROW EXECUTE FUNCTION check_ledger_transaction_balanced();
@@ -0,0 +1,20 @@
+-- Drop triggers
+DROP TRIGGER IF EXISTS update_payout_items_updated_at ON payout_items;
+DROP TRIGGER IF EXISTS update_payout_batches_updated_at ON payout_batches;
+DROP TRIGGER IF EXISTS update_commission_plans_updated_at ON commission_plans;
+
+-- Drop indexes
+DROP INDEX IF EXISTS idx_payout_items_driver_id;
+DROP INDEX IF EXISTS idx_payout_items_batch_id;
+DROP INDEX IF EXISTS idx_payout_batches_status;
+DROP INDEX IF EXISTS idx_driver_earnings_payout_item_id;
+DROP INDEX IF EXISTS idx_driver_earnings_driver_id_earned_at;
+DROP INDEX IF EXISTS idx_commission_plans_vehicle_type;
+
+-- Drop tables
+DROP TABLE IF EXISTS driver_earnings;
+DROP TABLE IF EXISTS payout_items;
+DROP TABLE IF EXISTS payout_batches;
+DROP TABLE IF EXISTS commission_plans;
+
+ALTER TABLE trips DROP COLUMN IF EXISTS tip;
@@ -0,0 +1,83 @@
+-- Tips collected at trip completion
+ALTER TABLE trips ADD COLUMN tip DECIMAL(10, 2) DEFAULT 0.00;
+
+-- Commission plans (vehicle_type NULL applies to every vehicle type)
+CREATE TABLE commission_plans (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(100) NOT NULL,
+    vehicle_type VARCHAR(50),
+    commission_rate DECIMAL(5, 4) NOT NULL CHECK (commission_rate >= 0 AND commission_rate <= 1),
+    tax_rate DECIMAL(5, 4) NOT NULL DEFAULT 0 CHECK (tax_rate >= 0 AND tax_rate <= 1),
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    effective_from TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    effective_to TIMESTAMP,
+    created_by UUID REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Payout batches (one settlement run covering all unpaid earnings up to period_end)
+CREATE TABLE payout_batches (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'processing', 'paid', 'failed')),
+    period_end TIMESTAMP NOT NULL,
+    total_amount DECIMAL(12, 2) NOT NULL DEFAULT 0.00,
+    driver_count INTEGER NOT NULL DEFAULT 0,
+    failure_reason TEXT,
+    created_by UUID REFERENCES users(id),
+    processed_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Payout items (one per driver per batch)
+CREATE TABLE payout_items (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    batch_id UUID NOT NULL REFERENCES payout_batches(id) ON DELETE CASCADE,
+    driver_id UUID NOT NULL REFERENCES users(id),
+    amount DECIMAL(12, 2) NOT NULL,
+    earnings_count INTEGER NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'processing', 'paid', 'failed')),
+    payment_reference VARCHAR(100),
+    failure_reason TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(batch_id, driver_id)
+);
+
+-- Driver earnings (fare split recorded once per completed trip)
+CREATE TABLE driver_earnings (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    trip_id UUID UNIQUE NOT NULL REFERENCES trips(id),
+    driver_id UUID NOT NULL REFERENCES users(id),
+    commission_plan_id UUID REFERENCES commission_plans(id),
+    gross_fare DECIMAL(10, 2) NOT NULL,
+    commission_rate DECIMAL(5, 4) NOT NULL,
+    commission DECIMAL(10, 2) NOT NULL,
+    tax DECIMAL(10, 2) NOT NULL,
+    tip DECIMAL(10, 2) NOT NULL DEFAULT 0.00,
+    net_earnings DECIMAL(10, 2) NOT NULL,
+    payment_method VARCHAR(20),
+    payout_item_id UUID REFERENCES payout_items(id),
+    earned_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_commission_plans_vehicle_type ON commission_plans(vehicle_type);
+CREATE INDEX idx_driver_earnings_driver_id_earned_at ON driver_earnings(driver_id, earned_at);
+CREATE INDEX idx_driver_earnings_payout_item_id ON driver_earnings(payout_item_id);
+CREATE INDEX idx_payout_batches_status ON payout_batches(status);
+CREATE INDEX idx_payout_items_batch_id ON payout_items(batch_id);
+CREATE INDEX idx_payout_items_driver_id ON payout_items(driver_id);
+
+CREATE TRIGGER update_commission_plans_updated_at BEFORE UPDATE ON commission_plans
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TRIGGER update_payout_batches_updated_at BEFORE UPDATE ON payout_batches
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TRIGGER update_payout_items_updated_at BEFORE UPDATE ON payout_items
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Default plan: 20% platform commission with 16% VAT charged on the commission
+INSERT INTO commission_plans (name, commission_rate, tax_rate) VALUES ('Standard', 0.2000, 0.1600);
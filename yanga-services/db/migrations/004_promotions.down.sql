@@ -0,0 +1,21 @@
+-- Drop triggers
+DROP TRIGGER IF EXISTS update_promo_redemptions_updated_at ON promo_redemptions;
+DROP TRIGGER IF EXISTS update_promo_codes_updated_at ON promo_codes;
+
+-- Drop indexes
+DROP INDEX IF EXISTS idx_referrals_referrer_id;
+DROP INDEX IF EXISTS idx_promo_redemptions_promo_user;
+DROP INDEX IF EXISTS idx_promo_codes_auto_apply;
+
+-- Drop tables
+DROP TABLE IF EXISTS referrals;
+DROP TABLE IF EXISTS referral_codes;
+DROP TABLE IF EXISTS promo_redemptions;
+DROP TABLE IF EXISTS promo_codes;
+
+ALTER TABLE driver_earnings DROP COLUMN IF EXISTS rider_discount;
+
+ALTER TABLE trips DROP COLUMN IF EXISTS promo_code;
+ALTER TABLE trips DROP COLUMN IF EXISTS discount_amount;
+ALTER TABLE trips DROP COLUMN IF EXISTS subtotal_fare;
+ALTER TABLE trips DROP COLUMN IF EXISTS vehicle_type;
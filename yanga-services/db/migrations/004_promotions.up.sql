@@ -0,0 +1,80 @@
+-- Fare breakdown on trips
+ALTER TABLE trips ADD COLUMN vehicle_type VARCHAR(50);
+ALTER TABLE trips ADD COLUMN subtotal_fare DECIMAL(10, 2);
+ALTER TABLE trips ADD COLUMN discount_amount DECIMAL(10, 2) DEFAULT 0.00;
+ALTER TABLE trips ADD COLUMN promo_code VARCHAR(30);
+
+-- Rider discounts are funded by the platform, so drivers are paid on the full fare
+ALTER TABLE driver_earnings ADD COLUMN rider_discount DECIMAL(10, 2) NOT NULL DEFAULT 0.00;
+
+-- Promo codes (percentage values are 0-100; vehicle_types/radius NULL means unrestricted)
+CREATE TABLE promo_codes (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    code VARCHAR(30) UNIQUE NOT NULL,
+    description TEXT,
+    discount_type VARCHAR(20) NOT NULL CHECK (discount_type IN ('percentage', 'fixed')),
+    discount_value DECIMAL(10, 2) NOT NULL CHECK (discount_value > 0),
+    max_discount DECIMAL(10, 2),
+    min_fare DECIMAL(10, 2),
+    usage_limit INTEGER,
+    per_user_limit INTEGER NOT NULL DEFAULT 1,
+    times_used INTEGER NOT NULL DEFAULT 0,
+    first_ride_only BOOLEAN NOT NULL DEFAULT false,
+    auto_apply BOOLEAN NOT NULL DEFAULT false,
+    vehicle_types TEXT[],
+    center_latitude DECIMAL(10, 8),
+    center_longitude DECIMAL(11, 8),
+    radius_km DECIMAL(8, 2),
+    valid_from TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    valid_until TIMESTAMP,
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    created_by UUID REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK (discount_type <> 'percentage' OR discount_value <= 100),
+    CHECK (usage_limit IS NULL OR times_used <= usage_limit)
+);
+
+-- Promo redemptions (reserved at booking, redeemed on completion, released on cancellation)
+CREATE TABLE promo_redemptions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    promo_code_id UUID NOT NULL REFERENCES promo_codes(id),
+    user_id UUID NOT NULL REFERENCES users(id),
+    trip_id UUID UNIQUE NOT NULL REFERENCES trips(id),
+    discount_amount DECIMAL(10, 2) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'reserved' CHECK (status IN ('reserved', 'redeemed', 'released')),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Referral codes (one per user, generated on sign-up)
+CREATE TABLE referral_codes (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID UNIQUE NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    code VARCHAR(20) UNIQUE NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Referrals (rewarded once the referee completes their first trip)
+CREATE TABLE referrals (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    referrer_id UUID NOT NULL REFERENCES users(id),
+    referee_id UUID UNIQUE NOT NULL REFERENCES users(id),
+    referrer_reward DECIMAL(10, 2) NOT NULL,
+    referee_reward DECIMAL(10, 2) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'rewarded')),
+    qualifying_trip_id UUID REFERENCES trips(id),
+    rewarded_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK (referrer_id <> referee_id)
+);
+
+CREATE INDEX idx_promo_codes_auto_apply ON promo_codes(auto_apply) WHERE is_active;
+CREATE INDEX idx_promo_redemptions_promo_user ON promo_redemptions(promo_code_id, user_id);
+CREATE INDEX idx_referrals_referrer_id ON referrals(referrer_id);
+
+CREATE TRIGGER update_promo_codes_updated_at BEFORE UPDATE ON promo_codes
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TRIGGER update_promo_redemptions_updated_at BEFORE UPDATE ON promo_redemptions
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
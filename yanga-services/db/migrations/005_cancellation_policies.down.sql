@@ -0,0 +1,21 @@
+-- Drop triggers
+DROP TRIGGER IF EXISTS update_cancellation_policies_updated_at ON cancellation_policies;
+
+-- Drop indexes
+DROP INDEX IF EXISTS idx_trips_cancelled_by;
+
+-- Drop tables
+DROP TABLE IF EXISTS cancellation_policies;
+
+-- The ledger is append-only, so existing cancellation_fee rows are kept and
+-- the narrower check only applies to new rows
+ALTER TABLE ledger_transactions DROP CONSTRAINT ledger_transactions_transaction_type_check;
+ALTER TABLE ledger_transactions ADD CONSTRAINT ledger_transactions_transaction_type_check
+    CHECK (transaction_type IN ('top_up', 'trip_charge', 'refund', 'promo_credit', 'transfer')) NOT VALID;
+
+ALTER TABLE trips DROP COLUMN IF EXISTS cancellation_fee;
+ALTER TABLE trips DROP COLUMN IF EXISTS no_show_party;
+ALTER TABLE trips DROP COLUMN IF EXISTS cancelled_by;
+ALTER TABLE trips DROP COLUMN IF EXISTS arrived_at;
+ALTER TABLE trips DROP COLUMN IF EXISTS accepted_at;
+ALTER TABLE trips DROP COLUMN IF EXISTS city_code;
@@ -0,0 +1,41 @@
+-- Cancellation details on trips
+ALTER TABLE trips ADD COLUMN city_code VARCHAR(50);
+ALTER TABLE trips ADD COLUMN accepted_at TIMESTAMP;
+ALTER TABLE trips ADD COLUMN arrived_at TIMESTAMP;
+ALTER TABLE trips ADD COLUMN cancelled_by VARCHAR(20) CHECK (cancelled_by IN ('rider', 'driver', 'system'));
+ALTER TABLE trips ADD COLUMN no_show_party VARCHAR(20) CHECK (no_show_party IN ('rider', 'driver'));
+ALTER TABLE trips ADD COLUMN cancellation_fee DECIMAL(10, 2) DEFAULT 0.00;
+
+-- Wallet riders are charged cancellation fees through the ledger
+ALTER TABLE ledger_transactions DROP CONSTRAINT ledger_transactions_transaction_type_check;
+ALTER TABLE ledger_transactions ADD CONSTRAINT ledger_transactions_transaction_type_check
+    CHECK (transaction_type IN ('top_up', 'trip_charge', 'refund', 'promo_credit', 'transfer', 'cancellation_fee'));
+
+-- Cancellation policies per city ('default' applies where a city has none)
+CREATE TABLE cancellation_policies (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    city_code VARCHAR(50) UNIQUE NOT NULL,
+    free_cancellation_seconds INTEGER NOT NULL DEFAULT 120 CHECK (free_cancellation_seconds >= 0),
+    late_cancellation_fee DECIMAL(10, 2) NOT NULL DEFAULT 0.00 CHECK (late_cancellation_fee >= 0),
+    driver_nearby_meters INTEGER NOT NULL DEFAULT 300 CHECK (driver_nearby_meters >= 0),
+    driver_arrived_fee DECIMAL(10, 2) NOT NULL DEFAULT 0.00 CHECK (driver_arrived_fee >= 0),
+    rider_no_show_wait_seconds INTEGER NOT NULL DEFAULT 300 CHECK (rider_no_show_wait_seconds >= 0),
+    rider_no_show_fee DECIMAL(10, 2) NOT NULL DEFAULT 0.00 CHECK (rider_no_show_fee >= 0),
+    driver_no_show_wait_seconds INTEGER NOT NULL DEFAULT 900 CHECK (driver_no_show_wait_seconds >= 0),
+    match_timeout_seconds INTEGER NOT NULL DEFAULT 600 CHECK (match_timeout_seconds > 0),
+    updated_by UUID REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_trips_cancelled_by ON trips(driver_id, cancelled_by) WHERE status = 'cancelled';
+
+CREATE TRIGGER update_cancellation_policies_updated_at BEFORE UPDATE ON cancellation_policies
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Default policy: 2 minutes free after acceptance, then a late fee; a higher
+-- fee once the driver is within 300m or has arrived
+INSERT INTO cancellation_policies (
+    city_code, free_cancellation_seconds, late_cancellation_fee, driver_nearby_meters, driver_arrived_fee,
+    rider_no_show_wait_seconds, rider_no_show_fee, driver_no_show_wait_seconds, match_timeout_seconds
+) VALUES ('default', 120, 50.00, 300, 100.00, 300, 100.00, 900, 600);
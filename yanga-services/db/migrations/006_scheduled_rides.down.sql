@@ -0,0 +1,19 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_trips_reserved_driver_id;
+DROP INDEX IF EXISTS idx_trips_scheduled_pickup_at;
+
+ALTER TABLE trips DROP COLUMN IF EXISTS reserved_at;
+ALTER TABLE trips DROP COLUMN IF EXISTS reserved_driver_id;
+ALTER TABLE trips DROP COLUMN IF EXISTS reminder_sent_at;
+ALTER TABLE trips DROP COLUMN IF EXISTS dispatched_at;
+ALTER TABLE trips DROP COLUMN IF EXISTS pickup_at;
+
+-- Trips still waiting to be dispatched can't be represented without the
+-- scheduled status
+UPDATE trips SET status = 'cancelled', cancelled_at = CURRENT_TIMESTAMP, cancelled_by = 'system',
+    cancellation_reason = 'Scheduled rides were disabled'
+WHERE status = 'scheduled';
+
+ALTER TABLE trips DROP CONSTRAINT trips_status_check;
+ALTER TABLE trips ADD CONSTRAINT trips_status_check
+    CHECK (status IN ('pending', 'accepted', 'in_progress', 'completed', 'cancelled'));
@@ -0,0 +1,15 @@
+-- Scheduled trips wait in 'scheduled' until they are dispatched shortly
+-- before pickup
+ALTER TABLE trips DROP CONSTRAINT trips_status_check;
+ALTER TABLE trips ADD CONSTRAINT trips_status_check
+    CHECK (status IN ('scheduled', 'pending', 'accepted', 'in_progress', 'completed', 'cancelled'));
+
+ALTER TABLE trips ADD COLUMN pickup_at TIMESTAMP;
+ALTER TABLE trips ADD COLUMN dispatched_at TIMESTAMP;
+ALTER TABLE trips ADD COLUMN reminder_sent_at TIMESTAMP;
+ALTER TABLE trips ADD COLUMN reserved_driver_id UUID REFERENCES users(id);
+ALTER TABLE trips ADD COLUMN reserved_at TIMESTAMP;
+
+-- Indexes for the scheduler and driver reservations
+CREATE INDEX idx_trips_scheduled_pickup_at ON trips(pickup_at) WHERE status = 'scheduled';
+CREATE INDEX idx_trips_reserved_driver_id ON trips(reserved_driver_id) WHERE reserved_driver_id IS NOT NULL;
@@ -0,0 +1,8 @@
+-- Drop triggers
+DROP TRIGGER IF EXISTS update_trip_stops_updated_at ON trip_stops;
+
+-- Drop indexes
+DROP INDEX IF EXISTS idx_trip_stops_trip_id;
+
+-- Drop tables
+DROP TABLE IF EXISTS trip_stops;
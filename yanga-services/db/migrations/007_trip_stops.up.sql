@@ -0,0 +1,21 @@
+-- Intermediate stops between a trip's pickup and dropoff, in visiting order
+CREATE TABLE trip_stops (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    trip_id UUID NOT NULL REFERENCES trips(id) ON DELETE CASCADE,
+    stop_order INTEGER NOT NULL CHECK (stop_order > 0),
+    latitude DECIMAL(10, 8) NOT NULL,
+    longitude DECIMAL(11, 8) NOT NULL,
+    address TEXT NOT NULL,
+    arrived_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    -- Deferred so stops can be renumbered in a single UPDATE
+    CONSTRAINT trip_stops_trip_id_stop_order_key UNIQUE (trip_id, stop_order) DEFERRABLE INITIALLY DEFERRED
+);
+
+-- Indexes
+CREATE INDEX idx_trip_stops_trip_id ON trip_stops(trip_id);
+
+-- Triggers
+CREATE TRIGGER update_trip_stops_updated_at BEFORE UPDATE ON trip_stops
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
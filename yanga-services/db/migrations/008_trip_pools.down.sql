@@ -0,0 +1,16 @@
+-- Drop triggers
+DROP TRIGGER IF EXISTS update_pool_waypoints_updated_at ON pool_waypoints;
+DROP TRIGGER IF EXISTS update_trip_pools_updated_at ON trip_pools;
+
+-- Drop indexes
+DROP INDEX IF EXISTS idx_pool_waypoints_pool_id;
+DROP INDEX IF EXISTS idx_trip_pools_open;
+DROP INDEX IF EXISTS idx_trips_pool_id;
+
+-- Drop tables
+DROP TABLE IF EXISTS pool_waypoints;
+
+ALTER TABLE trips DROP COLUMN IF EXISTS seat_count;
+ALTER TABLE trips DROP COLUMN IF EXISTS pool_id;
+
+DROP TABLE IF EXISTS trip_pools;
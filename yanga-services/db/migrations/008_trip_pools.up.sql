@@ -0,0 +1,44 @@
+-- A pool is one vehicle's shared route. Each rider keeps their own trip,
+-- linked to the pool, and the pool's waypoints order every rider's pickup
+-- and dropoff.
+CREATE TABLE trip_pools (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    driver_id UUID REFERENCES users(id),
+    vehicle_type VARCHAR(50) NOT NULL DEFAULT '',
+    seat_capacity INTEGER NOT NULL CHECK (seat_capacity > 0),
+    status VARCHAR(20) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'completed')),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE trips ADD COLUMN pool_id UUID REFERENCES trip_pools(id);
+ALTER TABLE trips ADD COLUMN seat_count INTEGER NOT NULL DEFAULT 1 CHECK (seat_count > 0);
+
+CREATE TABLE pool_waypoints (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    pool_id UUID NOT NULL REFERENCES trip_pools(id) ON DELETE CASCADE,
+    trip_id UUID NOT NULL REFERENCES trips(id) ON DELETE CASCADE,
+    kind VARCHAR(10) NOT NULL CHECK (kind IN ('pickup', 'dropoff')),
+    sequence INTEGER NOT NULL CHECK (sequence > 0),
+    latitude DECIMAL(10, 8) NOT NULL,
+    longitude DECIMAL(11, 8) NOT NULL,
+    address TEXT NOT NULL,
+    completed_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (trip_id, kind),
+    -- Deferred so waypoints can be resequenced one row at a time
+    CONSTRAINT pool_waypoints_pool_id_sequence_key UNIQUE (pool_id, sequence) DEFERRABLE INITIALLY DEFERRED
+);
+
+-- Indexes
+CREATE INDEX idx_trips_pool_id ON trips(pool_id) WHERE pool_id IS NOT NULL;
+CREATE INDEX idx_trip_pools_open ON trip_pools(created_at) WHERE status = 'open';
+CREATE INDEX idx_pool_waypoints_pool_id ON pool_waypoints(pool_id);
+
+-- Triggers
+CREATE TRIGGER update_trip_pools_updated_at BEFORE UPDATE ON trip_pools
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TRIGGER update_pool_waypoints_updated_at BEFORE UPDATE ON pool_waypoints
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,10 @@
+-- Drop triggers
+DROP TRIGGER IF EXISTS update_parcel_deliveries_updated_at ON parcel_deliveries;
+
+-- Drop indexes
+DROP INDEX IF EXISTS idx_trips_trip_type;
+
+-- Drop tables
+DROP TABLE IF EXISTS parcel_deliveries;
+
+ALTER TABLE trips DROP COLUMN IF EXISTS trip_type;
@@ -0,0 +1,33 @@
+-- Deliveries share the trip lifecycle; trip_type tells them apart from
+-- passenger rides
+ALTER TABLE trips ADD COLUMN trip_type VARCHAR(20) NOT NULL DEFAULT 'ride'
+    CHECK (trip_type IN ('ride', 'delivery'));
+
+-- Parcel details and proof of pickup and delivery for delivery trips
+CREATE TABLE parcel_deliveries (
+    trip_id UUID PRIMARY KEY REFERENCES trips(id) ON DELETE CASCADE,
+    sender_name VARCHAR(255) NOT NULL,
+    sender_phone VARCHAR(20) NOT NULL,
+    recipient_name VARCHAR(255) NOT NULL,
+    recipient_phone VARCHAR(20) NOT NULL,
+    parcel_size VARCHAR(20) NOT NULL CHECK (parcel_size IN ('small', 'medium', 'large')),
+    weight_kg DECIMAL(6, 2) NOT NULL CHECK (weight_kg > 0),
+    description TEXT,
+    instructions TEXT,
+    -- Shared by the sender with the recipient, who gives it to the driver
+    recipient_pin VARCHAR(6) NOT NULL,
+    pin_attempts INTEGER NOT NULL DEFAULT 0,
+    pickup_photo_ref TEXT,
+    picked_up_at TIMESTAMP,
+    delivery_photo_ref TEXT,
+    delivered_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Indexes
+CREATE INDEX idx_trips_trip_type ON trips(trip_type);
+
+-- Triggers
+CREATE TRIGGER update_parcel_deliveries_updated_at BEFORE UPDATE ON parcel_deliveries
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
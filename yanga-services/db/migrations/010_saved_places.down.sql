@@ -0,0 +1,9 @@
+-- Drop triggers
+DROP TRIGGER IF EXISTS update_saved_places_updated_at ON saved_places;
+
+-- Drop indexes
+DROP INDEX IF EXISTS idx_saved_places_user_label;
+DROP INDEX IF EXISTS idx_saved_places_user_id;
+
+-- Drop tables
+DROP TABLE IF EXISTS saved_places;
@@ -0,0 +1,22 @@
+-- Places riders save for quick booking: one home, one work and any number
+-- of favorites
+CREATE TABLE saved_places (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    label VARCHAR(20) NOT NULL CHECK (label IN ('home', 'work', 'favorite')),
+    name VARCHAR(100) NOT NULL,
+    address TEXT NOT NULL,
+    latitude DECIMAL(10, 8) NOT NULL,
+    longitude DECIMAL(11, 8) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Indexes
+CREATE INDEX idx_saved_places_user_id ON saved_places(user_id);
+CREATE UNIQUE INDEX idx_saved_places_user_label ON saved_places(user_id, label)
+    WHERE label IN ('home', 'work');
+
+-- Triggers
+CREATE TRIGGER update_saved_places_updated_at BEFORE UPDATE ON saved_places
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,13 @@
+-- Drop triggers
+DROP TRIGGER IF EXISTS update_geofences_updated_at ON geofences;
+
+-- Drop indexes
+DROP INDEX IF EXISTS idx_driver_geofences_geofence_id;
+DROP INDEX IF EXISTS idx_geofences_city_code;
+DROP INDEX IF EXISTS idx_geofences_bounds;
+
+-- Drop tables
+DROP TABLE IF EXISTS driver_geofences;
+DROP TABLE IF EXISTS geofences;
+
+ALTER TABLE trips DROP COLUMN IF EXISTS zone_fee;
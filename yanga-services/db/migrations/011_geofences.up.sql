@@ -0,0 +1,42 @@
+-- Polygons drawn around where we operate. Service areas bound where trips
+-- may start and end, restricted zones forbid pickups, and airports add fees
+-- to trips starting or ending inside them.
+CREATE TABLE geofences (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    city_code VARCHAR(50) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('service_area', 'restricted', 'airport')),
+    -- Vertices in order as [{"latitude": ..., "longitude": ...}, ...]
+    polygon JSONB NOT NULL,
+    -- Bounding box of the polygon, to narrow lookups before the exact test
+    min_latitude DECIMAL(10, 8) NOT NULL,
+    max_latitude DECIMAL(10, 8) NOT NULL,
+    min_longitude DECIMAL(11, 8) NOT NULL,
+    max_longitude DECIMAL(11, 8) NOT NULL,
+    pickup_fee DECIMAL(10, 2) NOT NULL DEFAULT 0 CHECK (pickup_fee >= 0),
+    dropoff_fee DECIMAL(10, 2) NOT NULL DEFAULT 0 CHECK (dropoff_fee >= 0),
+    active BOOLEAN NOT NULL DEFAULT true,
+    created_by UUID REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Geofences each driver was last seen inside, to tell entries from exits
+CREATE TABLE driver_geofences (
+    driver_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    geofence_id UUID NOT NULL REFERENCES geofences(id) ON DELETE CASCADE,
+    entered_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (driver_id, geofence_id)
+);
+
+-- Airport fees charged on the trip, kept so re-pricing preserves them
+ALTER TABLE trips ADD COLUMN zone_fee DECIMAL(10, 2) NOT NULL DEFAULT 0.00;
+
+-- Indexes
+CREATE INDEX idx_geofences_bounds ON geofences(min_latitude, max_latitude, min_longitude, max_longitude) WHERE active;
+CREATE INDEX idx_geofences_city_code ON geofences(city_code);
+CREATE INDEX idx_driver_geofences_geofence_id ON driver_geofences(geofence_id);
+
+-- Triggers
+CREATE TRIGGER update_geofences_updated_at BEFORE UPDATE ON geofences
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,12 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_airport_queue_entries_airport;
+DROP INDEX IF EXISTS idx_geofences_parent_id;
+
+-- Drop tables
+DROP TABLE IF EXISTS airport_queue_entries;
+
+DELETE FROM geofences WHERE kind = 'staging';
+ALTER TABLE geofences DROP COLUMN IF EXISTS parent_id;
+ALTER TABLE geofences DROP CONSTRAINT geofences_kind_check;
+ALTER TABLE geofences ADD CONSTRAINT geofences_kind_check
+    CHECK (kind IN ('service_area', 'restricted', 'airport'));
@@ -0,0 +1,18 @@
+-- Staging lots where drivers wait for airport pickups. Each belongs to the
+-- airport whose pickups it serves.
+ALTER TABLE geofences DROP CONSTRAINT geofences_kind_check;
+ALTER TABLE geofences ADD CONSTRAINT geofences_kind_check
+    CHECK (kind IN ('service_area', 'restricted', 'airport', 'staging'));
+ALTER TABLE geofences ADD COLUMN parent_id UUID REFERENCES geofences(id) ON DELETE CASCADE;
+
+-- Drivers waiting in an airport's staging lots, served first in, first out
+CREATE TABLE airport_queue_entries (
+    driver_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    airport_id UUID NOT NULL REFERENCES geofences(id) ON DELETE CASCADE,
+    staging_id UUID NOT NULL REFERENCES geofences(id) ON DELETE CASCADE,
+    joined_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Indexes
+CREATE INDEX idx_geofences_parent_id ON geofences(parent_id) WHERE parent_id IS NOT NULL;
+CREATE INDEX idx_airport_queue_entries_airport ON airport_queue_entries(airport_id, joined_at, driver_id);
@@ -0,0 +1,16 @@
+-- Drop triggers
+DROP TRIGGER IF EXISTS update_cities_updated_at ON cities;
+
+-- Drop indexes
+DROP INDEX IF EXISTS idx_driver_profiles_city_code;
+DROP INDEX IF EXISTS idx_trips_city_code;
+
+ALTER TABLE promo_codes DROP COLUMN IF EXISTS city_code;
+ALTER TABLE commission_plans DROP COLUMN IF EXISTS city_code;
+ALTER TABLE driver_profiles DROP COLUMN IF EXISTS city_code;
+ALTER TABLE geofences DROP CONSTRAINT IF EXISTS geofences_city_code_fkey;
+ALTER TABLE trips DROP COLUMN IF EXISTS currency;
+ALTER TABLE trips DROP CONSTRAINT IF EXISTS trips_city_code_fkey;
+
+-- Drop tables
+DROP TABLE IF EXISTS cities;
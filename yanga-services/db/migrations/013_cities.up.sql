@@ -0,0 +1,65 @@
+-- Cities (markets) we operate in. Every trip, driver and geofence belongs to
+-- one, which sets its currency, timezone, rate card, commission and the
+-- features offered there. Cancellation policies were already kept per city.
+CREATE TABLE cities (
+    code VARCHAR(50) PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    -- ISO 4217 code fares and fees are charged in
+    currency VARCHAR(3) NOT NULL,
+    -- IANA zone pickup times are shown in, e.g. Africa/Nairobi
+    timezone VARCHAR(50) NOT NULL,
+    -- Rate card
+    base_fare DECIMAL(10, 2) NOT NULL CHECK (base_fare >= 0),
+    per_km_rate DECIMAL(10, 2) NOT NULL CHECK (per_km_rate >= 0),
+    per_stop_fee DECIMAL(10, 2) NOT NULL CHECK (per_stop_fee >= 0),
+    delivery_base_fare DECIMAL(10, 2) NOT NULL CHECK (delivery_base_fare >= 0),
+    delivery_per_km_rate DECIMAL(10, 2) NOT NULL CHECK (delivery_per_km_rate >= 0),
+    -- Feature toggles
+    pooling_enabled BOOLEAN NOT NULL DEFAULT true,
+    deliveries_enabled BOOLEAN NOT NULL DEFAULT true,
+    scheduling_enabled BOOLEAN NOT NULL DEFAULT true,
+    stops_enabled BOOLEAN NOT NULL DEFAULT true,
+    active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- The market we launched in, at the rates used until now
+INSERT INTO cities (
+    code, name, currency, timezone, base_fare, per_km_rate, per_stop_fee, delivery_base_fare, delivery_per_km_rate
+) VALUES ('nairobi', 'Nairobi', 'KES', 'Africa/Nairobi', 50.00, 20.00, 20.00, 40.00, 18.00);
+
+-- Cities already named by geofences, trips or cancellation policies start
+-- out with the same settings
+INSERT INTO cities (
+    code, name, currency, timezone, base_fare, per_km_rate, per_stop_fee, delivery_base_fare, delivery_per_km_rate
+)
+SELECT code, code, 'KES', 'Africa/Nairobi', 50.00, 20.00, 20.00, 40.00, 18.00
+FROM (
+    SELECT city_code AS code FROM geofences
+    UNION SELECT city_code FROM trips WHERE city_code IS NOT NULL
+    UNION SELECT city_code FROM cancellation_policies WHERE city_code <> 'default'
+) existing
+ON CONFLICT (code) DO NOTHING;
+
+-- Trips booked before any service area was drawn belong to the launch city
+UPDATE trips SET city_code = 'nairobi' WHERE city_code IS NULL;
+ALTER TABLE trips ADD CONSTRAINT trips_city_code_fkey FOREIGN KEY (city_code) REFERENCES cities(code);
+ALTER TABLE trips ADD COLUMN currency VARCHAR(3) NOT NULL DEFAULT 'KES';
+
+ALTER TABLE geofences ADD CONSTRAINT geofences_city_code_fkey FOREIGN KEY (city_code) REFERENCES cities(code);
+
+-- A driver's home city; they are only offered trips there
+ALTER TABLE driver_profiles ADD COLUMN city_code VARCHAR(50) REFERENCES cities(code);
+
+-- Plans and promo codes limited to one city; NULL applies everywhere
+ALTER TABLE commission_plans ADD COLUMN city_code VARCHAR(50) REFERENCES cities(code);
+ALTER TABLE promo_codes ADD COLUMN city_code VARCHAR(50) REFERENCES cities(code);
+
+-- Indexes
+CREATE INDEX idx_trips_city_code ON trips(city_code, status);
+CREATE INDEX idx_driver_profiles_city_code ON driver_profiles(city_code);
+
+-- Triggers
+CREATE TRIGGER update_cities_updated_at BEFORE UPDATE ON cities
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1 @@
+ALTER TABLE driver_earnings DROP COLUMN IF EXISTS currency;
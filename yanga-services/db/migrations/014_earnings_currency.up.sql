@@ -0,0 +1,3 @@
+-- Earnings are recorded in the currency the trip was charged in. Earnings
+-- made before cities had their own currency were all in shillings.
+ALTER TABLE driver_earnings ADD COLUMN currency VARCHAR(3) NOT NULL DEFAULT 'KES';
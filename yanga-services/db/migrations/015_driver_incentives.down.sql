@@ -0,0 +1,19 @@
+-- Drop triggers
+DROP TRIGGER IF EXISTS update_incentive_progress_updated_at ON incentive_progress;
+DROP TRIGGER IF EXISTS update_incentive_programs_updated_at ON incentive_programs;
+
+-- Drop indexes
+DROP INDEX IF EXISTS idx_driver_earnings_incentive_program_id;
+DROP INDEX IF EXISTS idx_incentive_progress_driver_id;
+DROP INDEX IF EXISTS idx_incentive_programs_window;
+
+-- Bonus earnings have no trip and can't be kept
+DELETE FROM driver_earnings WHERE incentive_program_id IS NOT NULL;
+ALTER TABLE driver_earnings DROP CONSTRAINT IF EXISTS driver_earnings_source_check;
+ALTER TABLE driver_earnings DROP COLUMN IF EXISTS incentive_program_id;
+ALTER TABLE driver_earnings ALTER COLUMN trip_id SET NOT NULL;
+
+-- Drop tables
+DROP TABLE IF EXISTS incentive_trips;
+DROP TABLE IF EXISTS incentive_progress;
+DROP TABLE IF EXISTS incentive_programs;
@@ -0,0 +1,74 @@
+-- Driver incentive programs, evaluated against completed trips:
+--   quest           reward_amount once a driver completes target_trips
+--   peak_guarantee  tops fares up to reward_amount for drivers who complete
+--                   target_trips, paid once the window ends
+--   area_boost      pays boost_rate of the fare on trips picked up inside
+--                   geofence_id
+-- Only trips completed between starts_at and ends_at count.
+CREATE TABLE incentive_programs (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('quest', 'peak_guarantee', 'area_boost')),
+    -- NULL applies to every city or vehicle type
+    city_code VARCHAR(50) REFERENCES cities(code),
+    vehicle_type VARCHAR(50),
+    geofence_id UUID REFERENCES geofences(id),
+    currency VARCHAR(3) NOT NULL,
+    target_trips INTEGER NOT NULL DEFAULT 0 CHECK (target_trips >= 0),
+    reward_amount DECIMAL(10, 2) NOT NULL DEFAULT 0.00 CHECK (reward_amount >= 0),
+    boost_rate DECIMAL(5, 4) NOT NULL DEFAULT 0 CHECK (boost_rate >= 0 AND boost_rate <= 1),
+    starts_at TIMESTAMP NOT NULL,
+    ends_at TIMESTAMP NOT NULL,
+    -- Set once a peak guarantee's top-ups have been paid
+    settled_at TIMESTAMP,
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    created_by UUID REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK (ends_at > starts_at),
+    CHECK (kind <> 'area_boost' OR geofence_id IS NOT NULL)
+);
+
+-- Each driver's progress towards a program
+CREATE TABLE incentive_progress (
+    program_id UUID NOT NULL REFERENCES incentive_programs(id) ON DELETE CASCADE,
+    driver_id UUID NOT NULL REFERENCES users(id),
+    trips_completed INTEGER NOT NULL DEFAULT 0,
+    -- Gross fares of the counted trips, for peak guarantees
+    fares_earned DECIMAL(12, 2) NOT NULL DEFAULT 0.00,
+    bonus_earned DECIMAL(12, 2) NOT NULL DEFAULT 0.00,
+    -- When the driver reached target_trips
+    completed_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (program_id, driver_id)
+);
+
+-- Trips already counted towards a program, so a redelivered trip.completed
+-- event is never counted twice
+CREATE TABLE incentive_trips (
+    program_id UUID NOT NULL REFERENCES incentive_programs(id) ON DELETE CASCADE,
+    trip_id UUID NOT NULL REFERENCES trips(id),
+    driver_id UUID NOT NULL REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (program_id, trip_id)
+);
+
+-- Bonuses are credited as earnings without a trip so that statements and
+-- payouts include them
+ALTER TABLE driver_earnings ALTER COLUMN trip_id DROP NOT NULL;
+ALTER TABLE driver_earnings ADD COLUMN incentive_program_id UUID REFERENCES incentive_programs(id);
+ALTER TABLE driver_earnings ADD CONSTRAINT driver_earnings_source_check
+    CHECK ((trip_id IS NULL) <> (incentive_program_id IS NULL));
+
+-- Indexes
+CREATE INDEX idx_incentive_programs_window ON incentive_programs(starts_at, ends_at) WHERE is_active = true;
+CREATE INDEX idx_incentive_progress_driver_id ON incentive_progress(driver_id);
+CREATE INDEX idx_driver_earnings_incentive_program_id ON driver_earnings(incentive_program_id) WHERE incentive_program_id IS NOT NULL;
+
+-- Triggers
+CREATE TRIGGER update_incentive_programs_updated_at BEFORE UPDATE ON incentive_programs
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+CREATE TRIGGER update_incentive_progress_updated_at BEFORE UPDATE ON incentive_progress
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,6 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_driver_rating_entries_driver_id_rated_at;
+
+-- Drop tables
+DROP TABLE IF EXISTS driver_rating_stats;
+DROP TABLE IF EXISTS driver_rating_entries;
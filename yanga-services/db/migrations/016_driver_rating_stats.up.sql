@@ -0,0 +1,29 @@
+-- Ratings riders gave drivers that driver-service has counted. Recording
+-- each rating once makes a redelivered rating.created event harmless.
+CREATE TABLE driver_rating_entries (
+    rating_id UUID PRIMARY KEY REFERENCES ratings(id) ON DELETE CASCADE,
+    driver_id UUID NOT NULL REFERENCES users(id),
+    trip_id UUID NOT NULL REFERENCES trips(id) ON DELETE CASCADE,
+    rating INTEGER NOT NULL CHECK (rating >= 1 AND rating <= 5),
+    rated_at TIMESTAMP NOT NULL
+);
+
+-- Rating aggregates per driver, recomputed from driver_rating_entries.
+-- rolling_average covers the driver's latest rolling_count ratings and is
+-- the rating shown on their profile.
+CREATE TABLE driver_rating_stats (
+    driver_id UUID PRIMARY KEY REFERENCES users(id),
+    rating_count INTEGER NOT NULL DEFAULT 0,
+    lifetime_average DECIMAL(3, 2) NOT NULL DEFAULT 0.00,
+    rolling_count INTEGER NOT NULL DEFAULT 0,
+    rolling_average DECIMAL(3, 2) NOT NULL DEFAULT 0.00,
+    one_star INTEGER NOT NULL DEFAULT 0,
+    two_star INTEGER NOT NULL DEFAULT 0,
+    three_star INTEGER NOT NULL DEFAULT 0,
+    four_star INTEGER NOT NULL DEFAULT 0,
+    five_star INTEGER NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Indexes
+CREATE INDEX idx_driver_rating_entries_driver_id_rated_at ON driver_rating_entries(driver_id, rated_at DESC);
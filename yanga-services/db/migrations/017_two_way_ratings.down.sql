@@ -0,0 +1,10 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_ratings_rated_id_rater_type;
+
+-- Drop tables
+DROP TABLE IF EXISTS rating_tags;
+
+-- Ratings of riders can't be told apart from ratings of drivers without
+-- rater_type
+DELETE FROM ratings WHERE rater_type = 'driver';
+ALTER TABLE ratings DROP COLUMN IF EXISTS rater_type;
@@ -0,0 +1,22 @@
+-- Riders rate drivers and drivers rate riders. rater_type records which
+-- side of the trip the rater was on.
+ALTER TABLE ratings ADD COLUMN rater_type VARCHAR(10) NOT NULL DEFAULT 'rider'
+    CHECK (rater_type IN ('rider', 'driver'));
+
+UPDATE ratings r
+SET rater_type = 'driver'
+FROM trips t
+WHERE t.id = r.trip_id AND t.driver_id = r.rater_id;
+
+ALTER TABLE ratings ALTER COLUMN rater_type DROP DEFAULT;
+
+-- Structured feedback chosen from a fixed list per rater_type, such as
+-- clean_car or late
+CREATE TABLE rating_tags (
+    rating_id UUID NOT NULL REFERENCES ratings(id) ON DELETE CASCADE,
+    tag VARCHAR(30) NOT NULL,
+    PRIMARY KEY (rating_id, tag)
+);
+
+-- Indexes
+CREATE INDEX idx_ratings_rated_id_rater_type ON ratings(rated_id, rater_type, created_at DESC);
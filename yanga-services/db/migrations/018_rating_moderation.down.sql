@@ -0,0 +1,18 @@
+-- Drop triggers
+DROP TRIGGER IF EXISTS update_rating_disputes_updated_at ON rating_disputes;
+
+-- Drop indexes
+DROP INDEX IF EXISTS idx_rating_disputes_disputed_by;
+DROP INDEX IF EXISTS idx_rating_disputes_status;
+DROP INDEX IF EXISTS idx_ratings_rater_id_rater_type;
+DROP INDEX IF EXISTS idx_ratings_feedback_flagged;
+
+-- Drop tables
+DROP TABLE IF EXISTS rater_weights;
+DROP TABLE IF EXISTS rating_disputes;
+
+ALTER TABLE ratings DROP COLUMN IF EXISTS exclusion_reason;
+ALTER TABLE ratings DROP COLUMN IF EXISTS excluded_by;
+ALTER TABLE ratings DROP COLUMN IF EXISTS excluded_at;
+ALTER TABLE ratings DROP COLUMN IF EXISTS flag_reasons;
+ALTER TABLE ratings DROP COLUMN IF EXISTS feedback_flagged;
@@ -0,0 +1,45 @@
+-- Moderation state of a rating. Feedback the content filter flags is hidden
+-- from everyone but admins until reviewed; excluded ratings no longer count
+-- towards any average.
+ALTER TABLE ratings ADD COLUMN feedback_flagged BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE ratings ADD COLUMN flag_reasons TEXT;
+ALTER TABLE ratings ADD COLUMN excluded_at TIMESTAMP;
+ALTER TABLE ratings ADD COLUMN excluded_by UUID REFERENCES users(id);
+ALTER TABLE ratings ADD COLUMN exclusion_reason TEXT;
+
+-- A driver contesting a rating they received. Accepting a dispute excludes
+-- the rating.
+CREATE TABLE rating_disputes (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    rating_id UUID NOT NULL UNIQUE REFERENCES ratings(id) ON DELETE CASCADE,
+    disputed_by UUID NOT NULL REFERENCES users(id),
+    reason TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'accepted', 'rejected')),
+    resolution_note TEXT,
+    reviewed_by UUID REFERENCES users(id),
+    reviewed_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- How much each rater's ratings count towards averages. Raters who rate
+-- far below everyone else, such as giving every trip one star, weigh less.
+CREATE TABLE rater_weights (
+    rater_id UUID NOT NULL REFERENCES users(id),
+    rater_type VARCHAR(10) NOT NULL CHECK (rater_type IN ('rider', 'driver')),
+    ratings_given INTEGER NOT NULL DEFAULT 0,
+    average_given DECIMAL(3, 2) NOT NULL DEFAULT 0.00,
+    weight DECIMAL(4, 3) NOT NULL DEFAULT 1.000 CHECK (weight > 0 AND weight <= 1),
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (rater_id, rater_type)
+);
+
+-- Indexes
+CREATE INDEX idx_ratings_feedback_flagged ON ratings(created_at) WHERE feedback_flagged AND excluded_at IS NULL;
+CREATE INDEX idx_ratings_rater_id_rater_type ON ratings(rater_id, rater_type);
+CREATE INDEX idx_rating_disputes_status ON rating_disputes(status, created_at);
+CREATE INDEX idx_rating_disputes_disputed_by ON rating_disputes(disputed_by);
+
+-- Triggers
+CREATE TRIGGER update_rating_disputes_updated_at BEFORE UPDATE ON rating_disputes
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
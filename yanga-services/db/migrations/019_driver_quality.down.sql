@@ -0,0 +1,11 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_driver_quality_actions_driver_id_created_at;
+DROP INDEX IF EXISTS idx_driver_quality_scores_level;
+DROP INDEX IF EXISTS idx_driver_complaints_driver_id_created_at;
+
+-- Drop tables
+DROP TABLE IF EXISTS driver_quality_actions;
+DROP TABLE IF EXISTS driver_quality_scores;
+DROP TABLE IF EXISTS driver_complaints;
+
+ALTER TABLE driver_profiles DROP COLUMN IF EXISTS dispatch_suspended_until;
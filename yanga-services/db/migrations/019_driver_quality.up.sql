@@ -0,0 +1,50 @@
+-- Drivers suspended from dispatch by the quality check can't go online or
+-- be matched until this time passes.
+ALTER TABLE driver_profiles ADD COLUMN dispatch_suspended_until TIMESTAMP;
+
+-- Complaints against a driver, either recorded by an admin or raised by a
+-- rider's rating carrying a complaint tag such as unsafe_driving.
+CREATE TABLE driver_complaints (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    driver_id UUID NOT NULL REFERENCES users(id),
+    trip_id UUID REFERENCES trips(id) ON DELETE SET NULL,
+    rating_id UUID REFERENCES ratings(id) ON DELETE CASCADE,
+    source VARCHAR(20) NOT NULL CHECK (source IN ('rating', 'admin')),
+    category VARCHAR(30) NOT NULL,
+    description TEXT,
+    created_by UUID REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(rating_id, category)
+);
+
+-- Each driver's latest quality score and the components it was built from.
+CREATE TABLE driver_quality_scores (
+    driver_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    score DECIMAL(5, 2) NOT NULL,
+    rating_average DECIMAL(3, 2),
+    acceptance_rate DECIMAL(5, 4),
+    cancellation_rate DECIMAL(5, 4),
+    complaint_count INTEGER NOT NULL DEFAULT 0,
+    completed_trips INTEGER NOT NULL DEFAULT 0,
+    level VARCHAR(20) NOT NULL DEFAULT 'good' CHECK (level IN ('good', 'warning', 'suspended', 'review')),
+    period_start TIMESTAMP NOT NULL,
+    computed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Warnings, suspensions, reviews and reinstatements, newest last.
+CREATE TABLE driver_quality_actions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    driver_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    action VARCHAR(20) NOT NULL CHECK (action IN ('warning', 'suspension', 'review', 'reinstatement')),
+    score DECIMAL(5, 2),
+    reasons TEXT,
+    suspended_until TIMESTAMP,
+    note TEXT,
+    created_by UUID REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Indexes
+CREATE INDEX idx_driver_complaints_driver_id_created_at ON driver_complaints(driver_id, created_at);
+CREATE INDEX idx_driver_quality_scores_level ON driver_quality_scores(level);
+CREATE INDEX idx_driver_quality_actions_driver_id_created_at ON driver_quality_actions(driver_id, created_at);
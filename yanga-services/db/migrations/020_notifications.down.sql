@@ -0,0 +1,15 @@
+-- Drop triggers
+DROP TRIGGER IF EXISTS update_notification_deliveries_updated_at ON notification_deliveries;
+DROP TRIGGER IF EXISTS update_notification_preferences_updated_at ON notification_preferences;
+DROP TRIGGER IF EXISTS update_device_tokens_updated_at ON device_tokens;
+
+-- Drop indexes
+DROP INDEX IF EXISTS idx_inbox_messages_user_id_created_at;
+DROP INDEX IF EXISTS idx_notification_deliveries_due;
+DROP INDEX IF EXISTS idx_device_tokens_user_id;
+
+-- Drop tables
+DROP TABLE IF EXISTS inbox_messages;
+DROP TABLE IF EXISTS notification_deliveries;
+DROP TABLE IF EXISTS notification_preferences;
+DROP TABLE IF EXISTS device_tokens;
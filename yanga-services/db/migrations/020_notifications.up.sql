@@ -0,0 +1,76 @@
+-- Push tokens registered by the rider and driver apps. A token belongs to
+-- whoever registered it last, so signing in as someone else on a device
+-- moves it.
+CREATE TABLE device_tokens (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    token VARCHAR(512) UNIQUE NOT NULL,
+    platform VARCHAR(10) NOT NULL CHECK (platform IN ('ios', 'android')),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- How each user wants to be notified. Users without a row get the defaults.
+-- Quiet hours are minutes after midnight in the user's timezone.
+CREATE TABLE notification_preferences (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    push_enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    sms_enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    locale VARCHAR(10),
+    timezone VARCHAR(64),
+    quiet_hours_start SMALLINT CHECK (quiet_hours_start BETWEEN 0 AND 1439),
+    quiet_hours_end SMALLINT CHECK (quiet_hours_end BETWEEN 0 AND 1439),
+    muted_categories TEXT[] NOT NULL DEFAULT '{}',
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- One rendered notification on its way to one address on one channel.
+-- event_key identifies the event it was sent for, so redelivered events
+-- aren't sent twice.
+CREATE TABLE notification_deliveries (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    event_key VARCHAR(255) NOT NULL,
+    template VARCHAR(50) NOT NULL,
+    category VARCHAR(20) NOT NULL,
+    channel VARCHAR(10) NOT NULL CHECK (channel IN ('push', 'sms', 'inbox')),
+    address VARCHAR(512) NOT NULL DEFAULT '',
+    platform VARCHAR(10),
+    title VARCHAR(255) NOT NULL,
+    body TEXT NOT NULL,
+    data JSONB,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'sent', 'failed')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    sent_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(event_key, user_id, channel, address)
+);
+
+-- The in-app inbox.
+CREATE TABLE inbox_messages (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    delivery_id UUID UNIQUE REFERENCES notification_deliveries(id) ON DELETE SET NULL,
+    category VARCHAR(20) NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    body TEXT NOT NULL,
+    data JSONB,
+    read_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Indexes
+CREATE INDEX idx_device_tokens_user_id ON device_tokens(user_id);
+CREATE INDEX idx_notification_deliveries_due ON notification_deliveries(next_attempt_at) WHERE status = 'pending';
+CREATE INDEX idx_inbox_messages_user_id_created_at ON inbox_messages(user_id, created_at);
+
+-- Triggers
+CREATE TRIGGER update_device_tokens_updated_at BEFORE UPDATE ON device_tokens
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+CREATE TRIGGER update_notification_preferences_updated_at BEFORE UPDATE ON notification_preferences
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+CREATE TRIGGER update_notification_deliveries_updated_at BEFORE UPDATE ON notification_deliveries
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,7 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_trip_call_sessions_open;
+DROP INDEX IF EXISTS idx_trip_messages_trip_id_created_at;
+
+-- Drop tables
+DROP TABLE IF EXISTS trip_call_sessions;
+DROP TABLE IF EXISTS trip_messages;
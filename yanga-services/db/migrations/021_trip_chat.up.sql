@@ -0,0 +1,31 @@
+-- Messages between a trip's rider and driver while the trip is under way.
+-- client_id is chosen by the app so a message resent after a dropped
+-- connection is stored once.
+CREATE TABLE trip_messages (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    trip_id UUID NOT NULL REFERENCES trips(id) ON DELETE CASCADE,
+    sender_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    sender_role VARCHAR(10) NOT NULL CHECK (sender_role IN ('rider', 'driver')),
+    body TEXT NOT NULL,
+    quick_reply VARCHAR(50),
+    client_id VARCHAR(64),
+    read_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(trip_id, sender_id, client_id)
+);
+
+-- Proxy numbers the rider and driver call each other through, so neither
+-- sees the other's real number. A trip has at most one open session.
+CREATE TABLE trip_call_sessions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    trip_id UUID NOT NULL REFERENCES trips(id) ON DELETE CASCADE,
+    provider_session_id VARCHAR(100) NOT NULL,
+    proxy_number VARCHAR(20) NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    closed_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Indexes
+CREATE INDEX idx_trip_messages_trip_id_created_at ON trip_messages(trip_id, created_at);
+CREATE UNIQUE INDEX idx_trip_call_sessions_open ON trip_call_sessions(trip_id) WHERE closed_at IS NULL;
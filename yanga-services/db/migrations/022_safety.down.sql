@@ -0,0 +1,15 @@
+-- Drop triggers
+DROP TRIGGER IF EXISTS update_trip_safety_tracks_updated_at ON trip_safety_tracks;
+DROP TRIGGER IF EXISTS update_safety_incidents_updated_at ON safety_incidents;
+
+-- Drop indexes
+DROP INDEX IF EXISTS idx_safety_incidents_status_created_at;
+DROP INDEX IF EXISTS idx_safety_incidents_unresolved;
+DROP INDEX IF EXISTS idx_trip_share_links_trip_id;
+DROP INDEX IF EXISTS idx_emergency_contacts_user_id;
+
+-- Drop tables
+DROP TABLE IF EXISTS trip_safety_tracks;
+DROP TABLE IF EXISTS safety_incidents;
+DROP TABLE IF EXISTS trip_share_links;
+DROP TABLE IF EXISTS emergency_contacts;
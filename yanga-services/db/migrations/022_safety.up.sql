@@ -0,0 +1,72 @@
+-- People a user wants told when they raise an SOS. They don't need an
+-- account; they get an SMS with a link to follow the trip.
+CREATE TABLE emergency_contacts (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    phone_number VARCHAR(20) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(user_id, phone_number)
+);
+
+-- Links that let someone without an account follow a trip until they
+-- expire. Only a hash of the token is kept.
+CREATE TABLE trip_share_links (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    trip_id UUID NOT NULL REFERENCES trips(id) ON DELETE CASCADE,
+    created_by UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    expires_at TIMESTAMP NOT NULL,
+    revoked_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- The safety team's queue: SOS raised by a rider or driver, and anomalies
+-- detected on trips under way. snapshot is the trip as it stood when the
+-- incident was raised. A trip has at most one unresolved incident of each
+-- kind.
+CREATE TABLE safety_incidents (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    trip_id UUID NOT NULL REFERENCES trips(id) ON DELETE CASCADE,
+    reporter_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('sos', 'route_deviation', 'long_stop', 'over_eta')),
+    status VARCHAR(20) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'acknowledged', 'resolved')),
+    latitude DECIMAL(10, 8),
+    longitude DECIMAL(11, 8),
+    details TEXT,
+    snapshot JSONB NOT NULL,
+    acknowledged_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    acknowledged_at TIMESTAMP,
+    resolved_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    resolved_at TIMESTAMP,
+    resolution_note TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Where each trip under way was last seen, for anomaly detection.
+-- planned_polyline is the route the trip is expected to follow, cleared
+-- when the rider changes their stops. The anchor is where the driver was
+-- when they last moved further than a short distance.
+CREATE TABLE trip_safety_tracks (
+    trip_id UUID PRIMARY KEY REFERENCES trips(id) ON DELETE CASCADE,
+    planned_polyline TEXT,
+    anchor_latitude DECIMAL(10, 8) NOT NULL,
+    anchor_longitude DECIMAL(11, 8) NOT NULL,
+    anchored_at TIMESTAMP NOT NULL,
+    last_seen_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Indexes
+CREATE INDEX idx_emergency_contacts_user_id ON emergency_contacts(user_id);
+CREATE INDEX idx_trip_share_links_trip_id ON trip_share_links(trip_id);
+CREATE UNIQUE INDEX idx_safety_incidents_unresolved ON safety_incidents(trip_id, kind) WHERE status <> 'resolved';
+CREATE INDEX idx_safety_incidents_status_created_at ON safety_incidents(status, created_at);
+
+-- Triggers
+CREATE TRIGGER update_safety_incidents_updated_at BEFORE UPDATE ON safety_incidents
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+CREATE TRIGGER update_trip_safety_tracks_updated_at BEFORE UPDATE ON trip_safety_tracks
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,29 @@
+-- Drop triggers
+DROP TRIGGER IF EXISTS update_support_tickets_updated_at ON support_tickets;
+
+-- Drop indexes
+DROP INDEX IF EXISTS idx_support_attachments_ticket_id;
+DROP INDEX IF EXISTS idx_support_messages_ticket_id_created_at;
+DROP INDEX IF EXISTS idx_support_tickets_unresolved;
+DROP INDEX IF EXISTS idx_support_tickets_assigned_to;
+DROP INDEX IF EXISTS idx_support_tickets_status_priority;
+DROP INDEX IF EXISTS idx_support_tickets_driver_id;
+DROP INDEX IF EXISTS idx_support_tickets_user_id_created_at;
+
+-- Complaints from support go with the tickets they came from
+DELETE FROM driver_complaints WHERE source = 'support';
+ALTER TABLE driver_complaints DROP CONSTRAINT driver_complaints_source_check;
+ALTER TABLE driver_complaints ADD CONSTRAINT driver_complaints_source_check
+    CHECK (source IN ('rating', 'admin'));
+ALTER TABLE driver_complaints DROP COLUMN IF EXISTS ticket_id;
+
+-- The ledger is append-only, so existing fare_adjustment rows are kept and
+-- the narrower check only applies to new rows
+ALTER TABLE ledger_transactions DROP CONSTRAINT ledger_transactions_transaction_type_check;
+ALTER TABLE ledger_transactions ADD CONSTRAINT ledger_transactions_transaction_type_check
+    CHECK (transaction_type IN ('top_up', 'trip_charge', 'refund', 'promo_credit', 'transfer', 'cancellation_fee')) NOT VALID;
+
+-- Drop tables
+DROP TABLE IF EXISTS support_attachments;
+DROP TABLE IF EXISTS support_messages;
+DROP TABLE IF EXISTS support_tickets;
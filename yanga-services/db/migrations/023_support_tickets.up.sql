@@ -0,0 +1,82 @@
+-- Support tickets opened by riders and drivers, usually about a trip. The
+-- SLA deadlines are set from the priority when the ticket is opened, and
+-- the *_breached_at columns record when each was missed. A ticket resolved
+-- with a refund or fare adjustment is settled by payment-service, which
+-- reports back the ledger transaction or why it failed.
+CREATE TABLE support_tickets (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    trip_id UUID REFERENCES trips(id) ON DELETE SET NULL,
+    -- The trip's driver, who can follow and reply on a lost item report
+    driver_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    category VARCHAR(30) NOT NULL CHECK (category IN ('fare_dispute', 'lost_item', 'safety', 'driver_behavior', 'rider_behavior', 'payment', 'account', 'other')),
+    priority VARCHAR(10) NOT NULL CHECK (priority IN ('low', 'normal', 'high', 'urgent')),
+    status VARCHAR(20) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'awaiting_user', 'resolved')),
+    subject VARCHAR(200) NOT NULL,
+    assigned_to UUID REFERENCES users(id) ON DELETE SET NULL,
+    assigned_at TIMESTAMP,
+    first_response_due_at TIMESTAMP NOT NULL,
+    first_responded_at TIMESTAMP,
+    first_response_breached_at TIMESTAMP,
+    resolution_due_at TIMESTAMP NOT NULL,
+    resolution_breached_at TIMESTAMP,
+    resolution VARCHAR(20) CHECK (resolution IN ('none', 'refund', 'fare_adjustment')),
+    resolution_note TEXT,
+    adjustment_amount DECIMAL(10, 2),
+    adjustment_status VARCHAR(20) CHECK (adjustment_status IN ('pending', 'applied', 'failed')),
+    adjustment_transaction_id UUID REFERENCES ledger_transactions(id),
+    adjustment_error TEXT,
+    resolved_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    resolved_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- The thread of a ticket. Internal notes are between agents only.
+CREATE TABLE support_messages (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    ticket_id UUID NOT NULL REFERENCES support_tickets(id) ON DELETE CASCADE,
+    author_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    author_role VARCHAR(10) NOT NULL CHECK (author_role IN ('user', 'driver', 'agent')),
+    body TEXT NOT NULL,
+    internal BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Files attached to a ticket, such as receipts and photos. The content is
+-- in the blob store under blob_key.
+CREATE TABLE support_attachments (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    ticket_id UUID NOT NULL REFERENCES support_tickets(id) ON DELETE CASCADE,
+    uploaded_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    file_name VARCHAR(255) NOT NULL,
+    content_type VARCHAR(100) NOT NULL,
+    size_bytes BIGINT NOT NULL CHECK (size_bytes > 0),
+    blob_key VARCHAR(255) NOT NULL UNIQUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Fare adjustments credit the rider the difference between what they were
+-- charged and what the fare should have been
+ALTER TABLE ledger_transactions DROP CONSTRAINT ledger_transactions_transaction_type_check;
+ALTER TABLE ledger_transactions ADD CONSTRAINT ledger_transactions_transaction_type_check
+    CHECK (transaction_type IN ('top_up', 'trip_charge', 'refund', 'promo_credit', 'transfer', 'cancellation_fee', 'fare_adjustment'));
+
+-- Complaints upheld when a support ticket about a driver is resolved
+ALTER TABLE driver_complaints ADD COLUMN ticket_id UUID UNIQUE REFERENCES support_tickets(id) ON DELETE CASCADE;
+ALTER TABLE driver_complaints DROP CONSTRAINT driver_complaints_source_check;
+ALTER TABLE driver_complaints ADD CONSTRAINT driver_complaints_source_check
+    CHECK (source IN ('rating', 'admin', 'support'));
+
+-- Indexes
+CREATE INDEX idx_support_tickets_user_id_created_at ON support_tickets(user_id, created_at);
+CREATE INDEX idx_support_tickets_driver_id ON support_tickets(driver_id) WHERE driver_id IS NOT NULL;
+CREATE INDEX idx_support_tickets_status_priority ON support_tickets(status, priority, created_at);
+CREATE INDEX idx_support_tickets_assigned_to ON support_tickets(assigned_to) WHERE assigned_to IS NOT NULL;
+CREATE UNIQUE INDEX idx_support_tickets_unresolved ON support_tickets(user_id, trip_id, category) WHERE status <> 'resolved';
+CREATE INDEX idx_support_messages_ticket_id_created_at ON support_messages(ticket_id, created_at);
+CREATE INDEX idx_support_attachments_ticket_id ON support_attachments(ticket_id);
+
+-- Triggers
+CREATE TRIGGER update_support_tickets_updated_at BEFORE UPDATE ON support_tickets
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
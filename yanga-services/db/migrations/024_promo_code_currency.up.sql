@@ -0,0 +1,8 @@
+-- Fixed discounts, discount caps and minimum fares on a promo code are in
+-- the currency it was created in. Codes for a city take that city's
+-- currency; codes for every city were created in shillings.
+ALTER TABLE promo_codes ADD COLUMN currency VARCHAR(3) NOT NULL DEFAULT 'KES';
+
+UPDATE promo_codes p SET currency = c.currency
+FROM cities c
+WHERE p.city_code = c.code;
@@ -0,0 +1,37 @@
+-- name: JoinAirportQueue :execrows
+-- A driver moving between staging lots of the same airport keeps their place.
+INSERT INTO airport_queue_entries (driver_id, airport_id, staging_id)
+VALUES ($1, $2, $3)
+ON CONFLICT (driver_id) DO UPDATE
+SET staging_id = EXCLUDED.staging_id
+WHERE airport_queue_entries.airport_id = EXCLUDED.airport_id;
+
+-- name: LeaveStagingArea :execrows
+DELETE FROM airport_queue_entries
+WHERE driver_id = $1 AND staging_id = $2;
+
+-- name: LeaveAirportQueue :execrows
+DELETE FROM airport_queue_entries
+WHERE driver_id = $1;
+
+-- name: GetAirportQueuePosition :one
+SELECT
+    e.driver_id,
+    e.airport_id,
+    e.staging_id,
+    e.joined_at,
+    g.name AS airport_name,
+    (SELECT COUNT(*) FROM airport_queue_entries o
+     WHERE o.airport_id = e.airport_id
+       AND (o.joined_at, o.driver_id) <= (e.joined_at, e.driver_id))::bigint AS position,
+    (SELECT COUNT(*) FROM airport_queue_entries o
+     WHERE o.airport_id = e.airport_id)::bigint AS queue_length
+FROM airport_queue_entries e
+JOIN geofences g ON g.id = e.airport_id
+WHERE e.driver_id = $1;
+
+-- name: ListAirportQueue :many
+SELECT * FROM airport_queue_entries
+WHERE airport_id = $1
+ORDER BY joined_at, driver_id
+LIMIT $2;
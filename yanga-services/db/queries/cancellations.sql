@@ -0,0 +1,59 @@
+-- name: GetCancellationPolicy :one
+-- Falls back to the 'default' policy when the city has none.
+SELECT * FROM cancellation_policies
+WHERE city_code = $1 OR city_code = 'default'
+ORDER BY (city_code = 'default')
+LIMIT 1;
+
+-- name: ListCancellationPolicies :many
+SELECT * FROM cancellation_policies
+ORDER BY city_code;
+
+-- name: UpsertCancellationPolicy :one
+INSERT INTO cancellation_policies (
+    city_code,
+    free_cancellation_seconds,
+    late_cancellation_fee,
+    driver_nearby_meters,
+    driver_arrived_fee,
+    rider_no_show_wait_seconds,
+    rider_no_show_fee,
+    driver_no_show_wait_seconds,
+    match_timeout_seconds,
+    updated_by
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10
+)
+ON CONFLICT (city_code) DO UPDATE SET
+    free_cancellation_seconds = EXCLUDED.free_cancellation_seconds,
+    late_cancellation_fee = EXCLUDED.late_cancellation_fee,
+    driver_nearby_meters = EXCLUDED.driver_nearby_meters,
+    driver_arrived_fee = EXCLUDED.driver_arrived_fee,
+    rider_no_show_wait_seconds = EXCLUDED.rider_no_show_wait_seconds,
+    rider_no_show_fee = EXCLUDED.rider_no_show_fee,
+    driver_no_show_wait_seconds = EXCLUDED.driver_no_show_wait_seconds,
+    match_timeout_seconds = EXCLUDED.match_timeout_seconds,
+    updated_by = EXCLUDED.updated_by,
+    updated_at = CURRENT_TIMESTAMP
+RETURNING *;
+
+-- name: DeleteCancellationPolicy :execrows
+DELETE FROM cancellation_policies
+WHERE city_code = $1 AND city_code <> 'default';
+
+-- name: GetExpiredPendingTrips :many
+-- Pending trips nobody accepted within their city's match timeout. Scheduled
+-- trips are timed from dispatch rather than booking.
+SELECT t.* FROM trips t
+JOIN cancellation_policies p ON p.city_code = COALESCE(
+    (SELECT cp.city_code FROM cancellation_policies cp WHERE cp.city_code = t.city_code),
+    'default'
+)
+WHERE t.status = 'pending'
+  AND COALESCE(t.dispatched_at, t.created_at) < CURRENT_TIMESTAMP - p.match_timeout_seconds * INTERVAL '1 second'
+ORDER BY COALESCE(t.dispatched_at, t.created_at)
+LIMIT $1;
+
+-- name: GetDriverLocation :one
+SELECT current_latitude, current_longitude FROM driver_profiles
+WHERE user_id = $1 LIMIT 1;
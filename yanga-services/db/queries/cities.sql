@@ -0,0 +1,47 @@
+-- name: GetCity :one
+SELECT * FROM cities
+WHERE code = $1 LIMIT 1;
+
+-- name: ListCities :many
+SELECT * FROM cities
+ORDER BY name;
+
+-- name: UpsertCity :one
+INSERT INTO cities (
+    code,
+    name,
+    currency,
+    timezone,
+    base_fare,
+    per_km_rate,
+    per_stop_fee,
+    delivery_base_fare,
+    delivery_per_km_rate,
+    pooling_enabled,
+    deliveries_enabled,
+    scheduling_enabled,
+    stops_enabled,
+    active
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14
+)
+ON CONFLICT (code) DO UPDATE SET
+    name = EXCLUDED.name,
+    currency = EXCLUDED.currency,
+    timezone = EXCLUDED.timezone,
+    base_fare = EXCLUDED.base_fare,
+    per_km_rate = EXCLUDED.per_km_rate,
+    per_stop_fee = EXCLUDED.per_stop_fee,
+    delivery_base_fare = EXCLUDED.delivery_base_fare,
+    delivery_per_km_rate = EXCLUDED.delivery_per_km_rate,
+    pooling_enabled = EXCLUDED.pooling_enabled,
+    deliveries_enabled = EXCLUDED.deliveries_enabled,
+    scheduling_enabled = EXCLUDED.scheduling_enabled,
+    stops_enabled = EXCLUDED.stops_enabled,
+    active = EXCLUDED.active,
+    updated_at = CURRENT_TIMESTAMP
+RETURNING *;
+
+-- name: GetDriverCityCode :one
+SELECT city_code FROM driver_profiles
+WHERE user_id = $1 LIMIT 1;
@@ -0,0 +1,39 @@
+-- name: CreateParcelDelivery :one
+INSERT INTO parcel_deliveries (
+    trip_id,
+    sender_name,
+    sender_phone,
+    recipient_name,
+    recipient_phone,
+    parcel_size,
+    weight_kg,
+    description,
+    instructions,
+    recipient_pin
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10
+) RETURNING *;
+
+-- name: GetParcelDelivery :one
+SELECT * FROM parcel_deliveries
+WHERE trip_id = $1 LIMIT 1;
+
+-- name: RecordParcelPickup :execrows
+UPDATE parcel_deliveries
+SET pickup_photo_ref = $2, picked_up_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+WHERE trip_id = $1 AND picked_up_at IS NULL;
+
+-- name: RecordParcelDelivery :execrows
+UPDATE parcel_deliveries
+SET delivery_photo_ref = $2, delivered_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+WHERE trip_id = $1 AND picked_up_at IS NOT NULL AND delivered_at IS NULL;
+
+-- name: IncrementParcelPinAttempts :one
+UPDATE parcel_deliveries
+SET pin_attempts = pin_attempts + 1, updated_at = CURRENT_TIMESTAMP
+WHERE trip_id = $1
+RETURNING pin_attempts;
+
+-- name: GetDriverVehicleType :one
+SELECT vehicle_type FROM driver_profiles
+WHERE user_id = $1 LIMIT 1;
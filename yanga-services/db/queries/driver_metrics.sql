@@ -0,0 +1,20 @@
+-- name: GetDriverRequestStats :one
+SELECT
+    COUNT(*) AS offered,
+    COUNT(*) FILTER (WHERE status = 'accepted') AS accepted,
+    COUNT(*) FILTER (WHERE status = 'rejected') AS rejected,
+    COUNT(*) FILTER (WHERE status = 'expired') AS expired
+FROM ride_requests
+WHERE driver_id = sqlc.arg('driver_id') AND created_at >= sqlc.arg('since')::timestamp;
+
+-- name: GetDriverTripStats :one
+-- Rider no-shows reported by the driver don't count against the driver.
+SELECT
+    COUNT(*) AS accepted_trips,
+    COUNT(*) FILTER (WHERE status = 'completed') AS completed_trips,
+    COUNT(*) FILTER (WHERE cancelled_by = 'driver' AND no_show_party IS NULL) AS driver_cancellations,
+    COUNT(*) FILTER (WHERE no_show_party = 'driver') AS driver_no_shows,
+    COUNT(*) FILTER (WHERE no_show_party = 'rider') AS rider_no_shows,
+    COUNT(*) FILTER (WHERE cancelled_by = 'rider' AND no_show_party IS NULL) AS rider_cancellations
+FROM trips
+WHERE driver_id = sqlc.arg('driver_id') AND created_at >= sqlc.arg('since')::timestamp;
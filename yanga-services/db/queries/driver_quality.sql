@@ -0,0 +1,138 @@
+-- name: GetDriverRatingSince :one
+-- A driver's rating average over ratings received since a time, weighted
+-- by rater the same way as their rating aggregates.
+SELECT
+    COUNT(*) AS rating_count,
+    COALESCE(SUM(e.rating * COALESCE(w.weight, 1)) / NULLIF(SUM(COALESCE(w.weight, 1)), 0), 0)::float8 AS rating_average
+FROM driver_rating_entries e
+JOIN ratings r ON r.id = e.rating_id
+LEFT JOIN rater_weights w ON w.rater_id = r.rater_id AND w.rater_type = r.rater_type
+WHERE e.driver_id = sqlc.arg('driver_id')
+  AND e.rated_at >= sqlc.arg('since')::timestamp
+  AND r.excluded_at IS NULL;
+
+-- name: CountDriverComplaintsSince :one
+-- Complaints raised by a rating stop counting if the rating is excluded.
+SELECT COUNT(*)
+FROM driver_complaints c
+LEFT JOIN ratings r ON r.id = c.rating_id
+WHERE c.driver_id = sqlc.arg('driver_id')
+  AND c.created_at >= sqlc.arg('since')::timestamp
+  AND r.excluded_at IS NULL;
+
+-- name: RecordRatingComplaint :execrows
+INSERT INTO driver_complaints (driver_id, trip_id, rating_id, source, category)
+VALUES ($1, $2, $3, 'rating', $4)
+ON CONFLICT (rating_id, category) DO NOTHING;
+
+-- name: CreateDriverComplaint :one
+INSERT INTO driver_complaints (
+    driver_id,
+    trip_id,
+    source,
+    category,
+    description,
+    created_by
+) VALUES (
+    $1, $2, 'admin', $3, $4, $5
+) RETURNING *;
+
+-- name: RecordSupportComplaint :execrows
+INSERT INTO driver_complaints (driver_id, trip_id, ticket_id, source, category, description, created_by)
+VALUES ($1, $2, $3, 'support', $4, $5, $6)
+ON CONFLICT (ticket_id) DO NOTHING;
+
+-- name: ListDriverComplaints :many
+SELECT * FROM driver_complaints
+WHERE driver_id = $1
+ORDER BY created_at DESC
+LIMIT $2;
+
+-- name: GetDriverQualityScore :one
+SELECT * FROM driver_quality_scores
+WHERE driver_id = $1;
+
+-- name: UpsertDriverQualityScore :one
+INSERT INTO driver_quality_scores (
+    driver_id,
+    score,
+    rating_average,
+    acceptance_rate,
+    cancellation_rate,
+    complaint_count,
+    completed_trips,
+    level,
+    period_start,
+    computed_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, CURRENT_TIMESTAMP
+)
+ON CONFLICT (driver_id) DO UPDATE
+SET score = EXCLUDED.score,
+    rating_average = EXCLUDED.rating_average,
+    acceptance_rate = EXCLUDED.acceptance_rate,
+    cancellation_rate = EXCLUDED.cancellation_rate,
+    complaint_count = EXCLUDED.complaint_count,
+    completed_trips = EXCLUDED.completed_trips,
+    level = EXCLUDED.level,
+    period_start = EXCLUDED.period_start,
+    computed_at = EXCLUDED.computed_at
+RETURNING *;
+
+-- name: SetDriverQualityLevel :exec
+UPDATE driver_quality_scores
+SET level = $2
+WHERE driver_id = $1;
+
+-- name: ListDriverQualityScoresByLevel :many
+SELECT q.*, u.full_name
+FROM driver_quality_scores q
+JOIN users u ON u.id = q.driver_id
+WHERE q.level = $1
+ORDER BY q.score, q.driver_id
+LIMIT $2 OFFSET $3;
+
+-- name: CreateDriverQualityAction :one
+INSERT INTO driver_quality_actions (
+    driver_id,
+    action,
+    score,
+    reasons,
+    suspended_until,
+    note,
+    created_by
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7
+) RETURNING *;
+
+-- name: GetLatestDriverQualityAction :one
+SELECT * FROM driver_quality_actions
+WHERE driver_id = $1 AND action = $2
+ORDER BY created_at DESC
+LIMIT 1;
+
+-- name: ListDriverQualityActions :many
+SELECT * FROM driver_quality_actions
+WHERE driver_id = $1
+ORDER BY created_at DESC
+LIMIT $2;
+
+-- name: GetDriverDispatchStatus :one
+SELECT is_approved, dispatch_suspended_until FROM driver_profiles
+WHERE user_id = $1;
+
+-- name: SuspendDriverDispatch :exec
+-- Suspended drivers are also taken offline.
+UPDATE driver_profiles
+SET dispatch_suspended_until = $2, is_online = false, updated_at = CURRENT_TIMESTAMP
+WHERE user_id = $1;
+
+-- name: DeactivateDriver :exec
+UPDATE driver_profiles
+SET is_approved = false, is_online = false, updated_at = CURRENT_TIMESTAMP
+WHERE user_id = $1;
+
+-- name: ReinstateDriver :exec
+UPDATE driver_profiles
+SET is_approved = true, dispatch_suspended_until = NULL, updated_at = CURRENT_TIMESTAMP
+WHERE user_id = $1;
@@ -0,0 +1,85 @@
+-- name: RecordDriverRating :execrows
+-- Counts a rating towards a driver if it was given to the driver of the
+-- rated trip. Ratings already counted are ignored.
+INSERT INTO driver_rating_entries (rating_id, driver_id, trip_id, rating, rated_at)
+SELECT r.id, r.rated_id, r.trip_id, r.rating, COALESCE(r.created_at, CURRENT_TIMESTAMP)
+FROM ratings r
+JOIN trips t ON t.id = r.trip_id AND t.driver_id = r.rated_id
+WHERE r.id = sqlc.arg('rating_id')
+ON CONFLICT (rating_id) DO NOTHING;
+
+-- name: BackfillDriverRatingEntries :execrows
+-- Brings driver_rating_entries in line with the ratings table, including
+-- ratings that were edited after they were counted.
+INSERT INTO driver_rating_entries (rating_id, driver_id, trip_id, rating, rated_at)
+SELECT r.id, r.rated_id, r.trip_id, r.rating, COALESCE(r.created_at, CURRENT_TIMESTAMP)
+FROM ratings r
+JOIN trips t ON t.id = r.trip_id AND t.driver_id = r.rated_id
+ON CONFLICT (rating_id) DO UPDATE
+SET rating = EXCLUDED.rating
+WHERE driver_rating_entries.rating <> EXCLUDED.rating;
+
+-- name: RefreshDriverRatingStats :one
+-- Ratings an admin excluded don't count. Averages weigh each rating by its
+-- rater's weight; counts and the star distribution don't.
+WITH counted AS (
+    SELECT e.rating, e.rated_at, COALESCE(w.weight, 1) as weight
+    FROM driver_rating_entries e
+    JOIN ratings r ON r.id = e.rating_id
+    LEFT JOIN rater_weights w ON w.rater_id = r.rater_id AND w.rater_type = r.rater_type
+    WHERE e.driver_id = sqlc.arg('driver_id') AND r.excluded_at IS NULL
+), rolling AS (
+    SELECT rating, weight FROM counted
+    ORDER BY rated_at DESC
+    LIMIT sqlc.arg('window_size')
+)
+INSERT INTO driver_rating_stats (
+    driver_id,
+    rating_count,
+    lifetime_average,
+    rolling_count,
+    rolling_average,
+    one_star,
+    two_star,
+    three_star,
+    four_star,
+    five_star,
+    updated_at
+)
+SELECT
+    sqlc.arg('driver_id')::uuid,
+    COUNT(*),
+    COALESCE(SUM(rating * weight) / NULLIF(SUM(weight), 0), 0),
+    (SELECT COUNT(*) FROM rolling),
+    COALESCE((SELECT SUM(rating * weight) / NULLIF(SUM(weight), 0) FROM rolling), 0),
+    COUNT(*) FILTER (WHERE rating = 1),
+    COUNT(*) FILTER (WHERE rating = 2),
+    COUNT(*) FILTER (WHERE rating = 3),
+    COUNT(*) FILTER (WHERE rating = 4),
+    COUNT(*) FILTER (WHERE rating = 5),
+    CURRENT_TIMESTAMP
+FROM counted
+ON CONFLICT (driver_id) DO UPDATE
+SET rating_count = EXCLUDED.rating_count,
+    lifetime_average = EXCLUDED.lifetime_average,
+    rolling_count = EXCLUDED.rolling_count,
+    rolling_average = EXCLUDED.rolling_average,
+    one_star = EXCLUDED.one_star,
+    two_star = EXCLUDED.two_star,
+    three_star = EXCLUDED.three_star,
+    four_star = EXCLUDED.four_star,
+    five_star = EXCLUDED.five_star,
+    updated_at = EXCLUDED.updated_at
+RETURNING *;
+
+-- name: GetDriverRatingStats :one
+SELECT * FROM driver_rating_stats
+WHERE driver_id = $1;
+
+-- name: CountDriverCompletedTrips :one
+SELECT COUNT(*) FROM trips
+WHERE driver_id = $1 AND status = 'completed';
+
+-- name: ListDriverUserIDs :many
+SELECT user_id FROM driver_profiles
+ORDER BY user_id;
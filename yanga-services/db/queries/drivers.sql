@@ -5,9 +5,10 @@ INSERT INTO driver_profiles (
     vehicle_type,
     vehicle_model,
     vehicle_color,
-    vehicle_plate_number
+    vehicle_plate_number,
+    city_code
 ) VALUES (
-    $1, $2, $3, $4, $5, $6
+    $1, $2, $3, $4, $5, $6, $7
 ) RETURNING *;
 
 -- name: GetDriverProfile :one
@@ -40,6 +41,7 @@ SET
     vehicle_color = COALESCE(sqlc.narg('vehicle_color'), vehicle_color),
     vehicle_plate_number = COALESCE(sqlc.narg('vehicle_plate_number'), vehicle_plate_number),
     is_approved = COALESCE(sqlc.narg('is_approved'), is_approved),
+    city_code = COALESCE(sqlc.narg('city_code'), city_code),
     updated_at = CURRENT_TIMESTAMP
 WHERE id = sqlc.arg('id')
 RETURNING *;
@@ -54,6 +56,7 @@ SELECT dp.*, u.full_name, u.phone_number, u.profile_image_url
 FROM driver_profiles dp
 JOIN users u ON dp.user_id = u.id
 WHERE dp.is_online = TRUE AND dp.is_approved = TRUE
+    AND (dp.dispatch_suspended_until IS NULL OR dp.dispatch_suspended_until <= CURRENT_TIMESTAMP)
 ORDER BY dp.rating DESC
 LIMIT $1 OFFSET $2;
 
@@ -75,17 +78,17 @@ SELECT
     dp.created_at,
     dp.updated_at,
     u.full_name,
-    u.phone_number,
     u.profile_image_url,
     (6371 * acos(
         cos(radians($1)) * cos(radians(dp.current_latitude)) *
         cos(radians(dp.current_longitude) - radians($2)) +
         sin(radians($1)) * sin(radians(dp.current_latitude))
-    )) AS distance
+    ))::float8 AS distance
 FROM driver_profiles dp
 JOIN users u ON dp.user_id = u.id
 WHERE dp.is_online = TRUE 
     AND dp.is_approved = TRUE
+    AND (dp.dispatch_suspended_until IS NULL OR dp.dispatch_suspended_until <= CURRENT_TIMESTAMP)
     AND dp.current_latitude IS NOT NULL
     AND dp.current_longitude IS NOT NULL
     AND (6371 * acos(
@@ -0,0 +1,253 @@
+-- name: CreateCommissionPlan :one
+INSERT INTO commission_plans (
+    name,
+    vehicle_type,
+    commission_rate,
+    tax_rate,
+    effective_from,
+    effective_to,
+    created_by,
+    city_code
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8
+) RETURNING *;
+
+-- name: GetCommissionPlan :one
+SELECT * FROM commission_plans
+WHERE id = $1 LIMIT 1;
+
+-- name: ListCommissionPlans :many
+SELECT * FROM commission_plans
+ORDER BY is_active DESC, city_code NULLS FIRST, vehicle_type NULLS FIRST, effective_from DESC;
+
+-- name: UpdateCommissionPlanStatus :one
+UPDATE commission_plans
+SET is_active = $2, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+RETURNING *;
+
+-- name: GetApplicableCommissionPlan :one
+-- Prefers a plan for the trip's city over one for every city, then a plan
+-- for the driver's vehicle type over the catch-all plan, and the most
+-- recently effective plan within each.
+SELECT * FROM commission_plans
+WHERE is_active = true
+  AND (city_code = sqlc.arg('city_code') OR city_code IS NULL)
+  AND (vehicle_type = sqlc.arg('vehicle_type') OR vehicle_type IS NULL)
+  AND effective_from <= sqlc.arg('at')
+  AND (effective_to IS NULL OR effective_to > sqlc.arg('at'))
+ORDER BY city_code NULLS LAST, vehicle_type NULLS LAST, effective_from DESC
+LIMIT 1;
+
+-- name: CreateDriverEarning :one
+INSERT INTO driver_earnings (
+    trip_id,
+    driver_id,
+    commission_plan_id,
+    gross_fare,
+    commission_rate,
+    commission,
+    tax,
+    tip,
+    net_earnings,
+    payment_method,
+    rider_discount,
+    currency,
+    earned_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13
+)
+ON CONFLICT (trip_id) DO NOTHING
+RETURNING *;
+
+-- name: GetDriverEarningByTrip :one
+SELECT * FROM driver_earnings
+WHERE trip_id = $1 LIMIT 1;
+
+-- name: GetDriverEarnings :many
+SELECT * FROM driver_earnings
+WHERE driver_id = sqlc.arg('driver_id')
+  AND trip_id IS NOT NULL
+  AND earned_at >= sqlc.arg('period_start')::timestamp
+  AND earned_at < sqlc.arg('period_end')::timestamp
+ORDER BY earned_at DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: GetDriverEarningsSummary :one
+SELECT
+    COUNT(trip_id) AS trip_count,
+    COALESCE(SUM(gross_fare), 0)::numeric AS gross_fare,
+    COALESCE(SUM(commission), 0)::numeric AS commission,
+    COALESCE(SUM(tax), 0)::numeric AS tax,
+    COALESCE(SUM(tip), 0)::numeric AS tip,
+    COALESCE(SUM(net_earnings), 0)::numeric AS net_earnings,
+    COALESCE(SUM(net_earnings) FILTER (WHERE incentive_program_id IS NOT NULL), 0)::numeric AS bonuses
+FROM driver_earnings
+WHERE driver_id = sqlc.arg('driver_id')
+  AND earned_at >= sqlc.arg('period_start')::timestamp
+  AND earned_at < sqlc.arg('period_end')::timestamp;
+
+-- name: GetDriverDailyEarnings :many
+SELECT
+    date_trunc('day', earned_at)::timestamp AS period_start,
+    COUNT(trip_id) AS trip_count,
+    COALESCE(SUM(gross_fare), 0)::numeric AS gross_fare,
+    COALESCE(SUM(commission), 0)::numeric AS commission,
+    COALESCE(SUM(tax), 0)::numeric AS tax,
+    COALESCE(SUM(tip), 0)::numeric AS tip,
+    COALESCE(SUM(net_earnings), 0)::numeric AS net_earnings,
+    COALESCE(SUM(net_earnings) FILTER (WHERE incentive_program_id IS NOT NULL), 0)::numeric AS bonuses
+FROM driver_earnings
+WHERE driver_id = sqlc.arg('driver_id')
+  AND earned_at >= sqlc.arg('period_start')::timestamp
+  AND earned_at < sqlc.arg('period_end')::timestamp
+GROUP BY 1
+ORDER BY 1;
+
+-- name: GetDriverWeeklyEarnings :many
+SELECT
+    date_trunc('week', earned_at)::timestamp AS period_start,
+    COUNT(trip_id) AS trip_count,
+    COALESCE(SUM(gross_fare), 0)::numeric AS gross_fare,
+    COALESCE(SUM(commission), 0)::numeric AS commission,
+    COALESCE(SUM(tax), 0)::numeric AS tax,
+    COALESCE(SUM(tip), 0)::numeric AS tip,
+    COALESCE(SUM(net_earnings), 0)::numeric AS net_earnings,
+    COALESCE(SUM(net_earnings) FILTER (WHERE incentive_program_id IS NOT NULL), 0)::numeric AS bonuses
+FROM driver_earnings
+WHERE driver_id = sqlc.arg('driver_id')
+  AND earned_at >= sqlc.arg('period_start')::timestamp
+  AND earned_at < sqlc.arg('period_end')::timestamp
+GROUP BY 1
+ORDER BY 1;
+
+-- name: CreatePayoutBatch :one
+INSERT INTO payout_batches (
+    period_end,
+    created_by
+) VALUES (
+    $1, $2
+) RETURNING *;
+
+-- name: GetPayoutBatch :one
+SELECT * FROM payout_batches
+WHERE id = $1 LIMIT 1;
+
+-- name: ListPayoutBatches :many
+SELECT * FROM payout_batches
+ORDER BY created_at DESC
+LIMIT $1 OFFSET $2;
+
+-- name: LockPayoutBatch :exec
+SELECT 1 FROM payout_batches
+WHERE id = $1
+FOR UPDATE;
+
+-- name: GetPayableDrivers :many
+-- Cash fares were collected by the driver, so they are netted off against
+-- what the platform owes. Drivers who owe money are carried forward.
+SELECT driver_id
+FROM driver_earnings
+WHERE payout_item_id IS NULL
+  AND earned_at < sqlc.arg('period_end')::timestamp
+GROUP BY driver_id
+HAVING SUM(net_earnings - CASE WHEN payment_method = 'cash' THEN gross_fare + tip - rider_discount ELSE 0 END) > 0
+ORDER BY driver_id;
+
+-- name: CreatePayoutItem :one
+INSERT INTO payout_items (
+    batch_id,
+    driver_id,
+    amount,
+    earnings_count
+) VALUES (
+    $1, $2, 0, 0
+) RETURNING *;
+
+-- name: AssignEarningsToPayoutItem :execrows
+UPDATE driver_earnings
+SET payout_item_id = sqlc.arg('payout_item_id')
+WHERE driver_id = sqlc.arg('driver_id')
+  AND payout_item_id IS NULL
+  AND earned_at < sqlc.arg('period_end')::timestamp;
+
+-- name: RefreshPayoutItemTotals :one
+UPDATE payout_items
+SET
+    amount = totals.amount,
+    earnings_count = totals.earnings_count,
+    updated_at = CURRENT_TIMESTAMP
+FROM (
+    SELECT
+        COALESCE(SUM(net_earnings - CASE WHEN payment_method = 'cash' THEN gross_fare + tip - rider_discount ELSE 0 END), 0) AS amount,
+        COUNT(*)::integer AS earnings_count
+    FROM driver_earnings
+    WHERE payout_item_id = sqlc.arg('id')
+) AS totals
+WHERE payout_items.id = sqlc.arg('id')
+RETURNING payout_items.*;
+
+-- name: RefreshPayoutBatchTotals :one
+UPDATE payout_batches
+SET
+    total_amount = totals.total_amount,
+    driver_count = totals.driver_count,
+    updated_at = CURRENT_TIMESTAMP
+FROM (
+    SELECT
+        COALESCE(SUM(amount), 0) AS total_amount,
+        COUNT(*)::integer AS driver_count
+    FROM payout_items
+    WHERE batch_id = sqlc.arg('id')
+) AS totals
+WHERE payout_batches.id = sqlc.arg('id')
+RETURNING payout_batches.*;
+
+-- name: ReleasePayoutItemEarnings :exec
+UPDATE driver_earnings
+SET payout_item_id = NULL
+WHERE payout_item_id = $1;
+
+-- name: DeletePayoutItem :exec
+DELETE FROM payout_items
+WHERE id = $1;
+
+-- name: GetPayoutItem :one
+SELECT * FROM payout_items
+WHERE id = $1 LIMIT 1;
+
+-- name: GetPayoutItemsByBatch :many
+SELECT * FROM payout_items
+WHERE batch_id = $1
+ORDER BY created_at;
+
+-- name: GetDriverPayoutItems :many
+SELECT * FROM payout_items
+WHERE driver_id = $1
+ORDER BY created_at DESC
+LIMIT $2 OFFSET $3;
+
+-- name: UpdatePayoutBatchStatus :one
+UPDATE payout_batches
+SET
+    status = sqlc.arg('status'),
+    failure_reason = sqlc.narg('failure_reason'),
+    processed_at = CASE WHEN sqlc.arg('status') IN ('paid', 'failed') THEN CURRENT_TIMESTAMP ELSE processed_at END,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = sqlc.arg('id')
+RETURNING *;
+
+-- name: UpdatePayoutItemStatus :one
+UPDATE payout_items
+SET
+    status = sqlc.arg('status'),
+    payment_reference = COALESCE(sqlc.narg('payment_reference'), payment_reference),
+    failure_reason = sqlc.narg('failure_reason'),
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = sqlc.arg('id')
+RETURNING *;
+
+-- name: GetOpenPayoutItemsByBatch :many
+SELECT * FROM payout_items
+WHERE batch_id = $1 AND status IN ('pending', 'processing')
+ORDER BY created_at;
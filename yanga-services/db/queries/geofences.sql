@@ -0,0 +1,76 @@
+-- name: CreateGeofence :one
+INSERT INTO geofences (
+    city_code,
+    name,
+    kind,
+    polygon,
+    min_latitude,
+    max_latitude,
+    min_longitude,
+    max_longitude,
+    pickup_fee,
+    dropoff_fee,
+    active,
+    created_by,
+    parent_id
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13
+) RETURNING *;
+
+-- name: GetGeofence :one
+SELECT * FROM geofences
+WHERE id = $1 LIMIT 1;
+
+-- name: ListGeofences :many
+SELECT * FROM geofences
+WHERE (sqlc.narg('city_code')::varchar IS NULL OR city_code = sqlc.narg('city_code'))
+  AND (sqlc.narg('kind')::varchar IS NULL OR kind = sqlc.narg('kind'))
+ORDER BY city_code, kind, name;
+
+-- name: UpdateGeofence :one
+UPDATE geofences
+SET
+    city_code = $2,
+    name = $3,
+    kind = $4,
+    polygon = $5,
+    min_latitude = $6,
+    max_latitude = $7,
+    min_longitude = $8,
+    max_longitude = $9,
+    pickup_fee = $10,
+    dropoff_fee = $11,
+    active = $12,
+    parent_id = $13,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteGeofence :execrows
+DELETE FROM geofences
+WHERE id = $1;
+
+-- name: GetGeofencesAround :many
+-- Active geofences whose bounding box holds the point; callers still test
+-- the polygon itself.
+SELECT * FROM geofences
+WHERE active
+  AND min_latitude <= sqlc.arg('latitude')::numeric AND max_latitude >= sqlc.arg('latitude')::numeric
+  AND min_longitude <= sqlc.arg('longitude')::numeric AND max_longitude >= sqlc.arg('longitude')::numeric;
+
+-- name: CountActiveServiceAreas :one
+SELECT COUNT(*) FROM geofences
+WHERE active AND kind = 'service_area';
+
+-- name: GetDriverGeofences :many
+SELECT * FROM geofences
+WHERE id IN (SELECT geofence_id FROM driver_geofences WHERE driver_id = $1);
+
+-- name: EnterGeofence :execrows
+INSERT INTO driver_geofences (driver_id, geofence_id)
+VALUES ($1, $2)
+ON CONFLICT DO NOTHING;
+
+-- name: ExitGeofence :execrows
+DELETE FROM driver_geofences
+WHERE driver_id = $1 AND geofence_id = $2;
@@ -0,0 +1,141 @@
+-- name: CreateIncentiveProgram :one
+INSERT INTO incentive_programs (
+    name,
+    description,
+    kind,
+    city_code,
+    vehicle_type,
+    geofence_id,
+    currency,
+    target_trips,
+    reward_amount,
+    boost_rate,
+    starts_at,
+    ends_at,
+    created_by
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13
+) RETURNING *;
+
+-- name: GetIncentiveProgram :one
+SELECT * FROM incentive_programs
+WHERE id = $1 LIMIT 1;
+
+-- name: ListIncentivePrograms :many
+SELECT * FROM incentive_programs
+ORDER BY is_active DESC, ends_at DESC
+LIMIT $1 OFFSET $2;
+
+-- name: UpdateIncentiveProgramStatus :one
+UPDATE incentive_programs
+SET is_active = $2, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+RETURNING *;
+
+-- name: GetApplicableIncentivePrograms :many
+-- Programs a trip completed at the given time counts towards.
+SELECT * FROM incentive_programs
+WHERE is_active = true
+  AND starts_at <= sqlc.arg('at')
+  AND ends_at > sqlc.arg('at')
+  AND (city_code = sqlc.arg('city_code') OR city_code IS NULL)
+  AND (vehicle_type = sqlc.arg('vehicle_type') OR vehicle_type IS NULL)
+ORDER BY starts_at;
+
+-- name: GetDriverIncentivePrograms :many
+-- Programs open to a driver that end after since and start before until.
+SELECT * FROM incentive_programs
+WHERE is_active = true
+  AND ends_at > sqlc.arg('since')::timestamp
+  AND starts_at < sqlc.arg('until')::timestamp
+  AND (city_code = sqlc.arg('city_code') OR city_code IS NULL)
+  AND (vehicle_type = sqlc.arg('vehicle_type') OR vehicle_type IS NULL)
+ORDER BY ends_at;
+
+-- name: GetDriverIncentiveProgress :many
+SELECT g.* FROM incentive_progress g
+JOIN incentive_programs p ON p.id = g.program_id
+WHERE g.driver_id = sqlc.arg('driver_id')
+  AND p.ends_at > sqlc.arg('since')::timestamp;
+
+-- name: RecordIncentiveTrip :execrows
+INSERT INTO incentive_trips (
+    program_id,
+    trip_id,
+    driver_id
+) VALUES (
+    $1, $2, $3
+)
+ON CONFLICT (program_id, trip_id) DO NOTHING;
+
+-- name: AddIncentiveTrip :one
+INSERT INTO incentive_progress (
+    program_id,
+    driver_id,
+    trips_completed,
+    fares_earned
+) VALUES (
+    sqlc.arg('program_id'), sqlc.arg('driver_id'), 1, sqlc.arg('fare')
+)
+ON CONFLICT (program_id, driver_id) DO UPDATE
+SET
+    trips_completed = incentive_progress.trips_completed + 1,
+    fares_earned = incentive_progress.fares_earned + EXCLUDED.fares_earned,
+    updated_at = CURRENT_TIMESTAMP
+RETURNING *;
+
+-- name: MarkIncentiveCompleted :execrows
+UPDATE incentive_progress
+SET completed_at = sqlc.arg('completed_at'), updated_at = CURRENT_TIMESTAMP
+WHERE program_id = sqlc.arg('program_id')
+  AND driver_id = sqlc.arg('driver_id')
+  AND completed_at IS NULL;
+
+-- name: AddIncentiveBonus :exec
+UPDATE incentive_progress
+SET bonus_earned = bonus_earned + sqlc.arg('bonus')::numeric, updated_at = CURRENT_TIMESTAMP
+WHERE program_id = sqlc.arg('program_id') AND driver_id = sqlc.arg('driver_id');
+
+-- name: CreateIncentiveEarning :one
+-- Bonuses are earnings with no trip, fare, commission or tax.
+INSERT INTO driver_earnings (
+    driver_id,
+    incentive_program_id,
+    gross_fare,
+    commission_rate,
+    commission,
+    tax,
+    tip,
+    net_earnings,
+    currency,
+    earned_at
+) VALUES (
+    sqlc.arg('driver_id'), sqlc.arg('incentive_program_id'), 0, 0, 0, 0, 0, sqlc.arg('amount'), sqlc.arg('currency'), sqlc.arg('earned_at')
+) RETURNING *;
+
+-- name: GetUnsettledPeakGuarantees :many
+SELECT * FROM incentive_programs
+WHERE kind = 'peak_guarantee'
+  AND is_active = true
+  AND settled_at IS NULL
+  AND ends_at <= $1
+ORDER BY ends_at
+LIMIT $2;
+
+-- name: MarkIncentiveProgramSettled :execrows
+UPDATE incentive_programs
+SET settled_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND settled_at IS NULL;
+
+-- name: GetCompletedIncentiveProgress :many
+SELECT * FROM incentive_progress
+WHERE program_id = $1 AND completed_at IS NOT NULL
+ORDER BY driver_id;
+
+-- name: GetCityCurrency :one
+SELECT currency FROM cities
+WHERE code = $1 LIMIT 1;
+
+-- name: GetGeofencePolygon :one
+SELECT polygon FROM geofences
+WHERE id = $1 LIMIT 1;
@@ -0,0 +1,155 @@
+-- name: CreateLedgerAccount :one
+INSERT INTO ledger_accounts (
+    owner_id,
+    account_type,
+    currency
+) VALUES (
+    $1, $2, $3
+)
+ON CONFLICT DO NOTHING
+RETURNING *;
+
+-- name: GetLedgerAccountByID :one
+SELECT * FROM ledger_accounts
+WHERE id = $1 LIMIT 1;
+
+-- name: GetLedgerAccount :one
+SELECT * FROM ledger_accounts
+WHERE owner_id = $1 AND account_type = $2 AND currency = $3
+LIMIT 1;
+
+-- name: GetSystemLedgerAccount :one
+SELECT * FROM ledger_accounts
+WHERE owner_id IS NULL AND account_type = $1 AND currency = $2
+LIMIT 1;
+
+-- name: GetLedgerAccountsByOwner :many
+SELECT * FROM ledger_accounts
+WHERE owner_id = $1
+ORDER BY created_at;
+
+-- name: LockLedgerAccount :exec
+SELECT 1 FROM ledger_accounts
+WHERE id = $1
+FOR UPDATE;
+
+-- name: GetAccountBalance :one
+SELECT COALESCE(SUM(CASE WHEN direction = 'credit' THEN amount ELSE -amount END), 0)::numeric AS balance
+FROM ledger_entries
+WHERE account_id = $1;
+
+-- name: CreateLedgerTransaction :one
+INSERT INTO ledger_transactions (
+    transaction_type,
+    idempotency_key,
+    trip_id,
+    reversed_transaction_id,
+    description,
+    created_by
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+) RETURNING *;
+
+-- name: GetLedgerTransaction :one
+SELECT * FROM ledger_transactions
+WHERE id = $1 LIMIT 1;
+
+-- name: GetLedgerTransactionByIdempotencyKey :one
+SELECT * FROM ledger_transactions
+WHERE idempotency_key = $1 LIMIT 1;
+
+-- name: CreateLedgerEntry :one
+INSERT INTO ledger_entries (
+    transaction_id,
+    account_id,
+    direction,
+    amount
+) VALUES (
+    $1, $2, $3, $4
+) RETURNING *;
+
+-- name: GetLedgerEntriesByTransaction :many
+SELECT * FROM ledger_entries
+WHERE transaction_id = $1
+ORDER BY direction DESC, created_at;
+
+-- name: GetAccountStatement :many
+SELECT
+    e.id,
+    e.transaction_id,
+    e.direction,
+    e.amount,
+    t.transaction_type,
+    t.trip_id,
+    t.description,
+    e.created_at
+FROM ledger_entries e
+JOIN ledger_transactions t ON e.transaction_id = t.id
+WHERE e.account_id = $1
+ORDER BY e.created_at DESC
+LIMIT $2 OFFSET $3;
+
+-- name: GetTripLedgerTotal :one
+-- Only rider wallet legs count, so promo-funded parts of a charge are never
+-- refunded to the rider.
+SELECT COALESCE(SUM(e.amount), 0)::numeric AS total
+FROM ledger_transactions t
+JOIN ledger_entries e ON e.transaction_id = t.id
+JOIN ledger_accounts a ON a.id = e.account_id
+WHERE t.trip_id = $1 AND t.transaction_type = $2 AND a.account_type = 'rider_wallet';
+
+-- name: GetUnbalancedTransactions :many
+SELECT
+    t.id,
+    t.transaction_type,
+    t.idempotency_key,
+    COALESCE(SUM(CASE WHEN e.direction = 'debit' THEN e.amount ELSE 0 END), 0)::numeric AS total_debits,
+    COALESCE(SUM(CASE WHEN e.direction = 'credit' THEN e.amount ELSE 0 END), 0)::numeric AS total_credits,
+    t.created_at
+FROM ledger_transactions t
+LEFT JOIN ledger_entries e ON e.transaction_id = t.id
+WHERE t.created_at >= sqlc.arg(period_start)::timestamp AND t.created_at < sqlc.arg(period_end)::timestamp
+GROUP BY t.id
+HAVING COUNT(e.id) < 2
+    OR COALESCE(SUM(CASE WHEN e.direction = 'debit' THEN e.amount ELSE 0 END), 0)
+    <> COALESCE(SUM(CASE WHEN e.direction = 'credit' THEN e.amount ELSE 0 END), 0)
+ORDER BY t.created_at;
+
+-- name: GetTransactionTotalsByType :many
+SELECT
+    t.transaction_type,
+    a.currency,
+    COUNT(DISTINCT t.id) AS transaction_count,
+    COALESCE(SUM(CASE WHEN e.direction = 'debit' THEN e.amount ELSE 0 END), 0)::numeric AS total_amount
+FROM ledger_transactions t
+JOIN ledger_entries e ON e.transaction_id = t.id
+JOIN ledger_accounts a ON a.id = e.account_id
+WHERE t.created_at >= sqlc.arg(period_start)::timestamp AND t.created_at < sqlc.arg(period_end)::timestamp
+GROUP BY t.transaction_type, a.currency
+ORDER BY t.transaction_type, a.currency;
+
+-- name: GetAccountTypeTotals :many
+SELECT
+    a.account_type,
+    a.currency,
+    COUNT(DISTINCT a.id) AS account_count,
+    COALESCE(SUM(CASE WHEN e.direction = 'debit' THEN e.amount ELSE 0 END), 0)::numeric AS total_debits,
+    COALESCE(SUM(CASE WHEN e.direction = 'credit' THEN e.amount ELSE 0 END), 0)::numeric AS total_credits
+FROM ledger_accounts a
+LEFT JOIN ledger_entries e ON e.account_id = a.id AND e.created_at < sqlc.arg(as_of)::timestamp
+GROUP BY a.account_type, a.currency
+ORDER BY a.account_type, a.currency;
+
+-- name: GetNegativeBalanceAccounts :many
+SELECT
+    a.id,
+    a.owner_id,
+    a.account_type,
+    a.currency,
+    SUM(CASE WHEN e.direction = 'credit' THEN e.amount ELSE -e.amount END)::numeric AS balance
+FROM ledger_accounts a
+JOIN ledger_entries e ON e.account_id = a.id
+WHERE a.owner_id IS NOT NULL
+GROUP BY a.id
+HAVING SUM(CASE WHEN e.direction = 'credit' THEN e.amount ELSE -e.amount END) < 0
+ORDER BY a.account_type, a.created_at;
@@ -0,0 +1,145 @@
+-- name: GetNotificationUser :one
+SELECT id, phone_number, full_name, role, is_active FROM users
+WHERE id = $1;
+
+-- name: GetNotificationPreferences :one
+SELECT * FROM notification_preferences
+WHERE user_id = $1;
+
+-- name: UpsertNotificationPreferences :one
+INSERT INTO notification_preferences (
+    user_id, push_enabled, sms_enabled, locale, timezone, quiet_hours_start, quiet_hours_end, muted_categories
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8
+)
+ON CONFLICT (user_id) DO UPDATE SET
+    push_enabled = EXCLUDED.push_enabled,
+    sms_enabled = EXCLUDED.sms_enabled,
+    locale = EXCLUDED.locale,
+    timezone = EXCLUDED.timezone,
+    quiet_hours_start = EXCLUDED.quiet_hours_start,
+    quiet_hours_end = EXCLUDED.quiet_hours_end,
+    muted_categories = EXCLUDED.muted_categories
+RETURNING *;
+
+-- name: RegisterDeviceToken :one
+INSERT INTO device_tokens (
+    user_id, token, platform
+) VALUES (
+    $1, $2, $3
+)
+ON CONFLICT (token) DO UPDATE SET
+    user_id = EXCLUDED.user_id,
+    platform = EXCLUDED.platform,
+    updated_at = CURRENT_TIMESTAMP
+RETURNING *;
+
+-- name: ListUserDeviceTokens :many
+SELECT * FROM device_tokens
+WHERE user_id = $1
+ORDER BY updated_at DESC;
+
+-- name: DeleteUserDeviceToken :execrows
+DELETE FROM device_tokens
+WHERE id = $1 AND user_id = $2;
+
+-- name: DeleteDeviceToken :exec
+DELETE FROM device_tokens
+WHERE token = $1;
+
+-- name: CreateNotificationDelivery :one
+INSERT INTO notification_deliveries (
+    user_id, event_key, template, category, channel, address, platform, title, body, data, next_attempt_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
+)
+ON CONFLICT (event_key, user_id, channel, address) DO NOTHING
+RETURNING *;
+
+-- name: ClaimNotificationDelivery :one
+-- Takes a due delivery for sending, pushing next_attempt_at out to the
+-- lease so no other worker picks it up meanwhile.
+UPDATE notification_deliveries
+SET attempts = attempts + 1,
+    next_attempt_at = sqlc.arg('lease_until')::timestamp
+WHERE id = sqlc.arg('id')
+  AND status = 'pending'
+  AND next_attempt_at <= CURRENT_TIMESTAMP
+RETURNING *;
+
+-- name: ListDueNotificationDeliveries :many
+SELECT id FROM notification_deliveries
+WHERE status = 'pending' AND next_attempt_at <= CURRENT_TIMESTAMP
+ORDER BY next_attempt_at
+LIMIT $1;
+
+-- name: MarkNotificationDeliverySent :exec
+UPDATE notification_deliveries
+SET status = 'sent',
+    sent_at = CURRENT_TIMESTAMP,
+    last_error = NULL
+WHERE id = $1;
+
+-- name: MarkNotificationDeliveryFailed :exec
+UPDATE notification_deliveries
+SET status = 'failed',
+    last_error = $2
+WHERE id = $1;
+
+-- name: RetryNotificationDelivery :exec
+UPDATE notification_deliveries
+SET last_error = $2,
+    next_attempt_at = $3
+WHERE id = $1;
+
+-- name: CreateInboxMessage :exec
+INSERT INTO inbox_messages (
+    user_id, delivery_id, category, title, body, data
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+)
+ON CONFLICT (delivery_id) DO NOTHING;
+
+-- name: ListInboxMessages :many
+SELECT * FROM inbox_messages
+WHERE user_id = $1
+ORDER BY created_at DESC
+LIMIT $2 OFFSET $3;
+
+-- name: CountUnreadInboxMessages :one
+SELECT COUNT(*) FROM inbox_messages
+WHERE user_id = $1 AND read_at IS NULL;
+
+-- name: MarkInboxMessageRead :execrows
+UPDATE inbox_messages
+SET read_at = COALESCE(read_at, CURRENT_TIMESTAMP)
+WHERE id = $1 AND user_id = $2;
+
+-- name: MarkAllInboxMessagesRead :execrows
+UPDATE inbox_messages
+SET read_at = CURRENT_TIMESTAMP
+WHERE user_id = $1 AND read_at IS NULL;
+
+-- name: GetTripParticipants :one
+SELECT user_id, driver_id FROM trips
+WHERE id = $1;
+
+-- name: GetDriverVehicle :one
+SELECT u.full_name, dp.vehicle_model, dp.vehicle_color, dp.vehicle_plate_number
+FROM driver_profiles dp
+JOIN users u ON u.id = dp.user_id
+WHERE dp.user_id = $1;
+
+-- name: ListDriversNearPickup :many
+-- Online drivers who can be dispatched within a box around a pickup,
+-- nearest first. An empty vehicle_types matches every vehicle.
+SELECT dp.user_id
+FROM driver_profiles dp
+WHERE dp.is_online = true
+  AND dp.is_approved = true
+  AND (dp.dispatch_suspended_until IS NULL OR dp.dispatch_suspended_until <= CURRENT_TIMESTAMP)
+  AND dp.current_latitude BETWEEN sqlc.arg('min_latitude')::float8 AND sqlc.arg('max_latitude')::float8
+  AND dp.current_longitude BETWEEN sqlc.arg('min_longitude')::float8 AND sqlc.arg('max_longitude')::float8
+  AND (cardinality(sqlc.arg('vehicle_types')::text[]) = 0 OR dp.vehicle_type = ANY(sqlc.arg('vehicle_types')::text[]))
+ORDER BY (dp.current_latitude - sqlc.arg('latitude')::float8) ^ 2 + (dp.current_longitude - sqlc.arg('longitude')::float8) ^ 2
+LIMIT sqlc.arg('max_drivers');
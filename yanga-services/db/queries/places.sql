@@ -0,0 +1,44 @@
+-- name: ListSavedPlaces :many
+SELECT * FROM saved_places
+WHERE user_id = $1
+ORDER BY CASE label WHEN 'home' THEN 0 WHEN 'work' THEN 1 ELSE 2 END, created_at;
+
+-- name: CreateSavedPlace :one
+INSERT INTO saved_places (
+    user_id,
+    label,
+    name,
+    address,
+    latitude,
+    longitude
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+) RETURNING *;
+
+-- name: UpsertSavedPlace :one
+-- Replaces the user's home or work place.
+INSERT INTO saved_places (
+    user_id,
+    label,
+    name,
+    address,
+    latitude,
+    longitude
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+)
+ON CONFLICT (user_id, label) WHERE label IN ('home', 'work') DO UPDATE
+SET name = EXCLUDED.name,
+    address = EXCLUDED.address,
+    latitude = EXCLUDED.latitude,
+    longitude = EXCLUDED.longitude,
+    updated_at = CURRENT_TIMESTAMP
+RETURNING *;
+
+-- name: CountSavedPlacesByLabel :one
+SELECT COUNT(*) FROM saved_places
+WHERE user_id = $1 AND label = $2;
+
+-- name: DeleteSavedPlace :execrows
+DELETE FROM saved_places
+WHERE id = $1 AND user_id = $2;
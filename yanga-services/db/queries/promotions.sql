@@ -0,0 +1,140 @@
+-- name: CreatePromoCode :one
+INSERT INTO promo_codes (
+    code,
+    description,
+    discount_type,
+    discount_value,
+    max_discount,
+    min_fare,
+    usage_limit,
+    per_user_limit,
+    first_ride_only,
+    auto_apply,
+    vehicle_types,
+    center_latitude,
+    center_longitude,
+    radius_km,
+    valid_from,
+    valid_until,
+    created_by,
+    city_code,
+    currency
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19
+) RETURNING *;
+
+-- name: GetPromoCode :one
+SELECT * FROM promo_codes
+WHERE id = $1 LIMIT 1;
+
+-- name: GetPromoCodeByCode :one
+SELECT * FROM promo_codes
+WHERE code = $1 LIMIT 1;
+
+-- name: ListPromoCodes :many
+-- Filtering by city includes the codes valid in every city.
+SELECT * FROM promo_codes
+WHERE (sqlc.narg('city_code')::varchar IS NULL OR city_code IS NULL OR city_code = sqlc.narg('city_code'))
+ORDER BY created_at DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: UpdatePromoCodeStatus :one
+UPDATE promo_codes
+SET is_active = $2, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+RETURNING *;
+
+-- name: GetAutoApplyPromoCodes :many
+SELECT * FROM promo_codes
+WHERE is_active = true
+  AND auto_apply = true
+  AND valid_from <= sqlc.arg('at')
+  AND (valid_until IS NULL OR valid_until > sqlc.arg('at'))
+  AND (usage_limit IS NULL OR times_used < usage_limit)
+ORDER BY created_at;
+
+-- name: ClaimPromoCodeUse :execrows
+UPDATE promo_codes
+SET times_used = times_used + 1, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+  AND is_active = true
+  AND (usage_limit IS NULL OR times_used < usage_limit);
+
+-- name: ReleasePromoCodeUse :exec
+UPDATE promo_codes
+SET times_used = times_used - 1, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND times_used > 0;
+
+-- name: CountUserPromoRedemptions :one
+SELECT COUNT(*) FROM promo_redemptions
+WHERE promo_code_id = $1 AND user_id = $2 AND status IN ('reserved', 'redeemed');
+
+-- name: CreatePromoRedemption :one
+INSERT INTO promo_redemptions (
+    promo_code_id,
+    user_id,
+    trip_id,
+    discount_amount
+) VALUES (
+    $1, $2, $3, $4
+) RETURNING *;
+
+-- name: GetPromoRedemptionByTrip :one
+SELECT * FROM promo_redemptions
+WHERE trip_id = $1 LIMIT 1;
+
+-- name: RedeemPromoRedemption :one
+UPDATE promo_redemptions
+SET status = 'redeemed', discount_amount = $2, updated_at = CURRENT_TIMESTAMP
+WHERE trip_id = $1 AND status = 'reserved'
+RETURNING *;
+
+-- name: ReleasePromoRedemption :one
+UPDATE promo_redemptions
+SET status = 'released', updated_at = CURRENT_TIMESTAMP
+WHERE trip_id = $1 AND status = 'reserved'
+RETURNING *;
+
+-- name: CreateReferralCode :one
+INSERT INTO referral_codes (
+    user_id,
+    code
+) VALUES (
+    $1, $2
+)
+ON CONFLICT (user_id) DO NOTHING
+RETURNING *;
+
+-- name: GetReferralCodeByUser :one
+SELECT * FROM referral_codes
+WHERE user_id = $1 LIMIT 1;
+
+-- name: GetReferralCodeByCode :one
+SELECT * FROM referral_codes
+WHERE code = $1 LIMIT 1;
+
+-- name: CreateReferral :one
+INSERT INTO referrals (
+    referrer_id,
+    referee_id,
+    referrer_reward,
+    referee_reward
+) VALUES (
+    $1, $2, $3, $4
+)
+ON CONFLICT (referee_id) DO NOTHING
+RETURNING *;
+
+-- name: MarkReferralRewarded :one
+UPDATE referrals
+SET status = 'rewarded', qualifying_trip_id = $2, rewarded_at = CURRENT_TIMESTAMP
+WHERE referee_id = $1 AND status = 'pending'
+RETURNING *;
+
+-- name: GetReferralStats :one
+SELECT
+    COUNT(*) AS total_referrals,
+    COUNT(*) FILTER (WHERE status = 'rewarded') AS rewarded_referrals,
+    COALESCE(SUM(referrer_reward) FILTER (WHERE status = 'rewarded'), 0)::numeric AS total_earned
+FROM referrals
+WHERE referrer_id = $1;
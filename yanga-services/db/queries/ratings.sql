@@ -1,14 +1,27 @@
+-- name: GetTripParticipants :one
+SELECT id, user_id, driver_id, status, completed_at
+FROM trips
+WHERE id = $1;
+
 -- name: CreateRating :one
 INSERT INTO ratings (
     trip_id,
     rater_id,
     rated_id,
+    rater_type,
     rating,
-    feedback
+    feedback,
+    feedback_flagged,
+    flag_reasons
 ) VALUES (
-    $1, $2, $3, $4, $5
+    $1, $2, $3, $4, $5, $6, $7, $8
 ) RETURNING *;
 
+-- name: AddRatingTag :exec
+INSERT INTO rating_tags (rating_id, tag)
+VALUES ($1, $2)
+ON CONFLICT DO NOTHING;
+
 -- name: GetRating :one
 SELECT * FROM ratings
 WHERE id = $1 LIMIT 1;
@@ -18,20 +31,47 @@ SELECT * FROM ratings
 WHERE trip_id = $1 AND rater_id = $2
 LIMIT 1;
 
+-- name: GetTripRatings :many
+SELECT * FROM ratings
+WHERE trip_id = $1
+ORDER BY created_at;
+
 -- name: GetUserRatings :many
+-- Ratings a user received from the other side of their trips: rater_type
+-- 'rider' for ratings of a driver, 'driver' for ratings of a rider.
+-- Excluded ratings are left out.
 SELECT r.*, u.full_name as rater_name
 FROM ratings r
 JOIN users u ON r.rater_id = u.id
-WHERE r.rated_id = $1
+WHERE r.rated_id = sqlc.arg('rated_id') AND r.rater_type = sqlc.arg('rater_type')
+  AND r.excluded_at IS NULL
 ORDER BY r.created_at DESC
-LIMIT $2 OFFSET $3;
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: GetRatingTags :many
+SELECT rating_id, tag FROM rating_tags
+WHERE rating_id = ANY(sqlc.arg('rating_ids')::uuid[])
+ORDER BY rating_id, tag;
 
 -- name: GetAverageRating :one
-SELECT 
-    COALESCE(AVG(rating), 0) as average_rating,
+-- Each rating counts by its rater's weight, so raters who rate far below
+-- everyone else move the average less. Excluded ratings don't count.
+SELECT
+    COALESCE(SUM(r.rating * COALESCE(w.weight, 1)) / NULLIF(SUM(COALESCE(w.weight, 1)), 0), 0)::float8 as average_rating,
     COUNT(*) as total_ratings
-FROM ratings
-WHERE rated_id = $1;
+FROM ratings r
+LEFT JOIN rater_weights w ON w.rater_id = r.rater_id AND w.rater_type = r.rater_type
+WHERE r.rated_id = sqlc.arg('rated_id') AND r.rater_type = sqlc.arg('rater_type')
+  AND r.excluded_at IS NULL;
+
+-- name: GetRatingTagCounts :many
+SELECT t.tag, COUNT(*) as count
+FROM rating_tags t
+JOIN ratings r ON r.id = t.rating_id
+WHERE r.rated_id = sqlc.arg('rated_id') AND r.rater_type = sqlc.arg('rater_type')
+  AND r.excluded_at IS NULL
+GROUP BY t.tag
+ORDER BY count DESC, t.tag;
 
 -- name: UpdateRating :one
 UPDATE ratings
@@ -41,3 +81,88 @@ RETURNING *;
 
 -- name: DeleteRating :exec
 DELETE FROM ratings WHERE id = $1;
+
+-- name: ListFlaggedRatings :many
+-- Ratings whose feedback the content filter flagged, oldest first, for
+-- admins to review.
+SELECT * FROM ratings
+WHERE feedback_flagged AND excluded_at IS NULL
+ORDER BY created_at
+LIMIT $1 OFFSET $2;
+
+-- name: ExcludeRating :one
+UPDATE ratings
+SET excluded_at = CURRENT_TIMESTAMP,
+    excluded_by = sqlc.arg('excluded_by'),
+    exclusion_reason = sqlc.narg('exclusion_reason')
+WHERE id = sqlc.arg('id') AND excluded_at IS NULL
+RETURNING *;
+
+-- name: RestoreRating :one
+UPDATE ratings
+SET excluded_at = NULL, excluded_by = NULL, exclusion_reason = NULL
+WHERE id = $1 AND excluded_at IS NOT NULL
+RETURNING *;
+
+-- name: ClearRatingFlag :one
+UPDATE ratings
+SET feedback_flagged = false, flag_reasons = NULL
+WHERE id = $1 AND feedback_flagged
+RETURNING *;
+
+-- name: CreateRatingDispute :one
+INSERT INTO rating_disputes (rating_id, disputed_by, reason)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+-- name: GetRatingDispute :one
+SELECT * FROM rating_disputes
+WHERE id = $1;
+
+-- name: ListRatingDisputes :many
+SELECT d.*, r.rating, r.feedback, r.rater_id, r.trip_id
+FROM rating_disputes d
+JOIN ratings r ON r.id = d.rating_id
+WHERE sqlc.narg('status')::text IS NULL OR d.status = sqlc.narg('status')::text
+ORDER BY d.created_at
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: ListUserRatingDisputes :many
+SELECT d.*, r.rating, r.feedback, r.rater_id, r.trip_id
+FROM rating_disputes d
+JOIN ratings r ON r.id = d.rating_id
+WHERE d.disputed_by = $1
+ORDER BY d.created_at DESC;
+
+-- name: ResolveRatingDispute :one
+UPDATE rating_disputes
+SET status = sqlc.arg('status'),
+    resolution_note = sqlc.narg('resolution_note'),
+    reviewed_by = sqlc.arg('reviewed_by'),
+    reviewed_at = CURRENT_TIMESTAMP
+WHERE id = sqlc.arg('id') AND status = 'pending'
+RETURNING *;
+
+-- name: GetRaterGivenStats :one
+-- How many ratings a rater has given as a rider or as a driver, and their
+-- average.
+SELECT
+    COUNT(*) as ratings_given,
+    COALESCE(AVG(rating), 0)::float8 as average_given
+FROM ratings
+WHERE rater_id = $1 AND rater_type = $2 AND excluded_at IS NULL;
+
+-- name: GetRaterTypeAverage :one
+-- The average rating all riders, or all drivers, give.
+SELECT COALESCE(AVG(rating), 0)::float8 as average_rating
+FROM ratings
+WHERE rater_type = $1 AND excluded_at IS NULL;
+
+-- name: UpsertRaterWeight :exec
+INSERT INTO rater_weights (rater_id, rater_type, ratings_given, average_given, weight, updated_at)
+VALUES ($1, $2, $3, $4, $5, CURRENT_TIMESTAMP)
+ON CONFLICT (rater_id, rater_type) DO UPDATE
+SET ratings_given = EXCLUDED.ratings_given,
+    average_given = EXCLUDED.average_given,
+    weight = EXCLUDED.weight,
+    updated_at = EXCLUDED.updated_at;
@@ -0,0 +1,114 @@
+-- name: ListEmergencyContacts :many
+SELECT * FROM emergency_contacts
+WHERE user_id = $1
+ORDER BY created_at;
+
+-- name: CountEmergencyContacts :one
+SELECT COUNT(*) FROM emergency_contacts
+WHERE user_id = $1;
+
+-- name: CreateEmergencyContact :one
+INSERT INTO emergency_contacts (
+    user_id, name, phone_number
+) VALUES (
+    $1, $2, $3
+)
+ON CONFLICT (user_id, phone_number) DO NOTHING
+RETURNING *;
+
+-- name: DeleteEmergencyContact :execrows
+DELETE FROM emergency_contacts
+WHERE id = $1 AND user_id = $2;
+
+-- name: CreateTripShareLink :one
+INSERT INTO trip_share_links (
+    trip_id, created_by, token_hash, expires_at
+) VALUES (
+    $1, $2, $3, $4
+)
+RETURNING *;
+
+-- name: GetTripShareLinkByToken :one
+SELECT * FROM trip_share_links
+WHERE token_hash = $1;
+
+-- name: RevokeTripShareLink :execrows
+UPDATE trip_share_links
+SET revoked_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND trip_id = $2 AND revoked_at IS NULL;
+
+-- name: GetSafetyDriver :one
+SELECT u.full_name, p.vehicle_model, p.vehicle_color, p.vehicle_plate_number,
+       p.current_latitude, p.current_longitude
+FROM users u
+JOIN driver_profiles p ON p.user_id = u.id
+WHERE u.id = $1;
+
+-- name: CreateSafetyIncident :one
+INSERT INTO safety_incidents (
+    trip_id, reporter_id, kind, latitude, longitude, details, snapshot
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7
+)
+ON CONFLICT (trip_id, kind) WHERE status <> 'resolved' DO NOTHING
+RETURNING *;
+
+-- name: GetUnresolvedSafetyIncident :one
+SELECT * FROM safety_incidents
+WHERE trip_id = $1 AND kind = $2 AND status <> 'resolved';
+
+-- name: GetSafetyIncident :one
+SELECT * FROM safety_incidents
+WHERE id = $1;
+
+-- name: ListSafetyIncidents :many
+SELECT * FROM safety_incidents
+WHERE sqlc.narg('status')::text IS NULL OR status = sqlc.narg('status')::text
+ORDER BY created_at
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: AcknowledgeSafetyIncident :one
+UPDATE safety_incidents
+SET status = 'acknowledged',
+    acknowledged_by = $2,
+    acknowledged_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND status = 'open'
+RETURNING *;
+
+-- name: ResolveSafetyIncident :one
+UPDATE safety_incidents
+SET status = 'resolved',
+    resolved_by = $2,
+    resolved_at = CURRENT_TIMESTAMP,
+    resolution_note = $3
+WHERE id = $1 AND status <> 'resolved'
+RETURNING *;
+
+-- name: GetTripSafetyTrack :one
+SELECT * FROM trip_safety_tracks
+WHERE trip_id = $1;
+
+-- name: SaveTripSafetyTrack :exec
+INSERT INTO trip_safety_tracks (
+    trip_id, planned_polyline, anchor_latitude, anchor_longitude, anchored_at, last_seen_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+)
+ON CONFLICT (trip_id) DO UPDATE
+SET planned_polyline = EXCLUDED.planned_polyline,
+    anchor_latitude = EXCLUDED.anchor_latitude,
+    anchor_longitude = EXCLUDED.anchor_longitude,
+    anchored_at = EXCLUDED.anchored_at,
+    last_seen_at = EXCLUDED.last_seen_at;
+
+-- name: ClearTripPlannedRoute :exec
+UPDATE trip_safety_tracks
+SET planned_polyline = NULL
+WHERE trip_id = $1;
+
+-- name: ListTrackedTrips :many
+-- Trips under way with where their driver was last seen moving.
+SELECT t.*, s.anchor_latitude, s.anchor_longitude, s.anchored_at
+FROM trips t
+JOIN trip_safety_tracks s ON s.trip_id = t.id
+WHERE t.status = 'in_progress';
@@ -0,0 +1,63 @@
+-- name: GetUserScheduledTrips :many
+SELECT * FROM trips
+WHERE user_id = $1 AND status = 'scheduled'
+ORDER BY pickup_at
+LIMIT $2 OFFSET $3;
+
+-- name: GetAvailableScheduledTrips :many
+-- Drivers with a home city only see trips in it.
+SELECT * FROM trips
+WHERE status = 'scheduled'
+  AND reserved_driver_id IS NULL
+  AND pickup_at > CURRENT_TIMESTAMP
+  AND (sqlc.narg('city_code')::varchar IS NULL OR city_code = sqlc.narg('city_code'))
+ORDER BY pickup_at
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: GetDriverReservedTrips :many
+SELECT * FROM trips
+WHERE reserved_driver_id = $1 AND status = 'scheduled'
+ORDER BY pickup_at;
+
+-- name: ReserveScheduledTrip :execrows
+UPDATE trips
+SET reserved_driver_id = $2, reserved_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND status = 'scheduled' AND reserved_driver_id IS NULL;
+
+-- name: ReleaseScheduledTrip :execrows
+UPDATE trips
+SET reserved_driver_id = NULL, reserved_at = NULL, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND status = 'scheduled' AND reserved_driver_id = $2;
+
+-- name: GetTripsDueForReminder :many
+SELECT * FROM trips
+WHERE status = 'scheduled'
+  AND reminder_sent_at IS NULL
+  AND pickup_at <= sqlc.arg('remind_before')::timestamp
+ORDER BY pickup_at
+LIMIT sqlc.arg('limit');
+
+-- name: MarkTripReminderSent :execrows
+UPDATE trips
+SET reminder_sent_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND reminder_sent_at IS NULL;
+
+-- name: GetTripsDueForDispatch :many
+SELECT * FROM trips
+WHERE status = 'scheduled'
+  AND pickup_at <= sqlc.arg('dispatch_before')::timestamp
+ORDER BY pickup_at
+LIMIT sqlc.arg('limit');
+
+-- name: DispatchScheduledTrip :one
+-- A trip reserved in advance goes straight to its driver; otherwise it is
+-- opened up for matching like an immediate trip.
+UPDATE trips
+SET
+    status = CASE WHEN reserved_driver_id IS NOT NULL THEN 'accepted' ELSE 'pending' END,
+    driver_id = reserved_driver_id,
+    accepted_at = CASE WHEN reserved_driver_id IS NOT NULL THEN CURRENT_TIMESTAMP END,
+    dispatched_at = CURRENT_TIMESTAMP,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND status = 'scheduled'
+RETURNING *;
@@ -0,0 +1,159 @@
+-- name: GetSupportTrip :one
+SELECT id, user_id, driver_id, status, actual_fare, tip, discount_amount, currency
+FROM trips
+WHERE id = $1;
+
+-- name: GetTripAdjustmentTotal :one
+-- What support has given back on a trip so far, including credits still
+-- being settled.
+SELECT COALESCE(SUM(adjustment_amount), 0)::numeric AS total
+FROM support_tickets
+WHERE trip_id = $1 AND adjustment_status IN ('pending', 'applied');
+
+-- name: CreateSupportTicket :one
+-- A user has at most one unresolved ticket of each category about a trip.
+INSERT INTO support_tickets (
+    user_id,
+    trip_id,
+    driver_id,
+    category,
+    priority,
+    subject,
+    first_response_due_at,
+    resolution_due_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8
+)
+ON CONFLICT (user_id, trip_id, category) WHERE status <> 'resolved' DO NOTHING
+RETURNING *;
+
+-- name: GetSupportTicket :one
+SELECT * FROM support_tickets
+WHERE id = $1;
+
+-- name: ListUserSupportTickets :many
+-- Tickets a user opened, and lost item reports about their vehicle.
+SELECT * FROM support_tickets
+WHERE user_id = sqlc.arg('user_id') OR driver_id = sqlc.arg('user_id')
+ORDER BY created_at DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: ListSupportQueue :many
+-- The agents' queue, most urgent first and then by resolution deadline.
+SELECT * FROM support_tickets
+WHERE (sqlc.narg('status')::varchar IS NULL OR status = sqlc.narg('status'))
+  AND (sqlc.narg('category')::varchar IS NULL OR category = sqlc.narg('category'))
+  AND (sqlc.narg('assigned_to')::uuid IS NULL OR assigned_to = sqlc.narg('assigned_to'))
+  AND (NOT sqlc.arg('unassigned')::boolean OR assigned_to IS NULL)
+ORDER BY
+    CASE priority WHEN 'urgent' THEN 0 WHEN 'high' THEN 1 WHEN 'normal' THEN 2 ELSE 3 END,
+    resolution_due_at
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: AssignSupportTicket :one
+UPDATE support_tickets
+SET assigned_to = sqlc.arg('assigned_to'),
+    assigned_at = sqlc.arg('assigned_at')
+WHERE id = sqlc.arg('id') AND status <> 'resolved'
+RETURNING *;
+
+-- name: RecordSupportAgentReply :one
+-- An agent's reply puts the ticket back with the user, and the first one
+-- stops the first response clock.
+UPDATE support_tickets
+SET status = 'awaiting_user',
+    first_responded_at = COALESCE(first_responded_at, sqlc.arg('replied_at')::timestamp)
+WHERE id = sqlc.arg('id') AND status <> 'resolved'
+RETURNING *;
+
+-- name: RecordSupportUserReply :one
+UPDATE support_tickets
+SET status = 'open'
+WHERE id = $1 AND status <> 'resolved'
+RETURNING *;
+
+-- name: ResolveSupportTicket :one
+UPDATE support_tickets
+SET status = 'resolved',
+    resolution = sqlc.arg('resolution'),
+    resolution_note = sqlc.arg('resolution_note'),
+    adjustment_amount = sqlc.narg('adjustment_amount'),
+    adjustment_status = sqlc.narg('adjustment_status'),
+    resolved_by = sqlc.arg('resolved_by'),
+    resolved_at = sqlc.arg('resolved_at')
+WHERE id = sqlc.arg('id') AND status <> 'resolved'
+RETURNING *;
+
+-- name: SettleSupportAdjustment :execrows
+UPDATE support_tickets
+SET adjustment_status = sqlc.arg('adjustment_status'),
+    adjustment_transaction_id = sqlc.narg('adjustment_transaction_id'),
+    adjustment_error = sqlc.narg('adjustment_error')
+WHERE id = sqlc.arg('id') AND adjustment_status = 'pending';
+
+-- name: ListFirstResponseBreaches :many
+SELECT * FROM support_tickets
+WHERE status <> 'resolved'
+  AND first_responded_at IS NULL
+  AND first_response_breached_at IS NULL
+  AND first_response_due_at <= sqlc.arg('now')
+ORDER BY first_response_due_at;
+
+-- name: MarkFirstResponseBreached :execrows
+UPDATE support_tickets
+SET first_response_breached_at = sqlc.arg('breached_at')
+WHERE id = sqlc.arg('id') AND first_responded_at IS NULL AND first_response_breached_at IS NULL;
+
+-- name: ListResolutionBreaches :many
+SELECT * FROM support_tickets
+WHERE status <> 'resolved'
+  AND resolution_breached_at IS NULL
+  AND resolution_due_at <= sqlc.arg('now')
+ORDER BY resolution_due_at;
+
+-- name: MarkResolutionBreached :execrows
+UPDATE support_tickets
+SET resolution_breached_at = sqlc.arg('breached_at')
+WHERE id = sqlc.arg('id') AND status <> 'resolved' AND resolution_breached_at IS NULL;
+
+-- name: CreateSupportMessage :one
+INSERT INTO support_messages (
+    ticket_id,
+    author_id,
+    author_role,
+    body,
+    internal
+) VALUES (
+    $1, $2, $3, $4, $5
+) RETURNING *;
+
+-- name: ListSupportMessages :many
+SELECT * FROM support_messages
+WHERE ticket_id = sqlc.arg('ticket_id')
+  AND (sqlc.arg('include_internal')::boolean OR NOT internal)
+ORDER BY created_at;
+
+-- name: CreateSupportAttachment :one
+INSERT INTO support_attachments (
+    ticket_id,
+    uploaded_by,
+    file_name,
+    content_type,
+    size_bytes,
+    blob_key
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+) RETURNING *;
+
+-- name: CountSupportAttachments :one
+SELECT COUNT(*) FROM support_attachments
+WHERE ticket_id = $1;
+
+-- name: GetSupportAttachment :one
+SELECT * FROM support_attachments
+WHERE id = $1 AND ticket_id = $2;
+
+-- name: ListSupportAttachments :many
+SELECT * FROM support_attachments
+WHERE ticket_id = $1
+ORDER BY created_at;
@@ -0,0 +1,56 @@
+-- name: CreateTripMessage :one
+INSERT INTO trip_messages (
+    trip_id, sender_id, sender_role, body, quick_reply, client_id
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+)
+ON CONFLICT (trip_id, sender_id, client_id) DO NOTHING
+RETURNING *;
+
+-- name: GetTripMessage :one
+SELECT * FROM trip_messages
+WHERE id = $1 AND trip_id = $2;
+
+-- name: GetTripMessageByClientID :one
+SELECT * FROM trip_messages
+WHERE trip_id = $1 AND sender_id = $2 AND client_id = $3;
+
+-- name: ListTripMessages :many
+SELECT * FROM trip_messages
+WHERE trip_id = $1
+ORDER BY created_at DESC
+LIMIT $2 OFFSET $3;
+
+-- name: MarkTripMessagesRead :execrows
+-- Marks read everything the other party sent up to and including a message.
+UPDATE trip_messages
+SET read_at = CURRENT_TIMESTAMP
+WHERE trip_id = sqlc.arg('trip_id')
+  AND sender_id <> sqlc.arg('reader_id')
+  AND read_at IS NULL
+  AND created_at <= sqlc.arg('up_to')::timestamp;
+
+-- name: GetTripParticipantPhones :one
+SELECT u.phone_number AS rider_phone, d.phone_number AS driver_phone
+FROM trips t
+JOIN users u ON u.id = t.user_id
+JOIN users d ON d.id = t.driver_id
+WHERE t.id = $1;
+
+-- name: GetOpenTripCallSession :one
+SELECT * FROM trip_call_sessions
+WHERE trip_id = $1 AND closed_at IS NULL;
+
+-- name: CreateTripCallSession :one
+INSERT INTO trip_call_sessions (
+    trip_id, provider_session_id, proxy_number, expires_at
+) VALUES (
+    $1, $2, $3, $4
+)
+ON CONFLICT (trip_id) WHERE closed_at IS NULL DO NOTHING
+RETURNING *;
+
+-- name: CloseTripCallSession :exec
+UPDATE trip_call_sessions
+SET closed_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND closed_at IS NULL;
@@ -0,0 +1,94 @@
+-- name: CreateTripPool :one
+INSERT INTO trip_pools (
+    vehicle_type,
+    seat_capacity
+) VALUES (
+    $1, $2
+) RETURNING *;
+
+-- name: GetTripPool :one
+SELECT * FROM trip_pools
+WHERE id = $1 LIMIT 1;
+
+-- name: LockTripPool :one
+-- Serialises riders joining the same pool.
+SELECT * FROM trip_pools
+WHERE id = $1
+FOR UPDATE;
+
+-- name: FindOpenTripPools :many
+-- Open pools with a driver whose remaining route passes through the given
+-- bounding box.
+SELECT p.* FROM trip_pools p
+WHERE p.status = 'open'
+  AND p.driver_id IS NOT NULL
+  AND p.vehicle_type = sqlc.arg('vehicle_type')
+  AND EXISTS (
+      SELECT 1 FROM pool_waypoints w
+      WHERE w.pool_id = p.id
+        AND w.completed_at IS NULL
+        AND w.latitude BETWEEN sqlc.arg('min_latitude') AND sqlc.arg('max_latitude')
+        AND w.longitude BETWEEN sqlc.arg('min_longitude') AND sqlc.arg('max_longitude')
+  )
+ORDER BY p.created_at
+LIMIT sqlc.arg('limit');
+
+-- name: SetTripPoolDriver :execrows
+UPDATE trip_pools
+SET driver_id = $2, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND driver_id IS NULL;
+
+-- name: CompleteTripPoolIfDone :execrows
+-- Closes a pool once none of its trips are still active.
+UPDATE trip_pools
+SET status = 'completed', updated_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND status = 'open'
+  AND NOT EXISTS (
+      SELECT 1 FROM trips
+      WHERE trips.pool_id = trip_pools.id
+        AND trips.status IN ('pending', 'accepted', 'in_progress')
+  );
+
+-- name: SetTripPool :one
+UPDATE trips
+SET pool_id = $2, seat_count = $3, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+RETURNING *;
+
+-- name: GetPoolTrips :many
+SELECT * FROM trips
+WHERE pool_id = $1 AND status IN ('pending', 'accepted', 'in_progress')
+ORDER BY created_at;
+
+-- name: CreatePoolWaypoint :one
+INSERT INTO pool_waypoints (
+    pool_id,
+    trip_id,
+    kind,
+    sequence,
+    latitude,
+    longitude,
+    address
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7
+) RETURNING *;
+
+-- name: GetPoolWaypoints :many
+SELECT * FROM pool_waypoints
+WHERE pool_id = $1
+ORDER BY sequence;
+
+-- name: UpdatePoolWaypointSequence :exec
+UPDATE pool_waypoints
+SET sequence = $2, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1;
+
+-- name: CompletePoolWaypoint :exec
+UPDATE pool_waypoints
+SET completed_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+WHERE trip_id = $1 AND kind = $2 AND completed_at IS NULL;
+
+-- name: DeleteTripPoolWaypoints :exec
+-- Drops a cancelled rider's remaining pickup and dropoff from the route.
+DELETE FROM pool_waypoints
+WHERE trip_id = $1 AND completed_at IS NULL;
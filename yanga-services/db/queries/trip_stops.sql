@@ -0,0 +1,51 @@
+-- name: CreateTripStop :one
+INSERT INTO trip_stops (
+    trip_id,
+    stop_order,
+    latitude,
+    longitude,
+    address
+) VALUES (
+    $1, $2, $3, $4, $5
+) RETURNING *;
+
+-- name: GetTripStops :many
+SELECT * FROM trip_stops
+WHERE trip_id = $1
+ORDER BY stop_order;
+
+-- name: GetTripStop :one
+SELECT * FROM trip_stops
+WHERE id = $1 AND trip_id = $2 LIMIT 1;
+
+-- name: ShiftTripStopsBack :exec
+-- Makes room for a stop inserted at stop_order.
+UPDATE trip_stops
+SET stop_order = stop_order + 1, updated_at = CURRENT_TIMESTAMP
+WHERE trip_id = $1 AND stop_order >= $2;
+
+-- name: ShiftTripStopsForward :exec
+-- Closes the gap left by a stop removed from stop_order.
+UPDATE trip_stops
+SET stop_order = stop_order - 1, updated_at = CURRENT_TIMESTAMP
+WHERE trip_id = $1 AND stop_order > $2;
+
+-- name: DeleteTripStop :execrows
+DELETE FROM trip_stops
+WHERE id = $1 AND trip_id = $2 AND arrived_at IS NULL;
+
+-- name: MarkTripStopArrived :execrows
+UPDATE trip_stops
+SET arrived_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND trip_id = $2 AND arrived_at IS NULL;
+
+-- name: UpdateTripRoute :exec
+UPDATE trips
+SET
+    distance = $2,
+    estimated_duration = $3,
+    subtotal_fare = $4,
+    discount_amount = $5,
+    estimated_fare = $6,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1;
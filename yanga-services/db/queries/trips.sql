@@ -9,9 +9,20 @@ INSERT INTO trips (
     dropoff_address,
     estimated_fare,
     estimated_duration,
-    distance
+    distance,
+    payment_method,
+    vehicle_type,
+    subtotal_fare,
+    discount_amount,
+    promo_code,
+    status,
+    pickup_at,
+    trip_type,
+    city_code,
+    zone_fee,
+    currency
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21
 ) RETURNING *;
 
 -- name: GetTrip :one
@@ -40,8 +51,13 @@ WHERE id = $1;
 
 -- name: AssignDriverToTrip :exec
 UPDATE trips
-SET driver_id = $2, status = 'accepted', updated_at = CURRENT_TIMESTAMP
-WHERE id = $1;
+SET driver_id = $2, status = 'accepted', accepted_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND status = 'pending';
+
+-- name: MarkDriverArrived :execrows
+UPDATE trips
+SET arrived_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND status = 'accepted' AND arrived_at IS NULL;
 
 -- name: StartTrip :exec
 UPDATE trips
@@ -59,17 +75,27 @@ SET
     actual_fare = $2,
     actual_duration = $3,
     payment_status = $4,
+    tip = $5,
+    discount_amount = $6,
     updated_at = CURRENT_TIMESTAMP
 WHERE id = $1;
 
--- name: CancelTrip :exec
+-- name: UpdateTripPaymentStatus :exec
+UPDATE trips
+SET payment_status = $2, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1;
+
+-- name: CancelTrip :execrows
 UPDATE trips
 SET 
     status = 'cancelled',
     cancelled_at = CURRENT_TIMESTAMP,
     cancellation_reason = $2,
+    cancelled_by = $3,
+    no_show_party = $4,
+    cancellation_fee = $5,
     updated_at = CURRENT_TIMESTAMP
-WHERE id = $1;
+WHERE id = $1 AND status IN ('scheduled', 'pending', 'accepted');
 
 -- name: GetUserTrips :many
 SELECT * FROM trips
@@ -102,3 +128,7 @@ SELECT * FROM trips
 WHERE driver_id = $1 AND status IN ('accepted', 'in_progress')
 ORDER BY created_at DESC
 LIMIT 1;
+
+-- name: CountUserCompletedTrips :one
+SELECT COUNT(*) FROM trips
+WHERE user_id = $1 AND status = 'completed';
@@ -32,6 +32,40 @@ BEGIN
 END;
 $$;
 
+--
+-- Name: prevent_ledger_mutation(); Type: FUNCTION
+--
+CREATE OR REPLACE FUNCTION public.prevent_ledger_mutation()
+    RETURNS trigger
+    LANGUAGE plpgsql
+    AS $$
+BEGIN
+    RAISE EXCEPTION 'ledger rows are append-only: % on % is not allowed', TG_OP, TG_TABLE_NAME;
+END;
+$$;
+
+--
+-- Name: check_ledger_transaction_balanced(); Type: FUNCTION
+--
+CREATE OR REPLACE FUNCTION public.check_ledger_transaction_balanced()
+    RETURNS trigger
+    LANGUAGE plpgsql
+    AS $$
+DECLARE
+    imbalance numeric;
+BEGIN
+    SELECT COALESCE(SUM(CASE WHEN direction = 'debit' THEN amount ELSE -amount END), 0)
+      INTO imbalance
+      FROM public.ledger_entries
+     WHERE transaction_id = NEW.transaction_id;
+
+    IF imbalance <> 0 THEN
+        RAISE EXCEPTION 'ledger transaction % is unbalanced by %', NEW.transaction_id, imbalance;
+    END IF;
+    RETURN NULL;
+END;
+$$;
+
 SET default_tablespace = '';
 SET default_table_access_method = heap;
 
@@ -72,7 +106,9 @@ CREATE TABLE public.driver_profiles (
     current_latitude numeric(10,8),
     current_longitude numeric(11,8),
     created_at timestamp without time zone DEFAULT CURRENT_TIMESTAMP,
-    updated_at timestamp without time zone DEFAULT CURRENT_TIMESTAMP
+    updated_at timestamp without time zone DEFAULT CURRENT_TIMESTAMP,
+    city_code character varying(50),
+    dispatch_suspended_until timestamp without time zone
 );
 
 --
@@ -93,7 +129,7 @@ CREATE TABLE public.trips (
     estimated_duration integer,
     actual_duration integer,
     distance numeric(10,2),
-    status character varying(20) DEFAULT 'pending' NOT NULL CHECK (status IN ('pending', 'accepted', 'in_progress', 'completed', 'cancelled')),
+    status character varying(20) DEFAULT 'pending' NOT NULL CHECK (status IN ('scheduled', 'pending', 'accepted', 'in_progress', 'completed', 'cancelled')),
     payment_status character varying(20) DEFAULT 'pending' CHECK (payment_status IN ('pending', 'paid', 'failed')),
     payment_method character varying(20) CHECK (payment_method IN ('cash', 'card', 'wallet')),
     started_at timestamp without time zone,
@@ -101,7 +137,28 @@ CREATE TABLE public.trips (
     cancelled_at timestamp without time zone,
     cancellation_reason text,
     created_at timestamp without time zone DEFAULT CURRENT_TIMESTAMP,
-    updated_at timestamp without time zone DEFAULT CURRENT_TIMESTAMP
+    updated_at timestamp without time zone DEFAULT CURRENT_TIMESTAMP,
+    tip numeric(10,2) DEFAULT 0.00,
+    vehicle_type character varying(50),
+    subtotal_fare numeric(10,2),
+    discount_amount numeric(10,2) DEFAULT 0.00,
+    promo_code character varying(30),
+    city_code character varying(50),
+    accepted_at timestamp without time zone,
+    arrived_at timestamp without time zone,
+    cancelled_by character varying(20) CHECK (cancelled_by IN ('rider', 'driver', 'system')),
+    no_show_party character varying(20) CHECK (no_show_party IN ('rider', 'driver')),
+    cancellation_fee numeric(10,2) DEFAULT 0.00,
+    pickup_at timestamp without time zone,
+    dispatched_at timestamp without time zone,
+    reminder_sent_at timestamp without time zone,
+    reserved_driver_id uuid REFERENCES public.users(id),
+    reserved_at timestamp without time zone,
+    pool_id uuid,
+    seat_count integer DEFAULT 1 NOT NULL CHECK (seat_count > 0),
+    trip_type character varying(20) DEFAULT 'ride' NOT NULL CHECK (trip_type IN ('ride', 'delivery')),
+    zone_fee numeric(10,2) DEFAULT 0.00 NOT NULL,
+    currency character varying(3) DEFAULT 'KES' NOT NULL
 );
 
 --
@@ -115,6 +172,12 @@ CREATE TABLE public.ratings (
     rating integer NOT NULL CHECK (rating >= 1 AND rating <= 5),
     feedback text,
     created_at timestamp without time zone DEFAULT CURRENT_TIMESTAMP,
+    rater_type character varying(10) NOT NULL CHECK (rater_type IN ('rider', 'driver')),
+    feedback_flagged boolean DEFAULT false NOT NULL,
+    flag_reasons text,
+    excluded_at timestamp without time zone,
+    excluded_by uuid REFERENCES public.users(id),
+    exclusion_reason text,
     UNIQUE(trip_id, rater_id)
 );
 
@@ -132,6 +195,794 @@ CREATE TABLE public.ride_requests (
     UNIQUE(trip_id, driver_id)
 );
 
+--
+-- Name: ledger_accounts; Type: TABLE
+--
+CREATE TABLE public.ledger_accounts (
+    id uuid DEFAULT public.uuid_generate_v4() NOT NULL PRIMARY KEY,
+    owner_id uuid REFERENCES public.users(id),
+    account_type character varying(30) NOT NULL CHECK (account_type IN ('rider_wallet', 'driver_earnings', 'platform_cash', 'platform_revenue', 'platform_promotions')),
+    currency character varying(3) DEFAULT 'KES' NOT NULL,
+    created_at timestamp without time zone DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(owner_id, account_type, currency)
+);
+
+--
+-- Name: ledger_transactions; Type: TABLE
+--
+CREATE TABLE public.ledger_transactions (
+    id uuid DEFAULT public.uuid_generate_v4() NOT NULL PRIMARY KEY,
+    transaction_type character varying(20) NOT NULL CHECK (transaction_type IN ('top_up', 'trip_charge', 'refund', 'promo_credit', 'transfer', 'cancellation_fee', 'fare_adjustment')),
+    idempotency_key character varying(100) UNIQUE NOT NULL,
+    trip_id uuid REFERENCES public.trips(id),
+    reversed_transaction_id uuid REFERENCES public.ledger_transactions(id),
+    description text,
+    created_by uuid REFERENCES public.users(id),
+    created_at timestamp without time zone DEFAULT CURRENT_TIMESTAMP
+);
+
+--
+-- Name: ledger_entries; Type: TABLE
+--
+CREATE TABLE public.ledger_entries (
+    id uuid DEFAULT public.uuid_generate_v4() NOT NULL PRIMARY KEY,
+    transaction_id uuid NOT NULL REFERENCES public.ledger_transactions(id),
+    account_id uuid NOT NULL REFERENCES public.ledger_accounts(id),
+    direction character varying(6) NOT NULL CHECK (direction IN ('debit', 'credit')),
+    amount numeric(12,2) NOT NULL CHECK (amount > 0),
+    created_at timestamp without time zone DEFAULT CURRENT_TIMESTAMP
+);
+
+--
+-- Name: commission_plans; Type: TABLE
+--
+CREATE TABLE public.commission_plans (
+    id uuid DEFAULT public.uuid_generate_v4() NOT NULL PRIMARY KEY,
+    name character varying(100) NOT NULL,
+    vehicle_type character varying(50),
+    commission_rate numeric(5,4) NOT NULL CHECK (commission_rate >= 0 AND commission_rate <= 1),
+    tax_rate numeric(5,4) DEFAULT 0 NOT NULL CHECK (tax_rate >= 0 AND tax_rate <= 1),
+    is_active boolean DEFAULT true NOT NULL,
+    effective_from timestamp without time zone DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    effective_to timestamp without time zone,
+    created_by uuid REFERENCES public.users(id),
+    created_at timestamp without time zone DEFAULT CURRENT_TIMESTAMP,
+    updated_at timestamp without time zone DEFAULT CURRENT_TIMESTAMP,
+    city_code character varying(50)
+);
+
+--
+-- Name: payout_batches; Type: TABLE
+--
+CREATE TABLE public.payout_batches (
+    id uuid DEFAULT public.uuid_generate_v4() NOT NULL PRIMARY KEY,
+    status character varying(20) DEFAULT 'pending' NOT NULL CHECK (status IN ('pending', 'processing', 'paid', 'failed')),
+    period_end timestamp without time zone NOT NULL,
+    total_amount numeric(12,2) DEFAULT 0.00 NOT NULL,
+    driver_count integer DEFAULT 0 NOT NULL,
+    failure_reason text,
+    created_by uuid REFERENCES public.users(id),
+    processed_at timestamp without time zone,
+    created_at timestamp without time zone DEFAULT CURRENT_TIMESTAMP,
+    updated_at timestamp without time zone DEFAULT CURRENT_TIMESTAMP
+);
+
+--
+-- Name: payout_items; Type: TABLE
+--
+CREATE TABLE public.payout_items (
+    id uuid DEFAULT public.uuid_generate_v4() NOT NULL PRIMARY KEY,
+    batch_id uuid NOT NULL REFERENCES public.payout_batches(id) ON DELETE CASCADE,
+    driver_id uuid NOT NULL REFERENCES public.users(id),
+    amount numeric(12,2) NOT NULL,
+    earnings_count integer NOT NULL,
+    status character varying(20) DEFAULT 'pending' NOT NULL CHECK (status IN ('pending', 'processing', 'paid', 'failed')),
+    payment_reference character varying(100),
+    failure_reason text,
+    created_at timestamp without time zone DEFAULT CURRENT_TIMESTAMP,
+    updated_at timestamp without time zone DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(batch_id, driver_id)
+);
+
+--
+-- Name: driver_earnings; Type: TABLE
+--
+CREATE TABLE public.driver_earnings (
+    id uuid DEFAULT public.uuid_generate_v4() NOT NULL PRIMARY KEY,
+    trip_id uuid UNIQUE REFERENCES public.trips(id),
+    driver_id uuid NOT NULL REFERENCES public.users(id),
+    commission_plan_id uuid REFERENCES public.commission_plans(id),
+    gross_fare numeric(10,2) NOT NULL,
+    commission_rate numeric(5,4) NOT NULL,
+    commission numeric(10,2) NOT NULL,
+    tax numeric(10,2) NOT NULL,
+    tip numeric(10,2) DEFAULT 0.00 NOT NULL,
+    net_earnings numeric(10,2) NOT NULL,
+    payment_method character varying(20),
+    payout_item_id uuid REFERENCES public.payout_items(id),
+    earned_at timestamp without time zone NOT NULL,
+    created_at timestamp without time zone DEFAULT CURRENT_TIMESTAMP,
+    rider_discount numeric(10,2) DEFAULT 0.00 NOT NULL,
+    currency character varying(3) DEFAULT 'KES' NOT NULL,
+    incentive_program_id uuid
+);
+
+--
+-- Name: promo_codes; Type: TABLE
+--
+CREATE TABLE public.promo_codes (
+    id uuid DEFAULT public.uuid_generate_v4() NOT NULL PRIMARY KEY,
+    code character varying(30) NOT NULL UNIQUE,
+    description text,
+    discount_type character varying(20) NOT NULL CHECK (discount_type IN ('percentage', 'fixed')),
+    discount_value numeric(10,2) NOT NULL CHECK (discount_value > 0),
+    max_discount numeric(10,2),
+    min_fare numeric(10,2),
+    usage_limit integer,
+    per_user_limit integer DEFAULT 1 NOT NULL,
+    times_used integer DEFAULT 0 NOT NULL,
+    first_ride_only boolean DEFAULT false NOT NULL,
+    auto_apply boolean DEFAULT false NOT NULL,
+    vehicle_types text[],
+    center_latitude numeric(10,8),
+    center_longitude numeric(11,8),
+    radius_km numeric(8,2),
+    valid_from timestamp without time zone DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    valid_until timestamp without time zone,
+    is_active boolean DEFAULT true NOT NULL,
+    created_by uuid REFERENCES public.users(id),
+    created_at timestamp without time zone DEFAULT CURRENT_TIMESTAMP,
+    updated_at timestamp without time zone DEFAULT CURRENT_TIMESTAMP,
+    city_code character varying(50),
+    currency character varying(3) DEFAULT 'KES' NOT NULL,
+    CHECK (discount_type <> 'percentage' OR discount_value <= 100),
+    CHECK (usage_limit IS NULL OR times_used <= usage_limit)
+);
+
+--
+-- Name: promo_redemptions; Type: TABLE
+--
+CREATE TABLE public.promo_redemptions (
+    id uuid DEFAULT public.uuid_generate_v4() NOT NULL PRIMARY KEY,
+    promo_code_id uuid NOT NULL REFERENCES public.promo_codes(id),
+    user_id uuid NOT NULL REFERENCES public.users(id),
+    trip_id uuid NOT NULL UNIQUE REFERENCES public.trips(id),
+    discount_amount numeric(10,2) NOT NULL,
+    status character varying(20) DEFAULT 'reserved' NOT NULL CHECK (status IN ('reserved', 'redeemed', 'released')),
+    created_at timestamp without time zone DEFAULT CURRENT_TIMESTAMP,
+    updated_at timestamp without time zone DEFAULT CURRENT_TIMESTAMP
+);
+
+--
+-- Name: referral_codes; Type: TABLE
+--
+CREATE TABLE public.referral_codes (
+    id uuid DEFAULT public.uuid_generate_v4() NOT NULL PRIMARY KEY,
+    user_id uuid NOT NULL UNIQUE REFERENCES public.users(id) ON DELETE CASCADE,
+    code character varying(20) NOT NULL UNIQUE,
+    created_at timestamp without time zone DEFAULT CURRENT_TIMESTAMP
+);
+
+--
+-- Name: referrals; Type: TABLE
+--
+CREATE TABLE public.referrals (
+    id uuid DEFAULT public.uuid_generate_v4() NOT NULL PRIMARY KEY,
+    referrer_id uuid NOT NULL REFERENCES public.users(id),
+    referee_id uuid NOT NULL UNIQUE REFERENCES public.users(id),
+    referrer_reward numeric(10,2) NOT NULL,
+    referee_reward numeric(10,2) NOT NULL,
+    status character varying(20) DEFAULT 'pending' NOT NULL CHECK (status IN ('pending', 'rewarded')),
+    qualifying_trip_id uuid REFERENCES public.trips(id),
+    rewarded_at timestamp without time zone,
+    created_at timestamp without time zone DEFAULT CURRENT_TIMESTAMP,
+    CHECK (referrer_id <> referee_id)
+);
+
+--
+-- Name: cancellation_policies; Type: TABLE
+--
+CREATE TABLE public.cancellation_policies (
+    id uuid DEFAULT public.uuid_generate_v4() NOT NULL PRIMARY KEY,
+    city_code character varying(50) NOT NULL UNIQUE,
+    free_cancellation_seconds integer DEFAULT 120 NOT NULL CHECK (free_cancellation_seconds >= 0),
+    late_cancellation_fee numeric(10,2) DEFAULT 0.00 NOT NULL CHECK (late_cancellation_fee >= 0),
+    driver_nearby_meters integer DEFAULT 300 NOT NULL CHECK (driver_nearby_meters >= 0),
+    driver_arrived_fee numeric(10,2) DEFAULT 0.00 NOT NULL CHECK (driver_arrived_fee >= 0),
+    rider_no_show_wait_seconds integer DEFAULT 300 NOT NULL CHECK (rider_no_show_wait_seconds >= 0),
+    rider_no_show_fee numeric(10,2) DEFAULT 0.00 NOT NULL CHECK (rider_no_show_fee >= 0),
+    driver_no_show_wait_seconds integer DEFAULT 900 NOT NULL CHECK (driver_no_show_wait_seconds >= 0),
+    match_timeout_seconds integer DEFAULT 600 NOT NULL CHECK (match_timeout_seconds > 0),
+    updated_by uuid REFERENCES public.users(id),
+    created_at timestamp without time zone DEFAULT CURRENT_TIMESTAMP,
+    updated_at timestamp without time zone DEFAULT CURRENT_TIMESTAMP
+);
+
+--
+-- Name: trip_stops; Type: TABLE
+--
+CREATE TABLE public.trip_stops (
+    id uuid DEFAULT public.uuid_generate_v4() NOT NULL PRIMARY KEY,
+    trip_id uuid NOT NULL REFERENCES public.trips(id) ON DELETE CASCADE,
+    stop_order integer NOT NULL CHECK (stop_order > 0),
+    latitude numeric(10,8) NOT NULL,
+    longitude numeric(11,8) NOT NULL,
+    address text NOT NULL,
+    arrived_at timestamp without time zone,
+    created_at timestamp without time zone DEFAULT CURRENT_TIMESTAMP,
+    updated_at timestamp without time zone DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT trip_stops_trip_id_stop_order_key UNIQUE (trip_id, stop_order) DEFERRABLE INITIALLY DEFERRED
+);
+
+--
+-- Name: trip_pools; Type: TABLE
+--
+CREATE TABLE public.trip_pools (
+    id uuid DEFAULT public.uuid_generate_v4() NOT NULL PRIMARY KEY,
+    driver_id uuid REFERENCES public.users(id),
+    vehicle_type character varying(50) DEFAULT '' NOT NULL,
+    seat_capacity integer NOT NULL CHECK (seat_capacity > 0),
+    status character varying(20) DEFAULT 'open' NOT NULL CHECK (status IN ('open', 'completed')),
+    created_at timestamp without time zone DEFAULT CURRENT_TIMESTAMP,
+    updated_at timestamp without time zone DEFAULT CURRENT_TIMESTAMP
+);
+
+--
+-- Name: pool_waypoints; Type: TABLE
+--
+CREATE TABLE public.pool_waypoints (
+    id uuid DEFAULT public.uuid_generate_v4() NOT NULL PRIMARY KEY,
+    pool_id uuid NOT NULL REFERENCES public.trip_pools(id) ON DELETE CASCADE,
+    trip_id uuid NOT NULL REFERENCES public.trips(id) ON DELETE CASCADE,
+    kind character varying(10) NOT NULL CHECK (kind IN ('pickup', 'dropoff')),
+    sequence integer NOT NULL CHECK (sequence > 0),
+    latitude numeric(10,8) NOT NULL,
+    longitude numeric(11,8) NOT NULL,
+    address text NOT NULL,
+    completed_at timestamp without time zone,
+    created_at timestamp without time zone DEFAULT CURRENT_TIMESTAMP,
+    updated_at timestamp without time zone DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (trip_id, kind),
+    CONSTRAINT pool_waypoints_pool_id_sequence_key UNIQUE (pool_id, sequence) DEFERRABLE INITIALLY DEFERRED
+);
+
+--
+-- Name: trips trips_pool_id_fkey; Type: FK CONSTRAINT
+--
+ALTER TABLE ONLY public.trips
+    ADD CONSTRAINT trips_pool_id_fkey FOREIGN KEY (pool_id) REFERENCES public.trip_pools(id);
+
+--
+-- Name: parcel_deliveries; Type: TABLE
+--
+CREATE TABLE public.parcel_deliveries (
+    trip_id uuid NOT NULL PRIMARY KEY REFERENCES public.trips(id) ON DELETE CASCADE,
+    sender_name character varying(255) NOT NULL,
+    sender_phone character varying(20) NOT NULL,
+    recipient_name character varying(255) NOT NULL,
+    recipient_phone character varying(20) NOT NULL,
+    parcel_size character varying(20) NOT NULL CHECK (parcel_size IN ('small', 'medium', 'large')),
+    weight_kg numeric(6,2) NOT NULL CHECK (weight_kg > 0),
+    description text,
+    instructions text,
+    recipient_pin character varying(6) NOT NULL,
+    pin_attempts integer DEFAULT 0 NOT NULL,
+    pickup_photo_ref text,
+    picked_up_at timestamp without time zone,
+    delivery_photo_ref text,
+    delivered_at timestamp without time zone,
+    created_at timestamp without time zone DEFAULT CURRENT_TIMESTAMP,
+    updated_at timestamp without time zone DEFAULT CURRENT_TIMESTAMP
+);
+
+--
+-- Name: saved_places; Type: TABLE
+--
+CREATE TABLE public.saved_places (
+    id uuid DEFAULT public.uuid_generate_v4() NOT NULL PRIMARY KEY,
+    user_id uuid NOT NULL REFERENCES public.users(id) ON DELETE CASCADE,
+    label character varying(20) NOT NULL CHECK (label IN ('home', 'work', 'favorite')),
+    name character varying(100) NOT NULL,
+    address text NOT NULL,
+    latitude numeric(10,8) NOT NULL,
+    longitude numeric(11,8) NOT NULL,
+    created_at timestamp without time zone DEFAULT CURRENT_TIMESTAMP,
+    updated_at timestamp without time zone DEFAULT CURRENT_TIMESTAMP
+);
+
+--
+-- Name: geofences; Type: TABLE
+--
+CREATE TABLE public.geofences (
+    id uuid DEFAULT public.uuid_generate_v4() NOT NULL PRIMARY KEY,
+    city_code character varying(50) NOT NULL,
+    name character varying(100) NOT NULL,
+    kind character varying(20) NOT NULL CHECK (kind IN ('service_area', 'restricted', 'airport', 'staging')),
+    polygon jsonb NOT NULL,
+    min_latitude numeric(10,8) NOT NULL,
+    max_latitude numeric(10,8) NOT NULL,
+    min_longitude numeric(11,8) NOT NULL,
+    max_longitude numeric(11,8) NOT NULL,
+    pickup_fee numeric(10,2) DEFAULT 0 NOT NULL CHECK (pickup_fee >= 0),
+    dropoff_fee numeric(10,2) DEFAULT 0 NOT NULL CHECK (dropoff_fee >= 0),
+    active boolean DEFAULT true NOT NULL,
+    created_by uuid REFERENCES public.users(id),
+    created_at timestamp without time zone DEFAULT CURRENT_TIMESTAMP,
+    updated_at timestamp without time zone DEFAULT CURRENT_TIMESTAMP,
+    parent_id uuid REFERENCES public.geofences(id) ON DELETE CASCADE
+);
+
+--
+-- Name: driver_geofences; Type: TABLE
+--
+CREATE TABLE public.driver_geofences (
+    driver_id uuid NOT NULL REFERENCES public.users(id) ON DELETE CASCADE,
+    geofence_id uuid NOT NULL REFERENCES public.geofences(id) ON DELETE CASCADE,
+    entered_at timestamp without time zone DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    PRIMARY KEY (driver_id, geofence_id)
+);
+
+--
+-- Name: airport_queue_entries; Type: TABLE
+--
+CREATE TABLE public.airport_queue_entries (
+    driver_id uuid NOT NULL PRIMARY KEY REFERENCES public.users(id) ON DELETE CASCADE,
+    airport_id uuid NOT NULL REFERENCES public.geofences(id) ON DELETE CASCADE,
+    staging_id uuid NOT NULL REFERENCES public.geofences(id) ON DELETE CASCADE,
+    joined_at timestamp without time zone DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+--
+-- Name: cities; Type: TABLE
+--
+CREATE TABLE public.cities (
+    code character varying(50) NOT NULL PRIMARY KEY,
+    name character varying(100) NOT NULL,
+    currency character varying(3) NOT NULL,
+    timezone character varying(50) NOT NULL,
+    base_fare numeric(10,2) NOT NULL CHECK (base_fare >= 0),
+    per_km_rate numeric(10,2) NOT NULL CHECK (per_km_rate >= 0),
+    per_stop_fee numeric(10,2) NOT NULL CHECK (per_stop_fee >= 0),
+    delivery_base_fare numeric(10,2) NOT NULL CHECK (delivery_base_fare >= 0),
+    delivery_per_km_rate numeric(10,2) NOT NULL CHECK (delivery_per_km_rate >= 0),
+    pooling_enabled boolean DEFAULT true NOT NULL,
+    deliveries_enabled boolean DEFAULT true NOT NULL,
+    scheduling_enabled boolean DEFAULT true NOT NULL,
+    stops_enabled boolean DEFAULT true NOT NULL,
+    active boolean DEFAULT true NOT NULL,
+    created_at timestamp without time zone DEFAULT CURRENT_TIMESTAMP,
+    updated_at timestamp without time zone DEFAULT CURRENT_TIMESTAMP
+);
+
+--
+-- Name: trips trips_city_code_fkey; Type: FK CONSTRAINT
+--
+ALTER TABLE ONLY public.trips
+    ADD CONSTRAINT trips_city_code_fkey FOREIGN KEY (city_code) REFERENCES public.cities(code);
+
+--
+-- Name: geofences geofences_city_code_fkey; Type: FK CONSTRAINT
+--
+ALTER TABLE ONLY public.geofences
+    ADD CONSTRAINT geofences_city_code_fkey FOREIGN KEY (city_code) REFERENCES public.cities(code);
+
+--
+-- Name: driver_profiles driver_profiles_city_code_fkey; Type: FK CONSTRAINT
+--
+ALTER TABLE ONLY public.driver_profiles
+    ADD CONSTRAINT driver_profiles_city_code_fkey FOREIGN KEY (city_code) REFERENCES public.cities(code);
+
+--
+-- Name: commission_plans commission_plans_city_code_fkey; Type: FK CONSTRAINT
+--
+ALTER TABLE ONLY public.commission_plans
+    ADD CONSTRAINT commission_plans_city_code_fkey FOREIGN KEY (city_code) REFERENCES public.cities(code);
+
+--
+-- Name: promo_codes promo_codes_city_code_fkey; Type: FK CONSTRAINT
+--
+ALTER TABLE ONLY public.promo_codes
+    ADD CONSTRAINT promo_codes_city_code_fkey FOREIGN KEY (city_code) REFERENCES public.cities(code);
+
+--
+-- Name: incentive_programs; Type: TABLE
+--
+CREATE TABLE public.incentive_programs (
+    id uuid DEFAULT public.uuid_generate_v4() NOT NULL PRIMARY KEY,
+    name character varying(100) NOT NULL,
+    description text,
+    kind character varying(20) NOT NULL CHECK (kind IN ('quest', 'peak_guarantee', 'area_boost')),
+    city_code character varying(50) REFERENCES public.cities(code),
+    vehicle_type character varying(50),
+    geofence_id uuid REFERENCES public.geofences(id),
+    currency character varying(3) NOT NULL,
+    target_trips integer DEFAULT 0 NOT NULL CHECK (target_trips >= 0),
+    reward_amount numeric(10,2) DEFAULT 0.00 NOT NULL CHECK (reward_amount >= 0),
+    boost_rate numeric(5,4) DEFAULT 0 NOT NULL CHECK (boost_rate >= 0 AND boost_rate <= 1),
+    starts_at timestamp without time zone NOT NULL,
+    ends_at timestamp without time zone NOT NULL,
+    settled_at timestamp without time zone,
+    is_active boolean DEFAULT true NOT NULL,
+    created_by uuid REFERENCES public.users(id),
+    created_at timestamp without time zone DEFAULT CURRENT_TIMESTAMP,
+    updated_at timestamp without time zone DEFAULT CURRENT_TIMESTAMP,
+    CHECK (ends_at > starts_at),
+    CHECK (kind <> 'area_boost' OR geofence_id IS NOT NULL)
+);
+
+--
+-- Name: incentive_progress; Type: TABLE
+--
+CREATE TABLE public.incentive_progress (
+    program_id uuid NOT NULL REFERENCES public.incentive_programs(id) ON DELETE CASCADE,
+    driver_id uuid NOT NULL REFERENCES public.users(id),
+    trips_completed integer DEFAULT 0 NOT NULL,
+    fares_earned numeric(12,2) DEFAULT 0.00 NOT NULL,
+    bonus_earned numeric(12,2) DEFAULT 0.00 NOT NULL,
+    completed_at timestamp without time zone,
+    created_at timestamp without time zone DEFAULT CURRENT_TIMESTAMP,
+    updated_at timestamp without time zone DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (program_id, driver_id)
+);
+
+--
+-- Name: incentive_trips; Type: TABLE
+--
+CREATE TABLE public.incentive_trips (
+    program_id uuid NOT NULL REFERENCES public.incentive_programs(id) ON DELETE CASCADE,
+    trip_id uuid NOT NULL REFERENCES public.trips(id),
+    driver_id uuid NOT NULL REFERENCES public.users(id),
+    created_at timestamp without time zone DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (program_id, trip_id)
+);
+
+--
+-- Name: driver_earnings driver_earnings_incentive_program_id_fkey; Type: FK CONSTRAINT
+--
+ALTER TABLE ONLY public.driver_earnings
+    ADD CONSTRAINT driver_earnings_incentive_program_id_fkey FOREIGN KEY (incentive_program_id) REFERENCES public.incentive_programs(id);
+
+--
+-- Name: driver_earnings driver_earnings_source_check; Type: CHECK CONSTRAINT
+--
+ALTER TABLE ONLY public.driver_earnings
+    ADD CONSTRAINT driver_earnings_source_check CHECK ((trip_id IS NULL) <> (incentive_program_id IS NULL));
+
+--
+-- Name: driver_rating_entries; Type: TABLE
+--
+CREATE TABLE public.driver_rating_entries (
+    rating_id uuid NOT NULL PRIMARY KEY REFERENCES public.ratings(id) ON DELETE CASCADE,
+    driver_id uuid NOT NULL REFERENCES public.users(id),
+    trip_id uuid NOT NULL REFERENCES public.trips(id) ON DELETE CASCADE,
+    rating integer NOT NULL CHECK (rating >= 1 AND rating <= 5),
+    rated_at timestamp without time zone NOT NULL
+);
+
+--
+-- Name: driver_rating_stats; Type: TABLE
+--
+CREATE TABLE public.driver_rating_stats (
+    driver_id uuid NOT NULL PRIMARY KEY REFERENCES public.users(id),
+    rating_count integer DEFAULT 0 NOT NULL,
+    lifetime_average numeric(3,2) DEFAULT 0.00 NOT NULL,
+    rolling_count integer DEFAULT 0 NOT NULL,
+    rolling_average numeric(3,2) DEFAULT 0.00 NOT NULL,
+    one_star integer DEFAULT 0 NOT NULL,
+    two_star integer DEFAULT 0 NOT NULL,
+    three_star integer DEFAULT 0 NOT NULL,
+    four_star integer DEFAULT 0 NOT NULL,
+    five_star integer DEFAULT 0 NOT NULL,
+    updated_at timestamp without time zone DEFAULT CURRENT_TIMESTAMP
+);
+
+--
+-- Name: rating_tags; Type: TABLE
+--
+CREATE TABLE public.rating_tags (
+    rating_id uuid NOT NULL REFERENCES public.ratings(id) ON DELETE CASCADE,
+    tag character varying(30) NOT NULL,
+    PRIMARY KEY (rating_id, tag)
+);
+
+--
+-- Name: rating_disputes; Type: TABLE
+--
+CREATE TABLE public.rating_disputes (
+    id uuid DEFAULT public.uuid_generate_v4() NOT NULL PRIMARY KEY,
+    rating_id uuid NOT NULL UNIQUE REFERENCES public.ratings(id) ON DELETE CASCADE,
+    disputed_by uuid NOT NULL REFERENCES public.users(id),
+    reason text NOT NULL,
+    status character varying(20) DEFAULT 'pending' NOT NULL CHECK (status IN ('pending', 'accepted', 'rejected')),
+    resolution_note text,
+    reviewed_by uuid REFERENCES public.users(id),
+    reviewed_at timestamp without time zone,
+    created_at timestamp without time zone DEFAULT CURRENT_TIMESTAMP,
+    updated_at timestamp without time zone DEFAULT CURRENT_TIMESTAMP
+);
+
+--
+-- Name: rater_weights; Type: TABLE
+--
+CREATE TABLE public.rater_weights (
+    rater_id uuid NOT NULL REFERENCES public.users(id),
+    rater_type character varying(10) NOT NULL CHECK (rater_type IN ('rider', 'driver')),
+    ratings_given integer DEFAULT 0 NOT NULL,
+    average_given numeric(3,2) DEFAULT 0.00 NOT NULL,
+    weight numeric(4,3) DEFAULT 1.000 NOT NULL CHECK (weight > 0 AND weight <= 1),
+    updated_at timestamp without time zone DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (rater_id, rater_type)
+);
+
+--
+-- Name: driver_complaints; Type: TABLE
+--
+CREATE TABLE public.driver_complaints (
+    id uuid DEFAULT public.uuid_generate_v4() NOT NULL PRIMARY KEY,
+    driver_id uuid NOT NULL REFERENCES public.users(id),
+    trip_id uuid REFERENCES public.trips(id) ON DELETE SET NULL,
+    rating_id uuid REFERENCES public.ratings(id) ON DELETE CASCADE,
+    source character varying(20) NOT NULL CHECK (source IN ('rating', 'admin', 'support')),
+    category character varying(30) NOT NULL,
+    description text,
+    created_by uuid REFERENCES public.users(id),
+    created_at timestamp without time zone DEFAULT CURRENT_TIMESTAMP,
+    ticket_id uuid UNIQUE,
+    UNIQUE(rating_id, category)
+);
+
+--
+-- Name: driver_quality_scores; Type: TABLE
+--
+CREATE TABLE public.driver_quality_scores (
+    driver_id uuid NOT NULL PRIMARY KEY REFERENCES public.users(id) ON DELETE CASCADE,
+    score numeric(5,2) NOT NULL,
+    rating_average numeric(3,2),
+    acceptance_rate numeric(5,4),
+    cancellation_rate numeric(5,4),
+    complaint_count integer DEFAULT 0 NOT NULL,
+    completed_trips integer DEFAULT 0 NOT NULL,
+    level character varying(20) DEFAULT 'good' NOT NULL CHECK (level IN ('good', 'warning', 'suspended', 'review')),
+    period_start timestamp without time zone NOT NULL,
+    computed_at timestamp without time zone DEFAULT CURRENT_TIMESTAMP
+);
+
+--
+-- Name: driver_quality_actions; Type: TABLE
+--
+CREATE TABLE public.driver_quality_actions (
+    id uuid DEFAULT public.uuid_generate_v4() NOT NULL PRIMARY KEY,
+    driver_id uuid NOT NULL REFERENCES public.users(id) ON DELETE CASCADE,
+    action character varying(20) NOT NULL CHECK (action IN ('warning', 'suspension', 'review', 'reinstatement')),
+    score numeric(5,2),
+    reasons text,
+    suspended_until timestamp without time zone,
+    note text,
+    created_by uuid REFERENCES public.users(id),
+    created_at timestamp without time zone DEFAULT CURRENT_TIMESTAMP
+);
+
+--
+-- Name: device_tokens; Type: TABLE
+--
+CREATE TABLE public.device_tokens (
+    id uuid DEFAULT public.uuid_generate_v4() NOT NULL PRIMARY KEY,
+    user_id uuid NOT NULL REFERENCES public.users(id) ON DELETE CASCADE,
+    token character varying(512) UNIQUE NOT NULL,
+    platform character varying(10) NOT NULL CHECK (platform IN ('ios', 'android')),
+    created_at timestamp without time zone DEFAULT CURRENT_TIMESTAMP,
+    updated_at timestamp without time zone DEFAULT CURRENT_TIMESTAMP
+);
+
+--
+-- Name: notification_preferences; Type: TABLE
+--
+CREATE TABLE public.notification_preferences (
+    user_id uuid NOT NULL PRIMARY KEY REFERENCES public.users(id) ON DELETE CASCADE,
+    push_enabled boolean DEFAULT true NOT NULL,
+    sms_enabled boolean DEFAULT true NOT NULL,
+    locale character varying(10),
+    timezone character varying(64),
+    quiet_hours_start smallint CHECK (quiet_hours_start BETWEEN 0 AND 1439),
+    quiet_hours_end smallint CHECK (quiet_hours_end BETWEEN 0 AND 1439),
+    muted_categories text[] DEFAULT '{}' NOT NULL,
+    updated_at timestamp without time zone DEFAULT CURRENT_TIMESTAMP
+);
+
+--
+-- Name: notification_deliveries; Type: TABLE
+--
+CREATE TABLE public.notification_deliveries (
+    id uuid DEFAULT public.uuid_generate_v4() NOT NULL PRIMARY KEY,
+    user_id uuid NOT NULL REFERENCES public.users(id) ON DELETE CASCADE,
+    event_key character varying(255) NOT NULL,
+    template character varying(50) NOT NULL,
+    category character varying(20) NOT NULL,
+    channel character varying(10) NOT NULL CHECK (channel IN ('push', 'sms', 'inbox')),
+    address character varying(512) DEFAULT '' NOT NULL,
+    platform character varying(10),
+    title character varying(255) NOT NULL,
+    body text NOT NULL,
+    data jsonb,
+    status character varying(20) DEFAULT 'pending' NOT NULL CHECK (status IN ('pending', 'sent', 'failed')),
+    attempts integer DEFAULT 0 NOT NULL,
+    last_error text,
+    next_attempt_at timestamp without time zone DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    sent_at timestamp without time zone,
+    created_at timestamp without time zone DEFAULT CURRENT_TIMESTAMP,
+    updated_at timestamp without time zone DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(event_key, user_id, channel, address)
+);
+
+--
+-- Name: inbox_messages; Type: TABLE
+--
+CREATE TABLE public.inbox_messages (
+    id uuid DEFAULT public.uuid_generate_v4() NOT NULL PRIMARY KEY,
+    user_id uuid NOT NULL REFERENCES public.users(id) ON DELETE CASCADE,
+    delivery_id uuid UNIQUE REFERENCES public.notification_deliveries(id) ON DELETE SET NULL,
+    category character varying(20) NOT NULL,
+    title character varying(255) NOT NULL,
+    body text NOT NULL,
+    data jsonb,
+    read_at timestamp without time zone,
+    created_at timestamp without time zone DEFAULT CURRENT_TIMESTAMP
+);
+
+--
+-- Name: trip_messages; Type: TABLE
+--
+CREATE TABLE public.trip_messages (
+    id uuid DEFAULT public.uuid_generate_v4() NOT NULL PRIMARY KEY,
+    trip_id uuid NOT NULL REFERENCES public.trips(id) ON DELETE CASCADE,
+    sender_id uuid NOT NULL REFERENCES public.users(id) ON DELETE CASCADE,
+    sender_role character varying(10) NOT NULL CHECK (sender_role IN ('rider', 'driver')),
+    body text NOT NULL,
+    quick_reply character varying(50),
+    client_id character varying(64),
+    read_at timestamp without time zone,
+    created_at timestamp without time zone DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (trip_id, sender_id, client_id)
+);
+
+--
+-- Name: trip_call_sessions; Type: TABLE
+--
+CREATE TABLE public.trip_call_sessions (
+    id uuid DEFAULT public.uuid_generate_v4() NOT NULL PRIMARY KEY,
+    trip_id uuid NOT NULL REFERENCES public.trips(id) ON DELETE CASCADE,
+    provider_session_id character varying(100) NOT NULL,
+    proxy_number character varying(20) NOT NULL,
+    expires_at timestamp without time zone NOT NULL,
+    closed_at timestamp without time zone,
+    created_at timestamp without time zone DEFAULT CURRENT_TIMESTAMP
+);
+
+--
+-- Name: emergency_contacts; Type: TABLE
+--
+CREATE TABLE public.emergency_contacts (
+    id uuid DEFAULT public.uuid_generate_v4() NOT NULL PRIMARY KEY,
+    user_id uuid NOT NULL REFERENCES public.users(id) ON DELETE CASCADE,
+    name character varying(100) NOT NULL,
+    phone_number character varying(20) NOT NULL,
+    created_at timestamp without time zone DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_id, phone_number)
+);
+
+--
+-- Name: trip_share_links; Type: TABLE
+--
+CREATE TABLE public.trip_share_links (
+    id uuid DEFAULT public.uuid_generate_v4() NOT NULL PRIMARY KEY,
+    trip_id uuid NOT NULL REFERENCES public.trips(id) ON DELETE CASCADE,
+    created_by uuid NOT NULL REFERENCES public.users(id) ON DELETE CASCADE,
+    token_hash character varying(64) NOT NULL UNIQUE,
+    expires_at timestamp without time zone NOT NULL,
+    revoked_at timestamp without time zone,
+    created_at timestamp without time zone DEFAULT CURRENT_TIMESTAMP
+);
+
+--
+-- Name: safety_incidents; Type: TABLE
+--
+CREATE TABLE public.safety_incidents (
+    id uuid DEFAULT public.uuid_generate_v4() NOT NULL PRIMARY KEY,
+    trip_id uuid NOT NULL REFERENCES public.trips(id) ON DELETE CASCADE,
+    reporter_id uuid REFERENCES public.users(id) ON DELETE SET NULL,
+    kind character varying(20) NOT NULL CHECK (kind IN ('sos', 'route_deviation', 'long_stop', 'over_eta')),
+    status character varying(20) DEFAULT 'open' NOT NULL CHECK (status IN ('open', 'acknowledged', 'resolved')),
+    latitude numeric(10,8),
+    longitude numeric(11,8),
+    details text,
+    snapshot jsonb NOT NULL,
+    acknowledged_by uuid REFERENCES public.users(id) ON DELETE SET NULL,
+    acknowledged_at timestamp without time zone,
+    resolved_by uuid REFERENCES public.users(id) ON DELETE SET NULL,
+    resolved_at timestamp without time zone,
+    resolution_note text,
+    created_at timestamp without time zone DEFAULT CURRENT_TIMESTAMP,
+    updated_at timestamp without time zone DEFAULT CURRENT_TIMESTAMP
+);
+
+--
+-- Name: trip_safety_tracks; Type: TABLE
+--
+CREATE TABLE public.trip_safety_tracks (
+    trip_id uuid NOT NULL PRIMARY KEY REFERENCES public.trips(id) ON DELETE CASCADE,
+    planned_polyline text,
+    anchor_latitude numeric(10,8) NOT NULL,
+    anchor_longitude numeric(11,8) NOT NULL,
+    anchored_at timestamp without time zone NOT NULL,
+    last_seen_at timestamp without time zone NOT NULL,
+    created_at timestamp without time zone DEFAULT CURRENT_TIMESTAMP,
+    updated_at timestamp without time zone DEFAULT CURRENT_TIMESTAMP
+);
+
+--
+-- Name: support_tickets; Type: TABLE
+--
+CREATE TABLE public.support_tickets (
+    id uuid DEFAULT public.uuid_generate_v4() NOT NULL PRIMARY KEY,
+    user_id uuid NOT NULL REFERENCES public.users(id) ON DELETE CASCADE,
+    trip_id uuid REFERENCES public.trips(id) ON DELETE SET NULL,
+    driver_id uuid REFERENCES public.users(id) ON DELETE SET NULL,
+    category character varying(30) NOT NULL CHECK (category IN ('fare_dispute', 'lost_item', 'safety', 'driver_behavior', 'rider_behavior', 'payment', 'account', 'other')),
+    priority character varying(10) NOT NULL CHECK (priority IN ('low', 'normal', 'high', 'urgent')),
+    status character varying(20) DEFAULT 'open' NOT NULL CHECK (status IN ('open', 'awaiting_user', 'resolved')),
+    subject character varying(200) NOT NULL,
+    assigned_to uuid REFERENCES public.users(id) ON DELETE SET NULL,
+    assigned_at timestamp without time zone,
+    first_response_due_at timestamp without time zone NOT NULL,
+    first_responded_at timestamp without time zone,
+    first_response_breached_at timestamp without time zone,
+    resolution_due_at timestamp without time zone NOT NULL,
+    resolution_breached_at timestamp without time zone,
+    resolution character varying(20) CHECK (resolution IN ('none', 'refund', 'fare_adjustment')),
+    resolution_note text,
+    adjustment_amount numeric(10,2),
+    adjustment_status character varying(20) CHECK (adjustment_status IN ('pending', 'applied', 'failed')),
+    adjustment_transaction_id uuid REFERENCES public.ledger_transactions(id),
+    adjustment_error text,
+    resolved_by uuid REFERENCES public.users(id) ON DELETE SET NULL,
+    resolved_at timestamp without time zone,
+    created_at timestamp without time zone DEFAULT CURRENT_TIMESTAMP,
+    updated_at timestamp without time zone DEFAULT CURRENT_TIMESTAMP
+);
+
+--
+-- Name: driver_complaints driver_complaints_ticket_id_fkey; Type: FK CONSTRAINT
+--
+ALTER TABLE ONLY public.driver_complaints
+    ADD CONSTRAINT driver_complaints_ticket_id_fkey FOREIGN KEY (ticket_id) REFERENCES public.support_tickets(id) ON DELETE CASCADE;
+
+--
+-- Name: support_messages; Type: TABLE
+--
+CREATE TABLE public.support_messages (
+    id uuid DEFAULT public.uuid_generate_v4() NOT NULL PRIMARY KEY,
+    ticket_id uuid NOT NULL REFERENCES public.support_tickets(id) ON DELETE CASCADE,
+    author_id uuid REFERENCES public.users(id) ON DELETE SET NULL,
+    author_role character varying(10) NOT NULL CHECK (author_role IN ('user', 'driver', 'agent')),
+    body text NOT NULL,
+    internal boolean DEFAULT false NOT NULL,
+    created_at timestamp without time zone DEFAULT CURRENT_TIMESTAMP
+);
+
+--
+-- Name: support_attachments; Type: TABLE
+--
+CREATE TABLE public.support_attachments (
+    id uuid DEFAULT public.uuid_generate_v4() NOT NULL PRIMARY KEY,
+    ticket_id uuid NOT NULL REFERENCES public.support_tickets(id) ON DELETE CASCADE,
+    uploaded_by uuid REFERENCES public.users(id) ON DELETE SET NULL,
+    file_name character varying(255) NOT NULL,
+    content_type character varying(100) NOT NULL,
+    size_bytes bigint NOT NULL CHECK (size_bytes > 0),
+    blob_key character varying(255) NOT NULL UNIQUE,
+    created_at timestamp without time zone DEFAULT CURRENT_TIMESTAMP
+);
+
 --
 -- Name: idx_users_phone; Type: INDEX
 --
@@ -148,6 +999,66 @@ CREATE INDEX idx_ratings_rated_id ON public.ratings USING btree (rated_id);
 CREATE INDEX idx_ride_requests_driver_id ON public.ride_requests USING btree (driver_id);
 CREATE INDEX idx_ride_requests_trip_id ON public.ride_requests USING btree (trip_id);
 CREATE INDEX idx_ride_requests_status ON public.ride_requests USING btree (status);
+CREATE UNIQUE INDEX idx_ledger_accounts_system ON public.ledger_accounts USING btree (account_type, currency) WHERE owner_id IS NULL;
+CREATE INDEX idx_ledger_accounts_owner_id ON public.ledger_accounts USING btree (owner_id);
+CREATE INDEX idx_ledger_transactions_trip_id ON public.ledger_transactions USING btree (trip_id);
+CREATE INDEX idx_ledger_transactions_created_at ON public.ledger_transactions USING btree (created_at);
+CREATE INDEX idx_ledger_entries_transaction_id ON public.ledger_entries USING btree (transaction_id);
+CREATE INDEX idx_ledger_entries_account_id ON public.ledger_entries USING btree (account_id);
+CREATE INDEX idx_commission_plans_vehicle_type ON public.commission_plans USING btree (vehicle_type);
+CREATE INDEX idx_driver_earnings_driver_id_earned_at ON public.driver_earnings USING btree (driver_id, earned_at);
+CREATE INDEX idx_driver_earnings_payout_item_id ON public.driver_earnings USING btree (payout_item_id);
+CREATE INDEX idx_payout_batches_status ON public.payout_batches USING btree (status);
+CREATE INDEX idx_payout_items_batch_id ON public.payout_items USING btree (batch_id);
+CREATE INDEX idx_payout_items_driver_id ON public.payout_items USING btree (driver_id);
+CREATE INDEX idx_promo_codes_auto_apply ON public.promo_codes USING btree (auto_apply) WHERE is_active;
+CREATE INDEX idx_promo_redemptions_promo_user ON public.promo_redemptions USING btree (promo_code_id, user_id);
+CREATE INDEX idx_referrals_referrer_id ON public.referrals USING btree (referrer_id);
+CREATE INDEX idx_trips_cancelled_by ON public.trips USING btree (driver_id, cancelled_by) WHERE status = 'cancelled';
+CREATE INDEX idx_trips_scheduled_pickup_at ON public.trips USING btree (pickup_at) WHERE status = 'scheduled';
+CREATE INDEX idx_trips_reserved_driver_id ON public.trips USING btree (reserved_driver_id) WHERE reserved_driver_id IS NOT NULL;
+CREATE INDEX idx_trip_stops_trip_id ON public.trip_stops USING btree (trip_id);
+CREATE INDEX idx_trips_pool_id ON public.trips USING btree (pool_id) WHERE pool_id IS NOT NULL;
+CREATE INDEX idx_trip_pools_open ON public.trip_pools USING btree (created_at) WHERE status = 'open';
+CREATE INDEX idx_pool_waypoints_pool_id ON public.pool_waypoints USING btree (pool_id);
+CREATE INDEX idx_trips_trip_type ON public.trips USING btree (trip_type);
+CREATE INDEX idx_saved_places_user_id ON public.saved_places USING btree (user_id);
+CREATE UNIQUE INDEX idx_saved_places_user_label ON public.saved_places USING btree (user_id, label) WHERE label IN ('home', 'work');
+CREATE INDEX idx_geofences_bounds ON public.geofences USING btree (min_latitude, max_latitude, min_longitude, max_longitude) WHERE active;
+CREATE INDEX idx_geofences_city_code ON public.geofences USING btree (city_code);
+CREATE INDEX idx_driver_geofences_geofence_id ON public.driver_geofences USING btree (geofence_id);
+CREATE INDEX idx_geofences_parent_id ON public.geofences USING btree (parent_id) WHERE (parent_id IS NOT NULL);
+CREATE INDEX idx_airport_queue_entries_airport ON public.airport_queue_entries USING btree (airport_id, joined_at, driver_id);
+CREATE INDEX idx_trips_city_code ON public.trips USING btree (city_code, status);
+CREATE INDEX idx_driver_profiles_city_code ON public.driver_profiles USING btree (city_code);
+CREATE INDEX idx_incentive_programs_window ON public.incentive_programs USING btree (starts_at, ends_at) WHERE (is_active = true);
+CREATE INDEX idx_incentive_progress_driver_id ON public.incentive_progress USING btree (driver_id);
+CREATE INDEX idx_driver_earnings_incentive_program_id ON public.driver_earnings USING btree (incentive_program_id) WHERE (incentive_program_id IS NOT NULL);
+CREATE INDEX idx_driver_rating_entries_driver_id_rated_at ON public.driver_rating_entries USING btree (driver_id, rated_at DESC);
+CREATE INDEX idx_ratings_rated_id_rater_type ON public.ratings USING btree (rated_id, rater_type, created_at DESC);
+CREATE INDEX idx_ratings_feedback_flagged ON public.ratings USING btree (created_at) WHERE (feedback_flagged AND (excluded_at IS NULL));
+CREATE INDEX idx_ratings_rater_id_rater_type ON public.ratings USING btree (rater_id, rater_type);
+CREATE INDEX idx_rating_disputes_status ON public.rating_disputes USING btree (status, created_at);
+CREATE INDEX idx_rating_disputes_disputed_by ON public.rating_disputes USING btree (disputed_by);
+CREATE INDEX idx_driver_complaints_driver_id_created_at ON public.driver_complaints USING btree (driver_id, created_at);
+CREATE INDEX idx_driver_quality_scores_level ON public.driver_quality_scores USING btree (level);
+CREATE INDEX idx_driver_quality_actions_driver_id_created_at ON public.driver_quality_actions USING btree (driver_id, created_at);
+CREATE INDEX idx_device_tokens_user_id ON public.device_tokens USING btree (user_id);
+CREATE INDEX idx_notification_deliveries_due ON public.notification_deliveries USING btree (next_attempt_at) WHERE status = 'pending';
+CREATE INDEX idx_inbox_messages_user_id_created_at ON public.inbox_messages USING btree (user_id, created_at);
+CREATE INDEX idx_trip_messages_trip_id_created_at ON public.trip_messages USING btree (trip_id, created_at);
+CREATE UNIQUE INDEX idx_trip_call_sessions_open ON public.trip_call_sessions USING btree (trip_id) WHERE closed_at IS NULL;
+CREATE INDEX idx_emergency_contacts_user_id ON public.emergency_contacts USING btree (user_id);
+CREATE INDEX idx_trip_share_links_trip_id ON public.trip_share_links USING btree (trip_id);
+CREATE UNIQUE INDEX idx_safety_incidents_unresolved ON public.safety_incidents USING btree (trip_id, kind) WHERE status <> 'resolved';
+CREATE INDEX idx_safety_incidents_status_created_at ON public.safety_incidents USING btree (status, created_at);
+CREATE INDEX idx_support_tickets_user_id_created_at ON public.support_tickets USING btree (user_id, created_at);
+CREATE INDEX idx_support_tickets_driver_id ON public.support_tickets USING btree (driver_id) WHERE driver_id IS NOT NULL;
+CREATE INDEX idx_support_tickets_status_priority ON public.support_tickets USING btree (status, priority, created_at);
+CREATE INDEX idx_support_tickets_assigned_to ON public.support_tickets USING btree (assigned_to) WHERE assigned_to IS NOT NULL;
+CREATE UNIQUE INDEX idx_support_tickets_unresolved ON public.support_tickets USING btree (user_id, trip_id, category) WHERE status <> 'resolved';
+CREATE INDEX idx_support_messages_ticket_id_created_at ON public.support_messages USING btree (ticket_id, created_at);
+CREATE INDEX idx_support_attachments_ticket_id ON public.support_attachments USING btree (ticket_id);
 
 --
 -- Name: users update_users_updated_at; Type: TRIGGER
@@ -164,6 +1075,137 @@ CREATE TRIGGER update_driver_profiles_updated_at BEFORE UPDATE ON public.driver_
 --
 CREATE TRIGGER update_trips_updated_at BEFORE UPDATE ON public.trips FOR EACH ROW EXECUTE FUNCTION public.update_updated_at_column();
 
+--
+-- Name: ledger_transactions ledger_transactions_append_only; Type: TRIGGER
+--
+CREATE TRIGGER ledger_transactions_append_only BEFORE UPDATE OR DELETE ON public.ledger_transactions FOR EACH ROW EXECUTE FUNCTION public.prevent_ledger_mutation();
+
+--
+-- Name: ledger_entries ledger_entries_append_only; Type: TRIGGER
+--
+CREATE TRIGGER ledger_entries_append_only BEFORE UPDATE OR DELETE ON public.ledger_entries FOR EACH ROW EXECUTE FUNCTION public.prevent_ledger_mutation();
+
+--
+-- Name: ledger_entries ledger_entries_balanced; Type: TRIGGER
+--
+CREATE CONSTRAINT TRIGGER ledger_entries_balanced AFTER INSERT ON public.ledger_entries DEFERRABLE INITIALLY DEFERRED FOR EACH ROW EXECUTE FUNCTION public.check_ledger_transaction_balanced();
+
+--
+-- Name: commission_plans update_commission_plans_updated_at; Type: TRIGGER
+--
+CREATE TRIGGER update_commission_plans_updated_at BEFORE UPDATE ON public.commission_plans FOR EACH ROW EXECUTE FUNCTION public.update_updated_at_column();
+
+--
+-- Name: payout_batches update_payout_batches_updated_at; Type: TRIGGER
+--
+CREATE TRIGGER update_payout_batches_updated_at BEFORE UPDATE ON public.payout_batches FOR EACH ROW EXECUTE FUNCTION public.update_updated_at_column();
+
+--
+-- Name: payout_items update_payout_items_updated_at; Type: TRIGGER
+--
+CREATE TRIGGER update_payout_items_updated_at BEFORE UPDATE ON public.payout_items FOR EACH ROW EXECUTE FUNCTION public.update_updated_at_column();
+
+--
+-- Name: promo_codes update_promo_codes_updated_at; Type: TRIGGER
+--
+CREATE TRIGGER update_promo_codes_updated_at BEFORE UPDATE ON public.promo_codes FOR EACH ROW EXECUTE FUNCTION public.update_updated_at_column();
+
+--
+-- Name: promo_redemptions update_promo_redemptions_updated_at; Type: TRIGGER
+--
+CREATE TRIGGER update_promo_redemptions_updated_at BEFORE UPDATE ON public.promo_redemptions FOR EACH ROW EXECUTE FUNCTION public.update_updated_at_column();
+
+--
+-- Name: cancellation_policies update_cancellation_policies_updated_at; Type: TRIGGER
+--
+CREATE TRIGGER update_cancellation_policies_updated_at BEFORE UPDATE ON public.cancellation_policies FOR EACH ROW EXECUTE FUNCTION public.update_updated_at_column();
+
+--
+-- Name: trip_stops update_trip_stops_updated_at; Type: TRIGGER
+--
+CREATE TRIGGER update_trip_stops_updated_at BEFORE UPDATE ON public.trip_stops FOR EACH ROW EXECUTE FUNCTION public.update_updated_at_column();
+
+--
+-- Name: trip_pools update_trip_pools_updated_at; Type: TRIGGER
+--
+CREATE TRIGGER update_trip_pools_updated_at BEFORE UPDATE ON public.trip_pools FOR EACH ROW EXECUTE FUNCTION public.update_updated_at_column();
+
+--
+-- Name: pool_waypoints update_pool_waypoints_updated_at; Type: TRIGGER
+--
+CREATE TRIGGER update_pool_waypoints_updated_at BEFORE UPDATE ON public.pool_waypoints FOR EACH ROW EXECUTE FUNCTION public.update_updated_at_column();
+
+--
+-- Name: parcel_deliveries update_parcel_deliveries_updated_at; Type: TRIGGER
+--
+CREATE TRIGGER update_parcel_deliveries_updated_at BEFORE UPDATE ON public.parcel_deliveries FOR EACH ROW EXECUTE FUNCTION public.update_updated_at_column();
+
+--
+-- Name: saved_places update_saved_places_updated_at; Type: TRIGGER
+--
+CREATE TRIGGER update_saved_places_updated_at BEFORE UPDATE ON public.saved_places FOR EACH ROW EXECUTE FUNCTION public.update_updated_at_column();
+
+--
+-- Name: geofences update_geofences_updated_at; Type: TRIGGER
+--
+CREATE TRIGGER update_geofences_updated_at BEFORE UPDATE ON public.geofences FOR EACH ROW EXECUTE FUNCTION public.update_updated_at_column();
+
+--
+-- Name: cities update_cities_updated_at; Type: TRIGGER
+--
+CREATE TRIGGER update_cities_updated_at BEFORE UPDATE ON public.cities FOR EACH ROW EXECUTE FUNCTION public.update_updated_at_column();
+
+--
+-- Name: incentive_programs update_incentive_programs_updated_at; Type: TRIGGER
+--
+CREATE TRIGGER update_incentive_programs_updated_at BEFORE UPDATE ON public.incentive_programs FOR EACH ROW EXECUTE FUNCTION public.update_updated_at_column();
+
+--
+-- Name: incentive_progress update_incentive_progress_updated_at; Type: TRIGGER
+--
+CREATE TRIGGER update_incentive_progress_updated_at BEFORE UPDATE ON public.incentive_progress FOR EACH ROW EXECUTE FUNCTION public.update_updated_at_column();
+
+
+
+
+
+--
+-- Name: rating_disputes update_rating_disputes_updated_at; Type: TRIGGER
+--
+CREATE TRIGGER update_rating_disputes_updated_at BEFORE UPDATE ON public.rating_disputes FOR EACH ROW EXECUTE FUNCTION public.update_updated_at_column();
+
+
+
+--
+-- Name: device_tokens update_device_tokens_updated_at; Type: TRIGGER
+--
+CREATE TRIGGER update_device_tokens_updated_at BEFORE UPDATE ON public.device_tokens FOR EACH ROW EXECUTE FUNCTION public.update_updated_at_column();
+
+--
+-- Name: notification_preferences update_notification_preferences_updated_at; Type: TRIGGER
+--
+CREATE TRIGGER update_notification_preferences_updated_at BEFORE UPDATE ON public.notification_preferences FOR EACH ROW EXECUTE FUNCTION public.update_updated_at_column();
+
+--
+-- Name: notification_deliveries update_notification_deliveries_updated_at; Type: TRIGGER
+--
+CREATE TRIGGER update_notification_deliveries_updated_at BEFORE UPDATE ON public.notification_deliveries FOR EACH ROW EXECUTE FUNCTION public.update_updated_at_column();
+
+--
+-- Name: safety_incidents update_safety_incidents_updated_at; Type: TRIGGER
+--
+CREATE TRIGGER update_safety_incidents_updated_at BEFORE UPDATE ON public.safety_incidents FOR EACH ROW EXECUTE FUNCTION public.update_updated_at_column();
+
+--
+-- Name: trip_safety_tracks update_trip_safety_tracks_updated_at; Type: TRIGGER
+--
+CREATE TRIGGER update_trip_safety_tracks_updated_at BEFORE UPDATE ON public.trip_safety_tracks FOR EACH ROW EXECUTE FUNCTION public.update_updated_at_column();
+
+--
+-- Name: support_tickets update_support_tickets_updated_at; Type: TRIGGER
+--
+CREATE TRIGGER update_support_tickets_updated_at BEFORE UPDATE ON public.support_tickets FOR EACH ROW EXECUTE FUNCTION public.update_updated_at_column();
+
 --
 -- PostgreSQL database dump complete
 --
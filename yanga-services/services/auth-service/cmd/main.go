@@ -65,6 +65,7 @@ func main() {
 	router := mux.NewRouter()
 
 	// Setup middleware
+	router.Use(middleware.RequestIDMiddleware)
 	router.Use(middleware.LoggingMiddleware)
 	router.Use(middleware.CORSMiddleware)
 
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -68,11 +69,12 @@ func (s *AuthService) Register(ctx context.Context, req *domain.RegisterRequest)
 
 	// Publish user created event
 	userID, _ := uuid.FromBytes(user.ID.Bytes[:])
-	s.eventBus.Publish(events.SubjectUserCreated, events.UserCreatedPayload{
-		UserID:      userID.String(),
-		PhoneNumber: user.PhoneNumber,
-		Role:        user.Role,
-		CreatedAt:   user.CreatedAt.Time,
+	s.eventBus.Publish(ctx, events.SubjectUserCreated, events.UserCreatedPayload{
+		UserID:       userID.String(),
+		PhoneNumber:  user.PhoneNumber,
+		Role:         user.Role,
+		ReferralCode: strings.ToUpper(strings.TrimSpace(req.ReferralCode)),
+		CreatedAt:    user.CreatedAt.Time,
 	})
 
 	return &domain.AuthResponse{
@@ -0,0 +1,43 @@
+// Command backfill-ratings recomputes every driver's rating aggregates, and
+// the rating and trip count on their profile, from the ratings and trips
+// tables. It is safe to run while driver-service is running.
+package main
+
+import (
+	"context"
+	"log"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/namycodes/yanga-services/services/driver-service/internal/db"
+	"github.com/namycodes/yanga-services/services/driver-service/internal/repository"
+	"github.com/namycodes/yanga-services/services/driver-service/internal/service"
+	"github.com/namycodes/yanga-services/shared-lib/config"
+)
+
+func main() {
+	cfg := config.Load()
+
+	dbPool, err := pgxpool.New(context.Background(), cfg.DatabaseURL())
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer dbPool.Close()
+
+	if err := dbPool.Ping(context.Background()); err != nil {
+		log.Fatalf("Failed to ping database: %v", err)
+	}
+
+	queries := db.New(dbPool)
+	driverRatingService := service.NewDriverRatingService(
+		repository.NewDriverRatingRepository(queries),
+		repository.NewDriverRepository(queries),
+		nil,
+		cfg,
+	)
+
+	drivers, err := driverRatingService.Backfill(context.Background())
+	if err != nil {
+		log.Fatalf("Backfill stopped after %d drivers: %v", drivers, err)
+	}
+	log.Printf("✅ Recomputed ratings for %d drivers", drivers)
+}
@@ -21,6 +21,7 @@ import (
 	"github.com/namycodes/yanga-services/shared-lib/config"
 	"github.com/namycodes/yanga-services/shared-lib/events"
 	"github.com/namycodes/yanga-services/shared-lib/middleware"
+	"github.com/namycodes/yanga-services/shared-lib/routing"
 )
 
 // @title Driver Service API
@@ -51,14 +52,47 @@ func main() {
 
 	queries := db.New(dbPool)
 	driverRepo := repository.NewDriverRepository(queries)
-	driverService := service.NewDriverService(driverRepo, eventBus)
+	roadRouter := routing.New(cfg)
+	driverService := service.NewDriverService(driverRepo, roadRouter, eventBus)
 	driverHandler := handler.NewDriverHandler(driverService)
 
+	earningsRepo := repository.NewEarningsRepository(dbPool, queries)
+	earningsService := service.NewEarningsService(earningsRepo, driverRepo, eventBus, cfg)
+	earningsHandler := handler.NewEarningsHandler(earningsService)
+
+	metricsRepo := repository.NewMetricsRepository(queries)
+	metricsService := service.NewMetricsService(metricsRepo)
+	metricsHandler := handler.NewMetricsHandler(metricsService)
+
+	incentiveRepo := repository.NewIncentiveRepository(dbPool, queries)
+	incentiveService := service.NewIncentiveService(incentiveRepo, driverRepo, eventBus, cfg)
+	incentiveHandler := handler.NewIncentiveHandler(incentiveService)
+
+	driverRatingRepo := repository.NewDriverRatingRepository(queries)
+	driverRatingService := service.NewDriverRatingService(driverRatingRepo, driverRepo, eventBus, cfg)
+	driverRatingHandler := handler.NewDriverRatingHandler(driverRatingService)
+
+	qualityRepo := repository.NewQualityRepository(dbPool, queries)
+	qualityService := service.NewQualityService(qualityRepo, metricsRepo, driverRepo, eventBus, cfg)
+	qualityHandler := handler.NewQualityHandler(qualityService)
+
+	// Subscribe to events
+	earningsService.SubscribeToEvents()
+	incentiveService.SubscribeToEvents()
+	driverRatingService.SubscribeToEvents()
+	qualityService.SubscribeToEvents()
+
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+	go incentiveService.RunSettlementWorker(workerCtx)
+	go qualityService.RunQualityWorker(workerCtx)
+
 	router := mux.NewRouter()
+	router.Use(middleware.RequestIDMiddleware)
 	router.Use(middleware.LoggingMiddleware)
 	router.Use(middleware.CORSMiddleware)
 
-	routes.SetupDriverRoutes(router, driverHandler, config.JWTConfig{Secret: cfg.JWTSecret})
+	routes.SetupDriverRoutes(router, driverHandler, earningsHandler, metricsHandler, incentiveHandler, driverRatingHandler, qualityHandler, config.JWTConfig{Secret: cfg.JWTSecret, IdentitySecret: cfg.GatewayIdentitySecret})
 
 	router.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
@@ -86,6 +120,7 @@ func main() {
 	<-quit
 
 	log.Println("🛑 Shutting down server...")
+	stopWorkers()
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
@@ -18,10 +18,11 @@ INSERT INTO driver_profiles (
     vehicle_type,
     vehicle_model,
     vehicle_color,
-    vehicle_plate_number
+    vehicle_plate_number,
+    city_code
 ) VALUES (
-    $1, $2, $3, $4, $5, $6
-) RETURNING id, user_id, license_number, vehicle_type, vehicle_model, vehicle_color, vehicle_plate_number, is_online, is_approved, rating, total_trips, current_latitude, current_longitude, created_at, updated_at
+    $1, $2, $3, $4, $5, $6, $7
+) RETURNING id, user_id, license_number, vehicle_type, vehicle_model, vehicle_color, vehicle_plate_number, is_online, is_approved, rating, total_trips, current_latitude, current_longitude, created_at, updated_at, city_code, dispatch_suspended_until
 `
 
 type CreateDriverProfileParams struct {
@@ -31,6 +32,7 @@ type CreateDriverProfileParams struct {
 	VehicleModel       string      `json:"vehicle_model"`
 	VehicleColor       string      `json:"vehicle_color"`
 	VehiclePlateNumber string      `json:"vehicle_plate_number"`
+	CityCode           pgtype.Text `json:"city_code"`
 }
 
 func (q *Queries) CreateDriverProfile(ctx context.Context, arg CreateDriverProfileParams) (DriverProfile, error) {
@@ -41,6 +43,7 @@ func (q *Queries) CreateDriverProfile(ctx context.Context, arg CreateDriverProfi
 		arg.VehicleModel,
 		arg.VehicleColor,
 		arg.VehiclePlateNumber,
+		arg.CityCode,
 	)
 	var i DriverProfile
 	err := row.Scan(
@@ -59,12 +62,14 @@ func (q *Queries) CreateDriverProfile(ctx context.Context, arg CreateDriverProfi
 		&i.CurrentLongitude,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.CityCode,
+		&i.DispatchSuspendedUntil,
 	)
 	return i, err
 }
 
 const getDriverProfile = `-- name: GetDriverProfile :one
-SELECT id, user_id, license_number, vehicle_type, vehicle_model, vehicle_color, vehicle_plate_number, is_online, is_approved, rating, total_trips, current_latitude, current_longitude, created_at, updated_at FROM driver_profiles
+SELECT id, user_id, license_number, vehicle_type, vehicle_model, vehicle_color, vehicle_plate_number, is_online, is_approved, rating, total_trips, current_latitude, current_longitude, created_at, updated_at, city_code, dispatch_suspended_until FROM driver_profiles
 WHERE id = $1 LIMIT 1
 `
 
@@ -87,12 +92,14 @@ func (q *Queries) GetDriverProfile(ctx context.Context, id pgtype.UUID) (DriverP
 		&i.CurrentLongitude,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.CityCode,
+		&i.DispatchSuspendedUntil,
 	)
 	return i, err
 }
 
 const getDriverProfileByUserID = `-- name: GetDriverProfileByUserID :one
-SELECT id, user_id, license_number, vehicle_type, vehicle_model, vehicle_color, vehicle_plate_number, is_online, is_approved, rating, total_trips, current_latitude, current_longitude, created_at, updated_at FROM driver_profiles
+SELECT id, user_id, license_number, vehicle_type, vehicle_model, vehicle_color, vehicle_plate_number, is_online, is_approved, rating, total_trips, current_latitude, current_longitude, created_at, updated_at, city_code, dispatch_suspended_until FROM driver_profiles
 WHERE user_id = $1 LIMIT 1
 `
 
@@ -115,6 +122,8 @@ func (q *Queries) GetDriverProfileByUserID(ctx context.Context, userID pgtype.UU
 		&i.CurrentLongitude,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.CityCode,
+		&i.DispatchSuspendedUntil,
 	)
 	return i, err
 }
@@ -157,17 +166,17 @@ SELECT
     dp.created_at,
     dp.updated_at,
     u.full_name,
-    u.phone_number,
     u.profile_image_url,
     (6371 * acos(
         cos(radians($1)) * cos(radians(dp.current_latitude)) *
         cos(radians(dp.current_longitude) - radians($2)) +
         sin(radians($1)) * sin(radians(dp.current_latitude))
-    )) AS distance
+    ))::float8 AS distance
 FROM driver_profiles dp
 JOIN users u ON dp.user_id = u.id
 WHERE dp.is_online = TRUE 
     AND dp.is_approved = TRUE
+    AND (dp.dispatch_suspended_until IS NULL OR dp.dispatch_suspended_until <= CURRENT_TIMESTAMP)
     AND dp.current_latitude IS NOT NULL
     AND dp.current_longitude IS NOT NULL
     AND (6371 * acos(
@@ -203,9 +212,8 @@ type GetNearbyDriversRow struct {
 	CreatedAt          pgtype.Timestamp `json:"created_at"`
 	UpdatedAt          pgtype.Timestamp `json:"updated_at"`
 	FullName           string           `json:"full_name"`
-	PhoneNumber        string           `json:"phone_number"`
 	ProfileImageUrl    pgtype.Text      `json:"profile_image_url"`
-	Distance           int32            `json:"distance"`
+	Distance           float64          `json:"distance"`
 }
 
 func (q *Queries) GetNearbyDrivers(ctx context.Context, arg GetNearbyDriversParams) ([]GetNearbyDriversRow, error) {
@@ -239,7 +247,6 @@ func (q *Queries) GetNearbyDrivers(ctx context.Context, arg GetNearbyDriversPara
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.FullName,
-			&i.PhoneNumber,
 			&i.ProfileImageUrl,
 			&i.Distance,
 		); err != nil {
@@ -254,10 +261,11 @@ func (q *Queries) GetNearbyDrivers(ctx context.Context, arg GetNearbyDriversPara
 }
 
 const getOnlineDrivers = `-- name: GetOnlineDrivers :many
-SELECT dp.id, dp.user_id, dp.license_number, dp.vehicle_type, dp.vehicle_model, dp.vehicle_color, dp.vehicle_plate_number, dp.is_online, dp.is_approved, dp.rating, dp.total_trips, dp.current_latitude, dp.current_longitude, dp.created_at, dp.updated_at, u.full_name, u.phone_number, u.profile_image_url
+SELECT dp.id, dp.user_id, dp.license_number, dp.vehicle_type, dp.vehicle_model, dp.vehicle_color, dp.vehicle_plate_number, dp.is_online, dp.is_approved, dp.rating, dp.total_trips, dp.current_latitude, dp.current_longitude, dp.created_at, dp.updated_at, dp.city_code, dp.dispatch_suspended_until, u.full_name, u.phone_number, u.profile_image_url
 FROM driver_profiles dp
 JOIN users u ON dp.user_id = u.id
 WHERE dp.is_online = TRUE AND dp.is_approved = TRUE
+    AND (dp.dispatch_suspended_until IS NULL OR dp.dispatch_suspended_until <= CURRENT_TIMESTAMP)
 ORDER BY dp.rating DESC
 LIMIT $1 OFFSET $2
 `
@@ -268,24 +276,26 @@ type GetOnlineDriversParams struct {
 }
 
 type GetOnlineDriversRow struct {
-	ID                 pgtype.UUID      `json:"id"`
-	UserID             pgtype.UUID      `json:"user_id"`
-	LicenseNumber      string           `json:"license_number"`
-	VehicleType        string           `json:"vehicle_type"`
-	VehicleModel       string           `json:"vehicle_model"`
-	VehicleColor       string           `json:"vehicle_color"`
-	VehiclePlateNumber string           `json:"vehicle_plate_number"`
-	IsOnline           pgtype.Bool      `json:"is_online"`
-	IsApproved         pgtype.Bool      `json:"is_approved"`
-	Rating             pgtype.Numeric   `json:"rating"`
-	TotalTrips         pgtype.Int4      `json:"total_trips"`
-	CurrentLatitude    pgtype.Numeric   `json:"current_latitude"`
-	CurrentLongitude   pgtype.Numeric   `json:"current_longitude"`
-	CreatedAt          pgtype.Timestamp `json:"created_at"`
-	UpdatedAt          pgtype.Timestamp `json:"updated_at"`
-	FullName           string           `json:"full_name"`
-	PhoneNumber        string           `json:"phone_number"`
-	ProfileImageUrl    pgtype.Text      `json:"profile_image_url"`
+	ID                     pgtype.UUID      `json:"id"`
+	UserID                 pgtype.UUID      `json:"user_id"`
+	LicenseNumber          string           `json:"license_number"`
+	VehicleType            string           `json:"vehicle_type"`
+	VehicleModel           string           `json:"vehicle_model"`
+	VehicleColor           string           `json:"vehicle_color"`
+	VehiclePlateNumber     string           `json:"vehicle_plate_number"`
+	IsOnline               pgtype.Bool      `json:"is_online"`
+	IsApproved             pgtype.Bool      `json:"is_approved"`
+	Rating                 pgtype.Numeric   `json:"rating"`
+	TotalTrips             pgtype.Int4      `json:"total_trips"`
+	CurrentLatitude        pgtype.Numeric   `json:"current_latitude"`
+	CurrentLongitude       pgtype.Numeric   `json:"current_longitude"`
+	CreatedAt              pgtype.Timestamp `json:"created_at"`
+	UpdatedAt              pgtype.Timestamp `json:"updated_at"`
+	CityCode               pgtype.Text      `json:"city_code"`
+	DispatchSuspendedUntil pgtype.Timestamp `json:"dispatch_suspended_until"`
+	FullName               string           `json:"full_name"`
+	PhoneNumber            string           `json:"phone_number"`
+	ProfileImageUrl        pgtype.Text      `json:"profile_image_url"`
 }
 
 func (q *Queries) GetOnlineDrivers(ctx context.Context, arg GetOnlineDriversParams) ([]GetOnlineDriversRow, error) {
@@ -313,6 +323,8 @@ func (q *Queries) GetOnlineDrivers(ctx context.Context, arg GetOnlineDriversPara
 			&i.CurrentLongitude,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.CityCode,
+			&i.DispatchSuspendedUntil,
 			&i.FullName,
 			&i.PhoneNumber,
 			&i.ProfileImageUrl,
@@ -356,9 +368,10 @@ SET
     vehicle_color = COALESCE($4, vehicle_color),
     vehicle_plate_number = COALESCE($5, vehicle_plate_number),
     is_approved = COALESCE($6, is_approved),
+    city_code = COALESCE($7, city_code),
     updated_at = CURRENT_TIMESTAMP
-WHERE id = $7
-RETURNING id, user_id, license_number, vehicle_type, vehicle_model, vehicle_color, vehicle_plate_number, is_online, is_approved, rating, total_trips, current_latitude, current_longitude, created_at, updated_at
+WHERE id = $8
+RETURNING id, user_id, license_number, vehicle_type, vehicle_model, vehicle_color, vehicle_plate_number, is_online, is_approved, rating, total_trips, current_latitude, current_longitude, created_at, updated_at, city_code, dispatch_suspended_until
 `
 
 type UpdateDriverProfileParams struct {
@@ -368,6 +381,7 @@ type UpdateDriverProfileParams struct {
 	VehicleColor       pgtype.Text `json:"vehicle_color"`
 	VehiclePlateNumber pgtype.Text `json:"vehicle_plate_number"`
 	IsApproved         pgtype.Bool `json:"is_approved"`
+	CityCode           pgtype.Text `json:"city_code"`
 	ID                 pgtype.UUID `json:"id"`
 }
 
@@ -379,6 +393,7 @@ func (q *Queries) UpdateDriverProfile(ctx context.Context, arg UpdateDriverProfi
 		arg.VehicleColor,
 		arg.VehiclePlateNumber,
 		arg.IsApproved,
+		arg.CityCode,
 		arg.ID,
 	)
 	var i DriverProfile
@@ -398,6 +413,8 @@ func (q *Queries) UpdateDriverProfile(ctx context.Context, arg UpdateDriverProfi
 		&i.CurrentLongitude,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.CityCode,
+		&i.DispatchSuspendedUntil,
 	)
 	return i, err
 }
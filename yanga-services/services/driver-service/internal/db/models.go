@@ -8,31 +8,474 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
-type DriverProfile struct {
+type AirportQueueEntry struct {
+	DriverID  pgtype.UUID      `json:"driver_id"`
+	AirportID pgtype.UUID      `json:"airport_id"`
+	StagingID pgtype.UUID      `json:"staging_id"`
+	JoinedAt  pgtype.Timestamp `json:"joined_at"`
+}
+
+type CancellationPolicy struct {
+	ID                      pgtype.UUID      `json:"id"`
+	CityCode                string           `json:"city_code"`
+	FreeCancellationSeconds int32            `json:"free_cancellation_seconds"`
+	LateCancellationFee     pgtype.Numeric   `json:"late_cancellation_fee"`
+	DriverNearbyMeters      int32            `json:"driver_nearby_meters"`
+	DriverArrivedFee        pgtype.Numeric   `json:"driver_arrived_fee"`
+	RiderNoShowWaitSeconds  int32            `json:"rider_no_show_wait_seconds"`
+	RiderNoShowFee          pgtype.Numeric   `json:"rider_no_show_fee"`
+	DriverNoShowWaitSeconds int32            `json:"driver_no_show_wait_seconds"`
+	MatchTimeoutSeconds     int32            `json:"match_timeout_seconds"`
+	UpdatedBy               pgtype.UUID      `json:"updated_by"`
+	CreatedAt               pgtype.Timestamp `json:"created_at"`
+	UpdatedAt               pgtype.Timestamp `json:"updated_at"`
+}
+
+type City struct {
+	Code              string           `json:"code"`
+	Name              string           `json:"name"`
+	Currency          string           `json:"currency"`
+	Timezone          string           `json:"timezone"`
+	BaseFare          pgtype.Numeric   `json:"base_fare"`
+	PerKmRate         pgtype.Numeric   `json:"per_km_rate"`
+	PerStopFee        pgtype.Numeric   `json:"per_stop_fee"`
+	DeliveryBaseFare  pgtype.Numeric   `json:"delivery_base_fare"`
+	DeliveryPerKmRate pgtype.Numeric   `json:"delivery_per_km_rate"`
+	PoolingEnabled    bool             `json:"pooling_enabled"`
+	DeliveriesEnabled bool             `json:"deliveries_enabled"`
+	SchedulingEnabled bool             `json:"scheduling_enabled"`
+	StopsEnabled      bool             `json:"stops_enabled"`
+	Active            bool             `json:"active"`
+	CreatedAt         pgtype.Timestamp `json:"created_at"`
+	UpdatedAt         pgtype.Timestamp `json:"updated_at"`
+}
+
+type CommissionPlan struct {
+	ID             pgtype.UUID      `json:"id"`
+	Name           string           `json:"name"`
+	VehicleType    pgtype.Text      `json:"vehicle_type"`
+	CommissionRate pgtype.Numeric   `json:"commission_rate"`
+	TaxRate        pgtype.Numeric   `json:"tax_rate"`
+	IsActive       bool             `json:"is_active"`
+	EffectiveFrom  pgtype.Timestamp `json:"effective_from"`
+	EffectiveTo    pgtype.Timestamp `json:"effective_to"`
+	CreatedBy      pgtype.UUID      `json:"created_by"`
+	CreatedAt      pgtype.Timestamp `json:"created_at"`
+	UpdatedAt      pgtype.Timestamp `json:"updated_at"`
+	CityCode       pgtype.Text      `json:"city_code"`
+}
+
+type DeviceToken struct {
+	ID        pgtype.UUID      `json:"id"`
+	UserID    pgtype.UUID      `json:"user_id"`
+	Token     string           `json:"token"`
+	Platform  string           `json:"platform"`
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+	UpdatedAt pgtype.Timestamp `json:"updated_at"`
+}
+
+type DriverComplaint struct {
+	ID          pgtype.UUID      `json:"id"`
+	DriverID    pgtype.UUID      `json:"driver_id"`
+	TripID      pgtype.UUID      `json:"trip_id"`
+	RatingID    pgtype.UUID      `json:"rating_id"`
+	Source      string           `json:"source"`
+	Category    string           `json:"category"`
+	Description pgtype.Text      `json:"description"`
+	CreatedBy   pgtype.UUID      `json:"created_by"`
+	CreatedAt   pgtype.Timestamp `json:"created_at"`
+	TicketID    pgtype.UUID      `json:"ticket_id"`
+}
+
+type DriverEarning struct {
 	ID                 pgtype.UUID      `json:"id"`
-	UserID             pgtype.UUID      `json:"user_id"`
-	LicenseNumber      string           `json:"license_number"`
-	VehicleType        string           `json:"vehicle_type"`
-	VehicleModel       string           `json:"vehicle_model"`
-	VehicleColor       string           `json:"vehicle_color"`
-	VehiclePlateNumber string           `json:"vehicle_plate_number"`
-	IsOnline           pgtype.Bool      `json:"is_online"`
-	IsApproved         pgtype.Bool      `json:"is_approved"`
-	Rating             pgtype.Numeric   `json:"rating"`
-	TotalTrips         pgtype.Int4      `json:"total_trips"`
-	CurrentLatitude    pgtype.Numeric   `json:"current_latitude"`
-	CurrentLongitude   pgtype.Numeric   `json:"current_longitude"`
+	TripID             pgtype.UUID      `json:"trip_id"`
+	DriverID           pgtype.UUID      `json:"driver_id"`
+	CommissionPlanID   pgtype.UUID      `json:"commission_plan_id"`
+	GrossFare          pgtype.Numeric   `json:"gross_fare"`
+	CommissionRate     pgtype.Numeric   `json:"commission_rate"`
+	Commission         pgtype.Numeric   `json:"commission"`
+	Tax                pgtype.Numeric   `json:"tax"`
+	Tip                pgtype.Numeric   `json:"tip"`
+	NetEarnings        pgtype.Numeric   `json:"net_earnings"`
+	PaymentMethod      pgtype.Text      `json:"payment_method"`
+	PayoutItemID       pgtype.UUID      `json:"payout_item_id"`
+	EarnedAt           pgtype.Timestamp `json:"earned_at"`
 	CreatedAt          pgtype.Timestamp `json:"created_at"`
-	UpdatedAt          pgtype.Timestamp `json:"updated_at"`
+	RiderDiscount      pgtype.Numeric   `json:"rider_discount"`
+	Currency           string           `json:"currency"`
+	IncentiveProgramID pgtype.UUID      `json:"incentive_program_id"`
+}
+
+type DriverGeofence struct {
+	DriverID   pgtype.UUID      `json:"driver_id"`
+	GeofenceID pgtype.UUID      `json:"geofence_id"`
+	EnteredAt  pgtype.Timestamp `json:"entered_at"`
+}
+
+type DriverProfile struct {
+	ID                     pgtype.UUID      `json:"id"`
+	UserID                 pgtype.UUID      `json:"user_id"`
+	LicenseNumber          string           `json:"license_number"`
+	VehicleType            string           `json:"vehicle_type"`
+	VehicleModel           string           `json:"vehicle_model"`
+	VehicleColor           string           `json:"vehicle_color"`
+	VehiclePlateNumber     string           `json:"vehicle_plate_number"`
+	IsOnline               pgtype.Bool      `json:"is_online"`
+	IsApproved             pgtype.Bool      `json:"is_approved"`
+	Rating                 pgtype.Numeric   `json:"rating"`
+	TotalTrips             pgtype.Int4      `json:"total_trips"`
+	CurrentLatitude        pgtype.Numeric   `json:"current_latitude"`
+	CurrentLongitude       pgtype.Numeric   `json:"current_longitude"`
+	CreatedAt              pgtype.Timestamp `json:"created_at"`
+	UpdatedAt              pgtype.Timestamp `json:"updated_at"`
+	CityCode               pgtype.Text      `json:"city_code"`
+	DispatchSuspendedUntil pgtype.Timestamp `json:"dispatch_suspended_until"`
+}
+
+type DriverQualityAction struct {
+	ID             pgtype.UUID      `json:"id"`
+	DriverID       pgtype.UUID      `json:"driver_id"`
+	Action         string           `json:"action"`
+	Score          pgtype.Numeric   `json:"score"`
+	Reasons        pgtype.Text      `json:"reasons"`
+	SuspendedUntil pgtype.Timestamp `json:"suspended_until"`
+	Note           pgtype.Text      `json:"note"`
+	CreatedBy      pgtype.UUID      `json:"created_by"`
+	CreatedAt      pgtype.Timestamp `json:"created_at"`
+}
+
+type DriverQualityScore struct {
+	DriverID         pgtype.UUID      `json:"driver_id"`
+	Score            pgtype.Numeric   `json:"score"`
+	RatingAverage    pgtype.Numeric   `json:"rating_average"`
+	AcceptanceRate   pgtype.Numeric   `json:"acceptance_rate"`
+	CancellationRate pgtype.Numeric   `json:"cancellation_rate"`
+	ComplaintCount   int32            `json:"complaint_count"`
+	CompletedTrips   int32            `json:"completed_trips"`
+	Level            string           `json:"level"`
+	PeriodStart      pgtype.Timestamp `json:"period_start"`
+	ComputedAt       pgtype.Timestamp `json:"computed_at"`
+}
+
+type DriverRatingEntry struct {
+	RatingID pgtype.UUID      `json:"rating_id"`
+	DriverID pgtype.UUID      `json:"driver_id"`
+	TripID   pgtype.UUID      `json:"trip_id"`
+	Rating   int32            `json:"rating"`
+	RatedAt  pgtype.Timestamp `json:"rated_at"`
+}
+
+type DriverRatingStat struct {
+	DriverID        pgtype.UUID      `json:"driver_id"`
+	RatingCount     int32            `json:"rating_count"`
+	LifetimeAverage pgtype.Numeric   `json:"lifetime_average"`
+	RollingCount    int32            `json:"rolling_count"`
+	RollingAverage  pgtype.Numeric   `json:"rolling_average"`
+	OneStar         int32            `json:"one_star"`
+	TwoStar         int32            `json:"two_star"`
+	ThreeStar       int32            `json:"three_star"`
+	FourStar        int32            `json:"four_star"`
+	FiveStar        int32            `json:"five_star"`
+	UpdatedAt       pgtype.Timestamp `json:"updated_at"`
+}
+
+type EmergencyContact struct {
+	ID          pgtype.UUID      `json:"id"`
+	UserID      pgtype.UUID      `json:"user_id"`
+	Name        string           `json:"name"`
+	PhoneNumber string           `json:"phone_number"`
+	CreatedAt   pgtype.Timestamp `json:"created_at"`
+}
+
+type Geofence struct {
+	ID           pgtype.UUID      `json:"id"`
+	CityCode     string           `json:"city_code"`
+	Name         string           `json:"name"`
+	Kind         string           `json:"kind"`
+	Polygon      []byte           `json:"polygon"`
+	MinLatitude  pgtype.Numeric   `json:"min_latitude"`
+	MaxLatitude  pgtype.Numeric   `json:"max_latitude"`
+	MinLongitude pgtype.Numeric   `json:"min_longitude"`
+	MaxLongitude pgtype.Numeric   `json:"max_longitude"`
+	PickupFee    pgtype.Numeric   `json:"pickup_fee"`
+	DropoffFee   pgtype.Numeric   `json:"dropoff_fee"`
+	Active       bool             `json:"active"`
+	CreatedBy    pgtype.UUID      `json:"created_by"`
+	CreatedAt    pgtype.Timestamp `json:"created_at"`
+	UpdatedAt    pgtype.Timestamp `json:"updated_at"`
+	ParentID     pgtype.UUID      `json:"parent_id"`
+}
+
+type InboxMessage struct {
+	ID         pgtype.UUID      `json:"id"`
+	UserID     pgtype.UUID      `json:"user_id"`
+	DeliveryID pgtype.UUID      `json:"delivery_id"`
+	Category   string           `json:"category"`
+	Title      string           `json:"title"`
+	Body       string           `json:"body"`
+	Data       []byte           `json:"data"`
+	ReadAt     pgtype.Timestamp `json:"read_at"`
+	CreatedAt  pgtype.Timestamp `json:"created_at"`
+}
+
+type IncentiveProgram struct {
+	ID           pgtype.UUID      `json:"id"`
+	Name         string           `json:"name"`
+	Description  pgtype.Text      `json:"description"`
+	Kind         string           `json:"kind"`
+	CityCode     pgtype.Text      `json:"city_code"`
+	VehicleType  pgtype.Text      `json:"vehicle_type"`
+	GeofenceID   pgtype.UUID      `json:"geofence_id"`
+	Currency     string           `json:"currency"`
+	TargetTrips  int32            `json:"target_trips"`
+	RewardAmount pgtype.Numeric   `json:"reward_amount"`
+	BoostRate    pgtype.Numeric   `json:"boost_rate"`
+	StartsAt     pgtype.Timestamp `json:"starts_at"`
+	EndsAt       pgtype.Timestamp `json:"ends_at"`
+	SettledAt    pgtype.Timestamp `json:"settled_at"`
+	IsActive     bool             `json:"is_active"`
+	CreatedBy    pgtype.UUID      `json:"created_by"`
+	CreatedAt    pgtype.Timestamp `json:"created_at"`
+	UpdatedAt    pgtype.Timestamp `json:"updated_at"`
+}
+
+type IncentiveProgress struct {
+	ProgramID      pgtype.UUID      `json:"program_id"`
+	DriverID       pgtype.UUID      `json:"driver_id"`
+	TripsCompleted int32            `json:"trips_completed"`
+	FaresEarned    pgtype.Numeric   `json:"fares_earned"`
+	BonusEarned    pgtype.Numeric   `json:"bonus_earned"`
+	CompletedAt    pgtype.Timestamp `json:"completed_at"`
+	CreatedAt      pgtype.Timestamp `json:"created_at"`
+	UpdatedAt      pgtype.Timestamp `json:"updated_at"`
+}
+
+type IncentiveTrip struct {
+	ProgramID pgtype.UUID      `json:"program_id"`
+	TripID    pgtype.UUID      `json:"trip_id"`
+	DriverID  pgtype.UUID      `json:"driver_id"`
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+}
+
+type LedgerAccount struct {
+	ID          pgtype.UUID      `json:"id"`
+	OwnerID     pgtype.UUID      `json:"owner_id"`
+	AccountType string           `json:"account_type"`
+	Currency    string           `json:"currency"`
+	CreatedAt   pgtype.Timestamp `json:"created_at"`
+}
+
+type LedgerEntry struct {
+	ID            pgtype.UUID      `json:"id"`
+	TransactionID pgtype.UUID      `json:"transaction_id"`
+	AccountID     pgtype.UUID      `json:"account_id"`
+	Direction     string           `json:"direction"`
+	Amount        pgtype.Numeric   `json:"amount"`
+	CreatedAt     pgtype.Timestamp `json:"created_at"`
+}
+
+type LedgerTransaction struct {
+	ID                    pgtype.UUID      `json:"id"`
+	TransactionType       string           `json:"transaction_type"`
+	IdempotencyKey        string           `json:"idempotency_key"`
+	TripID                pgtype.UUID      `json:"trip_id"`
+	ReversedTransactionID pgtype.UUID      `json:"reversed_transaction_id"`
+	Description           pgtype.Text      `json:"description"`
+	CreatedBy             pgtype.UUID      `json:"created_by"`
+	CreatedAt             pgtype.Timestamp `json:"created_at"`
+}
+
+type NotificationDelivery struct {
+	ID            pgtype.UUID      `json:"id"`
+	UserID        pgtype.UUID      `json:"user_id"`
+	EventKey      string           `json:"event_key"`
+	Template      string           `json:"template"`
+	Category      string           `json:"category"`
+	Channel       string           `json:"channel"`
+	Address       string           `json:"address"`
+	Platform      pgtype.Text      `json:"platform"`
+	Title         string           `json:"title"`
+	Body          string           `json:"body"`
+	Data          []byte           `json:"data"`
+	Status        string           `json:"status"`
+	Attempts      int32            `json:"attempts"`
+	LastError     pgtype.Text      `json:"last_error"`
+	NextAttemptAt pgtype.Timestamp `json:"next_attempt_at"`
+	SentAt        pgtype.Timestamp `json:"sent_at"`
+	CreatedAt     pgtype.Timestamp `json:"created_at"`
+	UpdatedAt     pgtype.Timestamp `json:"updated_at"`
+}
+
+type NotificationPreference struct {
+	UserID          pgtype.UUID      `json:"user_id"`
+	PushEnabled     bool             `json:"push_enabled"`
+	SmsEnabled      bool             `json:"sms_enabled"`
+	Locale          pgtype.Text      `json:"locale"`
+	Timezone        pgtype.Text      `json:"timezone"`
+	QuietHoursStart pgtype.Int2      `json:"quiet_hours_start"`
+	QuietHoursEnd   pgtype.Int2      `json:"quiet_hours_end"`
+	MutedCategories []string         `json:"muted_categories"`
+	UpdatedAt       pgtype.Timestamp `json:"updated_at"`
+}
+
+type ParcelDelivery struct {
+	TripID           pgtype.UUID      `json:"trip_id"`
+	SenderName       string           `json:"sender_name"`
+	SenderPhone      string           `json:"sender_phone"`
+	RecipientName    string           `json:"recipient_name"`
+	RecipientPhone   string           `json:"recipient_phone"`
+	ParcelSize       string           `json:"parcel_size"`
+	WeightKg         pgtype.Numeric   `json:"weight_kg"`
+	Description      pgtype.Text      `json:"description"`
+	Instructions     pgtype.Text      `json:"instructions"`
+	RecipientPin     string           `json:"recipient_pin"`
+	PinAttempts      int32            `json:"pin_attempts"`
+	PickupPhotoRef   pgtype.Text      `json:"pickup_photo_ref"`
+	PickedUpAt       pgtype.Timestamp `json:"picked_up_at"`
+	DeliveryPhotoRef pgtype.Text      `json:"delivery_photo_ref"`
+	DeliveredAt      pgtype.Timestamp `json:"delivered_at"`
+	CreatedAt        pgtype.Timestamp `json:"created_at"`
+	UpdatedAt        pgtype.Timestamp `json:"updated_at"`
+}
+
+type PayoutBatch struct {
+	ID            pgtype.UUID      `json:"id"`
+	Status        string           `json:"status"`
+	PeriodEnd     pgtype.Timestamp `json:"period_end"`
+	TotalAmount   pgtype.Numeric   `json:"total_amount"`
+	DriverCount   int32            `json:"driver_count"`
+	FailureReason pgtype.Text      `json:"failure_reason"`
+	CreatedBy     pgtype.UUID      `json:"created_by"`
+	ProcessedAt   pgtype.Timestamp `json:"processed_at"`
+	CreatedAt     pgtype.Timestamp `json:"created_at"`
+	UpdatedAt     pgtype.Timestamp `json:"updated_at"`
+}
+
+type PayoutItem struct {
+	ID               pgtype.UUID      `json:"id"`
+	BatchID          pgtype.UUID      `json:"batch_id"`
+	DriverID         pgtype.UUID      `json:"driver_id"`
+	Amount           pgtype.Numeric   `json:"amount"`
+	EarningsCount    int32            `json:"earnings_count"`
+	Status           string           `json:"status"`
+	PaymentReference pgtype.Text      `json:"payment_reference"`
+	FailureReason    pgtype.Text      `json:"failure_reason"`
+	CreatedAt        pgtype.Timestamp `json:"created_at"`
+	UpdatedAt        pgtype.Timestamp `json:"updated_at"`
+}
+
+type PoolWaypoint struct {
+	ID          pgtype.UUID      `json:"id"`
+	PoolID      pgtype.UUID      `json:"pool_id"`
+	TripID      pgtype.UUID      `json:"trip_id"`
+	Kind        string           `json:"kind"`
+	Sequence    int32            `json:"sequence"`
+	Latitude    pgtype.Numeric   `json:"latitude"`
+	Longitude   pgtype.Numeric   `json:"longitude"`
+	Address     string           `json:"address"`
+	CompletedAt pgtype.Timestamp `json:"completed_at"`
+	CreatedAt   pgtype.Timestamp `json:"created_at"`
+	UpdatedAt   pgtype.Timestamp `json:"updated_at"`
+}
+
+type PromoCode struct {
+	ID              pgtype.UUID      `json:"id"`
+	Code            string           `json:"code"`
+	Description     pgtype.Text      `json:"description"`
+	DiscountType    string           `json:"discount_type"`
+	DiscountValue   pgtype.Numeric   `json:"discount_value"`
+	MaxDiscount     pgtype.Numeric   `json:"max_discount"`
+	MinFare         pgtype.Numeric   `json:"min_fare"`
+	UsageLimit      pgtype.Int4      `json:"usage_limit"`
+	PerUserLimit    int32            `json:"per_user_limit"`
+	TimesUsed       int32            `json:"times_used"`
+	FirstRideOnly   bool             `json:"first_ride_only"`
+	AutoApply       bool             `json:"auto_apply"`
+	VehicleTypes    []string         `json:"vehicle_types"`
+	CenterLatitude  pgtype.Numeric   `json:"center_latitude"`
+	CenterLongitude pgtype.Numeric   `json:"center_longitude"`
+	RadiusKm        pgtype.Numeric   `json:"radius_km"`
+	ValidFrom       pgtype.Timestamp `json:"valid_from"`
+	ValidUntil      pgtype.Timestamp `json:"valid_until"`
+	IsActive        bool             `json:"is_active"`
+	CreatedBy       pgtype.UUID      `json:"created_by"`
+	CreatedAt       pgtype.Timestamp `json:"created_at"`
+	UpdatedAt       pgtype.Timestamp `json:"updated_at"`
+	CityCode        pgtype.Text      `json:"city_code"`
+	Currency        string           `json:"currency"`
+}
+
+type PromoRedemption struct {
+	ID             pgtype.UUID      `json:"id"`
+	PromoCodeID    pgtype.UUID      `json:"promo_code_id"`
+	UserID         pgtype.UUID      `json:"user_id"`
+	TripID         pgtype.UUID      `json:"trip_id"`
+	DiscountAmount pgtype.Numeric   `json:"discount_amount"`
+	Status         string           `json:"status"`
+	CreatedAt      pgtype.Timestamp `json:"created_at"`
+	UpdatedAt      pgtype.Timestamp `json:"updated_at"`
+}
+
+type RaterWeight struct {
+	RaterID      pgtype.UUID      `json:"rater_id"`
+	RaterType    string           `json:"rater_type"`
+	RatingsGiven int32            `json:"ratings_given"`
+	AverageGiven pgtype.Numeric   `json:"average_given"`
+	Weight       pgtype.Numeric   `json:"weight"`
+	UpdatedAt    pgtype.Timestamp `json:"updated_at"`
 }
 
 type Rating struct {
+	ID              pgtype.UUID      `json:"id"`
+	TripID          pgtype.UUID      `json:"trip_id"`
+	RaterID         pgtype.UUID      `json:"rater_id"`
+	RatedID         pgtype.UUID      `json:"rated_id"`
+	Rating          int32            `json:"rating"`
+	Feedback        pgtype.Text      `json:"feedback"`
+	CreatedAt       pgtype.Timestamp `json:"created_at"`
+	RaterType       string           `json:"rater_type"`
+	FeedbackFlagged bool             `json:"feedback_flagged"`
+	FlagReasons     pgtype.Text      `json:"flag_reasons"`
+	ExcludedAt      pgtype.Timestamp `json:"excluded_at"`
+	ExcludedBy      pgtype.UUID      `json:"excluded_by"`
+	ExclusionReason pgtype.Text      `json:"exclusion_reason"`
+}
+
+type RatingDispute struct {
+	ID             pgtype.UUID      `json:"id"`
+	RatingID       pgtype.UUID      `json:"rating_id"`
+	DisputedBy     pgtype.UUID      `json:"disputed_by"`
+	Reason         string           `json:"reason"`
+	Status         string           `json:"status"`
+	ResolutionNote pgtype.Text      `json:"resolution_note"`
+	ReviewedBy     pgtype.UUID      `json:"reviewed_by"`
+	ReviewedAt     pgtype.Timestamp `json:"reviewed_at"`
+	CreatedAt      pgtype.Timestamp `json:"created_at"`
+	UpdatedAt      pgtype.Timestamp `json:"updated_at"`
+}
+
+type RatingTag struct {
+	RatingID pgtype.UUID `json:"rating_id"`
+	Tag      string      `json:"tag"`
+}
+
+type Referral struct {
+	ID               pgtype.UUID      `json:"id"`
+	ReferrerID       pgtype.UUID      `json:"referrer_id"`
+	RefereeID        pgtype.UUID      `json:"referee_id"`
+	ReferrerReward   pgtype.Numeric   `json:"referrer_reward"`
+	RefereeReward    pgtype.Numeric   `json:"referee_reward"`
+	Status           string           `json:"status"`
+	QualifyingTripID pgtype.UUID      `json:"qualifying_trip_id"`
+	RewardedAt       pgtype.Timestamp `json:"rewarded_at"`
+	CreatedAt        pgtype.Timestamp `json:"created_at"`
+}
+
+type ReferralCode struct {
 	ID        pgtype.UUID      `json:"id"`
-	TripID    pgtype.UUID      `json:"trip_id"`
-	RaterID   pgtype.UUID      `json:"rater_id"`
-	RatedID   pgtype.UUID      `json:"rated_id"`
-	Rating    int32            `json:"rating"`
-	Feedback  pgtype.Text      `json:"feedback"`
+	UserID    pgtype.UUID      `json:"user_id"`
+	Code      string           `json:"code"`
 	CreatedAt pgtype.Timestamp `json:"created_at"`
 }
 
@@ -46,6 +489,86 @@ type RideRequest struct {
 	CreatedAt   pgtype.Timestamp `json:"created_at"`
 }
 
+type SafetyIncident struct {
+	ID             pgtype.UUID      `json:"id"`
+	TripID         pgtype.UUID      `json:"trip_id"`
+	ReporterID     pgtype.UUID      `json:"reporter_id"`
+	Kind           string           `json:"kind"`
+	Status         string           `json:"status"`
+	Latitude       pgtype.Numeric   `json:"latitude"`
+	Longitude      pgtype.Numeric   `json:"longitude"`
+	Details        pgtype.Text      `json:"details"`
+	Snapshot       []byte           `json:"snapshot"`
+	AcknowledgedBy pgtype.UUID      `json:"acknowledged_by"`
+	AcknowledgedAt pgtype.Timestamp `json:"acknowledged_at"`
+	ResolvedBy     pgtype.UUID      `json:"resolved_by"`
+	ResolvedAt     pgtype.Timestamp `json:"resolved_at"`
+	ResolutionNote pgtype.Text      `json:"resolution_note"`
+	CreatedAt      pgtype.Timestamp `json:"created_at"`
+	UpdatedAt      pgtype.Timestamp `json:"updated_at"`
+}
+
+type SavedPlace struct {
+	ID        pgtype.UUID      `json:"id"`
+	UserID    pgtype.UUID      `json:"user_id"`
+	Label     string           `json:"label"`
+	Name      string           `json:"name"`
+	Address   string           `json:"address"`
+	Latitude  pgtype.Numeric   `json:"latitude"`
+	Longitude pgtype.Numeric   `json:"longitude"`
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+	UpdatedAt pgtype.Timestamp `json:"updated_at"`
+}
+
+type SupportAttachment struct {
+	ID          pgtype.UUID      `json:"id"`
+	TicketID    pgtype.UUID      `json:"ticket_id"`
+	UploadedBy  pgtype.UUID      `json:"uploaded_by"`
+	FileName    string           `json:"file_name"`
+	ContentType string           `json:"content_type"`
+	SizeBytes   int64            `json:"size_bytes"`
+	BlobKey     string           `json:"blob_key"`
+	CreatedAt   pgtype.Timestamp `json:"created_at"`
+}
+
+type SupportMessage struct {
+	ID         pgtype.UUID      `json:"id"`
+	TicketID   pgtype.UUID      `json:"ticket_id"`
+	AuthorID   pgtype.UUID      `json:"author_id"`
+	AuthorRole string           `json:"author_role"`
+	Body       string           `json:"body"`
+	Internal   bool             `json:"internal"`
+	CreatedAt  pgtype.Timestamp `json:"created_at"`
+}
+
+type SupportTicket struct {
+	ID                      pgtype.UUID      `json:"id"`
+	UserID                  pgtype.UUID      `json:"user_id"`
+	TripID                  pgtype.UUID      `json:"trip_id"`
+	DriverID                pgtype.UUID      `json:"driver_id"`
+	Category                string           `json:"category"`
+	Priority                string           `json:"priority"`
+	Status                  string           `json:"status"`
+	Subject                 string           `json:"subject"`
+	AssignedTo              pgtype.UUID      `json:"assigned_to"`
+	AssignedAt              pgtype.Timestamp `json:"assigned_at"`
+	FirstResponseDueAt      pgtype.Timestamp `json:"first_response_due_at"`
+	FirstRespondedAt        pgtype.Timestamp `json:"first_responded_at"`
+	FirstResponseBreachedAt pgtype.Timestamp `json:"first_response_breached_at"`
+	ResolutionDueAt         pgtype.Timestamp `json:"resolution_due_at"`
+	ResolutionBreachedAt    pgtype.Timestamp `json:"resolution_breached_at"`
+	Resolution              pgtype.Text      `json:"resolution"`
+	ResolutionNote          pgtype.Text      `json:"resolution_note"`
+	AdjustmentAmount        pgtype.Numeric   `json:"adjustment_amount"`
+	AdjustmentStatus        pgtype.Text      `json:"adjustment_status"`
+	AdjustmentTransactionID pgtype.UUID      `json:"adjustment_transaction_id"`
+	AdjustmentError         pgtype.Text      `json:"adjustment_error"`
+	ResolvedBy              pgtype.UUID      `json:"resolved_by"`
+	ResolvedAt              pgtype.Timestamp `json:"resolved_at"`
+	CreatedAt               pgtype.Timestamp `json:"created_at"`
+	UpdatedAt               pgtype.Timestamp `json:"updated_at"`
+}
+
 type Trip struct {
 	ID                 pgtype.UUID      `json:"id"`
 	UserID             pgtype.UUID      `json:"user_id"`
@@ -70,6 +593,92 @@ type Trip struct {
 	CancellationReason pgtype.Text      `json:"cancellation_reason"`
 	CreatedAt          pgtype.Timestamp `json:"created_at"`
 	UpdatedAt          pgtype.Timestamp `json:"updated_at"`
+	Tip                pgtype.Numeric   `json:"tip"`
+	VehicleType        pgtype.Text      `json:"vehicle_type"`
+	SubtotalFare       pgtype.Numeric   `json:"subtotal_fare"`
+	DiscountAmount     pgtype.Numeric   `json:"discount_amount"`
+	PromoCode          pgtype.Text      `json:"promo_code"`
+	CityCode           pgtype.Text      `json:"city_code"`
+	AcceptedAt         pgtype.Timestamp `json:"accepted_at"`
+	ArrivedAt          pgtype.Timestamp `json:"arrived_at"`
+	CancelledBy        pgtype.Text      `json:"cancelled_by"`
+	NoShowParty        pgtype.Text      `json:"no_show_party"`
+	CancellationFee    pgtype.Numeric   `json:"cancellation_fee"`
+	PickupAt           pgtype.Timestamp `json:"pickup_at"`
+	DispatchedAt       pgtype.Timestamp `json:"dispatched_at"`
+	ReminderSentAt     pgtype.Timestamp `json:"reminder_sent_at"`
+	ReservedDriverID   pgtype.UUID      `json:"reserved_driver_id"`
+	ReservedAt         pgtype.Timestamp `json:"reserved_at"`
+	PoolID             pgtype.UUID      `json:"pool_id"`
+	SeatCount          int32            `json:"seat_count"`
+	TripType           string           `json:"trip_type"`
+	ZoneFee            pgtype.Numeric   `json:"zone_fee"`
+	Currency           string           `json:"currency"`
+}
+
+type TripCallSession struct {
+	ID                pgtype.UUID      `json:"id"`
+	TripID            pgtype.UUID      `json:"trip_id"`
+	ProviderSessionID string           `json:"provider_session_id"`
+	ProxyNumber       string           `json:"proxy_number"`
+	ExpiresAt         pgtype.Timestamp `json:"expires_at"`
+	ClosedAt          pgtype.Timestamp `json:"closed_at"`
+	CreatedAt         pgtype.Timestamp `json:"created_at"`
+}
+
+type TripMessage struct {
+	ID         pgtype.UUID      `json:"id"`
+	TripID     pgtype.UUID      `json:"trip_id"`
+	SenderID   pgtype.UUID      `json:"sender_id"`
+	SenderRole string           `json:"sender_role"`
+	Body       string           `json:"body"`
+	QuickReply pgtype.Text      `json:"quick_reply"`
+	ClientID   pgtype.Text      `json:"client_id"`
+	ReadAt     pgtype.Timestamp `json:"read_at"`
+	CreatedAt  pgtype.Timestamp `json:"created_at"`
+}
+
+type TripPool struct {
+	ID           pgtype.UUID      `json:"id"`
+	DriverID     pgtype.UUID      `json:"driver_id"`
+	VehicleType  string           `json:"vehicle_type"`
+	SeatCapacity int32            `json:"seat_capacity"`
+	Status       string           `json:"status"`
+	CreatedAt    pgtype.Timestamp `json:"created_at"`
+	UpdatedAt    pgtype.Timestamp `json:"updated_at"`
+}
+
+type TripSafetyTrack struct {
+	TripID          pgtype.UUID      `json:"trip_id"`
+	PlannedPolyline pgtype.Text      `json:"planned_polyline"`
+	AnchorLatitude  pgtype.Numeric   `json:"anchor_latitude"`
+	AnchorLongitude pgtype.Numeric   `json:"anchor_longitude"`
+	AnchoredAt      pgtype.Timestamp `json:"anchored_at"`
+	LastSeenAt      pgtype.Timestamp `json:"last_seen_at"`
+	CreatedAt       pgtype.Timestamp `json:"created_at"`
+	UpdatedAt       pgtype.Timestamp `json:"updated_at"`
+}
+
+type TripShareLink struct {
+	ID        pgtype.UUID      `json:"id"`
+	TripID    pgtype.UUID      `json:"trip_id"`
+	CreatedBy pgtype.UUID      `json:"created_by"`
+	TokenHash string           `json:"token_hash"`
+	ExpiresAt pgtype.Timestamp `json:"expires_at"`
+	RevokedAt pgtype.Timestamp `json:"revoked_at"`
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+}
+
+type TripStop struct {
+	ID        pgtype.UUID      `json:"id"`
+	TripID    pgtype.UUID      `json:"trip_id"`
+	StopOrder int32            `json:"stop_order"`
+	Latitude  pgtype.Numeric   `json:"latitude"`
+	Longitude pgtype.Numeric   `json:"longitude"`
+	Address   string           `json:"address"`
+	ArrivedAt pgtype.Timestamp `json:"arrived_at"`
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+	UpdatedAt pgtype.Timestamp `json:"updated_at"`
 }
 
 type User struct {
@@ -11,16 +11,105 @@ import (
 )
 
 type Querier interface {
+	AddIncentiveBonus(ctx context.Context, arg AddIncentiveBonusParams) error
+	AddIncentiveTrip(ctx context.Context, arg AddIncentiveTripParams) (IncentiveProgress, error)
+	AssignEarningsToPayoutItem(ctx context.Context, arg AssignEarningsToPayoutItemParams) (int64, error)
+	// Brings driver_rating_entries in line with the ratings table, including
+	// ratings that were edited after they were counted.
+	BackfillDriverRatingEntries(ctx context.Context) (int64, error)
+	// Complaints raised by a rating stop counting if the rating is excluded.
+	CountDriverComplaintsSince(ctx context.Context, arg CountDriverComplaintsSinceParams) (int64, error)
+	CountDriverCompletedTrips(ctx context.Context, driverID pgtype.UUID) (int64, error)
+	CreateCommissionPlan(ctx context.Context, arg CreateCommissionPlanParams) (CommissionPlan, error)
+	CreateDriverComplaint(ctx context.Context, arg CreateDriverComplaintParams) (DriverComplaint, error)
+	CreateDriverEarning(ctx context.Context, arg CreateDriverEarningParams) (DriverEarning, error)
 	CreateDriverProfile(ctx context.Context, arg CreateDriverProfileParams) (DriverProfile, error)
+	CreateDriverQualityAction(ctx context.Context, arg CreateDriverQualityActionParams) (DriverQualityAction, error)
+	// Bonuses are earnings with no trip, fare, commission or tax.
+	CreateIncentiveEarning(ctx context.Context, arg CreateIncentiveEarningParams) (DriverEarning, error)
+	CreateIncentiveProgram(ctx context.Context, arg CreateIncentiveProgramParams) (IncentiveProgram, error)
+	CreatePayoutBatch(ctx context.Context, arg CreatePayoutBatchParams) (PayoutBatch, error)
+	CreatePayoutItem(ctx context.Context, arg CreatePayoutItemParams) (PayoutItem, error)
+	DeactivateDriver(ctx context.Context, userID pgtype.UUID) error
+	DeletePayoutItem(ctx context.Context, id pgtype.UUID) error
+	// Prefers a plan for the trip's city over one for every city, then a plan
+	// for the driver's vehicle type over the catch-all plan, and the most
+	// recently effective plan within each.
+	GetApplicableCommissionPlan(ctx context.Context, arg GetApplicableCommissionPlanParams) (CommissionPlan, error)
+	// Programs a trip completed at the given time counts towards.
+	GetApplicableIncentivePrograms(ctx context.Context, arg GetApplicableIncentiveProgramsParams) ([]IncentiveProgram, error)
+	GetCityCurrency(ctx context.Context, code string) (string, error)
+	GetCommissionPlan(ctx context.Context, id pgtype.UUID) (CommissionPlan, error)
+	GetCompletedIncentiveProgress(ctx context.Context, programID pgtype.UUID) ([]IncentiveProgress, error)
+	GetDriverDailyEarnings(ctx context.Context, arg GetDriverDailyEarningsParams) ([]GetDriverDailyEarningsRow, error)
+	GetDriverDispatchStatus(ctx context.Context, userID pgtype.UUID) (GetDriverDispatchStatusRow, error)
+	GetDriverEarningByTrip(ctx context.Context, tripID pgtype.UUID) (DriverEarning, error)
+	GetDriverEarnings(ctx context.Context, arg GetDriverEarningsParams) ([]DriverEarning, error)
+	GetDriverEarningsSummary(ctx context.Context, arg GetDriverEarningsSummaryParams) (GetDriverEarningsSummaryRow, error)
+	// Programs open to a driver that end after since and start before until.
+	GetDriverIncentivePrograms(ctx context.Context, arg GetDriverIncentiveProgramsParams) ([]IncentiveProgram, error)
+	GetDriverIncentiveProgress(ctx context.Context, arg GetDriverIncentiveProgressParams) ([]IncentiveProgress, error)
+	GetDriverPayoutItems(ctx context.Context, arg GetDriverPayoutItemsParams) ([]PayoutItem, error)
 	GetDriverProfile(ctx context.Context, id pgtype.UUID) (DriverProfile, error)
 	GetDriverProfileByUserID(ctx context.Context, userID pgtype.UUID) (DriverProfile, error)
+	GetDriverQualityScore(ctx context.Context, driverID pgtype.UUID) (DriverQualityScore, error)
+	// A driver's rating average over ratings received since a time, weighted
+	// by rater the same way as their rating aggregates.
+	GetDriverRatingSince(ctx context.Context, arg GetDriverRatingSinceParams) (GetDriverRatingSinceRow, error)
+	GetDriverRatingStats(ctx context.Context, driverID pgtype.UUID) (DriverRatingStat, error)
+	GetDriverRequestStats(ctx context.Context, arg GetDriverRequestStatsParams) (GetDriverRequestStatsRow, error)
 	GetDriverStats(ctx context.Context, userID pgtype.UUID) (GetDriverStatsRow, error)
+	// Rider no-shows reported by the driver don't count against the driver.
+	GetDriverTripStats(ctx context.Context, arg GetDriverTripStatsParams) (GetDriverTripStatsRow, error)
+	GetDriverWeeklyEarnings(ctx context.Context, arg GetDriverWeeklyEarningsParams) ([]GetDriverWeeklyEarningsRow, error)
+	GetGeofencePolygon(ctx context.Context, id pgtype.UUID) ([]byte, error)
+	GetIncentiveProgram(ctx context.Context, id pgtype.UUID) (IncentiveProgram, error)
+	GetLatestDriverQualityAction(ctx context.Context, arg GetLatestDriverQualityActionParams) (DriverQualityAction, error)
 	GetNearbyDrivers(ctx context.Context, arg GetNearbyDriversParams) ([]GetNearbyDriversRow, error)
 	GetOnlineDrivers(ctx context.Context, arg GetOnlineDriversParams) ([]GetOnlineDriversRow, error)
+	GetOpenPayoutItemsByBatch(ctx context.Context, batchID pgtype.UUID) ([]PayoutItem, error)
+	// Cash fares were collected by the driver, so they are netted off against
+	// what the platform owes. Drivers who owe money are carried forward.
+	GetPayableDrivers(ctx context.Context, periodEnd pgtype.Timestamp) ([]pgtype.UUID, error)
+	GetPayoutBatch(ctx context.Context, id pgtype.UUID) (PayoutBatch, error)
+	GetPayoutItem(ctx context.Context, id pgtype.UUID) (PayoutItem, error)
+	GetPayoutItemsByBatch(ctx context.Context, batchID pgtype.UUID) ([]PayoutItem, error)
+	GetUnsettledPeakGuarantees(ctx context.Context, arg GetUnsettledPeakGuaranteesParams) ([]IncentiveProgram, error)
+	ListCommissionPlans(ctx context.Context) ([]CommissionPlan, error)
+	ListDriverComplaints(ctx context.Context, arg ListDriverComplaintsParams) ([]DriverComplaint, error)
+	ListDriverQualityActions(ctx context.Context, arg ListDriverQualityActionsParams) ([]DriverQualityAction, error)
+	ListDriverQualityScoresByLevel(ctx context.Context, arg ListDriverQualityScoresByLevelParams) ([]ListDriverQualityScoresByLevelRow, error)
+	ListDriverUserIDs(ctx context.Context) ([]pgtype.UUID, error)
+	ListIncentivePrograms(ctx context.Context, arg ListIncentiveProgramsParams) ([]IncentiveProgram, error)
+	ListPayoutBatches(ctx context.Context, arg ListPayoutBatchesParams) ([]PayoutBatch, error)
+	LockPayoutBatch(ctx context.Context, id pgtype.UUID) error
+	MarkIncentiveCompleted(ctx context.Context, arg MarkIncentiveCompletedParams) (int64, error)
+	MarkIncentiveProgramSettled(ctx context.Context, id pgtype.UUID) (int64, error)
+	// Counts a rating towards a driver if it was given to the driver of the
+	// rated trip. Ratings already counted are ignored.
+	RecordDriverRating(ctx context.Context, ratingID pgtype.UUID) (int64, error)
+	RecordIncentiveTrip(ctx context.Context, arg RecordIncentiveTripParams) (int64, error)
+	RecordRatingComplaint(ctx context.Context, arg RecordRatingComplaintParams) (int64, error)
+	RecordSupportComplaint(ctx context.Context, arg RecordSupportComplaintParams) (int64, error)
+	// Ratings an admin excluded don't count. Averages weigh each rating by its
+	// rater's weight; counts and the star distribution don't.
+	RefreshDriverRatingStats(ctx context.Context, arg RefreshDriverRatingStatsParams) (DriverRatingStat, error)
+	RefreshPayoutBatchTotals(ctx context.Context, id pgtype.UUID) (PayoutBatch, error)
+	RefreshPayoutItemTotals(ctx context.Context, id pgtype.UUID) (PayoutItem, error)
+	ReinstateDriver(ctx context.Context, userID pgtype.UUID) error
+	ReleasePayoutItemEarnings(ctx context.Context, payoutItemID pgtype.UUID) error
+	SetDriverQualityLevel(ctx context.Context, arg SetDriverQualityLevelParams) error
+	// Suspended drivers are also taken offline.
+	SuspendDriverDispatch(ctx context.Context, arg SuspendDriverDispatchParams) error
+	UpdateCommissionPlanStatus(ctx context.Context, arg UpdateCommissionPlanStatusParams) (CommissionPlan, error)
 	UpdateDriverLocation(ctx context.Context, arg UpdateDriverLocationParams) error
 	UpdateDriverProfile(ctx context.Context, arg UpdateDriverProfileParams) (DriverProfile, error)
 	UpdateDriverRating(ctx context.Context, arg UpdateDriverRatingParams) error
 	UpdateDriverStatus(ctx context.Context, arg UpdateDriverStatusParams) error
+	UpdateIncentiveProgramStatus(ctx context.Context, arg UpdateIncentiveProgramStatusParams) (IncentiveProgram, error)
+	UpdatePayoutBatchStatus(ctx context.Context, arg UpdatePayoutBatchStatusParams) (PayoutBatch, error)
+	UpdatePayoutItemStatus(ctx context.Context, arg UpdatePayoutItemStatusParams) (PayoutItem, error)
+	UpsertDriverQualityScore(ctx context.Context, arg UpsertDriverQualityScoreParams) (DriverQualityScore, error)
 }
 
 var _ Querier = (*Queries)(nil)
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -50,6 +51,7 @@ func (h *DriverHandler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
 // @Param request body domain.ToggleStatusRequest true "Status"
 // @Success 200 {object} domain.MessageResponse
 // @Failure 400 {object} domain.ErrorResponse
+// @Failure 403 {object} domain.ErrorResponse
 // @Router /drivers/status [post]
 // @Security BearerAuth
 func (h *DriverHandler) ToggleStatus(w http.ResponseWriter, r *http.Request) {
@@ -63,6 +65,10 @@ func (h *DriverHandler) ToggleStatus(w http.ResponseWriter, r *http.Request) {
 
 	err := h.driverService.UpdateDriverStatus(r.Context(), userID, req.IsOnline)
 	if err != nil {
+		if errors.Is(err, service.ErrDispatchSuspended) {
+			utils.ErrorResponse(w, http.StatusForbidden, err.Error())
+			return
+		}
 		utils.HandleServiceError(w, err)
 		return
 	}
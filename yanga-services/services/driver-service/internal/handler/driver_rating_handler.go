@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/namycodes/yanga-services/services/driver-service/internal/service"
+	"github.com/namycodes/yanga-services/shared-lib/utils"
+)
+
+type DriverRatingHandler struct {
+	driverRatingService *service.DriverRatingService
+}
+
+func NewDriverRatingHandler(driverRatingService *service.DriverRatingService) *DriverRatingHandler {
+	return &DriverRatingHandler{
+		driverRatingService: driverRatingService,
+	}
+}
+
+// GetRating godoc
+// @Summary Get the current driver's rating, lifetime average and star distribution
+// @Tags ratings
+// @Produce json
+// @Success 200 {object} domain.SuccessResponse
+// @Router /drivers/rating [get]
+// @Security BearerAuth
+func (h *DriverRatingHandler) GetRating(w http.ResponseWriter, r *http.Request) {
+	driverIDStr := r.Context().Value("user_id").(string)
+	driverID, err := utils.ParseUUID(driverIDStr)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	rating, err := h.driverRatingService.GetDriverRating(r.Context(), driverID)
+	if err != nil {
+		utils.HandleServiceError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Driver rating retrieved successfully", rating)
+}
+
+// GetDriverRating godoc
+// @Summary Get a driver's rating, lifetime average and star distribution (admin)
+// @Tags ratings
+// @Produce json
+// @Param id path string true "Driver user ID"
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 400 {object} domain.ErrorResponse
+// @Router /drivers/{id}/rating [get]
+// @Security BearerAuth
+func (h *DriverRatingHandler) GetDriverRating(w http.ResponseWriter, r *http.Request) {
+	driverID, err := utils.ParseUUID(mux.Vars(r)["id"])
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid driver ID")
+		return
+	}
+
+	rating, err := h.driverRatingService.GetDriverRating(r.Context(), driverID)
+	if err != nil {
+		utils.HandleServiceError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Driver rating retrieved successfully", rating)
+}
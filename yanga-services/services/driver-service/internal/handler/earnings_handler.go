@@ -0,0 +1,366 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/namycodes/yanga-services/services/driver-service/internal/service"
+	"github.com/namycodes/yanga-services/shared-lib/domain"
+	"github.com/namycodes/yanga-services/shared-lib/utils"
+)
+
+type EarningsHandler struct {
+	earningsService *service.EarningsService
+}
+
+func NewEarningsHandler(earningsService *service.EarningsService) *EarningsHandler {
+	return &EarningsHandler{
+		earningsService: earningsService,
+	}
+}
+
+// GetEarnings godoc
+// @Summary Get the current driver's earnings with daily and weekly statements
+// @Tags earnings
+// @Produce json
+// @Param from query string false "Period start (YYYY-MM-DD or RFC3339), defaults to 7 days ago"
+// @Param to query string false "Period end (YYYY-MM-DD or RFC3339), defaults to now"
+// @Param limit query int false "Trip breakdown limit" default(50)
+// @Param offset query int false "Trip breakdown offset" default(0)
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 400 {object} domain.ErrorResponse
+// @Router /drivers/earnings [get]
+// @Security BearerAuth
+func (h *EarningsHandler) GetEarnings(w http.ResponseWriter, r *http.Request) {
+	driverIDStr := r.Context().Value("user_id").(string)
+	driverID, err := utils.ParseUUID(driverIDStr)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	to := time.Now()
+	from := to.AddDate(0, 0, -7)
+
+	if v := r.URL.Query().Get("from"); v != "" {
+		if from, err = parseTime(v); err != nil {
+			utils.ErrorResponse(w, http.StatusBadRequest, "Invalid from date")
+			return
+		}
+	}
+	if v := r.URL.Query().Get("to"); v != "" {
+		if to, err = parseTime(v); err != nil {
+			utils.ErrorResponse(w, http.StatusBadRequest, "Invalid to date")
+			return
+		}
+	}
+
+	earnings, err := h.earningsService.GetEarnings(r.Context(), driverID, from, to, queryInt(r, "limit", 50), queryInt(r, "offset", 0))
+	if err != nil {
+		handleEarningsError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Earnings retrieved successfully", earnings)
+}
+
+// GetPayouts godoc
+// @Summary Get the current driver's payouts
+// @Tags earnings
+// @Produce json
+// @Param limit query int false "Limit" default(20)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {object} domain.SuccessResponse
+// @Router /drivers/earnings/payouts [get]
+// @Security BearerAuth
+func (h *EarningsHandler) GetPayouts(w http.ResponseWriter, r *http.Request) {
+	driverIDStr := r.Context().Value("user_id").(string)
+	driverID, err := utils.ParseUUID(driverIDStr)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	payouts, err := h.earningsService.GetDriverPayouts(r.Context(), driverID, queryInt(r, "limit", 20), queryInt(r, "offset", 0))
+	if err != nil {
+		utils.HandleServiceError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Payouts retrieved successfully", payouts)
+}
+
+// ListCommissionPlans godoc
+// @Summary List commission plans (admin)
+// @Tags earnings
+// @Produce json
+// @Success 200 {object} domain.SuccessResponse
+// @Router /drivers/commission-plans [get]
+// @Security BearerAuth
+func (h *EarningsHandler) ListCommissionPlans(w http.ResponseWriter, r *http.Request) {
+	plans, err := h.earningsService.ListCommissionPlans(r.Context())
+	if err != nil {
+		utils.HandleServiceError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Commission plans retrieved successfully", plans)
+}
+
+// CreateCommissionPlan godoc
+// @Summary Create a commission plan (admin)
+// @Tags earnings
+// @Accept json
+// @Produce json
+// @Param request body domain.CreateCommissionPlanRequest true "Plan details"
+// @Success 201 {object} domain.SuccessResponse
+// @Failure 400 {object} domain.ErrorResponse
+// @Router /drivers/commission-plans [post]
+// @Security BearerAuth
+func (h *EarningsHandler) CreateCommissionPlan(w http.ResponseWriter, r *http.Request) {
+	var req domain.CreateCommissionPlanRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	adminIDStr := r.Context().Value("user_id").(string)
+	adminID, err := utils.ParseUUID(adminIDStr)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	plan, err := h.earningsService.CreateCommissionPlan(r.Context(), adminID, &req)
+	if err != nil {
+		handleEarningsError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusCreated, "Commission plan created successfully", plan)
+}
+
+// UpdateCommissionPlanStatus godoc
+// @Summary Activate or deactivate a commission plan (admin)
+// @Tags earnings
+// @Accept json
+// @Produce json
+// @Param id path string true "Plan ID"
+// @Param request body domain.UpdateCommissionPlanStatusRequest true "Status"
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Router /drivers/commission-plans/{id}/status [put]
+// @Security BearerAuth
+func (h *EarningsHandler) UpdateCommissionPlanStatus(w http.ResponseWriter, r *http.Request) {
+	planID, err := utils.ParseUUID(mux.Vars(r)["id"])
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid plan ID")
+		return
+	}
+
+	var req domain.UpdateCommissionPlanStatusRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	plan, err := h.earningsService.SetCommissionPlanActive(r.Context(), planID, req.IsActive)
+	if err != nil {
+		handleEarningsError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Commission plan updated successfully", plan)
+}
+
+// CreatePayoutBatch godoc
+// @Summary Create a payout batch from unpaid earnings (admin)
+// @Tags earnings
+// @Accept json
+// @Produce json
+// @Param request body domain.CreatePayoutBatchRequest true "Batch period"
+// @Success 201 {object} domain.SuccessResponse
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 422 {object} domain.ErrorResponse
+// @Router /drivers/payouts [post]
+// @Security BearerAuth
+func (h *EarningsHandler) CreatePayoutBatch(w http.ResponseWriter, r *http.Request) {
+	var req domain.CreatePayoutBatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	adminIDStr := r.Context().Value("user_id").(string)
+	adminID, err := utils.ParseUUID(adminIDStr)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	batch, err := h.earningsService.CreatePayoutBatch(r.Context(), adminID, req.PeriodEnd)
+	if err != nil {
+		handleEarningsError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusCreated, "Payout batch created successfully", batch)
+}
+
+// ListPayoutBatches godoc
+// @Summary List payout batches (admin)
+// @Tags earnings
+// @Produce json
+// @Param limit query int false "Limit" default(20)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {object} domain.SuccessResponse
+// @Router /drivers/payouts [get]
+// @Security BearerAuth
+func (h *EarningsHandler) ListPayoutBatches(w http.ResponseWriter, r *http.Request) {
+	batches, err := h.earningsService.ListPayoutBatches(r.Context(), queryInt(r, "limit", 20), queryInt(r, "offset", 0))
+	if err != nil {
+		utils.HandleServiceError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Payout batches retrieved successfully", batches)
+}
+
+// GetPayoutBatch godoc
+// @Summary Get a payout batch with its items (admin)
+// @Tags earnings
+// @Produce json
+// @Param id path string true "Batch ID"
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Router /drivers/payouts/{id} [get]
+// @Security BearerAuth
+func (h *EarningsHandler) GetPayoutBatch(w http.ResponseWriter, r *http.Request) {
+	batchID, err := utils.ParseUUID(mux.Vars(r)["id"])
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid batch ID")
+		return
+	}
+
+	batch, err := h.earningsService.GetPayoutBatch(r.Context(), batchID)
+	if err != nil {
+		handleEarningsError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Payout batch retrieved successfully", batch)
+}
+
+// UpdatePayoutBatchStatus godoc
+// @Summary Move a payout batch to processing, paid or failed (admin)
+// @Tags earnings
+// @Accept json
+// @Produce json
+// @Param id path string true "Batch ID"
+// @Param request body domain.UpdatePayoutStatusRequest true "Status"
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 409 {object} domain.ErrorResponse
+// @Router /drivers/payouts/{id}/status [put]
+// @Security BearerAuth
+func (h *EarningsHandler) UpdatePayoutBatchStatus(w http.ResponseWriter, r *http.Request) {
+	batchID, err := utils.ParseUUID(mux.Vars(r)["id"])
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid batch ID")
+		return
+	}
+
+	var req domain.UpdatePayoutStatusRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	batch, err := h.earningsService.UpdatePayoutBatchStatus(r.Context(), batchID, &req)
+	if err != nil {
+		handleEarningsError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Payout batch updated successfully", batch)
+}
+
+// UpdatePayoutItemStatus godoc
+// @Summary Mark a single driver payout as paid or failed (admin)
+// @Tags earnings
+// @Accept json
+// @Produce json
+// @Param id path string true "Batch ID"
+// @Param item_id path string true "Payout item ID"
+// @Param request body domain.UpdatePayoutStatusRequest true "Status"
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 409 {object} domain.ErrorResponse
+// @Router /drivers/payouts/{id}/items/{item_id}/status [put]
+// @Security BearerAuth
+func (h *EarningsHandler) UpdatePayoutItemStatus(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	batchID, err := utils.ParseUUID(vars["id"])
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid batch ID")
+		return
+	}
+	itemID, err := utils.ParseUUID(vars["item_id"])
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid payout item ID")
+		return
+	}
+
+	var req domain.UpdatePayoutStatusRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	item, err := h.earningsService.UpdatePayoutItemStatus(r.Context(), batchID, itemID, &req)
+	if err != nil {
+		handleEarningsError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Payout item updated successfully", item)
+}
+
+func handleEarningsError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidRate),
+		errors.Is(err, service.ErrInvalidPeriod),
+		errors.Is(err, service.ErrUnknownCity),
+		err.Error() == "plan name is required":
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, service.ErrInvalidPayoutTransition):
+		utils.ErrorResponse(w, http.StatusConflict, err.Error())
+	case errors.Is(err, service.ErrNoPayableEarnings):
+		utils.ErrorResponse(w, http.StatusUnprocessableEntity, err.Error())
+	case err.Error() == "commission plan not found",
+		err.Error() == "payout batch not found",
+		err.Error() == "payout item not found":
+		utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+	default:
+		utils.HandleServiceError(w, err)
+	}
+}
+
+func queryInt(r *http.Request, name string, defaultValue int32) int32 {
+	value, err := strconv.Atoi(r.URL.Query().Get(name))
+	if err != nil || value < 0 {
+		return defaultValue
+	}
+	return int32(value)
+}
+
+func parseTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", value)
+}
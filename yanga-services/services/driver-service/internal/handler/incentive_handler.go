@@ -0,0 +1,143 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/namycodes/yanga-services/services/driver-service/internal/service"
+	"github.com/namycodes/yanga-services/shared-lib/domain"
+	"github.com/namycodes/yanga-services/shared-lib/utils"
+)
+
+type IncentiveHandler struct {
+	incentiveService *service.IncentiveService
+}
+
+func NewIncentiveHandler(incentiveService *service.IncentiveService) *IncentiveHandler {
+	return &IncentiveHandler{
+		incentiveService: incentiveService,
+	}
+}
+
+// GetMyIncentives godoc
+// @Summary Get the incentive programs open to the current driver with their progress
+// @Tags incentives
+// @Produce json
+// @Success 200 {object} domain.SuccessResponse
+// @Router /drivers/incentives [get]
+// @Security BearerAuth
+func (h *IncentiveHandler) GetMyIncentives(w http.ResponseWriter, r *http.Request) {
+	driverIDStr := r.Context().Value("user_id").(string)
+	driverID, err := utils.ParseUUID(driverIDStr)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	incentives, err := h.incentiveService.GetDriverIncentives(r.Context(), driverID)
+	if err != nil {
+		handleIncentiveError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Incentives retrieved successfully", incentives)
+}
+
+// ListIncentivePrograms godoc
+// @Summary List incentive programs (admin)
+// @Tags incentives
+// @Produce json
+// @Param limit query int false "Limit" default(20)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {object} domain.SuccessResponse
+// @Router /drivers/incentive-programs [get]
+// @Security BearerAuth
+func (h *IncentiveHandler) ListIncentivePrograms(w http.ResponseWriter, r *http.Request) {
+	programs, err := h.incentiveService.ListPrograms(r.Context(), queryInt(r, "limit", 20), queryInt(r, "offset", 0))
+	if err != nil {
+		utils.HandleServiceError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Incentive programs retrieved successfully", programs)
+}
+
+// CreateIncentiveProgram godoc
+// @Summary Create a quest, peak guarantee or area boost (admin)
+// @Tags incentives
+// @Accept json
+// @Produce json
+// @Param request body domain.CreateIncentiveProgramRequest true "Program details"
+// @Success 201 {object} domain.SuccessResponse
+// @Failure 400 {object} domain.ErrorResponse
+// @Router /drivers/incentive-programs [post]
+// @Security BearerAuth
+func (h *IncentiveHandler) CreateIncentiveProgram(w http.ResponseWriter, r *http.Request) {
+	var req domain.CreateIncentiveProgramRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	adminIDStr := r.Context().Value("user_id").(string)
+	adminID, err := utils.ParseUUID(adminIDStr)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	program, err := h.incentiveService.CreateProgram(r.Context(), adminID, &req)
+	if err != nil {
+		handleIncentiveError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusCreated, "Incentive program created successfully", program)
+}
+
+// UpdateIncentiveProgramStatus godoc
+// @Summary Pause or resume an incentive program (admin)
+// @Tags incentives
+// @Accept json
+// @Produce json
+// @Param id path string true "Program ID"
+// @Param request body domain.UpdateIncentiveProgramStatusRequest true "Status"
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Router /drivers/incentive-programs/{id}/status [put]
+// @Security BearerAuth
+func (h *IncentiveHandler) UpdateIncentiveProgramStatus(w http.ResponseWriter, r *http.Request) {
+	programID, err := utils.ParseUUID(mux.Vars(r)["id"])
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid program ID")
+		return
+	}
+
+	var req domain.UpdateIncentiveProgramStatusRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	program, err := h.incentiveService.SetProgramActive(r.Context(), programID, req.IsActive)
+	if err != nil {
+		handleIncentiveError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Incentive program updated successfully", program)
+}
+
+func handleIncentiveError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidIncentive),
+		errors.Is(err, service.ErrUnknownCity):
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, service.ErrIncentiveNotFound):
+		utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+	default:
+		utils.HandleServiceError(w, err)
+	}
+}
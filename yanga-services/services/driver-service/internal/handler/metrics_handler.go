@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/namycodes/yanga-services/services/driver-service/internal/service"
+	"github.com/namycodes/yanga-services/shared-lib/utils"
+)
+
+type MetricsHandler struct {
+	metricsService *service.MetricsService
+}
+
+func NewMetricsHandler(metricsService *service.MetricsService) *MetricsHandler {
+	return &MetricsHandler{
+		metricsService: metricsService,
+	}
+}
+
+// GetMetrics godoc
+// @Summary Get the current driver's acceptance and cancellation rates
+// @Tags metrics
+// @Produce json
+// @Param days query int false "Look-back window in days" default(30)
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 400 {object} domain.ErrorResponse
+// @Router /drivers/metrics [get]
+// @Security BearerAuth
+func (h *MetricsHandler) GetMetrics(w http.ResponseWriter, r *http.Request) {
+	driverIDStr := r.Context().Value("user_id").(string)
+	driverID, err := utils.ParseUUID(driverIDStr)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	metrics, err := h.metricsService.GetDriverMetrics(r.Context(), driverID, queryInt(r, "days", 30))
+	if err != nil {
+		handleEarningsError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Driver metrics retrieved successfully", metrics)
+}
+
+// GetDriverMetrics godoc
+// @Summary Get a driver's acceptance and cancellation rates (admin)
+// @Tags metrics
+// @Produce json
+// @Param id path string true "Driver user ID"
+// @Param days query int false "Look-back window in days" default(30)
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 400 {object} domain.ErrorResponse
+// @Router /drivers/{id}/metrics [get]
+// @Security BearerAuth
+func (h *MetricsHandler) GetDriverMetrics(w http.ResponseWriter, r *http.Request) {
+	driverID, err := utils.ParseUUID(mux.Vars(r)["id"])
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid driver ID")
+		return
+	}
+
+	metrics, err := h.metricsService.GetDriverMetrics(r.Context(), driverID, queryInt(r, "days", 30))
+	if err != nil {
+		handleEarningsError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Driver metrics retrieved successfully", metrics)
+}
@@ -0,0 +1,224 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/namycodes/yanga-services/services/driver-service/internal/service"
+	"github.com/namycodes/yanga-services/shared-lib/domain"
+	"github.com/namycodes/yanga-services/shared-lib/utils"
+)
+
+type QualityHandler struct {
+	qualityService *service.QualityService
+}
+
+func NewQualityHandler(qualityService *service.QualityService) *QualityHandler {
+	return &QualityHandler{
+		qualityService: qualityService,
+	}
+}
+
+// GetMyQuality godoc
+// @Summary Get the current driver's quality score, any suspension and recent actions
+// @Tags drivers
+// @Produce json
+// @Success 200 {object} domain.DriverQualityResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Router /drivers/quality [get]
+// @Security BearerAuth
+func (h *QualityHandler) GetMyQuality(w http.ResponseWriter, r *http.Request) {
+	driverIDStr := r.Context().Value("user_id").(string)
+	driverID, err := utils.ParseUUID(driverIDStr)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	quality, err := h.qualityService.GetDriverQuality(r.Context(), driverID)
+	if err != nil {
+		handleQualityError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Driver quality retrieved successfully", quality)
+}
+
+// GetDriverQuality godoc
+// @Summary Get a driver's quality score and actions (admin only)
+// @Tags drivers
+// @Produce json
+// @Param id path string true "Driver user ID"
+// @Success 200 {object} domain.DriverQualityResponse
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Router /drivers/{id}/quality [get]
+// @Security BearerAuth
+func (h *QualityHandler) GetDriverQuality(w http.ResponseWriter, r *http.Request) {
+	driverID, err := utils.ParseUUID(mux.Vars(r)["id"])
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid driver ID")
+		return
+	}
+
+	quality, err := h.qualityService.GetDriverQuality(r.Context(), driverID)
+	if err != nil {
+		handleQualityError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Driver quality retrieved successfully", quality)
+}
+
+// EvaluateDriverQuality godoc
+// @Summary Score a driver now (admin only)
+// @Description Scores the driver as the periodic check would, including issuing any warning, suspension or review.
+// @Tags drivers
+// @Produce json
+// @Param id path string true "Driver user ID"
+// @Success 200 {object} domain.DriverQualityResponse
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Router /drivers/{id}/quality/evaluate [post]
+// @Security BearerAuth
+func (h *QualityHandler) EvaluateDriverQuality(w http.ResponseWriter, r *http.Request) {
+	driverID, err := utils.ParseUUID(mux.Vars(r)["id"])
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid driver ID")
+		return
+	}
+
+	quality, err := h.qualityService.Evaluate(r.Context(), driverID)
+	if err != nil {
+		handleQualityError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Driver quality evaluated", quality)
+}
+
+// ListQualityReviews godoc
+// @Summary List drivers at a quality level, worst first (admin only)
+// @Tags drivers
+// @Produce json
+// @Param level query string false "good, warning, suspended or review" default(review)
+// @Param limit query int false "Limit" default(50)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {array} domain.DriverQualityResponse
+// @Router /drivers/quality/reviews [get]
+// @Security BearerAuth
+func (h *QualityHandler) ListQualityReviews(w http.ResponseWriter, r *http.Request) {
+	level := r.URL.Query().Get("level")
+	if level == "" {
+		level = domain.QualityLevelReview
+	}
+
+	drivers, err := h.qualityService.ListByLevel(r.Context(), level, queryInt(r, "limit", 50), queryInt(r, "offset", 0))
+	if err != nil {
+		handleQualityError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Drivers retrieved successfully", drivers)
+}
+
+// ReinstateDriver godoc
+// @Summary Reinstate a suspended driver or one under review (admin only)
+// @Description Ends any suspension, approves the driver again and scores them afresh from now.
+// @Tags drivers
+// @Accept json
+// @Produce json
+// @Param id path string true "Driver user ID"
+// @Param request body domain.ReinstateDriverRequest true "Review note"
+// @Success 200 {object} domain.DriverQualityResponse
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 409 {object} domain.ErrorResponse
+// @Router /drivers/{id}/quality/reinstate [post]
+// @Security BearerAuth
+func (h *QualityHandler) ReinstateDriver(w http.ResponseWriter, r *http.Request) {
+	driverID, err := utils.ParseUUID(mux.Vars(r)["id"])
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid driver ID")
+		return
+	}
+
+	var req domain.ReinstateDriverRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	adminIDStr := r.Context().Value("user_id").(string)
+	adminID, err := utils.ParseUUID(adminIDStr)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	quality, err := h.qualityService.Reinstate(r.Context(), adminID, driverID, &req)
+	if err != nil {
+		handleQualityError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Driver reinstated", quality)
+}
+
+// CreateComplaint godoc
+// @Summary Record a complaint against a driver (admin only)
+// @Tags drivers
+// @Accept json
+// @Produce json
+// @Param id path string true "Driver user ID"
+// @Param request body domain.CreateDriverComplaintRequest true "Complaint"
+// @Success 201 {object} domain.DriverComplaintResponse
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Router /drivers/{id}/complaints [post]
+// @Security BearerAuth
+func (h *QualityHandler) CreateComplaint(w http.ResponseWriter, r *http.Request) {
+	driverID, err := utils.ParseUUID(mux.Vars(r)["id"])
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid driver ID")
+		return
+	}
+
+	var req domain.CreateDriverComplaintRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	adminIDStr := r.Context().Value("user_id").(string)
+	adminID, err := utils.ParseUUID(adminIDStr)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	complaint, err := h.qualityService.CreateComplaint(r.Context(), adminID, driverID, &req)
+	if err != nil {
+		handleQualityError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusCreated, "Complaint recorded", complaint)
+}
+
+func handleQualityError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidComplaint),
+		errors.Is(err, service.ErrNoteRequired):
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, service.ErrDriverNotFound),
+		errors.Is(err, service.ErrQualityNotScored):
+		utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrNotSuspended):
+		utils.ErrorResponse(w, http.StatusConflict, err.Error())
+	default:
+		utils.HandleServiceError(w, err)
+	}
+}
@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/namycodes/yanga-services/services/driver-service/internal/db"
+)
+
+type DriverRatingRepository struct {
+	queries *db.Queries
+}
+
+func NewDriverRatingRepository(queries *db.Queries) *DriverRatingRepository {
+	return &DriverRatingRepository{
+		queries: queries,
+	}
+}
+
+func (r *DriverRatingRepository) RecordDriverRating(ctx context.Context, ratingID pgtype.UUID) (int64, error) {
+	return r.queries.RecordDriverRating(ctx, ratingID)
+}
+
+func (r *DriverRatingRepository) BackfillDriverRatingEntries(ctx context.Context) (int64, error) {
+	return r.queries.BackfillDriverRatingEntries(ctx)
+}
+
+func (r *DriverRatingRepository) RefreshDriverRatingStats(ctx context.Context, params db.RefreshDriverRatingStatsParams) (db.DriverRatingStat, error) {
+	return r.queries.RefreshDriverRatingStats(ctx, params)
+}
+
+func (r *DriverRatingRepository) GetDriverRatingStats(ctx context.Context, driverID pgtype.UUID) (db.DriverRatingStat, error) {
+	return r.queries.GetDriverRatingStats(ctx, driverID)
+}
+
+func (r *DriverRatingRepository) CountDriverCompletedTrips(ctx context.Context, driverID pgtype.UUID) (int64, error) {
+	return r.queries.CountDriverCompletedTrips(ctx, driverID)
+}
+
+func (r *DriverRatingRepository) ListDriverUserIDs(ctx context.Context) ([]pgtype.UUID, error) {
+	return r.queries.ListDriverUserIDs(ctx)
+}
@@ -56,3 +56,7 @@ func (r *DriverRepository) GetNearbyDrivers(ctx context.Context, params db.GetNe
 func (r *DriverRepository) GetDriverStats(ctx context.Context, userID pgtype.UUID) (db.GetDriverStatsRow, error) {
 	return r.queries.GetDriverStats(ctx, userID)
 }
+
+func (r *DriverRepository) GetDriverDispatchStatus(ctx context.Context, userID pgtype.UUID) (db.GetDriverDispatchStatusRow, error) {
+	return r.queries.GetDriverDispatchStatus(ctx, userID)
+}
@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/namycodes/yanga-services/services/driver-service/internal/db"
+)
+
+type EarningsRepository struct {
+	pool    *pgxpool.Pool
+	queries *db.Queries
+}
+
+func NewEarningsRepository(pool *pgxpool.Pool, queries *db.Queries) *EarningsRepository {
+	return &EarningsRepository{
+		pool:    pool,
+		queries: queries,
+	}
+}
+
+// IsForeignKeyViolation reports whether err is a Postgres foreign key
+// violation, such as a row naming a city that doesn't exist.
+func IsForeignKeyViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		// 23503 foreign_key_violation
+		return pgErr.Code == "23503"
+	}
+	return false
+}
+
+// WithTx runs fn inside a database transaction, committing only if fn
+// returns nil.
+func (r *EarningsRepository) WithTx(ctx context.Context, fn func(q *db.Queries) error) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := fn(r.queries.WithTx(tx)); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *EarningsRepository) CreateCommissionPlan(ctx context.Context, params db.CreateCommissionPlanParams) (db.CommissionPlan, error) {
+	return r.queries.CreateCommissionPlan(ctx, params)
+}
+
+func (r *EarningsRepository) ListCommissionPlans(ctx context.Context) ([]db.CommissionPlan, error) {
+	return r.queries.ListCommissionPlans(ctx)
+}
+
+func (r *EarningsRepository) UpdateCommissionPlanStatus(ctx context.Context, params db.UpdateCommissionPlanStatusParams) (db.CommissionPlan, error) {
+	return r.queries.UpdateCommissionPlanStatus(ctx, params)
+}
+
+func (r *EarningsRepository) GetApplicableCommissionPlan(ctx context.Context, params db.GetApplicableCommissionPlanParams) (db.CommissionPlan, error) {
+	return r.queries.GetApplicableCommissionPlan(ctx, params)
+}
+
+func (r *EarningsRepository) CreateDriverEarning(ctx context.Context, params db.CreateDriverEarningParams) (db.DriverEarning, error) {
+	return r.queries.CreateDriverEarning(ctx, params)
+}
+
+func (r *EarningsRepository) GetDriverEarnings(ctx context.Context, params db.GetDriverEarningsParams) ([]db.DriverEarning, error) {
+	return r.queries.GetDriverEarnings(ctx, params)
+}
+
+func (r *EarningsRepository) GetDriverEarningsSummary(ctx context.Context, params db.GetDriverEarningsSummaryParams) (db.GetDriverEarningsSummaryRow, error) {
+	return r.queries.GetDriverEarningsSummary(ctx, params)
+}
+
+func (r *EarningsRepository) GetDriverDailyEarnings(ctx context.Context, params db.GetDriverDailyEarningsParams) ([]db.GetDriverDailyEarningsRow, error) {
+	return r.queries.GetDriverDailyEarnings(ctx, params)
+}
+
+func (r *EarningsRepository) GetDriverWeeklyEarnings(ctx context.Context, params db.GetDriverWeeklyEarningsParams) ([]db.GetDriverWeeklyEarningsRow, error) {
+	return r.queries.GetDriverWeeklyEarnings(ctx, params)
+}
+
+func (r *EarningsRepository) GetPayoutBatch(ctx context.Context, id pgtype.UUID) (db.PayoutBatch, error) {
+	return r.queries.GetPayoutBatch(ctx, id)
+}
+
+func (r *EarningsRepository) ListPayoutBatches(ctx context.Context, params db.ListPayoutBatchesParams) ([]db.PayoutBatch, error) {
+	return r.queries.ListPayoutBatches(ctx, params)
+}
+
+func (r *EarningsRepository) GetPayoutItemsByBatch(ctx context.Context, batchID pgtype.UUID) ([]db.PayoutItem, error) {
+	return r.queries.GetPayoutItemsByBatch(ctx, batchID)
+}
+
+func (r *EarningsRepository) GetDriverPayoutItems(ctx context.Context, params db.GetDriverPayoutItemsParams) ([]db.PayoutItem, error) {
+	return r.queries.GetDriverPayoutItems(ctx, params)
+}
@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/namycodes/yanga-services/services/driver-service/internal/db"
+)
+
+type IncentiveRepository struct {
+	pool    *pgxpool.Pool
+	queries *db.Queries
+}
+
+func NewIncentiveRepository(pool *pgxpool.Pool, queries *db.Queries) *IncentiveRepository {
+	return &IncentiveRepository{
+		pool:    pool,
+		queries: queries,
+	}
+}
+
+// WithTx runs fn inside a database transaction, committing only if fn
+// returns nil.
+func (r *IncentiveRepository) WithTx(ctx context.Context, fn func(q *db.Queries) error) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := fn(r.queries.WithTx(tx)); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *IncentiveRepository) CreateIncentiveProgram(ctx context.Context, params db.CreateIncentiveProgramParams) (db.IncentiveProgram, error) {
+	return r.queries.CreateIncentiveProgram(ctx, params)
+}
+
+func (r *IncentiveRepository) GetIncentiveProgram(ctx context.Context, id pgtype.UUID) (db.IncentiveProgram, error) {
+	return r.queries.GetIncentiveProgram(ctx, id)
+}
+
+func (r *IncentiveRepository) ListIncentivePrograms(ctx context.Context, params db.ListIncentiveProgramsParams) ([]db.IncentiveProgram, error) {
+	return r.queries.ListIncentivePrograms(ctx, params)
+}
+
+func (r *IncentiveRepository) UpdateIncentiveProgramStatus(ctx context.Context, params db.UpdateIncentiveProgramStatusParams) (db.IncentiveProgram, error) {
+	return r.queries.UpdateIncentiveProgramStatus(ctx, params)
+}
+
+func (r *IncentiveRepository) GetApplicableIncentivePrograms(ctx context.Context, params db.GetApplicableIncentiveProgramsParams) ([]db.IncentiveProgram, error) {
+	return r.queries.GetApplicableIncentivePrograms(ctx, params)
+}
+
+func (r *IncentiveRepository) GetDriverIncentivePrograms(ctx context.Context, params db.GetDriverIncentiveProgramsParams) ([]db.IncentiveProgram, error) {
+	return r.queries.GetDriverIncentivePrograms(ctx, params)
+}
+
+func (r *IncentiveRepository) GetDriverIncentiveProgress(ctx context.Context, params db.GetDriverIncentiveProgressParams) ([]db.IncentiveProgress, error) {
+	return r.queries.GetDriverIncentiveProgress(ctx, params)
+}
+
+func (r *IncentiveRepository) GetUnsettledPeakGuarantees(ctx context.Context, params db.GetUnsettledPeakGuaranteesParams) ([]db.IncentiveProgram, error) {
+	return r.queries.GetUnsettledPeakGuarantees(ctx, params)
+}
+
+func (r *IncentiveRepository) GetCityCurrency(ctx context.Context, code string) (string, error) {
+	return r.queries.GetCityCurrency(ctx, code)
+}
+
+func (r *IncentiveRepository) GetGeofencePolygon(ctx context.Context, id pgtype.UUID) ([]byte, error) {
+	return r.queries.GetGeofencePolygon(ctx, id)
+}
@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/namycodes/yanga-services/services/driver-service/internal/db"
+)
+
+type MetricsRepository struct {
+	queries *db.Queries
+}
+
+func NewMetricsRepository(queries *db.Queries) *MetricsRepository {
+	return &MetricsRepository{
+		queries: queries,
+	}
+}
+
+func (r *MetricsRepository) GetDriverRequestStats(ctx context.Context, params db.GetDriverRequestStatsParams) (db.GetDriverRequestStatsRow, error) {
+	return r.queries.GetDriverRequestStats(ctx, params)
+}
+
+func (r *MetricsRepository) GetDriverTripStats(ctx context.Context, params db.GetDriverTripStatsParams) (db.GetDriverTripStatsRow, error) {
+	return r.queries.GetDriverTripStats(ctx, params)
+}
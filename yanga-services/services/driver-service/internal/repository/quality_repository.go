@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/namycodes/yanga-services/services/driver-service/internal/db"
+)
+
+type QualityRepository struct {
+	pool    *pgxpool.Pool
+	queries *db.Queries
+}
+
+func NewQualityRepository(pool *pgxpool.Pool, queries *db.Queries) *QualityRepository {
+	return &QualityRepository{
+		pool:    pool,
+		queries: queries,
+	}
+}
+
+// WithTx runs fn inside a database transaction, committing only if fn
+// returns nil.
+func (r *QualityRepository) WithTx(ctx context.Context, fn func(q *db.Queries) error) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := fn(r.queries.WithTx(tx)); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *QualityRepository) GetDriverRatingSince(ctx context.Context, params db.GetDriverRatingSinceParams) (db.GetDriverRatingSinceRow, error) {
+	return r.queries.GetDriverRatingSince(ctx, params)
+}
+
+func (r *QualityRepository) CountDriverComplaintsSince(ctx context.Context, params db.CountDriverComplaintsSinceParams) (int64, error) {
+	return r.queries.CountDriverComplaintsSince(ctx, params)
+}
+
+func (r *QualityRepository) RecordRatingComplaint(ctx context.Context, params db.RecordRatingComplaintParams) (int64, error) {
+	return r.queries.RecordRatingComplaint(ctx, params)
+}
+
+func (r *QualityRepository) RecordSupportComplaint(ctx context.Context, params db.RecordSupportComplaintParams) (int64, error) {
+	return r.queries.RecordSupportComplaint(ctx, params)
+}
+
+func (r *QualityRepository) CreateDriverComplaint(ctx context.Context, params db.CreateDriverComplaintParams) (db.DriverComplaint, error) {
+	return r.queries.CreateDriverComplaint(ctx, params)
+}
+
+func (r *QualityRepository) ListDriverComplaints(ctx context.Context, params db.ListDriverComplaintsParams) ([]db.DriverComplaint, error) {
+	return r.queries.ListDriverComplaints(ctx, params)
+}
+
+func (r *QualityRepository) GetDriverQualityScore(ctx context.Context, driverID pgtype.UUID) (db.DriverQualityScore, error) {
+	return r.queries.GetDriverQualityScore(ctx, driverID)
+}
+
+func (r *QualityRepository) ListDriverQualityScoresByLevel(ctx context.Context, params db.ListDriverQualityScoresByLevelParams) ([]db.ListDriverQualityScoresByLevelRow, error) {
+	return r.queries.ListDriverQualityScoresByLevel(ctx, params)
+}
+
+func (r *QualityRepository) GetLatestDriverQualityAction(ctx context.Context, params db.GetLatestDriverQualityActionParams) (db.DriverQualityAction, error) {
+	return r.queries.GetLatestDriverQualityAction(ctx, params)
+}
+
+func (r *QualityRepository) ListDriverQualityActions(ctx context.Context, params db.ListDriverQualityActionsParams) ([]db.DriverQualityAction, error) {
+	return r.queries.ListDriverQualityActions(ctx, params)
+}
+
+func (r *QualityRepository) ListDriverUserIDs(ctx context.Context) ([]pgtype.UUID, error) {
+	return r.queries.ListDriverUserIDs(ctx)
+}
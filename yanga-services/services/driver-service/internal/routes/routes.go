@@ -8,12 +8,12 @@ import (
 	httpSwagger "github.com/swaggo/http-swagger"
 )
 
-func SetupDriverRoutes(router *mux.Router, driverHandler *handler.DriverHandler, jwtCfg config.JWTConfig) {
+func SetupDriverRoutes(router *mux.Router, driverHandler *handler.DriverHandler, earningsHandler *handler.EarningsHandler, metricsHandler *handler.MetricsHandler, incentiveHandler *handler.IncentiveHandler, driverRatingHandler *handler.DriverRatingHandler, qualityHandler *handler.QualityHandler, jwtCfg config.JWTConfig) {
 	api := router.PathPrefix("/api/v1").Subrouter()
 
 	// Protected routes - require authentication
 	drivers := api.PathPrefix("/drivers").Subrouter()
-	drivers.Use(middleware.AuthMiddleware(jwtCfg.Secret))
+	drivers.Use(middleware.AuthMiddleware(jwtCfg.Secret, jwtCfg.IdentitySecret))
 
 	// Driver profile management
 	drivers.HandleFunc("/profile", driverHandler.UpdateProfile).Methods("PUT")
@@ -26,6 +26,45 @@ func SetupDriverRoutes(router *mux.Router, driverHandler *handler.DriverHandler,
 	drivers.HandleFunc("/trips/{id}/start", driverHandler.StartTrip).Methods("POST")
 	drivers.HandleFunc("/trips/{id}/complete", driverHandler.CompleteTrip).Methods("POST")
 
+	// Driver earnings
+	drivers.HandleFunc("/earnings", earningsHandler.GetEarnings).Methods("GET")
+	drivers.HandleFunc("/earnings/payouts", earningsHandler.GetPayouts).Methods("GET")
+
+	// Driver incentives
+	drivers.HandleFunc("/incentives", incentiveHandler.GetMyIncentives).Methods("GET")
+
+	// Driver acceptance and cancellation metrics
+	drivers.HandleFunc("/metrics", metricsHandler.GetMetrics).Methods("GET")
+
+	// Driver rating aggregates
+	drivers.HandleFunc("/rating", driverRatingHandler.GetRating).Methods("GET")
+
+	// Driver quality score and any suspension
+	drivers.HandleFunc("/quality", qualityHandler.GetMyQuality).Methods("GET")
+
+	// Commission plans, incentive programs, payouts and driver quality - admin only
+	admin := drivers.NewRoute().Subrouter()
+	admin.Use(middleware.RequireRole("admin"))
+
+	admin.HandleFunc("/commission-plans", earningsHandler.ListCommissionPlans).Methods("GET")
+	admin.HandleFunc("/commission-plans", earningsHandler.CreateCommissionPlan).Methods("POST")
+	admin.HandleFunc("/commission-plans/{id}/status", earningsHandler.UpdateCommissionPlanStatus).Methods("PUT")
+	admin.HandleFunc("/incentive-programs", incentiveHandler.ListIncentivePrograms).Methods("GET")
+	admin.HandleFunc("/incentive-programs", incentiveHandler.CreateIncentiveProgram).Methods("POST")
+	admin.HandleFunc("/incentive-programs/{id}/status", incentiveHandler.UpdateIncentiveProgramStatus).Methods("PUT")
+	admin.HandleFunc("/payouts", earningsHandler.ListPayoutBatches).Methods("GET")
+	admin.HandleFunc("/payouts", earningsHandler.CreatePayoutBatch).Methods("POST")
+	admin.HandleFunc("/payouts/{id}", earningsHandler.GetPayoutBatch).Methods("GET")
+	admin.HandleFunc("/payouts/{id}/status", earningsHandler.UpdatePayoutBatchStatus).Methods("PUT")
+	admin.HandleFunc("/payouts/{id}/items/{item_id}/status", earningsHandler.UpdatePayoutItemStatus).Methods("PUT")
+	admin.HandleFunc("/{id}/metrics", metricsHandler.GetDriverMetrics).Methods("GET")
+	admin.HandleFunc("/{id}/rating", driverRatingHandler.GetDriverRating).Methods("GET")
+	admin.HandleFunc("/quality/reviews", qualityHandler.ListQualityReviews).Methods("GET")
+	admin.HandleFunc("/{id}/quality", qualityHandler.GetDriverQuality).Methods("GET")
+	admin.HandleFunc("/{id}/quality/evaluate", qualityHandler.EvaluateDriverQuality).Methods("POST")
+	admin.HandleFunc("/{id}/quality/reinstate", qualityHandler.ReinstateDriver).Methods("POST")
+	admin.HandleFunc("/{id}/complaints", qualityHandler.CreateComplaint).Methods("POST")
+
 	// Swagger documentation
 	router.PathPrefix("/swagger/").Handler(httpSwagger.WrapHandler)
 }
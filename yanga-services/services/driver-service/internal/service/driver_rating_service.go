@@ -0,0 +1,218 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/namycodes/yanga-services/services/driver-service/internal/db"
+	"github.com/namycodes/yanga-services/services/driver-service/internal/repository"
+	"github.com/namycodes/yanga-services/shared-lib/config"
+	"github.com/namycodes/yanga-services/shared-lib/domain"
+	"github.com/namycodes/yanga-services/shared-lib/events"
+	"github.com/namycodes/yanga-services/shared-lib/utils"
+)
+
+// DriverRatingService keeps each driver's rating aggregates and the rating
+// and trip count on their profile up to date. Aggregates are recomputed
+// from the ratings counted so far rather than adjusted, so processing the
+// same event twice gives the same result.
+type DriverRatingService struct {
+	repo       *repository.DriverRatingRepository
+	driverRepo *repository.DriverRepository
+	eventBus   events.EventBus
+	window     int32
+}
+
+// NewDriverRatingService creates the service. eventBus may be nil when the
+// service is only used to backfill.
+func NewDriverRatingService(repo *repository.DriverRatingRepository, driverRepo *repository.DriverRepository, eventBus events.EventBus, cfg *config.Config) *DriverRatingService {
+	window := int32(cfg.DriverRatingWindow)
+	if window <= 0 {
+		window = 100
+	}
+	return &DriverRatingService{
+		repo:       repo,
+		driverRepo: driverRepo,
+		eventBus:   eventBus,
+		window:     window,
+	}
+}
+
+// GetDriverRating returns a driver's rating aggregates. Drivers who haven't
+// been rated yet get zeroes.
+func (s *DriverRatingService) GetDriverRating(ctx context.Context, driverID uuid.UUID) (*domain.DriverRatingResponse, error) {
+	pgDriverID := utils.ToPgUUID(driverID)
+
+	stats, err := s.repo.GetDriverRatingStats(ctx, pgDriverID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("failed to get rating stats: %w", err)
+	}
+
+	trips, err := s.repo.CountDriverCompletedTrips(ctx, pgDriverID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count trips: %w", err)
+	}
+
+	return &domain.DriverRatingResponse{
+		DriverID:        driverID.String(),
+		Rating:          utils.NumericToFloat64(stats.RollingAverage),
+		RollingCount:    stats.RollingCount,
+		LifetimeAverage: utils.NumericToFloat64(stats.LifetimeAverage),
+		RatingCount:     stats.RatingCount,
+		Distribution: map[int]int32{
+			1: stats.OneStar,
+			2: stats.TwoStar,
+			3: stats.ThreeStar,
+			4: stats.FourStar,
+			5: stats.FiveStar,
+		},
+		TotalTrips: trips,
+		UpdatedAt:  stats.UpdatedAt.Time,
+	}, nil
+}
+
+// RecordRating counts a new rating towards the rated driver. Ratings of
+// riders, and ratings already counted, are ignored.
+func (s *DriverRatingService) RecordRating(ctx context.Context, event events.RatingCreatedPayload) error {
+	ratingID, err := uuid.Parse(event.RatingID)
+	if err != nil {
+		return fmt.Errorf("invalid rating ID: %w", err)
+	}
+	driverID, err := uuid.Parse(event.RatedID)
+	if err != nil {
+		return fmt.Errorf("invalid rated ID: %w", err)
+	}
+
+	recorded, err := s.repo.RecordDriverRating(ctx, utils.ToPgUUID(ratingID))
+	if err != nil {
+		return fmt.Errorf("failed to record rating: %w", err)
+	}
+	if recorded == 0 {
+		return nil
+	}
+
+	return s.refresh(ctx, utils.ToPgUUID(driverID))
+}
+
+// RecordModeration recomputes a driver's aggregates after one of their
+// ratings was excluded or restored. Ratings of riders are ignored.
+func (s *DriverRatingService) RecordModeration(ctx context.Context, event events.RatingModeratedEvent) error {
+	if event.RaterType != domain.RaterTypeRider {
+		return nil
+	}
+	driverID, err := uuid.Parse(event.RatedID)
+	if err != nil {
+		return fmt.Errorf("invalid rated ID: %w", err)
+	}
+	return s.refresh(ctx, utils.ToPgUUID(driverID))
+}
+
+// RecordTrip updates the trip count on a driver's profile.
+func (s *DriverRatingService) RecordTrip(ctx context.Context, event events.TripCompletedEvent) error {
+	driverID, err := uuid.Parse(event.DriverID)
+	if err != nil {
+		return fmt.Errorf("invalid driver ID: %w", err)
+	}
+	return s.refresh(ctx, utils.ToPgUUID(driverID))
+}
+
+// Backfill recomputes every driver's aggregates from the ratings table,
+// picking up ratings whose events were missed or that were edited later.
+// It returns the number of drivers refreshed.
+func (s *DriverRatingService) Backfill(ctx context.Context) (int, error) {
+	synced, err := s.repo.BackfillDriverRatingEntries(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to sync ratings: %w", err)
+	}
+	log.Printf("Synced %d driver ratings", synced)
+
+	drivers, err := s.repo.ListDriverUserIDs(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list drivers: %w", err)
+	}
+
+	for i, driverID := range drivers {
+		if err := s.refresh(ctx, driverID); err != nil {
+			return i, fmt.Errorf("driver %s: %w", utils.FromPgUUID(driverID), err)
+		}
+	}
+	return len(drivers), nil
+}
+
+// refresh recomputes a driver's rating aggregates and copies the rolling
+// average and completed trip count onto their profile.
+func (s *DriverRatingService) refresh(ctx context.Context, driverID pgtype.UUID) error {
+	stats, err := s.repo.RefreshDriverRatingStats(ctx, db.RefreshDriverRatingStatsParams{
+		DriverID:   driverID,
+		WindowSize: s.window,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to refresh rating stats: %w", err)
+	}
+
+	trips, err := s.repo.CountDriverCompletedTrips(ctx, driverID)
+	if err != nil {
+		return fmt.Errorf("failed to count trips: %w", err)
+	}
+
+	if err := s.driverRepo.UpdateDriverRating(ctx, db.UpdateDriverRatingParams{
+		UserID:     driverID,
+		Rating:     stats.RollingAverage,
+		TotalTrips: pgtype.Int4{Int32: int32(trips), Valid: true},
+	}); err != nil {
+		return fmt.Errorf("failed to update driver profile: %w", err)
+	}
+	return nil
+}
+
+// SubscribeToEvents keeps aggregates current as ratings arrive, are
+// excluded or restored, and as trips complete. trip.completed uses its own
+// queue group so earnings and incentives still see every event.
+func (s *DriverRatingService) SubscribeToEvents() {
+	s.eventBus.QueueSubscribe(events.SubjectRatingCreated, "driver-service", func(ctx context.Context, data []byte) {
+		var event events.RatingCreatedPayload
+		if err := json.Unmarshal(data, &event); err != nil {
+			log.Printf("Failed to unmarshal rating created event: %v", err)
+			return
+		}
+
+		if err := s.RecordRating(ctx, event); err != nil {
+			log.Printf("Failed to record rating %s: %v", event.RatingID, err)
+			return
+		}
+	})
+
+	for _, subject := range []string{events.SubjectRatingExcluded, events.SubjectRatingRestored} {
+		s.eventBus.QueueSubscribe(subject, "driver-service", func(ctx context.Context, data []byte) {
+			var event events.RatingModeratedEvent
+			if err := json.Unmarshal(data, &event); err != nil {
+				log.Printf("Failed to unmarshal rating moderated event: %v", err)
+				return
+			}
+
+			if err := s.RecordModeration(ctx, event); err != nil {
+				log.Printf("Failed to refresh rating of driver %s: %v", event.RatedID, err)
+				return
+			}
+		})
+	}
+
+	s.eventBus.QueueSubscribe(events.SubjectTripCompleted, "driver-service-ratings", func(ctx context.Context, data []byte) {
+		var event events.TripCompletedEvent
+		if err := json.Unmarshal(data, &event); err != nil {
+			log.Printf("Failed to unmarshal trip completed event: %v", err)
+			return
+		}
+
+		if err := s.RecordTrip(ctx, event); err != nil {
+			log.Printf("Failed to update trip count for driver %s: %v", event.DriverID, err)
+			return
+		}
+	})
+}
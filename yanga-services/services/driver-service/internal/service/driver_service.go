@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgtype"
@@ -11,16 +13,20 @@ import (
 	"github.com/namycodes/yanga-services/services/driver-service/internal/repository"
 	"github.com/namycodes/yanga-services/shared-lib/domain"
 	"github.com/namycodes/yanga-services/shared-lib/events"
+	"github.com/namycodes/yanga-services/shared-lib/routing"
+	"github.com/namycodes/yanga-services/shared-lib/utils"
 )
 
 type DriverService struct {
 	repo     *repository.DriverRepository
+	router   routing.Router
 	eventBus events.EventBus
 }
 
-func NewDriverService(repo *repository.DriverRepository, eventBus events.EventBus) *DriverService {
+func NewDriverService(repo *repository.DriverRepository, router routing.Router, eventBus events.EventBus) *DriverService {
 	return &DriverService{
 		repo:     repo,
+		router:   router,
 		eventBus: eventBus,
 	}
 }
@@ -35,6 +41,7 @@ func (s *DriverService) CreateDriverProfile(ctx context.Context, req *domain.Cre
 	copy(userID.Bytes[:], userUUID[:])
 	userID.Valid = true
 
+	cityCode := strings.ToLower(strings.TrimSpace(req.CityCode))
 	profile, err := s.repo.CreateDriverProfile(ctx, db.CreateDriverProfileParams{
 		UserID:             userID,
 		LicenseNumber:      req.LicenseNumber,
@@ -42,6 +49,7 @@ func (s *DriverService) CreateDriverProfile(ctx context.Context, req *domain.Cre
 		VehicleModel:       req.VehicleModel,
 		VehicleColor:       req.VehicleColor,
 		VehiclePlateNumber: req.VehiclePlateNumber,
+		CityCode:           pgtype.Text{String: cityCode, Valid: cityCode != ""},
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create driver profile: %w", err)
@@ -56,10 +64,11 @@ func (s *DriverService) CreateDriverProfile(ctx context.Context, req *domain.Cre
 		VehicleModel:       profile.VehicleModel,
 		VehicleColor:       profile.VehicleColor,
 		VehiclePlateNumber: profile.VehiclePlateNumber,
-		IsOnline:           profile.IsOnline,
-		IsApproved:         profile.IsApproved,
-		Rating:             profile.Rating,
-		TotalTrips:         profile.TotalTrips,
+		IsOnline:           profile.IsOnline.Bool,
+		IsApproved:         profile.IsApproved.Bool,
+		CityCode:           profile.CityCode.String,
+		Rating:             utils.NumericToFloat64(profile.Rating),
+		TotalTrips:         profile.TotalTrips.Int32,
 	}, nil
 }
 
@@ -87,12 +96,13 @@ func (s *DriverService) GetDriverProfile(ctx context.Context, userID string) (*d
 		VehicleModel:       profile.VehicleModel,
 		VehicleColor:       profile.VehicleColor,
 		VehiclePlateNumber: profile.VehiclePlateNumber,
-		IsOnline:           profile.IsOnline,
-		IsApproved:         profile.IsApproved,
-		Rating:             profile.Rating,
-		TotalTrips:         profile.TotalTrips,
-		CurrentLatitude:    profile.CurrentLatitude.Float64,
-		CurrentLongitude:   profile.CurrentLongitude.Float64,
+		IsOnline:           profile.IsOnline.Bool,
+		IsApproved:         profile.IsApproved.Bool,
+		CityCode:           profile.CityCode.String,
+		Rating:             utils.NumericToFloat64(profile.Rating),
+		TotalTrips:         profile.TotalTrips.Int32,
+		CurrentLatitude:    utils.NumericToFloat64(profile.CurrentLatitude),
+		CurrentLongitude:   utils.NumericToFloat64(profile.CurrentLongitude),
 	}, nil
 }
 
@@ -106,9 +116,21 @@ func (s *DriverService) UpdateDriverStatus(ctx context.Context, userID string, i
 	copy(userPgUUID.Bytes[:], userUUID[:])
 	userPgUUID.Valid = true
 
+	// Drivers suspended for low quality can't go online until the
+	// suspension ends.
+	if isOnline {
+		status, err := s.repo.GetDriverDispatchStatus(ctx, userPgUUID)
+		if err != nil {
+			return fmt.Errorf("failed to get driver status: %w", err)
+		}
+		if status.DispatchSuspendedUntil.Valid && status.DispatchSuspendedUntil.Time.After(time.Now().UTC()) {
+			return fmt.Errorf("%w until %s", ErrDispatchSuspended, status.DispatchSuspendedUntil.Time.Format(time.RFC3339))
+		}
+	}
+
 	err = s.repo.UpdateDriverStatus(ctx, db.UpdateDriverStatusParams{
 		UserID:   userPgUUID,
-		IsOnline: isOnline,
+		IsOnline: pgtype.Bool{Bool: isOnline, Valid: true},
 	})
 	if err != nil {
 		return fmt.Errorf("failed to update driver status: %w", err)
@@ -119,7 +141,7 @@ func (s *DriverService) UpdateDriverStatus(ctx context.Context, userID string, i
 	if isOnline {
 		subject = events.SubjectDriverOnline
 	}
-	s.eventBus.Publish(subject, events.DriverStatusPayload{
+	s.eventBus.Publish(ctx, subject, events.DriverStatusPayload{
 		DriverID: userID,
 		IsOnline: isOnline,
 	})
@@ -139,15 +161,15 @@ func (s *DriverService) UpdateDriverLocation(ctx context.Context, userID string,
 
 	err = s.repo.UpdateDriverLocation(ctx, db.UpdateDriverLocationParams{
 		UserID:           userPgUUID,
-		CurrentLatitude:  pgtype.Float8{Float64: lat, Valid: true},
-		CurrentLongitude: pgtype.Float8{Float64: lng, Valid: true},
+		CurrentLatitude:  utils.Float64ToNumeric(lat),
+		CurrentLongitude: utils.Float64ToNumeric(lng),
 	})
 	if err != nil {
 		return fmt.Errorf("failed to update driver location: %w", err)
 	}
 
 	// Publish location update event
-	s.eventBus.Publish(events.SubjectDriverLocation, events.DriverLocationPayload{
+	s.eventBus.Publish(ctx, events.SubjectDriverLocation, events.DriverLocationPayload{
 		DriverID:  userID,
 		Latitude:  lat,
 		Longitude: lng,
@@ -158,33 +180,42 @@ func (s *DriverService) UpdateDriverLocation(ctx context.Context, userID string,
 
 func (s *DriverService) GetNearbyDrivers(ctx context.Context, lat, lng, radiusKm float64, limit int32) ([]domain.NearbyDriverResponse, error) {
 	drivers, err := s.repo.GetNearbyDrivers(ctx, db.GetNearbyDriversParams{
-		Column1: lat,
-		Column2: lng,
-		Column3: radiusKm,
-		Column4: limit,
+		Radians:         lat,
+		Radians_2:       lng,
+		CurrentLatitude: utils.Float64ToNumeric(radiusKm),
+		Limit:           limit,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get nearby drivers: %w", err)
 	}
 
+	pickup := routing.Point{Lat: lat, Lng: lng}
 	var response []domain.NearbyDriverResponse
 	for _, driver := range drivers {
 		driverID, _ := uuid.FromBytes(driver.ID.Bytes[:])
 		userID, _ := uuid.FromBytes(driver.UserID.Bytes[:])
 
+		// Time to drive to the pickup by road, not as the crow flies.
+		driverLat := utils.NumericToFloat64(driver.CurrentLatitude)
+		driverLng := utils.NumericToFloat64(driver.CurrentLongitude)
+		route, err := s.router.Route(ctx, routing.Point{Lat: driverLat, Lng: driverLng}, pickup)
+		if err != nil {
+			return nil, fmt.Errorf("failed to estimate driver arrival: %w", err)
+		}
+
 		response = append(response, domain.NearbyDriverResponse{
 			DriverID:           driverID.String(),
 			UserID:             userID.String(),
 			FullName:           driver.FullName,
-			PhoneNumber:        driver.PhoneNumber,
 			VehicleType:        driver.VehicleType,
 			VehicleModel:       driver.VehicleModel,
 			VehicleColor:       driver.VehicleColor,
 			VehiclePlateNumber: driver.VehiclePlateNumber,
-			Rating:             driver.Rating,
-			CurrentLatitude:    driver.CurrentLatitude.Float64,
-			CurrentLongitude:   driver.CurrentLongitude.Float64,
-			Distance:           driver.Distance.(float64),
+			Rating:             utils.NumericToFloat64(driver.Rating),
+			CurrentLatitude:    driverLat,
+			CurrentLongitude:   driverLng,
+			Distance:           driver.Distance,
+			EtaMinutes:         route.Minutes(),
 		})
 	}
 
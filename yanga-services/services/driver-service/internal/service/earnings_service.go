@@ -0,0 +1,725 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/namycodes/yanga-services/services/driver-service/internal/db"
+	"github.com/namycodes/yanga-services/services/driver-service/internal/repository"
+	"github.com/namycodes/yanga-services/shared-lib/config"
+	"github.com/namycodes/yanga-services/shared-lib/domain"
+	"github.com/namycodes/yanga-services/shared-lib/events"
+	"github.com/namycodes/yanga-services/shared-lib/money"
+	"github.com/namycodes/yanga-services/shared-lib/utils"
+)
+
+// Fallback split used when no commission plan is configured: 20% platform
+// commission with 16% VAT charged on the commission.
+const (
+	defaultCommissionRate money.Rate = 2000
+	defaultTaxRate        money.Rate = 1600
+)
+
+var (
+	ErrInvalidRate             = errors.New("rates must be between 0 and 1")
+	ErrInvalidPeriod           = errors.New("invalid period")
+	ErrUnknownCity             = errors.New("city does not exist")
+	ErrNoPayableEarnings       = errors.New("no payable earnings for period")
+	ErrInvalidPayoutTransition = errors.New("invalid payout status transition")
+)
+
+// payoutTransitions lists the statuses a payout batch may move to from each
+// non-terminal status.
+var payoutTransitions = map[string][]string{
+	domain.PayoutStatusPending:    {domain.PayoutStatusProcessing, domain.PayoutStatusFailed},
+	domain.PayoutStatusProcessing: {domain.PayoutStatusPaid, domain.PayoutStatusFailed},
+}
+
+type EarningsService struct {
+	repo       *repository.EarningsRepository
+	driverRepo *repository.DriverRepository
+	eventBus   events.EventBus
+	// currency statements and payouts are read in; a driver only earns in
+	// their home city's currency.
+	currency money.Currency
+}
+
+func NewEarningsService(repo *repository.EarningsRepository, driverRepo *repository.DriverRepository, eventBus events.EventBus, cfg *config.Config) *EarningsService {
+	return &EarningsService{
+		repo:       repo,
+		driverRepo: driverRepo,
+		eventBus:   eventBus,
+		currency:   money.Currency(cfg.DefaultCurrency),
+	}
+}
+
+// fareSplit is the breakdown of a completed trip's fare.
+type fareSplit struct {
+	commission money.Money
+	tax        money.Money
+	net        money.Money
+}
+
+// splitFare takes the platform commission off the fare, charges tax on that
+// commission to the driver and passes the tip through untouched.
+func splitFare(fare, tip money.Money, commissionRate, taxRate money.Rate) fareSplit {
+	commission := fare.Apply(commissionRate, money.HalfUp)
+	tax := commission.Apply(taxRate, money.HalfUp)
+	return fareSplit{
+		commission: commission,
+		tax:        tax,
+		net:        fare.Sub(commission).Sub(tax).Add(tip),
+	}
+}
+
+// RecordTripEarnings splits a completed trip's fare according to the
+// commission plan in force when the trip ended. Redelivered events are
+// ignored because earnings are unique per trip.
+func (s *EarningsService) RecordTripEarnings(ctx context.Context, event events.TripCompletedEvent) error {
+	tripID, err := uuid.Parse(event.TripID)
+	if err != nil {
+		return fmt.Errorf("invalid trip ID: %w", err)
+	}
+	driverID, err := uuid.Parse(event.DriverID)
+	if err != nil {
+		return fmt.Errorf("invalid driver ID: %w", err)
+	}
+
+	currency := s.currency
+	if event.Currency != "" {
+		if currency, err = money.ParseCurrency(event.Currency); err != nil {
+			return err
+		}
+	}
+
+	fare := money.FromFloat(event.ActualFare, currency)
+	tip := money.FromFloat(event.Tip, currency)
+	// Promo discounts are funded by the platform: the driver's split is on
+	// the full fare, and only cash collection is reduced by the discount.
+	discount := money.FromFloat(event.Discount, currency)
+	if fare.Sign() < 0 || tip.Sign() < 0 || discount.Sign() < 0 {
+		return errors.New("fare, tip and discount cannot be negative")
+	}
+
+	earnedAt := event.CompletedAt
+	if earnedAt.IsZero() {
+		earnedAt = time.Now()
+	}
+	earnedAt = earnedAt.UTC()
+
+	var vehicleType pgtype.Text
+	if profile, err := s.driverRepo.GetDriverProfileByUserID(ctx, utils.ToPgUUID(driverID)); err == nil {
+		vehicleType = pgtype.Text{String: profile.VehicleType, Valid: true}
+	}
+
+	planID := pgtype.UUID{}
+	commissionRate := defaultCommissionRate
+	taxRate := defaultTaxRate
+
+	plan, err := s.repo.GetApplicableCommissionPlan(ctx, db.GetApplicableCommissionPlanParams{
+		CityCode:    pgtype.Text{String: event.CityCode, Valid: event.CityCode != ""},
+		VehicleType: vehicleType,
+		At:          pgtype.Timestamp{Time: earnedAt, Valid: true},
+	})
+	switch {
+	case err == nil:
+		planID = plan.ID
+		commissionRate = money.RateFromNumeric(plan.CommissionRate)
+		taxRate = money.RateFromNumeric(plan.TaxRate)
+	case errors.Is(err, pgx.ErrNoRows):
+		log.Printf("No commission plan applies to trip %s, using default split", event.TripID)
+	default:
+		return fmt.Errorf("failed to load commission plan: %w", err)
+	}
+
+	split := splitFare(fare, tip, commissionRate, taxRate)
+
+	_, err = s.repo.CreateDriverEarning(ctx, db.CreateDriverEarningParams{
+		TripID:           utils.ToPgUUID(tripID),
+		DriverID:         utils.ToPgUUID(driverID),
+		CommissionPlanID: planID,
+		GrossFare:        fare.Numeric(),
+		CommissionRate:   commissionRate.Numeric(),
+		Commission:       split.commission.Numeric(),
+		Tax:              split.tax.Numeric(),
+		Tip:              tip.Numeric(),
+		NetEarnings:      split.net.Numeric(),
+		PaymentMethod:    pgtype.Text{String: event.PaymentMethod, Valid: event.PaymentMethod != ""},
+		RiderDiscount:    discount.Numeric(),
+		Currency:         currency.String(),
+		EarnedAt:         pgtype.Timestamp{Time: earnedAt, Valid: true},
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			// ON CONFLICT DO NOTHING: earnings for this trip already exist
+			return nil
+		}
+		return fmt.Errorf("failed to record earnings: %w", err)
+	}
+
+	s.eventBus.Publish(ctx, events.SubjectEarningsRecorded, events.EarningsRecordedEvent{
+		TripID:      event.TripID,
+		DriverID:    event.DriverID,
+		GrossFare:   fare.Float64(),
+		Commission:  split.commission.Float64(),
+		Tax:         split.tax.Float64(),
+		Tip:         tip.Float64(),
+		NetEarnings: split.net.Float64(),
+		Currency:    currency.String(),
+		Timestamp:   time.Now(),
+	})
+
+	return nil
+}
+
+// GetEarnings returns a driver's totals plus daily and weekly statements for
+// earnings made in [from, to).
+func (s *EarningsService) GetEarnings(ctx context.Context, driverID uuid.UUID, from, to time.Time, limit, offset int32) (*domain.DriverEarningsResponse, error) {
+	if !from.Before(to) {
+		return nil, ErrInvalidPeriod
+	}
+
+	pgDriverID := utils.ToPgUUID(driverID)
+	periodStart := pgtype.Timestamp{Time: from.UTC(), Valid: true}
+	periodEnd := pgtype.Timestamp{Time: to.UTC(), Valid: true}
+
+	summary, err := s.repo.GetDriverEarningsSummary(ctx, db.GetDriverEarningsSummaryParams{
+		DriverID:    pgDriverID,
+		PeriodStart: periodStart,
+		PeriodEnd:   periodEnd,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get earnings summary: %w", err)
+	}
+
+	daily, err := s.repo.GetDriverDailyEarnings(ctx, db.GetDriverDailyEarningsParams{
+		DriverID:    pgDriverID,
+		PeriodStart: periodStart,
+		PeriodEnd:   periodEnd,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get daily earnings: %w", err)
+	}
+
+	weekly, err := s.repo.GetDriverWeeklyEarnings(ctx, db.GetDriverWeeklyEarningsParams{
+		DriverID:    pgDriverID,
+		PeriodStart: periodStart,
+		PeriodEnd:   periodEnd,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get weekly earnings: %w", err)
+	}
+
+	trips, err := s.repo.GetDriverEarnings(ctx, db.GetDriverEarningsParams{
+		DriverID:    pgDriverID,
+		PeriodStart: periodStart,
+		PeriodEnd:   periodEnd,
+		Limit:       limit,
+		Offset:      offset,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trip earnings: %w", err)
+	}
+
+	response := &domain.DriverEarningsResponse{
+		From: from,
+		To:   to,
+		Totals: domain.EarningsTotals{
+			TripCount:   summary.TripCount,
+			GrossFare:   money.FromNumeric(summary.GrossFare, s.currency).Float64(),
+			Commission:  money.FromNumeric(summary.Commission, s.currency).Float64(),
+			Tax:         money.FromNumeric(summary.Tax, s.currency).Float64(),
+			Tips:        money.FromNumeric(summary.Tip, s.currency).Float64(),
+			NetEarnings: money.FromNumeric(summary.NetEarnings, s.currency).Float64(),
+			Bonuses:     money.FromNumeric(summary.Bonuses, s.currency).Float64(),
+		},
+		Daily:  make([]domain.EarningsStatement, 0, len(daily)),
+		Weekly: make([]domain.EarningsStatement, 0, len(weekly)),
+		Trips:  make([]domain.TripEarningResponse, 0, len(trips)),
+	}
+
+	for _, row := range daily {
+		response.Daily = append(response.Daily, domain.EarningsStatement{
+			PeriodStart: row.PeriodStart.Time,
+			PeriodEnd:   row.PeriodStart.Time.AddDate(0, 0, 1),
+			EarningsTotals: domain.EarningsTotals{
+				TripCount:   row.TripCount,
+				GrossFare:   money.FromNumeric(row.GrossFare, s.currency).Float64(),
+				Commission:  money.FromNumeric(row.Commission, s.currency).Float64(),
+				Tax:         money.FromNumeric(row.Tax, s.currency).Float64(),
+				Tips:        money.FromNumeric(row.Tip, s.currency).Float64(),
+				NetEarnings: money.FromNumeric(row.NetEarnings, s.currency).Float64(),
+				Bonuses:     money.FromNumeric(row.Bonuses, s.currency).Float64(),
+			},
+		})
+	}
+
+	for _, row := range weekly {
+		response.Weekly = append(response.Weekly, domain.EarningsStatement{
+			PeriodStart: row.PeriodStart.Time,
+			PeriodEnd:   row.PeriodStart.Time.AddDate(0, 0, 7),
+			EarningsTotals: domain.EarningsTotals{
+				TripCount:   row.TripCount,
+				GrossFare:   money.FromNumeric(row.GrossFare, s.currency).Float64(),
+				Commission:  money.FromNumeric(row.Commission, s.currency).Float64(),
+				Tax:         money.FromNumeric(row.Tax, s.currency).Float64(),
+				Tips:        money.FromNumeric(row.Tip, s.currency).Float64(),
+				NetEarnings: money.FromNumeric(row.NetEarnings, s.currency).Float64(),
+				Bonuses:     money.FromNumeric(row.Bonuses, s.currency).Float64(),
+			},
+		})
+	}
+
+	for _, earning := range trips {
+		response.Trips = append(response.Trips, toTripEarningResponse(earning))
+	}
+
+	return response, nil
+}
+
+func (s *EarningsService) CreateCommissionPlan(ctx context.Context, adminID uuid.UUID, req *domain.CreateCommissionPlanRequest) (*domain.CommissionPlanResponse, error) {
+	if req.Name == "" {
+		return nil, errors.New("plan name is required")
+	}
+	if req.CommissionRate < 0 || req.CommissionRate > 1 || req.TaxRate < 0 || req.TaxRate > 1 {
+		return nil, ErrInvalidRate
+	}
+
+	effectiveFrom := time.Now().UTC()
+	if req.EffectiveFrom != nil {
+		effectiveFrom = req.EffectiveFrom.UTC()
+	}
+	effectiveTo := pgtype.Timestamp{}
+	if req.EffectiveTo != nil {
+		if !req.EffectiveTo.After(effectiveFrom) {
+			return nil, ErrInvalidPeriod
+		}
+		effectiveTo = pgtype.Timestamp{Time: req.EffectiveTo.UTC(), Valid: true}
+	}
+	cityCode := strings.ToLower(strings.TrimSpace(req.CityCode))
+
+	plan, err := s.repo.CreateCommissionPlan(ctx, db.CreateCommissionPlanParams{
+		Name:           req.Name,
+		VehicleType:    pgtype.Text{String: req.VehicleType, Valid: req.VehicleType != ""},
+		CityCode:       pgtype.Text{String: cityCode, Valid: cityCode != ""},
+		CommissionRate: money.RateFromFloat(req.CommissionRate).Numeric(),
+		TaxRate:        money.RateFromFloat(req.TaxRate).Numeric(),
+		EffectiveFrom:  pgtype.Timestamp{Time: effectiveFrom, Valid: true},
+		EffectiveTo:    effectiveTo,
+		CreatedBy:      utils.ToPgUUID(adminID),
+	})
+	if err != nil {
+		if repository.IsForeignKeyViolation(err) {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownCity, cityCode)
+		}
+		return nil, fmt.Errorf("failed to create commission plan: %w", err)
+	}
+
+	return toCommissionPlanResponse(plan), nil
+}
+
+func (s *EarningsService) ListCommissionPlans(ctx context.Context) ([]domain.CommissionPlanResponse, error) {
+	plans, err := s.repo.ListCommissionPlans(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list commission plans: %w", err)
+	}
+
+	response := make([]domain.CommissionPlanResponse, 0, len(plans))
+	for _, plan := range plans {
+		response = append(response, *toCommissionPlanResponse(plan))
+	}
+	return response, nil
+}
+
+func (s *EarningsService) SetCommissionPlanActive(ctx context.Context, planID uuid.UUID, isActive bool) (*domain.CommissionPlanResponse, error) {
+	plan, err := s.repo.UpdateCommissionPlanStatus(ctx, db.UpdateCommissionPlanStatusParams{
+		ID:       utils.ToPgUUID(planID),
+		IsActive: isActive,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("commission plan not found")
+		}
+		return nil, fmt.Errorf("failed to update commission plan: %w", err)
+	}
+
+	return toCommissionPlanResponse(plan), nil
+}
+
+// CreatePayoutBatch groups every unpaid earning before periodEnd into one
+// payout item per driver. Cash fares the driver already collected are netted
+// off, and drivers who end up owing the platform are carried forward.
+func (s *EarningsService) CreatePayoutBatch(ctx context.Context, adminID uuid.UUID, periodEnd time.Time) (*domain.PayoutBatchResponse, error) {
+	if periodEnd.IsZero() || periodEnd.After(time.Now()) {
+		return nil, ErrInvalidPeriod
+	}
+	end := pgtype.Timestamp{Time: periodEnd.UTC(), Valid: true}
+
+	var batch db.PayoutBatch
+	var items []db.PayoutItem
+
+	err := s.repo.WithTx(ctx, func(q *db.Queries) error {
+		items = nil
+
+		var err error
+		batch, err = q.CreatePayoutBatch(ctx, db.CreatePayoutBatchParams{
+			PeriodEnd: end,
+			CreatedBy: utils.ToPgUUID(adminID),
+		})
+		if err != nil {
+			return err
+		}
+
+		drivers, err := q.GetPayableDrivers(ctx, end)
+		if err != nil {
+			return err
+		}
+
+		for _, driverID := range drivers {
+			item, err := q.CreatePayoutItem(ctx, db.CreatePayoutItemParams{
+				BatchID:  batch.ID,
+				DriverID: driverID,
+			})
+			if err != nil {
+				return err
+			}
+
+			// Earnings claimed by a concurrent batch are skipped by the
+			// payout_item_id IS NULL guard, so totals are recomputed from
+			// what this item actually claimed.
+			if _, err := q.AssignEarningsToPayoutItem(ctx, db.AssignEarningsToPayoutItemParams{
+				PayoutItemID: item.ID,
+				DriverID:     driverID,
+				PeriodEnd:    end,
+			}); err != nil {
+				return err
+			}
+
+			item, err = q.RefreshPayoutItemTotals(ctx, item.ID)
+			if err != nil {
+				return err
+			}
+
+			if money.FromNumeric(item.Amount, s.currency).Sign() <= 0 {
+				if err := q.ReleasePayoutItemEarnings(ctx, item.ID); err != nil {
+					return err
+				}
+				if err := q.DeletePayoutItem(ctx, item.ID); err != nil {
+					return err
+				}
+				continue
+			}
+			items = append(items, item)
+		}
+
+		if len(items) == 0 {
+			return ErrNoPayableEarnings
+		}
+
+		batch, err = q.RefreshPayoutBatchTotals(ctx, batch.ID)
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, ErrNoPayableEarnings) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to create payout batch: %w", err)
+	}
+
+	return toPayoutBatchResponse(batch, items), nil
+}
+
+func (s *EarningsService) GetPayoutBatch(ctx context.Context, batchID uuid.UUID) (*domain.PayoutBatchResponse, error) {
+	batch, err := s.repo.GetPayoutBatch(ctx, utils.ToPgUUID(batchID))
+	if err != nil {
+		return nil, errors.New("payout batch not found")
+	}
+
+	items, err := s.repo.GetPayoutItemsByBatch(ctx, batch.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get payout items: %w", err)
+	}
+
+	return toPayoutBatchResponse(batch, items), nil
+}
+
+func (s *EarningsService) ListPayoutBatches(ctx context.Context, limit, offset int32) ([]domain.PayoutBatchResponse, error) {
+	batches, err := s.repo.ListPayoutBatches(ctx, db.ListPayoutBatchesParams{
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list payout batches: %w", err)
+	}
+
+	response := make([]domain.PayoutBatchResponse, 0, len(batches))
+	for _, batch := range batches {
+		response = append(response, *toPayoutBatchResponse(batch, nil))
+	}
+	return response, nil
+}
+
+func (s *EarningsService) GetDriverPayouts(ctx context.Context, driverID uuid.UUID, limit, offset int32) ([]domain.PayoutItemResponse, error) {
+	items, err := s.repo.GetDriverPayoutItems(ctx, db.GetDriverPayoutItemsParams{
+		DriverID: utils.ToPgUUID(driverID),
+		Limit:    limit,
+		Offset:   offset,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get payouts: %w", err)
+	}
+
+	response := make([]domain.PayoutItemResponse, 0, len(items))
+	for _, item := range items {
+		response = append(response, toPayoutItemResponse(item))
+	}
+	return response, nil
+}
+
+// UpdatePayoutBatchStatus moves a batch through pending → processing →
+// paid/failed and applies the same status to its unsettled items. Items of a
+// failed batch release their earnings so the next batch picks them up.
+func (s *EarningsService) UpdatePayoutBatchStatus(ctx context.Context, batchID uuid.UUID, req *domain.UpdatePayoutStatusRequest) (*domain.PayoutBatchResponse, error) {
+	var batch db.PayoutBatch
+	var items []db.PayoutItem
+
+	err := s.repo.WithTx(ctx, func(q *db.Queries) error {
+		id := utils.ToPgUUID(batchID)
+		if err := q.LockPayoutBatch(ctx, id); err != nil {
+			return err
+		}
+
+		current, err := q.GetPayoutBatch(ctx, id)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return errors.New("payout batch not found")
+			}
+			return err
+		}
+		if !canTransition(current.Status, req.Status) {
+			return ErrInvalidPayoutTransition
+		}
+
+		openItems, err := q.GetOpenPayoutItemsByBatch(ctx, id)
+		if err != nil {
+			return err
+		}
+		for _, item := range openItems {
+			if err := settlePayoutItem(ctx, q, item.ID, req.Status, "", req.FailureReason); err != nil {
+				return err
+			}
+		}
+
+		batch, err = q.UpdatePayoutBatchStatus(ctx, db.UpdatePayoutBatchStatusParams{
+			ID:            id,
+			Status:        req.Status,
+			FailureReason: pgtype.Text{String: req.FailureReason, Valid: req.FailureReason != ""},
+		})
+		if err != nil {
+			return err
+		}
+
+		items, err = q.GetPayoutItemsByBatch(ctx, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return toPayoutBatchResponse(batch, items), nil
+}
+
+// UpdatePayoutItemStatus settles a single driver's payout within a
+// processing batch. Once no items are left open the batch is closed as paid,
+// or as failed when every item failed.
+func (s *EarningsService) UpdatePayoutItemStatus(ctx context.Context, batchID, itemID uuid.UUID, req *domain.UpdatePayoutStatusRequest) (*domain.PayoutItemResponse, error) {
+	if req.Status != domain.PayoutStatusPaid && req.Status != domain.PayoutStatusFailed {
+		return nil, ErrInvalidPayoutTransition
+	}
+
+	var item db.PayoutItem
+
+	err := s.repo.WithTx(ctx, func(q *db.Queries) error {
+		id := utils.ToPgUUID(batchID)
+		if err := q.LockPayoutBatch(ctx, id); err != nil {
+			return err
+		}
+
+		batch, err := q.GetPayoutBatch(ctx, id)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return errors.New("payout batch not found")
+			}
+			return err
+		}
+
+		current, err := q.GetPayoutItem(ctx, utils.ToPgUUID(itemID))
+		if err != nil || current.BatchID != batch.ID {
+			return errors.New("payout item not found")
+		}
+		if batch.Status != domain.PayoutStatusProcessing ||
+			current.Status == domain.PayoutStatusPaid || current.Status == domain.PayoutStatusFailed {
+			return ErrInvalidPayoutTransition
+		}
+
+		if err := settlePayoutItem(ctx, q, current.ID, req.Status, req.PaymentReference, req.FailureReason); err != nil {
+			return err
+		}
+
+		item, err = q.GetPayoutItem(ctx, current.ID)
+		if err != nil {
+			return err
+		}
+
+		remaining, err := q.GetOpenPayoutItemsByBatch(ctx, batch.ID)
+		if err != nil || len(remaining) > 0 {
+			return err
+		}
+
+		items, err := q.GetPayoutItemsByBatch(ctx, batch.ID)
+		if err != nil {
+			return err
+		}
+		status := domain.PayoutStatusFailed
+		for _, i := range items {
+			if i.Status == domain.PayoutStatusPaid {
+				status = domain.PayoutStatusPaid
+				break
+			}
+		}
+
+		_, err = q.UpdatePayoutBatchStatus(ctx, db.UpdatePayoutBatchStatusParams{
+			ID:     batch.ID,
+			Status: status,
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := toPayoutItemResponse(item)
+	return &response, nil
+}
+
+// SubscribeToEvents records earnings for every completed trip. A queue group
+// spreads events across driver-service instances.
+func (s *EarningsService) SubscribeToEvents() {
+	s.eventBus.QueueSubscribe(events.SubjectTripCompleted, "driver-service", func(ctx context.Context, data []byte) {
+		var event events.TripCompletedEvent
+		if err := json.Unmarshal(data, &event); err != nil {
+			log.Printf("Failed to unmarshal trip completed event: %v", err)
+			return
+		}
+
+		if err := s.RecordTripEarnings(ctx, event); err != nil {
+			log.Printf("Failed to record earnings for trip %s: %v", event.TripID, err)
+			return
+		}
+	})
+}
+
+func settlePayoutItem(ctx context.Context, q *db.Queries, itemID pgtype.UUID, status, reference, reason string) error {
+	params := db.UpdatePayoutItemStatusParams{
+		ID:               itemID,
+		Status:           status,
+		PaymentReference: pgtype.Text{String: reference, Valid: reference != ""},
+	}
+	if status == domain.PayoutStatusFailed {
+		params.FailureReason = pgtype.Text{String: reason, Valid: reason != ""}
+	}
+
+	if _, err := q.UpdatePayoutItemStatus(ctx, params); err != nil {
+		return err
+	}
+
+	if status == domain.PayoutStatusFailed {
+		return q.ReleasePayoutItemEarnings(ctx, itemID)
+	}
+	return nil
+}
+
+func canTransition(from, to string) bool {
+	for _, next := range payoutTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+func toTripEarningResponse(earning db.DriverEarning) domain.TripEarningResponse {
+	currency := money.Currency(earning.Currency)
+	return domain.TripEarningResponse{
+		TripID:         utils.FromPgUUID(earning.TripID).String(),
+		GrossFare:      money.FromNumeric(earning.GrossFare, currency).Float64(),
+		CommissionRate: money.RateFromNumeric(earning.CommissionRate).Float64(),
+		Commission:     money.FromNumeric(earning.Commission, currency).Float64(),
+		Tax:            money.FromNumeric(earning.Tax, currency).Float64(),
+		Tip:            money.FromNumeric(earning.Tip, currency).Float64(),
+		NetEarnings:    money.FromNumeric(earning.NetEarnings, currency).Float64(),
+		Currency:       earning.Currency,
+		PaymentMethod:  earning.PaymentMethod.String,
+		PaidOut:        earning.PayoutItemID.Valid,
+		EarnedAt:       earning.EarnedAt.Time,
+	}
+}
+
+func toCommissionPlanResponse(plan db.CommissionPlan) *domain.CommissionPlanResponse {
+	response := &domain.CommissionPlanResponse{
+		ID:             utils.FromPgUUID(plan.ID).String(),
+		Name:           plan.Name,
+		VehicleType:    plan.VehicleType.String,
+		CityCode:       plan.CityCode.String,
+		CommissionRate: money.RateFromNumeric(plan.CommissionRate).Float64(),
+		TaxRate:        money.RateFromNumeric(plan.TaxRate).Float64(),
+		IsActive:       plan.IsActive,
+		EffectiveFrom:  plan.EffectiveFrom.Time,
+		CreatedAt:      plan.CreatedAt.Time,
+	}
+	if plan.EffectiveTo.Valid {
+		response.EffectiveTo = &plan.EffectiveTo.Time
+	}
+	return response
+}
+
+func toPayoutBatchResponse(batch db.PayoutBatch, items []db.PayoutItem) *domain.PayoutBatchResponse {
+	response := &domain.PayoutBatchResponse{
+		ID:            utils.FromPgUUID(batch.ID).String(),
+		Status:        batch.Status,
+		PeriodEnd:     batch.PeriodEnd.Time,
+		TotalAmount:   utils.NumericToFloat64(batch.TotalAmount),
+		DriverCount:   batch.DriverCount,
+		FailureReason: batch.FailureReason.String,
+		CreatedAt:     batch.CreatedAt.Time,
+	}
+	if batch.ProcessedAt.Valid {
+		response.ProcessedAt = &batch.ProcessedAt.Time
+	}
+	for _, item := range items {
+		response.Items = append(response.Items, toPayoutItemResponse(item))
+	}
+	return response
+}
+
+func toPayoutItemResponse(item db.PayoutItem) domain.PayoutItemResponse {
+	return domain.PayoutItemResponse{
+		ID:               utils.FromPgUUID(item.ID).String(),
+		BatchID:          utils.FromPgUUID(item.BatchID).String(),
+		DriverID:         utils.FromPgUUID(item.DriverID).String(),
+		Amount:           utils.NumericToFloat64(item.Amount),
+		EarningsCount:    item.EarningsCount,
+		Status:           item.Status,
+		PaymentReference: item.PaymentReference.String,
+		FailureReason:    item.FailureReason.String,
+		UpdatedAt:        item.UpdatedAt.Time,
+	}
+}
@@ -0,0 +1,554 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/namycodes/yanga-services/services/driver-service/internal/db"
+	"github.com/namycodes/yanga-services/services/driver-service/internal/repository"
+	"github.com/namycodes/yanga-services/shared-lib/config"
+	"github.com/namycodes/yanga-services/shared-lib/domain"
+	"github.com/namycodes/yanga-services/shared-lib/events"
+	"github.com/namycodes/yanga-services/shared-lib/geofence"
+	"github.com/namycodes/yanga-services/shared-lib/money"
+	"github.com/namycodes/yanga-services/shared-lib/routing"
+	"github.com/namycodes/yanga-services/shared-lib/utils"
+)
+
+const (
+	// Peak guarantees are settled this long after they end, so that
+	// trip.completed events for trips that finished just inside the window
+	// have been counted.
+	incentiveSettleDelay    = 15 * time.Minute
+	incentiveSettleInterval = time.Minute
+	incentiveSettleBatch    = 20
+
+	// Drivers see programs that ended in the last week, with their result,
+	// and those starting in the next week.
+	incentiveLookback  = 7 * 24 * time.Hour
+	incentiveLookahead = 7 * 24 * time.Hour
+)
+
+var (
+	ErrInvalidIncentive  = errors.New("invalid incentive program")
+	ErrIncentiveNotFound = errors.New("incentive program not found")
+)
+
+type IncentiveService struct {
+	repo       *repository.IncentiveRepository
+	driverRepo *repository.DriverRepository
+	eventBus   events.EventBus
+	currency   money.Currency
+}
+
+func NewIncentiveService(repo *repository.IncentiveRepository, driverRepo *repository.DriverRepository, eventBus events.EventBus, cfg *config.Config) *IncentiveService {
+	return &IncentiveService{
+		repo:       repo,
+		driverRepo: driverRepo,
+		eventBus:   eventBus,
+		currency:   money.Currency(cfg.DefaultCurrency),
+	}
+}
+
+// incentiveCredit is a bonus credited to a driver's earnings, published once
+// the transaction that credited it has committed.
+type incentiveCredit struct {
+	program  db.IncentiveProgram
+	driverID pgtype.UUID
+	tripID   string
+	amount   money.Money
+}
+
+// CreateProgram defines a quest, peak guarantee or area boost. Amounts are
+// in the currency of the program's city, or the default currency for
+// programs that run everywhere.
+func (s *IncentiveService) CreateProgram(ctx context.Context, adminID uuid.UUID, req *domain.CreateIncentiveProgramRequest) (*domain.IncentiveProgramResponse, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" || len(name) > 100 {
+		return nil, fmt.Errorf("%w: name is required and at most 100 characters", ErrInvalidIncentive)
+	}
+	if !req.EndsAt.After(req.StartsAt) {
+		return nil, fmt.Errorf("%w: ends_at must be after starts_at", ErrInvalidIncentive)
+	}
+
+	cityCode := strings.ToLower(strings.TrimSpace(req.CityCode))
+	currency := s.currency
+	if cityCode != "" {
+		code, err := s.repo.GetCityCurrency(ctx, cityCode)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownCity, cityCode)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get city: %w", err)
+		}
+		currency = money.Currency(code)
+	}
+
+	params := db.CreateIncentiveProgramParams{
+		Name:         name,
+		Description:  pgtype.Text{String: req.Description, Valid: req.Description != ""},
+		Kind:         req.Kind,
+		CityCode:     pgtype.Text{String: cityCode, Valid: cityCode != ""},
+		VehicleType:  pgtype.Text{String: req.VehicleType, Valid: req.VehicleType != ""},
+		Currency:     currency.String(),
+		RewardAmount: money.Zero(currency).Numeric(),
+		BoostRate:    money.Rate(0).Numeric(),
+		StartsAt:     pgtype.Timestamp{Time: req.StartsAt.UTC(), Valid: true},
+		EndsAt:       pgtype.Timestamp{Time: req.EndsAt.UTC(), Valid: true},
+		CreatedBy:    utils.ToPgUUID(adminID),
+	}
+
+	switch req.Kind {
+	case domain.IncentiveKindQuest, domain.IncentiveKindPeakGuarantee:
+		reward := money.FromFloat(req.RewardAmount, currency)
+		if req.TargetTrips <= 0 || reward.Sign() <= 0 {
+			return nil, fmt.Errorf("%w: %s needs target_trips and reward_amount", ErrInvalidIncentive, req.Kind)
+		}
+		params.TargetTrips = req.TargetTrips
+		params.RewardAmount = reward.Numeric()
+	case domain.IncentiveKindAreaBoost:
+		geofenceID, err := uuid.Parse(req.GeofenceID)
+		if err != nil {
+			return nil, fmt.Errorf("%w: area_boost needs a geofence_id", ErrInvalidIncentive)
+		}
+		rate := money.RateFromFloat(req.BoostRate)
+		if rate <= 0 || req.BoostRate > 1 {
+			return nil, fmt.Errorf("%w: boost_rate must be above 0 and at most 1", ErrInvalidIncentive)
+		}
+		params.GeofenceID = utils.ToPgUUID(geofenceID)
+		params.BoostRate = rate.Numeric()
+	default:
+		return nil, fmt.Errorf("%w: unknown kind %q", ErrInvalidIncentive, req.Kind)
+	}
+
+	program, err := s.repo.CreateIncentiveProgram(ctx, params)
+	if err != nil {
+		if repository.IsForeignKeyViolation(err) {
+			return nil, fmt.Errorf("%w: geofence %s doesn't exist", ErrInvalidIncentive, req.GeofenceID)
+		}
+		return nil, fmt.Errorf("failed to create incentive program: %w", err)
+	}
+
+	return toIncentiveProgramResponse(program), nil
+}
+
+func (s *IncentiveService) ListPrograms(ctx context.Context, limit, offset int32) ([]domain.IncentiveProgramResponse, error) {
+	programs, err := s.repo.ListIncentivePrograms(ctx, db.ListIncentiveProgramsParams{
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list incentive programs: %w", err)
+	}
+
+	response := make([]domain.IncentiveProgramResponse, 0, len(programs))
+	for _, program := range programs {
+		response = append(response, *toIncentiveProgramResponse(program))
+	}
+	return response, nil
+}
+
+// SetProgramActive pauses or resumes a program. Trips completed while it is
+// paused don't count, and paused peak guarantees are never settled.
+func (s *IncentiveService) SetProgramActive(ctx context.Context, programID uuid.UUID, isActive bool) (*domain.IncentiveProgramResponse, error) {
+	program, err := s.repo.UpdateIncentiveProgramStatus(ctx, db.UpdateIncentiveProgramStatusParams{
+		ID:       utils.ToPgUUID(programID),
+		IsActive: isActive,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrIncentiveNotFound
+		}
+		return nil, fmt.Errorf("failed to update incentive program: %w", err)
+	}
+
+	return toIncentiveProgramResponse(program), nil
+}
+
+// GetDriverIncentives returns the programs open to a driver's city and
+// vehicle type, with the driver's progress in each.
+func (s *IncentiveService) GetDriverIncentives(ctx context.Context, driverID uuid.UUID) ([]domain.IncentiveProgressResponse, error) {
+	pgDriverID := utils.ToPgUUID(driverID)
+
+	var cityCode, vehicleType pgtype.Text
+	profile, err := s.driverRepo.GetDriverProfileByUserID(ctx, pgDriverID)
+	switch {
+	case err == nil:
+		cityCode = profile.CityCode
+		vehicleType = pgtype.Text{String: profile.VehicleType, Valid: profile.VehicleType != ""}
+	case !errors.Is(err, pgx.ErrNoRows):
+		return nil, fmt.Errorf("failed to get driver profile: %w", err)
+	}
+
+	now := time.Now().UTC()
+	since := pgtype.Timestamp{Time: now.Add(-incentiveLookback), Valid: true}
+
+	programs, err := s.repo.GetDriverIncentivePrograms(ctx, db.GetDriverIncentiveProgramsParams{
+		Since:       since,
+		Until:       pgtype.Timestamp{Time: now.Add(incentiveLookahead), Valid: true},
+		CityCode:    cityCode,
+		VehicleType: vehicleType,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get incentive programs: %w", err)
+	}
+
+	progress, err := s.repo.GetDriverIncentiveProgress(ctx, db.GetDriverIncentiveProgressParams{
+		DriverID: pgDriverID,
+		Since:    since,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get incentive progress: %w", err)
+	}
+	byProgram := make(map[pgtype.UUID]db.IncentiveProgress, len(progress))
+	for _, p := range progress {
+		byProgram[p.ProgramID] = p
+	}
+
+	response := make([]domain.IncentiveProgressResponse, 0, len(programs))
+	for _, program := range programs {
+		response = append(response, toIncentiveProgressResponse(program, byProgram[program.ID]))
+	}
+	return response, nil
+}
+
+// RecordTrip counts a completed trip towards every program it qualifies
+// for, crediting quest bonuses and area boosts as soon as they are earned.
+// Each trip is counted at most once per program, so redelivered events
+// are ignored.
+func (s *IncentiveService) RecordTrip(ctx context.Context, event events.TripCompletedEvent) error {
+	tripID, err := uuid.Parse(event.TripID)
+	if err != nil {
+		return fmt.Errorf("invalid trip ID: %w", err)
+	}
+	driverID, err := uuid.Parse(event.DriverID)
+	if err != nil {
+		return fmt.Errorf("invalid driver ID: %w", err)
+	}
+
+	currency := s.currency
+	if event.Currency != "" {
+		if currency, err = money.ParseCurrency(event.Currency); err != nil {
+			return err
+		}
+	}
+
+	completedAt := event.CompletedAt
+	if completedAt.IsZero() {
+		completedAt = time.Now()
+	}
+	completedAt = completedAt.UTC()
+
+	var vehicleType pgtype.Text
+	if profile, err := s.driverRepo.GetDriverProfileByUserID(ctx, utils.ToPgUUID(driverID)); err == nil {
+		vehicleType = pgtype.Text{String: profile.VehicleType, Valid: true}
+	}
+
+	programs, err := s.repo.GetApplicableIncentivePrograms(ctx, db.GetApplicableIncentiveProgramsParams{
+		At:          pgtype.Timestamp{Time: completedAt, Valid: true},
+		CityCode:    pgtype.Text{String: event.CityCode, Valid: event.CityCode != ""},
+		VehicleType: vehicleType,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to load incentive programs: %w", err)
+	}
+
+	fare := money.FromFloat(event.ActualFare, currency)
+	pickup := routing.Point{Lat: event.PickupLatitude, Lng: event.PickupLongitude}
+
+	for _, program := range programs {
+		// Programs for every city are in the default currency and only
+		// count trips charged in it.
+		if program.Currency != currency.String() {
+			continue
+		}
+		if program.Kind == domain.IncentiveKindAreaBoost {
+			inside, err := s.pickedUpIn(ctx, program, pickup)
+			if err != nil {
+				log.Printf("Skipping area boost %s: %v", utils.FromPgUUID(program.ID), err)
+				continue
+			}
+			if !inside {
+				continue
+			}
+		}
+
+		var credits []incentiveCredit
+		err := s.repo.WithTx(ctx, func(q *db.Queries) error {
+			var err error
+			credits, err = s.countTrip(ctx, q, program, tripID, utils.ToPgUUID(driverID), fare, completedAt)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("failed to count trip towards incentive %s: %w", utils.FromPgUUID(program.ID), err)
+		}
+		s.publishCredits(ctx, credits)
+	}
+
+	return nil
+}
+
+// countTrip adds a trip to the driver's progress in a program and credits
+// whatever it earns.
+func (s *IncentiveService) countTrip(ctx context.Context, q *db.Queries, program db.IncentiveProgram, tripID uuid.UUID, driverID pgtype.UUID, fare money.Money, at time.Time) ([]incentiveCredit, error) {
+	recorded, err := q.RecordIncentiveTrip(ctx, db.RecordIncentiveTripParams{
+		ProgramID: program.ID,
+		TripID:    utils.ToPgUUID(tripID),
+		DriverID:  driverID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if recorded == 0 {
+		return nil, nil
+	}
+
+	progress, err := q.AddIncentiveTrip(ctx, db.AddIncentiveTripParams{
+		ProgramID: program.ID,
+		DriverID:  driverID,
+		Fare:      fare.Numeric(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	currency := money.Currency(program.Currency)
+	switch program.Kind {
+	case domain.IncentiveKindQuest, domain.IncentiveKindPeakGuarantee:
+		if progress.TripsCompleted < program.TargetTrips {
+			return nil, nil
+		}
+		completed, err := q.MarkIncentiveCompleted(ctx, db.MarkIncentiveCompletedParams{
+			CompletedAt: pgtype.Timestamp{Time: at, Valid: true},
+			ProgramID:   program.ID,
+			DriverID:    driverID,
+		})
+		if err != nil {
+			return nil, err
+		}
+		// Peak guarantees are only paid once the window has closed.
+		if completed == 0 || program.Kind != domain.IncentiveKindQuest {
+			return nil, nil
+		}
+		credit, err := s.credit(ctx, q, program, driverID, money.FromNumeric(program.RewardAmount, currency), at)
+		if err != nil {
+			return nil, err
+		}
+		return []incentiveCredit{credit}, nil
+
+	case domain.IncentiveKindAreaBoost:
+		bonus := fare.Apply(money.RateFromNumeric(program.BoostRate), money.HalfUp)
+		if bonus.Sign() <= 0 {
+			return nil, nil
+		}
+		credit, err := s.credit(ctx, q, program, driverID, bonus, at)
+		if err != nil {
+			return nil, err
+		}
+		credit.tripID = tripID.String()
+		return []incentiveCredit{credit}, nil
+	}
+	return nil, nil
+}
+
+// credit adds a bonus to the driver's earnings, where the next payout batch
+// picks it up.
+func (s *IncentiveService) credit(ctx context.Context, q *db.Queries, program db.IncentiveProgram, driverID pgtype.UUID, amount money.Money, at time.Time) (incentiveCredit, error) {
+	if _, err := q.CreateIncentiveEarning(ctx, db.CreateIncentiveEarningParams{
+		DriverID:           driverID,
+		IncentiveProgramID: program.ID,
+		Amount:             amount.Numeric(),
+		Currency:           amount.Currency().String(),
+		EarnedAt:           pgtype.Timestamp{Time: at, Valid: true},
+	}); err != nil {
+		return incentiveCredit{}, err
+	}
+	if err := q.AddIncentiveBonus(ctx, db.AddIncentiveBonusParams{
+		Bonus:     amount.Numeric(),
+		ProgramID: program.ID,
+		DriverID:  driverID,
+	}); err != nil {
+		return incentiveCredit{}, err
+	}
+	return incentiveCredit{program: program, driverID: driverID, amount: amount}, nil
+}
+
+// pickedUpIn reports whether a pickup lies inside an area boost's geofence.
+func (s *IncentiveService) pickedUpIn(ctx context.Context, program db.IncentiveProgram, pickup routing.Point) (bool, error) {
+	if pickup.Lat == 0 && pickup.Lng == 0 {
+		return false, nil
+	}
+	data, err := s.repo.GetGeofencePolygon(ctx, program.GeofenceID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get geofence: %w", err)
+	}
+	var vertices []domain.GeoPoint
+	if err := json.Unmarshal(data, &vertices); err != nil {
+		return false, fmt.Errorf("unreadable polygon: %w", err)
+	}
+	polygon := make(geofence.Polygon, len(vertices))
+	for i, v := range vertices {
+		polygon[i] = routing.Point{Lat: v.Latitude, Lng: v.Longitude}
+	}
+	return polygon.Contains(pickup), nil
+}
+
+// RunSettlementWorker pays peak guarantees once their window has closed. It
+// blocks until ctx is cancelled.
+func (s *IncentiveService) RunSettlementWorker(ctx context.Context) {
+	ticker := time.NewTicker(incentiveSettleInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.settlePeakGuarantees(ctx)
+		}
+	}
+}
+
+func (s *IncentiveService) settlePeakGuarantees(ctx context.Context) {
+	programs, err := s.repo.GetUnsettledPeakGuarantees(ctx, db.GetUnsettledPeakGuaranteesParams{
+		EndsAt: pgtype.Timestamp{Time: time.Now().UTC().Add(-incentiveSettleDelay), Valid: true},
+		Limit:  incentiveSettleBatch,
+	})
+	if err != nil {
+		log.Printf("Failed to load peak guarantees to settle: %v", err)
+		return
+	}
+
+	for _, program := range programs {
+		credits, err := s.settlePeakGuarantee(ctx, program)
+		if err != nil {
+			log.Printf("Failed to settle peak guarantee %s: %v", utils.FromPgUUID(program.ID), err)
+			continue
+		}
+		s.publishCredits(ctx, credits)
+		log.Printf("Settled peak guarantee %s with %d top-ups", utils.FromPgUUID(program.ID), len(credits))
+	}
+}
+
+// settlePeakGuarantee tops up every driver who completed the target number
+// of trips to the guaranteed amount. Marking the program settled in the
+// same transaction means a guarantee is only ever paid once.
+func (s *IncentiveService) settlePeakGuarantee(ctx context.Context, program db.IncentiveProgram) ([]incentiveCredit, error) {
+	currency := money.Currency(program.Currency)
+	guaranteed := money.FromNumeric(program.RewardAmount, currency)
+
+	var credits []incentiveCredit
+	err := s.repo.WithTx(ctx, func(q *db.Queries) error {
+		credits = nil
+
+		settled, err := q.MarkIncentiveProgramSettled(ctx, program.ID)
+		if err != nil {
+			return err
+		}
+		if settled == 0 {
+			return nil
+		}
+
+		qualified, err := q.GetCompletedIncentiveProgress(ctx, program.ID)
+		if err != nil {
+			return err
+		}
+		for _, p := range qualified {
+			topUp := guaranteed.Sub(money.FromNumeric(p.FaresEarned, currency))
+			if topUp.Sign() <= 0 {
+				continue
+			}
+			credit, err := s.credit(ctx, q, program, p.DriverID, topUp, program.EndsAt.Time)
+			if err != nil {
+				return err
+			}
+			credits = append(credits, credit)
+		}
+		return nil
+	})
+	return credits, err
+}
+
+func (s *IncentiveService) publishCredits(ctx context.Context, credits []incentiveCredit) {
+	for _, c := range credits {
+		s.eventBus.Publish(ctx, events.SubjectIncentiveEarned, events.IncentiveEarnedEvent{
+			ProgramID: utils.FromPgUUID(c.program.ID).String(),
+			DriverID:  utils.FromPgUUID(c.driverID).String(),
+			Kind:      c.program.Kind,
+			TripID:    c.tripID,
+			Amount:    c.amount.Float64(),
+			Currency:  c.amount.Currency().String(),
+			Timestamp: time.Now(),
+		})
+	}
+}
+
+// SubscribeToEvents counts completed trips towards incentive programs. The
+// queue group is separate from the earnings one so every instance group
+// sees each event once.
+func (s *IncentiveService) SubscribeToEvents() {
+	s.eventBus.QueueSubscribe(events.SubjectTripCompleted, "driver-service-incentives", func(ctx context.Context, data []byte) {
+		var event events.TripCompletedEvent
+		if err := json.Unmarshal(data, &event); err != nil {
+			log.Printf("Failed to unmarshal trip completed event: %v", err)
+			return
+		}
+
+		if err := s.RecordTrip(ctx, event); err != nil {
+			log.Printf("Failed to record incentives for trip %s: %v", event.TripID, err)
+			return
+		}
+	})
+}
+
+func toIncentiveProgramResponse(program db.IncentiveProgram) *domain.IncentiveProgramResponse {
+	currency := money.Currency(program.Currency)
+	response := &domain.IncentiveProgramResponse{
+		ID:           utils.FromPgUUID(program.ID).String(),
+		Name:         program.Name,
+		Description:  program.Description.String,
+		Kind:         program.Kind,
+		CityCode:     program.CityCode.String,
+		VehicleType:  program.VehicleType.String,
+		Currency:     program.Currency,
+		TargetTrips:  program.TargetTrips,
+		RewardAmount: money.FromNumeric(program.RewardAmount, currency).Float64(),
+		BoostRate:    money.RateFromNumeric(program.BoostRate).Float64(),
+		StartsAt:     program.StartsAt.Time,
+		EndsAt:       program.EndsAt.Time,
+		IsActive:     program.IsActive,
+		CreatedAt:    program.CreatedAt.Time,
+	}
+	if program.GeofenceID.Valid {
+		response.GeofenceID = utils.FromPgUUID(program.GeofenceID).String()
+	}
+	if program.SettledAt.Valid {
+		response.SettledAt = &program.SettledAt.Time
+	}
+	return response
+}
+
+// toIncentiveProgressResponse reports a driver's progress; progress is the
+// zero value for drivers who haven't completed a qualifying trip yet.
+func toIncentiveProgressResponse(program db.IncentiveProgram, progress db.IncentiveProgress) domain.IncentiveProgressResponse {
+	currency := money.Currency(program.Currency)
+	response := domain.IncentiveProgressResponse{
+		Program:        *toIncentiveProgramResponse(program),
+		TripsCompleted: progress.TripsCompleted,
+		TripsRemaining: max(program.TargetTrips-progress.TripsCompleted, 0),
+		FaresEarned:    money.FromNumeric(progress.FaresEarned, currency).Float64(),
+		BonusEarned:    money.FromNumeric(progress.BonusEarned, currency).Float64(),
+	}
+	if progress.CompletedAt.Valid {
+		response.CompletedAt = &progress.CompletedAt.Time
+	}
+	return response
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/namycodes/yanga-services/services/driver-service/internal/db"
+	"github.com/namycodes/yanga-services/services/driver-service/internal/repository"
+	"github.com/namycodes/yanga-services/shared-lib/domain"
+	"github.com/namycodes/yanga-services/shared-lib/utils"
+)
+
+const maxMetricsDays = 365
+
+type MetricsService struct {
+	repo *repository.MetricsRepository
+}
+
+func NewMetricsService(repo *repository.MetricsRepository) *MetricsService {
+	return &MetricsService{
+		repo: repo,
+	}
+}
+
+// GetDriverMetrics reports a driver's acceptance and cancellation rates over
+// the last days. Rider no-shows and rider cancellations are reported but do
+// not count against the driver.
+func (s *MetricsService) GetDriverMetrics(ctx context.Context, driverID uuid.UUID, days int32) (*domain.DriverMetricsResponse, error) {
+	if days <= 0 || days > maxMetricsDays {
+		return nil, fmt.Errorf("%w: days must be between 1 and %d", ErrInvalidPeriod, maxMetricsDays)
+	}
+
+	end := time.Now().UTC()
+	start := end.AddDate(0, 0, -int(days))
+	pgDriverID := utils.ToPgUUID(driverID)
+	since := pgtype.Timestamp{Time: start, Valid: true}
+
+	requests, err := s.repo.GetDriverRequestStats(ctx, db.GetDriverRequestStatsParams{
+		DriverID: pgDriverID,
+		Since:    since,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ride request stats: %w", err)
+	}
+
+	trips, err := s.repo.GetDriverTripStats(ctx, db.GetDriverTripStatsParams{
+		DriverID: pgDriverID,
+		Since:    since,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trip stats: %w", err)
+	}
+
+	return &domain.DriverMetricsResponse{
+		DriverID:            driverID.String(),
+		PeriodStart:         start,
+		PeriodEnd:           end,
+		RequestsOffered:     requests.Offered,
+		RequestsAccepted:    requests.Accepted,
+		RequestsRejected:    requests.Rejected,
+		RequestsExpired:     requests.Expired,
+		AcceptanceRate:      rate(requests.Accepted, requests.Offered),
+		AcceptedTrips:       trips.AcceptedTrips,
+		CompletedTrips:      trips.CompletedTrips,
+		DriverCancellations: trips.DriverCancellations,
+		DriverNoShows:       trips.DriverNoShows,
+		RiderCancellations:  trips.RiderCancellations,
+		RiderNoShows:        trips.RiderNoShows,
+		CancellationRate:    rate(trips.DriverCancellations+trips.DriverNoShows, trips.AcceptedTrips),
+	}, nil
+}
+
+// rate returns part/total as a fraction rounded to four decimal places.
+func rate(part, total int64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return math.Round(float64(part)/float64(total)*10000) / 10000
+}
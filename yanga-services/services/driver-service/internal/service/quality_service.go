@@ -0,0 +1,739 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/namycodes/yanga-services/services/driver-service/internal/db"
+	"github.com/namycodes/yanga-services/services/driver-service/internal/repository"
+	"github.com/namycodes/yanga-services/shared-lib/config"
+	"github.com/namycodes/yanga-services/shared-lib/domain"
+	"github.com/namycodes/yanga-services/shared-lib/events"
+	"github.com/namycodes/yanga-services/shared-lib/utils"
+)
+
+// How much each component counts towards the quality score.
+const (
+	qualityRatingWeight       = 0.40
+	qualityAcceptanceWeight   = 0.20
+	qualityCancellationWeight = 0.25
+	qualityComplaintWeight    = 0.15
+)
+
+const (
+	// complaintPenalty is how much of the complaint component each
+	// complaint per completed trip costs: one complaint in ten trips
+	// zeroes it.
+	complaintPenalty = 10
+	// qualityReasonThreshold is the component value below which the
+	// component is given as a reason for an action.
+	qualityReasonThreshold = 0.75
+	// qualityWarningCooldown keeps a driver who stays in the warning band
+	// from being warned on every check.
+	qualityWarningCooldown = 7 * 24 * time.Hour
+	qualityActionHistory   = 10
+	maxComplaintLength     = 1000
+)
+
+var (
+	ErrDriverNotFound    = errors.New("driver not found")
+	ErrQualityNotScored  = errors.New("driver has not been scored yet")
+	ErrNotSuspended      = errors.New("driver is not suspended or under review")
+	ErrInvalidComplaint  = errors.New("invalid complaint")
+	ErrNoteRequired      = errors.New("a note is required to reinstate a driver")
+	ErrDispatchSuspended = errors.New("you are suspended from taking trips")
+)
+
+// QualityService scores drivers on their rating, acceptance rate,
+// cancellation rate and complaints, and warns, suspends from dispatch or
+// deactivates pending review those whose score falls below the configured
+// thresholds.
+type QualityService struct {
+	repo            *repository.QualityRepository
+	metricsRepo     *repository.MetricsRepository
+	driverRepo      *repository.DriverRepository
+	eventBus        events.EventBus
+	window          time.Duration
+	minTrips        int64
+	warningScore    float64
+	suspensionScore float64
+	reviewScore     float64
+	suspension      time.Duration
+	interval        time.Duration
+}
+
+func NewQualityService(repo *repository.QualityRepository, metricsRepo *repository.MetricsRepository, driverRepo *repository.DriverRepository, eventBus events.EventBus, cfg *config.Config) *QualityService {
+	return &QualityService{
+		repo:            repo,
+		metricsRepo:     metricsRepo,
+		driverRepo:      driverRepo,
+		eventBus:        eventBus,
+		window:          time.Duration(cfg.QualityWindowDays) * 24 * time.Hour,
+		minTrips:        int64(cfg.QualityMinTrips),
+		warningScore:    float64(cfg.QualityWarningScore),
+		suspensionScore: float64(cfg.QualitySuspensionScore),
+		reviewScore:     float64(cfg.QualityReviewScore),
+		suspension:      time.Duration(cfg.QualitySuspensionHours) * time.Hour,
+		interval:        time.Duration(cfg.QualityCheckIntervalMinutes) * time.Minute,
+	}
+}
+
+// qualityScore is a driver's score and what it was built from. Components
+// with nothing to measure, such as the acceptance rate of a driver who was
+// offered no trips, are nil and left out of the score.
+type qualityScore struct {
+	score            float64
+	ratingAverage    *float64
+	acceptanceRate   *float64
+	cancellationRate *float64
+	complaints       int64
+	completedTrips   int64
+	reasons          []string
+}
+
+// GetDriverQuality returns a driver's latest score and recent actions.
+func (s *QualityService) GetDriverQuality(ctx context.Context, driverID uuid.UUID) (*domain.DriverQualityResponse, error) {
+	pgDriverID := utils.ToPgUUID(driverID)
+
+	score, err := s.repo.GetDriverQualityScore(ctx, pgDriverID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrQualityNotScored
+		}
+		return nil, fmt.Errorf("failed to get quality score: %w", err)
+	}
+
+	status, err := s.driverRepo.GetDriverDispatchStatus(ctx, pgDriverID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get driver: %w", err)
+	}
+
+	actions, err := s.repo.ListDriverQualityActions(ctx, db.ListDriverQualityActionsParams{
+		DriverID: pgDriverID,
+		Limit:    qualityActionHistory,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get quality actions: %w", err)
+	}
+
+	response := toQualityResponse(score)
+	if status.DispatchSuspendedUntil.Valid && status.DispatchSuspendedUntil.Time.After(time.Now().UTC()) {
+		response.DispatchSuspendedUntil = &status.DispatchSuspendedUntil.Time
+	}
+	response.Actions = make([]domain.DriverQualityActionResponse, len(actions))
+	for i, action := range actions {
+		response.Actions[i] = toQualityActionResponse(action)
+	}
+	return response, nil
+}
+
+// ListByLevel lists drivers at a quality level, worst score first. Admins
+// use it to find drivers awaiting review.
+func (s *QualityService) ListByLevel(ctx context.Context, level string, limit, offset int32) ([]domain.DriverQualityResponse, error) {
+	rows, err := s.repo.ListDriverQualityScoresByLevel(ctx, db.ListDriverQualityScoresByLevelParams{
+		Level:  level,
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list quality scores: %w", err)
+	}
+
+	response := make([]domain.DriverQualityResponse, 0, len(rows))
+	for _, row := range rows {
+		r := toQualityResponse(db.DriverQualityScore{
+			DriverID:         row.DriverID,
+			Score:            row.Score,
+			RatingAverage:    row.RatingAverage,
+			AcceptanceRate:   row.AcceptanceRate,
+			CancellationRate: row.CancellationRate,
+			ComplaintCount:   row.ComplaintCount,
+			CompletedTrips:   row.CompletedTrips,
+			Level:            row.Level,
+			PeriodStart:      row.PeriodStart,
+			ComputedAt:       row.ComputedAt,
+		})
+		r.FullName = row.FullName
+		response = append(response, *r)
+	}
+	return response, nil
+}
+
+// Evaluate scores a driver now and takes whatever action the score calls
+// for.
+func (s *QualityService) Evaluate(ctx context.Context, driverID uuid.UUID) (*domain.DriverQualityResponse, error) {
+	if err := s.evaluate(ctx, utils.ToPgUUID(driverID)); err != nil {
+		return nil, err
+	}
+	return s.GetDriverQuality(ctx, driverID)
+}
+
+// evaluate scores a driver over the activity since the later of the start
+// of the quality window, the end of their last suspension and their last
+// reinstatement, so a driver isn't punished twice for the same trips. A
+// driver with fewer than minTrips completed trips in that time is scored
+// but not acted on. Falling below the suspension threshold again after a
+// suspension sends the driver to review.
+func (s *QualityService) evaluate(ctx context.Context, driverID pgtype.UUID) error {
+	status, err := s.driverRepo.GetDriverDispatchStatus(ctx, driverID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrDriverNotFound
+		}
+		return fmt.Errorf("failed to get driver: %w", err)
+	}
+
+	current, err := s.repo.GetDriverQualityScore(ctx, driverID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("failed to get quality score: %w", err)
+	}
+
+	// Drivers under review wait for an admin, and suspended drivers keep
+	// their score until the suspension ends.
+	now := time.Now().UTC()
+	if current.Level == domain.QualityLevelReview {
+		return nil
+	}
+	if status.DispatchSuspendedUntil.Valid && status.DispatchSuspendedUntil.Time.After(now) {
+		return nil
+	}
+
+	lastSuspension, err := s.latestAction(ctx, driverID, domain.QualityActionSuspension)
+	if err != nil {
+		return err
+	}
+	lastReinstatement, err := s.latestAction(ctx, driverID, domain.QualityActionReinstatement)
+	if err != nil {
+		return err
+	}
+
+	windowStart := now.Add(-s.window)
+	periodStart := windowStart
+	if lastSuspension != nil && lastSuspension.SuspendedUntil.Time.After(periodStart) {
+		periodStart = lastSuspension.SuspendedUntil.Time
+	}
+	if lastReinstatement != nil && lastReinstatement.CreatedAt.Time.After(periodStart) {
+		periodStart = lastReinstatement.CreatedAt.Time
+	}
+
+	score, err := s.score(ctx, driverID, periodStart)
+	if err != nil {
+		return err
+	}
+
+	level := s.levelFor(score)
+	var action string
+	switch level {
+	case domain.QualityLevelReview:
+		action = domain.QualityActionReview
+	case domain.QualityLevelSuspended:
+		action = domain.QualityActionSuspension
+		suspendedBefore := lastSuspension != nil && lastSuspension.CreatedAt.Time.After(windowStart) &&
+			(lastReinstatement == nil || lastSuspension.CreatedAt.Time.After(lastReinstatement.CreatedAt.Time))
+		if suspendedBefore {
+			level, action = domain.QualityLevelReview, domain.QualityActionReview
+			score.reasons = append(score.reasons, "repeat_suspension")
+		}
+	case domain.QualityLevelWarning:
+		lastWarning, err := s.latestAction(ctx, driverID, domain.QualityActionWarning)
+		if err != nil {
+			return err
+		}
+		if lastWarning == nil || now.Sub(lastWarning.CreatedAt.Time) >= qualityWarningCooldown {
+			action = domain.QualityActionWarning
+		}
+	}
+
+	var suspendedUntil pgtype.Timestamp
+	if action == domain.QualityActionSuspension {
+		suspendedUntil = pgtype.Timestamp{Time: now.Add(s.suspension), Valid: true}
+	}
+
+	err = s.repo.WithTx(ctx, func(q *db.Queries) error {
+		if _, err := q.UpsertDriverQualityScore(ctx, db.UpsertDriverQualityScoreParams{
+			DriverID:         driverID,
+			Score:            utils.Float64ToNumeric(score.score),
+			RatingAverage:    optionalNumeric(score.ratingAverage),
+			AcceptanceRate:   optionalNumeric(score.acceptanceRate),
+			CancellationRate: optionalNumeric(score.cancellationRate),
+			ComplaintCount:   int32(score.complaints),
+			CompletedTrips:   int32(score.completedTrips),
+			Level:            level,
+			PeriodStart:      pgtype.Timestamp{Time: periodStart, Valid: true},
+		}); err != nil {
+			return err
+		}
+		if action == "" {
+			return nil
+		}
+
+		if _, err := q.CreateDriverQualityAction(ctx, db.CreateDriverQualityActionParams{
+			DriverID:       driverID,
+			Action:         action,
+			Score:          utils.Float64ToNumeric(score.score),
+			Reasons:        pgtype.Text{String: strings.Join(score.reasons, ","), Valid: len(score.reasons) > 0},
+			SuspendedUntil: suspendedUntil,
+		}); err != nil {
+			return err
+		}
+		switch action {
+		case domain.QualityActionSuspension:
+			return q.SuspendDriverDispatch(ctx, db.SuspendDriverDispatchParams{
+				UserID:                 driverID,
+				DispatchSuspendedUntil: suspendedUntil,
+			})
+		case domain.QualityActionReview:
+			return q.DeactivateDriver(ctx, driverID)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save quality score: %w", err)
+	}
+
+	if action != "" {
+		s.publishAction(ctx, driverID, action, score.score, score.reasons, suspendedUntil, "")
+	}
+	return nil
+}
+
+// score builds a driver's quality score from their activity since a time.
+func (s *QualityService) score(ctx context.Context, driverID pgtype.UUID, since time.Time) (qualityScore, error) {
+	pgSince := pgtype.Timestamp{Time: since, Valid: true}
+
+	ratings, err := s.repo.GetDriverRatingSince(ctx, db.GetDriverRatingSinceParams{
+		DriverID: driverID,
+		Since:    pgSince,
+	})
+	if err != nil {
+		return qualityScore{}, fmt.Errorf("failed to get ratings: %w", err)
+	}
+
+	requests, err := s.metricsRepo.GetDriverRequestStats(ctx, db.GetDriverRequestStatsParams{
+		DriverID: driverID,
+		Since:    pgSince,
+	})
+	if err != nil {
+		return qualityScore{}, fmt.Errorf("failed to get ride request stats: %w", err)
+	}
+
+	trips, err := s.metricsRepo.GetDriverTripStats(ctx, db.GetDriverTripStatsParams{
+		DriverID: driverID,
+		Since:    pgSince,
+	})
+	if err != nil {
+		return qualityScore{}, fmt.Errorf("failed to get trip stats: %w", err)
+	}
+
+	complaints, err := s.repo.CountDriverComplaintsSince(ctx, db.CountDriverComplaintsSinceParams{
+		DriverID: driverID,
+		Since:    pgSince,
+	})
+	if err != nil {
+		return qualityScore{}, fmt.Errorf("failed to count complaints: %w", err)
+	}
+
+	score := qualityScore{
+		complaints:     complaints,
+		completedTrips: trips.CompletedTrips,
+	}
+	var total, weights float64
+	add := func(value, weight float64, reason string) {
+		total += value * weight
+		weights += weight
+		if value < qualityReasonThreshold {
+			score.reasons = append(score.reasons, reason)
+		}
+	}
+
+	if ratings.RatingCount > 0 {
+		average := math.Round(ratings.RatingAverage*100) / 100
+		score.ratingAverage = &average
+		add((average-1)/4, qualityRatingWeight, "low_rating")
+	}
+	if requests.Offered > 0 {
+		acceptance := rate(requests.Accepted, requests.Offered)
+		score.acceptanceRate = &acceptance
+		add(acceptance, qualityAcceptanceWeight, "low_acceptance")
+	}
+	if trips.AcceptedTrips > 0 {
+		cancellation := rate(trips.DriverCancellations+trips.DriverNoShows, trips.AcceptedTrips)
+		score.cancellationRate = &cancellation
+		add(1-cancellation, qualityCancellationWeight, "high_cancellations")
+	}
+	if trips.CompletedTrips > 0 || complaints > 0 {
+		perTrip := float64(complaints) / math.Max(float64(trips.CompletedTrips), 1)
+		add(math.Max(0, 1-complaintPenalty*perTrip), qualityComplaintWeight, "complaints")
+	}
+
+	score.score = 100
+	if weights > 0 {
+		score.score = math.Round(total/weights*10000) / 100
+	}
+	return score, nil
+}
+
+// levelFor returns the level a score puts a driver at. Drivers with too
+// few trips to judge are left at good.
+func (s *QualityService) levelFor(score qualityScore) string {
+	switch {
+	case score.completedTrips < s.minTrips:
+		return domain.QualityLevelGood
+	case score.score < s.reviewScore:
+		return domain.QualityLevelReview
+	case score.score < s.suspensionScore:
+		return domain.QualityLevelSuspended
+	case score.score < s.warningScore:
+		return domain.QualityLevelWarning
+	}
+	return domain.QualityLevelGood
+}
+
+// Reinstate lets an admin end a driver's suspension or review. The driver
+// is approved again and starts from a clean period.
+func (s *QualityService) Reinstate(ctx context.Context, adminID, driverID uuid.UUID, req *domain.ReinstateDriverRequest) (*domain.DriverQualityResponse, error) {
+	note := strings.TrimSpace(req.Note)
+	if note == "" {
+		return nil, ErrNoteRequired
+	}
+	pgDriverID := utils.ToPgUUID(driverID)
+
+	status, err := s.driverRepo.GetDriverDispatchStatus(ctx, pgDriverID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrDriverNotFound
+		}
+		return nil, fmt.Errorf("failed to get driver: %w", err)
+	}
+	current, err := s.repo.GetDriverQualityScore(ctx, pgDriverID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("failed to get quality score: %w", err)
+	}
+
+	suspended := status.DispatchSuspendedUntil.Valid && status.DispatchSuspendedUntil.Time.After(time.Now().UTC())
+	if !suspended && current.Level != domain.QualityLevelReview {
+		return nil, ErrNotSuspended
+	}
+
+	err = s.repo.WithTx(ctx, func(q *db.Queries) error {
+		if err := q.ReinstateDriver(ctx, pgDriverID); err != nil {
+			return err
+		}
+		if _, err := q.CreateDriverQualityAction(ctx, db.CreateDriverQualityActionParams{
+			DriverID:  pgDriverID,
+			Action:    domain.QualityActionReinstatement,
+			Score:     current.Score,
+			Note:      pgtype.Text{String: note, Valid: true},
+			CreatedBy: utils.ToPgUUID(adminID),
+		}); err != nil {
+			return err
+		}
+		return q.SetDriverQualityLevel(ctx, db.SetDriverQualityLevelParams{
+			DriverID: pgDriverID,
+			Level:    domain.QualityLevelGood,
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to reinstate driver: %w", err)
+	}
+
+	s.publishAction(ctx, pgDriverID, domain.QualityActionReinstatement, utils.NumericToFloat64(current.Score), nil, pgtype.Timestamp{}, note)
+	return s.GetDriverQuality(ctx, driverID)
+}
+
+// CreateComplaint records a complaint against a driver on an admin's
+// behalf, such as one a rider made to support.
+func (s *QualityService) CreateComplaint(ctx context.Context, adminID, driverID uuid.UUID, req *domain.CreateDriverComplaintRequest) (*domain.DriverComplaintResponse, error) {
+	category := strings.ToLower(strings.TrimSpace(req.Category))
+	if !slices.Contains(domain.ComplaintCategories, category) {
+		return nil, fmt.Errorf("%w: category must be one of %s", ErrInvalidComplaint, strings.Join(domain.ComplaintCategories, ", "))
+	}
+	description := strings.TrimSpace(req.Description)
+	if len(description) > maxComplaintLength {
+		return nil, fmt.Errorf("%w: description is limited to %d characters", ErrInvalidComplaint, maxComplaintLength)
+	}
+
+	pgDriverID := utils.ToPgUUID(driverID)
+	if _, err := s.driverRepo.GetDriverDispatchStatus(ctx, pgDriverID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrDriverNotFound
+		}
+		return nil, fmt.Errorf("failed to get driver: %w", err)
+	}
+
+	var tripID pgtype.UUID
+	if req.TripID != nil {
+		tripID = utils.ToPgUUID(*req.TripID)
+	}
+	complaint, err := s.repo.CreateDriverComplaint(ctx, db.CreateDriverComplaintParams{
+		DriverID:    pgDriverID,
+		TripID:      tripID,
+		Category:    category,
+		Description: pgtype.Text{String: description, Valid: description != ""},
+		CreatedBy:   utils.ToPgUUID(adminID),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create complaint: %w", err)
+	}
+	return toComplaintResponse(complaint), nil
+}
+
+// RecordRatingComplaints raises a complaint for each complaint category a
+// rider tagged their driver with, such as unsafe_driving.
+func (s *QualityService) RecordRatingComplaints(ctx context.Context, event events.RatingCreatedPayload) error {
+	if event.RaterType != domain.RaterTypeRider {
+		return nil
+	}
+	driverID, err := uuid.Parse(event.RatedID)
+	if err != nil {
+		return fmt.Errorf("invalid rated ID: %w", err)
+	}
+	ratingID, err := uuid.Parse(event.RatingID)
+	if err != nil {
+		return fmt.Errorf("invalid rating ID: %w", err)
+	}
+	tripID, err := uuid.Parse(event.TripID)
+	if err != nil {
+		return fmt.Errorf("invalid trip ID: %w", err)
+	}
+
+	for _, tag := range event.Tags {
+		if !slices.Contains(domain.ComplaintCategories, tag) {
+			continue
+		}
+		if _, err := s.repo.RecordRatingComplaint(ctx, db.RecordRatingComplaintParams{
+			DriverID: utils.ToPgUUID(driverID),
+			TripID:   utils.ToPgUUID(tripID),
+			RatingID: utils.ToPgUUID(ratingID),
+			Category: tag,
+		}); err != nil {
+			return fmt.Errorf("failed to record complaint: %w", err)
+		}
+	}
+	return nil
+}
+
+// RecordSupportComplaint records the complaint a support agent upheld when
+// resolving a rider's ticket about their driver. A ticket raises at most
+// one complaint, so a redelivered event is a no-op.
+func (s *QualityService) RecordSupportComplaint(ctx context.Context, event events.SupportTicketResolvedEvent) error {
+	if event.ComplaintCategory == "" {
+		return nil
+	}
+	if !slices.Contains(domain.ComplaintCategories, event.ComplaintCategory) {
+		return fmt.Errorf("%w: unknown category %q", ErrInvalidComplaint, event.ComplaintCategory)
+	}
+	driverID, err := uuid.Parse(event.DriverID)
+	if err != nil {
+		return fmt.Errorf("invalid driver ID: %w", err)
+	}
+	tripID, err := uuid.Parse(event.TripID)
+	if err != nil {
+		return fmt.Errorf("invalid trip ID: %w", err)
+	}
+	ticketID, err := uuid.Parse(event.TicketID)
+	if err != nil {
+		return fmt.Errorf("invalid ticket ID: %w", err)
+	}
+	resolvedBy, err := uuid.Parse(event.ResolvedBy)
+	if err != nil {
+		return fmt.Errorf("invalid resolver ID: %w", err)
+	}
+
+	if _, err := s.repo.RecordSupportComplaint(ctx, db.RecordSupportComplaintParams{
+		DriverID:    utils.ToPgUUID(driverID),
+		TripID:      utils.ToPgUUID(tripID),
+		TicketID:    utils.ToPgUUID(ticketID),
+		Category:    event.ComplaintCategory,
+		Description: pgtype.Text{String: event.Note, Valid: event.Note != ""},
+		CreatedBy:   utils.ToPgUUID(resolvedBy),
+	}); err != nil {
+		return fmt.Errorf("failed to record complaint: %w", err)
+	}
+	return nil
+}
+
+// RunQualityWorker scores every driver each QUALITY_CHECK_INTERVAL_MINUTES
+// until ctx is cancelled.
+func (s *QualityService) RunQualityWorker(ctx context.Context) {
+	if s.interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.evaluateAll(ctx)
+		}
+	}
+}
+
+func (s *QualityService) evaluateAll(ctx context.Context) {
+	drivers, err := s.repo.ListDriverUserIDs(ctx)
+	if err != nil {
+		log.Printf("Failed to list drivers to score: %v", err)
+		return
+	}
+
+	for _, driverID := range drivers {
+		if ctx.Err() != nil {
+			return
+		}
+		if err := s.evaluate(ctx, driverID); err != nil {
+			log.Printf("Failed to score driver %s: %v", utils.FromPgUUID(driverID), err)
+		}
+	}
+}
+
+// SubscribeToEvents raises complaints from rider ratings. rating.created
+// uses its own queue group so rating aggregates still see every event.
+func (s *QualityService) SubscribeToEvents() {
+	s.eventBus.QueueSubscribe(events.SubjectRatingCreated, "driver-service-quality", func(ctx context.Context, data []byte) {
+		var event events.RatingCreatedPayload
+		if err := json.Unmarshal(data, &event); err != nil {
+			log.Printf("Failed to unmarshal rating created event: %v", err)
+			return
+		}
+
+		if err := s.RecordRatingComplaints(ctx, event); err != nil {
+			log.Printf("Failed to record complaints from rating %s: %v", event.RatingID, err)
+			return
+		}
+	})
+
+	// Complaints upheld by support when resolving a ticket about a driver
+	s.eventBus.QueueSubscribe(events.SubjectSupportTicketResolved, "driver-service-quality", func(ctx context.Context, data []byte) {
+		var event events.SupportTicketResolvedEvent
+		if err := json.Unmarshal(data, &event); err != nil {
+			log.Printf("Failed to unmarshal support ticket resolved event: %v", err)
+			return
+		}
+
+		if err := s.RecordSupportComplaint(ctx, event); err != nil {
+			log.Printf("Failed to record complaint from support ticket %s: %v", event.TicketID, err)
+			return
+		}
+	})
+}
+
+func (s *QualityService) latestAction(ctx context.Context, driverID pgtype.UUID, action string) (*db.DriverQualityAction, error) {
+	latest, err := s.repo.GetLatestDriverQualityAction(ctx, db.GetLatestDriverQualityActionParams{
+		DriverID: driverID,
+		Action:   action,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get last %s: %w", action, err)
+	}
+	return &latest, nil
+}
+
+// publishAction tells notifications and admin tooling about a quality
+// action. Suspended and deactivated drivers were also taken offline, which
+// is published the same way as the driver going offline themselves.
+func (s *QualityService) publishAction(ctx context.Context, driverID pgtype.UUID, action string, score float64, reasons []string, suspendedUntil pgtype.Timestamp, note string) {
+	subject := map[string]string{
+		domain.QualityActionWarning:       events.SubjectDriverQualityWarning,
+		domain.QualityActionSuspension:    events.SubjectDriverQualitySuspended,
+		domain.QualityActionReview:        events.SubjectDriverQualityReview,
+		domain.QualityActionReinstatement: events.SubjectDriverQualityReinstated,
+	}[action]
+
+	event := events.DriverQualityEvent{
+		DriverID:  utils.FromPgUUID(driverID).String(),
+		Action:    action,
+		Score:     score,
+		Reasons:   reasons,
+		Note:      note,
+		Timestamp: time.Now(),
+	}
+	if suspendedUntil.Valid {
+		event.SuspendedUntil = &suspendedUntil.Time
+	}
+	s.eventBus.Publish(ctx, subject, event)
+
+	if action == domain.QualityActionSuspension || action == domain.QualityActionReview {
+		s.eventBus.Publish(ctx, events.SubjectDriverOffline, events.DriverStatusPayload{
+			DriverID: event.DriverID,
+			IsOnline: false,
+		})
+	}
+}
+
+func optionalNumeric(value *float64) pgtype.Numeric {
+	if value == nil {
+		return pgtype.Numeric{}
+	}
+	return utils.Float64ToNumeric(*value)
+}
+
+func optionalFloat(value pgtype.Numeric) *float64 {
+	if !value.Valid {
+		return nil
+	}
+	f := utils.NumericToFloat64(value)
+	return &f
+}
+
+func toQualityResponse(score db.DriverQualityScore) *domain.DriverQualityResponse {
+	return &domain.DriverQualityResponse{
+		DriverID:         utils.FromPgUUID(score.DriverID).String(),
+		Score:            utils.NumericToFloat64(score.Score),
+		Level:            score.Level,
+		RatingAverage:    optionalFloat(score.RatingAverage),
+		AcceptanceRate:   optionalFloat(score.AcceptanceRate),
+		CancellationRate: optionalFloat(score.CancellationRate),
+		ComplaintCount:   score.ComplaintCount,
+		CompletedTrips:   score.CompletedTrips,
+		PeriodStart:      score.PeriodStart.Time,
+		ComputedAt:       score.ComputedAt.Time,
+	}
+}
+
+func toQualityActionResponse(action db.DriverQualityAction) domain.DriverQualityActionResponse {
+	response := domain.DriverQualityActionResponse{
+		ID:        utils.FromPgUUID(action.ID).String(),
+		Action:    action.Action,
+		Score:     optionalFloat(action.Score),
+		Note:      action.Note.String,
+		CreatedAt: action.CreatedAt.Time,
+	}
+	if action.Reasons.String != "" {
+		response.Reasons = strings.Split(action.Reasons.String, ",")
+	}
+	if action.SuspendedUntil.Valid {
+		response.SuspendedUntil = &action.SuspendedUntil.Time
+	}
+	return response
+}
+
+func toComplaintResponse(complaint db.DriverComplaint) *domain.DriverComplaintResponse {
+	response := &domain.DriverComplaintResponse{
+		ID:          utils.FromPgUUID(complaint.ID).String(),
+		DriverID:    utils.FromPgUUID(complaint.DriverID).String(),
+		Source:      complaint.Source,
+		Category:    complaint.Category,
+		Description: complaint.Description.String,
+		CreatedAt:   complaint.CreatedAt.Time,
+	}
+	if complaint.TripID.Valid {
+		response.TripID = utils.FromPgUUID(complaint.TripID).String()
+	}
+	return response
+}
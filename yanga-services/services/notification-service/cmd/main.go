@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/jackc/pgx/v5/pgxpool"
+	_ "github.com/namycodes/yanga-services/services/notification-service/docs"
+	"github.com/namycodes/yanga-services/services/notification-service/internal/db"
+	"github.com/namycodes/yanga-services/services/notification-service/internal/handler"
+	"github.com/namycodes/yanga-services/services/notification-service/internal/notifier"
+	"github.com/namycodes/yanga-services/services/notification-service/internal/repository"
+	"github.com/namycodes/yanga-services/services/notification-service/internal/routes"
+	"github.com/namycodes/yanga-services/services/notification-service/internal/service"
+	"github.com/namycodes/yanga-services/shared-lib/config"
+	"github.com/namycodes/yanga-services/shared-lib/domain"
+	"github.com/namycodes/yanga-services/shared-lib/events"
+	"github.com/namycodes/yanga-services/shared-lib/middleware"
+)
+
+// @title Notification Service API
+// @version 1.0
+// @description Push, SMS and in-app notifications for riders and drivers
+// @host localhost:8086
+// @BasePath /api/v1
+func main() {
+	cfg := config.Load()
+
+	dbPool, err := pgxpool.New(context.Background(), cfg.DatabaseURL())
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer dbPool.Close()
+
+	if err := dbPool.Ping(context.Background()); err != nil {
+		log.Fatalf("Failed to ping database: %v", err)
+	}
+	log.Println("✅ Connected to database")
+
+	eventBus, err := events.NewNATSEventBus(cfg.NatsURL)
+	if err != nil {
+		log.Fatalf("Failed to connect to NATS: %v", err)
+	}
+	defer eventBus.Close()
+	log.Println("✅ Connected to NATS")
+
+	queries := db.New(dbPool)
+	notificationRepo := repository.NewNotificationRepository(queries)
+
+	// Providers are stubbed until FCM, APNs and SMS credentials are wired in
+	notifiers := map[string]notifier.Notifier{
+		domain.NotificationChannelPush:  notifier.NewPush(notifier.NewLog("fcm"), notifier.NewLog("apns")),
+		domain.NotificationChannelSMS:   notifier.NewLog("sms"),
+		domain.NotificationChannelInbox: notifier.NewInbox(notificationRepo),
+	}
+	notificationService := service.NewNotificationService(notificationRepo, notifiers, eventBus, cfg)
+	notificationHandler := handler.NewNotificationHandler(notificationService)
+
+	notificationService.SubscribeToEvents()
+
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+	go notificationService.RunRetryWorker(workerCtx)
+
+	router := mux.NewRouter()
+	router.Use(middleware.RequestIDMiddleware)
+	router.Use(middleware.LoggingMiddleware)
+	router.Use(middleware.CORSMiddleware)
+
+	routes.SetupNotificationRoutes(router, notificationHandler, config.JWTConfig{Secret: cfg.JWTSecret, IdentitySecret: cfg.GatewayIdentitySecret})
+
+	router.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"status":"healthy"}`))
+	}).Methods("GET")
+
+	addr := fmt.Sprintf("%s:%s", cfg.ServiceHost, cfg.ServicePort)
+	srv := &http.Server{
+		Addr:         addr,
+		Handler:      router,
+		ReadTimeout:  15 * time.Second,
+		WriteTimeout: 15 * time.Second,
+		IdleTimeout:  60 * time.Second,
+	}
+
+	go func() {
+		log.Printf("🚀 Notification Service starting on %s", addr)
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatalf("Failed to start server: %v", err)
+		}
+	}()
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+
+	log.Println("🛑 Shutting down server...")
+	stopWorkers()
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if err := srv.Shutdown(ctx); err != nil {
+		log.Fatalf("Server forced to shutdown: %v", err)
+	}
+
+	log.Println("✅ Server exited gracefully")
+}
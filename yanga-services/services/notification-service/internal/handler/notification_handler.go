@@ -0,0 +1,249 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/namycodes/yanga-services/services/notification-service/internal/service"
+	"github.com/namycodes/yanga-services/shared-lib/domain"
+	"github.com/namycodes/yanga-services/shared-lib/utils"
+)
+
+type NotificationHandler struct {
+	notificationService *service.NotificationService
+}
+
+func NewNotificationHandler(notificationService *service.NotificationService) *NotificationHandler {
+	return &NotificationHandler{
+		notificationService: notificationService,
+	}
+}
+
+// GetInbox godoc
+// @Summary Get the current user's notification inbox
+// @Description Newest first, with the number of unread messages.
+// @Tags notifications
+// @Produce json
+// @Param limit query int false "Limit" default(20)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {object} domain.InboxResponse
+// @Router /notifications [get]
+// @Security BearerAuth
+func (h *NotificationHandler) GetInbox(w http.ResponseWriter, r *http.Request) {
+	userIDStr := r.Context().Value("user_id").(string)
+	userID, err := utils.ParseUUID(userIDStr)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	inbox, err := h.notificationService.GetInbox(r.Context(), userID, queryInt(r, "limit", 20), queryInt(r, "offset", 0))
+	if err != nil {
+		handleNotificationError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Inbox retrieved successfully", inbox)
+}
+
+// MarkRead godoc
+// @Summary Mark an inbox message read
+// @Tags notifications
+// @Produce json
+// @Param id path string true "Message ID"
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Router /notifications/{id}/read [put]
+// @Security BearerAuth
+func (h *NotificationHandler) MarkRead(w http.ResponseWriter, r *http.Request) {
+	userIDStr := r.Context().Value("user_id").(string)
+	userID, err := utils.ParseUUID(userIDStr)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	messageID, err := utils.ParseUUID(mux.Vars(r)["id"])
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid message ID")
+		return
+	}
+
+	if err := h.notificationService.MarkRead(r.Context(), userID, messageID); err != nil {
+		handleNotificationError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Message marked read", nil)
+}
+
+// MarkAllRead godoc
+// @Summary Mark every inbox message read
+// @Tags notifications
+// @Produce json
+// @Success 200 {object} domain.SuccessResponse
+// @Router /notifications/read-all [post]
+// @Security BearerAuth
+func (h *NotificationHandler) MarkAllRead(w http.ResponseWriter, r *http.Request) {
+	userIDStr := r.Context().Value("user_id").(string)
+	userID, err := utils.ParseUUID(userIDStr)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	if err := h.notificationService.MarkAllRead(r.Context(), userID); err != nil {
+		handleNotificationError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Messages marked read", nil)
+}
+
+// GetPreferences godoc
+// @Summary Get the current user's notification preferences
+// @Tags notifications
+// @Produce json
+// @Success 200 {object} domain.NotificationPreferencesResponse
+// @Router /notifications/preferences [get]
+// @Security BearerAuth
+func (h *NotificationHandler) GetPreferences(w http.ResponseWriter, r *http.Request) {
+	userIDStr := r.Context().Value("user_id").(string)
+	userID, err := utils.ParseUUID(userIDStr)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	prefs, err := h.notificationService.GetPreferences(r.Context(), userID)
+	if err != nil {
+		handleNotificationError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Preferences retrieved successfully", prefs)
+}
+
+// UpdatePreferences godoc
+// @Summary Update the current user's notification preferences
+// @Description Quiet hours are HH:MM in the user's timezone and hold back payment, earnings and promotions notifications until they end. Only those categories can be muted; muted notifications still reach the inbox.
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Param request body domain.NotificationPreferencesRequest true "Preferences"
+// @Success 200 {object} domain.NotificationPreferencesResponse
+// @Failure 400 {object} domain.ErrorResponse
+// @Router /notifications/preferences [put]
+// @Security BearerAuth
+func (h *NotificationHandler) UpdatePreferences(w http.ResponseWriter, r *http.Request) {
+	var req domain.NotificationPreferencesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	userIDStr := r.Context().Value("user_id").(string)
+	userID, err := utils.ParseUUID(userIDStr)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	prefs, err := h.notificationService.UpdatePreferences(r.Context(), userID, &req)
+	if err != nil {
+		handleNotificationError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Preferences updated successfully", prefs)
+}
+
+// RegisterDevice godoc
+// @Summary Register a device for push notifications
+// @Description Takes the FCM (Android) or APNs (iOS) token the Expo app gets from the platform. Call it on every launch; re-registering a token is harmless.
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Param request body domain.RegisterDeviceRequest true "Device token"
+// @Success 201 {object} domain.DeviceResponse
+// @Failure 400 {object} domain.ErrorResponse
+// @Router /notifications/devices [post]
+// @Security BearerAuth
+func (h *NotificationHandler) RegisterDevice(w http.ResponseWriter, r *http.Request) {
+	var req domain.RegisterDeviceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	userIDStr := r.Context().Value("user_id").(string)
+	userID, err := utils.ParseUUID(userIDStr)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	device, err := h.notificationService.RegisterDevice(r.Context(), userID, &req)
+	if err != nil {
+		handleNotificationError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusCreated, "Device registered successfully", device)
+}
+
+// UnregisterDevice godoc
+// @Summary Stop push notifications to a device
+// @Description Call it when the user signs out of the app.
+// @Tags notifications
+// @Produce json
+// @Param id path string true "Device ID"
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Router /notifications/devices/{id} [delete]
+// @Security BearerAuth
+func (h *NotificationHandler) UnregisterDevice(w http.ResponseWriter, r *http.Request) {
+	userIDStr := r.Context().Value("user_id").(string)
+	userID, err := utils.ParseUUID(userIDStr)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	deviceID, err := utils.ParseUUID(mux.Vars(r)["id"])
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid device ID")
+		return
+	}
+
+	if err := h.notificationService.UnregisterDevice(r.Context(), userID, deviceID); err != nil {
+		handleNotificationError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Device unregistered successfully", nil)
+}
+
+func handleNotificationError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidDevice),
+		errors.Is(err, service.ErrInvalidPreferences):
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, service.ErrDeviceNotFound),
+		errors.Is(err, service.ErrMessageNotFound):
+		utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+	default:
+		utils.HandleServiceError(w, err)
+	}
+}
+
+func queryInt(r *http.Request, name string, defaultValue int32) int32 {
+	value, err := strconv.Atoi(r.URL.Query().Get(name))
+	if err != nil || value < 0 {
+		return defaultValue
+	}
+	return int32(value)
+}
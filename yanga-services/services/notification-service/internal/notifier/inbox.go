@@ -0,0 +1,43 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/namycodes/yanga-services/services/notification-service/internal/db"
+	"github.com/namycodes/yanga-services/shared-lib/utils"
+)
+
+// InboxStore saves inbox messages.
+type InboxStore interface {
+	CreateInboxMessage(ctx context.Context, params db.CreateInboxMessageParams) error
+}
+
+// Inbox puts messages in the user's in-app inbox in Postgres. A message is
+// saved once per delivery, so retrying a delivery doesn't duplicate it.
+type Inbox struct {
+	store InboxStore
+}
+
+func NewInbox(store InboxStore) *Inbox {
+	return &Inbox{store: store}
+}
+
+func (i *Inbox) Send(ctx context.Context, msg Message) error {
+	var data []byte
+	if len(msg.Data) > 0 {
+		var err error
+		if data, err = json.Marshal(msg.Data); err != nil {
+			return err
+		}
+	}
+
+	return i.store.CreateInboxMessage(ctx, db.CreateInboxMessageParams{
+		UserID:     utils.ToPgUUID(msg.UserID),
+		DeliveryID: utils.ToPgUUID(msg.DeliveryID),
+		Category:   msg.Category,
+		Title:      msg.Title,
+		Body:       msg.Body,
+		Data:       data,
+	})
+}
@@ -0,0 +1,86 @@
+// Package notifier sends rendered notifications over a channel: push to
+// the rider and driver apps, SMS, or the in-app inbox.
+package notifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/google/uuid"
+)
+
+// ErrInvalidAddress means the address will never accept the message, such
+// as a push token the app has unregistered. The delivery isn't retried.
+var ErrInvalidAddress = errors.New("address no longer accepts messages")
+
+// Message is one notification on its way to one address. Address is a
+// device token for push and a phone number for SMS, and is unused by the
+// inbox.
+type Message struct {
+	DeliveryID uuid.UUID
+	UserID     uuid.UUID
+	Address    string
+	Platform   string
+	Category   string
+	Title      string
+	Body       string
+	Data       map[string]string
+}
+
+// Notifier sends messages over one channel. Implementations usually call
+// out to a provider, so Send can fail; failures other than
+// ErrInvalidAddress are retried.
+type Notifier interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// Push sends to each platform's push provider: FCM for Android and APNs
+// for iOS.
+type Push struct {
+	providers map[string]Notifier
+}
+
+func NewPush(fcm, apns Notifier) *Push {
+	return &Push{providers: map[string]Notifier{
+		"android": fcm,
+		"ios":     apns,
+	}}
+}
+
+func (p *Push) Send(ctx context.Context, msg Message) error {
+	provider, ok := p.providers[msg.Platform]
+	if !ok {
+		return fmt.Errorf("%w: unknown platform %q", ErrInvalidAddress, msg.Platform)
+	}
+	return provider.Send(ctx, msg)
+}
+
+// Log stands in for a provider in development by logging each message
+// instead of sending it.
+type Log struct {
+	name string
+}
+
+// NewLog returns a stub provider that logs under name, such as "fcm".
+func NewLog(name string) *Log {
+	return &Log{name: name}
+}
+
+func (l *Log) Send(ctx context.Context, msg Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	log.Printf("[%s] to %s (%s): %s - %s", l.name, msg.UserID, redact(msg.Address), msg.Title, msg.Body)
+	return nil
+}
+
+// redact keeps the end of a token or phone number, enough to tell them
+// apart in logs.
+func redact(address string) string {
+	if len(address) <= 4 {
+		return address
+	}
+	return "…" + address[len(address)-4:]
+}
@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/namycodes/yanga-services/services/notification-service/internal/db"
+)
+
+type NotificationRepository struct {
+	queries *db.Queries
+}
+
+func NewNotificationRepository(queries *db.Queries) *NotificationRepository {
+	return &NotificationRepository{
+		queries: queries,
+	}
+}
+
+func (r *NotificationRepository) ClaimNotificationDelivery(ctx context.Context, params db.ClaimNotificationDeliveryParams) (db.NotificationDelivery, error) {
+	return r.queries.ClaimNotificationDelivery(ctx, params)
+}
+
+func (r *NotificationRepository) CountUnreadInboxMessages(ctx context.Context, userID pgtype.UUID) (int64, error) {
+	return r.queries.CountUnreadInboxMessages(ctx, userID)
+}
+
+func (r *NotificationRepository) CreateInboxMessage(ctx context.Context, params db.CreateInboxMessageParams) error {
+	return r.queries.CreateInboxMessage(ctx, params)
+}
+
+func (r *NotificationRepository) CreateNotificationDelivery(ctx context.Context, params db.CreateNotificationDeliveryParams) (db.NotificationDelivery, error) {
+	return r.queries.CreateNotificationDelivery(ctx, params)
+}
+
+func (r *NotificationRepository) DeleteDeviceToken(ctx context.Context, token string) error {
+	return r.queries.DeleteDeviceToken(ctx, token)
+}
+
+func (r *NotificationRepository) DeleteUserDeviceToken(ctx context.Context, params db.DeleteUserDeviceTokenParams) (int64, error) {
+	return r.queries.DeleteUserDeviceToken(ctx, params)
+}
+
+func (r *NotificationRepository) GetDriverVehicle(ctx context.Context, userID pgtype.UUID) (db.GetDriverVehicleRow, error) {
+	return r.queries.GetDriverVehicle(ctx, userID)
+}
+
+func (r *NotificationRepository) GetNotificationPreferences(ctx context.Context, userID pgtype.UUID) (db.NotificationPreference, error) {
+	return r.queries.GetNotificationPreferences(ctx, userID)
+}
+
+func (r *NotificationRepository) GetNotificationUser(ctx context.Context, id pgtype.UUID) (db.GetNotificationUserRow, error) {
+	return r.queries.GetNotificationUser(ctx, id)
+}
+
+func (r *NotificationRepository) GetTripParticipants(ctx context.Context, id pgtype.UUID) (db.GetTripParticipantsRow, error) {
+	return r.queries.GetTripParticipants(ctx, id)
+}
+
+func (r *NotificationRepository) ListDriversNearPickup(ctx context.Context, params db.ListDriversNearPickupParams) ([]pgtype.UUID, error) {
+	return r.queries.ListDriversNearPickup(ctx, params)
+}
+
+func (r *NotificationRepository) ListDueNotificationDeliveries(ctx context.Context, limit int32) ([]pgtype.UUID, error) {
+	return r.queries.ListDueNotificationDeliveries(ctx, limit)
+}
+
+func (r *NotificationRepository) ListInboxMessages(ctx context.Context, params db.ListInboxMessagesParams) ([]db.InboxMessage, error) {
+	return r.queries.ListInboxMessages(ctx, params)
+}
+
+func (r *NotificationRepository) ListUserDeviceTokens(ctx context.Context, userID pgtype.UUID) ([]db.DeviceToken, error) {
+	return r.queries.ListUserDeviceTokens(ctx, userID)
+}
+
+func (r *NotificationRepository) MarkAllInboxMessagesRead(ctx context.Context, userID pgtype.UUID) (int64, error) {
+	return r.queries.MarkAllInboxMessagesRead(ctx, userID)
+}
+
+func (r *NotificationRepository) MarkInboxMessageRead(ctx context.Context, params db.MarkInboxMessageReadParams) (int64, error) {
+	return r.queries.MarkInboxMessageRead(ctx, params)
+}
+
+func (r *NotificationRepository) MarkNotificationDeliveryFailed(ctx context.Context, params db.MarkNotificationDeliveryFailedParams) error {
+	return r.queries.MarkNotificationDeliveryFailed(ctx, params)
+}
+
+func (r *NotificationRepository) MarkNotificationDeliverySent(ctx context.Context, id pgtype.UUID) error {
+	return r.queries.MarkNotificationDeliverySent(ctx, id)
+}
+
+func (r *NotificationRepository) RegisterDeviceToken(ctx context.Context, params db.RegisterDeviceTokenParams) (db.DeviceToken, error) {
+	return r.queries.RegisterDeviceToken(ctx, params)
+}
+
+func (r *NotificationRepository) RetryNotificationDelivery(ctx context.Context, params db.RetryNotificationDeliveryParams) error {
+	return r.queries.RetryNotificationDelivery(ctx, params)
+}
+
+func (r *NotificationRepository) UpsertNotificationPreferences(ctx context.Context, params db.UpsertNotificationPreferencesParams) (db.NotificationPreference, error) {
+	return r.queries.UpsertNotificationPreferences(ctx, params)
+}
@@ -0,0 +1,31 @@
+package routes
+
+import (
+	"github.com/gorilla/mux"
+	"github.com/namycodes/yanga-services/services/notification-service/internal/handler"
+	"github.com/namycodes/yanga-services/shared-lib/config"
+	"github.com/namycodes/yanga-services/shared-lib/middleware"
+	httpSwagger "github.com/swaggo/http-swagger"
+)
+
+func SetupNotificationRoutes(router *mux.Router, notificationHandler *handler.NotificationHandler, jwtCfg config.JWTConfig) {
+	api := router.PathPrefix("/api/v1").Subrouter()
+
+	notifications := api.PathPrefix("/notifications").Subrouter()
+	notifications.Use(middleware.AuthMiddleware(jwtCfg.Secret, jwtCfg.IdentitySecret))
+
+	// Inbox
+	notifications.HandleFunc("", notificationHandler.GetInbox).Methods("GET")
+	notifications.HandleFunc("/read-all", notificationHandler.MarkAllRead).Methods("POST")
+	notifications.HandleFunc("/{id}/read", notificationHandler.MarkRead).Methods("PUT")
+
+	// Preferences
+	notifications.HandleFunc("/preferences", notificationHandler.GetPreferences).Methods("GET")
+	notifications.HandleFunc("/preferences", notificationHandler.UpdatePreferences).Methods("PUT")
+
+	// Push devices
+	notifications.HandleFunc("/devices", notificationHandler.RegisterDevice).Methods("POST")
+	notifications.HandleFunc("/devices/{id}", notificationHandler.UnregisterDevice).Methods("DELETE")
+
+	router.PathPrefix("/swagger/").Handler(httpSwagger.WrapHandler)
+}
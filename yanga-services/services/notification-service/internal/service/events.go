@@ -0,0 +1,485 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/namycodes/yanga-services/services/notification-service/internal/db"
+	"github.com/namycodes/yanga-services/shared-lib/domain"
+	"github.com/namycodes/yanga-services/shared-lib/events"
+	"github.com/namycodes/yanga-services/shared-lib/money"
+	"github.com/namycodes/yanga-services/shared-lib/utils"
+)
+
+const (
+	notificationQueue = "notification-service"
+	metersPerDegree   = 111320.0
+)
+
+// SubscribeToEvents notifies users of what happens to their account,
+// trips and money.
+func (s *NotificationService) SubscribeToEvents() {
+	subscribe(s, events.SubjectUserCreated, s.onUserCreated)
+	subscribe(s, events.SubjectTripCreated, s.onTripCreated)
+	subscribe(s, events.SubjectTripAccepted, s.onTripAccepted)
+	subscribe(s, events.SubjectDriverArrived, s.onDriverArrived)
+	subscribe(s, events.SubjectTripStarted, s.onTripStarted)
+	subscribe(s, events.SubjectTripCompleted, s.onTripCompleted)
+	subscribe(s, events.SubjectTripCancelled, s.onTripCancelled)
+	subscribe(s, events.SubjectTripScheduled, s.onTripScheduled)
+	subscribe(s, events.SubjectTripReminder, s.onTripReminder)
+	subscribe(s, events.SubjectTripStopArrived, s.onStopArrived)
+	subscribe(s, events.SubjectTripRouteUpdated, s.onRouteUpdated)
+	subscribe(s, events.SubjectTripPoolUpdated, s.onPoolUpdated)
+	subscribe(s, events.SubjectParcelPickedUp, s.parcelHandler(templateParcelPickedUp))
+	subscribe(s, events.SubjectParcelDelivered, s.parcelHandler(templateParcelDelivered))
+	subscribe(s, events.SubjectTripChatMessage, s.onChatMessage)
+	subscribe(s, events.SubjectSafetySOS, s.onSafetySOS)
+	subscribe(s, events.SubjectSafetyAlert, s.onSafetyAlert)
+	subscribe(s, events.SubjectSupportTicketCreated, s.onSupportTicketCreated)
+	subscribe(s, events.SubjectSupportTicketReplied, s.onSupportTicketReplied)
+	subscribe(s, events.SubjectSupportTicketResolved, s.onSupportTicketResolved)
+	subscribe(s, events.SubjectSupportAdjustmentSettled, s.onSupportAdjustmentSettled)
+	subscribe(s, events.SubjectSupportSLABreached, s.onSupportSLABreached)
+	subscribe(s, events.SubjectPaymentCompleted, s.onPaymentCompleted)
+	subscribe(s, events.SubjectPaymentFailed, s.onPaymentFailed)
+	subscribe(s, events.SubjectWalletToppedUp, s.onWalletToppedUp)
+	subscribe(s, events.SubjectEarningsRecorded, s.onEarningsRecorded)
+	subscribe(s, events.SubjectIncentiveEarned, s.onIncentiveEarned)
+	subscribe(s, events.SubjectReferralRewarded, s.onReferralRewarded)
+	subscribe(s, events.SubjectDriverQualityWarning, s.qualityHandler(templateQualityWarning))
+	subscribe(s, events.SubjectDriverQualitySuspended, s.qualityHandler(templateQualitySuspended))
+	subscribe(s, events.SubjectDriverQualityReview, s.qualityHandler(templateQualityReview))
+	subscribe(s, events.SubjectDriverQualityReinstated, s.qualityHandler(templateQualityReinstated))
+	subscribe(s, events.SubjectDisputeResolved, s.onDisputeResolved)
+}
+
+// subscribe decodes each event on subject and hands it to handle, logging
+// what can't be sent.
+func subscribe[T any](s *NotificationService, subject string, handle func(context.Context, T) error) {
+	s.eventBus.QueueSubscribe(subject, notificationQueue, func(ctx context.Context, data []byte) {
+		var event T
+		if err := json.Unmarshal(data, &event); err != nil {
+			log.Printf("Failed to unmarshal %s event: %v", subject, err)
+			return
+		}
+		if err := handle(ctx, event); err != nil {
+			log.Printf("Failed to send notifications for %s: %v", subject, err)
+		}
+	})
+}
+
+// notifyUser sends a notification to a user identified by a string ID
+// from an event.
+func (s *NotificationService) notifyUser(ctx context.Context, userID, template, key string, vars map[string]any, data map[string]string) error {
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return fmt.Errorf("invalid user ID %q: %w", userID, err)
+	}
+	return s.notify(ctx, notification{
+		userID:   id,
+		template: template,
+		key:      key,
+		vars:     vars,
+		data:     data,
+	})
+}
+
+func (s *NotificationService) onUserCreated(ctx context.Context, event events.UserCreatedPayload) error {
+	return s.notifyUser(ctx, event.UserID, templateWelcome, events.SubjectUserCreated+":"+event.UserID, nil, nil)
+}
+
+// onTripCreated offers a new trip to drivers: the head of the airport queue
+// when the trip came from one, otherwise the nearest online drivers who
+// can take it.
+func (s *NotificationService) onTripCreated(ctx context.Context, event events.TripCreatedEvent) error {
+	driverIDs := event.DriverIDs
+	if len(driverIDs) == 0 {
+		nearby, err := s.nearbyDrivers(ctx, event)
+		if err != nil {
+			return err
+		}
+		driverIDs = nearby
+	}
+
+	vars := map[string]any{"Fare": s.optionalAmount(event.EstimatedFare, event.Currency)}
+	data := map[string]string{"trip_id": event.TripID}
+	key := events.SubjectTripCreated + ":" + event.TripID
+
+	var errs []error
+	for _, driverID := range driverIDs {
+		if err := s.notifyUser(ctx, driverID, templateTripOffer, key, vars, data); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (s *NotificationService) nearbyDrivers(ctx context.Context, event events.TripCreatedEvent) ([]string, error) {
+	latDelta := s.offerRadius / metersPerDegree
+	lngDelta := s.offerRadius / (metersPerDegree * math.Max(math.Cos(event.PickupLatitude*math.Pi/180), 0.01))
+
+	vehicleTypes := event.VehicleTypes
+	if vehicleTypes == nil {
+		vehicleTypes = []string{}
+	}
+
+	drivers, err := s.repo.ListDriversNearPickup(ctx, db.ListDriversNearPickupParams{
+		MinLatitude:  event.PickupLatitude - latDelta,
+		MaxLatitude:  event.PickupLatitude + latDelta,
+		MinLongitude: event.PickupLongitude - lngDelta,
+		MaxLongitude: event.PickupLongitude + lngDelta,
+		VehicleTypes: vehicleTypes,
+		Latitude:     event.PickupLatitude,
+		Longitude:    event.PickupLongitude,
+		MaxDrivers:   s.offerLimit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find nearby drivers: %w", err)
+	}
+
+	driverIDs := make([]string, len(drivers))
+	for i, driverID := range drivers {
+		driverIDs[i] = utils.FromPgUUID(driverID).String()
+	}
+	return driverIDs, nil
+}
+
+func (s *NotificationService) onTripAccepted(ctx context.Context, event events.TripAcceptedEvent) error {
+	driverID, err := uuid.Parse(event.DriverID)
+	if err != nil {
+		return fmt.Errorf("invalid driver ID %q: %w", event.DriverID, err)
+	}
+	driver, err := s.repo.GetDriverVehicle(ctx, utils.ToPgUUID(driverID))
+	if err != nil {
+		return fmt.Errorf("failed to get driver: %w", err)
+	}
+
+	return s.notifyUser(ctx, event.UserID, templateTripAccepted, events.SubjectTripAccepted+":"+event.TripID, map[string]any{
+		"DriverName":   driver.FullName,
+		"VehicleColor": driver.VehicleColor,
+		"VehicleModel": driver.VehicleModel,
+		"PlateNumber":  driver.VehiclePlateNumber,
+	}, map[string]string{"trip_id": event.TripID, "driver_id": event.DriverID})
+}
+
+func (s *NotificationService) onDriverArrived(ctx context.Context, event events.DriverArrivedEvent) error {
+	return s.notifyUser(ctx, event.UserID, templateDriverArrived, events.SubjectDriverArrived+":"+event.TripID, nil, map[string]string{"trip_id": event.TripID})
+}
+
+// onTripStarted looks the rider up, as trip.started doesn't carry them.
+func (s *NotificationService) onTripStarted(ctx context.Context, event events.TripStartedEvent) error {
+	tripID, err := uuid.Parse(event.TripID)
+	if err != nil {
+		return fmt.Errorf("invalid trip ID %q: %w", event.TripID, err)
+	}
+	trip, err := s.repo.GetTripParticipants(ctx, utils.ToPgUUID(tripID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		return fmt.Errorf("failed to get trip: %w", err)
+	}
+
+	return s.notify(ctx, notification{
+		userID:   utils.FromPgUUID(trip.UserID),
+		template: templateTripStarted,
+		key:      events.SubjectTripStarted + ":" + event.TripID,
+		data:     map[string]string{"trip_id": event.TripID},
+	})
+}
+
+func (s *NotificationService) onTripCompleted(ctx context.Context, event events.TripCompletedEvent) error {
+	return s.notifyUser(ctx, event.UserID, templateTripCompleted, events.SubjectTripCompleted+":"+event.TripID, map[string]any{
+		"Fare": s.amount(event.ActualFare, event.Currency),
+	}, map[string]string{"trip_id": event.TripID})
+}
+
+// onTripCancelled tells whoever didn't cancel that the trip is off, and the
+// rider about any fee. A rider who didn't show up only hears about the fee.
+func (s *NotificationService) onTripCancelled(ctx context.Context, event events.TripCancelledEvent) error {
+	key := events.SubjectTripCancelled + ":" + event.TripID
+	data := map[string]string{"trip_id": event.TripID}
+
+	var errs []error
+	switch event.CancelledBy {
+	case domain.CancelledByRider:
+		if event.DriverID != "" {
+			errs = append(errs, s.notifyUser(ctx, event.DriverID, templateCancelledByRider, key, nil, data))
+		}
+	case domain.CancelledByDriver:
+		if event.NoShowParty != domain.NoShowRider {
+			errs = append(errs, s.notifyUser(ctx, event.UserID, templateCancelledByDriver, key, nil, data))
+		}
+	case domain.CancelledBySystem:
+		errs = append(errs, s.notifyUser(ctx, event.UserID, templateCancelledBySystem, key, nil, data))
+	}
+
+	if event.CancellationFee > 0 {
+		errs = append(errs, s.notifyUser(ctx, event.UserID, templateCancellationFee, key, map[string]any{
+			"Fee": s.amount(event.CancellationFee, event.Currency),
+		}, data))
+	}
+	return errors.Join(errs...)
+}
+
+// onTripScheduled shows the pickup time in the trip's city rather than
+// where the rider's preferences say they are.
+func (s *NotificationService) onTripScheduled(ctx context.Context, event events.TripScheduledEvent) error {
+	var pickupAt any = event.PickupAt
+	if location, err := time.LoadLocation(event.Timezone); err == nil && event.Timezone != "" {
+		pickupAt = formatTime(event.PickupAt, location)
+	}
+
+	return s.notifyUser(ctx, event.UserID, templateTripScheduled, events.SubjectTripScheduled+":"+event.TripID, map[string]any{
+		"PickupAddress": event.PickupAddress,
+		"PickupAt":      pickupAt,
+	}, map[string]string{"trip_id": event.TripID})
+}
+
+func (s *NotificationService) onTripReminder(ctx context.Context, event events.TripReminderEvent) error {
+	key := events.SubjectTripReminder + ":" + event.TripID
+	vars := map[string]any{
+		"PickupAddress": event.PickupAddress,
+		"PickupAt":      event.PickupAt,
+	}
+	data := map[string]string{"trip_id": event.TripID}
+
+	errs := []error{s.notifyUser(ctx, event.UserID, templateTripReminder, key, vars, data)}
+	if event.ReservedDriverID != "" {
+		errs = append(errs, s.notifyUser(ctx, event.ReservedDriverID, templateTripReminderDriver, key, vars, data))
+	}
+	return errors.Join(errs...)
+}
+
+func (s *NotificationService) onStopArrived(ctx context.Context, event events.TripStopArrivedEvent) error {
+	return s.notifyUser(ctx, event.UserID, templateStopArrived, events.SubjectTripStopArrived+":"+event.StopID, map[string]any{
+		"StopNumber": event.StopOrder,
+		"Address":    event.Address,
+	}, map[string]string{"trip_id": event.TripID})
+}
+
+// onRouteUpdated tells the driver the rider changed their stops. A route
+// can change many times, so each update is its own notification.
+func (s *NotificationService) onRouteUpdated(ctx context.Context, event events.TripRouteUpdatedEvent) error {
+	if event.DriverID == "" {
+		return nil
+	}
+	key := events.SubjectTripRouteUpdated + ":" + event.TripID + ":" + timestampKey(event.Timestamp)
+	return s.notifyUser(ctx, event.DriverID, templateRouteUpdated, key, map[string]any{
+		"StopCount": event.StopCount,
+	}, map[string]string{"trip_id": event.TripID})
+}
+
+func (s *NotificationService) onPoolUpdated(ctx context.Context, event events.TripPoolUpdatedEvent) error {
+	key := events.SubjectTripPoolUpdated + ":" + event.PoolID + ":" + event.TripID + ":" + event.Change
+	return s.notifyUser(ctx, event.DriverID, templatePoolUpdated, key, map[string]any{
+		"SeatsBooked": event.SeatsBooked,
+	}, map[string]string{"pool_id": event.PoolID, "trip_id": event.TripID})
+}
+
+func (s *NotificationService) parcelHandler(template string) func(context.Context, events.TripParcelEvent) error {
+	return func(ctx context.Context, event events.TripParcelEvent) error {
+		return s.notifyUser(ctx, event.UserID, template, template+":"+event.TripID, nil, map[string]string{"trip_id": event.TripID})
+	}
+}
+
+// onChatMessage pushes a chat message to the other side of the trip, for
+// when they don't have the chat open.
+func (s *NotificationService) onChatMessage(ctx context.Context, event events.TripChatMessageEvent) error {
+	return s.notifyUser(ctx, event.RecipientID, templateChatMessage, events.SubjectTripChatMessage+":"+event.MessageID, map[string]any{
+		"SenderRole": event.SenderRole,
+		"Body":       event.Body,
+	}, map[string]string{"trip_id": event.TripID, "message_id": event.MessageID})
+}
+
+// onSafetySOS texts the reporter's emergency contacts a link to follow
+// their trip, and lets the reporter know help is coming.
+func (s *NotificationService) onSafetySOS(ctx context.Context, event events.SafetySOSEvent) error {
+	reporterID, err := uuid.Parse(event.ReporterID)
+	if err != nil {
+		return fmt.Errorf("invalid reporter ID %q: %w", event.ReporterID, err)
+	}
+	key := events.SubjectSafetySOS + ":" + event.IncidentID
+
+	var errs []error
+	if len(event.Contacts) > 0 {
+		user, err := s.repo.GetNotificationUser(ctx, utils.ToPgUUID(reporterID))
+		if err != nil {
+			return fmt.Errorf("failed to get reporter: %w", err)
+		}
+		phoneNumbers := make([]string, len(event.Contacts))
+		for i, contact := range event.Contacts {
+			phoneNumbers[i] = contact.PhoneNumber
+		}
+		errs = append(errs, s.notifyContacts(ctx, reporterID, phoneNumbers, templateSOSContact, key, map[string]any{
+			"Name":     user.FullName,
+			"ShareURL": event.ShareURL,
+		}))
+	}
+	errs = append(errs, s.notifyUser(ctx, event.ReporterID, templateSOSRaised, key, map[string]any{
+		"ContactCount": len(event.Contacts),
+	}, map[string]string{"trip_id": event.TripID, "incident_id": event.IncidentID}))
+	return errors.Join(errs...)
+}
+
+// onSafetyAlert checks in with a rider whose trip looks wrong.
+func (s *NotificationService) onSafetyAlert(ctx context.Context, event events.SafetyAlertEvent) error {
+	return s.notifyUser(ctx, event.UserID, templateSafetyCheck, events.SubjectSafetyAlert+":"+event.IncidentID, map[string]any{
+		"Kind": event.Kind,
+	}, map[string]string{"trip_id": event.TripID, "incident_id": event.IncidentID})
+}
+
+// onSupportTicketCreated acknowledges a new ticket, and asks the driver to
+// look for a lost item.
+func (s *NotificationService) onSupportTicketCreated(ctx context.Context, event events.SupportTicketCreatedEvent) error {
+	key := events.SubjectSupportTicketCreated + ":" + event.TicketID
+	data := map[string]string{"ticket_id": event.TicketID}
+
+	errs := []error{s.notifyUser(ctx, event.UserID, templateSupportOpened, key, map[string]any{
+		"Subject": event.Subject,
+	}, data)}
+	if event.DriverID != "" {
+		errs = append(errs, s.notifyUser(ctx, event.DriverID, templateSupportLostItem, key, nil, data))
+	}
+	return errors.Join(errs...)
+}
+
+func (s *NotificationService) onSupportTicketReplied(ctx context.Context, event events.SupportTicketRepliedEvent) error {
+	var errs []error
+	for _, recipientID := range event.RecipientIDs {
+		errs = append(errs, s.notifyUser(ctx, recipientID, templateSupportReply, events.SubjectSupportTicketReplied+":"+event.MessageID, map[string]any{
+			"Subject": event.Subject,
+		}, map[string]string{"ticket_id": event.TicketID, "message_id": event.MessageID}))
+	}
+	return errors.Join(errs...)
+}
+
+func (s *NotificationService) onSupportTicketResolved(ctx context.Context, event events.SupportTicketResolvedEvent) error {
+	var amount *float64
+	if event.Amount > 0 {
+		amount = &event.Amount
+	}
+	return s.notifyUser(ctx, event.UserID, templateSupportResolved, events.SubjectSupportTicketResolved+":"+event.TicketID, map[string]any{
+		"Note":   event.Note,
+		"Amount": s.optionalAmount(amount, event.Currency),
+	}, map[string]string{"ticket_id": event.TicketID})
+}
+
+// onSupportAdjustmentSettled tells the rider once a refund or fare
+// adjustment is in their wallet. Failures are left to the agent.
+func (s *NotificationService) onSupportAdjustmentSettled(ctx context.Context, event events.SupportAdjustmentSettledEvent) error {
+	if event.Error != "" {
+		return nil
+	}
+	return s.notifyUser(ctx, event.UserID, templateSupportRefund, events.SubjectSupportAdjustmentSettled+":"+event.TicketID, map[string]any{
+		"Amount": s.amount(event.Amount, event.Currency),
+	}, map[string]string{"ticket_id": event.TicketID, "transaction_id": event.TransactionID})
+}
+
+// onSupportSLABreached warns the agent a ticket is assigned to. Unassigned
+// tickets show as breached in the queue.
+func (s *NotificationService) onSupportSLABreached(ctx context.Context, event events.SupportSLABreachedEvent) error {
+	if event.AssignedTo == "" {
+		return nil
+	}
+	return s.notifyUser(ctx, event.AssignedTo, templateSupportOverdue, events.SubjectSupportSLABreached+":"+event.TicketID+":"+event.Deadline, map[string]any{
+		"Priority": event.Priority,
+		"Category": strings.ReplaceAll(event.Category, "_", " "),
+		"Deadline": event.Deadline,
+	}, map[string]string{"ticket_id": event.TicketID})
+}
+
+func (s *NotificationService) onPaymentCompleted(ctx context.Context, event events.PaymentCompletedEvent) error {
+	return s.notifyUser(ctx, event.UserID, templatePaymentCompleted, events.SubjectPaymentCompleted+":"+event.TransactionID, map[string]any{
+		"Amount": s.amount(event.Amount, ""),
+	}, map[string]string{"trip_id": event.TripID})
+}
+
+func (s *NotificationService) onPaymentFailed(ctx context.Context, event events.PaymentFailedEvent) error {
+	key := events.SubjectPaymentFailed + ":" + event.TripID + ":" + timestampKey(event.Timestamp)
+	return s.notifyUser(ctx, event.UserID, templatePaymentFailed, key, map[string]any{
+		"Amount": s.amount(event.Amount, ""),
+	}, map[string]string{"trip_id": event.TripID})
+}
+
+func (s *NotificationService) onWalletToppedUp(ctx context.Context, event events.WalletToppedUpEvent) error {
+	return s.notifyUser(ctx, event.UserID, templateWalletToppedUp, events.SubjectWalletToppedUp+":"+event.TransactionID, map[string]any{
+		"Amount":  s.amount(event.Amount, ""),
+		"Balance": s.amount(event.Balance, ""),
+	}, nil)
+}
+
+func (s *NotificationService) onEarningsRecorded(ctx context.Context, event events.EarningsRecordedEvent) error {
+	return s.notifyUser(ctx, event.DriverID, templateEarningsRecorded, events.SubjectEarningsRecorded+":"+event.TripID, map[string]any{
+		"Amount": s.amount(event.NetEarnings, event.Currency),
+	}, map[string]string{"trip_id": event.TripID})
+}
+
+func (s *NotificationService) onIncentiveEarned(ctx context.Context, event events.IncentiveEarnedEvent) error {
+	key := events.SubjectIncentiveEarned + ":" + event.ProgramID + ":" + event.TripID + ":" + timestampKey(event.Timestamp)
+	return s.notifyUser(ctx, event.DriverID, templateIncentiveEarned, key, map[string]any{
+		"Amount": s.amount(event.Amount, event.Currency),
+	}, map[string]string{"program_id": event.ProgramID})
+}
+
+func (s *NotificationService) onReferralRewarded(ctx context.Context, event events.ReferralRewardedEvent) error {
+	if event.ReferrerReward <= 0 {
+		return nil
+	}
+	return s.notifyUser(ctx, event.ReferrerID, templateReferralRewarded, events.SubjectReferralRewarded+":"+event.ReferralID, map[string]any{
+		"Amount": s.amount(event.ReferrerReward, ""),
+	}, nil)
+}
+
+func (s *NotificationService) qualityHandler(template string) func(context.Context, events.DriverQualityEvent) error {
+	return func(ctx context.Context, event events.DriverQualityEvent) error {
+		vars := map[string]any{"Score": strconv.FormatFloat(event.Score, 'f', 0, 64)}
+		if event.SuspendedUntil != nil {
+			vars["Until"] = *event.SuspendedUntil
+		}
+		key := template + ":" + event.DriverID + ":" + timestampKey(event.Timestamp)
+		return s.notifyUser(ctx, event.DriverID, template, key, vars, nil)
+	}
+}
+
+func (s *NotificationService) onDisputeResolved(ctx context.Context, event events.RatingDisputeResolvedEvent) error {
+	template := templateDisputeRejected
+	if event.Status == domain.DisputeStatusAccepted {
+		template = templateDisputeAccepted
+	}
+	return s.notifyUser(ctx, event.DisputedBy, template, events.SubjectDisputeResolved+":"+event.DisputeID, nil, map[string]string{"rating_id": event.RatingID})
+}
+
+// amount is an event's amount in its currency, or the default currency for
+// events that don't say.
+func (s *NotificationService) amount(value float64, currency string) money.Money {
+	c := s.defaultCurrency
+	if currency != "" {
+		c = money.Currency(currency)
+	}
+	return money.FromFloat(value, c)
+}
+
+func (s *NotificationService) optionalAmount(value *float64, currency string) *money.Money {
+	if value == nil {
+		return nil
+	}
+	amount := s.amount(*value, currency)
+	return &amount
+}
+
+// timestampKey tells apart repeats of an event that can happen more than
+// once for the same trip or driver.
+func timestampKey(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
@@ -0,0 +1,641 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/namycodes/yanga-services/services/notification-service/internal/db"
+	"github.com/namycodes/yanga-services/services/notification-service/internal/notifier"
+	"github.com/namycodes/yanga-services/services/notification-service/internal/repository"
+	"github.com/namycodes/yanga-services/shared-lib/config"
+	"github.com/namycodes/yanga-services/shared-lib/domain"
+	"github.com/namycodes/yanga-services/shared-lib/events"
+	"github.com/namycodes/yanga-services/shared-lib/money"
+	"github.com/namycodes/yanga-services/shared-lib/utils"
+)
+
+const (
+	// deliveryLease is how long a delivery being sent is hidden from other
+	// workers. A worker that dies mid-send leaves it to be retried after.
+	deliveryLease        = time.Minute
+	retryBatchSize       = 100
+	maxDeviceTokenLength = 512
+)
+
+var (
+	ErrInvalidDevice      = errors.New("invalid device")
+	ErrDeviceNotFound     = errors.New("device not found")
+	ErrInvalidPreferences = errors.New("invalid notification preferences")
+	ErrMessageNotFound    = errors.New("message not found")
+)
+
+var (
+	localePattern    = regexp.MustCompile(`^[a-z]{2}(-[A-Z]{2})?$`)
+	quietHourPattern = regexp.MustCompile(`^([01]\d|2[0-3]):([0-5]\d)$`)
+)
+
+// NotificationService tells riders and drivers about their trips, payments
+// and accounts. Each notification goes to the user's inbox and, unless
+// they've turned it off, to every device they've registered for push. When
+// a user can't get push, the most important notifications go by SMS.
+type NotificationService struct {
+	repo            *repository.NotificationRepository
+	notifiers       map[string]notifier.Notifier
+	eventBus        events.EventBus
+	defaultLocale   string
+	defaultLocation *time.Location
+	defaultCurrency money.Currency
+	maxAttempts     int32
+	retryDelay      time.Duration
+	offerRadius     float64
+	offerLimit      int32
+}
+
+// NewNotificationService sends each channel's notifications with the
+// notifier registered for it in notifiers.
+func NewNotificationService(repo *repository.NotificationRepository, notifiers map[string]notifier.Notifier, eventBus events.EventBus, cfg *config.Config) *NotificationService {
+	location, err := time.LoadLocation(cfg.NotificationDefaultTimezone)
+	if err != nil {
+		log.Printf("Unknown timezone %s, using UTC: %v", cfg.NotificationDefaultTimezone, err)
+		location = time.UTC
+	}
+
+	return &NotificationService{
+		repo:            repo,
+		notifiers:       notifiers,
+		eventBus:        eventBus,
+		defaultLocale:   cfg.NotificationDefaultLocale,
+		defaultLocation: location,
+		defaultCurrency: money.Currency(cfg.DefaultCurrency),
+		maxAttempts:     int32(cfg.NotificationMaxAttempts),
+		retryDelay:      time.Duration(cfg.NotificationRetrySeconds) * time.Second,
+		offerRadius:     float64(cfg.NotificationOfferRadiusMeters),
+		offerLimit:      int32(cfg.NotificationOfferLimit),
+	}
+}
+
+// notification is something to tell a user. key identifies the event it
+// is for, so an event delivered twice is only sent once. data is passed
+// to the app alongside the text, such as the trip to open.
+type notification struct {
+	userID   uuid.UUID
+	template string
+	key      string
+	vars     map[string]any
+	data     map[string]string
+}
+
+// preferences are a user's notification preferences with the defaults
+// filled in.
+type preferences struct {
+	push       bool
+	sms        bool
+	locale     string
+	timezone   string
+	location   *time.Location
+	quietStart pgtype.Int2
+	quietEnd   pgtype.Int2
+	muted      []string
+}
+
+// notify renders a notification in the recipient's language and queues it
+// on each channel they get it on. Deliveries that are due are attempted
+// straight away; those held back by quiet hours, and failures, are left to
+// the retry worker.
+func (s *NotificationService) notify(ctx context.Context, n notification) error {
+	tmpl, ok := templates[n.template]
+	if !ok {
+		return fmt.Errorf("unknown notification template %q", n.template)
+	}
+
+	pgUserID := utils.ToPgUUID(n.userID)
+	user, err := s.repo.GetNotificationUser(ctx, pgUserID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			log.Printf("Skipping %s notification for unknown user %s", n.template, n.userID)
+			return nil
+		}
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if user.IsActive.Valid && !user.IsActive.Bool {
+		return nil
+	}
+
+	prefs, err := s.preferences(ctx, pgUserID)
+	if err != nil {
+		return err
+	}
+
+	title, body, err := render(n.template, prefs.locale, prefs.location, n.vars)
+	if err != nil {
+		return err
+	}
+	var data []byte
+	if len(n.data) > 0 {
+		if data, err = json.Marshal(n.data); err != nil {
+			return fmt.Errorf("failed to encode notification data: %w", err)
+		}
+	}
+
+	devices, err := s.repo.ListUserDeviceTokens(ctx, pgUserID)
+	if err != nil {
+		return fmt.Errorf("failed to get devices: %w", err)
+	}
+
+	// Trip and account notifications can't be muted and aren't held for
+	// quiet hours.
+	now := time.Now().UTC()
+	mutable := slices.Contains(domain.MutableNotificationCategories, tmpl.category)
+	muted := mutable && slices.Contains(prefs.muted, tmpl.category)
+	sendAt := now
+	if mutable {
+		sendAt = prefs.quietHoursEnd(now)
+	}
+
+	params := db.CreateNotificationDeliveryParams{
+		UserID:        pgUserID,
+		EventKey:      n.key + ":" + n.template,
+		Template:      n.template,
+		Category:      tmpl.category,
+		Channel:       domain.NotificationChannelInbox,
+		Title:         title,
+		Body:          body,
+		Data:          data,
+		NextAttemptAt: pgtype.Timestamp{Time: now, Valid: true},
+	}
+	deliveries := []db.CreateNotificationDeliveryParams{params}
+
+	params.NextAttemptAt = pgtype.Timestamp{Time: sendAt, Valid: true}
+	pushed := false
+	if prefs.push && !muted {
+		for _, device := range devices {
+			push := params
+			push.Channel = domain.NotificationChannelPush
+			push.Address = device.Token
+			push.Platform = pgtype.Text{String: device.Platform, Valid: true}
+			deliveries = append(deliveries, push)
+			pushed = true
+		}
+	}
+	if tmpl.sms && prefs.sms && !muted && !pushed && user.PhoneNumber != "" {
+		sms := params
+		sms.Channel = domain.NotificationChannelSMS
+		sms.Address = user.PhoneNumber
+		deliveries = append(deliveries, sms)
+	}
+
+	for _, params := range deliveries {
+		delivery, err := s.repo.CreateNotificationDelivery(ctx, params)
+		if err != nil {
+			// Already sent for this event.
+			if errors.Is(err, pgx.ErrNoRows) {
+				continue
+			}
+			return fmt.Errorf("failed to queue %s notification: %w", params.Channel, err)
+		}
+		if !delivery.NextAttemptAt.Time.After(now) {
+			s.attempt(ctx, delivery.ID)
+		}
+	}
+	return nil
+}
+
+// notifyContacts texts people outside Yanga on a user's behalf, such as
+// their emergency contacts, in the user's language. The deliveries are
+// recorded against the user, addressed to each phone number, and aren't
+// held for quiet hours or preferences, which are the user's, not theirs.
+func (s *NotificationService) notifyContacts(ctx context.Context, userID uuid.UUID, phoneNumbers []string, template, key string, vars map[string]any) error {
+	tmpl, ok := templates[template]
+	if !ok {
+		return fmt.Errorf("unknown notification template %q", template)
+	}
+
+	pgUserID := utils.ToPgUUID(userID)
+	prefs, err := s.preferences(ctx, pgUserID)
+	if err != nil {
+		return err
+	}
+	title, body, err := render(template, prefs.locale, prefs.location, vars)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	for _, phoneNumber := range phoneNumbers {
+		delivery, err := s.repo.CreateNotificationDelivery(ctx, db.CreateNotificationDeliveryParams{
+			UserID:        pgUserID,
+			EventKey:      key + ":" + template,
+			Template:      template,
+			Category:      tmpl.category,
+			Channel:       domain.NotificationChannelSMS,
+			Address:       phoneNumber,
+			Title:         title,
+			Body:          body,
+			NextAttemptAt: pgtype.Timestamp{Time: now, Valid: true},
+		})
+		if err != nil {
+			// Already sent for this event.
+			if errors.Is(err, pgx.ErrNoRows) {
+				continue
+			}
+			return fmt.Errorf("failed to queue sms notification: %w", err)
+		}
+		s.attempt(ctx, delivery.ID)
+	}
+	return nil
+}
+
+// attempt sends a due delivery. Failures are retried after a wait that
+// doubles each time, until NotificationMaxAttempts. Addresses that will
+// never work, such as unregistered push tokens, aren't retried, and the
+// token is forgotten.
+func (s *NotificationService) attempt(ctx context.Context, deliveryID pgtype.UUID) {
+	now := time.Now().UTC()
+	delivery, err := s.repo.ClaimNotificationDelivery(ctx, db.ClaimNotificationDeliveryParams{
+		ID:         deliveryID,
+		LeaseUntil: pgtype.Timestamp{Time: now.Add(deliveryLease), Valid: true},
+	})
+	if err != nil {
+		// Sent, or being sent by another worker.
+		if !errors.Is(err, pgx.ErrNoRows) {
+			log.Printf("Failed to claim notification %s: %v", utils.FromPgUUID(deliveryID), err)
+		}
+		return
+	}
+
+	n, ok := s.notifiers[delivery.Channel]
+	if !ok {
+		s.fail(ctx, delivery, fmt.Errorf("no notifier for channel %s", delivery.Channel))
+		return
+	}
+
+	sendErr := n.Send(ctx, toMessage(delivery))
+	switch {
+	case sendErr == nil:
+		if err := s.repo.MarkNotificationDeliverySent(ctx, delivery.ID); err != nil {
+			log.Printf("Failed to mark notification %s sent: %v", utils.FromPgUUID(delivery.ID), err)
+		}
+	case errors.Is(sendErr, notifier.ErrInvalidAddress):
+		s.fail(ctx, delivery, sendErr)
+		if delivery.Channel == domain.NotificationChannelPush {
+			if err := s.repo.DeleteDeviceToken(ctx, delivery.Address); err != nil {
+				log.Printf("Failed to remove invalid device token: %v", err)
+			}
+		}
+	case delivery.Attempts >= s.maxAttempts:
+		s.fail(ctx, delivery, sendErr)
+	default:
+		retryAt := now.Add(s.retryDelay << (delivery.Attempts - 1))
+		if err := s.repo.RetryNotificationDelivery(ctx, db.RetryNotificationDeliveryParams{
+			ID:            delivery.ID,
+			LastError:     pgtype.Text{String: sendErr.Error(), Valid: true},
+			NextAttemptAt: pgtype.Timestamp{Time: retryAt, Valid: true},
+		}); err != nil {
+			log.Printf("Failed to schedule retry of notification %s: %v", utils.FromPgUUID(delivery.ID), err)
+		}
+	}
+}
+
+func (s *NotificationService) fail(ctx context.Context, delivery db.NotificationDelivery, cause error) {
+	log.Printf("Giving up on %s notification %s after %d attempts: %v", delivery.Channel, utils.FromPgUUID(delivery.ID), delivery.Attempts, cause)
+	if err := s.repo.MarkNotificationDeliveryFailed(ctx, db.MarkNotificationDeliveryFailedParams{
+		ID:        delivery.ID,
+		LastError: pgtype.Text{String: cause.Error(), Valid: true},
+	}); err != nil {
+		log.Printf("Failed to mark notification %s failed: %v", utils.FromPgUUID(delivery.ID), err)
+	}
+}
+
+// RunRetryWorker sends deliveries that are due, whether retries or held
+// back by quiet hours, every NOTIFICATION_RETRY_SECONDS until ctx is
+// cancelled.
+func (s *NotificationService) RunRetryWorker(ctx context.Context) {
+	if s.retryDelay <= 0 {
+		return
+	}
+	ticker := time.NewTicker(s.retryDelay)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.sendDue(ctx)
+		}
+	}
+}
+
+func (s *NotificationService) sendDue(ctx context.Context) {
+	due, err := s.repo.ListDueNotificationDeliveries(ctx, retryBatchSize)
+	if err != nil {
+		log.Printf("Failed to list due notifications: %v", err)
+		return
+	}
+
+	for _, deliveryID := range due {
+		if ctx.Err() != nil {
+			return
+		}
+		s.attempt(ctx, deliveryID)
+	}
+}
+
+// GetPreferences returns a user's notification preferences, or the
+// defaults if they haven't set any.
+func (s *NotificationService) GetPreferences(ctx context.Context, userID uuid.UUID) (*domain.NotificationPreferencesResponse, error) {
+	prefs, err := s.preferences(ctx, utils.ToPgUUID(userID))
+	if err != nil {
+		return nil, err
+	}
+	return prefs.toResponse(), nil
+}
+
+// UpdatePreferences replaces a user's notification preferences.
+func (s *NotificationService) UpdatePreferences(ctx context.Context, userID uuid.UUID, req *domain.NotificationPreferencesRequest) (*domain.NotificationPreferencesResponse, error) {
+	params := db.UpsertNotificationPreferencesParams{
+		UserID:          utils.ToPgUUID(userID),
+		PushEnabled:     req.PushEnabled,
+		SmsEnabled:      req.SMSEnabled,
+		MutedCategories: []string{},
+	}
+
+	if locale := strings.TrimSpace(req.Locale); locale != "" {
+		if !localePattern.MatchString(locale) {
+			return nil, fmt.Errorf("%w: locale must look like en or sw-KE", ErrInvalidPreferences)
+		}
+		params.Locale = pgtype.Text{String: locale, Valid: true}
+	}
+	if timezone := strings.TrimSpace(req.Timezone); timezone != "" {
+		if _, err := time.LoadLocation(timezone); err != nil {
+			return nil, fmt.Errorf("%w: unknown timezone %s", ErrInvalidPreferences, timezone)
+		}
+		params.Timezone = pgtype.Text{String: timezone, Valid: true}
+	}
+
+	if req.QuietHoursStart != "" || req.QuietHoursEnd != "" {
+		start, ok := parseQuietHour(req.QuietHoursStart)
+		end, endOK := parseQuietHour(req.QuietHoursEnd)
+		if !ok || !endOK {
+			return nil, fmt.Errorf("%w: quiet hours need a start and end as HH:MM", ErrInvalidPreferences)
+		}
+		if start == end {
+			return nil, fmt.Errorf("%w: quiet hours can't start and end at the same time", ErrInvalidPreferences)
+		}
+		params.QuietHoursStart = pgtype.Int2{Int16: start, Valid: true}
+		params.QuietHoursEnd = pgtype.Int2{Int16: end, Valid: true}
+	}
+
+	for _, category := range req.MutedCategories {
+		category = strings.ToLower(strings.TrimSpace(category))
+		if !slices.Contains(domain.MutableNotificationCategories, category) {
+			return nil, fmt.Errorf("%w: only %s notifications can be muted", ErrInvalidPreferences, strings.Join(domain.MutableNotificationCategories, ", "))
+		}
+		if !slices.Contains(params.MutedCategories, category) {
+			params.MutedCategories = append(params.MutedCategories, category)
+		}
+	}
+
+	saved, err := s.repo.UpsertNotificationPreferences(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save preferences: %w", err)
+	}
+	return s.withDefaults(saved).toResponse(), nil
+}
+
+// RegisterDevice records a device's push token for a user. Registering a
+// token that belongs to someone else, as after signing in as another user
+// on the same phone, moves it.
+func (s *NotificationService) RegisterDevice(ctx context.Context, userID uuid.UUID, req *domain.RegisterDeviceRequest) (*domain.DeviceResponse, error) {
+	token := strings.TrimSpace(req.Token)
+	if token == "" || len(token) > maxDeviceTokenLength {
+		return nil, fmt.Errorf("%w: token is required and limited to %d characters", ErrInvalidDevice, maxDeviceTokenLength)
+	}
+	platform := strings.ToLower(strings.TrimSpace(req.Platform))
+	if platform != domain.DevicePlatformIOS && platform != domain.DevicePlatformAndroid {
+		return nil, fmt.Errorf("%w: platform must be ios or android", ErrInvalidDevice)
+	}
+
+	device, err := s.repo.RegisterDeviceToken(ctx, db.RegisterDeviceTokenParams{
+		UserID:   utils.ToPgUUID(userID),
+		Token:    token,
+		Platform: platform,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to register device: %w", err)
+	}
+	return &domain.DeviceResponse{
+		ID:        utils.FromPgUUID(device.ID).String(),
+		Platform:  device.Platform,
+		CreatedAt: device.CreatedAt.Time,
+		UpdatedAt: device.UpdatedAt.Time,
+	}, nil
+}
+
+// UnregisterDevice stops push notifications to one of a user's devices,
+// such as when they sign out.
+func (s *NotificationService) UnregisterDevice(ctx context.Context, userID, deviceID uuid.UUID) error {
+	deleted, err := s.repo.DeleteUserDeviceToken(ctx, db.DeleteUserDeviceTokenParams{
+		ID:     utils.ToPgUUID(deviceID),
+		UserID: utils.ToPgUUID(userID),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to unregister device: %w", err)
+	}
+	if deleted == 0 {
+		return ErrDeviceNotFound
+	}
+	return nil
+}
+
+// GetInbox returns a user's inbox, newest first, and how many messages
+// they haven't read.
+func (s *NotificationService) GetInbox(ctx context.Context, userID uuid.UUID, limit, offset int32) (*domain.InboxResponse, error) {
+	pgUserID := utils.ToPgUUID(userID)
+	messages, err := s.repo.ListInboxMessages(ctx, db.ListInboxMessagesParams{
+		UserID: pgUserID,
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get inbox: %w", err)
+	}
+
+	unread, err := s.repo.CountUnreadInboxMessages(ctx, pgUserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count unread messages: %w", err)
+	}
+
+	response := &domain.InboxResponse{
+		Messages:    make([]domain.InboxMessageResponse, len(messages)),
+		UnreadCount: unread,
+	}
+	for i, message := range messages {
+		response.Messages[i] = toInboxMessageResponse(message)
+	}
+	return response, nil
+}
+
+// MarkRead marks one of a user's inbox messages read.
+func (s *NotificationService) MarkRead(ctx context.Context, userID, messageID uuid.UUID) error {
+	updated, err := s.repo.MarkInboxMessageRead(ctx, db.MarkInboxMessageReadParams{
+		ID:     utils.ToPgUUID(messageID),
+		UserID: utils.ToPgUUID(userID),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to mark message read: %w", err)
+	}
+	if updated == 0 {
+		return ErrMessageNotFound
+	}
+	return nil
+}
+
+// MarkAllRead marks every message in a user's inbox read.
+func (s *NotificationService) MarkAllRead(ctx context.Context, userID uuid.UUID) error {
+	if _, err := s.repo.MarkAllInboxMessagesRead(ctx, utils.ToPgUUID(userID)); err != nil {
+		return fmt.Errorf("failed to mark messages read: %w", err)
+	}
+	return nil
+}
+
+func (s *NotificationService) preferences(ctx context.Context, userID pgtype.UUID) (preferences, error) {
+	saved, err := s.repo.GetNotificationPreferences(ctx, userID)
+	if err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return preferences{}, fmt.Errorf("failed to get preferences: %w", err)
+		}
+		saved = db.NotificationPreference{PushEnabled: true, SmsEnabled: true}
+	}
+	return s.withDefaults(saved), nil
+}
+
+func (s *NotificationService) withDefaults(saved db.NotificationPreference) preferences {
+	prefs := preferences{
+		push:       saved.PushEnabled,
+		sms:        saved.SmsEnabled,
+		locale:     s.defaultLocale,
+		timezone:   s.defaultLocation.String(),
+		location:   s.defaultLocation,
+		quietStart: saved.QuietHoursStart,
+		quietEnd:   saved.QuietHoursEnd,
+		muted:      saved.MutedCategories,
+	}
+	if saved.Locale.Valid {
+		prefs.locale = saved.Locale.String
+	}
+	if saved.Timezone.Valid {
+		if location, err := time.LoadLocation(saved.Timezone.String); err == nil {
+			prefs.timezone = saved.Timezone.String
+			prefs.location = location
+		}
+	}
+	return prefs
+}
+
+// quietHoursEnd returns when quiet hours that now falls in end, or now if
+// it's outside them. Quiet hours may run past midnight, such as 22:00 to
+// 07:00.
+func (p preferences) quietHoursEnd(now time.Time) time.Time {
+	if !p.quietStart.Valid || !p.quietEnd.Valid {
+		return now
+	}
+	local := now.In(p.location)
+	minute := int16(local.Hour()*60 + local.Minute())
+	start, end := p.quietStart.Int16, p.quietEnd.Int16
+
+	quiet := minute >= start && minute < end
+	if start > end {
+		quiet = minute >= start || minute < end
+	}
+	if !quiet {
+		return now
+	}
+
+	year, month, day := local.Date()
+	endsAt := time.Date(year, month, day, int(end/60), int(end%60), 0, 0, p.location)
+	if !endsAt.After(local) {
+		endsAt = endsAt.AddDate(0, 0, 1)
+	}
+	return endsAt.UTC()
+}
+
+func (p preferences) toResponse() *domain.NotificationPreferencesResponse {
+	response := &domain.NotificationPreferencesResponse{
+		PushEnabled:     p.push,
+		SMSEnabled:      p.sms,
+		Locale:          p.locale,
+		Timezone:        p.timezone,
+		MutedCategories: p.muted,
+	}
+	if response.MutedCategories == nil {
+		response.MutedCategories = []string{}
+	}
+	if p.quietStart.Valid && p.quietEnd.Valid {
+		response.QuietHoursStart = formatQuietHour(p.quietStart.Int16)
+		response.QuietHoursEnd = formatQuietHour(p.quietEnd.Int16)
+	}
+	return response
+}
+
+// parseQuietHour reads "HH:MM" as minutes after midnight.
+func parseQuietHour(value string) (int16, bool) {
+	match := quietHourPattern.FindStringSubmatch(strings.TrimSpace(value))
+	if match == nil {
+		return 0, false
+	}
+	hours := int16(match[1][0]-'0')*10 + int16(match[1][1]-'0')
+	minutes := int16(match[2][0]-'0')*10 + int16(match[2][1]-'0')
+	return hours*60 + minutes, true
+}
+
+func formatQuietHour(minutes int16) string {
+	return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+}
+
+func toMessage(delivery db.NotificationDelivery) notifier.Message {
+	msg := notifier.Message{
+		DeliveryID: utils.FromPgUUID(delivery.ID),
+		UserID:     utils.FromPgUUID(delivery.UserID),
+		Address:    delivery.Address,
+		Platform:   delivery.Platform.String,
+		Category:   delivery.Category,
+		Title:      delivery.Title,
+		Body:       delivery.Body,
+	}
+	if len(delivery.Data) > 0 {
+		if err := json.Unmarshal(delivery.Data, &msg.Data); err != nil {
+			log.Printf("Ignoring unreadable data on notification %s: %v", utils.FromPgUUID(delivery.ID), err)
+		}
+	}
+	return msg
+}
+
+func toInboxMessageResponse(message db.InboxMessage) domain.InboxMessageResponse {
+	response := domain.InboxMessageResponse{
+		ID:        utils.FromPgUUID(message.ID).String(),
+		Category:  message.Category,
+		Title:     message.Title,
+		Body:      message.Body,
+		CreatedAt: message.CreatedAt.Time,
+	}
+	if len(message.Data) > 0 {
+		if err := json.Unmarshal(message.Data, &response.Data); err != nil {
+			log.Printf("Ignoring unreadable data on inbox message %s: %v", response.ID, err)
+		}
+	}
+	if message.ReadAt.Valid {
+		response.ReadAt = &message.ReadAt.Time
+	}
+	return response
+}
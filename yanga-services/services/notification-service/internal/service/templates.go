@@ -0,0 +1,329 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/namycodes/yanga-services/shared-lib/domain"
+	"github.com/namycodes/yanga-services/shared-lib/money"
+)
+
+// Template names
+const (
+	templateWelcome            = "welcome"
+	templateTripOffer          = "trip_offer"
+	templateTripAccepted       = "trip_accepted"
+	templateDriverArrived      = "driver_arrived"
+	templateTripStarted        = "trip_started"
+	templateTripCompleted      = "trip_completed"
+	templateCancelledByRider   = "trip_cancelled_by_rider"
+	templateCancelledByDriver  = "trip_cancelled_by_driver"
+	templateCancelledBySystem  = "trip_cancelled_by_system"
+	templateCancellationFee    = "trip_cancellation_fee"
+	templateTripScheduled      = "trip_scheduled"
+	templateTripReminder       = "trip_reminder"
+	templateTripReminderDriver = "trip_reminder_driver"
+	templateStopArrived        = "stop_arrived"
+	templateRouteUpdated       = "route_updated"
+	templatePoolUpdated        = "pool_updated"
+	templateParcelPickedUp     = "parcel_picked_up"
+	templateParcelDelivered    = "parcel_delivered"
+	templateChatMessage        = "chat_message"
+	templatePaymentCompleted   = "payment_completed"
+	templatePaymentFailed      = "payment_failed"
+	templateWalletToppedUp     = "wallet_topped_up"
+	templateEarningsRecorded   = "earnings_recorded"
+	templateIncentiveEarned    = "incentive_earned"
+	templateReferralRewarded   = "referral_rewarded"
+	templateQualityWarning     = "quality_warning"
+	templateQualitySuspended   = "quality_suspended"
+	templateQualityReview      = "quality_review"
+	templateQualityReinstated  = "quality_reinstated"
+	templateDisputeAccepted    = "dispute_accepted"
+	templateDisputeRejected    = "dispute_rejected"
+	templateSOSRaised          = "sos_raised"
+	templateSOSContact         = "sos_contact"
+	templateSafetyCheck        = "safety_check"
+	templateSupportOpened      = "support_ticket_opened"
+	templateSupportLostItem    = "support_lost_item"
+	templateSupportReply       = "support_reply"
+	templateSupportResolved    = "support_resolved"
+	templateSupportRefund      = "support_refund"
+	templateSupportOverdue     = "support_overdue"
+)
+
+const (
+	defaultTemplateLanguage = "en"
+	// Times later today are written without the date.
+	templateTimeLayout      = "Mon 2 Jan, 15:04"
+	templateTimeLayoutToday = "15:04"
+)
+
+// notificationTemplate is the text of one kind of notification in each
+// language we support. Languages without a translation get English.
+type notificationTemplate struct {
+	category string
+	// sms marks notifications worth an SMS when the user can't get them by
+	// push.
+	sms  bool
+	text map[string]templateText
+}
+
+type templateText struct {
+	title string
+	body  string
+}
+
+var templates = map[string]notificationTemplate{
+	templateWelcome: {category: domain.NotificationCategoryAccount, sms: true, text: map[string]templateText{
+		"en": {"Welcome to Yanga", "Your account is ready. Where are you headed?"},
+		"sw": {"Karibu Yanga", "Akaunti yako iko tayari. Unaelekea wapi?"},
+	}},
+	templateTripOffer: {category: domain.NotificationCategoryTrip, text: map[string]templateText{
+		"en": {"New ride request", "A rider near you needs a trip{{with .Fare}}, estimated {{.}}{{end}}. Open the app to accept."},
+		"sw": {"Ombi jipya la safari", "Mteja aliye karibu nawe anahitaji safari{{with .Fare}}, takriban {{.}}{{end}}. Fungua programu ukubali."},
+	}},
+	templateTripAccepted: {category: domain.NotificationCategoryTrip, sms: true, text: map[string]templateText{
+		"en": {"Your driver is on the way", "{{.DriverName}} is coming in a {{.VehicleColor}} {{.VehicleModel}}, {{.PlateNumber}}."},
+		"sw": {"Dereva wako yuko njiani", "{{.DriverName}} anakuja na {{.VehicleModel}} ya rangi {{.VehicleColor}}, {{.PlateNumber}}."},
+	}},
+	templateDriverArrived: {category: domain.NotificationCategoryTrip, sms: true, text: map[string]templateText{
+		"en": {"Your driver has arrived", "Your driver is waiting at the pickup point."},
+		"sw": {"Dereva wako amefika", "Dereva wako anakusubiri mahali pa kuchukuliwa."},
+	}},
+	templateTripStarted: {category: domain.NotificationCategoryTrip, text: map[string]templateText{
+		"en": {"Trip started", "Enjoy your ride. You can share your trip from the app."},
+		"sw": {"Safari imeanza", "Furahia safari yako. Unaweza kushiriki safari yako kupitia programu."},
+	}},
+	templateTripCompleted: {category: domain.NotificationCategoryTrip, text: map[string]templateText{
+		"en": {"Trip completed", "Your fare was {{.Fare}}. Thanks for riding with Yanga, and don't forget to rate your driver."},
+		"sw": {"Safari imekamilika", "Nauli yako ilikuwa {{.Fare}}. Asante kwa kusafiri na Yanga, usisahau kumkadiria dereva wako."},
+	}},
+	templateCancelledByRider: {category: domain.NotificationCategoryTrip, text: map[string]templateText{
+		"en": {"Trip cancelled", "The rider cancelled the trip."},
+		"sw": {"Safari imeghairiwa", "Mteja ameghairi safari."},
+	}},
+	templateCancelledByDriver: {category: domain.NotificationCategoryTrip, sms: true, text: map[string]templateText{
+		"en": {"Trip cancelled", "Your driver cancelled the trip. Request again to find another driver."},
+		"sw": {"Safari imeghairiwa", "Dereva wako ameghairi safari. Omba tena kupata dereva mwingine."},
+	}},
+	templateCancelledBySystem: {category: domain.NotificationCategoryTrip, text: map[string]templateText{
+		"en": {"Trip cancelled", "We couldn't complete your trip request. Please try again."},
+		"sw": {"Safari imeghairiwa", "Hatukuweza kukamilisha ombi lako la safari. Tafadhali jaribu tena."},
+	}},
+	templateCancellationFee: {category: domain.NotificationCategoryPayment, text: map[string]templateText{
+		"en": {"Cancellation fee", "A cancellation fee of {{.Fee}} was charged for your trip."},
+		"sw": {"Ada ya kughairi", "Ada ya kughairi ya {{.Fee}} imetozwa kwa safari yako."},
+	}},
+	templateTripScheduled: {category: domain.NotificationCategoryTrip, text: map[string]templateText{
+		"en": {"Ride scheduled", "Your ride from {{.PickupAddress}} is booked for {{.PickupAt}}."},
+		"sw": {"Safari imepangwa", "Safari yako kutoka {{.PickupAddress}} imepangwa {{.PickupAt}}."},
+	}},
+	templateTripReminder: {category: domain.NotificationCategoryTrip, sms: true, text: map[string]templateText{
+		"en": {"Upcoming ride", "Your ride from {{.PickupAddress}} is at {{.PickupAt}}."},
+		"sw": {"Safari inayokuja", "Safari yako kutoka {{.PickupAddress}} ni saa {{.PickupAt}}."},
+	}},
+	templateTripReminderDriver: {category: domain.NotificationCategoryTrip, sms: true, text: map[string]templateText{
+		"en": {"Upcoming reserved ride", "Pick up your reserved rider at {{.PickupAddress}} at {{.PickupAt}}."},
+		"sw": {"Safari uliyohifadhi inakuja", "Mchukue mteja wako {{.PickupAddress}} saa {{.PickupAt}}."},
+	}},
+	templateStopArrived: {category: domain.NotificationCategoryTrip, text: map[string]templateText{
+		"en": {"Arrived at stop {{.StopNumber}}", "Your driver has reached {{.Address}}."},
+		"sw": {"Umefika kituo cha {{.StopNumber}}", "Dereva wako amefika {{.Address}}."},
+	}},
+	templateRouteUpdated: {category: domain.NotificationCategoryTrip, text: map[string]templateText{
+		"en": {"Route updated", "The rider changed their stops. The trip now has {{.StopCount}} stops."},
+		"sw": {"Njia imebadilishwa", "Mteja amebadilisha vituo. Safari sasa ina vituo {{.StopCount}}."},
+	}},
+	templatePoolUpdated: {category: domain.NotificationCategoryTrip, text: map[string]templateText{
+		"en": {"Pool updated", "{{.SeatsBooked}} seats are now booked. Check the app for your new pickup and drop-off order."},
+		"sw": {"Safari ya pamoja imebadilika", "Viti {{.SeatsBooked}} sasa vimehifadhiwa. Angalia programu kwa mpangilio mpya wa kuchukua na kushusha."},
+	}},
+	templateParcelPickedUp: {category: domain.NotificationCategoryTrip, text: map[string]templateText{
+		"en": {"Parcel picked up", "Your driver has collected your parcel and is on the way."},
+		"sw": {"Kifurushi kimechukuliwa", "Dereva wako amechukua kifurushi chako na yuko njiani."},
+	}},
+	templateParcelDelivered: {category: domain.NotificationCategoryTrip, sms: true, text: map[string]templateText{
+		"en": {"Parcel delivered", "Your parcel has been delivered."},
+		"sw": {"Kifurushi kimefikishwa", "Kifurushi chako kimefikishwa."},
+	}},
+	templateChatMessage: {category: domain.NotificationCategoryTrip, text: map[string]templateText{
+		"en": {"{{if eq .SenderRole \"driver\"}}Message from your driver{{else}}Message from your rider{{end}}", "{{.Body}}"},
+		"sw": {"{{if eq .SenderRole \"driver\"}}Ujumbe kutoka kwa dereva wako{{else}}Ujumbe kutoka kwa mteja wako{{end}}", "{{.Body}}"},
+	}},
+	templatePaymentCompleted: {category: domain.NotificationCategoryPayment, text: map[string]templateText{
+		"en": {"Payment received", "We received your payment of {{.Amount}}."},
+		"sw": {"Malipo yamepokelewa", "Tumepokea malipo yako ya {{.Amount}}."},
+	}},
+	templatePaymentFailed: {category: domain.NotificationCategoryPayment, sms: true, text: map[string]templateText{
+		"en": {"Payment failed", "We couldn't take your payment of {{.Amount}}. Please top up your wallet or choose another way to pay."},
+		"sw": {"Malipo hayakufaulu", "Hatukuweza kupokea malipo yako ya {{.Amount}}. Tafadhali ongeza salio au chagua njia nyingine ya kulipa."},
+	}},
+	templateWalletToppedUp: {category: domain.NotificationCategoryPayment, text: map[string]templateText{
+		"en": {"Wallet topped up", "{{.Amount}} was added to your wallet. Your balance is {{.Balance}}."},
+		"sw": {"Salio limeongezwa", "{{.Amount}} imeongezwa kwenye pochi yako. Salio lako ni {{.Balance}}."},
+	}},
+	templateEarningsRecorded: {category: domain.NotificationCategoryEarnings, text: map[string]templateText{
+		"en": {"Trip earnings", "You earned {{.Amount}} on your last trip."},
+		"sw": {"Mapato ya safari", "Umepata {{.Amount}} kwenye safari yako ya mwisho."},
+	}},
+	templateIncentiveEarned: {category: domain.NotificationCategoryEarnings, text: map[string]templateText{
+		"en": {"Bonus earned", "You earned a {{.Amount}} bonus. Keep it up!"},
+		"sw": {"Umepata bonasi", "Umepata bonasi ya {{.Amount}}. Endelea hivyo!"},
+	}},
+	templateReferralRewarded: {category: domain.NotificationCategoryPromotions, text: map[string]templateText{
+		"en": {"Referral reward", "Your friend took their first trip, so {{.Amount}} is on its way to your wallet."},
+		"sw": {"Zawadi ya rufaa", "Rafiki yako amesafiri kwa mara ya kwanza, kwa hivyo {{.Amount}} inakuja kwenye pochi yako."},
+	}},
+	templateQualityWarning: {category: domain.NotificationCategoryAccount, text: map[string]templateText{
+		"en": {"Your service quality", "Your quality score has dropped to {{.Score}}. Accept more requests, avoid cancelling and keep your ratings up to stay on the road."},
+		"sw": {"Ubora wa huduma yako", "Alama yako ya ubora imeshuka hadi {{.Score}}. Kubali maombi zaidi, epuka kughairi na dumisha ukadiriaji wako ili uendelee kufanya kazi."},
+	}},
+	templateQualitySuspended: {category: domain.NotificationCategoryAccount, sms: true, text: map[string]templateText{
+		"en": {"Temporarily suspended", "Your quality score of {{.Score}} is too low to take trips. You can go online again at {{.Until}}."},
+		"sw": {"Umesimamishwa kwa muda", "Alama yako ya ubora ya {{.Score}} iko chini mno kupokea safari. Unaweza kuingia mtandaoni tena {{.Until}}."},
+	}},
+	templateQualityReview: {category: domain.NotificationCategoryAccount, sms: true, text: map[string]templateText{
+		"en": {"Account under review", "Your account is paused while our team reviews your recent trips. We'll be in touch."},
+		"sw": {"Akaunti inakaguliwa", "Akaunti yako imesimamishwa huku timu yetu ikikagua safari zako za hivi karibuni. Tutawasiliana nawe."},
+	}},
+	templateQualityReinstated: {category: domain.NotificationCategoryAccount, text: map[string]templateText{
+		"en": {"Welcome back", "Your account has been reinstated. You can go online again."},
+		"sw": {"Karibu tena", "Akaunti yako imerejeshwa. Unaweza kuingia mtandaoni tena."},
+	}},
+	templateDisputeAccepted: {category: domain.NotificationCategoryAccount, text: map[string]templateText{
+		"en": {"Rating dispute accepted", "The rating you disputed no longer counts towards your average."},
+		"sw": {"Pingamizi limekubaliwa", "Ukadiriaji uliopinga hauhesabiwi tena kwenye wastani wako."},
+	}},
+	templateDisputeRejected: {category: domain.NotificationCategoryAccount, text: map[string]templateText{
+		"en": {"Rating dispute rejected", "After review, the rating you disputed stays on your record."},
+		"sw": {"Pingamizi limekataliwa", "Baada ya ukaguzi, ukadiriaji uliopinga utabaki kwenye rekodi yako."},
+	}},
+	templateSOSRaised: {category: domain.NotificationCategoryTrip, sms: true, text: map[string]templateText{
+		"en": {"SOS sent", "Our safety team has been alerted{{if .ContactCount}} and we've texted your {{.ContactCount}} emergency contacts{{end}}. If you're in danger, call emergency services."},
+		"sw": {"SOS imetumwa", "Timu yetu ya usalama imearifiwa{{if .ContactCount}} na tumewatumia ujumbe watu wako {{.ContactCount}} wa dharura{{end}}. Ukiwa hatarini, piga simu huduma za dharura."},
+	}},
+	// Sent by SMS to someone who may not have the app, so the link is all
+	// they have.
+	templateSOSContact: {category: domain.NotificationCategoryTrip, sms: true, text: map[string]templateText{
+		"en": {"Yanga SOS", "{{.Name}} has raised an SOS on a Yanga trip and listed you as an emergency contact. Follow the trip: {{.ShareURL}}"},
+		"sw": {"Yanga SOS", "{{.Name}} ametuma SOS akiwa kwenye safari ya Yanga na amekuweka kama mtu wa dharura. Fuatilia safari: {{.ShareURL}}"},
+	}},
+	templateSafetyCheck: {category: domain.NotificationCategoryTrip, sms: true, text: map[string]templateText{
+		"en": {"Is everything OK?", "{{if eq .Kind \"route_deviation\"}}Your trip has gone off the planned route.{{else if eq .Kind \"long_stop\"}}Your trip has been stopped for a while.{{else}}Your trip is taking much longer than expected.{{end}} Our safety team has been told. If you need help, use SOS in the app."},
+		"sw": {"Je, kila kitu kiko sawa?", "{{if eq .Kind \"route_deviation\"}}Safari yako imetoka kwenye njia iliyopangwa.{{else if eq .Kind \"long_stop\"}}Safari yako imesimama kwa muda.{{else}}Safari yako inachukua muda mrefu kuliko ilivyotarajiwa.{{end}} Timu yetu ya usalama imearifiwa. Ukihitaji msaada, tumia SOS kwenye programu."},
+	}},
+	templateSupportOpened: {category: domain.NotificationCategoryAccount, text: map[string]templateText{
+		"en": {"We've got your request", "Our support team will reply to \"{{.Subject}}\" soon."},
+		"sw": {"Tumepokea ombi lako", "Timu yetu ya huduma kwa wateja itajibu \"{{.Subject}}\" hivi karibuni."},
+	}},
+	templateSupportLostItem: {category: domain.NotificationCategoryTrip, sms: true, text: map[string]templateText{
+		"en": {"A rider left something behind", "A rider from one of your trips thinks they left something in your vehicle. Please check and reply on the support ticket in the app."},
+		"sw": {"Abiria amesahau kitu", "Abiria wa mojawapo ya safari zako anadhani amesahau kitu kwenye gari lako. Tafadhali angalia na ujibu kwenye tiketi ya msaada kwenye programu."},
+	}},
+	templateSupportReply: {category: domain.NotificationCategoryAccount, text: map[string]templateText{
+		"en": {"New reply from support", "There's a new reply on \"{{.Subject}}\"."},
+		"sw": {"Jibu jipya kutoka huduma kwa wateja", "Kuna jibu jipya kwenye \"{{.Subject}}\"."},
+	}},
+	templateSupportResolved: {category: domain.NotificationCategoryAccount, text: map[string]templateText{
+		"en": {"Support ticket resolved", "{{.Note}}{{if .Amount}} {{.Amount}} will be credited to your wallet.{{end}}"},
+		"sw": {"Tiketi ya msaada imetatuliwa", "{{.Note}}{{if .Amount}} {{.Amount}} itawekwa kwenye pochi yako.{{end}}"},
+	}},
+	templateSupportRefund: {category: domain.NotificationCategoryPayment, text: map[string]templateText{
+		"en": {"Refund credited", "{{.Amount}} from your support ticket was added to your wallet."},
+		"sw": {"Marejesho yamewekwa", "{{.Amount}} kutoka kwa tiketi yako ya msaada imeongezwa kwenye pochi yako."},
+	}},
+	// For support agents
+	templateSupportOverdue: {category: domain.NotificationCategoryAccount, text: map[string]templateText{
+		"en": {"Support ticket overdue", "A {{.Priority}} priority {{.Category}} ticket assigned to you missed its {{if eq .Deadline \"first_response\"}}first response{{else}}resolution{{end}} deadline."},
+	}},
+}
+
+type compiledText struct {
+	title *template.Template
+	body  *template.Template
+}
+
+// compiled holds every template parsed, by name and language. Parsing at
+// start-up means a broken template stops the service rather than a
+// notification.
+var compiled = compileTemplates()
+
+func compileTemplates() map[string]map[string]compiledText {
+	out := make(map[string]map[string]compiledText, len(templates))
+	for name, tmpl := range templates {
+		if _, ok := tmpl.text[defaultTemplateLanguage]; !ok {
+			panic(fmt.Sprintf("notification template %s has no %s text", name, defaultTemplateLanguage))
+		}
+		out[name] = make(map[string]compiledText, len(tmpl.text))
+		for language, text := range tmpl.text {
+			key := name + "." + language
+			out[name][language] = compiledText{
+				title: template.Must(template.New(key + ".title").Option("missingkey=error").Parse(text.title)),
+				body:  template.Must(template.New(key + ".body").Option("missingkey=error").Parse(text.body)),
+			}
+		}
+	}
+	return out
+}
+
+// render writes a notification in the recipient's locale. Amounts are
+// written the way the locale writes them and times in the recipient's
+// timezone.
+func render(name, locale string, loc *time.Location, vars map[string]any) (title, body string, err error) {
+	texts, ok := compiled[name]
+	if !ok {
+		return "", "", fmt.Errorf("unknown notification template %q", name)
+	}
+	text, ok := texts[language(locale)]
+	if !ok {
+		text = texts[defaultTemplateLanguage]
+	}
+
+	values := make(map[string]any, len(vars))
+	for key, value := range vars {
+		switch v := value.(type) {
+		case money.Money:
+			values[key] = v.Format(locale)
+		case *money.Money:
+			if v == nil {
+				values[key] = ""
+			} else {
+				values[key] = v.Format(locale)
+			}
+		case time.Time:
+			values[key] = formatTime(v, loc)
+		default:
+			values[key] = value
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := text.title.Execute(&buf, values); err != nil {
+		return "", "", fmt.Errorf("failed to render %s title: %w", name, err)
+	}
+	title = buf.String()
+	buf.Reset()
+	if err := text.body.Execute(&buf, values); err != nil {
+		return "", "", fmt.Errorf("failed to render %s body: %w", name, err)
+	}
+	return title, buf.String(), nil
+}
+
+func formatTime(t time.Time, loc *time.Location) string {
+	local := t.In(loc)
+	now := time.Now().In(loc)
+	if local.YearDay() == now.YearDay() && local.Year() == now.Year() {
+		return local.Format(templateTimeLayoutToday)
+	}
+	return local.Format(templateTimeLayout)
+}
+
+// language returns the language of a locale such as "sw-KE".
+func language(locale string) string {
+	language, _, _ := strings.Cut(strings.ReplaceAll(locale, "_", "-"), "-")
+	return strings.ToLower(language)
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/jackc/pgx/v5/pgxpool"
+	_ "github.com/namycodes/yanga-services/services/payment-service/docs"
+	"github.com/namycodes/yanga-services/services/payment-service/internal/db"
+	"github.com/namycodes/yanga-services/services/payment-service/internal/handler"
+	"github.com/namycodes/yanga-services/services/payment-service/internal/repository"
+	"github.com/namycodes/yanga-services/services/payment-service/internal/routes"
+	"github.com/namycodes/yanga-services/services/payment-service/internal/service"
+	"github.com/namycodes/yanga-services/shared-lib/config"
+	"github.com/namycodes/yanga-services/shared-lib/events"
+	"github.com/namycodes/yanga-services/shared-lib/middleware"
+)
+
+// @title Payment Service API
+// @version 1.0
+// @description Rider wallets, ledger postings and reconciliation
+// @host localhost:8085
+// @BasePath /api/v1
+func main() {
+	cfg := config.Load()
+
+	dbPool, err := pgxpool.New(context.Background(), cfg.DatabaseURL())
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer dbPool.Close()
+
+	if err := dbPool.Ping(context.Background()); err != nil {
+		log.Fatalf("Failed to ping database: %v", err)
+	}
+	log.Println("✅ Connected to database")
+
+	eventBus, err := events.NewNATSEventBus(cfg.NatsURL)
+	if err != nil {
+		log.Fatalf("Failed to connect to NATS: %v", err)
+	}
+	defer eventBus.Close()
+	log.Println("✅ Connected to NATS")
+
+	queries := db.New(dbPool)
+	ledgerRepo := repository.NewLedgerRepository(dbPool, queries)
+	walletService := service.NewWalletService(ledgerRepo, eventBus, cfg)
+	walletHandler := handler.NewWalletHandler(walletService)
+
+	walletService.SubscribeToEvents()
+
+	router := mux.NewRouter()
+	router.Use(middleware.RequestIDMiddleware)
+	router.Use(middleware.LoggingMiddleware)
+	router.Use(middleware.CORSMiddleware)
+
+	routes.SetupWalletRoutes(router, walletHandler, config.JWTConfig{Secret: cfg.JWTSecret, IdentitySecret: cfg.GatewayIdentitySecret})
+
+	router.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"status":"healthy"}`))
+	}).Methods("GET")
+
+	addr := fmt.Sprintf("%s:%s", cfg.ServiceHost, cfg.ServicePort)
+	srv := &http.Server{
+		Addr:         addr,
+		Handler:      router,
+		ReadTimeout:  15 * time.Second,
+		WriteTimeout: 15 * time.Second,
+		IdleTimeout:  60 * time.Second,
+	}
+
+	go func() {
+		log.Printf("🚀 Payment Service starting on %s", addr)
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatalf("Failed to start server: %v", err)
+		}
+	}()
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+
+	log.Println("🛑 Shutting down server...")
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if err := srv.Shutdown(ctx); err != nil {
+		log.Fatalf("Server forced to shutdown: %v", err)
+	}
+
+	log.Println("✅ Server exited gracefully")
+}
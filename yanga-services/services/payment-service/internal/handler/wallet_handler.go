@@ -0,0 +1,323 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/namycodes/yanga-services/services/payment-service/internal/service"
+	"github.com/namycodes/yanga-services/shared-lib/domain"
+	"github.com/namycodes/yanga-services/shared-lib/utils"
+)
+
+// maxIdempotencyKeyLength keeps scoped keys within the ledger_transactions column
+const maxIdempotencyKeyLength = 50
+
+type WalletHandler struct {
+	walletService *service.WalletService
+}
+
+func NewWalletHandler(walletService *service.WalletService) *WalletHandler {
+	return &WalletHandler{
+		walletService: walletService,
+	}
+}
+
+// GetWallet godoc
+// @Summary Get the current user's wallet balance
+// @Description Riders hold one wallet per currency; without a currency the default currency's wallet is returned
+// @Tags wallet
+// @Produce json
+// @Param currency query string false "ISO 4217 currency code"
+// @Success 200 {object} domain.SuccessResponse
+// @Router /wallet [get]
+// @Security BearerAuth
+func (h *WalletHandler) GetWallet(w http.ResponseWriter, r *http.Request) {
+	userIDStr := r.Context().Value("user_id").(string)
+	userID, err := utils.ParseUUID(userIDStr)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	wallet, err := h.walletService.GetWallet(r.Context(), userID, r.URL.Query().Get("currency"))
+	if err != nil {
+		handleWalletError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Wallet retrieved successfully", wallet)
+}
+
+// GetTransactions godoc
+// @Summary Get the current user's wallet transactions
+// @Tags wallet
+// @Produce json
+// @Param currency query string false "ISO 4217 currency code"
+// @Param limit query int false "Limit" default(20)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {object} domain.SuccessResponse
+// @Router /wallet/transactions [get]
+// @Security BearerAuth
+func (h *WalletHandler) GetTransactions(w http.ResponseWriter, r *http.Request) {
+	userIDStr := r.Context().Value("user_id").(string)
+	userID, err := utils.ParseUUID(userIDStr)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	limit := queryInt(r, "limit", 20)
+	offset := queryInt(r, "offset", 0)
+
+	transactions, err := h.walletService.GetTransactions(r.Context(), userID, r.URL.Query().Get("currency"), limit, offset)
+	if err != nil {
+		handleWalletError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Transactions retrieved successfully", transactions)
+}
+
+// Transfer godoc
+// @Summary Transfer wallet funds to another user
+// @Tags wallet
+// @Accept json
+// @Produce json
+// @Param Idempotency-Key header string false "Client generated key to make retries safe"
+// @Param request body domain.TransferWalletRequest true "Transfer details"
+// @Success 201 {object} domain.SuccessResponse
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 402 {object} domain.ErrorResponse
+// @Router /wallet/transfers [post]
+// @Security BearerAuth
+func (h *WalletHandler) Transfer(w http.ResponseWriter, r *http.Request) {
+	var req domain.TransferWalletRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	userIDStr := r.Context().Value("user_id").(string)
+	userID, err := utils.ParseUUID(userIDStr)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	key, ok := idempotencyKey(w, r)
+	if !ok {
+		return
+	}
+
+	txn, err := h.walletService.Transfer(r.Context(), userID, key, &req)
+	if err != nil {
+		handleWalletError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusCreated, "Transfer completed successfully", txn)
+}
+
+// TopUp godoc
+// @Summary Record a confirmed wallet top-up (admin)
+// @Tags wallet
+// @Accept json
+// @Produce json
+// @Param request body domain.TopUpWalletRequest true "Top-up details"
+// @Success 201 {object} domain.SuccessResponse
+// @Failure 400 {object} domain.ErrorResponse
+// @Router /wallet/top-ups [post]
+// @Security BearerAuth
+func (h *WalletHandler) TopUp(w http.ResponseWriter, r *http.Request) {
+	var req domain.TopUpWalletRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	adminIDStr := r.Context().Value("user_id").(string)
+	adminID, err := utils.ParseUUID(adminIDStr)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	if len(req.PaymentReference) > maxIdempotencyKeyLength {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Payment reference is too long")
+		return
+	}
+
+	txn, err := h.walletService.TopUp(r.Context(), adminID, &req)
+	if err != nil {
+		handleWalletError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusCreated, "Wallet topped up successfully", txn)
+}
+
+// Refund godoc
+// @Summary Refund a wallet trip charge (admin)
+// @Tags wallet
+// @Accept json
+// @Produce json
+// @Param Idempotency-Key header string false "Client generated key to make retries safe"
+// @Param request body domain.RefundRequest true "Refund details"
+// @Success 201 {object} domain.SuccessResponse
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Router /wallet/refunds [post]
+// @Security BearerAuth
+func (h *WalletHandler) Refund(w http.ResponseWriter, r *http.Request) {
+	var req domain.RefundRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	adminIDStr := r.Context().Value("user_id").(string)
+	adminID, err := utils.ParseUUID(adminIDStr)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	key, ok := idempotencyKey(w, r)
+	if !ok {
+		return
+	}
+
+	txn, err := h.walletService.Refund(r.Context(), adminID, key, &req)
+	if err != nil {
+		handleWalletError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusCreated, "Refund issued successfully", txn)
+}
+
+// PromoCredit godoc
+// @Summary Credit promotional funds to a wallet (admin)
+// @Tags wallet
+// @Accept json
+// @Produce json
+// @Param Idempotency-Key header string false "Client generated key to make retries safe"
+// @Param request body domain.PromoCreditRequest true "Promo credit details"
+// @Success 201 {object} domain.SuccessResponse
+// @Failure 400 {object} domain.ErrorResponse
+// @Router /wallet/promo-credits [post]
+// @Security BearerAuth
+func (h *WalletHandler) PromoCredit(w http.ResponseWriter, r *http.Request) {
+	var req domain.PromoCreditRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	adminIDStr := r.Context().Value("user_id").(string)
+	adminID, err := utils.ParseUUID(adminIDStr)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	key, ok := idempotencyKey(w, r)
+	if !ok {
+		return
+	}
+
+	txn, err := h.walletService.PromoCredit(r.Context(), adminID, key, &req)
+	if err != nil {
+		handleWalletError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusCreated, "Promotional credit issued successfully", txn)
+}
+
+// GetReconciliationReport godoc
+// @Summary Get a ledger reconciliation report (admin)
+// @Tags wallet
+// @Produce json
+// @Param from query string false "Period start (YYYY-MM-DD or RFC3339), defaults to 24 hours ago"
+// @Param to query string false "Period end (YYYY-MM-DD or RFC3339), defaults to now"
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 400 {object} domain.ErrorResponse
+// @Router /wallet/reconciliation [get]
+// @Security BearerAuth
+func (h *WalletHandler) GetReconciliationReport(w http.ResponseWriter, r *http.Request) {
+	to := time.Now()
+	from := to.Add(-24 * time.Hour)
+
+	var err error
+	if v := r.URL.Query().Get("from"); v != "" {
+		if from, err = parseTime(v); err != nil {
+			utils.ErrorResponse(w, http.StatusBadRequest, "Invalid from date")
+			return
+		}
+	}
+	if v := r.URL.Query().Get("to"); v != "" {
+		if to, err = parseTime(v); err != nil {
+			utils.ErrorResponse(w, http.StatusBadRequest, "Invalid to date")
+			return
+		}
+	}
+	if !from.Before(to) {
+		utils.ErrorResponse(w, http.StatusBadRequest, "from must be before to")
+		return
+	}
+
+	report, err := h.walletService.GetReconciliationReport(r.Context(), from, to)
+	if err != nil {
+		utils.HandleServiceError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Reconciliation report generated successfully", report)
+}
+
+func handleWalletError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrInsufficientBalance):
+		utils.ErrorResponse(w, http.StatusPaymentRequired, err.Error())
+	case errors.Is(err, service.ErrIdempotencyKeyReused):
+		utils.ErrorResponse(w, http.StatusConflict, err.Error())
+	case errors.Is(err, service.ErrInvalidAmount),
+		errors.Is(err, service.ErrSelfTransfer),
+		errors.Is(err, service.ErrRefundExceedsCharge),
+		errors.Is(err, service.ErrInvalidCurrency),
+		err.Error() == "payment reference is required":
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+	case err.Error() == "trip charge not found":
+		utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+	default:
+		utils.HandleServiceError(w, err)
+	}
+}
+
+func idempotencyKey(w http.ResponseWriter, r *http.Request) (string, bool) {
+	key := r.Header.Get("Idempotency-Key")
+	if len(key) > maxIdempotencyKeyLength {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Idempotency-Key header is too long")
+		return "", false
+	}
+	return key, true
+}
+
+func queryInt(r *http.Request, name string, defaultValue int32) int32 {
+	value, err := strconv.Atoi(r.URL.Query().Get(name))
+	if err != nil || value < 0 {
+		return defaultValue
+	}
+	return int32(value)
+}
+
+func parseTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", value)
+}
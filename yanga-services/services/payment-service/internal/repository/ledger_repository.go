@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/namycodes/yanga-services/services/payment-service/internal/db"
+)
+
+// maxSerializationRetries bounds how often a serializable transaction is
+// replayed after Postgres reports a serialization failure or deadlock.
+const maxSerializationRetries = 5
+
+type LedgerRepository struct {
+	pool    *pgxpool.Pool
+	queries *db.Queries
+}
+
+func NewLedgerRepository(pool *pgxpool.Pool, queries *db.Queries) *LedgerRepository {
+	return &LedgerRepository{
+		pool:    pool,
+		queries: queries,
+	}
+}
+
+// WithSerializableTx runs fn inside a SERIALIZABLE transaction and retries it
+// when the database aborts the transaction because of a concurrent posting.
+// fn may be invoked more than once and must not have side effects outside q.
+func (r *LedgerRepository) WithSerializableTx(ctx context.Context, fn func(q *db.Queries) error) error {
+	var err error
+	for attempt := 0; attempt < maxSerializationRetries; attempt++ {
+		err = r.runTx(ctx, fn)
+		if err == nil || !isRetryable(err) {
+			return err
+		}
+	}
+	return fmt.Errorf("ledger transaction retries exhausted: %w", err)
+}
+
+func (r *LedgerRepository) runTx(ctx context.Context, fn func(q *db.Queries) error) error {
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.Serializable})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := fn(r.queries.WithTx(tx)); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func isRetryable(err error) bool {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		// 40001 serialization_failure, 40P01 deadlock_detected
+		return pgErr.Code == "40001" || pgErr.Code == "40P01"
+	}
+	return false
+}
+
+func (r *LedgerRepository) GetLedgerAccount(ctx context.Context, params db.GetLedgerAccountParams) (db.LedgerAccount, error) {
+	return r.queries.GetLedgerAccount(ctx, params)
+}
+
+func (r *LedgerRepository) GetLedgerAccountByID(ctx context.Context, id pgtype.UUID) (db.LedgerAccount, error) {
+	return r.queries.GetLedgerAccountByID(ctx, id)
+}
+
+func (r *LedgerRepository) GetLedgerAccountsByOwner(ctx context.Context, ownerID pgtype.UUID) ([]db.LedgerAccount, error) {
+	return r.queries.GetLedgerAccountsByOwner(ctx, ownerID)
+}
+
+func (r *LedgerRepository) GetAccountBalance(ctx context.Context, accountID pgtype.UUID) (pgtype.Numeric, error) {
+	return r.queries.GetAccountBalance(ctx, accountID)
+}
+
+func (r *LedgerRepository) GetAccountStatement(ctx context.Context, params db.GetAccountStatementParams) ([]db.GetAccountStatementRow, error) {
+	return r.queries.GetAccountStatement(ctx, params)
+}
+
+func (r *LedgerRepository) GetLedgerTransactionByIdempotencyKey(ctx context.Context, key string) (db.LedgerTransaction, error) {
+	return r.queries.GetLedgerTransactionByIdempotencyKey(ctx, key)
+}
+
+func (r *LedgerRepository) GetTransactionTotalsByType(ctx context.Context, params db.GetTransactionTotalsByTypeParams) ([]db.GetTransactionTotalsByTypeRow, error) {
+	return r.queries.GetTransactionTotalsByType(ctx, params)
+}
+
+func (r *LedgerRepository) GetAccountTypeTotals(ctx context.Context, asOf pgtype.Timestamp) ([]db.GetAccountTypeTotalsRow, error) {
+	return r.queries.GetAccountTypeTotals(ctx, asOf)
+}
+
+func (r *LedgerRepository) GetUnbalancedTransactions(ctx context.Context, params db.GetUnbalancedTransactionsParams) ([]db.GetUnbalancedTransactionsRow, error) {
+	return r.queries.GetUnbalancedTransactions(ctx, params)
+}
+
+func (r *LedgerRepository) GetNegativeBalanceAccounts(ctx context.Context) ([]db.GetNegativeBalanceAccountsRow, error) {
+	return r.queries.GetNegativeBalanceAccounts(ctx)
+}
+
+func (r *LedgerRepository) GetLedgerEntriesByTransaction(ctx context.Context, transactionID pgtype.UUID) ([]db.LedgerEntry, error) {
+	return r.queries.GetLedgerEntriesByTransaction(ctx, transactionID)
+}
@@ -0,0 +1,30 @@
+package routes
+
+import (
+	"github.com/gorilla/mux"
+	"github.com/namycodes/yanga-services/services/payment-service/internal/handler"
+	"github.com/namycodes/yanga-services/shared-lib/config"
+	"github.com/namycodes/yanga-services/shared-lib/middleware"
+	httpSwagger "github.com/swaggo/http-swagger"
+)
+
+func SetupWalletRoutes(router *mux.Router, walletHandler *handler.WalletHandler, jwtCfg config.JWTConfig) {
+	api := router.PathPrefix("/api/v1").Subrouter()
+
+	wallet := api.PathPrefix("/wallet").Subrouter()
+	wallet.Use(middleware.AuthMiddleware(jwtCfg.Secret, jwtCfg.IdentitySecret))
+
+	wallet.HandleFunc("", walletHandler.GetWallet).Methods("GET")
+	wallet.HandleFunc("/transactions", walletHandler.GetTransactions).Methods("GET")
+	wallet.HandleFunc("/transfers", walletHandler.Transfer).Methods("POST")
+
+	admin := wallet.NewRoute().Subrouter()
+	admin.Use(middleware.RequireRole("admin"))
+
+	admin.HandleFunc("/top-ups", walletHandler.TopUp).Methods("POST")
+	admin.HandleFunc("/refunds", walletHandler.Refund).Methods("POST")
+	admin.HandleFunc("/promo-credits", walletHandler.PromoCredit).Methods("POST")
+	admin.HandleFunc("/reconciliation", walletHandler.GetReconciliationReport).Methods("GET")
+
+	router.PathPrefix("/swagger/").Handler(httpSwagger.WrapHandler)
+}
@@ -0,0 +1,1098 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/namycodes/yanga-services/services/payment-service/internal/db"
+	"github.com/namycodes/yanga-services/services/payment-service/internal/repository"
+	"github.com/namycodes/yanga-services/shared-lib/config"
+	"github.com/namycodes/yanga-services/shared-lib/domain"
+	"github.com/namycodes/yanga-services/shared-lib/events"
+	"github.com/namycodes/yanga-services/shared-lib/money"
+	"github.com/namycodes/yanga-services/shared-lib/utils"
+)
+
+// Ledger account types
+const (
+	AccountRiderWallet        = "rider_wallet"
+	AccountDriverEarnings     = "driver_earnings"
+	AccountPlatformCash       = "platform_cash"
+	AccountPlatformRevenue    = "platform_revenue"
+	AccountPlatformPromotions = "platform_promotions"
+)
+
+// Ledger transaction types
+const (
+	TransactionTopUp       = "top_up"
+	TransactionTripCharge  = "trip_charge"
+	TransactionRefund      = "refund"
+	TransactionPromoCredit = "promo_credit"
+	TransactionTransfer    = "transfer"
+	TransactionCancelFee   = "cancellation_fee"
+	// Credits a rider the difference when support lowers a trip's fare
+	TransactionFareAdjustment = "fare_adjustment"
+)
+
+const (
+	directionDebit  = "debit"
+	directionCredit = "credit"
+)
+
+var (
+	ErrInvalidAmount        = errors.New("amount must be greater than zero")
+	ErrInsufficientBalance  = errors.New("insufficient wallet balance")
+	ErrSelfTransfer         = errors.New("cannot transfer to your own wallet")
+	ErrRefundExceedsCharge  = errors.New("refund exceeds what the rider paid for the trip")
+	ErrIdempotencyKeyReused = errors.New("idempotency key already used for a different request")
+	ErrInvalidCurrency      = errors.New("currency must be a 3-letter ISO 4217 code")
+)
+
+// accountRef identifies a ledger account; system accounts have no owner.
+type accountRef struct {
+	ownerID     pgtype.UUID
+	accountType string
+}
+
+type leg struct {
+	account   accountRef
+	direction string
+	amount    money.Money
+}
+
+// posting describes one balanced ledger transaction, with every leg in the
+// same currency. User-owned accounts that are debited must hold enough funds,
+// so wallets can never go negative.
+type posting struct {
+	transactionType string
+	currency        money.Currency
+	idempotencyKey  string
+	tripID          pgtype.UUID
+	reversedID      pgtype.UUID
+	description     string
+	createdBy       pgtype.UUID
+	legs            []leg
+	check           func(q *db.Queries) error
+}
+
+// postedTransaction is a ledger transaction as stored, with its entries.
+type postedTransaction struct {
+	db.LedgerTransaction
+	currency money.Currency
+	entries  []db.LedgerEntry
+}
+
+// amount is what the transaction moved: the total of its credits, which
+// its debits balance.
+func (t postedTransaction) amount() money.Money {
+	total := money.Zero(t.currency)
+	for _, entry := range t.entries {
+		if entry.Direction == directionCredit {
+			total = total.Add(money.FromNumeric(entry.Amount, t.currency))
+		}
+	}
+	return total
+}
+
+type WalletService struct {
+	repo     *repository.LedgerRepository
+	eventBus events.EventBus
+	currency money.Currency
+}
+
+func NewWalletService(repo *repository.LedgerRepository, eventBus events.EventBus, cfg *config.Config) *WalletService {
+	return &WalletService{
+		repo:     repo,
+		eventBus: eventBus,
+		currency: money.Currency(cfg.DefaultCurrency),
+	}
+}
+
+// GetWallet returns the user's wallet in a currency, or the default
+// currency when none is given. Riders hold a separate wallet for each
+// currency they have used.
+func (s *WalletService) GetWallet(ctx context.Context, userID uuid.UUID, code string) (*domain.WalletResponse, error) {
+	currency, err := s.walletCurrency(code)
+	if err != nil {
+		return nil, err
+	}
+	wallet := &domain.WalletResponse{
+		UserID:   userID.String(),
+		Currency: currency.String(),
+	}
+
+	account, err := s.repo.GetLedgerAccount(ctx, db.GetLedgerAccountParams{
+		OwnerID:     utils.ToPgUUID(userID),
+		AccountType: AccountRiderWallet,
+		Currency:    currency.String(),
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return wallet, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get wallet: %w", err)
+	}
+
+	balance, err := s.repo.GetAccountBalance(ctx, account.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get wallet balance: %w", err)
+	}
+
+	wallet.AccountID = utils.FromPgUUID(account.ID).String()
+	wallet.Balance = money.FromNumeric(balance, currency).Float64()
+	return wallet, nil
+}
+
+func (s *WalletService) GetTransactions(ctx context.Context, userID uuid.UUID, code string, limit, offset int32) ([]domain.WalletTransactionResponse, error) {
+	currency, err := s.walletCurrency(code)
+	if err != nil {
+		return nil, err
+	}
+	transactions := []domain.WalletTransactionResponse{}
+
+	account, err := s.repo.GetLedgerAccount(ctx, db.GetLedgerAccountParams{
+		OwnerID:     utils.ToPgUUID(userID),
+		AccountType: AccountRiderWallet,
+		Currency:    currency.String(),
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return transactions, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get wallet: %w", err)
+	}
+
+	rows, err := s.repo.GetAccountStatement(ctx, db.GetAccountStatementParams{
+		AccountID: account.ID,
+		Limit:     limit,
+		Offset:    offset,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get wallet transactions: %w", err)
+	}
+
+	for _, row := range rows {
+		tx := domain.WalletTransactionResponse{
+			ID:              utils.FromPgUUID(row.ID).String(),
+			TransactionID:   utils.FromPgUUID(row.TransactionID).String(),
+			TransactionType: row.TransactionType,
+			Direction:       row.Direction,
+			Amount:          money.FromNumeric(row.Amount, currency).Float64(),
+			Description:     row.Description.String,
+			CreatedAt:       row.CreatedAt.Time,
+		}
+		if row.TripID.Valid {
+			tx.TripID = utils.FromPgUUID(row.TripID).String()
+		}
+		transactions = append(transactions, tx)
+	}
+
+	return transactions, nil
+}
+
+// TopUp records a top-up that the payment provider has already confirmed.
+// The provider reference doubles as the idempotency key, so a replayed
+// callback never credits the wallet twice.
+func (s *WalletService) TopUp(ctx context.Context, recordedBy uuid.UUID, req *domain.TopUpWalletRequest) (*domain.LedgerTransactionResponse, error) {
+	currency, err := s.walletCurrency(req.Currency)
+	if err != nil {
+		return nil, err
+	}
+	amount := money.FromFloat(req.Amount, currency)
+	if amount.Sign() <= 0 {
+		return nil, ErrInvalidAmount
+	}
+	if req.PaymentReference == "" {
+		return nil, errors.New("payment reference is required")
+	}
+
+	wallet := accountRef{ownerID: utils.ToPgUUID(req.UserID), accountType: AccountRiderWallet}
+	txn, created, err := s.post(ctx, posting{
+		transactionType: TransactionTopUp,
+		currency:        currency,
+		idempotencyKey:  "top_up:" + req.PaymentReference,
+		description:     "Wallet top-up " + req.PaymentReference,
+		createdBy:       utils.ToPgUUID(recordedBy),
+		legs: []leg{
+			{account: accountRef{accountType: AccountPlatformCash}, direction: directionDebit, amount: amount},
+			{account: wallet, direction: directionCredit, amount: amount},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if created {
+		balance := 0.0
+		if w, err := s.GetWallet(ctx, req.UserID, currency.String()); err == nil {
+			balance = w.Balance
+		}
+		s.eventBus.Publish(ctx, events.SubjectWalletToppedUp, events.WalletToppedUpEvent{
+			UserID:        req.UserID.String(),
+			TransactionID: utils.FromPgUUID(txn.ID).String(),
+			Amount:        amount.Float64(),
+			Balance:       balance,
+			Timestamp:     time.Now(),
+		})
+	}
+
+	return toTransactionResponse(txn), nil
+}
+
+func (s *WalletService) Transfer(ctx context.Context, userID uuid.UUID, idempotencyKey string, req *domain.TransferWalletRequest) (*domain.LedgerTransactionResponse, error) {
+	currency, err := s.walletCurrency(req.Currency)
+	if err != nil {
+		return nil, err
+	}
+	amount := money.FromFloat(req.Amount, currency)
+	if amount.Sign() <= 0 {
+		return nil, ErrInvalidAmount
+	}
+	if req.RecipientID == userID {
+		return nil, ErrSelfTransfer
+	}
+
+	description := req.Note
+	if description == "" {
+		description = "Wallet transfer"
+	}
+
+	txn, _, err := s.post(ctx, posting{
+		transactionType: TransactionTransfer,
+		currency:        currency,
+		idempotencyKey:  scopedKey(TransactionTransfer, userID, idempotencyKey),
+		description:     description,
+		createdBy:       utils.ToPgUUID(userID),
+		legs: []leg{
+			{account: accountRef{ownerID: utils.ToPgUUID(userID), accountType: AccountRiderWallet}, direction: directionDebit, amount: amount},
+			{account: accountRef{ownerID: utils.ToPgUUID(req.RecipientID), accountType: AccountRiderWallet}, direction: directionCredit, amount: amount},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return toTransactionResponse(txn), nil
+}
+
+// Refund returns part or all of a wallet trip charge to the rider who paid it.
+func (s *WalletService) Refund(ctx context.Context, adminID uuid.UUID, idempotencyKey string, req *domain.RefundRequest) (*domain.LedgerTransactionResponse, error) {
+	if req.Amount <= 0 {
+		return nil, ErrInvalidAmount
+	}
+
+	tripID := utils.ToPgUUID(req.TripID)
+	charge, err := s.repo.GetLedgerTransactionByIdempotencyKey(ctx, tripChargeKey(req.TripID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, errors.New("trip charge not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trip charge: %w", err)
+	}
+
+	wallet, err := s.chargedWallet(ctx, charge)
+	if err != nil {
+		return nil, err
+	}
+
+	// Refunds are in the currency the trip was charged in.
+	currency := money.Currency(wallet.Currency)
+	amount := money.FromFloat(req.Amount, currency)
+	if amount.Sign() <= 0 {
+		return nil, ErrInvalidAmount
+	}
+
+	txn, _, err := s.post(ctx, posting{
+		transactionType: TransactionRefund,
+		currency:        currency,
+		idempotencyKey:  scopedKey(TransactionRefund, adminID, idempotencyKey),
+		tripID:          tripID,
+		reversedID:      charge.ID,
+		description:     req.Reason,
+		createdBy:       utils.ToPgUUID(adminID),
+		legs: []leg{
+			{account: accountRef{accountType: AccountPlatformRevenue}, direction: directionDebit, amount: amount},
+			{account: accountRef{ownerID: wallet.OwnerID, accountType: AccountRiderWallet}, direction: directionCredit, amount: amount},
+		},
+		check: func(q *db.Queries) error {
+			charged, err := walletCharged(ctx, q, tripID, currency)
+			if err != nil {
+				return err
+			}
+			return checkRefundable(ctx, q, tripID, amount, charged)
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return toTransactionResponse(txn), nil
+}
+
+func (s *WalletService) PromoCredit(ctx context.Context, adminID uuid.UUID, idempotencyKey string, req *domain.PromoCreditRequest) (*domain.LedgerTransactionResponse, error) {
+	currency, err := s.walletCurrency(req.Currency)
+	if err != nil {
+		return nil, err
+	}
+	amount := money.FromFloat(req.Amount, currency)
+	if amount.Sign() <= 0 {
+		return nil, ErrInvalidAmount
+	}
+
+	description := req.Description
+	if description == "" {
+		description = "Promotional credit"
+	}
+
+	txn, _, err := s.post(ctx, posting{
+		transactionType: TransactionPromoCredit,
+		currency:        currency,
+		idempotencyKey:  scopedKey(TransactionPromoCredit, adminID, idempotencyKey),
+		description:     description,
+		createdBy:       utils.ToPgUUID(adminID),
+		legs: []leg{
+			{account: accountRef{accountType: AccountPlatformPromotions}, direction: directionDebit, amount: amount},
+			{account: accountRef{ownerID: utils.ToPgUUID(req.UserID), accountType: AccountRiderWallet}, direction: directionCredit, amount: amount},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return toTransactionResponse(txn), nil
+}
+
+// CreditReferral pays both sides of a rewarded referral into their wallets.
+// Each credit has its own idempotency key so a redelivered event is a no-op.
+func (s *WalletService) CreditReferral(ctx context.Context, event events.ReferralRewardedEvent) error {
+	referralID, err := uuid.Parse(event.ReferralID)
+	if err != nil {
+		return fmt.Errorf("invalid referral ID: %w", err)
+	}
+
+	credits := []struct {
+		userID      string
+		role        string
+		amount      float64
+		description string
+	}{
+		{event.ReferrerID, "referrer", event.ReferrerReward, "Referral reward"},
+		{event.RefereeID, "referee", event.RefereeReward, "Referral welcome reward"},
+	}
+
+	for _, c := range credits {
+		amount := money.FromFloat(c.amount, s.currency)
+		if amount.Sign() <= 0 {
+			continue
+		}
+		userID, err := uuid.Parse(c.userID)
+		if err != nil {
+			return fmt.Errorf("invalid %s ID: %w", c.role, err)
+		}
+
+		_, _, err = s.post(ctx, posting{
+			transactionType: TransactionPromoCredit,
+			idempotencyKey:  fmt.Sprintf("referral:%s:%s", referralID, c.role),
+			description:     c.description,
+			legs: []leg{
+				{account: accountRef{accountType: AccountPlatformPromotions}, direction: directionDebit, amount: amount},
+				{account: accountRef{ownerID: utils.ToPgUUID(userID), accountType: AccountRiderWallet}, direction: directionCredit, amount: amount},
+			},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to credit %s: %w", c.role, err)
+		}
+	}
+	return nil
+}
+
+// ChargeTrip debits the rider's wallet for a completed wallet trip and
+// publishes the outcome so trip-service can update the trip's payment status.
+func (s *WalletService) ChargeTrip(ctx context.Context, event events.TripCompletedEvent) error {
+	if event.PaymentMethod != domain.PaymentMethodWallet {
+		return nil
+	}
+
+	tripID, err := uuid.Parse(event.TripID)
+	if err != nil {
+		return fmt.Errorf("invalid trip ID: %w", err)
+	}
+	userID, err := uuid.Parse(event.UserID)
+	if err != nil {
+		return fmt.Errorf("invalid user ID: %w", err)
+	}
+	currency, err := s.walletCurrency(event.Currency)
+	if err != nil {
+		return err
+	}
+
+	// Tips ride along with the fare; the driver's share is settled through
+	// driver-service payouts. Promo discounts are funded from the platform's
+	// promotions account, so revenue still books the full fare.
+	gross := money.FromFloat(event.ActualFare, currency).Add(money.FromFloat(event.Tip, currency))
+	discount := money.FromFloat(event.Discount, currency)
+	if discount.Sign() < 0 || discount.Cmp(gross) > 0 {
+		return ErrInvalidAmount
+	}
+	amount := gross.Sub(discount)
+	if amount.Sign() <= 0 {
+		return ErrInvalidAmount
+	}
+
+	legs := []leg{
+		{account: accountRef{ownerID: utils.ToPgUUID(userID), accountType: AccountRiderWallet}, direction: directionDebit, amount: amount},
+	}
+	if discount.Sign() > 0 {
+		legs = append(legs, leg{account: accountRef{accountType: AccountPlatformPromotions}, direction: directionDebit, amount: discount})
+	}
+	legs = append(legs, leg{account: accountRef{accountType: AccountPlatformRevenue}, direction: directionCredit, amount: gross})
+
+	txn, _, err := s.post(ctx, posting{
+		transactionType: TransactionTripCharge,
+		currency:        currency,
+		idempotencyKey:  tripChargeKey(tripID),
+		tripID:          utils.ToPgUUID(tripID),
+		description:     "Trip fare",
+		legs:            legs,
+	})
+	if err != nil {
+		if errors.Is(err, ErrInsufficientBalance) {
+			s.eventBus.Publish(ctx, events.SubjectPaymentFailed, events.PaymentFailedEvent{
+				TripID:        event.TripID,
+				UserID:        event.UserID,
+				Amount:        amount.Float64(),
+				PaymentMethod: domain.PaymentMethodWallet,
+				Reason:        err.Error(),
+				Timestamp:     time.Now(),
+			})
+			return nil
+		}
+		return err
+	}
+
+	s.eventBus.Publish(ctx, events.SubjectPaymentCompleted, events.PaymentCompletedEvent{
+		TripID:        event.TripID,
+		UserID:        event.UserID,
+		TransactionID: utils.FromPgUUID(txn.ID).String(),
+		Amount:        amount.Float64(),
+		PaymentMethod: domain.PaymentMethodWallet,
+		Timestamp:     time.Now(),
+	})
+	return nil
+}
+
+// ChargeCancellationFee debits the rider's wallet for a cancellation or
+// no-show fee. Fees on cash and card trips are left on the trip record.
+func (s *WalletService) ChargeCancellationFee(ctx context.Context, event events.TripCancelledEvent) error {
+	if event.PaymentMethod != domain.PaymentMethodWallet || event.CancellationFee <= 0 {
+		return nil
+	}
+
+	tripID, err := uuid.Parse(event.TripID)
+	if err != nil {
+		return fmt.Errorf("invalid trip ID: %w", err)
+	}
+	userID, err := uuid.Parse(event.UserID)
+	if err != nil {
+		return fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	currency, err := s.walletCurrency(event.Currency)
+	if err != nil {
+		return err
+	}
+
+	amount := money.FromFloat(event.CancellationFee, currency)
+	txn, _, err := s.post(ctx, posting{
+		transactionType: TransactionCancelFee,
+		currency:        currency,
+		idempotencyKey:  "cancellation_fee:" + tripID.String(),
+		tripID:          utils.ToPgUUID(tripID),
+		description:     "Cancellation fee",
+		legs: []leg{
+			{account: accountRef{ownerID: utils.ToPgUUID(userID), accountType: AccountRiderWallet}, direction: directionDebit, amount: amount},
+			{account: accountRef{accountType: AccountPlatformRevenue}, direction: directionCredit, amount: amount},
+		},
+	})
+	if err != nil {
+		if errors.Is(err, ErrInsufficientBalance) {
+			s.eventBus.Publish(ctx, events.SubjectPaymentFailed, events.PaymentFailedEvent{
+				TripID:        event.TripID,
+				UserID:        event.UserID,
+				Amount:        amount.Float64(),
+				PaymentMethod: domain.PaymentMethodWallet,
+				Reason:        err.Error(),
+				Timestamp:     time.Now(),
+			})
+			return nil
+		}
+		return err
+	}
+
+	s.eventBus.Publish(ctx, events.SubjectPaymentCompleted, events.PaymentCompletedEvent{
+		TripID:        event.TripID,
+		UserID:        event.UserID,
+		TransactionID: utils.FromPgUUID(txn.ID).String(),
+		Amount:        amount.Float64(),
+		PaymentMethod: domain.PaymentMethodWallet,
+		Timestamp:     time.Now(),
+	})
+	return nil
+}
+
+// SettleSupportResolution posts the refund or fare adjustment a support
+// agent granted on a ticket, and reports back whether it made it into the
+// ledger. Wallet trips are credited back to the wallet that paid, up to
+// what was charged; cash and card trips are credited to the rider's wallet.
+func (s *WalletService) SettleSupportResolution(ctx context.Context, event events.SupportTicketResolvedEvent) error {
+	var transactionType, description string
+	switch event.Resolution {
+	case domain.SupportResolutionRefund:
+		transactionType, description = TransactionRefund, "Refund from support"
+	case domain.SupportResolutionFareAdjustment:
+		transactionType, description = TransactionFareAdjustment, "Fare adjustment from support"
+	default:
+		return nil
+	}
+
+	settled := events.SupportAdjustmentSettledEvent{
+		TicketID:   event.TicketID,
+		UserID:     event.UserID,
+		Resolution: event.Resolution,
+		Amount:     event.Amount,
+		Currency:   event.Currency,
+	}
+	txn, err := s.creditSupportResolution(ctx, transactionType, description, event)
+	if err != nil {
+		settled.Error = err.Error()
+	} else {
+		settled.TransactionID = utils.FromPgUUID(txn.ID).String()
+	}
+	settled.Timestamp = time.Now()
+	s.eventBus.Publish(ctx, events.SubjectSupportAdjustmentSettled, settled)
+	return err
+}
+
+func (s *WalletService) creditSupportResolution(ctx context.Context, transactionType, description string, event events.SupportTicketResolvedEvent) (postedTransaction, error) {
+	tripID, err := uuid.Parse(event.TripID)
+	if err != nil {
+		return postedTransaction{}, fmt.Errorf("invalid trip ID: %w", err)
+	}
+	userID, err := uuid.Parse(event.UserID)
+	if err != nil {
+		return postedTransaction{}, fmt.Errorf("invalid user ID: %w", err)
+	}
+	resolvedBy, err := uuid.Parse(event.ResolvedBy)
+	if err != nil {
+		return postedTransaction{}, fmt.Errorf("invalid resolver ID: %w", err)
+	}
+
+	p := posting{
+		transactionType: transactionType,
+		idempotencyKey:  "support:" + event.TicketID,
+		tripID:          utils.ToPgUUID(tripID),
+		description:     description,
+		createdBy:       utils.ToPgUUID(resolvedBy),
+	}
+	owner := utils.ToPgUUID(userID)
+
+	charge, err := s.repo.GetLedgerTransactionByIdempotencyKey(ctx, tripChargeKey(tripID))
+	switch {
+	case err == nil:
+		wallet, err := s.chargedWallet(ctx, charge)
+		if err != nil {
+			return postedTransaction{}, err
+		}
+		owner = wallet.OwnerID
+		p.currency = money.Currency(wallet.Currency)
+		p.reversedID = charge.ID
+	case errors.Is(err, pgx.ErrNoRows):
+		if p.currency, err = s.walletCurrency(event.Currency); err != nil {
+			return postedTransaction{}, err
+		}
+	default:
+		return postedTransaction{}, fmt.Errorf("failed to get trip charge: %w", err)
+	}
+
+	amount := money.FromFloat(event.Amount, p.currency)
+	if amount.Sign() <= 0 {
+		return postedTransaction{}, ErrInvalidAmount
+	}
+	p.legs = []leg{
+		{account: accountRef{accountType: AccountPlatformRevenue}, direction: directionDebit, amount: amount},
+		{account: accountRef{ownerID: owner, accountType: AccountRiderWallet}, direction: directionCredit, amount: amount},
+	}
+	// Credits on a trip, from every ticket and refund together, are capped
+	// at what the rider paid: the wallet charge, or the cash fare support
+	// reports.
+	p.check = func(q *db.Queries) error {
+		paid := money.FromFloat(event.Paid, p.currency)
+		if p.reversedID.Valid {
+			charged, err := walletCharged(ctx, q, p.tripID, p.currency)
+			if err != nil {
+				return err
+			}
+			paid = charged
+		}
+		return checkRefundable(ctx, q, p.tripID, amount, paid)
+	}
+
+	txn, _, err := s.post(ctx, p)
+	return txn, err
+}
+
+// GetReconciliationReport summarises ledger activity between from and to and
+// flags anything that breaks the double-entry invariants.
+func (s *WalletService) GetReconciliationReport(ctx context.Context, from, to time.Time) (*domain.ReconciliationReport, error) {
+	periodStart := pgtype.Timestamp{Time: from, Valid: true}
+	periodEnd := pgtype.Timestamp{Time: to, Valid: true}
+
+	report := &domain.ReconciliationReport{
+		PeriodStart:            from,
+		PeriodEnd:              to,
+		CurrencyTotals:         []domain.CurrencyTotal{},
+		TransactionTotals:      []domain.TransactionTypeTotal{},
+		AccountTotals:          []domain.AccountTypeTotal{},
+		UnbalancedTransactions: []domain.UnbalancedTransaction{},
+		NegativeBalances:       []domain.NegativeBalanceAccount{},
+	}
+
+	typeTotals, err := s.repo.GetTransactionTotalsByType(ctx, db.GetTransactionTotalsByTypeParams{
+		PeriodStart: periodStart,
+		PeriodEnd:   periodEnd,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transaction totals: %w", err)
+	}
+	for _, t := range typeTotals {
+		report.TransactionTotals = append(report.TransactionTotals, domain.TransactionTypeTotal{
+			TransactionType:  t.TransactionType,
+			Currency:         t.Currency,
+			TransactionCount: t.TransactionCount,
+			TotalAmount:      money.FromNumeric(t.TotalAmount, money.Currency(t.Currency)).Float64(),
+		})
+	}
+
+	accountTotals, err := s.repo.GetAccountTypeTotals(ctx, periodEnd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account totals: %w", err)
+	}
+	// Debits and credits only have to balance within each currency.
+	var currencies []money.Currency
+	totalDebits := make(map[money.Currency]money.Money)
+	totalCredits := make(map[money.Currency]money.Money)
+	for _, a := range accountTotals {
+		currency := money.Currency(a.Currency)
+		debits := money.FromNumeric(a.TotalDebits, currency)
+		credits := money.FromNumeric(a.TotalCredits, currency)
+		if _, ok := totalDebits[currency]; !ok {
+			currencies = append(currencies, currency)
+		}
+		totalDebits[currency] = totalDebits[currency].Add(debits)
+		totalCredits[currency] = totalCredits[currency].Add(credits)
+		report.AccountTotals = append(report.AccountTotals, domain.AccountTypeTotal{
+			AccountType:  a.AccountType,
+			Currency:     a.Currency,
+			AccountCount: a.AccountCount,
+			TotalDebits:  debits.Float64(),
+			TotalCredits: credits.Float64(),
+		})
+	}
+	currenciesBalanced := true
+	for _, currency := range currencies {
+		debits, credits := totalDebits[currency], totalCredits[currency]
+		balanced := debits.Cmp(credits) == 0
+		currenciesBalanced = currenciesBalanced && balanced
+		report.CurrencyTotals = append(report.CurrencyTotals, domain.CurrencyTotal{
+			Currency:     currency.String(),
+			Balanced:     balanced,
+			TotalDebits:  debits.Float64(),
+			TotalCredits: credits.Float64(),
+		})
+	}
+
+	unbalanced, err := s.repo.GetUnbalancedTransactions(ctx, db.GetUnbalancedTransactionsParams{
+		PeriodStart: periodStart,
+		PeriodEnd:   periodEnd,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get unbalanced transactions: %w", err)
+	}
+	for _, u := range unbalanced {
+		report.UnbalancedTransactions = append(report.UnbalancedTransactions, domain.UnbalancedTransaction{
+			TransactionID:   utils.FromPgUUID(u.ID).String(),
+			TransactionType: u.TransactionType,
+			IdempotencyKey:  u.IdempotencyKey,
+			TotalDebits:     utils.NumericToFloat64(u.TotalDebits),
+			TotalCredits:    utils.NumericToFloat64(u.TotalCredits),
+			CreatedAt:       u.CreatedAt.Time,
+		})
+	}
+
+	negative, err := s.repo.GetNegativeBalanceAccounts(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get negative balances: %w", err)
+	}
+	for _, n := range negative {
+		report.NegativeBalances = append(report.NegativeBalances, domain.NegativeBalanceAccount{
+			AccountID:   utils.FromPgUUID(n.ID).String(),
+			OwnerID:     utils.FromPgUUID(n.OwnerID).String(),
+			AccountType: n.AccountType,
+			Currency:    n.Currency,
+			Balance:     money.FromNumeric(n.Balance, money.Currency(n.Currency)).Float64(),
+		})
+	}
+
+	report.Balanced = currenciesBalanced &&
+		len(report.UnbalancedTransactions) == 0 &&
+		len(report.NegativeBalances) == 0
+
+	return report, nil
+}
+
+func (s *WalletService) SubscribeToEvents() {
+	// Charge wallet trips once they complete. A queue group keeps multiple
+	// payment-service instances from racing on the same event; the trip
+	// charge idempotency key covers redeliveries.
+	s.eventBus.QueueSubscribe(events.SubjectTripCompleted, "payment-service", func(ctx context.Context, data []byte) {
+		var event events.TripCompletedEvent
+		if err := json.Unmarshal(data, &event); err != nil {
+			log.Printf("Failed to unmarshal trip completed event: %v", err)
+			return
+		}
+
+		if err := s.ChargeTrip(ctx, event); err != nil {
+			log.Printf("Failed to charge wallet for trip %s: %v", event.TripID, err)
+			return
+		}
+	})
+
+	// Cancellation and no-show fees are decided by trip-service's city policy.
+	s.eventBus.QueueSubscribe(events.SubjectTripCancelled, "payment-service", func(ctx context.Context, data []byte) {
+		var event events.TripCancelledEvent
+		if err := json.Unmarshal(data, &event); err != nil {
+			log.Printf("Failed to unmarshal trip cancelled event: %v", err)
+			return
+		}
+
+		if err := s.ChargeCancellationFee(ctx, event); err != nil {
+			log.Printf("Failed to charge cancellation fee for trip %s: %v", event.TripID, err)
+			return
+		}
+	})
+
+	// Refunds and fare adjustments granted when support resolves a ticket.
+	// The ticket ID is the idempotency key, so a redelivery posts nothing
+	// new and just reports the outcome again.
+	s.eventBus.QueueSubscribe(events.SubjectSupportTicketResolved, "payment-service", func(ctx context.Context, data []byte) {
+		var event events.SupportTicketResolvedEvent
+		if err := json.Unmarshal(data, &event); err != nil {
+			log.Printf("Failed to unmarshal support ticket resolved event: %v", err)
+			return
+		}
+
+		if err := s.SettleSupportResolution(ctx, event); err != nil {
+			log.Printf("Failed to settle support ticket %s: %v", event.TicketID, err)
+			return
+		}
+	})
+
+	// Referral rewards are issued by trip-service once the referee completes
+	// their first trip.
+	s.eventBus.QueueSubscribe(events.SubjectReferralRewarded, "payment-service", func(ctx context.Context, data []byte) {
+		var event events.ReferralRewardedEvent
+		if err := json.Unmarshal(data, &event); err != nil {
+			log.Printf("Failed to unmarshal referral rewarded event: %v", err)
+			return
+		}
+
+		if err := s.CreditReferral(ctx, event); err != nil {
+			log.Printf("Failed to credit referral %s: %v", event.ReferralID, err)
+			return
+		}
+	})
+}
+
+// post writes a balanced ledger transaction inside a serializable database
+// transaction. Replaying an idempotency key returns the original transaction
+// and reports created=false, as long as it moved the same amounts between
+// the same accounts. Postings without a currency are in the default
+// currency.
+func (s *WalletService) post(ctx context.Context, p posting) (postedTransaction, bool, error) {
+	var result postedTransaction
+	var created bool
+
+	currency := p.currency
+	if currency == "" {
+		currency = s.currency
+	}
+
+	err := s.repo.WithSerializableTx(ctx, func(q *db.Queries) error {
+		created = false
+
+		existing, err := q.GetLedgerTransactionByIdempotencyKey(ctx, p.idempotencyKey)
+		if err == nil {
+			if existing.TransactionType != p.transactionType {
+				return ErrIdempotencyKeyReused
+			}
+			entries, err := q.GetLedgerEntriesByTransaction(ctx, existing.ID)
+			if err != nil {
+				return err
+			}
+			same, err := sameLegs(ctx, q, entries, p.legs, currency)
+			if err != nil {
+				return err
+			}
+			if !same {
+				return ErrIdempotencyKeyReused
+			}
+			result = postedTransaction{LedgerTransaction: existing, currency: currency, entries: entries}
+			return nil
+		}
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return err
+		}
+
+		if p.check != nil {
+			if err := p.check(q); err != nil {
+				return err
+			}
+		}
+
+		accountIDs := make(map[accountRef]pgtype.UUID, len(p.legs))
+		debits := make(map[accountRef]money.Money)
+		for _, l := range p.legs {
+			if _, ok := accountIDs[l.account]; !ok {
+				id, err := s.ensureAccount(ctx, q, l.account, currency.String())
+				if err != nil {
+					return err
+				}
+				accountIDs[l.account] = id
+			}
+			if l.direction == directionDebit && l.account.ownerID.Valid {
+				debits[l.account] = debits[l.account].Add(l.amount)
+			}
+		}
+
+		for ref, amount := range debits {
+			id := accountIDs[ref]
+			if err := q.LockLedgerAccount(ctx, id); err != nil {
+				return err
+			}
+			balance, err := q.GetAccountBalance(ctx, id)
+			if err != nil {
+				return err
+			}
+			if money.FromNumeric(balance, currency).Cmp(amount) < 0 {
+				return ErrInsufficientBalance
+			}
+		}
+
+		txn, err := q.CreateLedgerTransaction(ctx, db.CreateLedgerTransactionParams{
+			TransactionType:       p.transactionType,
+			IdempotencyKey:        p.idempotencyKey,
+			TripID:                p.tripID,
+			ReversedTransactionID: p.reversedID,
+			Description:           pgtype.Text{String: p.description, Valid: p.description != ""},
+			CreatedBy:             p.createdBy,
+		})
+		if err != nil {
+			return err
+		}
+
+		entries := make([]db.LedgerEntry, 0, len(p.legs))
+		for _, l := range p.legs {
+			entry, err := q.CreateLedgerEntry(ctx, db.CreateLedgerEntryParams{
+				TransactionID: txn.ID,
+				AccountID:     accountIDs[l.account],
+				Direction:     l.direction,
+				Amount:        l.amount.Numeric(),
+			})
+			if err != nil {
+				return err
+			}
+			entries = append(entries, entry)
+		}
+
+		result = postedTransaction{LedgerTransaction: txn, currency: currency, entries: entries}
+		created = true
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, ErrInsufficientBalance) || errors.Is(err, ErrRefundExceedsCharge) || errors.Is(err, ErrIdempotencyKeyReused) {
+			return postedTransaction{}, false, err
+		}
+		return postedTransaction{}, false, fmt.Errorf("failed to post %s: %w", p.transactionType, err)
+	}
+
+	return result, created, nil
+}
+
+// sameLegs reports whether a stored transaction's entries are exactly legs,
+// in currency: the same amounts, each way, on the same accounts.
+func sameLegs(ctx context.Context, q *db.Queries, entries []db.LedgerEntry, legs []leg, currency money.Currency) (bool, error) {
+	if len(entries) != len(legs) {
+		return false, nil
+	}
+
+	type legKey struct {
+		account   accountRef
+		direction string
+		amount    int64
+	}
+	unmatched := make(map[legKey]int, len(legs))
+	for _, l := range legs {
+		unmatched[legKey{l.account, l.direction, l.amount.Minor()}]++
+	}
+
+	for _, entry := range entries {
+		account, err := q.GetLedgerAccountByID(ctx, entry.AccountID)
+		if err != nil {
+			return false, err
+		}
+		if account.Currency != currency.String() {
+			return false, nil
+		}
+		key := legKey{
+			account:   accountRef{ownerID: account.OwnerID, accountType: account.AccountType},
+			direction: entry.Direction,
+			amount:    money.FromNumeric(entry.Amount, currency).Minor(),
+		}
+		if unmatched[key] == 0 {
+			return false, nil
+		}
+		unmatched[key]--
+	}
+	return true, nil
+}
+
+func (s *WalletService) ensureAccount(ctx context.Context, q *db.Queries, ref accountRef, currency string) (pgtype.UUID, error) {
+	var account db.LedgerAccount
+	var err error
+	if ref.ownerID.Valid {
+		account, err = q.GetLedgerAccount(ctx, db.GetLedgerAccountParams{
+			OwnerID:     ref.ownerID,
+			AccountType: ref.accountType,
+			Currency:    currency,
+		})
+	} else {
+		account, err = q.GetSystemLedgerAccount(ctx, db.GetSystemLedgerAccountParams{
+			AccountType: ref.accountType,
+			Currency:    currency,
+		})
+	}
+	if err == nil {
+		return account.ID, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return pgtype.UUID{}, err
+	}
+
+	account, err = q.CreateLedgerAccount(ctx, db.CreateLedgerAccountParams{
+		OwnerID:     ref.ownerID,
+		AccountType: ref.accountType,
+		Currency:    currency,
+	})
+	if err != nil {
+		return pgtype.UUID{}, fmt.Errorf("failed to create %s account: %w", ref.accountType, err)
+	}
+	return account.ID, nil
+}
+
+// chargedWallet returns the rider wallet debited by a trip charge.
+func (s *WalletService) chargedWallet(ctx context.Context, charge db.LedgerTransaction) (db.LedgerAccount, error) {
+	entries, err := s.repo.GetLedgerEntriesByTransaction(ctx, charge.ID)
+	if err != nil {
+		return db.LedgerAccount{}, fmt.Errorf("failed to get trip charge entries: %w", err)
+	}
+
+	for _, entry := range entries {
+		if entry.Direction != directionDebit {
+			continue
+		}
+		account, err := s.repo.GetLedgerAccountByID(ctx, entry.AccountID)
+		if err != nil {
+			return db.LedgerAccount{}, fmt.Errorf("failed to get charged account: %w", err)
+		}
+		if account.AccountType == AccountRiderWallet {
+			return account, nil
+		}
+	}
+	return db.LedgerAccount{}, errors.New("trip charge has no rider wallet debit")
+}
+
+// walletCharged is what a trip's wallet charge took from the rider.
+func walletCharged(ctx context.Context, q *db.Queries, tripID pgtype.UUID, currency money.Currency) (money.Money, error) {
+	charged, err := q.GetTripLedgerTotal(ctx, db.GetTripLedgerTotalParams{TripID: tripID, TransactionType: TransactionTripCharge})
+	if err != nil {
+		return money.Money{}, err
+	}
+	return money.FromNumeric(charged, currency), nil
+}
+
+// checkRefundable makes sure amount, with everything already given back on
+// a trip, doesn't come to more than the rider paid for it.
+func checkRefundable(ctx context.Context, q *db.Queries, tripID pgtype.UUID, amount, paid money.Money) error {
+	currency := amount.Currency()
+	returned := amount
+	for _, transactionType := range []string{TransactionRefund, TransactionFareAdjustment} {
+		total, err := q.GetTripLedgerTotal(ctx, db.GetTripLedgerTotalParams{TripID: tripID, TransactionType: transactionType})
+		if err != nil {
+			return err
+		}
+		returned = returned.Add(money.FromNumeric(total, currency))
+	}
+	if returned.Cmp(paid) > 0 {
+		return ErrRefundExceedsCharge
+	}
+	return nil
+}
+
+// walletCurrency normalises a requested currency, defaulting to the
+// service's default currency.
+func (s *WalletService) walletCurrency(currency string) (money.Currency, error) {
+	if strings.TrimSpace(currency) == "" {
+		return s.currency, nil
+	}
+	c, err := money.ParseCurrency(currency)
+	if err != nil {
+		return "", ErrInvalidCurrency
+	}
+	return c, nil
+}
+
+func tripChargeKey(tripID uuid.UUID) string {
+	return "trip_charge:" + tripID.String()
+}
+
+// scopedKey namespaces a client supplied idempotency key by operation and
+// caller. Without a key every request is treated as a new operation.
+func scopedKey(transactionType string, userID uuid.UUID, key string) string {
+	if key == "" {
+		key = uuid.New().String()
+	}
+	return fmt.Sprintf("%s:%s:%s", transactionType, userID, key)
+}
+
+func toTransactionResponse(txn postedTransaction) *domain.LedgerTransactionResponse {
+	resp := &domain.LedgerTransactionResponse{
+		ID:              utils.FromPgUUID(txn.ID).String(),
+		TransactionType: txn.TransactionType,
+		IdempotencyKey:  txn.IdempotencyKey,
+		Description:     txn.Description.String,
+		Amount:          txn.amount().Float64(),
+		CreatedAt:       txn.CreatedAt.Time,
+	}
+	if txn.TripID.Valid {
+		resp.TripID = utils.FromPgUUID(txn.TripID).String()
+	}
+	return resp
+}
@@ -21,6 +21,7 @@ import (
 	"github.com/namycodes/yanga-services/shared-lib/config"
 	"github.com/namycodes/yanga-services/shared-lib/events"
 	"github.com/namycodes/yanga-services/shared-lib/middleware"
+	"github.com/namycodes/yanga-services/shared-lib/moderation"
 )
 
 // @title Rating Service API
@@ -50,15 +51,16 @@ func main() {
 	log.Println("✅ Connected to NATS")
 
 	queries := db.New(dbPool)
-	ratingRepo := repository.NewRatingRepository(queries)
-	ratingService := service.NewRatingService(ratingRepo, eventBus)
+	ratingRepo := repository.NewRatingRepository(dbPool, queries)
+	ratingService := service.NewRatingService(ratingRepo, eventBus, moderation.New(cfg), cfg)
 	ratingHandler := handler.NewRatingHandler(ratingService)
 
 	router := mux.NewRouter()
+	router.Use(middleware.RequestIDMiddleware)
 	router.Use(middleware.LoggingMiddleware)
 	router.Use(middleware.CORSMiddleware)
 
-	routes.SetupRatingRoutes(router, ratingHandler, config.JWTConfig{Secret: cfg.JWTSecret})
+	routes.SetupRatingRoutes(router, ratingHandler, config.JWTConfig{Secret: cfg.JWTSecret, IdentitySecret: cfg.GatewayIdentitySecret})
 
 	router.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
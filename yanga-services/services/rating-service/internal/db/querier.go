@@ -11,13 +11,40 @@ import (
 )
 
 type Querier interface {
+	AddRatingTag(ctx context.Context, arg AddRatingTagParams) error
+	ClearRatingFlag(ctx context.Context, id pgtype.UUID) (Rating, error)
 	CreateRating(ctx context.Context, arg CreateRatingParams) (Rating, error)
+	CreateRatingDispute(ctx context.Context, arg CreateRatingDisputeParams) (RatingDispute, error)
 	DeleteRating(ctx context.Context, id pgtype.UUID) error
-	GetAverageRating(ctx context.Context, ratedID pgtype.UUID) (GetAverageRatingRow, error)
+	ExcludeRating(ctx context.Context, arg ExcludeRatingParams) (Rating, error)
+	// Each rating counts by its rater's weight, so raters who rate far below
+	// everyone else move the average less. Excluded ratings don't count.
+	GetAverageRating(ctx context.Context, arg GetAverageRatingParams) (GetAverageRatingRow, error)
+	// How many ratings a rater has given as a rider or as a driver, and their
+	// average.
+	GetRaterGivenStats(ctx context.Context, arg GetRaterGivenStatsParams) (GetRaterGivenStatsRow, error)
+	// The average rating all riders, or all drivers, give.
+	GetRaterTypeAverage(ctx context.Context, raterType string) (float64, error)
 	GetRating(ctx context.Context, id pgtype.UUID) (Rating, error)
 	GetRatingByTripAndRater(ctx context.Context, arg GetRatingByTripAndRaterParams) (Rating, error)
+	GetRatingDispute(ctx context.Context, id pgtype.UUID) (RatingDispute, error)
+	GetRatingTagCounts(ctx context.Context, arg GetRatingTagCountsParams) ([]GetRatingTagCountsRow, error)
+	GetRatingTags(ctx context.Context, ratingIds []pgtype.UUID) ([]RatingTag, error)
+	GetTripParticipants(ctx context.Context, id pgtype.UUID) (GetTripParticipantsRow, error)
+	GetTripRatings(ctx context.Context, tripID pgtype.UUID) ([]Rating, error)
+	// Ratings a user received from the other side of their trips: rater_type
+	// 'rider' for ratings of a driver, 'driver' for ratings of a rider.
+	// Excluded ratings are left out.
 	GetUserRatings(ctx context.Context, arg GetUserRatingsParams) ([]GetUserRatingsRow, error)
+	// Ratings whose feedback the content filter flagged, oldest first, for
+	// admins to review.
+	ListFlaggedRatings(ctx context.Context, arg ListFlaggedRatingsParams) ([]Rating, error)
+	ListRatingDisputes(ctx context.Context, arg ListRatingDisputesParams) ([]ListRatingDisputesRow, error)
+	ListUserRatingDisputes(ctx context.Context, disputedBy pgtype.UUID) ([]ListUserRatingDisputesRow, error)
+	ResolveRatingDispute(ctx context.Context, arg ResolveRatingDisputeParams) (RatingDispute, error)
+	RestoreRating(ctx context.Context, id pgtype.UUID) (Rating, error)
 	UpdateRating(ctx context.Context, arg UpdateRatingParams) (Rating, error)
+	UpsertRaterWeight(ctx context.Context, arg UpsertRaterWeightParams) error
 }
 
 var _ Querier = (*Queries)(nil)
@@ -11,24 +11,74 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const addRatingTag = `-- name: AddRatingTag :exec
+INSERT INTO rating_tags (rating_id, tag)
+VALUES ($1, $2)
+ON CONFLICT DO NOTHING
+`
+
+type AddRatingTagParams struct {
+	RatingID pgtype.UUID `json:"rating_id"`
+	Tag      string      `json:"tag"`
+}
+
+func (q *Queries) AddRatingTag(ctx context.Context, arg AddRatingTagParams) error {
+	_, err := q.db.Exec(ctx, addRatingTag, arg.RatingID, arg.Tag)
+	return err
+}
+
+const clearRatingFlag = `-- name: ClearRatingFlag :one
+UPDATE ratings
+SET feedback_flagged = false, flag_reasons = NULL
+WHERE id = $1 AND feedback_flagged
+RETURNING id, trip_id, rater_id, rated_id, rating, feedback, created_at, rater_type, feedback_flagged, flag_reasons, excluded_at, excluded_by, exclusion_reason
+`
+
+func (q *Queries) ClearRatingFlag(ctx context.Context, id pgtype.UUID) (Rating, error) {
+	row := q.db.QueryRow(ctx, clearRatingFlag, id)
+	var i Rating
+	err := row.Scan(
+		&i.ID,
+		&i.TripID,
+		&i.RaterID,
+		&i.RatedID,
+		&i.Rating,
+		&i.Feedback,
+		&i.CreatedAt,
+		&i.RaterType,
+		&i.FeedbackFlagged,
+		&i.FlagReasons,
+		&i.ExcludedAt,
+		&i.ExcludedBy,
+		&i.ExclusionReason,
+	)
+	return i, err
+}
+
 const createRating = `-- name: CreateRating :one
 INSERT INTO ratings (
     trip_id,
     rater_id,
     rated_id,
+    rater_type,
     rating,
-    feedback
+    feedback,
+    feedback_flagged,
+    flag_reasons
 ) VALUES (
-    $1, $2, $3, $4, $5
-) RETURNING id, trip_id, rater_id, rated_id, rating, feedback, created_at
+    $1, $2, $3, $4, $5, $6, $7, $8
+) RETURNING id, trip_id, rater_id, rated_id, rating, feedback, created_at, rater_type, feedback_flagged, flag_reasons, excluded_at, excluded_by, exclusion_reason
 `
 
 type CreateRatingParams struct {
-	TripID   pgtype.UUID `json:"trip_id"`
-	RaterID  pgtype.UUID `json:"rater_id"`
-	RatedID  pgtype.UUID `json:"rated_id"`
-	Rating   int32       `json:"rating"`
-	Feedback pgtype.Text `json:"feedback"`
+	TripID          pgtype.UUID `json:"trip_id"`
+	RaterID         pgtype.UUID `json:"rater_id"`
+	RatedID         pgtype.UUID `json:"rated_id"`
+	RaterType       string      `json:"rater_type"`
+	Rating          int32       `json:"rating"`
+	Feedback        pgtype.Text `json:"feedback"`
+	FeedbackFlagged bool        `json:"feedback_flagged"`
+	FlagReasons     pgtype.Text `json:"flag_reasons"`
 }
 
 func (q *Queries) CreateRating(ctx context.Context, arg CreateRatingParams) (Rating, error) {
@@ -36,8 +86,11 @@ func (q *Queries) CreateRating(ctx context.Context, arg CreateRatingParams) (Rat
 		arg.TripID,
 		arg.RaterID,
 		arg.RatedID,
+		arg.RaterType,
 		arg.Rating,
 		arg.Feedback,
+		arg.FeedbackFlagged,
+		arg.FlagReasons,
 	)
 	var i Rating
 	err := row.Scan(
@@ -48,6 +101,42 @@ func (q *Queries) CreateRating(ctx context.Context, arg CreateRatingParams) (Rat
 		&i.Rating,
 		&i.Feedback,
 		&i.CreatedAt,
+		&i.RaterType,
+		&i.FeedbackFlagged,
+		&i.FlagReasons,
+		&i.ExcludedAt,
+		&i.ExcludedBy,
+		&i.ExclusionReason,
+	)
+	return i, err
+}
+
+const createRatingDispute = `-- name: CreateRatingDispute :one
+INSERT INTO rating_disputes (rating_id, disputed_by, reason)
+VALUES ($1, $2, $3)
+RETURNING id, rating_id, disputed_by, reason, status, resolution_note, reviewed_by, reviewed_at, created_at, updated_at
+`
+
+type CreateRatingDisputeParams struct {
+	RatingID   pgtype.UUID `json:"rating_id"`
+	DisputedBy pgtype.UUID `json:"disputed_by"`
+	Reason     string      `json:"reason"`
+}
+
+func (q *Queries) CreateRatingDispute(ctx context.Context, arg CreateRatingDisputeParams) (RatingDispute, error) {
+	row := q.db.QueryRow(ctx, createRatingDispute, arg.RatingID, arg.DisputedBy, arg.Reason)
+	var i RatingDispute
+	err := row.Scan(
+		&i.ID,
+		&i.RatingID,
+		&i.DisputedBy,
+		&i.Reason,
+		&i.Status,
+		&i.ResolutionNote,
+		&i.ReviewedBy,
+		&i.ReviewedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
 	)
 	return i, err
 }
@@ -61,28 +150,114 @@ func (q *Queries) DeleteRating(ctx context.Context, id pgtype.UUID) error {
 	return err
 }
 
+const excludeRating = `-- name: ExcludeRating :one
+UPDATE ratings
+SET excluded_at = CURRENT_TIMESTAMP,
+    excluded_by = $1,
+    exclusion_reason = $2
+WHERE id = $3 AND excluded_at IS NULL
+RETURNING id, trip_id, rater_id, rated_id, rating, feedback, created_at, rater_type, feedback_flagged, flag_reasons, excluded_at, excluded_by, exclusion_reason
+`
+
+type ExcludeRatingParams struct {
+	ExcludedBy      pgtype.UUID `json:"excluded_by"`
+	ExclusionReason pgtype.Text `json:"exclusion_reason"`
+	ID              pgtype.UUID `json:"id"`
+}
+
+func (q *Queries) ExcludeRating(ctx context.Context, arg ExcludeRatingParams) (Rating, error) {
+	row := q.db.QueryRow(ctx, excludeRating, arg.ExcludedBy, arg.ExclusionReason, arg.ID)
+	var i Rating
+	err := row.Scan(
+		&i.ID,
+		&i.TripID,
+		&i.RaterID,
+		&i.RatedID,
+		&i.Rating,
+		&i.Feedback,
+		&i.CreatedAt,
+		&i.RaterType,
+		&i.FeedbackFlagged,
+		&i.FlagReasons,
+		&i.ExcludedAt,
+		&i.ExcludedBy,
+		&i.ExclusionReason,
+	)
+	return i, err
+}
+
 const getAverageRating = `-- name: GetAverageRating :one
-SELECT 
-    COALESCE(AVG(rating), 0) as average_rating,
+SELECT
+    COALESCE(SUM(r.rating * COALESCE(w.weight, 1)) / NULLIF(SUM(COALESCE(w.weight, 1)), 0), 0)::float8 as average_rating,
     COUNT(*) as total_ratings
-FROM ratings
-WHERE rated_id = $1
+FROM ratings r
+LEFT JOIN rater_weights w ON w.rater_id = r.rater_id AND w.rater_type = r.rater_type
+WHERE r.rated_id = $1 AND r.rater_type = $2
+  AND r.excluded_at IS NULL
 `
 
+type GetAverageRatingParams struct {
+	RatedID   pgtype.UUID `json:"rated_id"`
+	RaterType string      `json:"rater_type"`
+}
+
 type GetAverageRatingRow struct {
-	AverageRating interface{} `json:"average_rating"`
-	TotalRatings  int64       `json:"total_ratings"`
+	AverageRating float64 `json:"average_rating"`
+	TotalRatings  int64   `json:"total_ratings"`
 }
 
-func (q *Queries) GetAverageRating(ctx context.Context, ratedID pgtype.UUID) (GetAverageRatingRow, error) {
-	row := q.db.QueryRow(ctx, getAverageRating, ratedID)
+// Each rating counts by its rater's weight, so raters who rate far below
+// everyone else move the average less. Excluded ratings don't count.
+func (q *Queries) GetAverageRating(ctx context.Context, arg GetAverageRatingParams) (GetAverageRatingRow, error) {
+	row := q.db.QueryRow(ctx, getAverageRating, arg.RatedID, arg.RaterType)
 	var i GetAverageRatingRow
 	err := row.Scan(&i.AverageRating, &i.TotalRatings)
 	return i, err
 }
 
+const getRaterGivenStats = `-- name: GetRaterGivenStats :one
+SELECT
+    COUNT(*) as ratings_given,
+    COALESCE(AVG(rating), 0)::float8 as average_given
+FROM ratings
+WHERE rater_id = $1 AND rater_type = $2 AND excluded_at IS NULL
+`
+
+type GetRaterGivenStatsParams struct {
+	RaterID   pgtype.UUID `json:"rater_id"`
+	RaterType string      `json:"rater_type"`
+}
+
+type GetRaterGivenStatsRow struct {
+	RatingsGiven int64   `json:"ratings_given"`
+	AverageGiven float64 `json:"average_given"`
+}
+
+// How many ratings a rater has given as a rider or as a driver, and their
+// average.
+func (q *Queries) GetRaterGivenStats(ctx context.Context, arg GetRaterGivenStatsParams) (GetRaterGivenStatsRow, error) {
+	row := q.db.QueryRow(ctx, getRaterGivenStats, arg.RaterID, arg.RaterType)
+	var i GetRaterGivenStatsRow
+	err := row.Scan(&i.RatingsGiven, &i.AverageGiven)
+	return i, err
+}
+
+const getRaterTypeAverage = `-- name: GetRaterTypeAverage :one
+SELECT COALESCE(AVG(rating), 0)::float8 as average_rating
+FROM ratings
+WHERE rater_type = $1 AND excluded_at IS NULL
+`
+
+// The average rating all riders, or all drivers, give.
+func (q *Queries) GetRaterTypeAverage(ctx context.Context, raterType string) (float64, error) {
+	row := q.db.QueryRow(ctx, getRaterTypeAverage, raterType)
+	var average_rating float64
+	err := row.Scan(&average_rating)
+	return average_rating, err
+}
+
 const getRating = `-- name: GetRating :one
-SELECT id, trip_id, rater_id, rated_id, rating, feedback, created_at FROM ratings
+SELECT id, trip_id, rater_id, rated_id, rating, feedback, created_at, rater_type, feedback_flagged, flag_reasons, excluded_at, excluded_by, exclusion_reason FROM ratings
 WHERE id = $1 LIMIT 1
 `
 
@@ -97,12 +272,18 @@ func (q *Queries) GetRating(ctx context.Context, id pgtype.UUID) (Rating, error)
 		&i.Rating,
 		&i.Feedback,
 		&i.CreatedAt,
+		&i.RaterType,
+		&i.FeedbackFlagged,
+		&i.FlagReasons,
+		&i.ExcludedAt,
+		&i.ExcludedBy,
+		&i.ExclusionReason,
 	)
 	return i, err
 }
 
 const getRatingByTripAndRater = `-- name: GetRatingByTripAndRater :one
-SELECT id, trip_id, rater_id, rated_id, rating, feedback, created_at FROM ratings
+SELECT id, trip_id, rater_id, rated_id, rating, feedback, created_at, rater_type, feedback_flagged, flag_reasons, excluded_at, excluded_by, exclusion_reason FROM ratings
 WHERE trip_id = $1 AND rater_id = $2
 LIMIT 1
 `
@@ -123,38 +304,216 @@ func (q *Queries) GetRatingByTripAndRater(ctx context.Context, arg GetRatingByTr
 		&i.Rating,
 		&i.Feedback,
 		&i.CreatedAt,
+		&i.RaterType,
+		&i.FeedbackFlagged,
+		&i.FlagReasons,
+		&i.ExcludedAt,
+		&i.ExcludedBy,
+		&i.ExclusionReason,
+	)
+	return i, err
+}
+
+const getRatingDispute = `-- name: GetRatingDispute :one
+SELECT id, rating_id, disputed_by, reason, status, resolution_note, reviewed_by, reviewed_at, created_at, updated_at FROM rating_disputes
+WHERE id = $1
+`
+
+func (q *Queries) GetRatingDispute(ctx context.Context, id pgtype.UUID) (RatingDispute, error) {
+	row := q.db.QueryRow(ctx, getRatingDispute, id)
+	var i RatingDispute
+	err := row.Scan(
+		&i.ID,
+		&i.RatingID,
+		&i.DisputedBy,
+		&i.Reason,
+		&i.Status,
+		&i.ResolutionNote,
+		&i.ReviewedBy,
+		&i.ReviewedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
 	)
 	return i, err
 }
 
+const getRatingTagCounts = `-- name: GetRatingTagCounts :many
+SELECT t.tag, COUNT(*) as count
+FROM rating_tags t
+JOIN ratings r ON r.id = t.rating_id
+WHERE r.rated_id = $1 AND r.rater_type = $2
+  AND r.excluded_at IS NULL
+GROUP BY t.tag
+ORDER BY count DESC, t.tag
+`
+
+type GetRatingTagCountsParams struct {
+	RatedID   pgtype.UUID `json:"rated_id"`
+	RaterType string      `json:"rater_type"`
+}
+
+type GetRatingTagCountsRow struct {
+	Tag   string `json:"tag"`
+	Count int64  `json:"count"`
+}
+
+func (q *Queries) GetRatingTagCounts(ctx context.Context, arg GetRatingTagCountsParams) ([]GetRatingTagCountsRow, error) {
+	rows, err := q.db.Query(ctx, getRatingTagCounts, arg.RatedID, arg.RaterType)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetRatingTagCountsRow{}
+	for rows.Next() {
+		var i GetRatingTagCountsRow
+		if err := rows.Scan(&i.Tag, &i.Count); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRatingTags = `-- name: GetRatingTags :many
+SELECT rating_id, tag FROM rating_tags
+WHERE rating_id = ANY($1::uuid[])
+ORDER BY rating_id, tag
+`
+
+func (q *Queries) GetRatingTags(ctx context.Context, ratingIds []pgtype.UUID) ([]RatingTag, error) {
+	rows, err := q.db.Query(ctx, getRatingTags, ratingIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []RatingTag{}
+	for rows.Next() {
+		var i RatingTag
+		if err := rows.Scan(&i.RatingID, &i.Tag); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTripParticipants = `-- name: GetTripParticipants :one
+SELECT id, user_id, driver_id, status, completed_at
+FROM trips
+WHERE id = $1
+`
+
+type GetTripParticipantsRow struct {
+	ID          pgtype.UUID      `json:"id"`
+	UserID      pgtype.UUID      `json:"user_id"`
+	DriverID    pgtype.UUID      `json:"driver_id"`
+	Status      string           `json:"status"`
+	CompletedAt pgtype.Timestamp `json:"completed_at"`
+}
+
+func (q *Queries) GetTripParticipants(ctx context.Context, id pgtype.UUID) (GetTripParticipantsRow, error) {
+	row := q.db.QueryRow(ctx, getTripParticipants, id)
+	var i GetTripParticipantsRow
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.DriverID,
+		&i.Status,
+		&i.CompletedAt,
+	)
+	return i, err
+}
+
+const getTripRatings = `-- name: GetTripRatings :many
+SELECT id, trip_id, rater_id, rated_id, rating, feedback, created_at, rater_type, feedback_flagged, flag_reasons, excluded_at, excluded_by, exclusion_reason FROM ratings
+WHERE trip_id = $1
+ORDER BY created_at
+`
+
+func (q *Queries) GetTripRatings(ctx context.Context, tripID pgtype.UUID) ([]Rating, error) {
+	rows, err := q.db.Query(ctx, getTripRatings, tripID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Rating{}
+	for rows.Next() {
+		var i Rating
+		if err := rows.Scan(
+			&i.ID,
+			&i.TripID,
+			&i.RaterID,
+			&i.RatedID,
+			&i.Rating,
+			&i.Feedback,
+			&i.CreatedAt,
+			&i.RaterType,
+			&i.FeedbackFlagged,
+			&i.FlagReasons,
+			&i.ExcludedAt,
+			&i.ExcludedBy,
+			&i.ExclusionReason,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getUserRatings = `-- name: GetUserRatings :many
-SELECT r.id, r.trip_id, r.rater_id, r.rated_id, r.rating, r.feedback, r.created_at, u.full_name as rater_name
+SELECT r.id, r.trip_id, r.rater_id, r.rated_id, r.rating, r.feedback, r.created_at, r.rater_type, r.feedback_flagged, r.flag_reasons, r.excluded_at, r.excluded_by, r.exclusion_reason, u.full_name as rater_name
 FROM ratings r
 JOIN users u ON r.rater_id = u.id
-WHERE r.rated_id = $1
+WHERE r.rated_id = $1 AND r.rater_type = $2
+  AND r.excluded_at IS NULL
 ORDER BY r.created_at DESC
-LIMIT $2 OFFSET $3
+LIMIT $3 OFFSET $4
 `
 
 type GetUserRatingsParams struct {
-	RatedID pgtype.UUID `json:"rated_id"`
-	Limit   int32       `json:"limit"`
-	Offset  int32       `json:"offset"`
+	RatedID   pgtype.UUID `json:"rated_id"`
+	RaterType string      `json:"rater_type"`
+	Limit     int32       `json:"limit"`
+	Offset    int32       `json:"offset"`
 }
 
 type GetUserRatingsRow struct {
-	ID        pgtype.UUID      `json:"id"`
-	TripID    pgtype.UUID      `json:"trip_id"`
-	RaterID   pgtype.UUID      `json:"rater_id"`
-	RatedID   pgtype.UUID      `json:"rated_id"`
-	Rating    int32            `json:"rating"`
-	Feedback  pgtype.Text      `json:"feedback"`
-	CreatedAt pgtype.Timestamp `json:"created_at"`
-	RaterName string           `json:"rater_name"`
+	ID              pgtype.UUID      `json:"id"`
+	TripID          pgtype.UUID      `json:"trip_id"`
+	RaterID         pgtype.UUID      `json:"rater_id"`
+	RatedID         pgtype.UUID      `json:"rated_id"`
+	Rating          int32            `json:"rating"`
+	Feedback        pgtype.Text      `json:"feedback"`
+	CreatedAt       pgtype.Timestamp `json:"created_at"`
+	RaterType       string           `json:"rater_type"`
+	FeedbackFlagged bool             `json:"feedback_flagged"`
+	FlagReasons     pgtype.Text      `json:"flag_reasons"`
+	ExcludedAt      pgtype.Timestamp `json:"excluded_at"`
+	ExcludedBy      pgtype.UUID      `json:"excluded_by"`
+	ExclusionReason pgtype.Text      `json:"exclusion_reason"`
+	RaterName       string           `json:"rater_name"`
 }
 
+// Ratings a user received from the other side of their trips: rater_type
+// 'rider' for ratings of a driver, 'driver' for ratings of a rider.
+// Excluded ratings are left out.
 func (q *Queries) GetUserRatings(ctx context.Context, arg GetUserRatingsParams) ([]GetUserRatingsRow, error) {
-	rows, err := q.db.Query(ctx, getUserRatings, arg.RatedID, arg.Limit, arg.Offset)
+	rows, err := q.db.Query(ctx, getUserRatings,
+		arg.RatedID,
+		arg.RaterType,
+		arg.Limit,
+		arg.Offset,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -170,6 +529,12 @@ func (q *Queries) GetUserRatings(ctx context.Context, arg GetUserRatingsParams)
 			&i.Rating,
 			&i.Feedback,
 			&i.CreatedAt,
+			&i.RaterType,
+			&i.FeedbackFlagged,
+			&i.FlagReasons,
+			&i.ExcludedAt,
+			&i.ExcludedBy,
+			&i.ExclusionReason,
 			&i.RaterName,
 		); err != nil {
 			return nil, err
@@ -182,11 +547,254 @@ func (q *Queries) GetUserRatings(ctx context.Context, arg GetUserRatingsParams)
 	return items, nil
 }
 
+const listFlaggedRatings = `-- name: ListFlaggedRatings :many
+SELECT id, trip_id, rater_id, rated_id, rating, feedback, created_at, rater_type, feedback_flagged, flag_reasons, excluded_at, excluded_by, exclusion_reason FROM ratings
+WHERE feedback_flagged AND excluded_at IS NULL
+ORDER BY created_at
+LIMIT $1 OFFSET $2
+`
+
+type ListFlaggedRatingsParams struct {
+	Limit  int32 `json:"limit"`
+	Offset int32 `json:"offset"`
+}
+
+// Ratings whose feedback the content filter flagged, oldest first, for
+// admins to review.
+func (q *Queries) ListFlaggedRatings(ctx context.Context, arg ListFlaggedRatingsParams) ([]Rating, error) {
+	rows, err := q.db.Query(ctx, listFlaggedRatings, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Rating{}
+	for rows.Next() {
+		var i Rating
+		if err := rows.Scan(
+			&i.ID,
+			&i.TripID,
+			&i.RaterID,
+			&i.RatedID,
+			&i.Rating,
+			&i.Feedback,
+			&i.CreatedAt,
+			&i.RaterType,
+			&i.FeedbackFlagged,
+			&i.FlagReasons,
+			&i.ExcludedAt,
+			&i.ExcludedBy,
+			&i.ExclusionReason,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRatingDisputes = `-- name: ListRatingDisputes :many
+SELECT d.id, d.rating_id, d.disputed_by, d.reason, d.status, d.resolution_note, d.reviewed_by, d.reviewed_at, d.created_at, d.updated_at, r.rating, r.feedback, r.rater_id, r.trip_id
+FROM rating_disputes d
+JOIN ratings r ON r.id = d.rating_id
+WHERE $1::text IS NULL OR d.status = $1::text
+ORDER BY d.created_at
+LIMIT $2 OFFSET $3
+`
+
+type ListRatingDisputesParams struct {
+	Status pgtype.Text `json:"status"`
+	Limit  int32       `json:"limit"`
+	Offset int32       `json:"offset"`
+}
+
+type ListRatingDisputesRow struct {
+	ID             pgtype.UUID      `json:"id"`
+	RatingID       pgtype.UUID      `json:"rating_id"`
+	DisputedBy     pgtype.UUID      `json:"disputed_by"`
+	Reason         string           `json:"reason"`
+	Status         string           `json:"status"`
+	ResolutionNote pgtype.Text      `json:"resolution_note"`
+	ReviewedBy     pgtype.UUID      `json:"reviewed_by"`
+	ReviewedAt     pgtype.Timestamp `json:"reviewed_at"`
+	CreatedAt      pgtype.Timestamp `json:"created_at"`
+	UpdatedAt      pgtype.Timestamp `json:"updated_at"`
+	Rating         int32            `json:"rating"`
+	Feedback       pgtype.Text      `json:"feedback"`
+	RaterID        pgtype.UUID      `json:"rater_id"`
+	TripID         pgtype.UUID      `json:"trip_id"`
+}
+
+func (q *Queries) ListRatingDisputes(ctx context.Context, arg ListRatingDisputesParams) ([]ListRatingDisputesRow, error) {
+	rows, err := q.db.Query(ctx, listRatingDisputes, arg.Status, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListRatingDisputesRow{}
+	for rows.Next() {
+		var i ListRatingDisputesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.RatingID,
+			&i.DisputedBy,
+			&i.Reason,
+			&i.Status,
+			&i.ResolutionNote,
+			&i.ReviewedBy,
+			&i.ReviewedAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Rating,
+			&i.Feedback,
+			&i.RaterID,
+			&i.TripID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUserRatingDisputes = `-- name: ListUserRatingDisputes :many
+SELECT d.id, d.rating_id, d.disputed_by, d.reason, d.status, d.resolution_note, d.reviewed_by, d.reviewed_at, d.created_at, d.updated_at, r.rating, r.feedback, r.rater_id, r.trip_id
+FROM rating_disputes d
+JOIN ratings r ON r.id = d.rating_id
+WHERE d.disputed_by = $1
+ORDER BY d.created_at DESC
+`
+
+type ListUserRatingDisputesRow struct {
+	ID             pgtype.UUID      `json:"id"`
+	RatingID       pgtype.UUID      `json:"rating_id"`
+	DisputedBy     pgtype.UUID      `json:"disputed_by"`
+	Reason         string           `json:"reason"`
+	Status         string           `json:"status"`
+	ResolutionNote pgtype.Text      `json:"resolution_note"`
+	ReviewedBy     pgtype.UUID      `json:"reviewed_by"`
+	ReviewedAt     pgtype.Timestamp `json:"reviewed_at"`
+	CreatedAt      pgtype.Timestamp `json:"created_at"`
+	UpdatedAt      pgtype.Timestamp `json:"updated_at"`
+	Rating         int32            `json:"rating"`
+	Feedback       pgtype.Text      `json:"feedback"`
+	RaterID        pgtype.UUID      `json:"rater_id"`
+	TripID         pgtype.UUID      `json:"trip_id"`
+}
+
+func (q *Queries) ListUserRatingDisputes(ctx context.Context, disputedBy pgtype.UUID) ([]ListUserRatingDisputesRow, error) {
+	rows, err := q.db.Query(ctx, listUserRatingDisputes, disputedBy)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListUserRatingDisputesRow{}
+	for rows.Next() {
+		var i ListUserRatingDisputesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.RatingID,
+			&i.DisputedBy,
+			&i.Reason,
+			&i.Status,
+			&i.ResolutionNote,
+			&i.ReviewedBy,
+			&i.ReviewedAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Rating,
+			&i.Feedback,
+			&i.RaterID,
+			&i.TripID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const resolveRatingDispute = `-- name: ResolveRatingDispute :one
+UPDATE rating_disputes
+SET status = $1,
+    resolution_note = $2,
+    reviewed_by = $3,
+    reviewed_at = CURRENT_TIMESTAMP
+WHERE id = $4 AND status = 'pending'
+RETURNING id, rating_id, disputed_by, reason, status, resolution_note, reviewed_by, reviewed_at, created_at, updated_at
+`
+
+type ResolveRatingDisputeParams struct {
+	Status         string      `json:"status"`
+	ResolutionNote pgtype.Text `json:"resolution_note"`
+	ReviewedBy     pgtype.UUID `json:"reviewed_by"`
+	ID             pgtype.UUID `json:"id"`
+}
+
+func (q *Queries) ResolveRatingDispute(ctx context.Context, arg ResolveRatingDisputeParams) (RatingDispute, error) {
+	row := q.db.QueryRow(ctx, resolveRatingDispute,
+		arg.Status,
+		arg.ResolutionNote,
+		arg.ReviewedBy,
+		arg.ID,
+	)
+	var i RatingDispute
+	err := row.Scan(
+		&i.ID,
+		&i.RatingID,
+		&i.DisputedBy,
+		&i.Reason,
+		&i.Status,
+		&i.ResolutionNote,
+		&i.ReviewedBy,
+		&i.ReviewedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const restoreRating = `-- name: RestoreRating :one
+UPDATE ratings
+SET excluded_at = NULL, excluded_by = NULL, exclusion_reason = NULL
+WHERE id = $1 AND excluded_at IS NOT NULL
+RETURNING id, trip_id, rater_id, rated_id, rating, feedback, created_at, rater_type, feedback_flagged, flag_reasons, excluded_at, excluded_by, exclusion_reason
+`
+
+func (q *Queries) RestoreRating(ctx context.Context, id pgtype.UUID) (Rating, error) {
+	row := q.db.QueryRow(ctx, restoreRating, id)
+	var i Rating
+	err := row.Scan(
+		&i.ID,
+		&i.TripID,
+		&i.RaterID,
+		&i.RatedID,
+		&i.Rating,
+		&i.Feedback,
+		&i.CreatedAt,
+		&i.RaterType,
+		&i.FeedbackFlagged,
+		&i.FlagReasons,
+		&i.ExcludedAt,
+		&i.ExcludedBy,
+		&i.ExclusionReason,
+	)
+	return i, err
+}
+
 const updateRating = `-- name: UpdateRating :one
 UPDATE ratings
 SET rating = $2, feedback = $3
 WHERE id = $1
-RETURNING id, trip_id, rater_id, rated_id, rating, feedback, created_at
+RETURNING id, trip_id, rater_id, rated_id, rating, feedback, created_at, rater_type, feedback_flagged, flag_reasons, excluded_at, excluded_by, exclusion_reason
 `
 
 type UpdateRatingParams struct {
@@ -206,6 +814,41 @@ func (q *Queries) UpdateRating(ctx context.Context, arg UpdateRatingParams) (Rat
 		&i.Rating,
 		&i.Feedback,
 		&i.CreatedAt,
+		&i.RaterType,
+		&i.FeedbackFlagged,
+		&i.FlagReasons,
+		&i.ExcludedAt,
+		&i.ExcludedBy,
+		&i.ExclusionReason,
 	)
 	return i, err
 }
+
+const upsertRaterWeight = `-- name: UpsertRaterWeight :exec
+INSERT INTO rater_weights (rater_id, rater_type, ratings_given, average_given, weight, updated_at)
+VALUES ($1, $2, $3, $4, $5, CURRENT_TIMESTAMP)
+ON CONFLICT (rater_id, rater_type) DO UPDATE
+SET ratings_given = EXCLUDED.ratings_given,
+    average_given = EXCLUDED.average_given,
+    weight = EXCLUDED.weight,
+    updated_at = EXCLUDED.updated_at
+`
+
+type UpsertRaterWeightParams struct {
+	RaterID      pgtype.UUID    `json:"rater_id"`
+	RaterType    string         `json:"rater_type"`
+	RatingsGiven int32          `json:"ratings_given"`
+	AverageGiven pgtype.Numeric `json:"average_given"`
+	Weight       pgtype.Numeric `json:"weight"`
+}
+
+func (q *Queries) UpsertRaterWeight(ctx context.Context, arg UpsertRaterWeightParams) error {
+	_, err := q.db.Exec(ctx, upsertRaterWeight,
+		arg.RaterID,
+		arg.RaterType,
+		arg.RatingsGiven,
+		arg.AverageGiven,
+		arg.Weight,
+	)
+	return err
+}
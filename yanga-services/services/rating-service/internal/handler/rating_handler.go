@@ -2,11 +2,14 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
+	"strconv"
 
 	"github.com/gorilla/mux"
 	"github.com/namycodes/yanga-services/services/rating-service/internal/service"
 	"github.com/namycodes/yanga-services/shared-lib/domain"
+	"github.com/namycodes/yanga-services/shared-lib/middleware"
 	"github.com/namycodes/yanga-services/shared-lib/utils"
 )
 
@@ -21,13 +24,17 @@ func NewRatingHandler(ratingService *service.RatingService) *RatingHandler {
 }
 
 // CreateRating godoc
-// @Summary Create a rating
+// @Summary Rate the other party of a completed trip
+// @Description Riders rate their driver and drivers rate their rider, once per trip and within RATING_WINDOW_HOURS of completion.
 // @Tags ratings
 // @Accept json
 // @Produce json
 // @Param request body domain.CreateRatingRequest true "Rating details"
 // @Success 201 {object} domain.RatingResponse
 // @Failure 400 {object} domain.ErrorResponse
+// @Failure 403 {object} domain.ErrorResponse
+// @Failure 409 {object} domain.ErrorResponse
+// @Failure 422 {object} domain.ErrorResponse
 // @Router /ratings [post]
 // @Security BearerAuth
 func (h *RatingHandler) CreateRating(w http.ResponseWriter, r *http.Request) {
@@ -37,38 +44,128 @@ func (h *RatingHandler) CreateRating(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	userID := r.Context().Value("user_id").(string)
-	req.RaterID = userID
+	userIDStr := r.Context().Value("user_id").(string)
+	userID, err := utils.ParseUUID(userIDStr)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
 
-	response, err := h.ratingService.CreateRating(r.Context(), &req)
+	response, err := h.ratingService.CreateRating(r.Context(), userID, &req)
 	if err != nil {
-		utils.HandleServiceError(w, err)
+		handleRatingError(w, err)
 		return
 	}
 
 	utils.SuccessResponse(w, http.StatusCreated, "Rating created successfully", response)
 }
 
-// GetTripRating godoc
-// @Summary Get rating for a trip
+// GetMyRatings godoc
+// @Summary Get the ratings the current user received
+// @Tags ratings
+// @Produce json
+// @Param as query string false "driver or rider, defaults to the user's role"
+// @Param limit query int false "Limit" default(20)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {array} domain.RatingResponse
+// @Router /ratings/my [get]
+// @Security BearerAuth
+func (h *RatingHandler) GetMyRatings(w http.ResponseWriter, r *http.Request) {
+	userIDStr := r.Context().Value("user_id").(string)
+	userID, err := utils.ParseUUID(userIDStr)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	ratings, err := h.ratingService.GetUserRatings(r.Context(), userID, ratedAs(r), queryInt(r, "limit", 20), queryInt(r, "offset", 0))
+	if err != nil {
+		handleRatingError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Ratings retrieved successfully", ratings)
+}
+
+// GetMyRatingSummary godoc
+// @Summary Get the current user's average rating and tag counts
+// @Tags ratings
+// @Produce json
+// @Param as query string false "driver or rider, defaults to the user's role"
+// @Success 200 {object} domain.RatingSummaryResponse
+// @Router /ratings/my/summary [get]
+// @Security BearerAuth
+func (h *RatingHandler) GetMyRatingSummary(w http.ResponseWriter, r *http.Request) {
+	userIDStr := r.Context().Value("user_id").(string)
+	userID, err := utils.ParseUUID(userIDStr)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	summary, err := h.ratingService.GetRatingSummary(r.Context(), userID, ratedAs(r))
+	if err != nil {
+		handleRatingError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Rating summary retrieved", summary)
+}
+
+// GetTripRatings godoc
+// @Summary Get the ratings given on a trip
 // @Tags ratings
 // @Produce json
 // @Param trip_id path string true "Trip ID"
-// @Success 200 {object} domain.RatingResponse
+// @Success 200 {array} domain.RatingResponse
 // @Failure 404 {object} domain.ErrorResponse
 // @Router /ratings/trip/{trip_id} [get]
 // @Security BearerAuth
-func (h *RatingHandler) GetTripRating(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	tripID := vars["trip_id"]
+func (h *RatingHandler) GetTripRatings(w http.ResponseWriter, r *http.Request) {
+	tripID, err := utils.ParseUUID(mux.Vars(r)["trip_id"])
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid trip ID")
+		return
+	}
 
-	response, err := h.ratingService.GetTripRating(r.Context(), tripID)
+	userIDStr := r.Context().Value("user_id").(string)
+	userID, err := utils.ParseUUID(userIDStr)
 	if err != nil {
-		utils.HandleServiceError(w, err)
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid user ID")
 		return
 	}
 
-	utils.SuccessResponse(w, http.StatusOK, "Rating retrieved successfully", response)
+	ratings, err := h.ratingService.GetTripRatings(r.Context(), userID, middleware.GetUserRole(r.Context()), tripID)
+	if err != nil {
+		handleRatingError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Ratings retrieved successfully", ratings)
+}
+
+// GetRiderRatingSummary godoc
+// @Summary Get a rider's average rating and tag counts (drivers and admins)
+// @Tags ratings
+// @Produce json
+// @Param rider_id path string true "Rider ID"
+// @Success 200 {object} domain.RatingSummaryResponse
+// @Router /ratings/rider/{rider_id}/average [get]
+// @Security BearerAuth
+func (h *RatingHandler) GetRiderRatingSummary(w http.ResponseWriter, r *http.Request) {
+	riderID, err := utils.ParseUUID(mux.Vars(r)["rider_id"])
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid rider ID")
+		return
+	}
+
+	summary, err := h.ratingService.GetRatingSummary(r.Context(), riderID, domain.RaterTypeRider)
+	if err != nil {
+		handleRatingError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Rating summary retrieved", summary)
 }
 
 // GetDriverRatings godoc
@@ -76,15 +173,20 @@ func (h *RatingHandler) GetTripRating(w http.ResponseWriter, r *http.Request) {
 // @Tags ratings
 // @Produce json
 // @Param driver_id path string true "Driver ID"
+// @Param limit query int false "Limit" default(10)
+// @Param offset query int false "Offset" default(0)
 // @Success 200 {array} domain.RatingResponse
 // @Router /ratings/driver/{driver_id} [get]
 func (h *RatingHandler) GetDriverRatings(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	driverID := vars["driver_id"]
+	driverID, err := utils.ParseUUID(mux.Vars(r)["driver_id"])
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid driver ID")
+		return
+	}
 
-	ratings, err := h.ratingService.GetDriverRatings(r.Context(), driverID, 10, 0)
+	ratings, err := h.ratingService.GetUserRatings(r.Context(), driverID, domain.RaterTypeDriver, queryInt(r, "limit", 10), queryInt(r, "offset", 0))
 	if err != nil {
-		utils.HandleServiceError(w, err)
+		handleRatingError(w, err)
 		return
 	}
 
@@ -92,24 +194,70 @@ func (h *RatingHandler) GetDriverRatings(w http.ResponseWriter, r *http.Request)
 }
 
 // GetDriverAverageRating godoc
-// @Summary Get driver average rating
+// @Summary Get driver average rating and tag counts
 // @Tags ratings
 // @Produce json
 // @Param driver_id path string true "Driver ID"
-// @Success 200 {object} domain.AverageRatingResponse
+// @Success 200 {object} domain.RatingSummaryResponse
 // @Router /ratings/driver/{driver_id}/average [get]
 func (h *RatingHandler) GetDriverAverageRating(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	driverID := vars["driver_id"]
+	driverID, err := utils.ParseUUID(mux.Vars(r)["driver_id"])
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid driver ID")
+		return
+	}
 
-	avgRating, err := h.ratingService.GetAverageDriverRating(r.Context(), driverID)
+	summary, err := h.ratingService.GetRatingSummary(r.Context(), driverID, domain.RaterTypeDriver)
 	if err != nil {
-		utils.HandleServiceError(w, err)
+		handleRatingError(w, err)
 		return
 	}
 
-	utils.SuccessResponse(w, http.StatusOK, "Average rating retrieved", map[string]interface{}{
-		"driver_id":      driverID,
-		"average_rating": avgRating,
-	})
+	utils.SuccessResponse(w, http.StatusOK, "Average rating retrieved", summary)
+}
+
+func handleRatingError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidRating),
+		errors.Is(err, service.ErrInvalidModeration):
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, service.ErrNotTripParticipant),
+		errors.Is(err, service.ErrDisputeNotAllowed):
+		utils.ErrorResponse(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, service.ErrTripNotFound),
+		errors.Is(err, service.ErrRatingNotFound),
+		errors.Is(err, service.ErrDisputeNotFound):
+		utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrAlreadyRated),
+		errors.Is(err, service.ErrAlreadyDisputed),
+		errors.Is(err, service.ErrDisputeResolved),
+		errors.Is(err, service.ErrModerationConflict):
+		utils.ErrorResponse(w, http.StatusConflict, err.Error())
+	case errors.Is(err, service.ErrTripNotCompleted),
+		errors.Is(err, service.ErrRatingWindowClosed),
+		errors.Is(err, service.ErrDisputeWindowClosed):
+		utils.ErrorResponse(w, http.StatusUnprocessableEntity, err.Error())
+	default:
+		utils.HandleServiceError(w, err)
+	}
+}
+
+// ratedAs returns whether the caller wants their ratings as a driver or as
+// a rider, from the "as" query parameter or else their role.
+func ratedAs(r *http.Request) string {
+	if as := r.URL.Query().Get("as"); as != "" {
+		return as
+	}
+	if middleware.GetUserRole(r.Context()) == "driver" {
+		return domain.RaterTypeDriver
+	}
+	return domain.RaterTypeRider
+}
+
+func queryInt(r *http.Request, name string, defaultValue int32) int32 {
+	value, err := strconv.Atoi(r.URL.Query().Get(name))
+	if err != nil || value < 0 {
+		return defaultValue
+	}
+	return int32(value)
 }
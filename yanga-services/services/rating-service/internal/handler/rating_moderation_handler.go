@@ -0,0 +1,203 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/namycodes/yanga-services/shared-lib/domain"
+	"github.com/namycodes/yanga-services/shared-lib/utils"
+)
+
+// DisputeRating godoc
+// @Summary Dispute a rating
+// @Description Drivers can contest a rating a rider gave them, once and within 30 days. An admin reviews it and can exclude the rating from their averages.
+// @Tags ratings
+// @Accept json
+// @Produce json
+// @Param id path string true "Rating ID"
+// @Param request body domain.CreateRatingDisputeRequest true "Why the rating is unfair"
+// @Success 201 {object} domain.RatingDisputeResponse
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 403 {object} domain.ErrorResponse
+// @Failure 409 {object} domain.ErrorResponse
+// @Failure 422 {object} domain.ErrorResponse
+// @Router /ratings/{id}/dispute [post]
+// @Security BearerAuth
+func (h *RatingHandler) DisputeRating(w http.ResponseWriter, r *http.Request) {
+	ratingID, err := utils.ParseUUID(mux.Vars(r)["id"])
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid rating ID")
+		return
+	}
+
+	var req domain.CreateRatingDisputeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	userIDStr := r.Context().Value("user_id").(string)
+	userID, err := utils.ParseUUID(userIDStr)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	dispute, err := h.ratingService.DisputeRating(r.Context(), userID, ratingID, &req)
+	if err != nil {
+		handleRatingError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusCreated, "Dispute submitted", dispute)
+}
+
+// GetMyDisputes godoc
+// @Summary Get the current driver's rating disputes
+// @Tags ratings
+// @Produce json
+// @Success 200 {array} domain.RatingDisputeResponse
+// @Router /ratings/disputes/my [get]
+// @Security BearerAuth
+func (h *RatingHandler) GetMyDisputes(w http.ResponseWriter, r *http.Request) {
+	userIDStr := r.Context().Value("user_id").(string)
+	userID, err := utils.ParseUUID(userIDStr)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	disputes, err := h.ratingService.GetMyDisputes(r.Context(), userID)
+	if err != nil {
+		handleRatingError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Disputes retrieved successfully", disputes)
+}
+
+// ListDisputes godoc
+// @Summary List rating disputes (admin only)
+// @Tags ratings
+// @Produce json
+// @Param status query string false "pending, accepted or rejected"
+// @Param limit query int false "Limit" default(50)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {array} domain.RatingDisputeResponse
+// @Router /ratings/disputes [get]
+// @Security BearerAuth
+func (h *RatingHandler) ListDisputes(w http.ResponseWriter, r *http.Request) {
+	disputes, err := h.ratingService.ListDisputes(r.Context(), r.URL.Query().Get("status"), queryInt(r, "limit", 50), queryInt(r, "offset", 0))
+	if err != nil {
+		handleRatingError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Disputes retrieved successfully", disputes)
+}
+
+// ResolveDispute godoc
+// @Summary Accept or reject a rating dispute (admin only)
+// @Description Accepting a dispute excludes the rating from the driver's averages.
+// @Tags ratings
+// @Accept json
+// @Produce json
+// @Param id path string true "Dispute ID"
+// @Param request body domain.ResolveRatingDisputeRequest true "Decision"
+// @Success 200 {object} domain.RatingDisputeResponse
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 409 {object} domain.ErrorResponse
+// @Router /ratings/disputes/{id} [put]
+// @Security BearerAuth
+func (h *RatingHandler) ResolveDispute(w http.ResponseWriter, r *http.Request) {
+	disputeID, err := utils.ParseUUID(mux.Vars(r)["id"])
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid dispute ID")
+		return
+	}
+
+	var req domain.ResolveRatingDisputeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	adminIDStr := r.Context().Value("user_id").(string)
+	adminID, err := utils.ParseUUID(adminIDStr)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	dispute, err := h.ratingService.ResolveDispute(r.Context(), adminID, disputeID, &req)
+	if err != nil {
+		handleRatingError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Dispute resolved", dispute)
+}
+
+// ListFlaggedRatings godoc
+// @Summary List ratings with flagged feedback (admin only)
+// @Tags ratings
+// @Produce json
+// @Param limit query int false "Limit" default(50)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {array} domain.RatingResponse
+// @Router /ratings/flagged [get]
+// @Security BearerAuth
+func (h *RatingHandler) ListFlaggedRatings(w http.ResponseWriter, r *http.Request) {
+	ratings, err := h.ratingService.ListFlaggedRatings(r.Context(), queryInt(r, "limit", 50), queryInt(r, "offset", 0))
+	if err != nil {
+		handleRatingError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Flagged ratings retrieved successfully", ratings)
+}
+
+// ModerateRating godoc
+// @Summary Exclude, restore or clear the flag on a rating (admin only)
+// @Description Excluded ratings no longer count towards any average. Clearing the flag shows the feedback again.
+// @Tags ratings
+// @Accept json
+// @Produce json
+// @Param id path string true "Rating ID"
+// @Param request body domain.ModerateRatingRequest true "Moderation action"
+// @Success 200 {object} domain.RatingResponse
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 409 {object} domain.ErrorResponse
+// @Router /ratings/{id}/moderation [put]
+// @Security BearerAuth
+func (h *RatingHandler) ModerateRating(w http.ResponseWriter, r *http.Request) {
+	ratingID, err := utils.ParseUUID(mux.Vars(r)["id"])
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid rating ID")
+		return
+	}
+
+	var req domain.ModerateRatingRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	adminIDStr := r.Context().Value("user_id").(string)
+	adminID, err := utils.ParseUUID(adminIDStr)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	rating, err := h.ratingService.ModerateRating(r.Context(), adminID, ratingID, &req)
+	if err != nil {
+		handleRatingError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Rating updated", rating)
+}
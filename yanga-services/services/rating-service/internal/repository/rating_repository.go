@@ -2,45 +2,120 @@ package repository
 
 import (
 	"context"
+	"errors"
 
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/namycodes/yanga-services/services/rating-service/internal/db"
 )
 
 type RatingRepository struct {
+	pool    *pgxpool.Pool
 	queries *db.Queries
 }
 
-func NewRatingRepository(queries *db.Queries) *RatingRepository {
+func NewRatingRepository(pool *pgxpool.Pool, queries *db.Queries) *RatingRepository {
 	return &RatingRepository{
+		pool:    pool,
 		queries: queries,
 	}
 }
 
-func (r *RatingRepository) CreateRating(ctx context.Context, params db.CreateRatingParams) (db.Rating, error) {
-	return r.queries.CreateRating(ctx, params)
+// IsUniqueViolation reports whether err is a Postgres unique constraint
+// violation, such as rating the same trip twice.
+func IsUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		// 23505 unique_violation
+		return pgErr.Code == "23505"
+	}
+	return false
+}
+
+// WithTx runs fn inside a database transaction, committing only if fn
+// returns nil.
+func (r *RatingRepository) WithTx(ctx context.Context, fn func(q *db.Queries) error) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := fn(r.queries.WithTx(tx)); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *RatingRepository) GetTripParticipants(ctx context.Context, tripID pgtype.UUID) (db.GetTripParticipantsRow, error) {
+	return r.queries.GetTripParticipants(ctx, tripID)
 }
 
-func (r *RatingRepository) GetRatingByID(ctx context.Context, id pgtype.UUID) (db.Rating, error) {
-	return r.queries.GetRatingByID(ctx, id)
+func (r *RatingRepository) GetRating(ctx context.Context, id pgtype.UUID) (db.Rating, error) {
+	return r.queries.GetRating(ctx, id)
 }
 
-func (r *RatingRepository) GetTripRating(ctx context.Context, tripID pgtype.UUID) (db.Rating, error) {
-	return r.queries.GetTripRating(ctx, tripID)
+func (r *RatingRepository) GetTripRatings(ctx context.Context, tripID pgtype.UUID) ([]db.Rating, error) {
+	return r.queries.GetTripRatings(ctx, tripID)
 }
 
-func (r *RatingRepository) GetUserRatings(ctx context.Context, params db.GetUserRatingsParams) ([]db.Rating, error) {
+func (r *RatingRepository) GetUserRatings(ctx context.Context, params db.GetUserRatingsParams) ([]db.GetUserRatingsRow, error) {
 	return r.queries.GetUserRatings(ctx, params)
 }
 
-func (r *RatingRepository) GetDriverRatings(ctx context.Context, params db.GetDriverRatingsParams) ([]db.Rating, error) {
-	return r.queries.GetDriverRatings(ctx, params)
+func (r *RatingRepository) GetRatingTags(ctx context.Context, ratingIDs []pgtype.UUID) ([]db.RatingTag, error) {
+	return r.queries.GetRatingTags(ctx, ratingIDs)
+}
+
+func (r *RatingRepository) GetAverageRating(ctx context.Context, params db.GetAverageRatingParams) (db.GetAverageRatingRow, error) {
+	return r.queries.GetAverageRating(ctx, params)
+}
+
+func (r *RatingRepository) GetRatingTagCounts(ctx context.Context, params db.GetRatingTagCountsParams) ([]db.GetRatingTagCountsRow, error) {
+	return r.queries.GetRatingTagCounts(ctx, params)
+}
+
+func (r *RatingRepository) ListFlaggedRatings(ctx context.Context, params db.ListFlaggedRatingsParams) ([]db.Rating, error) {
+	return r.queries.ListFlaggedRatings(ctx, params)
+}
+
+func (r *RatingRepository) ExcludeRating(ctx context.Context, params db.ExcludeRatingParams) (db.Rating, error) {
+	return r.queries.ExcludeRating(ctx, params)
+}
+
+func (r *RatingRepository) RestoreRating(ctx context.Context, id pgtype.UUID) (db.Rating, error) {
+	return r.queries.RestoreRating(ctx, id)
+}
+
+func (r *RatingRepository) ClearRatingFlag(ctx context.Context, id pgtype.UUID) (db.Rating, error) {
+	return r.queries.ClearRatingFlag(ctx, id)
+}
+
+func (r *RatingRepository) CreateRatingDispute(ctx context.Context, params db.CreateRatingDisputeParams) (db.RatingDispute, error) {
+	return r.queries.CreateRatingDispute(ctx, params)
+}
+
+func (r *RatingRepository) GetRatingDispute(ctx context.Context, id pgtype.UUID) (db.RatingDispute, error) {
+	return r.queries.GetRatingDispute(ctx, id)
+}
+
+func (r *RatingRepository) ListRatingDisputes(ctx context.Context, params db.ListRatingDisputesParams) ([]db.ListRatingDisputesRow, error) {
+	return r.queries.ListRatingDisputes(ctx, params)
+}
+
+func (r *RatingRepository) ListUserRatingDisputes(ctx context.Context, disputedBy pgtype.UUID) ([]db.ListUserRatingDisputesRow, error) {
+	return r.queries.ListUserRatingDisputes(ctx, disputedBy)
+}
+
+func (r *RatingRepository) GetRaterGivenStats(ctx context.Context, params db.GetRaterGivenStatsParams) (db.GetRaterGivenStatsRow, error) {
+	return r.queries.GetRaterGivenStats(ctx, params)
 }
 
-func (r *RatingRepository) GetAverageDriverRating(ctx context.Context, driverID pgtype.UUID) (string, error) {
-	return r.queries.GetAverageDriverRating(ctx, driverID)
+func (r *RatingRepository) GetRaterTypeAverage(ctx context.Context, raterType string) (float64, error) {
+	return r.queries.GetRaterTypeAverage(ctx, raterType)
 }
 
-func (r *RatingRepository) GetAverageUserRating(ctx context.Context, userID pgtype.UUID) (string, error) {
-	return r.queries.GetAverageUserRating(ctx, userID)
+func (r *RatingRepository) UpsertRaterWeight(ctx context.Context, params db.UpsertRaterWeightParams) error {
+	return r.queries.UpsertRaterWeight(ctx, params)
 }
@@ -12,10 +12,31 @@ func SetupRatingRoutes(router *mux.Router, ratingHandler *handler.RatingHandler,
 	api := router.PathPrefix("/api/v1").Subrouter()
 
 	ratings := api.PathPrefix("/ratings").Subrouter()
-	ratings.Use(middleware.AuthMiddleware(jwtCfg.Secret))
+	ratings.Use(middleware.AuthMiddleware(jwtCfg.Secret, jwtCfg.IdentitySecret))
 
 	ratings.HandleFunc("", ratingHandler.CreateRating).Methods("POST")
-	ratings.HandleFunc("/trip/{trip_id}", ratingHandler.GetTripRating).Methods("GET")
+	ratings.HandleFunc("/my", ratingHandler.GetMyRatings).Methods("GET")
+	ratings.HandleFunc("/my/summary", ratingHandler.GetMyRatingSummary).Methods("GET")
+	ratings.HandleFunc("/trip/{trip_id}", ratingHandler.GetTripRatings).Methods("GET")
+
+	// Disputes - drivers contest ratings riders gave them
+	drivers := ratings.NewRoute().Subrouter()
+	drivers.Use(middleware.RequireRole("driver", "admin"))
+	drivers.HandleFunc("/{id}/dispute", ratingHandler.DisputeRating).Methods("POST")
+	drivers.HandleFunc("/disputes/my", ratingHandler.GetMyDisputes).Methods("GET")
+
+	// Moderation - admin only
+	admin := ratings.NewRoute().Subrouter()
+	admin.Use(middleware.RequireRole("admin"))
+	admin.HandleFunc("/disputes", ratingHandler.ListDisputes).Methods("GET")
+	admin.HandleFunc("/disputes/{id}", ratingHandler.ResolveDispute).Methods("PUT")
+	admin.HandleFunc("/flagged", ratingHandler.ListFlaggedRatings).Methods("GET")
+	admin.HandleFunc("/{id}/moderation", ratingHandler.ModerateRating).Methods("PUT")
+
+	// Riders' ratings - drivers and admins only
+	riders := ratings.NewRoute().Subrouter()
+	riders.Use(middleware.RequireRole("driver", "admin"))
+	riders.HandleFunc("/rider/{rider_id}/average", ratingHandler.GetRiderRatingSummary).Methods("GET")
 
 	// Public endpoints
 	public := api.PathPrefix("/ratings").Subrouter()
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/namycodes/yanga-services/services/rating-service/internal/db"
+	"github.com/namycodes/yanga-services/shared-lib/utils"
+)
+
+const (
+	// minRatingsForBias is how many ratings a rater must have given before
+	// their ratings are down-weighted.
+	minRatingsForBias = 5
+	// biasTolerance is how far, in stars, a rater's average may sit below
+	// everyone else's before their ratings are down-weighted.
+	biasTolerance = 1.0
+	// minRaterWeight keeps the harshest raters' ratings counting for
+	// something.
+	minRaterWeight = 0.1
+)
+
+// raterWeight returns how much a rater's ratings count towards averages.
+// Raters whose average sits more than biasTolerance stars below the
+// population's, such as someone who gives every trip one star, count for
+// less the further below they are.
+func raterWeight(ratingsGiven int64, averageGiven, populationAverage float64) float64 {
+	harshness := populationAverage - averageGiven
+	if ratingsGiven < minRatingsForBias || harshness <= biasTolerance {
+		return 1
+	}
+	return math.Max(minRaterWeight, 1/harshness)
+}
+
+// refreshRaterWeight recomputes a rater's weight from the ratings they've
+// given that still count.
+func (s *RatingService) refreshRaterWeight(ctx context.Context, raterID pgtype.UUID, raterType string) error {
+	given, err := s.repo.GetRaterGivenStats(ctx, db.GetRaterGivenStatsParams{
+		RaterID:   raterID,
+		RaterType: raterType,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get rater stats: %w", err)
+	}
+
+	population, err := s.repo.GetRaterTypeAverage(ctx, raterType)
+	if err != nil {
+		return fmt.Errorf("failed to get average rating: %w", err)
+	}
+
+	weight := raterWeight(given.RatingsGiven, given.AverageGiven, population)
+	if err := s.repo.UpsertRaterWeight(ctx, db.UpsertRaterWeightParams{
+		RaterID:      raterID,
+		RaterType:    raterType,
+		RatingsGiven: int32(given.RatingsGiven),
+		AverageGiven: utils.Float64ToNumeric(roundRating(given.AverageGiven)),
+		Weight:       utils.Float64ToNumeric(math.Round(weight*1000) / 1000),
+	}); err != nil {
+		return fmt.Errorf("failed to update rater weight: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,337 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/namycodes/yanga-services/services/rating-service/internal/db"
+	"github.com/namycodes/yanga-services/services/rating-service/internal/repository"
+	"github.com/namycodes/yanga-services/shared-lib/domain"
+	"github.com/namycodes/yanga-services/shared-lib/events"
+	"github.com/namycodes/yanga-services/shared-lib/utils"
+)
+
+// disputeWindow is how long after being rated a driver may dispute it.
+const disputeWindow = 30 * 24 * time.Hour
+
+var (
+	ErrRatingNotFound      = errors.New("rating not found")
+	ErrDisputeNotAllowed   = errors.New("only the rated driver can dispute a rating")
+	ErrDisputeWindowClosed = errors.New("the time to dispute this rating has passed")
+	ErrAlreadyDisputed     = errors.New("this rating has already been disputed")
+	ErrDisputeNotFound     = errors.New("dispute not found")
+	ErrDisputeResolved     = errors.New("dispute has already been resolved")
+	ErrInvalidModeration   = errors.New("invalid moderation request")
+	ErrModerationConflict  = errors.New("rating is not in a state for that action")
+)
+
+// DisputeRating lets a driver contest a rating a rider gave them. Each
+// rating can be disputed once, within disputeWindow.
+func (s *RatingService) DisputeRating(ctx context.Context, userID, ratingID uuid.UUID, req *domain.CreateRatingDisputeRequest) (*domain.RatingDisputeResponse, error) {
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		return nil, fmt.Errorf("%w: a reason is required", ErrInvalidModeration)
+	}
+	if len(reason) > maxFeedbackLength {
+		return nil, fmt.Errorf("%w: reason is limited to %d characters", ErrInvalidModeration, maxFeedbackLength)
+	}
+
+	rating, err := s.getRating(ctx, ratingID)
+	if err != nil {
+		return nil, err
+	}
+	if rating.RaterType != domain.RaterTypeRider || rating.RatedID != utils.ToPgUUID(userID) {
+		return nil, ErrDisputeNotAllowed
+	}
+	if rating.ExcludedAt.Valid {
+		return nil, fmt.Errorf("%w: rating is already excluded", ErrModerationConflict)
+	}
+	if time.Since(rating.CreatedAt.Time) > disputeWindow {
+		return nil, ErrDisputeWindowClosed
+	}
+
+	dispute, err := s.repo.CreateRatingDispute(ctx, db.CreateRatingDisputeParams{
+		RatingID:   rating.ID,
+		DisputedBy: utils.ToPgUUID(userID),
+		Reason:     reason,
+	})
+	if err != nil {
+		if repository.IsUniqueViolation(err) {
+			return nil, ErrAlreadyDisputed
+		}
+		return nil, fmt.Errorf("failed to create dispute: %w", err)
+	}
+
+	response := toDisputeResponse(dispute)
+	response.TripID = utils.FromPgUUID(rating.TripID).String()
+	response.Rating = rating.Rating
+	return response, nil
+}
+
+// GetMyDisputes lists the disputes a driver has raised, newest first.
+func (s *RatingService) GetMyDisputes(ctx context.Context, userID uuid.UUID) ([]domain.RatingDisputeResponse, error) {
+	rows, err := s.repo.ListUserRatingDisputes(ctx, utils.ToPgUUID(userID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get disputes: %w", err)
+	}
+
+	disputes := make([]domain.RatingDisputeResponse, 0, len(rows))
+	for _, row := range rows {
+		// Drivers see the score they were given, not who gave it or what
+		// they wrote.
+		dispute := toDisputeResponse(db.RatingDispute{
+			ID:             row.ID,
+			RatingID:       row.RatingID,
+			DisputedBy:     row.DisputedBy,
+			Reason:         row.Reason,
+			Status:         row.Status,
+			ResolutionNote: row.ResolutionNote,
+			ReviewedAt:     row.ReviewedAt,
+			CreatedAt:      row.CreatedAt,
+		})
+		dispute.TripID = utils.FromPgUUID(row.TripID).String()
+		dispute.Rating = row.Rating
+		disputes = append(disputes, *dispute)
+	}
+	return disputes, nil
+}
+
+// ListDisputes lists disputes for admins, oldest first, optionally only
+// those with a given status.
+func (s *RatingService) ListDisputes(ctx context.Context, status string, limit, offset int32) ([]domain.RatingDisputeResponse, error) {
+	rows, err := s.repo.ListRatingDisputes(ctx, db.ListRatingDisputesParams{
+		Status: pgtype.Text{String: status, Valid: status != ""},
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list disputes: %w", err)
+	}
+
+	disputes := make([]domain.RatingDisputeResponse, 0, len(rows))
+	for _, row := range rows {
+		dispute := toDisputeResponse(db.RatingDispute{
+			ID:             row.ID,
+			RatingID:       row.RatingID,
+			DisputedBy:     row.DisputedBy,
+			Reason:         row.Reason,
+			Status:         row.Status,
+			ResolutionNote: row.ResolutionNote,
+			ReviewedBy:     row.ReviewedBy,
+			ReviewedAt:     row.ReviewedAt,
+			CreatedAt:      row.CreatedAt,
+		})
+		dispute.TripID = utils.FromPgUUID(row.TripID).String()
+		dispute.RaterID = utils.FromPgUUID(row.RaterID).String()
+		dispute.Rating = row.Rating
+		dispute.Feedback = row.Feedback.String
+		disputes = append(disputes, *dispute)
+	}
+	return disputes, nil
+}
+
+// ResolveDispute records an admin's decision on a pending dispute.
+// Accepting it excludes the rating from averages.
+func (s *RatingService) ResolveDispute(ctx context.Context, adminID, disputeID uuid.UUID, req *domain.ResolveRatingDisputeRequest) (*domain.RatingDisputeResponse, error) {
+	if req.Decision != domain.DisputeStatusAccepted && req.Decision != domain.DisputeStatusRejected {
+		return nil, fmt.Errorf("%w: decision must be accepted or rejected", ErrInvalidModeration)
+	}
+	note := strings.TrimSpace(req.Note)
+
+	current, err := s.repo.GetRatingDispute(ctx, utils.ToPgUUID(disputeID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrDisputeNotFound
+		}
+		return nil, fmt.Errorf("failed to get dispute: %w", err)
+	}
+	if current.Status != domain.DisputeStatusPending {
+		return nil, ErrDisputeResolved
+	}
+
+	pgAdminID := utils.ToPgUUID(adminID)
+	var dispute db.RatingDispute
+	var excluded *db.Rating
+	err = s.repo.WithTx(ctx, func(q *db.Queries) error {
+		var err error
+		dispute, err = q.ResolveRatingDispute(ctx, db.ResolveRatingDisputeParams{
+			Status:         req.Decision,
+			ResolutionNote: pgtype.Text{String: note, Valid: note != ""},
+			ReviewedBy:     pgAdminID,
+			ID:             current.ID,
+		})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrDisputeResolved
+			}
+			return err
+		}
+		if dispute.Status != domain.DisputeStatusAccepted {
+			return nil
+		}
+
+		rating, err := q.ExcludeRating(ctx, db.ExcludeRatingParams{
+			ExcludedBy:      pgAdminID,
+			ExclusionReason: pgtype.Text{String: "dispute accepted", Valid: true},
+			ID:              dispute.RatingID,
+		})
+		if err != nil {
+			// Already excluded by an admin before the dispute was decided.
+			if errors.Is(err, pgx.ErrNoRows) {
+				return nil
+			}
+			return err
+		}
+		excluded = &rating
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, ErrDisputeResolved) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to resolve dispute: %w", err)
+	}
+
+	if excluded != nil {
+		s.ratingModerated(ctx, events.SubjectRatingExcluded, *excluded, "dispute accepted")
+	}
+
+	response := toDisputeResponse(dispute)
+	s.eventBus.Publish(ctx, events.SubjectDisputeResolved, events.RatingDisputeResolvedEvent{
+		DisputeID:  response.ID,
+		RatingID:   response.RatingID,
+		DisputedBy: response.DisputedBy,
+		Status:     response.Status,
+		Note:       response.ResolutionNote,
+		Timestamp:  time.Now(),
+	})
+	return response, nil
+}
+
+// ListFlaggedRatings lists ratings whose feedback the content filter
+// flagged and that haven't been excluded, oldest first.
+func (s *RatingService) ListFlaggedRatings(ctx context.Context, limit, offset int32) ([]domain.RatingResponse, error) {
+	ratings, err := s.repo.ListFlaggedRatings(ctx, db.ListFlaggedRatingsParams{
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list flagged ratings: %w", err)
+	}
+
+	ids := make([]pgtype.UUID, len(ratings))
+	for i, rating := range ratings {
+		ids[i] = rating.ID
+	}
+	tags, err := s.tagsByRating(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]domain.RatingResponse, 0, len(ratings))
+	for _, rating := range ratings {
+		response = append(response, *toRatingResponse(rating, tags[rating.ID]))
+	}
+	return response, nil
+}
+
+// ModerateRating lets an admin exclude a rating from averages, restore an
+// excluded rating, or clear the content filter's flag on its feedback.
+func (s *RatingService) ModerateRating(ctx context.Context, adminID, ratingID uuid.UUID, req *domain.ModerateRatingRequest) (*domain.RatingResponse, error) {
+	current, err := s.getRating(ctx, ratingID)
+	if err != nil {
+		return nil, err
+	}
+	reason := strings.TrimSpace(req.Reason)
+
+	var rating db.Rating
+	var subject string
+	switch req.Action {
+	case domain.ModerationActionExclude:
+		if reason == "" {
+			return nil, fmt.Errorf("%w: a reason is required to exclude a rating", ErrInvalidModeration)
+		}
+		rating, err = s.repo.ExcludeRating(ctx, db.ExcludeRatingParams{
+			ExcludedBy:      utils.ToPgUUID(adminID),
+			ExclusionReason: pgtype.Text{String: reason, Valid: true},
+			ID:              current.ID,
+		})
+		subject = events.SubjectRatingExcluded
+	case domain.ModerationActionRestore:
+		rating, err = s.repo.RestoreRating(ctx, current.ID)
+		subject = events.SubjectRatingRestored
+	case domain.ModerationActionClearFlag:
+		rating, err = s.repo.ClearRatingFlag(ctx, current.ID)
+	default:
+		return nil, fmt.Errorf("%w: action must be exclude, restore or clear_flag", ErrInvalidModeration)
+	}
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrModerationConflict
+		}
+		return nil, fmt.Errorf("failed to moderate rating: %w", err)
+	}
+
+	if subject != "" {
+		s.ratingModerated(ctx, subject, rating, reason)
+	}
+
+	tags, err := s.tagsByRating(ctx, []pgtype.UUID{rating.ID})
+	if err != nil {
+		return nil, err
+	}
+	return toRatingResponse(rating, tags[rating.ID]), nil
+}
+
+// ratingModerated refreshes the rater's weight now that one of their
+// ratings counts or no longer counts, then tells other services.
+func (s *RatingService) ratingModerated(ctx context.Context, subject string, rating db.Rating, reason string) {
+	if err := s.refreshRaterWeight(ctx, rating.RaterID, rating.RaterType); err != nil {
+		log.Printf("Failed to refresh weight of rater %s: %v", utils.FromPgUUID(rating.RaterID), err)
+	}
+
+	s.eventBus.Publish(ctx, subject, events.RatingModeratedEvent{
+		RatingID:  utils.FromPgUUID(rating.ID).String(),
+		RatedID:   utils.FromPgUUID(rating.RatedID).String(),
+		RaterType: rating.RaterType,
+		Reason:    reason,
+		Timestamp: time.Now(),
+	})
+}
+
+func (s *RatingService) getRating(ctx context.Context, ratingID uuid.UUID) (db.Rating, error) {
+	rating, err := s.repo.GetRating(ctx, utils.ToPgUUID(ratingID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.Rating{}, ErrRatingNotFound
+		}
+		return db.Rating{}, fmt.Errorf("failed to get rating: %w", err)
+	}
+	return rating, nil
+}
+
+func toDisputeResponse(dispute db.RatingDispute) *domain.RatingDisputeResponse {
+	response := &domain.RatingDisputeResponse{
+		ID:             utils.FromPgUUID(dispute.ID).String(),
+		RatingID:       utils.FromPgUUID(dispute.RatingID).String(),
+		DisputedBy:     utils.FromPgUUID(dispute.DisputedBy).String(),
+		Reason:         dispute.Reason,
+		Status:         dispute.Status,
+		ResolutionNote: dispute.ResolutionNote.String,
+		CreatedAt:      dispute.CreatedAt.Time,
+	}
+	if dispute.ReviewedBy.Valid {
+		response.ReviewedBy = utils.FromPgUUID(dispute.ReviewedBy).String()
+	}
+	if dispute.ReviewedAt.Valid {
+		response.ReviewedAt = &dispute.ReviewedAt.Time
+	}
+	return response
+}
@@ -4,178 +4,371 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log"
+	"math"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/namycodes/yanga-services/services/rating-service/internal/db"
 	"github.com/namycodes/yanga-services/services/rating-service/internal/repository"
+	"github.com/namycodes/yanga-services/shared-lib/config"
 	"github.com/namycodes/yanga-services/shared-lib/domain"
 	"github.com/namycodes/yanga-services/shared-lib/events"
+	"github.com/namycodes/yanga-services/shared-lib/moderation"
+	"github.com/namycodes/yanga-services/shared-lib/utils"
+)
+
+const (
+	maxFeedbackLength = 1000
+	maxRatingTags     = 5
+)
+
+var (
+	ErrInvalidRating      = errors.New("invalid rating")
+	ErrTripNotFound       = errors.New("trip not found")
+	ErrNotTripParticipant = errors.New("only the rider and driver of a trip can rate it")
+	ErrTripNotCompleted   = errors.New("only completed trips can be rated")
+	ErrRatingWindowClosed = errors.New("the time to rate this trip has passed")
+	ErrAlreadyRated       = errors.New("you have already rated this trip")
 )
 
 type RatingService struct {
 	repo     *repository.RatingRepository
 	eventBus events.EventBus
+	filter   moderation.Filter
+	window   time.Duration
 }
 
-func NewRatingService(repo *repository.RatingRepository, eventBus events.EventBus) *RatingService {
+func NewRatingService(repo *repository.RatingRepository, eventBus events.EventBus, filter moderation.Filter, cfg *config.Config) *RatingService {
 	return &RatingService{
 		repo:     repo,
 		eventBus: eventBus,
+		filter:   filter,
+		window:   time.Duration(cfg.RatingWindowHours) * time.Hour,
 	}
 }
 
-func (s *RatingService) CreateRating(ctx context.Context, req *domain.CreateRatingRequest) (*domain.RatingResponse, error) {
-	tripUUID, err := uuid.Parse(req.TripID)
-	if err != nil {
-		return nil, errors.New("invalid trip ID")
+// CreateRating records a rating of the other party of a completed trip.
+// The rider rates the driver and the driver rates the rider, each once, and
+// only until the rating window after completion has passed. Feedback the
+// content filter flags is kept but hidden until an admin reviews it.
+func (s *RatingService) CreateRating(ctx context.Context, raterID uuid.UUID, req *domain.CreateRatingRequest) (*domain.RatingResponse, error) {
+	if req.Rating < 1 || req.Rating > 5 {
+		return nil, fmt.Errorf("%w: rating must be between 1 and 5", ErrInvalidRating)
 	}
-
-	raterUUID, err := uuid.Parse(req.RaterID)
-	if err != nil {
-		return nil, errors.New("invalid rater ID")
+	feedback := strings.TrimSpace(req.Feedback)
+	if len(feedback) > maxFeedbackLength {
+		return nil, fmt.Errorf("%w: feedback is limited to %d characters", ErrInvalidRating, maxFeedbackLength)
 	}
 
-	ratedUUID, err := uuid.Parse(req.RatedID)
+	trip, err := s.repo.GetTripParticipants(ctx, utils.ToPgUUID(req.TripID))
 	if err != nil {
-		return nil, errors.New("invalid rated ID")
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrTripNotFound
+		}
+		return nil, fmt.Errorf("failed to get trip: %w", err)
 	}
 
-	tripID := pgtype.UUID{}
-	copy(tripID.Bytes[:], tripUUID[:])
-	tripID.Valid = true
+	pgRaterID := utils.ToPgUUID(raterID)
+	var raterType string
+	var ratedID pgtype.UUID
+	switch {
+	case trip.UserID == pgRaterID:
+		raterType, ratedID = domain.RaterTypeRider, trip.DriverID
+	case trip.DriverID.Valid && trip.DriverID == pgRaterID:
+		raterType, ratedID = domain.RaterTypeDriver, trip.UserID
+	default:
+		return nil, ErrNotTripParticipant
+	}
+	if trip.Status != "completed" || !ratedID.Valid {
+		return nil, ErrTripNotCompleted
+	}
+	if trip.CompletedAt.Valid && time.Since(trip.CompletedAt.Time) > s.window {
+		return nil, ErrRatingWindowClosed
+	}
 
-	raterID := pgtype.UUID{}
-	copy(raterID.Bytes[:], raterUUID[:])
-	raterID.Valid = true
+	tags, err := normalizeTags(raterType, req.Tags)
+	if err != nil {
+		return nil, err
+	}
 
-	ratedID := pgtype.UUID{}
-	copy(ratedID.Bytes[:], ratedUUID[:])
-	ratedID.Valid = true
+	// A filter outage shouldn't stop riders rating, so feedback is let
+	// through unflagged if it can't be checked.
+	var verdict moderation.Verdict
+	if feedback != "" {
+		verdict, err = s.filter.Check(ctx, feedback)
+		if err != nil {
+			log.Printf("Failed to check feedback on trip %s: %v", req.TripID, err)
+		}
+	}
 
-	rating, err := s.repo.CreateRating(ctx, db.CreateRatingParams{
-		TripID:    tripID,
-		RaterID:   raterID,
-		RatedID:   ratedID,
-		RaterType: req.RaterType,
-		Rating:    req.Rating,
-		Comment:   pgtype.Text{String: req.Comment, Valid: req.Comment != ""},
+	var rating db.Rating
+	err = s.repo.WithTx(ctx, func(q *db.Queries) error {
+		var err error
+		rating, err = q.CreateRating(ctx, db.CreateRatingParams{
+			TripID:          trip.ID,
+			RaterID:         pgRaterID,
+			RatedID:         ratedID,
+			RaterType:       raterType,
+			Rating:          req.Rating,
+			Feedback:        pgtype.Text{String: feedback, Valid: feedback != ""},
+			FeedbackFlagged: verdict.Flagged,
+			FlagReasons:     pgtype.Text{String: strings.Join(verdict.Reasons, ","), Valid: verdict.Flagged},
+		})
+		if err != nil {
+			return err
+		}
+		for _, tag := range tags {
+			if err := q.AddRatingTag(ctx, db.AddRatingTagParams{RatingID: rating.ID, Tag: tag}); err != nil {
+				return err
+			}
+		}
+		return nil
 	})
 	if err != nil {
+		if repository.IsUniqueViolation(err) {
+			return nil, ErrAlreadyRated
+		}
 		return nil, fmt.Errorf("failed to create rating: %w", err)
 	}
 
-	// Publish rating created event
-	ratingID, _ := uuid.FromBytes(rating.ID.Bytes[:])
-	s.eventBus.Publish(events.SubjectRatingCreated, events.RatingCreatedPayload{
-		RatingID:  ratingID.String(),
-		TripID:    req.TripID,
-		RatedID:   req.RatedID,
-		RaterType: req.RaterType,
-		Rating:    req.Rating,
-	})
+	// The weight is refreshed before the event goes out so the rated user's
+	// aggregates are recomputed with it.
+	if err := s.refreshRaterWeight(ctx, pgRaterID, raterType); err != nil {
+		log.Printf("Failed to refresh weight of rater %s: %v", raterID, err)
+	}
 
-	return &domain.RatingResponse{
-		ID:        ratingID.String(),
-		TripID:    req.TripID,
-		RaterID:   req.RaterID,
-		RatedID:   req.RatedID,
-		RaterType: rating.RaterType,
+	response := toRatingResponse(rating, tags)
+	s.eventBus.Publish(ctx, events.SubjectRatingCreated, events.RatingCreatedPayload{
+		RatingID:  response.ID,
+		TripID:    response.TripID,
+		RaterID:   response.RaterID,
+		RatedID:   response.RatedID,
+		RaterType: raterType,
 		Rating:    rating.Rating,
-		Comment:   rating.Comment.String,
-		CreatedAt: rating.CreatedAt.Time,
-	}, nil
+		Tags:      tags,
+	})
+
+	return response, nil
 }
 
-func (s *RatingService) GetTripRating(ctx context.Context, tripID string) (*domain.RatingResponse, error) {
-	tripUUID, err := uuid.Parse(tripID)
+// GetTripRatings returns the ratings given on a trip. Only the trip's rider
+// and driver, and admins, may see them, and only admins see flagged
+// feedback.
+func (s *RatingService) GetTripRatings(ctx context.Context, userID uuid.UUID, role string, tripID uuid.UUID) ([]domain.RatingResponse, error) {
+	trip, err := s.repo.GetTripParticipants(ctx, utils.ToPgUUID(tripID))
 	if err != nil {
-		return nil, errors.New("invalid trip ID")
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrTripNotFound
+		}
+		return nil, fmt.Errorf("failed to get trip: %w", err)
 	}
 
-	tripPgUUID := pgtype.UUID{}
-	copy(tripPgUUID.Bytes[:], tripUUID[:])
-	tripPgUUID.Valid = true
+	pgUserID := utils.ToPgUUID(userID)
+	if role != "admin" && trip.UserID != pgUserID && trip.DriverID != pgUserID {
+		return nil, ErrTripNotFound
+	}
 
-	rating, err := s.repo.GetTripRating(ctx, tripPgUUID)
+	ratings, err := s.repo.GetTripRatings(ctx, trip.ID)
 	if err != nil {
-		return nil, errors.New("rating not found")
+		return nil, fmt.Errorf("failed to get trip ratings: %w", err)
 	}
 
-	ratingID, _ := uuid.FromBytes(rating.ID.Bytes[:])
-	raterID, _ := uuid.FromBytes(rating.RaterID.Bytes[:])
-	ratedID, _ := uuid.FromBytes(rating.RatedID.Bytes[:])
+	ids := make([]pgtype.UUID, len(ratings))
+	for i, rating := range ratings {
+		ids[i] = rating.ID
+	}
+	tags, err := s.tagsByRating(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
 
-	return &domain.RatingResponse{
-		ID:        ratingID.String(),
-		TripID:    tripID,
-		RaterID:   raterID.String(),
-		RatedID:   ratedID.String(),
-		RaterType: rating.RaterType,
-		Rating:    rating.Rating,
-		Comment:   rating.Comment.String,
-		CreatedAt: rating.CreatedAt.Time,
-	}, nil
+	response := make([]domain.RatingResponse, 0, len(ratings))
+	for _, rating := range ratings {
+		r := toRatingResponse(rating, tags[rating.ID])
+		if role != "admin" {
+			hideFlaggedFeedback(r)
+		}
+		response = append(response, *r)
+	}
+	return response, nil
 }
 
-func (s *RatingService) GetDriverRatings(ctx context.Context, driverID string, limit, offset int32) ([]domain.RatingResponse, error) {
-	driverUUID, err := uuid.Parse(driverID)
+// GetUserRatings lists the ratings a user received as a driver or as a
+// rider, newest first. Excluded ratings are left out and flagged feedback
+// is hidden.
+func (s *RatingService) GetUserRatings(ctx context.Context, userID uuid.UUID, ratedAs string, limit, offset int32) ([]domain.RatingResponse, error) {
+	raterType, err := raterTypeFor(ratedAs)
 	if err != nil {
-		return nil, errors.New("invalid driver ID")
+		return nil, err
 	}
 
-	driverPgUUID := pgtype.UUID{}
-	copy(driverPgUUID.Bytes[:], driverUUID[:])
-	driverPgUUID.Valid = true
-
-	ratings, err := s.repo.GetDriverRatings(ctx, db.GetDriverRatingsParams{
-		RatedID: driverPgUUID,
-		Limit:   limit,
-		Offset:  offset,
+	ratings, err := s.repo.GetUserRatings(ctx, db.GetUserRatingsParams{
+		RatedID:   utils.ToPgUUID(userID),
+		RaterType: raterType,
+		Limit:     limit,
+		Offset:    offset,
 	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to get driver ratings: %w", err)
+		return nil, fmt.Errorf("failed to get ratings: %w", err)
 	}
 
-	var response []domain.RatingResponse
-	for _, rating := range ratings {
-		ratingID, _ := uuid.FromBytes(rating.ID.Bytes[:])
-		tripID, _ := uuid.FromBytes(rating.TripID.Bytes[:])
-		raterID, _ := uuid.FromBytes(rating.RaterID.Bytes[:])
-		ratedID, _ := uuid.FromBytes(rating.RatedID.Bytes[:])
-
-		response = append(response, domain.RatingResponse{
-			ID:        ratingID.String(),
-			TripID:    tripID.String(),
-			RaterID:   raterID.String(),
-			RatedID:   ratedID.String(),
-			RaterType: rating.RaterType,
-			Rating:    rating.Rating,
-			Comment:   rating.Comment.String,
-			CreatedAt: rating.CreatedAt.Time,
-		})
+	ids := make([]pgtype.UUID, len(ratings))
+	for i, rating := range ratings {
+		ids[i] = rating.ID
+	}
+	tags, err := s.tagsByRating(ctx, ids)
+	if err != nil {
+		return nil, err
 	}
 
+	response := make([]domain.RatingResponse, 0, len(ratings))
+	for _, row := range ratings {
+		rating := toRatingResponse(db.Rating{
+			ID:              row.ID,
+			TripID:          row.TripID,
+			RaterID:         row.RaterID,
+			RatedID:         row.RatedID,
+			Rating:          row.Rating,
+			Feedback:        row.Feedback,
+			CreatedAt:       row.CreatedAt,
+			RaterType:       row.RaterType,
+			FeedbackFlagged: row.FeedbackFlagged,
+			FlagReasons:     row.FlagReasons,
+		}, tags[row.ID])
+		rating.RaterName = row.RaterName
+		hideFlaggedFeedback(rating)
+		response = append(response, *rating)
+	}
 	return response, nil
 }
 
-func (s *RatingService) GetAverageDriverRating(ctx context.Context, driverID string) (float64, error) {
-	driverUUID, err := uuid.Parse(driverID)
+// GetRatingSummary returns a user's average rating as a driver or as a
+// rider, and how often each tag was given to them. The average leaves out
+// excluded ratings and weighs each rating by its rater's weight.
+func (s *RatingService) GetRatingSummary(ctx context.Context, userID uuid.UUID, ratedAs string) (*domain.RatingSummaryResponse, error) {
+	raterType, err := raterTypeFor(ratedAs)
 	if err != nil {
-		return 0, errors.New("invalid driver ID")
+		return nil, err
 	}
+	pgUserID := utils.ToPgUUID(userID)
 
-	driverPgUUID := pgtype.UUID{}
-	copy(driverPgUUID.Bytes[:], driverUUID[:])
-	driverPgUUID.Valid = true
+	average, err := s.repo.GetAverageRating(ctx, db.GetAverageRatingParams{
+		RatedID:   pgUserID,
+		RaterType: raterType,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get average rating: %w", err)
+	}
 
-	avgRating, err := s.repo.GetAverageDriverRating(ctx, driverPgUUID)
+	tagCounts, err := s.repo.GetRatingTagCounts(ctx, db.GetRatingTagCountsParams{
+		RatedID:   pgUserID,
+		RaterType: raterType,
+	})
 	if err != nil {
-		return 0, fmt.Errorf("failed to get average rating: %w", err)
+		return nil, fmt.Errorf("failed to get rating tags: %w", err)
+	}
+
+	tags := make([]domain.RatingTagCount, len(tagCounts))
+	for i, t := range tagCounts {
+		tags[i] = domain.RatingTagCount{Tag: t.Tag, Count: t.Count}
+	}
+
+	return &domain.RatingSummaryResponse{
+		UserID:        userID.String(),
+		RatedAs:       ratedAs,
+		AverageRating: roundRating(average.AverageRating),
+		TotalRatings:  average.TotalRatings,
+		Tags:          tags,
+	}, nil
+}
+
+func (s *RatingService) tagsByRating(ctx context.Context, ids []pgtype.UUID) (map[pgtype.UUID][]string, error) {
+	tags := make(map[pgtype.UUID][]string, len(ids))
+	if len(ids) == 0 {
+		return tags, nil
+	}
+	rows, err := s.repo.GetRatingTags(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rating tags: %w", err)
+	}
+	for _, row := range rows {
+		tags[row.RatingID] = append(tags[row.RatingID], row.Tag)
 	}
+	return tags, nil
+}
 
-	// Convert string to float64
-	var rating float64
-	fmt.Sscanf(avgRating, "%f", &rating)
-	return rating, nil
+// raterTypeFor returns who rates users in a role: riders rate drivers and
+// drivers rate riders.
+func raterTypeFor(ratedAs string) (string, error) {
+	switch ratedAs {
+	case domain.RaterTypeDriver:
+		return domain.RaterTypeRider, nil
+	case domain.RaterTypeRider:
+		return domain.RaterTypeDriver, nil
+	}
+	return "", fmt.Errorf("%w: ratings are for a driver or a rider", ErrInvalidRating)
+}
+
+// normalizeTags lower-cases and de-duplicates tags, rejecting any the rater
+// can't give.
+func normalizeTags(raterType string, tags []string) ([]string, error) {
+	allowed := domain.RatingTags[raterType]
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if !slices.Contains(allowed, tag) {
+			return nil, fmt.Errorf("%w: unknown tag %q, choose from %s", ErrInvalidRating, tag, strings.Join(allowed, ", "))
+		}
+		if !slices.Contains(normalized, tag) {
+			normalized = append(normalized, tag)
+		}
+	}
+	if len(normalized) > maxRatingTags {
+		return nil, fmt.Errorf("%w: at most %d tags", ErrInvalidRating, maxRatingTags)
+	}
+	slices.Sort(normalized)
+	return normalized, nil
+}
+
+// roundRating rounds an average to two decimal places.
+func roundRating(average float64) float64 {
+	return math.Round(average*100) / 100
+}
+
+func toRatingResponse(rating db.Rating, tags []string) *domain.RatingResponse {
+	if tags == nil {
+		tags = []string{}
+	}
+	var flagReasons []string
+	if rating.FlagReasons.String != "" {
+		flagReasons = strings.Split(rating.FlagReasons.String, ",")
+	}
+	return &domain.RatingResponse{
+		ID:              utils.FromPgUUID(rating.ID).String(),
+		TripID:          utils.FromPgUUID(rating.TripID).String(),
+		RaterID:         utils.FromPgUUID(rating.RaterID).String(),
+		RatedID:         utils.FromPgUUID(rating.RatedID).String(),
+		RaterType:       rating.RaterType,
+		Rating:          rating.Rating,
+		Feedback:        rating.Feedback.String,
+		Tags:            tags,
+		CreatedAt:       rating.CreatedAt.Time,
+		FeedbackFlagged: rating.FeedbackFlagged,
+		FlagReasons:     flagReasons,
+		Excluded:        rating.ExcludedAt.Valid,
+	}
+}
+
+// hideFlaggedFeedback blanks feedback the content filter flagged, for
+// anyone but admins.
+func hideFlaggedFeedback(rating *domain.RatingResponse) {
+	if rating.FeedbackFlagged {
+		rating.Feedback = ""
+		rating.FlagReasons = nil
+	}
 }
@@ -0,0 +1,407 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/namycodes/yanga-services/services/support-service/internal/service"
+	"github.com/namycodes/yanga-services/shared-lib/domain"
+	"github.com/namycodes/yanga-services/shared-lib/middleware"
+	"github.com/namycodes/yanga-services/shared-lib/utils"
+)
+
+// Multipart bodies are allowed this much on top of the file itself for the
+// form's headers and boundaries.
+const multipartOverhead = 1 << 20
+
+type SupportHandler struct {
+	supportService *service.SupportService
+}
+
+func NewSupportHandler(supportService *service.SupportService) *SupportHandler {
+	return &SupportHandler{
+		supportService: supportService,
+	}
+}
+
+// CreateTicket godoc
+// @Summary Open a support ticket
+// @Description Fare disputes, lost items and reports about a driver or rider need the trip they're about. Lost items are shared with the trip's driver. The description becomes the first message.
+// @Tags support
+// @Accept json
+// @Produce json
+// @Param request body domain.CreateSupportTicketRequest true "Ticket"
+// @Success 201 {object} domain.SuccessResponse
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 403 {object} domain.ErrorResponse
+// @Failure 409 {object} domain.ErrorResponse
+// @Router /support/tickets [post]
+// @Security BearerAuth
+func (h *SupportHandler) CreateTicket(w http.ResponseWriter, r *http.Request) {
+	userID, ok := supportUser(w, r)
+	if !ok {
+		return
+	}
+
+	var req domain.CreateSupportTicketRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	ticket, err := h.supportService.CreateTicket(r.Context(), userID, middleware.GetUserRole(r.Context()), &req)
+	if err != nil {
+		handleSupportError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusCreated, "Support ticket created successfully", ticket)
+}
+
+// ListTickets godoc
+// @Summary Get the caller's support tickets
+// @Description Tickets the caller opened, and lost item reports about trips they drove, newest first.
+// @Tags support
+// @Produce json
+// @Param limit query int false "Limit" default(20)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {object} domain.SuccessResponse
+// @Router /support/tickets [get]
+// @Security BearerAuth
+func (h *SupportHandler) ListTickets(w http.ResponseWriter, r *http.Request) {
+	userID, ok := supportUser(w, r)
+	if !ok {
+		return
+	}
+
+	tickets, err := h.supportService.ListTickets(r.Context(), userID, queryInt(r, "limit", 20), queryInt(r, "offset", 0))
+	if err != nil {
+		handleSupportError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Support tickets retrieved successfully", tickets)
+}
+
+// GetTicket godoc
+// @Summary Get a support ticket with its messages and attachments
+// @Description Agents also see internal notes.
+// @Tags support
+// @Produce json
+// @Param id path string true "Ticket ID"
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Router /support/tickets/{id} [get]
+// @Security BearerAuth
+func (h *SupportHandler) GetTicket(w http.ResponseWriter, r *http.Request) {
+	userID, ticketID, ok := supportTicket(w, r)
+	if !ok {
+		return
+	}
+
+	ticket, err := h.supportService.GetTicket(r.Context(), ticketID, userID, middleware.GetUserRole(r.Context()))
+	if err != nil {
+		handleSupportError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Support ticket retrieved successfully", ticket)
+}
+
+// Reply godoc
+// @Summary Reply on a support ticket
+// @Description An agent's reply leaves the ticket awaiting the user; the user's reply puts it back in the queue. Only agents may post internal notes.
+// @Tags support
+// @Accept json
+// @Produce json
+// @Param id path string true "Ticket ID"
+// @Param request body domain.SupportReplyRequest true "Message"
+// @Success 201 {object} domain.SuccessResponse
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 409 {object} domain.ErrorResponse
+// @Router /support/tickets/{id}/messages [post]
+// @Security BearerAuth
+func (h *SupportHandler) Reply(w http.ResponseWriter, r *http.Request) {
+	userID, ticketID, ok := supportTicket(w, r)
+	if !ok {
+		return
+	}
+
+	var req domain.SupportReplyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	message, err := h.supportService.Reply(r.Context(), ticketID, userID, middleware.GetUserRole(r.Context()), &req)
+	if err != nil {
+		handleSupportError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusCreated, "Reply sent successfully", message)
+}
+
+// AddAttachment godoc
+// @Summary Attach a file to a support ticket
+// @Description JPEG, PNG or WebP images, or PDFs, up to SUPPORT_ATTACHMENT_MAX_MB each and 10 per ticket.
+// @Tags support
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path string true "Ticket ID"
+// @Param file formData file true "File"
+// @Success 201 {object} domain.SuccessResponse
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 409 {object} domain.ErrorResponse
+// @Failure 413 {object} domain.ErrorResponse
+// @Failure 415 {object} domain.ErrorResponse
+// @Router /support/tickets/{id}/attachments [post]
+// @Security BearerAuth
+func (h *SupportHandler) AddAttachment(w http.ResponseWriter, r *http.Request) {
+	userID, ticketID, ok := supportTicket(w, r)
+	if !ok {
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, h.supportService.MaxAttachmentBytes()+multipartOverhead)
+	if err := r.ParseMultipartForm(multipartOverhead); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			handleSupportError(w, service.ErrAttachmentTooLarge)
+			return
+		}
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid multipart form")
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "A file is required")
+		return
+	}
+	defer file.Close()
+
+	attachment, err := h.supportService.AddAttachment(r.Context(), ticketID, userID, middleware.GetUserRole(r.Context()), header.Filename, header.Size, file)
+	if err != nil {
+		handleSupportError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusCreated, "Attachment added successfully", attachment)
+}
+
+// GetAttachment godoc
+// @Summary Download a support ticket attachment
+// @Tags support
+// @Produce octet-stream
+// @Param id path string true "Ticket ID"
+// @Param attachment_id path string true "Attachment ID"
+// @Success 200 {file} file
+// @Failure 404 {object} domain.ErrorResponse
+// @Router /support/tickets/{id}/attachments/{attachment_id} [get]
+// @Security BearerAuth
+func (h *SupportHandler) GetAttachment(w http.ResponseWriter, r *http.Request) {
+	userID, ticketID, ok := supportTicket(w, r)
+	if !ok {
+		return
+	}
+
+	attachmentID, err := utils.ParseUUID(mux.Vars(r)["attachment_id"])
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid attachment ID")
+		return
+	}
+
+	attachment, content, err := h.supportService.OpenAttachment(r.Context(), ticketID, attachmentID, userID, middleware.GetUserRole(r.Context()))
+	if err != nil {
+		handleSupportError(w, err)
+		return
+	}
+	defer content.Close()
+
+	w.Header().Set("Content-Type", attachment.ContentType)
+	w.Header().Set("Content-Length", strconv.FormatInt(attachment.SizeBytes, 10))
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": attachment.FileName}))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusOK)
+	if _, err := io.Copy(w, content); err != nil {
+		log.Printf("Failed to send attachment %s: %v", attachment.ID, err)
+	}
+}
+
+// ListQueue godoc
+// @Summary Get the support queue (admin only)
+// @Description Most urgent first, then by resolution deadline.
+// @Tags support
+// @Produce json
+// @Param status query string false "open, awaiting_user or resolved"
+// @Param category query string false "Ticket category"
+// @Param assigned_to query string false "Agent ID"
+// @Param unassigned query bool false "Only unassigned tickets"
+// @Param limit query int false "Limit" default(50)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 400 {object} domain.ErrorResponse
+// @Router /support/queue [get]
+// @Security BearerAuth
+func (h *SupportHandler) ListQueue(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	var assignedTo *uuid.UUID
+	if value := query.Get("assigned_to"); value != "" {
+		agentID, err := utils.ParseUUID(value)
+		if err != nil {
+			utils.ErrorResponse(w, http.StatusBadRequest, "Invalid assigned_to")
+			return
+		}
+		assignedTo = &agentID
+	}
+	unassigned, _ := strconv.ParseBool(query.Get("unassigned"))
+
+	tickets, err := h.supportService.ListQueue(r.Context(), query.Get("status"), query.Get("category"), assignedTo, unassigned, queryInt(r, "limit", 50), queryInt(r, "offset", 0))
+	if err != nil {
+		handleSupportError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Support queue retrieved successfully", tickets)
+}
+
+// AssignTicket godoc
+// @Summary Assign a support ticket to an agent (admin only)
+// @Description Assigns it to the caller when no agent is given.
+// @Tags support
+// @Accept json
+// @Produce json
+// @Param id path string true "Ticket ID"
+// @Param request body domain.AssignSupportTicketRequest false "Agent"
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 409 {object} domain.ErrorResponse
+// @Router /support/tickets/{id}/assign [post]
+// @Security BearerAuth
+func (h *SupportHandler) AssignTicket(w http.ResponseWriter, r *http.Request) {
+	adminID, ticketID, ok := supportTicket(w, r)
+	if !ok {
+		return
+	}
+
+	var req domain.AssignSupportTicketRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+	}
+
+	ticket, err := h.supportService.AssignTicket(r.Context(), ticketID, adminID, &req)
+	if err != nil {
+		handleSupportError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Support ticket assigned successfully", ticket)
+}
+
+// ResolveTicket godoc
+// @Summary Resolve a support ticket (admin only)
+// @Description A refund gives the amount back to the rider; a fare adjustment sets the trip's fare to the amount and refunds the difference. Both are for the rider of a completed trip, are capped at what they paid, and are posted to the ledger by payment-service. complaint_category upholds a complaint against the driver on driver behaviour and safety tickets.
+// @Tags support
+// @Accept json
+// @Produce json
+// @Param id path string true "Ticket ID"
+// @Param request body domain.ResolveSupportTicketRequest true "Resolution"
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 409 {object} domain.ErrorResponse
+// @Router /support/tickets/{id}/resolve [post]
+// @Security BearerAuth
+func (h *SupportHandler) ResolveTicket(w http.ResponseWriter, r *http.Request) {
+	adminID, ticketID, ok := supportTicket(w, r)
+	if !ok {
+		return
+	}
+
+	var req domain.ResolveSupportTicketRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	ticket, err := h.supportService.ResolveTicket(r.Context(), ticketID, adminID, &req)
+	if err != nil {
+		handleSupportError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Support ticket resolved successfully", ticket)
+}
+
+func supportUser(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	userIDStr := r.Context().Value("user_id").(string)
+	userID, err := utils.ParseUUID(userIDStr)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid user ID")
+		return uuid.Nil, false
+	}
+	return userID, true
+}
+
+func supportTicket(w http.ResponseWriter, r *http.Request) (uuid.UUID, uuid.UUID, bool) {
+	userID, ok := supportUser(w, r)
+	if !ok {
+		return uuid.Nil, uuid.Nil, false
+	}
+	ticketID, err := utils.ParseUUID(mux.Vars(r)["id"])
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid ticket ID")
+		return uuid.Nil, uuid.Nil, false
+	}
+	return userID, ticketID, true
+}
+
+func handleSupportError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidTicket),
+		errors.Is(err, service.ErrInvalidMessage),
+		errors.Is(err, service.ErrInvalidAttachment),
+		errors.Is(err, service.ErrInvalidResolution),
+		errors.Is(err, service.ErrInvalidQueueFilter):
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, service.ErrNotTripParticipant):
+		utils.ErrorResponse(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, service.ErrTripNotFound),
+		errors.Is(err, service.ErrTicketNotFound),
+		errors.Is(err, service.ErrAttachmentNotFound):
+		utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrDuplicateTicket),
+		errors.Is(err, service.ErrTicketResolved),
+		errors.Is(err, service.ErrTooManyAttachments):
+		utils.ErrorResponse(w, http.StatusConflict, err.Error())
+	case errors.Is(err, service.ErrAttachmentTooLarge):
+		utils.ErrorResponse(w, http.StatusRequestEntityTooLarge, err.Error())
+	case errors.Is(err, service.ErrUnsupportedContentType):
+		utils.ErrorResponse(w, http.StatusUnsupportedMediaType, err.Error())
+	default:
+		utils.HandleServiceError(w, err)
+	}
+}
+
+func queryInt(r *http.Request, name string, defaultValue int32) int32 {
+	value, err := strconv.Atoi(r.URL.Query().Get(name))
+	if err != nil || value < 0 {
+		return defaultValue
+	}
+	return int32(value)
+}
@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/namycodes/yanga-services/services/support-service/internal/db"
+)
+
+type SupportRepository struct {
+	queries *db.Queries
+}
+
+func NewSupportRepository(queries *db.Queries) *SupportRepository {
+	return &SupportRepository{
+		queries: queries,
+	}
+}
+
+func (r *SupportRepository) AssignSupportTicket(ctx context.Context, params db.AssignSupportTicketParams) (db.SupportTicket, error) {
+	return r.queries.AssignSupportTicket(ctx, params)
+}
+
+func (r *SupportRepository) CountSupportAttachments(ctx context.Context, ticketID pgtype.UUID) (int64, error) {
+	return r.queries.CountSupportAttachments(ctx, ticketID)
+}
+
+func (r *SupportRepository) CreateSupportAttachment(ctx context.Context, params db.CreateSupportAttachmentParams) (db.SupportAttachment, error) {
+	return r.queries.CreateSupportAttachment(ctx, params)
+}
+
+func (r *SupportRepository) CreateSupportMessage(ctx context.Context, params db.CreateSupportMessageParams) (db.SupportMessage, error) {
+	return r.queries.CreateSupportMessage(ctx, params)
+}
+
+func (r *SupportRepository) CreateSupportTicket(ctx context.Context, params db.CreateSupportTicketParams) (db.SupportTicket, error) {
+	return r.queries.CreateSupportTicket(ctx, params)
+}
+
+func (r *SupportRepository) GetSupportAttachment(ctx context.Context, params db.GetSupportAttachmentParams) (db.SupportAttachment, error) {
+	return r.queries.GetSupportAttachment(ctx, params)
+}
+
+func (r *SupportRepository) GetSupportTicket(ctx context.Context, id pgtype.UUID) (db.SupportTicket, error) {
+	return r.queries.GetSupportTicket(ctx, id)
+}
+
+func (r *SupportRepository) GetSupportTrip(ctx context.Context, id pgtype.UUID) (db.GetSupportTripRow, error) {
+	return r.queries.GetSupportTrip(ctx, id)
+}
+
+func (r *SupportRepository) GetTripAdjustmentTotal(ctx context.Context, tripID pgtype.UUID) (pgtype.Numeric, error) {
+	return r.queries.GetTripAdjustmentTotal(ctx, tripID)
+}
+
+func (r *SupportRepository) ListFirstResponseBreaches(ctx context.Context, now pgtype.Timestamp) ([]db.SupportTicket, error) {
+	return r.queries.ListFirstResponseBreaches(ctx, now)
+}
+
+func (r *SupportRepository) ListResolutionBreaches(ctx context.Context, now pgtype.Timestamp) ([]db.SupportTicket, error) {
+	return r.queries.ListResolutionBreaches(ctx, now)
+}
+
+func (r *SupportRepository) ListSupportAttachments(ctx context.Context, ticketID pgtype.UUID) ([]db.SupportAttachment, error) {
+	return r.queries.ListSupportAttachments(ctx, ticketID)
+}
+
+func (r *SupportRepository) ListSupportMessages(ctx context.Context, params db.ListSupportMessagesParams) ([]db.SupportMessage, error) {
+	return r.queries.ListSupportMessages(ctx, params)
+}
+
+func (r *SupportRepository) ListSupportQueue(ctx context.Context, params db.ListSupportQueueParams) ([]db.SupportTicket, error) {
+	return r.queries.ListSupportQueue(ctx, params)
+}
+
+func (r *SupportRepository) ListUserSupportTickets(ctx context.Context, params db.ListUserSupportTicketsParams) ([]db.SupportTicket, error) {
+	return r.queries.ListUserSupportTickets(ctx, params)
+}
+
+func (r *SupportRepository) MarkFirstResponseBreached(ctx context.Context, params db.MarkFirstResponseBreachedParams) (int64, error) {
+	return r.queries.MarkFirstResponseBreached(ctx, params)
+}
+
+func (r *SupportRepository) MarkResolutionBreached(ctx context.Context, params db.MarkResolutionBreachedParams) (int64, error) {
+	return r.queries.MarkResolutionBreached(ctx, params)
+}
+
+func (r *SupportRepository) RecordSupportAgentReply(ctx context.Context, params db.RecordSupportAgentReplyParams) (db.SupportTicket, error) {
+	return r.queries.RecordSupportAgentReply(ctx, params)
+}
+
+func (r *SupportRepository) RecordSupportUserReply(ctx context.Context, id pgtype.UUID) (db.SupportTicket, error) {
+	return r.queries.RecordSupportUserReply(ctx, id)
+}
+
+func (r *SupportRepository) ResolveSupportTicket(ctx context.Context, params db.ResolveSupportTicketParams) (db.SupportTicket, error) {
+	return r.queries.ResolveSupportTicket(ctx, params)
+}
+
+func (r *SupportRepository) SettleSupportAdjustment(ctx context.Context, params db.SettleSupportAdjustmentParams) (int64, error) {
+	return r.queries.SettleSupportAdjustment(ctx, params)
+}
@@ -0,0 +1,34 @@
+package routes
+
+import (
+	"github.com/gorilla/mux"
+	"github.com/namycodes/yanga-services/services/support-service/internal/handler"
+	"github.com/namycodes/yanga-services/shared-lib/config"
+	"github.com/namycodes/yanga-services/shared-lib/middleware"
+	httpSwagger "github.com/swaggo/http-swagger"
+)
+
+func SetupSupportRoutes(router *mux.Router, supportHandler *handler.SupportHandler, jwtCfg config.JWTConfig) {
+	api := router.PathPrefix("/api/v1").Subrouter()
+
+	support := api.PathPrefix("/support").Subrouter()
+	support.Use(middleware.AuthMiddleware(jwtCfg.Secret, jwtCfg.IdentitySecret))
+
+	// Tickets, for the rider or driver who opened them and for agents
+	support.HandleFunc("/tickets", supportHandler.CreateTicket).Methods("POST")
+	support.HandleFunc("/tickets", supportHandler.ListTickets).Methods("GET")
+	support.HandleFunc("/tickets/{id}", supportHandler.GetTicket).Methods("GET")
+	support.HandleFunc("/tickets/{id}/messages", supportHandler.Reply).Methods("POST")
+	support.HandleFunc("/tickets/{id}/attachments", supportHandler.AddAttachment).Methods("POST")
+	support.HandleFunc("/tickets/{id}/attachments/{attachment_id}", supportHandler.GetAttachment).Methods("GET")
+
+	// Queue, assignment and resolution - admin only
+	admin := support.NewRoute().Subrouter()
+	admin.Use(middleware.RequireRole("admin"))
+
+	admin.HandleFunc("/queue", supportHandler.ListQueue).Methods("GET")
+	admin.HandleFunc("/tickets/{id}/assign", supportHandler.AssignTicket).Methods("POST")
+	admin.HandleFunc("/tickets/{id}/resolve", supportHandler.ResolveTicket).Methods("POST")
+
+	router.PathPrefix("/swagger/").Handler(httpSwagger.WrapHandler)
+}
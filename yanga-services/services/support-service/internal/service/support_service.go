@@ -0,0 +1,895 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/namycodes/yanga-services/services/support-service/internal/db"
+	"github.com/namycodes/yanga-services/services/support-service/internal/repository"
+	"github.com/namycodes/yanga-services/shared-lib/blobstore"
+	"github.com/namycodes/yanga-services/shared-lib/config"
+	"github.com/namycodes/yanga-services/shared-lib/domain"
+	"github.com/namycodes/yanga-services/shared-lib/events"
+	"github.com/namycodes/yanga-services/shared-lib/money"
+	"github.com/namycodes/yanga-services/shared-lib/utils"
+)
+
+const (
+	maxSubjectLength      = 200
+	maxMessageLength      = 5000
+	maxFileNameLength     = 255
+	maxTicketAttachments  = 10
+	contentSniffBytes     = 512
+	deadlineFirstResponse = "first_response"
+	deadlineResolution    = "resolution"
+)
+
+var (
+	ErrInvalidTicket          = errors.New("invalid support ticket")
+	ErrInvalidMessage         = errors.New("invalid message")
+	ErrInvalidAttachment      = errors.New("invalid attachment")
+	ErrInvalidResolution      = errors.New("invalid resolution")
+	ErrInvalidQueueFilter     = errors.New("invalid queue filter")
+	ErrTripNotFound           = errors.New("trip not found")
+	ErrNotTripParticipant     = errors.New("only the trip's rider or driver can open a ticket about it")
+	ErrDuplicateTicket        = errors.New("an open ticket about this trip already exists")
+	ErrTicketNotFound         = errors.New("support ticket not found")
+	ErrTicketResolved         = errors.New("support ticket is already resolved")
+	ErrAttachmentNotFound     = errors.New("attachment not found")
+	ErrTooManyAttachments     = fmt.Errorf("at most %d files can be attached to a ticket", maxTicketAttachments)
+	ErrAttachmentTooLarge     = errors.New("attachment is too large")
+	ErrUnsupportedContentType = errors.New("attachments must be JPEG, PNG or WebP images, or PDFs")
+)
+
+// Categories that are always about a trip
+var tripCategories = []string{
+	domain.SupportCategoryFareDispute,
+	domain.SupportCategoryLostItem,
+	domain.SupportCategoryDriverBehavior,
+	domain.SupportCategoryRiderBehavior,
+}
+
+// Each category is answered and resolved within the deadlines of its
+// priority. Safety comes first, then things a user is out of pocket or
+// without their belongings over.
+var categoryPriority = map[string]string{
+	domain.SupportCategorySafety:         domain.SupportPriorityUrgent,
+	domain.SupportCategoryLostItem:       domain.SupportPriorityHigh,
+	domain.SupportCategoryPayment:        domain.SupportPriorityHigh,
+	domain.SupportCategoryFareDispute:    domain.SupportPriorityNormal,
+	domain.SupportCategoryDriverBehavior: domain.SupportPriorityNormal,
+	domain.SupportCategoryRiderBehavior:  domain.SupportPriorityNormal,
+	domain.SupportCategoryAccount:        domain.SupportPriorityLow,
+	domain.SupportCategoryOther:          domain.SupportPriorityLow,
+}
+
+type sla struct {
+	firstResponse time.Duration
+	resolution    time.Duration
+}
+
+var prioritySLA = map[string]sla{
+	domain.SupportPriorityUrgent: {15 * time.Minute, 4 * time.Hour},
+	domain.SupportPriorityHigh:   {time.Hour, 24 * time.Hour},
+	domain.SupportPriorityNormal: {4 * time.Hour, 72 * time.Hour},
+	domain.SupportPriorityLow:    {24 * time.Hour, 120 * time.Hour},
+}
+
+var attachmentTypes = []string{"image/jpeg", "image/png", "image/webp", "application/pdf"}
+
+// SupportService runs the help desk: riders and drivers open tickets,
+// mostly about a trip, and agents (admins) reply, assign and resolve them.
+//
+// Every ticket has a first response and a resolution deadline set from its
+// category's priority; the SLA worker flags each one missed, once. Refunds
+// and fare adjustments granted on resolution are applied by payment-service,
+// which reports back whether they made it into the ledger.
+type SupportService struct {
+	repo               *repository.SupportRepository
+	blobs              blobstore.Store
+	eventBus           events.EventBus
+	maxAttachmentBytes int64
+	checkInterval      time.Duration
+}
+
+func NewSupportService(repo *repository.SupportRepository, blobs blobstore.Store, eventBus events.EventBus, cfg *config.Config) *SupportService {
+	return &SupportService{
+		repo:               repo,
+		blobs:              blobs,
+		eventBus:           eventBus,
+		maxAttachmentBytes: int64(cfg.SupportAttachmentMaxMB) << 20,
+		checkInterval:      time.Duration(cfg.SupportSLACheckIntervalSeconds) * time.Second,
+	}
+}
+
+// MaxAttachmentBytes is the largest file that can be attached to a ticket.
+func (s *SupportService) MaxAttachmentBytes() int64 {
+	return s.maxAttachmentBytes
+}
+
+// CreateTicket opens a ticket, with the description as its first message.
+// Lost item reports are shared with the trip's driver so they can reply.
+func (s *SupportService) CreateTicket(ctx context.Context, userID uuid.UUID, role string, req *domain.CreateSupportTicketRequest) (*domain.SupportTicketDetailResponse, error) {
+	if !slices.Contains(domain.SupportCategories, req.Category) {
+		return nil, fmt.Errorf("%w: category must be one of %s", ErrInvalidTicket, strings.Join(domain.SupportCategories, ", "))
+	}
+	subject := strings.TrimSpace(req.Subject)
+	if subject == "" || len([]rune(subject)) > maxSubjectLength {
+		return nil, fmt.Errorf("%w: subject is required and limited to %d characters", ErrInvalidTicket, maxSubjectLength)
+	}
+	description := strings.TrimSpace(req.Description)
+	if description == "" || len([]rune(description)) > maxMessageLength {
+		return nil, fmt.Errorf("%w: description is required and limited to %d characters", ErrInvalidTicket, maxMessageLength)
+	}
+
+	var tripID, driverID pgtype.UUID
+	if req.TripID == nil {
+		if slices.Contains(tripCategories, req.Category) {
+			return nil, fmt.Errorf("%w: trip_id is required for %s tickets", ErrInvalidTicket, req.Category)
+		}
+	} else {
+		trip, err := s.getTrip(ctx, *req.TripID)
+		if err != nil {
+			return nil, err
+		}
+		isRider := utils.FromPgUUID(trip.UserID) == userID
+		isDriver := trip.DriverID.Valid && utils.FromPgUUID(trip.DriverID) == userID
+		if !isRider && !isDriver {
+			return nil, ErrNotTripParticipant
+		}
+
+		switch req.Category {
+		case domain.SupportCategoryDriverBehavior:
+			if !isRider {
+				return nil, fmt.Errorf("%w: only the rider can report the driver", ErrInvalidTicket)
+			}
+		case domain.SupportCategoryRiderBehavior:
+			if !isDriver {
+				return nil, fmt.Errorf("%w: only the driver can report the rider", ErrInvalidTicket)
+			}
+		case domain.SupportCategoryLostItem:
+			if !isRider || !trip.DriverID.Valid {
+				return nil, fmt.Errorf("%w: lost items are reported by the rider of a trip with a driver", ErrInvalidTicket)
+			}
+			driverID = trip.DriverID
+		}
+		tripID = trip.ID
+	}
+
+	priority := categoryPriority[req.Category]
+	deadlines := prioritySLA[priority]
+	now := time.Now().UTC()
+
+	ticket, err := s.repo.CreateSupportTicket(ctx, db.CreateSupportTicketParams{
+		UserID:             utils.ToPgUUID(userID),
+		TripID:             tripID,
+		DriverID:           driverID,
+		Category:           req.Category,
+		Priority:           priority,
+		Subject:            subject,
+		FirstResponseDueAt: pgtype.Timestamp{Time: now.Add(deadlines.firstResponse), Valid: true},
+		ResolutionDueAt:    pgtype.Timestamp{Time: now.Add(deadlines.resolution), Valid: true},
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrDuplicateTicket
+		}
+		return nil, fmt.Errorf("failed to create support ticket: %w", err)
+	}
+
+	message, err := s.repo.CreateSupportMessage(ctx, db.CreateSupportMessageParams{
+		TicketID:   ticket.ID,
+		AuthorID:   utils.ToPgUUID(userID),
+		AuthorRole: authorRole(role),
+		Body:       description,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to save ticket description: %w", err)
+	}
+
+	event := events.SupportTicketCreatedEvent{
+		TicketID:  utils.FromPgUUID(ticket.ID).String(),
+		UserID:    userID.String(),
+		Category:  ticket.Category,
+		Priority:  ticket.Priority,
+		Subject:   ticket.Subject,
+		Timestamp: now,
+	}
+	if ticket.TripID.Valid {
+		event.TripID = utils.FromPgUUID(ticket.TripID).String()
+	}
+	if ticket.DriverID.Valid {
+		event.DriverID = utils.FromPgUUID(ticket.DriverID).String()
+	}
+	s.eventBus.Publish(ctx, events.SubjectSupportTicketCreated, event)
+
+	return &domain.SupportTicketDetailResponse{
+		SupportTicketResponse: toTicketResponse(ticket),
+		Messages:              []domain.SupportMessageResponse{toMessageResponse(message)},
+		Attachments:           []domain.SupportAttachmentResponse{},
+	}, nil
+}
+
+// ListTickets returns the tickets a user opened and lost item reports about
+// trips they drove, newest first.
+func (s *SupportService) ListTickets(ctx context.Context, userID uuid.UUID, limit, offset int32) ([]domain.SupportTicketResponse, error) {
+	tickets, err := s.repo.ListUserSupportTickets(ctx, db.ListUserSupportTicketsParams{
+		UserID: utils.ToPgUUID(userID),
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get support tickets: %w", err)
+	}
+	return toTicketResponses(tickets), nil
+}
+
+// GetTicket returns a ticket with its thread and attachments. Internal
+// notes are only shown to agents.
+func (s *SupportService) GetTicket(ctx context.Context, ticketID, userID uuid.UUID, role string) (*domain.SupportTicketDetailResponse, error) {
+	ticket, err := s.visibleTicket(ctx, ticketID, userID, role)
+	if err != nil {
+		return nil, err
+	}
+
+	messages, err := s.repo.ListSupportMessages(ctx, db.ListSupportMessagesParams{
+		TicketID:        ticket.ID,
+		IncludeInternal: isAgent(role),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ticket messages: %w", err)
+	}
+	attachments, err := s.repo.ListSupportAttachments(ctx, ticket.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ticket attachments: %w", err)
+	}
+
+	response := &domain.SupportTicketDetailResponse{
+		SupportTicketResponse: toTicketResponse(ticket),
+		Messages:              make([]domain.SupportMessageResponse, len(messages)),
+		Attachments:           make([]domain.SupportAttachmentResponse, len(attachments)),
+	}
+	for i, message := range messages {
+		response.Messages[i] = toMessageResponse(message)
+	}
+	for i, attachment := range attachments {
+		response.Attachments[i] = toAttachmentResponse(attachment)
+	}
+	return response, nil
+}
+
+// Reply adds a message to a ticket. An agent's reply leaves the ticket
+// awaiting the user and stops the first response clock; the user's reply
+// puts it back in the queue. Internal notes change neither.
+func (s *SupportService) Reply(ctx context.Context, ticketID, userID uuid.UUID, role string, req *domain.SupportReplyRequest) (*domain.SupportMessageResponse, error) {
+	body := strings.TrimSpace(req.Body)
+	if body == "" || len([]rune(body)) > maxMessageLength {
+		return nil, fmt.Errorf("%w: body is required and limited to %d characters", ErrInvalidMessage, maxMessageLength)
+	}
+	agent := isAgent(role)
+	if req.Internal && !agent {
+		return nil, fmt.Errorf("%w: only agents can post internal notes", ErrInvalidMessage)
+	}
+
+	ticket, err := s.visibleTicket(ctx, ticketID, userID, role)
+	if err != nil {
+		return nil, err
+	}
+	if ticket.Status == domain.SupportStatusResolved {
+		return nil, ErrTicketResolved
+	}
+
+	now := time.Now().UTC()
+	switch {
+	case req.Internal:
+	case agent:
+		ticket, err = s.repo.RecordSupportAgentReply(ctx, db.RecordSupportAgentReplyParams{
+			RepliedAt: pgtype.Timestamp{Time: now, Valid: true},
+			ID:        ticket.ID,
+		})
+	default:
+		ticket, err = s.repo.RecordSupportUserReply(ctx, ticket.ID)
+	}
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrTicketResolved
+		}
+		return nil, fmt.Errorf("failed to update support ticket: %w", err)
+	}
+
+	message, err := s.repo.CreateSupportMessage(ctx, db.CreateSupportMessageParams{
+		TicketID:   ticket.ID,
+		AuthorID:   utils.ToPgUUID(userID),
+		AuthorRole: authorRole(role),
+		Body:       body,
+		Internal:   req.Internal,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to save message: %w", err)
+	}
+
+	if !req.Internal {
+		if recipients := replyRecipients(ticket, userID, agent); len(recipients) > 0 {
+			s.eventBus.Publish(ctx, events.SubjectSupportTicketReplied, events.SupportTicketRepliedEvent{
+				TicketID:     utils.FromPgUUID(ticket.ID).String(),
+				MessageID:    utils.FromPgUUID(message.ID).String(),
+				AuthorRole:   message.AuthorRole,
+				RecipientIDs: recipients,
+				Subject:      ticket.Subject,
+				Timestamp:    now,
+			})
+		}
+	}
+
+	response := toMessageResponse(message)
+	return &response, nil
+}
+
+// AddAttachment stores a file on a ticket. The type is worked out from the
+// content rather than trusted from the upload.
+func (s *SupportService) AddAttachment(ctx context.Context, ticketID, userID uuid.UUID, role, fileName string, size int64, body io.Reader) (*domain.SupportAttachmentResponse, error) {
+	fileName = filepath.Base(strings.TrimSpace(fileName))
+	if fileName == "" || fileName == "." || fileName == string(filepath.Separator) || len(fileName) > maxFileNameLength {
+		return nil, fmt.Errorf("%w: a file name of up to %d characters is required", ErrInvalidAttachment, maxFileNameLength)
+	}
+	if size <= 0 {
+		return nil, fmt.Errorf("%w: file is empty", ErrInvalidAttachment)
+	}
+	if size > s.maxAttachmentBytes {
+		return nil, fmt.Errorf("%w: files are limited to %d MB", ErrAttachmentTooLarge, s.maxAttachmentBytes>>20)
+	}
+
+	ticket, err := s.visibleTicket(ctx, ticketID, userID, role)
+	if err != nil {
+		return nil, err
+	}
+	if ticket.Status == domain.SupportStatusResolved {
+		return nil, ErrTicketResolved
+	}
+	count, err := s.repo.CountSupportAttachments(ctx, ticket.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count attachments: %w", err)
+	}
+	if count >= maxTicketAttachments {
+		return nil, ErrTooManyAttachments
+	}
+
+	content := bufio.NewReaderSize(body, contentSniffBytes)
+	head, err := content.Peek(contentSniffBytes)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to read attachment: %w", err)
+	}
+	contentType, _, _ := strings.Cut(http.DetectContentType(head), ";")
+	if !slices.Contains(attachmentTypes, contentType) {
+		return nil, ErrUnsupportedContentType
+	}
+
+	key := fmt.Sprintf("support/%s/%s", ticketID, uuid.New())
+	if err := s.blobs.Put(ctx, key, contentType, io.LimitReader(content, size)); err != nil {
+		return nil, fmt.Errorf("failed to store attachment: %w", err)
+	}
+
+	attachment, err := s.repo.CreateSupportAttachment(ctx, db.CreateSupportAttachmentParams{
+		TicketID:    ticket.ID,
+		UploadedBy:  utils.ToPgUUID(userID),
+		FileName:    fileName,
+		ContentType: contentType,
+		SizeBytes:   size,
+		BlobKey:     key,
+	})
+	if err != nil {
+		if delErr := s.blobs.Delete(ctx, key); delErr != nil {
+			log.Printf("Failed to remove orphaned attachment %s: %v", key, delErr)
+		}
+		return nil, fmt.Errorf("failed to save attachment: %w", err)
+	}
+
+	response := toAttachmentResponse(attachment)
+	return &response, nil
+}
+
+// OpenAttachment returns an attachment and its content, which the caller
+// must close.
+func (s *SupportService) OpenAttachment(ctx context.Context, ticketID, attachmentID, userID uuid.UUID, role string) (*domain.SupportAttachmentResponse, io.ReadCloser, error) {
+	ticket, err := s.visibleTicket(ctx, ticketID, userID, role)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	attachment, err := s.repo.GetSupportAttachment(ctx, db.GetSupportAttachmentParams{
+		ID:       utils.ToPgUUID(attachmentID),
+		TicketID: ticket.ID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil, ErrAttachmentNotFound
+		}
+		return nil, nil, fmt.Errorf("failed to get attachment: %w", err)
+	}
+
+	content, err := s.blobs.Get(ctx, attachment.BlobKey)
+	if err != nil {
+		if errors.Is(err, blobstore.ErrNotFound) {
+			return nil, nil, ErrAttachmentNotFound
+		}
+		return nil, nil, fmt.Errorf("failed to read attachment: %w", err)
+	}
+
+	response := toAttachmentResponse(attachment)
+	return &response, content, nil
+}
+
+// ListQueue returns tickets for agents, most urgent first. Status,
+// category and assignee filter the queue when given.
+func (s *SupportService) ListQueue(ctx context.Context, status, category string, assignedTo *uuid.UUID, unassigned bool, limit, offset int32) ([]domain.SupportTicketResponse, error) {
+	params := db.ListSupportQueueParams{
+		Unassigned: unassigned,
+		Limit:      limit,
+		Offset:     offset,
+	}
+	if status != "" {
+		if status != domain.SupportStatusOpen && status != domain.SupportStatusAwaitingUser && status != domain.SupportStatusResolved {
+			return nil, fmt.Errorf("%w: status must be open, awaiting_user or resolved", ErrInvalidQueueFilter)
+		}
+		params.Status = pgtype.Text{String: status, Valid: true}
+	}
+	if category != "" {
+		if !slices.Contains(domain.SupportCategories, category) {
+			return nil, fmt.Errorf("%w: unknown category %q", ErrInvalidQueueFilter, category)
+		}
+		params.Category = pgtype.Text{String: category, Valid: true}
+	}
+	if assignedTo != nil {
+		params.AssignedTo = utils.ToPgUUID(*assignedTo)
+	}
+
+	tickets, err := s.repo.ListSupportQueue(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get support queue: %w", err)
+	}
+	return toTicketResponses(tickets), nil
+}
+
+// AssignTicket hands a ticket to an agent, the caller unless another is
+// named.
+func (s *SupportService) AssignTicket(ctx context.Context, ticketID, adminID uuid.UUID, req *domain.AssignSupportTicketRequest) (*domain.SupportTicketResponse, error) {
+	agentID := adminID
+	if req.AgentID != nil {
+		agentID = *req.AgentID
+	}
+
+	ticket, err := s.getTicket(ctx, ticketID)
+	if err != nil {
+		return nil, err
+	}
+	if ticket.Status == domain.SupportStatusResolved {
+		return nil, ErrTicketResolved
+	}
+
+	ticket, err = s.repo.AssignSupportTicket(ctx, db.AssignSupportTicketParams{
+		AssignedTo: utils.ToPgUUID(agentID),
+		AssignedAt: pgtype.Timestamp{Time: time.Now().UTC(), Valid: true},
+		ID:         ticket.ID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrTicketResolved
+		}
+		return nil, fmt.Errorf("failed to assign support ticket: %w", err)
+	}
+
+	response := toTicketResponse(ticket)
+	return &response, nil
+}
+
+// ResolveTicket closes a ticket. A refund or fare adjustment is only for
+// the rider of a completed trip, capped at what they paid, and is handed
+// to payment-service to post to the ledger; the ticket shows it pending
+// until payment-service reports back.
+func (s *SupportService) ResolveTicket(ctx context.Context, ticketID, adminID uuid.UUID, req *domain.ResolveSupportTicketRequest) (*domain.SupportTicketResponse, error) {
+	note := strings.TrimSpace(req.Note)
+	if note == "" || len([]rune(note)) > maxMessageLength {
+		return nil, fmt.Errorf("%w: a note of up to %d characters is required", ErrInvalidResolution, maxMessageLength)
+	}
+	switch req.Resolution {
+	case domain.SupportResolutionNone, domain.SupportResolutionRefund, domain.SupportResolutionFareAdjustment:
+	default:
+		return nil, fmt.Errorf("%w: resolution must be none, refund or fare_adjustment", ErrInvalidResolution)
+	}
+
+	ticket, err := s.getTicket(ctx, ticketID)
+	if err != nil {
+		return nil, err
+	}
+	if ticket.Status == domain.SupportStatusResolved {
+		return nil, ErrTicketResolved
+	}
+
+	var trip *db.GetSupportTripRow
+	if ticket.TripID.Valid {
+		trip, err = s.getTrip(ctx, utils.FromPgUUID(ticket.TripID))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if req.ComplaintCategory != "" {
+		if ticket.Category != domain.SupportCategoryDriverBehavior && ticket.Category != domain.SupportCategorySafety {
+			return nil, fmt.Errorf("%w: complaints can only be upheld on driver behaviour and safety tickets", ErrInvalidResolution)
+		}
+		if trip == nil || !trip.DriverID.Valid || trip.UserID != ticket.UserID {
+			return nil, fmt.Errorf("%w: the ticket must be the rider's, about a trip with a driver, to uphold a complaint", ErrInvalidResolution)
+		}
+		if !slices.Contains(domain.ComplaintCategories, req.ComplaintCategory) {
+			return nil, fmt.Errorf("%w: complaint_category must be one of %s", ErrInvalidResolution, strings.Join(domain.ComplaintCategories, ", "))
+		}
+	}
+
+	var credit, paid money.Money
+	var currency string
+	if req.Resolution != domain.SupportResolutionNone {
+		if trip == nil || trip.UserID != ticket.UserID || trip.Status != domain.TripStatusCompleted {
+			return nil, fmt.Errorf("%w: refunds and fare adjustments are for the rider of a completed trip", ErrInvalidResolution)
+		}
+		credited, err := s.repo.GetTripAdjustmentTotal(ctx, trip.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get earlier adjustments: %w", err)
+		}
+		currency = trip.Currency
+		paid = tripPaid(trip)
+		credit, err = adjustmentCredit(trip, req.Resolution, req.Amount, money.FromNumeric(credited, money.Currency(currency)))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	params := db.ResolveSupportTicketParams{
+		Resolution:     pgtype.Text{String: req.Resolution, Valid: true},
+		ResolutionNote: pgtype.Text{String: note, Valid: true},
+		ResolvedBy:     utils.ToPgUUID(adminID),
+		ResolvedAt:     pgtype.Timestamp{Time: time.Now().UTC(), Valid: true},
+		ID:             ticket.ID,
+	}
+	if credit.Sign() > 0 {
+		params.AdjustmentAmount = credit.Numeric()
+		params.AdjustmentStatus = pgtype.Text{String: domain.SupportAdjustmentPending, Valid: true}
+	}
+
+	ticket, err = s.repo.ResolveSupportTicket(ctx, params)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrTicketResolved
+		}
+		return nil, fmt.Errorf("failed to resolve support ticket: %w", err)
+	}
+
+	event := events.SupportTicketResolvedEvent{
+		TicketID:          utils.FromPgUUID(ticket.ID).String(),
+		UserID:            utils.FromPgUUID(ticket.UserID).String(),
+		Category:          ticket.Category,
+		Resolution:        req.Resolution,
+		Amount:            credit.Float64(),
+		Currency:          currency,
+		Paid:              paid.Float64(),
+		ComplaintCategory: req.ComplaintCategory,
+		Note:              note,
+		ResolvedBy:        adminID.String(),
+		Timestamp:         ticket.ResolvedAt.Time,
+	}
+	if trip != nil {
+		event.TripID = utils.FromPgUUID(trip.ID).String()
+		if trip.DriverID.Valid {
+			event.DriverID = utils.FromPgUUID(trip.DriverID).String()
+		}
+	}
+	s.eventBus.Publish(ctx, events.SubjectSupportTicketResolved, event)
+
+	response := toTicketResponse(ticket)
+	return &response, nil
+}
+
+// SettleAdjustment records payment-service's outcome for a ticket's refund
+// or fare adjustment.
+func (s *SupportService) SettleAdjustment(ctx context.Context, event events.SupportAdjustmentSettledEvent) error {
+	ticketID, err := uuid.Parse(event.TicketID)
+	if err != nil {
+		return fmt.Errorf("invalid ticket ID: %w", err)
+	}
+
+	params := db.SettleSupportAdjustmentParams{
+		AdjustmentStatus: pgtype.Text{String: domain.SupportAdjustmentApplied, Valid: true},
+		ID:               utils.ToPgUUID(ticketID),
+	}
+	if event.Error != "" {
+		params.AdjustmentStatus.String = domain.SupportAdjustmentFailed
+		params.AdjustmentError = pgtype.Text{String: event.Error, Valid: true}
+	} else {
+		transactionID, err := uuid.Parse(event.TransactionID)
+		if err != nil {
+			return fmt.Errorf("invalid transaction ID: %w", err)
+		}
+		params.AdjustmentTransactionID = utils.ToPgUUID(transactionID)
+	}
+
+	if _, err := s.repo.SettleSupportAdjustment(ctx, params); err != nil {
+		return fmt.Errorf("failed to record adjustment outcome: %w", err)
+	}
+	return nil
+}
+
+// RunSLAWorker flags tickets that miss their first response or resolution
+// deadline until ctx is cancelled.
+func (s *SupportService) RunSLAWorker(ctx context.Context) {
+	ticker := time.NewTicker(s.checkInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.checkDeadlines(ctx)
+		}
+	}
+}
+
+func (s *SupportService) checkDeadlines(ctx context.Context) {
+	now := pgtype.Timestamp{Time: time.Now().UTC(), Valid: true}
+
+	late, err := s.repo.ListFirstResponseBreaches(ctx, now)
+	if err != nil {
+		log.Printf("Failed to load tickets past their first response deadline: %v", err)
+	}
+	for _, ticket := range late {
+		flagged, err := s.repo.MarkFirstResponseBreached(ctx, db.MarkFirstResponseBreachedParams{BreachedAt: now, ID: ticket.ID})
+		if err != nil {
+			log.Printf("Failed to flag ticket %s: %v", utils.FromPgUUID(ticket.ID), err)
+			continue
+		}
+		if flagged > 0 {
+			s.publishBreach(ctx, ticket, deadlineFirstResponse, ticket.FirstResponseDueAt.Time, now.Time)
+		}
+	}
+
+	overdue, err := s.repo.ListResolutionBreaches(ctx, now)
+	if err != nil {
+		log.Printf("Failed to load tickets past their resolution deadline: %v", err)
+	}
+	for _, ticket := range overdue {
+		flagged, err := s.repo.MarkResolutionBreached(ctx, db.MarkResolutionBreachedParams{BreachedAt: now, ID: ticket.ID})
+		if err != nil {
+			log.Printf("Failed to flag ticket %s: %v", utils.FromPgUUID(ticket.ID), err)
+			continue
+		}
+		if flagged > 0 {
+			s.publishBreach(ctx, ticket, deadlineResolution, ticket.ResolutionDueAt.Time, now.Time)
+		}
+	}
+}
+
+func (s *SupportService) publishBreach(ctx context.Context, ticket db.SupportTicket, deadline string, dueAt, now time.Time) {
+	event := events.SupportSLABreachedEvent{
+		TicketID:  utils.FromPgUUID(ticket.ID).String(),
+		Category:  ticket.Category,
+		Priority:  ticket.Priority,
+		Deadline:  deadline,
+		DueAt:     dueAt,
+		Timestamp: now,
+	}
+	if ticket.AssignedTo.Valid {
+		event.AssignedTo = utils.FromPgUUID(ticket.AssignedTo).String()
+	}
+	s.eventBus.Publish(ctx, events.SubjectSupportSLABreached, event)
+}
+
+func (s *SupportService) SubscribeToEvents() {
+	// payment-service reports whether a refund or fare adjustment was posted
+	s.eventBus.QueueSubscribe(events.SubjectSupportAdjustmentSettled, "support-service", func(ctx context.Context, data []byte) {
+		var event events.SupportAdjustmentSettledEvent
+		if err := json.Unmarshal(data, &event); err != nil {
+			log.Printf("Failed to unmarshal adjustment settled event: %v", err)
+			return
+		}
+
+		if err := s.SettleAdjustment(ctx, event); err != nil {
+			log.Printf("Failed to settle adjustment for ticket %s: %v", event.TicketID, err)
+		}
+	})
+}
+
+func (s *SupportService) getTicket(ctx context.Context, ticketID uuid.UUID) (db.SupportTicket, error) {
+	ticket, err := s.repo.GetSupportTicket(ctx, utils.ToPgUUID(ticketID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.SupportTicket{}, ErrTicketNotFound
+		}
+		return db.SupportTicket{}, fmt.Errorf("failed to get support ticket: %w", err)
+	}
+	return ticket, nil
+}
+
+// visibleTicket returns a ticket the caller may see: their own, a lost
+// item report about their trip, or any ticket for agents. Others' tickets
+// are reported as not found.
+func (s *SupportService) visibleTicket(ctx context.Context, ticketID, userID uuid.UUID, role string) (db.SupportTicket, error) {
+	ticket, err := s.getTicket(ctx, ticketID)
+	if err != nil {
+		return db.SupportTicket{}, err
+	}
+	if isAgent(role) || utils.FromPgUUID(ticket.UserID) == userID ||
+		(ticket.DriverID.Valid && utils.FromPgUUID(ticket.DriverID) == userID) {
+		return ticket, nil
+	}
+	return db.SupportTicket{}, ErrTicketNotFound
+}
+
+func (s *SupportService) getTrip(ctx context.Context, tripID uuid.UUID) (*db.GetSupportTripRow, error) {
+	trip, err := s.repo.GetSupportTrip(ctx, utils.ToPgUUID(tripID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrTripNotFound
+		}
+		return nil, fmt.Errorf("failed to get trip: %w", err)
+	}
+	return &trip, nil
+}
+
+// tripPaid is what the rider paid for a trip: the fare and tip, less any
+// promo discount.
+func tripPaid(trip *db.GetSupportTripRow) money.Money {
+	currency := money.Currency(trip.Currency)
+	fare := money.FromNumeric(trip.ActualFare, currency)
+	return fare.Add(money.FromNumeric(trip.Tip, currency)).Sub(money.FromNumeric(trip.DiscountAmount, currency))
+}
+
+// adjustmentCredit works out what goes back to the rider. A refund is the
+// amount given; a fare adjustment sets a lower fare, and the rider gets back
+// the difference. Either way, with what other tickets already credited, it
+// can't come to more than the rider paid.
+func adjustmentCredit(trip *db.GetSupportTripRow, resolution string, amount float64, credited money.Money) (money.Money, error) {
+	currency := money.Currency(trip.Currency)
+	fare := money.FromNumeric(trip.ActualFare, currency)
+	paid := tripPaid(trip)
+	requested := money.FromFloat(amount, currency)
+
+	var credit money.Money
+	switch resolution {
+	case domain.SupportResolutionRefund:
+		credit = requested
+		if credit.Sign() <= 0 {
+			return money.Money{}, fmt.Errorf("%w: refund amount must be greater than zero", ErrInvalidResolution)
+		}
+	case domain.SupportResolutionFareAdjustment:
+		if requested.Sign() < 0 || requested.Cmp(fare) >= 0 {
+			return money.Money{}, fmt.Errorf("%w: the adjusted fare must be at least zero and below the fare of %s", ErrInvalidResolution, fare)
+		}
+		credit = fare.Sub(requested)
+	}
+	if credit.Cmp(paid) > 0 {
+		return money.Money{}, fmt.Errorf("%w: %s is more than the %s the rider paid", ErrInvalidResolution, credit, paid)
+	}
+	if credit.Add(credited).Cmp(paid) > 0 {
+		return money.Money{}, fmt.Errorf("%w: %s has already been credited on this trip, and the rider paid %s", ErrInvalidResolution, credited, paid)
+	}
+	return credit, nil
+}
+
+// replyRecipients are who's told about a reply: the ticket's user and, on
+// lost item reports, the driver, except whoever wrote it. Users' replies
+// go to the assigned agent.
+func replyRecipients(ticket db.SupportTicket, authorID uuid.UUID, agent bool) []string {
+	var recipients []string
+	for _, id := range []pgtype.UUID{ticket.UserID, ticket.DriverID} {
+		if id.Valid && utils.FromPgUUID(id) != authorID {
+			recipients = append(recipients, utils.FromPgUUID(id).String())
+		}
+	}
+	if !agent && ticket.AssignedTo.Valid && utils.FromPgUUID(ticket.AssignedTo) != authorID {
+		recipients = append(recipients, utils.FromPgUUID(ticket.AssignedTo).String())
+	}
+	return recipients
+}
+
+func isAgent(role string) bool {
+	return role == "admin"
+}
+
+func authorRole(role string) string {
+	switch role {
+	case "admin":
+		return domain.SupportAuthorAgent
+	case "driver":
+		return domain.SupportAuthorDriver
+	default:
+		return domain.SupportAuthorUser
+	}
+}
+
+func toTicketResponses(tickets []db.SupportTicket) []domain.SupportTicketResponse {
+	response := make([]domain.SupportTicketResponse, len(tickets))
+	for i, ticket := range tickets {
+		response[i] = toTicketResponse(ticket)
+	}
+	return response
+}
+
+func toTicketResponse(ticket db.SupportTicket) domain.SupportTicketResponse {
+	response := domain.SupportTicketResponse{
+		ID:                 utils.FromPgUUID(ticket.ID).String(),
+		UserID:             utils.FromPgUUID(ticket.UserID).String(),
+		Category:           ticket.Category,
+		Priority:           ticket.Priority,
+		Status:             ticket.Status,
+		Subject:            ticket.Subject,
+		FirstResponseDueAt: ticket.FirstResponseDueAt.Time,
+		ResolutionDueAt:    ticket.ResolutionDueAt.Time,
+		SLABreached:        ticket.FirstResponseBreachedAt.Valid || ticket.ResolutionBreachedAt.Valid,
+		Resolution:         ticket.Resolution.String,
+		ResolutionNote:     ticket.ResolutionNote.String,
+		AdjustmentStatus:   ticket.AdjustmentStatus.String,
+		AdjustmentError:    ticket.AdjustmentError.String,
+		CreatedAt:          ticket.CreatedAt.Time,
+		UpdatedAt:          ticket.UpdatedAt.Time,
+	}
+	if ticket.TripID.Valid {
+		response.TripID = utils.FromPgUUID(ticket.TripID).String()
+	}
+	if ticket.DriverID.Valid {
+		response.DriverID = utils.FromPgUUID(ticket.DriverID).String()
+	}
+	if ticket.AssignedTo.Valid {
+		response.AssignedTo = utils.FromPgUUID(ticket.AssignedTo).String()
+	}
+	if ticket.FirstRespondedAt.Valid {
+		response.FirstRespondedAt = &ticket.FirstRespondedAt.Time
+	}
+	if ticket.AdjustmentAmount.Valid {
+		amount := utils.NumericToFloat64(ticket.AdjustmentAmount)
+		response.AdjustmentAmount = &amount
+	}
+	if ticket.ResolvedAt.Valid {
+		response.ResolvedAt = &ticket.ResolvedAt.Time
+	}
+	return response
+}
+
+func toMessageResponse(message db.SupportMessage) domain.SupportMessageResponse {
+	response := domain.SupportMessageResponse{
+		ID:         utils.FromPgUUID(message.ID).String(),
+		AuthorRole: message.AuthorRole,
+		Body:       message.Body,
+		Internal:   message.Internal,
+		CreatedAt:  message.CreatedAt.Time,
+	}
+	if message.AuthorID.Valid {
+		response.AuthorID = utils.FromPgUUID(message.AuthorID).String()
+	}
+	return response
+}
+
+func toAttachmentResponse(attachment db.SupportAttachment) domain.SupportAttachmentResponse {
+	response := domain.SupportAttachmentResponse{
+		ID:          utils.FromPgUUID(attachment.ID).String(),
+		FileName:    attachment.FileName,
+		ContentType: attachment.ContentType,
+		SizeBytes:   attachment.SizeBytes,
+		CreatedAt:   attachment.CreatedAt.Time,
+	}
+	if attachment.UploadedBy.Valid {
+		response.UploadedBy = utils.FromPgUUID(attachment.UploadedBy).String()
+	}
+	return response
+}
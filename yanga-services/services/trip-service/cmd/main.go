@@ -20,7 +20,11 @@ import (
 	"github.com/namycodes/yanga-services/services/trip-service/internal/service"
 	"github.com/namycodes/yanga-services/shared-lib/config"
 	"github.com/namycodes/yanga-services/shared-lib/events"
+	"github.com/namycodes/yanga-services/shared-lib/geocoding"
 	"github.com/namycodes/yanga-services/shared-lib/middleware"
+	"github.com/namycodes/yanga-services/shared-lib/moderation"
+	"github.com/namycodes/yanga-services/shared-lib/routing"
+	"github.com/namycodes/yanga-services/shared-lib/telephony"
 )
 
 // @title Trip Service API
@@ -50,16 +54,63 @@ func main() {
 	log.Println("✅ Connected to NATS")
 
 	queries := db.New(dbPool)
-	tripRepo := repository.NewTripRepository(queries)
+	tripRepo := repository.NewTripRepository(dbPool, queries)
 	rideRequestRepo := repository.NewRideRequestRepository(queries)
-	tripService := service.NewTripService(tripRepo, rideRequestRepo, eventBus)
+	cityRepo := repository.NewCityRepository(queries)
+	cityService := service.NewCityService(cityRepo, cfg)
+	promotionRepo := repository.NewPromotionRepository(queries)
+	promotionService := service.NewPromotionService(promotionRepo, tripRepo, cityService, eventBus, cfg)
+	roadRouter := routing.New(cfg)
+	poolService := service.NewPoolService(tripRepo, roadRouter, eventBus, cfg)
+	deliveryService := service.NewDeliveryService(tripRepo, eventBus)
+	placeRepo := repository.NewPlaceRepository(queries)
+	placeService := service.NewPlaceService(placeRepo, geocoding.New(cfg), cfg)
+	geofenceRepo := repository.NewGeofenceRepository(queries)
+	geofenceService := service.NewGeofenceService(geofenceRepo, cityService, eventBus)
+	airportQueueRepo := repository.NewAirportQueueRepository(queries)
+	airportQueueService := service.NewAirportQueueService(airportQueueRepo, geofenceService, eventBus, cfg)
+	tripService := service.NewTripService(tripRepo, rideRequestRepo, promotionService, poolService, deliveryService, placeService, geofenceService, cityService, airportQueueService, roadRouter, eventBus, cfg)
+	scheduledTripService := service.NewScheduledTripService(tripRepo, cityService, airportQueueService, eventBus, cfg)
+	tripStopService := service.NewTripStopService(tripRepo, promotionService, geofenceService, cityService, roadRouter, eventBus)
+	cancellationRepo := repository.NewCancellationRepository(queries)
+	cancellationService := service.NewCancellationService(cancellationRepo, tripRepo, promotionService, poolService, cityService, eventBus)
+	chatRepo := repository.NewChatRepository(queries)
+	chatService := service.NewChatService(chatRepo, tripRepo, moderation.New(cfg), telephony.New(cfg), eventBus, cfg)
+	safetyRepo := repository.NewSafetyRepository(queries)
+	safetyService := service.NewSafetyService(safetyRepo, tripRepo, roadRouter, eventBus, cfg)
 	tripHandler := handler.NewTripHandler(tripService)
+	promotionHandler := handler.NewPromotionHandler(promotionService)
+	cancellationHandler := handler.NewCancellationHandler(cancellationService)
+	scheduledTripHandler := handler.NewScheduledTripHandler(scheduledTripService)
+	tripStopHandler := handler.NewTripStopHandler(tripStopService)
+	poolHandler := handler.NewPoolHandler(poolService)
+	deliveryHandler := handler.NewDeliveryHandler(deliveryService)
+	placeHandler := handler.NewPlaceHandler(placeService)
+	geofenceHandler := handler.NewGeofenceHandler(geofenceService)
+	cityHandler := handler.NewCityHandler(cityService)
+	airportQueueHandler := handler.NewAirportQueueHandler(airportQueueService)
+	chatHandler := handler.NewChatHandler(chatService)
+	safetyHandler := handler.NewSafetyHandler(safetyService)
+
+	tripService.SubscribeToEvents()
+	promotionService.SubscribeToEvents()
+	geofenceService.SubscribeToEvents()
+	airportQueueService.SubscribeToEvents()
+	chatService.SubscribeToEvents()
+	safetyService.SubscribeToEvents()
+
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+	go cancellationService.RunExpiryWorker(workerCtx)
+	go scheduledTripService.RunScheduler(workerCtx)
+	go safetyService.RunAnomalyWorker(workerCtx)
 
 	router := mux.NewRouter()
+	router.Use(middleware.RequestIDMiddleware)
 	router.Use(middleware.LoggingMiddleware)
 	router.Use(middleware.CORSMiddleware)
 
-	routes.SetupTripRoutes(router, tripHandler, config.JWTConfig{Secret: cfg.JWTSecret})
+	routes.SetupTripRoutes(router, tripHandler, promotionHandler, cancellationHandler, scheduledTripHandler, tripStopHandler, poolHandler, deliveryHandler, placeHandler, geofenceHandler, cityHandler, airportQueueHandler, chatHandler, safetyHandler, config.JWTConfig{Secret: cfg.JWTSecret, IdentitySecret: cfg.GatewayIdentitySecret})
 
 	router.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
@@ -87,6 +138,7 @@ func main() {
 	<-quit
 
 	log.Println("🛑 Shutting down server...")
+	stopWorkers()
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
@@ -7,6 +7,7 @@ replace github.com/namycodes/yanga-services/shared-lib => ../../shared-lib
 require (
 	github.com/google/uuid v1.6.0
 	github.com/gorilla/mux v1.8.1
+	github.com/gorilla/websocket v1.5.3
 	github.com/jackc/pgx/v5 v5.5.3
 	github.com/namycodes/yanga-services/shared-lib v0.0.0
 	github.com/swaggo/http-swagger v1.3.4
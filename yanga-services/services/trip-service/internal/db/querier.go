@@ -11,19 +11,154 @@ import (
 )
 
 type Querier interface {
+	AcknowledgeSafetyIncident(ctx context.Context, arg AcknowledgeSafetyIncidentParams) (SafetyIncident, error)
 	AssignDriverToTrip(ctx context.Context, arg AssignDriverToTripParams) error
-	CancelTrip(ctx context.Context, arg CancelTripParams) error
+	CancelTrip(ctx context.Context, arg CancelTripParams) (int64, error)
+	ClaimPromoCodeUse(ctx context.Context, id pgtype.UUID) (int64, error)
+	ClearTripPlannedRoute(ctx context.Context, tripID pgtype.UUID) error
+	CloseTripCallSession(ctx context.Context, id pgtype.UUID) error
+	CompletePoolWaypoint(ctx context.Context, arg CompletePoolWaypointParams) error
 	CompleteTrip(ctx context.Context, arg CompleteTripParams) error
+	// Closes a pool once none of its trips are still active.
+	CompleteTripPoolIfDone(ctx context.Context, id pgtype.UUID) (int64, error)
+	CountActiveServiceAreas(ctx context.Context) (int64, error)
+	CountEmergencyContacts(ctx context.Context, userID pgtype.UUID) (int64, error)
+	CountSavedPlacesByLabel(ctx context.Context, arg CountSavedPlacesByLabelParams) (int64, error)
+	CountUserCompletedTrips(ctx context.Context, userID pgtype.UUID) (int64, error)
+	CountUserPromoRedemptions(ctx context.Context, arg CountUserPromoRedemptionsParams) (int64, error)
+	CreateEmergencyContact(ctx context.Context, arg CreateEmergencyContactParams) (EmergencyContact, error)
+	CreateGeofence(ctx context.Context, arg CreateGeofenceParams) (Geofence, error)
+	CreateParcelDelivery(ctx context.Context, arg CreateParcelDeliveryParams) (ParcelDelivery, error)
+	CreatePoolWaypoint(ctx context.Context, arg CreatePoolWaypointParams) (PoolWaypoint, error)
+	CreatePromoCode(ctx context.Context, arg CreatePromoCodeParams) (PromoCode, error)
+	CreatePromoRedemption(ctx context.Context, arg CreatePromoRedemptionParams) (PromoRedemption, error)
+	CreateReferral(ctx context.Context, arg CreateReferralParams) (Referral, error)
+	CreateReferralCode(ctx context.Context, arg CreateReferralCodeParams) (ReferralCode, error)
+	CreateSafetyIncident(ctx context.Context, arg CreateSafetyIncidentParams) (SafetyIncident, error)
+	CreateSavedPlace(ctx context.Context, arg CreateSavedPlaceParams) (SavedPlace, error)
 	CreateTrip(ctx context.Context, arg CreateTripParams) (Trip, error)
+	CreateTripCallSession(ctx context.Context, arg CreateTripCallSessionParams) (TripCallSession, error)
+	CreateTripMessage(ctx context.Context, arg CreateTripMessageParams) (TripMessage, error)
+	CreateTripPool(ctx context.Context, arg CreateTripPoolParams) (TripPool, error)
+	CreateTripShareLink(ctx context.Context, arg CreateTripShareLinkParams) (TripShareLink, error)
+	CreateTripStop(ctx context.Context, arg CreateTripStopParams) (TripStop, error)
+	DeleteCancellationPolicy(ctx context.Context, cityCode string) (int64, error)
+	DeleteEmergencyContact(ctx context.Context, arg DeleteEmergencyContactParams) (int64, error)
+	DeleteGeofence(ctx context.Context, id pgtype.UUID) (int64, error)
+	DeleteSavedPlace(ctx context.Context, arg DeleteSavedPlaceParams) (int64, error)
+	// Drops a cancelled rider's remaining pickup and dropoff from the route.
+	DeleteTripPoolWaypoints(ctx context.Context, tripID pgtype.UUID) error
+	DeleteTripStop(ctx context.Context, arg DeleteTripStopParams) (int64, error)
+	// A trip reserved in advance goes straight to its driver; otherwise it is
+	// opened up for matching like an immediate trip.
+	DispatchScheduledTrip(ctx context.Context, id pgtype.UUID) (Trip, error)
+	EnterGeofence(ctx context.Context, arg EnterGeofenceParams) (int64, error)
+	ExitGeofence(ctx context.Context, arg ExitGeofenceParams) (int64, error)
+	// Open pools with a driver whose remaining route passes through the given
+	// bounding box.
+	FindOpenTripPools(ctx context.Context, arg FindOpenTripPoolsParams) ([]TripPool, error)
 	GetActiveTrip(ctx context.Context, userID pgtype.UUID) (Trip, error)
+	GetAirportQueuePosition(ctx context.Context, driverID pgtype.UUID) (GetAirportQueuePositionRow, error)
+	GetAutoApplyPromoCodes(ctx context.Context, at pgtype.Timestamp) ([]PromoCode, error)
+	// Drivers with a home city only see trips in it.
+	GetAvailableScheduledTrips(ctx context.Context, arg GetAvailableScheduledTripsParams) ([]Trip, error)
+	// Falls back to the 'default' policy when the city has none.
+	GetCancellationPolicy(ctx context.Context, cityCode string) (CancellationPolicy, error)
+	GetCity(ctx context.Context, code string) (City, error)
 	GetDriverActiveTrip(ctx context.Context, driverID pgtype.UUID) (Trip, error)
+	GetDriverCityCode(ctx context.Context, userID pgtype.UUID) (pgtype.Text, error)
+	GetDriverGeofences(ctx context.Context, driverID pgtype.UUID) ([]Geofence, error)
+	GetDriverLocation(ctx context.Context, userID pgtype.UUID) (GetDriverLocationRow, error)
+	GetDriverReservedTrips(ctx context.Context, reservedDriverID pgtype.UUID) ([]Trip, error)
 	GetDriverTrips(ctx context.Context, arg GetDriverTripsParams) ([]Trip, error)
+	GetDriverVehicleType(ctx context.Context, userID pgtype.UUID) (string, error)
+	// Pending trips nobody accepted within their city's match timeout. Scheduled
+	// trips are timed from dispatch rather than booking.
+	GetExpiredPendingTrips(ctx context.Context, limit int32) ([]Trip, error)
+	GetGeofence(ctx context.Context, id pgtype.UUID) (Geofence, error)
+	// Active geofences whose bounding box holds the point; callers still test
+	// the polygon itself.
+	GetGeofencesAround(ctx context.Context, arg GetGeofencesAroundParams) ([]Geofence, error)
+	GetOpenTripCallSession(ctx context.Context, tripID pgtype.UUID) (TripCallSession, error)
+	GetParcelDelivery(ctx context.Context, tripID pgtype.UUID) (ParcelDelivery, error)
 	GetPendingTrips(ctx context.Context, arg GetPendingTripsParams) ([]GetPendingTripsRow, error)
+	GetPoolTrips(ctx context.Context, poolID pgtype.UUID) ([]Trip, error)
+	GetPoolWaypoints(ctx context.Context, poolID pgtype.UUID) ([]PoolWaypoint, error)
+	GetPromoCode(ctx context.Context, id pgtype.UUID) (PromoCode, error)
+	GetPromoCodeByCode(ctx context.Context, code string) (PromoCode, error)
+	GetPromoRedemptionByTrip(ctx context.Context, tripID pgtype.UUID) (PromoRedemption, error)
+	GetReferralCodeByCode(ctx context.Context, code string) (ReferralCode, error)
+	GetReferralCodeByUser(ctx context.Context, userID pgtype.UUID) (ReferralCode, error)
+	GetReferralStats(ctx context.Context, referrerID pgtype.UUID) (GetReferralStatsRow, error)
+	GetSafetyDriver(ctx context.Context, id pgtype.UUID) (GetSafetyDriverRow, error)
+	GetSafetyIncident(ctx context.Context, id pgtype.UUID) (SafetyIncident, error)
 	GetTrip(ctx context.Context, id pgtype.UUID) (Trip, error)
+	GetTripMessage(ctx context.Context, arg GetTripMessageParams) (TripMessage, error)
+	GetTripMessageByClientID(ctx context.Context, arg GetTripMessageByClientIDParams) (TripMessage, error)
+	GetTripParticipantPhones(ctx context.Context, id pgtype.UUID) (GetTripParticipantPhonesRow, error)
+	GetTripPool(ctx context.Context, id pgtype.UUID) (TripPool, error)
+	GetTripSafetyTrack(ctx context.Context, tripID pgtype.UUID) (TripSafetyTrack, error)
+	GetTripShareLinkByToken(ctx context.Context, tokenHash string) (TripShareLink, error)
+	GetTripStop(ctx context.Context, arg GetTripStopParams) (TripStop, error)
+	GetTripStops(ctx context.Context, tripID pgtype.UUID) ([]TripStop, error)
 	GetTripWithDetails(ctx context.Context, id pgtype.UUID) (GetTripWithDetailsRow, error)
+	GetTripsDueForDispatch(ctx context.Context, arg GetTripsDueForDispatchParams) ([]Trip, error)
+	GetTripsDueForReminder(ctx context.Context, arg GetTripsDueForReminderParams) ([]Trip, error)
+	GetUnresolvedSafetyIncident(ctx context.Context, arg GetUnresolvedSafetyIncidentParams) (SafetyIncident, error)
+	GetUserScheduledTrips(ctx context.Context, arg GetUserScheduledTripsParams) ([]Trip, error)
 	GetUserTrips(ctx context.Context, arg GetUserTripsParams) ([]Trip, error)
+	IncrementParcelPinAttempts(ctx context.Context, tripID pgtype.UUID) (int32, error)
+	// A driver moving between staging lots of the same airport keeps their place.
+	JoinAirportQueue(ctx context.Context, arg JoinAirportQueueParams) (int64, error)
+	LeaveAirportQueue(ctx context.Context, driverID pgtype.UUID) (int64, error)
+	LeaveStagingArea(ctx context.Context, arg LeaveStagingAreaParams) (int64, error)
+	ListAirportQueue(ctx context.Context, arg ListAirportQueueParams) ([]AirportQueueEntry, error)
+	ListCancellationPolicies(ctx context.Context) ([]CancellationPolicy, error)
+	ListCities(ctx context.Context) ([]City, error)
+	ListEmergencyContacts(ctx context.Context, userID pgtype.UUID) ([]EmergencyContact, error)
+	ListGeofences(ctx context.Context, arg ListGeofencesParams) ([]Geofence, error)
+	// Filtering by city includes the codes valid in every city.
+	ListPromoCodes(ctx context.Context, arg ListPromoCodesParams) ([]PromoCode, error)
+	ListSafetyIncidents(ctx context.Context, arg ListSafetyIncidentsParams) ([]SafetyIncident, error)
+	ListSavedPlaces(ctx context.Context, userID pgtype.UUID) ([]SavedPlace, error)
+	// Trips under way with where their driver was last seen moving.
+	ListTrackedTrips(ctx context.Context) ([]ListTrackedTripsRow, error)
+	ListTripMessages(ctx context.Context, arg ListTripMessagesParams) ([]TripMessage, error)
+	// Serialises riders joining the same pool.
+	LockTripPool(ctx context.Context, id pgtype.UUID) (TripPool, error)
+	MarkDriverArrived(ctx context.Context, id pgtype.UUID) (int64, error)
+	MarkReferralRewarded(ctx context.Context, arg MarkReferralRewardedParams) (Referral, error)
+	// Marks read everything the other party sent up to and including a message.
+	MarkTripMessagesRead(ctx context.Context, arg MarkTripMessagesReadParams) (int64, error)
+	MarkTripReminderSent(ctx context.Context, id pgtype.UUID) (int64, error)
+	MarkTripStopArrived(ctx context.Context, arg MarkTripStopArrivedParams) (int64, error)
+	RecordParcelDelivery(ctx context.Context, arg RecordParcelDeliveryParams) (int64, error)
+	RecordParcelPickup(ctx context.Context, arg RecordParcelPickupParams) (int64, error)
+	RedeemPromoRedemption(ctx context.Context, arg RedeemPromoRedemptionParams) (PromoRedemption, error)
+	ReleasePromoCodeUse(ctx context.Context, id pgtype.UUID) error
+	ReleasePromoRedemption(ctx context.Context, tripID pgtype.UUID) (PromoRedemption, error)
+	ReleaseScheduledTrip(ctx context.Context, arg ReleaseScheduledTripParams) (int64, error)
+	ReserveScheduledTrip(ctx context.Context, arg ReserveScheduledTripParams) (int64, error)
+	ResolveSafetyIncident(ctx context.Context, arg ResolveSafetyIncidentParams) (SafetyIncident, error)
+	RevokeTripShareLink(ctx context.Context, arg RevokeTripShareLinkParams) (int64, error)
+	SaveTripSafetyTrack(ctx context.Context, arg SaveTripSafetyTrackParams) error
+	SetTripPool(ctx context.Context, arg SetTripPoolParams) (Trip, error)
+	SetTripPoolDriver(ctx context.Context, arg SetTripPoolDriverParams) (int64, error)
+	// Makes room for a stop inserted at stop_order.
+	ShiftTripStopsBack(ctx context.Context, arg ShiftTripStopsBackParams) error
+	// Closes the gap left by a stop removed from stop_order.
+	ShiftTripStopsForward(ctx context.Context, arg ShiftTripStopsForwardParams) error
 	StartTrip(ctx context.Context, id pgtype.UUID) error
+	UpdateGeofence(ctx context.Context, arg UpdateGeofenceParams) (Geofence, error)
+	UpdatePoolWaypointSequence(ctx context.Context, arg UpdatePoolWaypointSequenceParams) error
+	UpdatePromoCodeStatus(ctx context.Context, arg UpdatePromoCodeStatusParams) (PromoCode, error)
+	UpdateTripPaymentStatus(ctx context.Context, arg UpdateTripPaymentStatusParams) error
+	UpdateTripRoute(ctx context.Context, arg UpdateTripRouteParams) error
 	UpdateTripStatus(ctx context.Context, arg UpdateTripStatusParams) error
+	UpsertCancellationPolicy(ctx context.Context, arg UpsertCancellationPolicyParams) (CancellationPolicy, error)
+	UpsertCity(ctx context.Context, arg UpsertCityParams) (City, error)
+	// Replaces the user's home or work place.
+	UpsertSavedPlace(ctx context.Context, arg UpsertSavedPlaceParams) (SavedPlace, error)
 }
 
 var _ Querier = (*Queries)(nil)
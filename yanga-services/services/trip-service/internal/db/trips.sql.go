@@ -13,8 +13,8 @@ import (
 
 const assignDriverToTrip = `-- name: AssignDriverToTrip :exec
 UPDATE trips
-SET driver_id = $2, status = 'accepted', updated_at = CURRENT_TIMESTAMP
-WHERE id = $1
+SET driver_id = $2, status = 'accepted', accepted_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND status = 'pending'
 `
 
 type AssignDriverToTripParams struct {
@@ -27,24 +27,39 @@ func (q *Queries) AssignDriverToTrip(ctx context.Context, arg AssignDriverToTrip
 	return err
 }
 
-const cancelTrip = `-- name: CancelTrip :exec
+const cancelTrip = `-- name: CancelTrip :execrows
 UPDATE trips
 SET 
     status = 'cancelled',
     cancelled_at = CURRENT_TIMESTAMP,
     cancellation_reason = $2,
+    cancelled_by = $3,
+    no_show_party = $4,
+    cancellation_fee = $5,
     updated_at = CURRENT_TIMESTAMP
-WHERE id = $1
+WHERE id = $1 AND status IN ('scheduled', 'pending', 'accepted')
 `
 
 type CancelTripParams struct {
-	ID                 pgtype.UUID `json:"id"`
-	CancellationReason pgtype.Text `json:"cancellation_reason"`
+	ID                 pgtype.UUID    `json:"id"`
+	CancellationReason pgtype.Text    `json:"cancellation_reason"`
+	CancelledBy        pgtype.Text    `json:"cancelled_by"`
+	NoShowParty        pgtype.Text    `json:"no_show_party"`
+	CancellationFee    pgtype.Numeric `json:"cancellation_fee"`
 }
 
-func (q *Queries) CancelTrip(ctx context.Context, arg CancelTripParams) error {
-	_, err := q.db.Exec(ctx, cancelTrip, arg.ID, arg.CancellationReason)
-	return err
+func (q *Queries) CancelTrip(ctx context.Context, arg CancelTripParams) (int64, error) {
+	result, err := q.db.Exec(ctx, cancelTrip,
+		arg.ID,
+		arg.CancellationReason,
+		arg.CancelledBy,
+		arg.NoShowParty,
+		arg.CancellationFee,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
 }
 
 const completeTrip = `-- name: CompleteTrip :exec
@@ -55,6 +70,8 @@ SET
     actual_fare = $2,
     actual_duration = $3,
     payment_status = $4,
+    tip = $5,
+    discount_amount = $6,
     updated_at = CURRENT_TIMESTAMP
 WHERE id = $1
 `
@@ -64,6 +81,8 @@ type CompleteTripParams struct {
 	ActualFare     pgtype.Numeric `json:"actual_fare"`
 	ActualDuration pgtype.Int4    `json:"actual_duration"`
 	PaymentStatus  pgtype.Text    `json:"payment_status"`
+	Tip            pgtype.Numeric `json:"tip"`
+	DiscountAmount pgtype.Numeric `json:"discount_amount"`
 }
 
 func (q *Queries) CompleteTrip(ctx context.Context, arg CompleteTripParams) error {
@@ -72,10 +91,24 @@ func (q *Queries) CompleteTrip(ctx context.Context, arg CompleteTripParams) erro
 		arg.ActualFare,
 		arg.ActualDuration,
 		arg.PaymentStatus,
+		arg.Tip,
+		arg.DiscountAmount,
 	)
 	return err
 }
 
+const countUserCompletedTrips = `-- name: CountUserCompletedTrips :one
+SELECT COUNT(*) FROM trips
+WHERE user_id = $1 AND status = 'completed'
+`
+
+func (q *Queries) CountUserCompletedTrips(ctx context.Context, userID pgtype.UUID) (int64, error) {
+	row := q.db.QueryRow(ctx, countUserCompletedTrips, userID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createTrip = `-- name: CreateTrip :one
 INSERT INTO trips (
     user_id,
@@ -87,23 +120,45 @@ INSERT INTO trips (
     dropoff_address,
     estimated_fare,
     estimated_duration,
-    distance
+    distance,
+    payment_method,
+    vehicle_type,
+    subtotal_fare,
+    discount_amount,
+    promo_code,
+    status,
+    pickup_at,
+    trip_type,
+    city_code,
+    zone_fee,
+    currency
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10
-) RETURNING id, user_id, driver_id, pickup_latitude, pickup_longitude, pickup_address, dropoff_latitude, dropoff_longitude, dropoff_address, estimated_fare, actual_fare, estimated_duration, actual_duration, distance, status, payment_status, payment_method, started_at, completed_at, cancelled_at, cancellation_reason, created_at, updated_at
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21
+) RETURNING id, user_id, driver_id, pickup_latitude, pickup_longitude, pickup_address, dropoff_latitude, dropoff_longitude, dropoff_address, estimated_fare, actual_fare, estimated_duration, actual_duration, distance, status, payment_status, payment_method, started_at, completed_at, cancelled_at, cancellation_reason, created_at, updated_at, tip, vehicle_type, subtotal_fare, discount_amount, promo_code, city_code, accepted_at, arrived_at, cancelled_by, no_show_party, cancellation_fee, pickup_at, dispatched_at, reminder_sent_at, reserved_driver_id, reserved_at, pool_id, seat_count, trip_type, zone_fee, currency
 `
 
 type CreateTripParams struct {
-	UserID            pgtype.UUID    `json:"user_id"`
-	PickupLatitude    pgtype.Numeric `json:"pickup_latitude"`
-	PickupLongitude   pgtype.Numeric `json:"pickup_longitude"`
-	PickupAddress     string         `json:"pickup_address"`
-	DropoffLatitude   pgtype.Numeric `json:"dropoff_latitude"`
-	DropoffLongitude  pgtype.Numeric `json:"dropoff_longitude"`
-	DropoffAddress    string         `json:"dropoff_address"`
-	EstimatedFare     pgtype.Numeric `json:"estimated_fare"`
-	EstimatedDuration pgtype.Int4    `json:"estimated_duration"`
-	Distance          pgtype.Numeric `json:"distance"`
+	UserID            pgtype.UUID      `json:"user_id"`
+	PickupLatitude    pgtype.Numeric   `json:"pickup_latitude"`
+	PickupLongitude   pgtype.Numeric   `json:"pickup_longitude"`
+	PickupAddress     string           `json:"pickup_address"`
+	DropoffLatitude   pgtype.Numeric   `json:"dropoff_latitude"`
+	DropoffLongitude  pgtype.Numeric   `json:"dropoff_longitude"`
+	DropoffAddress    string           `json:"dropoff_address"`
+	EstimatedFare     pgtype.Numeric   `json:"estimated_fare"`
+	EstimatedDuration pgtype.Int4      `json:"estimated_duration"`
+	Distance          pgtype.Numeric   `json:"distance"`
+	PaymentMethod     pgtype.Text      `json:"payment_method"`
+	VehicleType       pgtype.Text      `json:"vehicle_type"`
+	SubtotalFare      pgtype.Numeric   `json:"subtotal_fare"`
+	DiscountAmount    pgtype.Numeric   `json:"discount_amount"`
+	PromoCode         pgtype.Text      `json:"promo_code"`
+	Status            string           `json:"status"`
+	PickupAt          pgtype.Timestamp `json:"pickup_at"`
+	TripType          string           `json:"trip_type"`
+	CityCode          pgtype.Text      `json:"city_code"`
+	ZoneFee           pgtype.Numeric   `json:"zone_fee"`
+	Currency          string           `json:"currency"`
 }
 
 func (q *Queries) CreateTrip(ctx context.Context, arg CreateTripParams) (Trip, error) {
@@ -118,6 +173,17 @@ func (q *Queries) CreateTrip(ctx context.Context, arg CreateTripParams) (Trip, e
 		arg.EstimatedFare,
 		arg.EstimatedDuration,
 		arg.Distance,
+		arg.PaymentMethod,
+		arg.VehicleType,
+		arg.SubtotalFare,
+		arg.DiscountAmount,
+		arg.PromoCode,
+		arg.Status,
+		arg.PickupAt,
+		arg.TripType,
+		arg.CityCode,
+		arg.ZoneFee,
+		arg.Currency,
 	)
 	var i Trip
 	err := row.Scan(
@@ -144,12 +210,33 @@ func (q *Queries) CreateTrip(ctx context.Context, arg CreateTripParams) (Trip, e
 		&i.CancellationReason,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Tip,
+		&i.VehicleType,
+		&i.SubtotalFare,
+		&i.DiscountAmount,
+		&i.PromoCode,
+		&i.CityCode,
+		&i.AcceptedAt,
+		&i.ArrivedAt,
+		&i.CancelledBy,
+		&i.NoShowParty,
+		&i.CancellationFee,
+		&i.PickupAt,
+		&i.DispatchedAt,
+		&i.ReminderSentAt,
+		&i.ReservedDriverID,
+		&i.ReservedAt,
+		&i.PoolID,
+		&i.SeatCount,
+		&i.TripType,
+		&i.ZoneFee,
+		&i.Currency,
 	)
 	return i, err
 }
 
 const getActiveTrip = `-- name: GetActiveTrip :one
-SELECT id, user_id, driver_id, pickup_latitude, pickup_longitude, pickup_address, dropoff_latitude, dropoff_longitude, dropoff_address, estimated_fare, actual_fare, estimated_duration, actual_duration, distance, status, payment_status, payment_method, started_at, completed_at, cancelled_at, cancellation_reason, created_at, updated_at FROM trips
+SELECT id, user_id, driver_id, pickup_latitude, pickup_longitude, pickup_address, dropoff_latitude, dropoff_longitude, dropoff_address, estimated_fare, actual_fare, estimated_duration, actual_duration, distance, status, payment_status, payment_method, started_at, completed_at, cancelled_at, cancellation_reason, created_at, updated_at, tip, vehicle_type, subtotal_fare, discount_amount, promo_code, city_code, accepted_at, arrived_at, cancelled_by, no_show_party, cancellation_fee, pickup_at, dispatched_at, reminder_sent_at, reserved_driver_id, reserved_at, pool_id, seat_count, trip_type, zone_fee, currency FROM trips
 WHERE user_id = $1 AND status IN ('pending', 'accepted', 'in_progress')
 ORDER BY created_at DESC
 LIMIT 1
@@ -182,12 +269,33 @@ func (q *Queries) GetActiveTrip(ctx context.Context, userID pgtype.UUID) (Trip,
 		&i.CancellationReason,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Tip,
+		&i.VehicleType,
+		&i.SubtotalFare,
+		&i.DiscountAmount,
+		&i.PromoCode,
+		&i.CityCode,
+		&i.AcceptedAt,
+		&i.ArrivedAt,
+		&i.CancelledBy,
+		&i.NoShowParty,
+		&i.CancellationFee,
+		&i.PickupAt,
+		&i.DispatchedAt,
+		&i.ReminderSentAt,
+		&i.ReservedDriverID,
+		&i.ReservedAt,
+		&i.PoolID,
+		&i.SeatCount,
+		&i.TripType,
+		&i.ZoneFee,
+		&i.Currency,
 	)
 	return i, err
 }
 
 const getDriverActiveTrip = `-- name: GetDriverActiveTrip :one
-SELECT id, user_id, driver_id, pickup_latitude, pickup_longitude, pickup_address, dropoff_latitude, dropoff_longitude, dropoff_address, estimated_fare, actual_fare, estimated_duration, actual_duration, distance, status, payment_status, payment_method, started_at, completed_at, cancelled_at, cancellation_reason, created_at, updated_at FROM trips
+SELECT id, user_id, driver_id, pickup_latitude, pickup_longitude, pickup_address, dropoff_latitude, dropoff_longitude, dropoff_address, estimated_fare, actual_fare, estimated_duration, actual_duration, distance, status, payment_status, payment_method, started_at, completed_at, cancelled_at, cancellation_reason, created_at, updated_at, tip, vehicle_type, subtotal_fare, discount_amount, promo_code, city_code, accepted_at, arrived_at, cancelled_by, no_show_party, cancellation_fee, pickup_at, dispatched_at, reminder_sent_at, reserved_driver_id, reserved_at, pool_id, seat_count, trip_type, zone_fee, currency FROM trips
 WHERE driver_id = $1 AND status IN ('accepted', 'in_progress')
 ORDER BY created_at DESC
 LIMIT 1
@@ -220,12 +328,33 @@ func (q *Queries) GetDriverActiveTrip(ctx context.Context, driverID pgtype.UUID)
 		&i.CancellationReason,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Tip,
+		&i.VehicleType,
+		&i.SubtotalFare,
+		&i.DiscountAmount,
+		&i.PromoCode,
+		&i.CityCode,
+		&i.AcceptedAt,
+		&i.ArrivedAt,
+		&i.CancelledBy,
+		&i.NoShowParty,
+		&i.CancellationFee,
+		&i.PickupAt,
+		&i.DispatchedAt,
+		&i.ReminderSentAt,
+		&i.ReservedDriverID,
+		&i.ReservedAt,
+		&i.PoolID,
+		&i.SeatCount,
+		&i.TripType,
+		&i.ZoneFee,
+		&i.Currency,
 	)
 	return i, err
 }
 
 const getDriverTrips = `-- name: GetDriverTrips :many
-SELECT id, user_id, driver_id, pickup_latitude, pickup_longitude, pickup_address, dropoff_latitude, dropoff_longitude, dropoff_address, estimated_fare, actual_fare, estimated_duration, actual_duration, distance, status, payment_status, payment_method, started_at, completed_at, cancelled_at, cancellation_reason, created_at, updated_at FROM trips
+SELECT id, user_id, driver_id, pickup_latitude, pickup_longitude, pickup_address, dropoff_latitude, dropoff_longitude, dropoff_address, estimated_fare, actual_fare, estimated_duration, actual_duration, distance, status, payment_status, payment_method, started_at, completed_at, cancelled_at, cancellation_reason, created_at, updated_at, tip, vehicle_type, subtotal_fare, discount_amount, promo_code, city_code, accepted_at, arrived_at, cancelled_by, no_show_party, cancellation_fee, pickup_at, dispatched_at, reminder_sent_at, reserved_driver_id, reserved_at, pool_id, seat_count, trip_type, zone_fee, currency FROM trips
 WHERE driver_id = $1
 ORDER BY created_at DESC
 LIMIT $2 OFFSET $3
@@ -270,6 +399,27 @@ func (q *Queries) GetDriverTrips(ctx context.Context, arg GetDriverTripsParams)
 			&i.CancellationReason,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Tip,
+			&i.VehicleType,
+			&i.SubtotalFare,
+			&i.DiscountAmount,
+			&i.PromoCode,
+			&i.CityCode,
+			&i.AcceptedAt,
+			&i.ArrivedAt,
+			&i.CancelledBy,
+			&i.NoShowParty,
+			&i.CancellationFee,
+			&i.PickupAt,
+			&i.DispatchedAt,
+			&i.ReminderSentAt,
+			&i.ReservedDriverID,
+			&i.ReservedAt,
+			&i.PoolID,
+			&i.SeatCount,
+			&i.TripType,
+			&i.ZoneFee,
+			&i.Currency,
 		); err != nil {
 			return nil, err
 		}
@@ -282,7 +432,7 @@ func (q *Queries) GetDriverTrips(ctx context.Context, arg GetDriverTripsParams)
 }
 
 const getPendingTrips = `-- name: GetPendingTrips :many
-SELECT t.id, t.user_id, t.driver_id, t.pickup_latitude, t.pickup_longitude, t.pickup_address, t.dropoff_latitude, t.dropoff_longitude, t.dropoff_address, t.estimated_fare, t.actual_fare, t.estimated_duration, t.actual_duration, t.distance, t.status, t.payment_status, t.payment_method, t.started_at, t.completed_at, t.cancelled_at, t.cancellation_reason, t.created_at, t.updated_at, u.full_name, u.phone_number, u.profile_image_url
+SELECT t.id, t.user_id, t.driver_id, t.pickup_latitude, t.pickup_longitude, t.pickup_address, t.dropoff_latitude, t.dropoff_longitude, t.dropoff_address, t.estimated_fare, t.actual_fare, t.estimated_duration, t.actual_duration, t.distance, t.status, t.payment_status, t.payment_method, t.started_at, t.completed_at, t.cancelled_at, t.cancellation_reason, t.created_at, t.updated_at, t.tip, t.vehicle_type, t.subtotal_fare, t.discount_amount, t.promo_code, t.city_code, t.accepted_at, t.arrived_at, t.cancelled_by, t.no_show_party, t.cancellation_fee, t.pickup_at, t.dispatched_at, t.reminder_sent_at, t.reserved_driver_id, t.reserved_at, t.pool_id, t.seat_count, t.trip_type, t.zone_fee, t.currency, u.full_name, u.phone_number, u.profile_image_url
 FROM trips t
 JOIN users u ON t.user_id = u.id
 WHERE t.status = 'pending'
@@ -319,6 +469,27 @@ type GetPendingTripsRow struct {
 	CancellationReason pgtype.Text      `json:"cancellation_reason"`
 	CreatedAt          pgtype.Timestamp `json:"created_at"`
 	UpdatedAt          pgtype.Timestamp `json:"updated_at"`
+	Tip                pgtype.Numeric   `json:"tip"`
+	VehicleType        pgtype.Text      `json:"vehicle_type"`
+	SubtotalFare       pgtype.Numeric   `json:"subtotal_fare"`
+	DiscountAmount     pgtype.Numeric   `json:"discount_amount"`
+	PromoCode          pgtype.Text      `json:"promo_code"`
+	CityCode           pgtype.Text      `json:"city_code"`
+	AcceptedAt         pgtype.Timestamp `json:"accepted_at"`
+	ArrivedAt          pgtype.Timestamp `json:"arrived_at"`
+	CancelledBy        pgtype.Text      `json:"cancelled_by"`
+	NoShowParty        pgtype.Text      `json:"no_show_party"`
+	CancellationFee    pgtype.Numeric   `json:"cancellation_fee"`
+	PickupAt           pgtype.Timestamp `json:"pickup_at"`
+	DispatchedAt       pgtype.Timestamp `json:"dispatched_at"`
+	ReminderSentAt     pgtype.Timestamp `json:"reminder_sent_at"`
+	ReservedDriverID   pgtype.UUID      `json:"reserved_driver_id"`
+	ReservedAt         pgtype.Timestamp `json:"reserved_at"`
+	PoolID             pgtype.UUID      `json:"pool_id"`
+	SeatCount          int32            `json:"seat_count"`
+	TripType           string           `json:"trip_type"`
+	ZoneFee            pgtype.Numeric   `json:"zone_fee"`
+	Currency           string           `json:"currency"`
 	FullName           string           `json:"full_name"`
 	PhoneNumber        string           `json:"phone_number"`
 	ProfileImageUrl    pgtype.Text      `json:"profile_image_url"`
@@ -357,6 +528,27 @@ func (q *Queries) GetPendingTrips(ctx context.Context, arg GetPendingTripsParams
 			&i.CancellationReason,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Tip,
+			&i.VehicleType,
+			&i.SubtotalFare,
+			&i.DiscountAmount,
+			&i.PromoCode,
+			&i.CityCode,
+			&i.AcceptedAt,
+			&i.ArrivedAt,
+			&i.CancelledBy,
+			&i.NoShowParty,
+			&i.CancellationFee,
+			&i.PickupAt,
+			&i.DispatchedAt,
+			&i.ReminderSentAt,
+			&i.ReservedDriverID,
+			&i.ReservedAt,
+			&i.PoolID,
+			&i.SeatCount,
+			&i.TripType,
+			&i.ZoneFee,
+			&i.Currency,
 			&i.FullName,
 			&i.PhoneNumber,
 			&i.ProfileImageUrl,
@@ -372,7 +564,7 @@ func (q *Queries) GetPendingTrips(ctx context.Context, arg GetPendingTripsParams
 }
 
 const getTrip = `-- name: GetTrip :one
-SELECT id, user_id, driver_id, pickup_latitude, pickup_longitude, pickup_address, dropoff_latitude, dropoff_longitude, dropoff_address, estimated_fare, actual_fare, estimated_duration, actual_duration, distance, status, payment_status, payment_method, started_at, completed_at, cancelled_at, cancellation_reason, created_at, updated_at FROM trips
+SELECT id, user_id, driver_id, pickup_latitude, pickup_longitude, pickup_address, dropoff_latitude, dropoff_longitude, dropoff_address, estimated_fare, actual_fare, estimated_duration, actual_duration, distance, status, payment_status, payment_method, started_at, completed_at, cancelled_at, cancellation_reason, created_at, updated_at, tip, vehicle_type, subtotal_fare, discount_amount, promo_code, city_code, accepted_at, arrived_at, cancelled_by, no_show_party, cancellation_fee, pickup_at, dispatched_at, reminder_sent_at, reserved_driver_id, reserved_at, pool_id, seat_count, trip_type, zone_fee, currency FROM trips
 WHERE id = $1 LIMIT 1
 `
 
@@ -403,13 +595,34 @@ func (q *Queries) GetTrip(ctx context.Context, id pgtype.UUID) (Trip, error) {
 		&i.CancellationReason,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Tip,
+		&i.VehicleType,
+		&i.SubtotalFare,
+		&i.DiscountAmount,
+		&i.PromoCode,
+		&i.CityCode,
+		&i.AcceptedAt,
+		&i.ArrivedAt,
+		&i.CancelledBy,
+		&i.NoShowParty,
+		&i.CancellationFee,
+		&i.PickupAt,
+		&i.DispatchedAt,
+		&i.ReminderSentAt,
+		&i.ReservedDriverID,
+		&i.ReservedAt,
+		&i.PoolID,
+		&i.SeatCount,
+		&i.TripType,
+		&i.ZoneFee,
+		&i.Currency,
 	)
 	return i, err
 }
 
 const getTripWithDetails = `-- name: GetTripWithDetails :one
 SELECT 
-    t.id, t.user_id, t.driver_id, t.pickup_latitude, t.pickup_longitude, t.pickup_address, t.dropoff_latitude, t.dropoff_longitude, t.dropoff_address, t.estimated_fare, t.actual_fare, t.estimated_duration, t.actual_duration, t.distance, t.status, t.payment_status, t.payment_method, t.started_at, t.completed_at, t.cancelled_at, t.cancellation_reason, t.created_at, t.updated_at,
+    t.id, t.user_id, t.driver_id, t.pickup_latitude, t.pickup_longitude, t.pickup_address, t.dropoff_latitude, t.dropoff_longitude, t.dropoff_address, t.estimated_fare, t.actual_fare, t.estimated_duration, t.actual_duration, t.distance, t.status, t.payment_status, t.payment_method, t.started_at, t.completed_at, t.cancelled_at, t.cancellation_reason, t.created_at, t.updated_at, t.tip, t.vehicle_type, t.subtotal_fare, t.discount_amount, t.promo_code, t.city_code, t.accepted_at, t.arrived_at, t.cancelled_by, t.no_show_party, t.cancellation_fee, t.pickup_at, t.dispatched_at, t.reminder_sent_at, t.reserved_driver_id, t.reserved_at, t.pool_id, t.seat_count, t.trip_type, t.zone_fee, t.currency,
     u.full_name as user_name,
     u.phone_number as user_phone,
     u.profile_image_url as user_image,
@@ -447,6 +660,27 @@ type GetTripWithDetailsRow struct {
 	CancellationReason pgtype.Text      `json:"cancellation_reason"`
 	CreatedAt          pgtype.Timestamp `json:"created_at"`
 	UpdatedAt          pgtype.Timestamp `json:"updated_at"`
+	Tip                pgtype.Numeric   `json:"tip"`
+	VehicleType        pgtype.Text      `json:"vehicle_type"`
+	SubtotalFare       pgtype.Numeric   `json:"subtotal_fare"`
+	DiscountAmount     pgtype.Numeric   `json:"discount_amount"`
+	PromoCode          pgtype.Text      `json:"promo_code"`
+	CityCode           pgtype.Text      `json:"city_code"`
+	AcceptedAt         pgtype.Timestamp `json:"accepted_at"`
+	ArrivedAt          pgtype.Timestamp `json:"arrived_at"`
+	CancelledBy        pgtype.Text      `json:"cancelled_by"`
+	NoShowParty        pgtype.Text      `json:"no_show_party"`
+	CancellationFee    pgtype.Numeric   `json:"cancellation_fee"`
+	PickupAt           pgtype.Timestamp `json:"pickup_at"`
+	DispatchedAt       pgtype.Timestamp `json:"dispatched_at"`
+	ReminderSentAt     pgtype.Timestamp `json:"reminder_sent_at"`
+	ReservedDriverID   pgtype.UUID      `json:"reserved_driver_id"`
+	ReservedAt         pgtype.Timestamp `json:"reserved_at"`
+	PoolID             pgtype.UUID      `json:"pool_id"`
+	SeatCount          int32            `json:"seat_count"`
+	TripType           string           `json:"trip_type"`
+	ZoneFee            pgtype.Numeric   `json:"zone_fee"`
+	Currency           string           `json:"currency"`
 	UserName           string           `json:"user_name"`
 	UserPhone          string           `json:"user_phone"`
 	UserImage          pgtype.Text      `json:"user_image"`
@@ -482,6 +716,27 @@ func (q *Queries) GetTripWithDetails(ctx context.Context, id pgtype.UUID) (GetTr
 		&i.CancellationReason,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Tip,
+		&i.VehicleType,
+		&i.SubtotalFare,
+		&i.DiscountAmount,
+		&i.PromoCode,
+		&i.CityCode,
+		&i.AcceptedAt,
+		&i.ArrivedAt,
+		&i.CancelledBy,
+		&i.NoShowParty,
+		&i.CancellationFee,
+		&i.PickupAt,
+		&i.DispatchedAt,
+		&i.ReminderSentAt,
+		&i.ReservedDriverID,
+		&i.ReservedAt,
+		&i.PoolID,
+		&i.SeatCount,
+		&i.TripType,
+		&i.ZoneFee,
+		&i.Currency,
 		&i.UserName,
 		&i.UserPhone,
 		&i.UserImage,
@@ -493,7 +748,7 @@ func (q *Queries) GetTripWithDetails(ctx context.Context, id pgtype.UUID) (GetTr
 }
 
 const getUserTrips = `-- name: GetUserTrips :many
-SELECT id, user_id, driver_id, pickup_latitude, pickup_longitude, pickup_address, dropoff_latitude, dropoff_longitude, dropoff_address, estimated_fare, actual_fare, estimated_duration, actual_duration, distance, status, payment_status, payment_method, started_at, completed_at, cancelled_at, cancellation_reason, created_at, updated_at FROM trips
+SELECT id, user_id, driver_id, pickup_latitude, pickup_longitude, pickup_address, dropoff_latitude, dropoff_longitude, dropoff_address, estimated_fare, actual_fare, estimated_duration, actual_duration, distance, status, payment_status, payment_method, started_at, completed_at, cancelled_at, cancellation_reason, created_at, updated_at, tip, vehicle_type, subtotal_fare, discount_amount, promo_code, city_code, accepted_at, arrived_at, cancelled_by, no_show_party, cancellation_fee, pickup_at, dispatched_at, reminder_sent_at, reserved_driver_id, reserved_at, pool_id, seat_count, trip_type, zone_fee, currency FROM trips
 WHERE user_id = $1
 ORDER BY created_at DESC
 LIMIT $2 OFFSET $3
@@ -538,6 +793,27 @@ func (q *Queries) GetUserTrips(ctx context.Context, arg GetUserTripsParams) ([]T
 			&i.CancellationReason,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Tip,
+			&i.VehicleType,
+			&i.SubtotalFare,
+			&i.DiscountAmount,
+			&i.PromoCode,
+			&i.CityCode,
+			&i.AcceptedAt,
+			&i.ArrivedAt,
+			&i.CancelledBy,
+			&i.NoShowParty,
+			&i.CancellationFee,
+			&i.PickupAt,
+			&i.DispatchedAt,
+			&i.ReminderSentAt,
+			&i.ReservedDriverID,
+			&i.ReservedAt,
+			&i.PoolID,
+			&i.SeatCount,
+			&i.TripType,
+			&i.ZoneFee,
+			&i.Currency,
 		); err != nil {
 			return nil, err
 		}
@@ -549,6 +825,20 @@ func (q *Queries) GetUserTrips(ctx context.Context, arg GetUserTripsParams) ([]T
 	return items, nil
 }
 
+const markDriverArrived = `-- name: MarkDriverArrived :execrows
+UPDATE trips
+SET arrived_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND status = 'accepted' AND arrived_at IS NULL
+`
+
+func (q *Queries) MarkDriverArrived(ctx context.Context, id pgtype.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, markDriverArrived, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const startTrip = `-- name: StartTrip :exec
 UPDATE trips
 SET 
@@ -563,6 +853,22 @@ func (q *Queries) StartTrip(ctx context.Context, id pgtype.UUID) error {
 	return err
 }
 
+const updateTripPaymentStatus = `-- name: UpdateTripPaymentStatus :exec
+UPDATE trips
+SET payment_status = $2, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+`
+
+type UpdateTripPaymentStatusParams struct {
+	ID            pgtype.UUID `json:"id"`
+	PaymentStatus pgtype.Text `json:"payment_status"`
+}
+
+func (q *Queries) UpdateTripPaymentStatus(ctx context.Context, arg UpdateTripPaymentStatusParams) error {
+	_, err := q.db.Exec(ctx, updateTripPaymentStatus, arg.ID, arg.PaymentStatus)
+	return err
+}
+
 const updateTripStatus = `-- name: UpdateTripStatus :exec
 UPDATE trips
 SET status = $2, updated_at = CURRENT_TIMESTAMP
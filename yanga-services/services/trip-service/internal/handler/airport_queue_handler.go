@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/namycodes/yanga-services/services/trip-service/internal/service"
+	"github.com/namycodes/yanga-services/shared-lib/utils"
+)
+
+type AirportQueueHandler struct {
+	queueService *service.AirportQueueService
+}
+
+func NewAirportQueueHandler(queueService *service.AirportQueueService) *AirportQueueHandler {
+	return &AirportQueueHandler{
+		queueService: queueService,
+	}
+}
+
+// GetMyQueuePosition godoc
+// @Summary Get the current driver's place in the airport queue
+// @Description Drivers join the queue by entering one of an airport's staging lots. Position 1 is offered the next pickup at the airport.
+// @Tags airport-queues
+// @Produce json
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Router /airport-queues/me [get]
+// @Security BearerAuth
+func (h *AirportQueueHandler) GetMyQueuePosition(w http.ResponseWriter, r *http.Request) {
+	driverIDStr := r.Context().Value("user_id").(string)
+	driverID, err := utils.ParseUUID(driverIDStr)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	position, err := h.queueService.GetQueuePosition(r.Context(), driverID)
+	if err != nil {
+		handleAirportQueueError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Queue position retrieved successfully", position)
+}
+
+// ListAirportQueue godoc
+// @Summary List the drivers queued for an airport's pickups (admin)
+// @Tags airport-queues
+// @Produce json
+// @Param id path string true "Airport geofence ID"
+// @Param limit query int false "Maximum drivers to list (default 100)"
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Router /airport-queues/{id} [get]
+// @Security BearerAuth
+func (h *AirportQueueHandler) ListAirportQueue(w http.ResponseWriter, r *http.Request) {
+	airportID, err := utils.ParseUUID(mux.Vars(r)["id"])
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid airport ID")
+		return
+	}
+
+	queue, err := h.queueService.ListQueue(r.Context(), airportID, queryInt(r, "limit", 100))
+	if err != nil {
+		handleAirportQueueError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Airport queue retrieved successfully", queue)
+}
+
+func handleAirportQueueError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrNotInAirportQueue),
+		errors.Is(err, service.ErrGeofenceNotFound):
+		utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+	default:
+		utils.HandleServiceError(w, err)
+	}
+}
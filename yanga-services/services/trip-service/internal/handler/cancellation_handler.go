@@ -0,0 +1,223 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/namycodes/yanga-services/services/trip-service/internal/service"
+	"github.com/namycodes/yanga-services/shared-lib/domain"
+	"github.com/namycodes/yanga-services/shared-lib/middleware"
+	"github.com/namycodes/yanga-services/shared-lib/utils"
+)
+
+type CancellationHandler struct {
+	cancellationService *service.CancellationService
+}
+
+func NewCancellationHandler(cancellationService *service.CancellationService) *CancellationHandler {
+	return &CancellationHandler{
+		cancellationService: cancellationService,
+	}
+}
+
+// CancelTrip godoc
+// @Summary Cancel a trip (rider, assigned driver or admin)
+// @Description Riders may be charged a fee according to the city's cancellation policy
+// @Tags trips
+// @Accept json
+// @Produce json
+// @Param id path string true "Trip ID"
+// @Param request body domain.CancelTripRequest true "Cancellation details"
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 403 {object} domain.ErrorResponse
+// @Failure 409 {object} domain.ErrorResponse
+// @Router /trips/{id}/cancel [post]
+// @Security BearerAuth
+func (h *CancellationHandler) CancelTrip(w http.ResponseWriter, r *http.Request) {
+	tripID, err := utils.ParseUUID(mux.Vars(r)["id"])
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid trip ID")
+		return
+	}
+
+	var req domain.CancelTripRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	userIDStr := r.Context().Value("user_id").(string)
+	userID, err := utils.ParseUUID(userIDStr)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	result, err := h.cancellationService.CancelTrip(r.Context(), tripID, userID, middleware.GetUserRole(r.Context()), req.Reason)
+	if err != nil {
+		handleCancellationError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Trip cancelled successfully", result)
+}
+
+// MarkDriverArrived godoc
+// @Summary Mark the driver as arrived at pickup (assigned driver)
+// @Tags trips
+// @Produce json
+// @Param id path string true "Trip ID"
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 403 {object} domain.ErrorResponse
+// @Failure 409 {object} domain.ErrorResponse
+// @Router /trips/{id}/arrived [post]
+// @Security BearerAuth
+func (h *CancellationHandler) MarkDriverArrived(w http.ResponseWriter, r *http.Request) {
+	tripID, err := utils.ParseUUID(mux.Vars(r)["id"])
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid trip ID")
+		return
+	}
+
+	driverIDStr := r.Context().Value("user_id").(string)
+	driverID, err := utils.ParseUUID(driverIDStr)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	if err := h.cancellationService.MarkDriverArrived(r.Context(), tripID, driverID); err != nil {
+		handleCancellationError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Driver marked as arrived", nil)
+}
+
+// ReportRiderNoShow godoc
+// @Summary Cancel a trip because the rider did not show up (assigned driver)
+// @Description Allowed once the driver has waited at pickup for the city's no-show wait time
+// @Tags trips
+// @Produce json
+// @Param id path string true "Trip ID"
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 403 {object} domain.ErrorResponse
+// @Failure 409 {object} domain.ErrorResponse
+// @Router /trips/{id}/no-show [post]
+// @Security BearerAuth
+func (h *CancellationHandler) ReportRiderNoShow(w http.ResponseWriter, r *http.Request) {
+	tripID, err := utils.ParseUUID(mux.Vars(r)["id"])
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid trip ID")
+		return
+	}
+
+	driverIDStr := r.Context().Value("user_id").(string)
+	driverID, err := utils.ParseUUID(driverIDStr)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	result, err := h.cancellationService.ReportRiderNoShow(r.Context(), tripID, driverID)
+	if err != nil {
+		handleCancellationError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Rider no-show recorded", result)
+}
+
+// ListPolicies godoc
+// @Summary List cancellation policies (admin)
+// @Tags cancellation-policies
+// @Produce json
+// @Success 200 {object} domain.SuccessResponse
+// @Router /cancellation-policies [get]
+// @Security BearerAuth
+func (h *CancellationHandler) ListPolicies(w http.ResponseWriter, r *http.Request) {
+	policies, err := h.cancellationService.ListPolicies(r.Context())
+	if err != nil {
+		handleCancellationError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Cancellation policies retrieved successfully", policies)
+}
+
+// UpsertPolicy godoc
+// @Summary Create or replace a city's cancellation policy (admin)
+// @Tags cancellation-policies
+// @Accept json
+// @Produce json
+// @Param city_code path string true "City code, or 'default'"
+// @Param request body domain.UpsertCancellationPolicyRequest true "Policy"
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 400 {object} domain.ErrorResponse
+// @Router /cancellation-policies/{city_code} [put]
+// @Security BearerAuth
+func (h *CancellationHandler) UpsertPolicy(w http.ResponseWriter, r *http.Request) {
+	var req domain.UpsertCancellationPolicyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	adminIDStr := r.Context().Value("user_id").(string)
+	adminID, err := utils.ParseUUID(adminIDStr)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	policy, err := h.cancellationService.UpsertPolicy(r.Context(), adminID, mux.Vars(r)["city_code"], &req)
+	if err != nil {
+		handleCancellationError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Cancellation policy saved successfully", policy)
+}
+
+// DeletePolicy godoc
+// @Summary Delete a city's cancellation policy so it falls back to the default (admin)
+// @Tags cancellation-policies
+// @Produce json
+// @Param city_code path string true "City code"
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Router /cancellation-policies/{city_code} [delete]
+// @Security BearerAuth
+func (h *CancellationHandler) DeletePolicy(w http.ResponseWriter, r *http.Request) {
+	if err := h.cancellationService.DeletePolicy(r.Context(), mux.Vars(r)["city_code"]); err != nil {
+		handleCancellationError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Cancellation policy deleted successfully", nil)
+}
+
+func handleCancellationError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidCityCode),
+		errors.Is(err, service.ErrInvalidPolicy),
+		errors.Is(err, service.ErrDefaultPolicyRequired):
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, service.ErrNotTripParticipant):
+		utils.ErrorResponse(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, service.ErrTripNotFound),
+		errors.Is(err, service.ErrPolicyNotFound):
+		utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrTripNotCancellable),
+		errors.Is(err, service.ErrTripNotAccepted),
+		errors.Is(err, service.ErrDriverAlreadyArrived),
+		errors.Is(err, service.ErrDriverNotArrived),
+		errors.Is(err, service.ErrNoShowWaitNotElapsed):
+		utils.ErrorResponse(w, http.StatusConflict, err.Error())
+	default:
+		utils.HandleServiceError(w, err)
+	}
+}
@@ -0,0 +1,346 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+	"github.com/namycodes/yanga-services/services/trip-service/internal/service"
+	"github.com/namycodes/yanga-services/shared-lib/domain"
+	"github.com/namycodes/yanga-services/shared-lib/middleware"
+	"github.com/namycodes/yanga-services/shared-lib/utils"
+)
+
+const (
+	chatWriteWait  = 10 * time.Second
+	chatPongWait   = 60 * time.Second
+	chatPingPeriod = chatPongWait * 9 / 10
+	chatMaxFrame   = 8 << 10
+)
+
+// The apps aren't browsers, and the gateway checks the token before the
+// upgrade reaches us, so the origin isn't checked.
+var chatUpgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+	CheckOrigin:     func(r *http.Request) bool { return true },
+}
+
+type ChatHandler struct {
+	chatService *service.ChatService
+}
+
+func NewChatHandler(chatService *service.ChatService) *ChatHandler {
+	return &ChatHandler{
+		chatService: chatService,
+	}
+}
+
+// GetMessages godoc
+// @Summary Get a trip's chat history, newest first (rider or assigned driver)
+// @Tags trip-chat
+// @Produce json
+// @Param id path string true "Trip ID"
+// @Param limit query int false "Page size" default(50)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 403 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Router /trips/{id}/messages [get]
+// @Security BearerAuth
+func (h *ChatHandler) GetMessages(w http.ResponseWriter, r *http.Request) {
+	tripID, userID, ok := chatParticipant(w, r)
+	if !ok {
+		return
+	}
+
+	messages, err := h.chatService.ListMessages(r.Context(), tripID, userID, queryInt(r, "limit", 50), queryInt(r, "offset", 0))
+	if err != nil {
+		handleChatError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Messages retrieved successfully", messages)
+}
+
+// SendMessage godoc
+// @Summary Send a chat message on a trip (rider or assigned driver)
+// @Description Send either a body or a quick_reply code. Resending with the same client_id returns the original message.
+// @Tags trip-chat
+// @Accept json
+// @Produce json
+// @Param id path string true "Trip ID"
+// @Param request body domain.SendChatMessageRequest true "Message"
+// @Success 201 {object} domain.SuccessResponse
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 403 {object} domain.ErrorResponse
+// @Failure 409 {object} domain.ErrorResponse
+// @Failure 422 {object} domain.ErrorResponse
+// @Router /trips/{id}/messages [post]
+// @Security BearerAuth
+func (h *ChatHandler) SendMessage(w http.ResponseWriter, r *http.Request) {
+	tripID, userID, ok := chatParticipant(w, r)
+	if !ok {
+		return
+	}
+
+	var req domain.SendChatMessageRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	message, err := h.chatService.SendMessage(r.Context(), tripID, userID, &req)
+	if err != nil {
+		handleChatError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusCreated, "Message sent successfully", message)
+}
+
+// MarkRead godoc
+// @Summary Mark the other side's messages read up to a message (rider or assigned driver)
+// @Tags trip-chat
+// @Accept json
+// @Produce json
+// @Param id path string true "Trip ID"
+// @Param request body domain.MarkChatReadRequest true "Last message read"
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 403 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Router /trips/{id}/messages/read [post]
+// @Security BearerAuth
+func (h *ChatHandler) MarkRead(w http.ResponseWriter, r *http.Request) {
+	tripID, userID, ok := chatParticipant(w, r)
+	if !ok {
+		return
+	}
+
+	var req domain.MarkChatReadRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	receipt, err := h.chatService.MarkRead(r.Context(), tripID, userID, &req)
+	if err != nil {
+		handleChatError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Messages marked read", receipt)
+}
+
+// GetQuickReplies godoc
+// @Summary Get the canned chat messages for the caller's role
+// @Tags trip-chat
+// @Produce json
+// @Success 200 {object} domain.SuccessResponse
+// @Router /trips/chat/quick-replies [get]
+// @Security BearerAuth
+func (h *ChatHandler) GetQuickReplies(w http.ResponseWriter, r *http.Request) {
+	replies := h.chatService.QuickReplies(middleware.GetUserRole(r.Context()))
+	utils.SuccessResponse(w, http.StatusOK, "Quick replies retrieved successfully", replies)
+}
+
+// OpenCall godoc
+// @Summary Get a proxy number to call the other side of a trip (rider or assigned driver)
+// @Description Neither side sees the other's real number. The number works until expires_at or the trip ends.
+// @Tags trip-chat
+// @Produce json
+// @Param id path string true "Trip ID"
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 403 {object} domain.ErrorResponse
+// @Failure 409 {object} domain.ErrorResponse
+// @Failure 503 {object} domain.ErrorResponse
+// @Router /trips/{id}/call [post]
+// @Security BearerAuth
+func (h *ChatHandler) OpenCall(w http.ResponseWriter, r *http.Request) {
+	tripID, userID, ok := chatParticipant(w, r)
+	if !ok {
+		return
+	}
+
+	call, err := h.chatService.OpenCall(r.Context(), tripID, userID)
+	if err != nil {
+		handleChatError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Call number retrieved successfully", call)
+}
+
+// Chat godoc
+// @Summary Open the trip chat WebSocket (rider or assigned driver)
+// @Description Send {"type":"message",...} frames with the fields of SendChatMessageRequest and {"type":"read","message_id":...} frames. Receives "message", "read" and "error" frames.
+// @Tags trip-chat
+// @Param id path string true "Trip ID"
+// @Success 101
+// @Failure 403 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Router /trips/{id}/chat [get]
+// @Security BearerAuth
+func (h *ChatHandler) Chat(w http.ResponseWriter, r *http.Request) {
+	tripID, userID, ok := chatParticipant(w, r)
+	if !ok {
+		return
+	}
+
+	// Check before upgrading so a refusal is a normal HTTP error.
+	frames, stop, err := h.chatService.Listen(r.Context(), tripID, userID)
+	if err != nil {
+		handleChatError(w, err)
+		return
+	}
+	defer stop()
+
+	conn, err := chatUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Printf("Failed to upgrade chat connection on trip %s: %v", tripID, err)
+		return
+	}
+	defer conn.Close()
+
+	var writeMu sync.Mutex
+	write := func(frame domain.ChatServerFrame) error {
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		conn.SetWriteDeadline(time.Now().Add(chatWriteWait))
+		return conn.WriteJSON(frame)
+	}
+
+	go func() {
+		ticker := time.NewTicker(chatPingPeriod)
+		defer ticker.Stop()
+		for {
+			select {
+			case frame, ok := <-frames:
+				if !ok {
+					// Stopped, or fell too far behind. Closing the
+					// connection ends the read loop below.
+					conn.Close()
+					return
+				}
+				if err := write(frame); err != nil {
+					conn.Close()
+					return
+				}
+			case <-ticker.C:
+				writeMu.Lock()
+				err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(chatWriteWait))
+				writeMu.Unlock()
+				if err != nil {
+					conn.Close()
+					return
+				}
+			}
+		}
+	}()
+
+	conn.SetReadLimit(chatMaxFrame)
+	conn.SetReadDeadline(time.Now().Add(chatPongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(chatPongWait))
+	})
+
+	for {
+		var frame domain.ChatClientFrame
+		if err := conn.ReadJSON(&frame); err != nil {
+			var syntaxErr *json.SyntaxError
+			var typeErr *json.UnmarshalTypeError
+			if errors.As(err, &syntaxErr) || errors.As(err, &typeErr) {
+				write(domain.ChatServerFrame{Type: domain.ChatFrameError, Error: "Invalid frame"})
+				continue
+			}
+			return
+		}
+
+		var reply domain.ChatServerFrame
+		switch frame.Type {
+		case domain.ChatFrameMessage:
+			message, err := h.chatService.SendMessage(r.Context(), tripID, userID, &frame.SendChatMessageRequest)
+			if err != nil {
+				reply = chatErrorFrame(err)
+				break
+			}
+			reply = domain.ChatServerFrame{Type: domain.ChatFrameMessage, Message: message}
+		case domain.ChatFrameRead:
+			receipt, err := h.chatService.MarkRead(r.Context(), tripID, userID, &domain.MarkChatReadRequest{MessageID: frame.MessageID})
+			if err != nil {
+				reply = chatErrorFrame(err)
+				break
+			}
+			reply = domain.ChatServerFrame{Type: domain.ChatFrameRead, Read: receipt}
+		default:
+			reply = domain.ChatServerFrame{Type: domain.ChatFrameError, Error: "Unknown frame type"}
+		}
+
+		if err := write(reply); err != nil {
+			return
+		}
+	}
+}
+
+func chatParticipant(w http.ResponseWriter, r *http.Request) (uuid.UUID, uuid.UUID, bool) {
+	tripID, err := utils.ParseUUID(mux.Vars(r)["id"])
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid trip ID")
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	userIDStr := r.Context().Value("user_id").(string)
+	userID, err := utils.ParseUUID(userIDStr)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid user ID")
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return tripID, userID, true
+}
+
+// chatErrorFrame reports a failed frame on the socket. Anything
+// unexpected is logged rather than shown.
+func chatErrorFrame(err error) domain.ChatServerFrame {
+	if chatErrorStatus(err) == http.StatusInternalServerError {
+		log.Printf("Chat frame failed: %v", err)
+		return domain.ChatServerFrame{Type: domain.ChatFrameError, Error: "Something went wrong"}
+	}
+	return domain.ChatServerFrame{Type: domain.ChatFrameError, Error: err.Error()}
+}
+
+func chatErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrInvalidChatMessage):
+		return http.StatusBadRequest
+	case errors.Is(err, service.ErrNotTripParticipant):
+		return http.StatusForbidden
+	case errors.Is(err, service.ErrTripNotFound),
+		errors.Is(err, service.ErrChatMessageNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrTripNotAccepted),
+		errors.Is(err, service.ErrTripNotUnderWay):
+		return http.StatusConflict
+	case errors.Is(err, service.ErrChatMessageRejected):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, service.ErrCallUnavailable):
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func handleChatError(w http.ResponseWriter, err error) {
+	if status := chatErrorStatus(err); status != http.StatusInternalServerError {
+		utils.ErrorResponse(w, status, err.Error())
+		return
+	}
+	utils.HandleServiceError(w, err)
+}
@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/namycodes/yanga-services/services/trip-service/internal/service"
+	"github.com/namycodes/yanga-services/shared-lib/domain"
+	"github.com/namycodes/yanga-services/shared-lib/utils"
+)
+
+type CityHandler struct {
+	cityService *service.CityService
+}
+
+func NewCityHandler(cityService *service.CityService) *CityHandler {
+	return &CityHandler{
+		cityService: cityService,
+	}
+}
+
+// ListCities godoc
+// @Summary List the cities we operate in, with their currency, rates and features
+// @Tags cities
+// @Produce json
+// @Success 200 {object} domain.SuccessResponse
+// @Router /cities [get]
+// @Security BearerAuth
+func (h *CityHandler) ListCities(w http.ResponseWriter, r *http.Request) {
+	cities, err := h.cityService.ListCities(r.Context())
+	if err != nil {
+		handleCityError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Cities retrieved successfully", cities)
+}
+
+// GetCity godoc
+// @Summary Get a city's settings
+// @Tags cities
+// @Produce json
+// @Param code path string true "City code"
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Router /cities/{code} [get]
+// @Security BearerAuth
+func (h *CityHandler) GetCity(w http.ResponseWriter, r *http.Request) {
+	city, err := h.cityService.GetCity(r.Context(), mux.Vars(r)["code"])
+	if err != nil {
+		handleCityError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "City retrieved successfully", city)
+}
+
+// UpsertCity godoc
+// @Summary Create a city or replace its settings (admin)
+// @Description New rates apply to trips booked afterwards. Commission plans and cancellation policies are set per city separately.
+// @Tags cities
+// @Accept json
+// @Produce json
+// @Param code path string true "City code"
+// @Param request body domain.UpsertCityRequest true "City settings"
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 400 {object} domain.ErrorResponse
+// @Router /cities/{code} [put]
+// @Security BearerAuth
+func (h *CityHandler) UpsertCity(w http.ResponseWriter, r *http.Request) {
+	var req domain.UpsertCityRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	city, err := h.cityService.UpsertCity(r.Context(), mux.Vars(r)["code"], &req)
+	if err != nil {
+		handleCityError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "City saved successfully", city)
+}
+
+func handleCityError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidCityCode),
+		errors.Is(err, service.ErrInvalidCity):
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, service.ErrCityNotFound):
+		utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+	default:
+		utils.HandleServiceError(w, err)
+	}
+}
@@ -0,0 +1,162 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/namycodes/yanga-services/services/trip-service/internal/service"
+	"github.com/namycodes/yanga-services/shared-lib/domain"
+	"github.com/namycodes/yanga-services/shared-lib/middleware"
+	"github.com/namycodes/yanga-services/shared-lib/utils"
+)
+
+type DeliveryHandler struct {
+	deliveryService *service.DeliveryService
+}
+
+func NewDeliveryHandler(deliveryService *service.DeliveryService) *DeliveryHandler {
+	return &DeliveryHandler{
+		deliveryService: deliveryService,
+	}
+}
+
+// GetDelivery godoc
+// @Summary Get a delivery trip's parcel details (sender, assigned driver or admin)
+// @Description The recipient PIN is only shown to the sender
+// @Tags deliveries
+// @Produce json
+// @Param id path string true "Trip ID"
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 403 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Router /trips/{id}/delivery [get]
+// @Security BearerAuth
+func (h *DeliveryHandler) GetDelivery(w http.ResponseWriter, r *http.Request) {
+	tripID, err := utils.ParseUUID(mux.Vars(r)["id"])
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid trip ID")
+		return
+	}
+
+	userIDStr := r.Context().Value("user_id").(string)
+	userID, err := utils.ParseUUID(userIDStr)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	delivery, err := h.deliveryService.GetDelivery(r.Context(), tripID, userID, middleware.GetUserRole(r.Context()))
+	if err != nil {
+		handleDeliveryError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Delivery retrieved successfully", delivery)
+}
+
+// ConfirmPickup godoc
+// @Summary Confirm a parcel was picked up (assigned driver)
+// @Tags deliveries
+// @Accept json
+// @Produce json
+// @Param id path string true "Trip ID"
+// @Param request body domain.ConfirmParcelPickupRequest true "Proof of pickup"
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 403 {object} domain.ErrorResponse
+// @Failure 409 {object} domain.ErrorResponse
+// @Router /trips/{id}/delivery/pickup [post]
+// @Security BearerAuth
+func (h *DeliveryHandler) ConfirmPickup(w http.ResponseWriter, r *http.Request) {
+	tripID, err := utils.ParseUUID(mux.Vars(r)["id"])
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid trip ID")
+		return
+	}
+
+	var req domain.ConfirmParcelPickupRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	driverIDStr := r.Context().Value("user_id").(string)
+	driverID, err := utils.ParseUUID(driverIDStr)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	if err := h.deliveryService.ConfirmPickup(r.Context(), tripID, driverID, req.PhotoRef); err != nil {
+		handleDeliveryError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Parcel pickup confirmed", nil)
+}
+
+// ConfirmDelivery godoc
+// @Summary Confirm a parcel was handed to the recipient (assigned driver)
+// @Description Requires the PIN the recipient received from the sender and a photo of the handover
+// @Tags deliveries
+// @Accept json
+// @Produce json
+// @Param id path string true "Trip ID"
+// @Param request body domain.ConfirmParcelDeliveryRequest true "Proof of delivery"
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 403 {object} domain.ErrorResponse
+// @Failure 409 {object} domain.ErrorResponse
+// @Router /trips/{id}/delivery/confirm [post]
+// @Security BearerAuth
+func (h *DeliveryHandler) ConfirmDelivery(w http.ResponseWriter, r *http.Request) {
+	tripID, err := utils.ParseUUID(mux.Vars(r)["id"])
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid trip ID")
+		return
+	}
+
+	var req domain.ConfirmParcelDeliveryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	driverIDStr := r.Context().Value("user_id").(string)
+	driverID, err := utils.ParseUUID(driverIDStr)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	if err := h.deliveryService.ConfirmDelivery(r.Context(), tripID, driverID, req.PhotoRef, req.Pin); err != nil {
+		handleDeliveryError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Parcel delivery confirmed", nil)
+}
+
+func handleDeliveryError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidDelivery),
+		errors.Is(err, service.ErrInvalidDeliveryPin):
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, service.ErrNotTripParticipant):
+		utils.ErrorResponse(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, service.ErrTripNotFound),
+		errors.Is(err, service.ErrNotDeliveryTrip):
+		utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrParcelAlreadyPickedUp),
+		errors.Is(err, service.ErrParcelNotPickedUp),
+		errors.Is(err, service.ErrParcelAlreadyDelivered),
+		errors.Is(err, service.ErrParcelNotDelivered),
+		errors.Is(err, service.ErrDeliveryPinLocked),
+		errors.Is(err, service.ErrTripNotInProgress):
+		utils.ErrorResponse(w, http.StatusConflict, err.Error())
+	default:
+		utils.HandleServiceError(w, err)
+	}
+}
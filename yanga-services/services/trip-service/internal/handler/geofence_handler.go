@@ -0,0 +1,169 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/namycodes/yanga-services/services/trip-service/internal/service"
+	"github.com/namycodes/yanga-services/shared-lib/domain"
+	"github.com/namycodes/yanga-services/shared-lib/utils"
+)
+
+type GeofenceHandler struct {
+	geofenceService *service.GeofenceService
+}
+
+func NewGeofenceHandler(geofenceService *service.GeofenceService) *GeofenceHandler {
+	return &GeofenceHandler{
+		geofenceService: geofenceService,
+	}
+}
+
+// ListGeofences godoc
+// @Summary List service areas and zones (admin)
+// @Tags geofences
+// @Produce json
+// @Param city_code query string false "Only this city's geofences"
+// @Param kind query string false "Only geofences of this kind (service_area, restricted, airport, staging)"
+// @Success 200 {object} domain.SuccessResponse
+// @Router /geofences [get]
+// @Security BearerAuth
+func (h *GeofenceHandler) ListGeofences(w http.ResponseWriter, r *http.Request) {
+	geofences, err := h.geofenceService.ListGeofences(r.Context(), r.URL.Query().Get("city_code"), r.URL.Query().Get("kind"))
+	if err != nil {
+		handleGeofenceError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Geofences retrieved successfully", geofences)
+}
+
+// GetGeofence godoc
+// @Summary Get a service area or zone (admin)
+// @Tags geofences
+// @Produce json
+// @Param id path string true "Geofence ID"
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Router /geofences/{id} [get]
+// @Security BearerAuth
+func (h *GeofenceHandler) GetGeofence(w http.ResponseWriter, r *http.Request) {
+	id, err := utils.ParseUUID(mux.Vars(r)["id"])
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid geofence ID")
+		return
+	}
+
+	geofence, err := h.geofenceService.GetGeofence(r.Context(), id)
+	if err != nil {
+		handleGeofenceError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Geofence retrieved successfully", geofence)
+}
+
+// CreateGeofence godoc
+// @Summary Draw a service area, restricted zone or airport (admin)
+// @Tags geofences
+// @Accept json
+// @Produce json
+// @Param request body domain.GeofenceRequest true "Geofence"
+// @Success 201 {object} domain.SuccessResponse
+// @Failure 400 {object} domain.ErrorResponse
+// @Router /geofences [post]
+// @Security BearerAuth
+func (h *GeofenceHandler) CreateGeofence(w http.ResponseWriter, r *http.Request) {
+	var req domain.GeofenceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	adminIDStr := r.Context().Value("user_id").(string)
+	adminID, err := utils.ParseUUID(adminIDStr)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	geofence, err := h.geofenceService.CreateGeofence(r.Context(), adminID, &req)
+	if err != nil {
+		handleGeofenceError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusCreated, "Geofence created successfully", geofence)
+}
+
+// UpdateGeofence godoc
+// @Summary Replace a service area or zone (admin)
+// @Tags geofences
+// @Accept json
+// @Produce json
+// @Param id path string true "Geofence ID"
+// @Param request body domain.GeofenceRequest true "Geofence"
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Router /geofences/{id} [put]
+// @Security BearerAuth
+func (h *GeofenceHandler) UpdateGeofence(w http.ResponseWriter, r *http.Request) {
+	id, err := utils.ParseUUID(mux.Vars(r)["id"])
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid geofence ID")
+		return
+	}
+
+	var req domain.GeofenceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	geofence, err := h.geofenceService.UpdateGeofence(r.Context(), id, &req)
+	if err != nil {
+		handleGeofenceError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Geofence updated successfully", geofence)
+}
+
+// DeleteGeofence godoc
+// @Summary Delete a service area or zone (admin)
+// @Tags geofences
+// @Produce json
+// @Param id path string true "Geofence ID"
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Router /geofences/{id} [delete]
+// @Security BearerAuth
+func (h *GeofenceHandler) DeleteGeofence(w http.ResponseWriter, r *http.Request) {
+	id, err := utils.ParseUUID(mux.Vars(r)["id"])
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid geofence ID")
+		return
+	}
+
+	if err := h.geofenceService.DeleteGeofence(r.Context(), id); err != nil {
+		handleGeofenceError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Geofence deleted successfully", nil)
+}
+
+func handleGeofenceError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidGeofence),
+		errors.Is(err, service.ErrInvalidCityCode):
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, service.ErrGeofenceNotFound):
+		utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+	default:
+		utils.HandleServiceError(w, err)
+	}
+}
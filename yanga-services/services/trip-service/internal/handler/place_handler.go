@@ -0,0 +1,201 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/namycodes/yanga-services/services/trip-service/internal/service"
+	"github.com/namycodes/yanga-services/shared-lib/domain"
+	"github.com/namycodes/yanga-services/shared-lib/routing"
+	"github.com/namycodes/yanga-services/shared-lib/utils"
+)
+
+type PlaceHandler struct {
+	placeService *service.PlaceService
+}
+
+func NewPlaceHandler(placeService *service.PlaceService) *PlaceHandler {
+	return &PlaceHandler{
+		placeService: placeService,
+	}
+}
+
+// ListSavedPlaces godoc
+// @Summary List the current user's saved places
+// @Description Home and work come first, then favorites in the order they were saved
+// @Tags places
+// @Produce json
+// @Success 200 {object} domain.SuccessResponse
+// @Router /places [get]
+// @Security BearerAuth
+func (h *PlaceHandler) ListSavedPlaces(w http.ResponseWriter, r *http.Request) {
+	userIDStr := r.Context().Value("user_id").(string)
+	userID, err := utils.ParseUUID(userIDStr)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	places, err := h.placeService.ListSavedPlaces(r.Context(), userID)
+	if err != nil {
+		handlePlaceError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Saved places retrieved successfully", places)
+}
+
+// SavePlace godoc
+// @Summary Save a place for the current user
+// @Description Saving home or work replaces the one saved before. The address must match the coordinates.
+// @Tags places
+// @Accept json
+// @Produce json
+// @Param request body domain.SavePlaceRequest true "Place details"
+// @Success 201 {object} domain.SuccessResponse
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 409 {object} domain.ErrorResponse
+// @Router /places [post]
+// @Security BearerAuth
+func (h *PlaceHandler) SavePlace(w http.ResponseWriter, r *http.Request) {
+	var req domain.SavePlaceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	userIDStr := r.Context().Value("user_id").(string)
+	userID, err := utils.ParseUUID(userIDStr)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	place, err := h.placeService.SavePlace(r.Context(), userID, &req)
+	if err != nil {
+		handlePlaceError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusCreated, "Place saved successfully", place)
+}
+
+// DeleteSavedPlace godoc
+// @Summary Delete one of the current user's saved places
+// @Tags places
+// @Produce json
+// @Param id path string true "Saved place ID"
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Router /places/{id} [delete]
+// @Security BearerAuth
+func (h *PlaceHandler) DeleteSavedPlace(w http.ResponseWriter, r *http.Request) {
+	placeID, err := utils.ParseUUID(mux.Vars(r)["id"])
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid place ID")
+		return
+	}
+
+	userIDStr := r.Context().Value("user_id").(string)
+	userID, err := utils.ParseUUID(userIDStr)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	if err := h.placeService.DeleteSavedPlace(r.Context(), userID, placeID); err != nil {
+		handlePlaceError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Place deleted successfully", nil)
+}
+
+// SearchPlaces godoc
+// @Summary Autocomplete a place name or address
+// @Description Pass the user's position as lat and lng to rank nearby places first
+// @Tags places
+// @Produce json
+// @Param q query string true "Partly typed name or address"
+// @Param lat query number false "Latitude to search near"
+// @Param lng query number false "Longitude to search near"
+// @Param limit query int false "Maximum results (default 5, at most 20)"
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 400 {object} domain.ErrorResponse
+// @Router /places/search [get]
+// @Security BearerAuth
+func (h *PlaceHandler) SearchPlaces(w http.ResponseWriter, r *http.Request) {
+	var near *routing.Point
+	if r.URL.Query().Has("lat") || r.URL.Query().Has("lng") {
+		lat, lng, ok := queryCoordinates(r)
+		if !ok {
+			utils.ErrorResponse(w, http.StatusBadRequest, "Invalid coordinates")
+			return
+		}
+		near = &routing.Point{Lat: lat, Lng: lng}
+	}
+
+	places, err := h.placeService.Search(r.Context(), r.URL.Query().Get("q"), near, int(queryInt(r, "limit", 0)))
+	if err != nil {
+		handlePlaceError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Places retrieved successfully", places)
+}
+
+// ReverseGeocode godoc
+// @Summary Name the place at a point, such as a driver's position or a pickup pin
+// @Tags places
+// @Produce json
+// @Param lat query number true "Latitude"
+// @Param lng query number true "Longitude"
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Router /places/reverse [get]
+// @Security BearerAuth
+func (h *PlaceHandler) ReverseGeocode(w http.ResponseWriter, r *http.Request) {
+	lat, lng, ok := queryCoordinates(r)
+	if !ok {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid coordinates")
+		return
+	}
+
+	place, err := h.placeService.Reverse(r.Context(), lat, lng)
+	if err != nil {
+		handlePlaceError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Place retrieved successfully", place)
+}
+
+func queryCoordinates(r *http.Request) (float64, float64, bool) {
+	lat, err := strconv.ParseFloat(r.URL.Query().Get("lat"), 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	lng, err := strconv.ParseFloat(r.URL.Query().Get("lng"), 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	return lat, lng, true
+}
+
+func handlePlaceError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidPlace),
+		errors.Is(err, service.ErrAddressMismatch):
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, service.ErrPlaceNotFound):
+		utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrTooManyPlaces):
+		utils.ErrorResponse(w, http.StatusConflict, err.Error())
+	default:
+		utils.HandleServiceError(w, err)
+	}
+}
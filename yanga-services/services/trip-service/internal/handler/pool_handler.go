@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/namycodes/yanga-services/services/trip-service/internal/service"
+	"github.com/namycodes/yanga-services/shared-lib/middleware"
+	"github.com/namycodes/yanga-services/shared-lib/utils"
+)
+
+type PoolHandler struct {
+	poolService *service.PoolService
+}
+
+func NewPoolHandler(poolService *service.PoolService) *PoolHandler {
+	return &PoolHandler{
+		poolService: poolService,
+	}
+}
+
+// GetPool godoc
+// @Summary Get the shared route of a pooled trip (rider, pool driver or admin)
+// @Description Drivers see every rider's pickup and dropoff in order; riders only see their own
+// @Tags pools
+// @Produce json
+// @Param id path string true "Trip ID"
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 403 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Router /trips/{id}/pool [get]
+// @Security BearerAuth
+func (h *PoolHandler) GetPool(w http.ResponseWriter, r *http.Request) {
+	tripID, err := utils.ParseUUID(mux.Vars(r)["id"])
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid trip ID")
+		return
+	}
+
+	userIDStr := r.Context().Value("user_id").(string)
+	userID, err := utils.ParseUUID(userIDStr)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	pool, err := h.poolService.GetPool(r.Context(), tripID, userID, middleware.GetUserRole(r.Context()))
+	if err != nil {
+		handlePoolError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Pool retrieved successfully", pool)
+}
+
+func handlePoolError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrNotTripParticipant):
+		utils.ErrorResponse(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, service.ErrTripNotFound),
+		errors.Is(err, service.ErrTripNotPooled):
+		utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+	default:
+		utils.HandleServiceError(w, err)
+	}
+}
@@ -0,0 +1,178 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/namycodes/yanga-services/services/trip-service/internal/service"
+	"github.com/namycodes/yanga-services/shared-lib/domain"
+	"github.com/namycodes/yanga-services/shared-lib/utils"
+)
+
+type PromotionHandler struct {
+	promotionService *service.PromotionService
+}
+
+func NewPromotionHandler(promotionService *service.PromotionService) *PromotionHandler {
+	return &PromotionHandler{
+		promotionService: promotionService,
+	}
+}
+
+// GetReferral godoc
+// @Summary Get the current user's referral code and rewards
+// @Tags promotions
+// @Produce json
+// @Success 200 {object} domain.SuccessResponse
+// @Router /promotions/referral [get]
+// @Security BearerAuth
+func (h *PromotionHandler) GetReferral(w http.ResponseWriter, r *http.Request) {
+	userIDStr := r.Context().Value("user_id").(string)
+	userID, err := utils.ParseUUID(userIDStr)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	referral, err := h.promotionService.GetReferral(r.Context(), userID)
+	if err != nil {
+		handlePromotionError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Referral retrieved successfully", referral)
+}
+
+// ListPromoCodes godoc
+// @Summary List promo codes (admin)
+// @Tags promotions
+// @Produce json
+// @Description Filtering by city also lists the codes valid in every city
+// @Param city_code query string false "Filter by city"
+// @Param limit query int false "Limit" default(20)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {object} domain.SuccessResponse
+// @Router /promotions [get]
+// @Security BearerAuth
+func (h *PromotionHandler) ListPromoCodes(w http.ResponseWriter, r *http.Request) {
+	promos, err := h.promotionService.ListPromoCodes(r.Context(), r.URL.Query().Get("city_code"), queryInt(r, "limit", 20), queryInt(r, "offset", 0))
+	if err != nil {
+		handlePromotionError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Promo codes retrieved successfully", promos)
+}
+
+// CreatePromoCode godoc
+// @Summary Create a promo code (admin)
+// @Tags promotions
+// @Accept json
+// @Produce json
+// @Param request body domain.CreatePromoCodeRequest true "Promo code details"
+// @Success 201 {object} domain.SuccessResponse
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 409 {object} domain.ErrorResponse
+// @Router /promotions [post]
+// @Security BearerAuth
+func (h *PromotionHandler) CreatePromoCode(w http.ResponseWriter, r *http.Request) {
+	var req domain.CreatePromoCodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	adminIDStr := r.Context().Value("user_id").(string)
+	adminID, err := utils.ParseUUID(adminIDStr)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	promo, err := h.promotionService.CreatePromoCode(r.Context(), adminID, &req)
+	if err != nil {
+		handlePromotionError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusCreated, "Promo code created successfully", promo)
+}
+
+// UpdatePromoCodeStatus godoc
+// @Summary Activate or deactivate a promo code (admin)
+// @Tags promotions
+// @Accept json
+// @Produce json
+// @Param id path string true "Promo code ID"
+// @Param request body domain.UpdatePromoCodeStatusRequest true "Status"
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Router /promotions/{id}/status [put]
+// @Security BearerAuth
+func (h *PromotionHandler) UpdatePromoCodeStatus(w http.ResponseWriter, r *http.Request) {
+	promoID, err := utils.ParseUUID(mux.Vars(r)["id"])
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid promo code ID")
+		return
+	}
+
+	var req domain.UpdatePromoCodeStatusRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	promo, err := h.promotionService.SetPromoCodeActive(r.Context(), promoID, req.IsActive)
+	if err != nil {
+		handlePromotionError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Promo code updated successfully", promo)
+}
+
+func handlePromotionError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidPromoCode),
+		errors.Is(err, service.ErrInvalidCityCode),
+		errors.Is(err, service.ErrPromoInactive),
+		errors.Is(err, service.ErrPromoExpired),
+		errors.Is(err, service.ErrPromoNotEligible),
+		errors.Is(err, service.ErrInvalidPickupTime),
+		errors.Is(err, service.ErrInvalidStop),
+		errors.Is(err, service.ErrTooManyStops),
+		errors.Is(err, service.ErrInvalidPoolRequest),
+		errors.Is(err, service.ErrInvalidDelivery),
+		errors.Is(err, service.ErrVehicleNotEligible),
+		errors.Is(err, service.ErrAddressMismatch),
+		errors.Is(err, service.ErrAddressUnknown),
+		errors.Is(err, service.ErrOutsideServiceArea),
+		errors.Is(err, service.ErrPickupRestricted),
+		errors.Is(err, service.ErrCityNotServed),
+		errors.Is(err, service.ErrFeatureUnavailable),
+		err.Error() == "pickup location is required",
+		err.Error() == "dropoff location is required",
+		err.Error() == "invalid payment method",
+		err.Error() == "invalid trip type":
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, service.ErrPromoNotFound):
+		utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrPromoCodeExists),
+		errors.Is(err, service.ErrPromoUsageLimitReached),
+		errors.Is(err, service.ErrPromoAlreadyUsed):
+		utils.ErrorResponse(w, http.StatusConflict, err.Error())
+	default:
+		utils.HandleServiceError(w, err)
+	}
+}
+
+func queryInt(r *http.Request, name string, defaultValue int32) int32 {
+	value, err := strconv.Atoi(r.URL.Query().Get(name))
+	if err != nil || value < 0 {
+		return defaultValue
+	}
+	return int32(value)
+}
@@ -0,0 +1,372 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/namycodes/yanga-services/services/trip-service/internal/service"
+	"github.com/namycodes/yanga-services/shared-lib/domain"
+	"github.com/namycodes/yanga-services/shared-lib/utils"
+)
+
+type SafetyHandler struct {
+	safetyService *service.SafetyService
+}
+
+func NewSafetyHandler(safetyService *service.SafetyService) *SafetyHandler {
+	return &SafetyHandler{
+		safetyService: safetyService,
+	}
+}
+
+// ListContacts godoc
+// @Summary Get the caller's emergency contacts
+// @Tags safety
+// @Produce json
+// @Success 200 {object} domain.SuccessResponse
+// @Router /safety/contacts [get]
+// @Security BearerAuth
+func (h *SafetyHandler) ListContacts(w http.ResponseWriter, r *http.Request) {
+	userID, ok := safetyUser(w, r)
+	if !ok {
+		return
+	}
+
+	contacts, err := h.safetyService.ListContacts(r.Context(), userID)
+	if err != nil {
+		handleSafetyError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Emergency contacts retrieved successfully", contacts)
+}
+
+// AddContact godoc
+// @Summary Add an emergency contact, texted when the caller raises an SOS (at most 5)
+// @Tags safety
+// @Accept json
+// @Produce json
+// @Param request body domain.EmergencyContactRequest true "Contact"
+// @Success 201 {object} domain.SuccessResponse
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 409 {object} domain.ErrorResponse
+// @Router /safety/contacts [post]
+// @Security BearerAuth
+func (h *SafetyHandler) AddContact(w http.ResponseWriter, r *http.Request) {
+	userID, ok := safetyUser(w, r)
+	if !ok {
+		return
+	}
+
+	var req domain.EmergencyContactRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	contact, err := h.safetyService.AddContact(r.Context(), userID, &req)
+	if err != nil {
+		handleSafetyError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusCreated, "Emergency contact added successfully", contact)
+}
+
+// RemoveContact godoc
+// @Summary Remove an emergency contact
+// @Tags safety
+// @Produce json
+// @Param id path string true "Contact ID"
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Router /safety/contacts/{id} [delete]
+// @Security BearerAuth
+func (h *SafetyHandler) RemoveContact(w http.ResponseWriter, r *http.Request) {
+	userID, ok := safetyUser(w, r)
+	if !ok {
+		return
+	}
+
+	contactID, err := utils.ParseUUID(mux.Vars(r)["id"])
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid contact ID")
+		return
+	}
+
+	if err := h.safetyService.RemoveContact(r.Context(), userID, contactID); err != nil {
+		handleSafetyError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Emergency contact removed successfully", nil)
+}
+
+// RaiseSOS godoc
+// @Summary Raise an SOS on a trip (rider or assigned driver)
+// @Description Alerts the safety team with a snapshot of the trip and texts the caller's emergency contacts a link to follow it. Raising it again while the first is unresolved returns the first.
+// @Tags safety
+// @Accept json
+// @Produce json
+// @Param id path string true "Trip ID"
+// @Param request body domain.SOSRequest false "Where the caller is, and what's wrong"
+// @Success 201 {object} domain.SuccessResponse
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 403 {object} domain.ErrorResponse
+// @Failure 409 {object} domain.ErrorResponse
+// @Router /trips/{id}/sos [post]
+// @Security BearerAuth
+func (h *SafetyHandler) RaiseSOS(w http.ResponseWriter, r *http.Request) {
+	tripID, userID, ok := chatParticipant(w, r)
+	if !ok {
+		return
+	}
+
+	// The body is optional so the button works with nothing but a tap.
+	var req domain.SOSRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+	}
+
+	sos, err := h.safetyService.RaiseSOS(r.Context(), tripID, userID, &req)
+	if err != nil {
+		handleSafetyError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusCreated, "SOS raised", sos)
+}
+
+// ShareTrip godoc
+// @Summary Create a link anyone can open to follow a trip (rider or assigned driver)
+// @Tags safety
+// @Produce json
+// @Param id path string true "Trip ID"
+// @Success 201 {object} domain.SuccessResponse
+// @Failure 403 {object} domain.ErrorResponse
+// @Failure 409 {object} domain.ErrorResponse
+// @Router /trips/{id}/share [post]
+// @Security BearerAuth
+func (h *SafetyHandler) ShareTrip(w http.ResponseWriter, r *http.Request) {
+	tripID, userID, ok := chatParticipant(w, r)
+	if !ok {
+		return
+	}
+
+	link, err := h.safetyService.ShareTrip(r.Context(), tripID, userID)
+	if err != nil {
+		handleSafetyError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusCreated, "Share link created successfully", link)
+}
+
+// RevokeShare godoc
+// @Summary Stop a trip's share link working (rider or assigned driver)
+// @Tags safety
+// @Produce json
+// @Param id path string true "Trip ID"
+// @Param link_id path string true "Share link ID"
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 403 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Router /trips/{id}/share/{link_id} [delete]
+// @Security BearerAuth
+func (h *SafetyHandler) RevokeShare(w http.ResponseWriter, r *http.Request) {
+	tripID, userID, ok := chatParticipant(w, r)
+	if !ok {
+		return
+	}
+
+	linkID, err := utils.ParseUUID(mux.Vars(r)["link_id"])
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid share link ID")
+		return
+	}
+
+	if err := h.safetyService.RevokeShare(r.Context(), tripID, linkID, userID); err != nil {
+		handleSafetyError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Share link revoked successfully", nil)
+}
+
+// GetSharedTrip godoc
+// @Summary Follow a shared trip (no account needed)
+// @Tags safety
+// @Produce json
+// @Param token path string true "Share token"
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 410 {object} domain.ErrorResponse
+// @Router /shared-trips/{token} [get]
+func (h *SafetyHandler) GetSharedTrip(w http.ResponseWriter, r *http.Request) {
+	trip, err := h.safetyService.GetSharedTrip(r.Context(), mux.Vars(r)["token"])
+	if err != nil {
+		handleSafetyError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Trip retrieved successfully", trip)
+}
+
+// ListIncidents godoc
+// @Summary Get the safety team's queue of incidents, oldest first (admin)
+// @Tags safety
+// @Produce json
+// @Param status query string false "open, acknowledged or resolved"
+// @Param limit query int false "Page size" default(50)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 400 {object} domain.ErrorResponse
+// @Router /safety/incidents [get]
+// @Security BearerAuth
+func (h *SafetyHandler) ListIncidents(w http.ResponseWriter, r *http.Request) {
+	incidents, err := h.safetyService.ListIncidents(r.Context(), r.URL.Query().Get("status"), queryInt(r, "limit", 50), queryInt(r, "offset", 0))
+	if err != nil {
+		handleSafetyError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Safety incidents retrieved successfully", incidents)
+}
+
+// GetIncident godoc
+// @Summary Get a safety incident with its trip snapshot (admin)
+// @Tags safety
+// @Produce json
+// @Param id path string true "Incident ID"
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Router /safety/incidents/{id} [get]
+// @Security BearerAuth
+func (h *SafetyHandler) GetIncident(w http.ResponseWriter, r *http.Request) {
+	incidentID, err := utils.ParseUUID(mux.Vars(r)["id"])
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid incident ID")
+		return
+	}
+
+	incident, err := h.safetyService.GetIncident(r.Context(), incidentID)
+	if err != nil {
+		handleSafetyError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Safety incident retrieved successfully", incident)
+}
+
+// AcknowledgeIncident godoc
+// @Summary Take an open safety incident (admin)
+// @Tags safety
+// @Produce json
+// @Param id path string true "Incident ID"
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 409 {object} domain.ErrorResponse
+// @Router /safety/incidents/{id}/acknowledge [post]
+// @Security BearerAuth
+func (h *SafetyHandler) AcknowledgeIncident(w http.ResponseWriter, r *http.Request) {
+	incidentID, adminID, ok := safetyIncidentAdmin(w, r)
+	if !ok {
+		return
+	}
+
+	incident, err := h.safetyService.AcknowledgeIncident(r.Context(), incidentID, adminID)
+	if err != nil {
+		handleSafetyError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Safety incident acknowledged", incident)
+}
+
+// ResolveIncident godoc
+// @Summary Close a safety incident with a note of what was done (admin)
+// @Tags safety
+// @Accept json
+// @Produce json
+// @Param id path string true "Incident ID"
+// @Param request body domain.ResolveSafetyIncidentRequest true "Resolution"
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 409 {object} domain.ErrorResponse
+// @Router /safety/incidents/{id}/resolve [post]
+// @Security BearerAuth
+func (h *SafetyHandler) ResolveIncident(w http.ResponseWriter, r *http.Request) {
+	incidentID, adminID, ok := safetyIncidentAdmin(w, r)
+	if !ok {
+		return
+	}
+
+	var req domain.ResolveSafetyIncidentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	incident, err := h.safetyService.ResolveIncident(r.Context(), incidentID, adminID, &req)
+	if err != nil {
+		handleSafetyError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Safety incident resolved", incident)
+}
+
+func safetyUser(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	userIDStr := r.Context().Value("user_id").(string)
+	userID, err := utils.ParseUUID(userIDStr)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid user ID")
+		return uuid.Nil, false
+	}
+	return userID, true
+}
+
+func safetyIncidentAdmin(w http.ResponseWriter, r *http.Request) (uuid.UUID, uuid.UUID, bool) {
+	incidentID, err := utils.ParseUUID(mux.Vars(r)["id"])
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid incident ID")
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	adminID, ok := safetyUser(w, r)
+	return incidentID, adminID, ok
+}
+
+func handleSafetyError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidEmergencyContact),
+		errors.Is(err, service.ErrInvalidSOS),
+		errors.Is(err, service.ErrInvalidIncidentStatus),
+		errors.Is(err, service.ErrResolutionNoteRequired):
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, service.ErrNotTripParticipant):
+		utils.ErrorResponse(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, service.ErrTripNotFound),
+		errors.Is(err, service.ErrEmergencyContactNotFound),
+		errors.Is(err, service.ErrShareLinkNotFound),
+		errors.Is(err, service.ErrIncidentNotFound):
+		utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrTooManyEmergencyContacts),
+		errors.Is(err, service.ErrDuplicateEmergencyContact),
+		errors.Is(err, service.ErrTripNotUnderWay),
+		errors.Is(err, service.ErrIncidentNotOpen),
+		errors.Is(err, service.ErrIncidentResolved):
+		utils.ErrorResponse(w, http.StatusConflict, err.Error())
+	case errors.Is(err, service.ErrShareLinkExpired):
+		utils.ErrorResponse(w, http.StatusGone, err.Error())
+	default:
+		utils.HandleServiceError(w, err)
+	}
+}
@@ -0,0 +1,180 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/namycodes/yanga-services/services/trip-service/internal/service"
+	"github.com/namycodes/yanga-services/shared-lib/utils"
+)
+
+type ScheduledTripHandler struct {
+	scheduledTripService *service.ScheduledTripService
+}
+
+func NewScheduledTripHandler(scheduledTripService *service.ScheduledTripService) *ScheduledTripHandler {
+	return &ScheduledTripHandler{
+		scheduledTripService: scheduledTripService,
+	}
+}
+
+// GetScheduledTrips godoc
+// @Summary Get the current rider's upcoming scheduled trips
+// @Tags scheduled-trips
+// @Produce json
+// @Param limit query int false "Limit" default(20)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {object} domain.SuccessResponse
+// @Router /trips/scheduled [get]
+// @Security BearerAuth
+func (h *ScheduledTripHandler) GetScheduledTrips(w http.ResponseWriter, r *http.Request) {
+	userIDStr := r.Context().Value("user_id").(string)
+	userID, err := utils.ParseUUID(userIDStr)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	trips, err := h.scheduledTripService.GetUserScheduledTrips(r.Context(), userID, queryInt(r, "limit", 20), queryInt(r, "offset", 0))
+	if err != nil {
+		handleScheduledTripError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Scheduled trips retrieved successfully", trips)
+}
+
+// GetAvailableTrips godoc
+// @Summary List upcoming scheduled trips open for reservation (driver)
+// @Description Drivers assigned to a city only see trips in it
+// @Tags scheduled-trips
+// @Produce json
+// @Param limit query int false "Limit" default(20)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {object} domain.SuccessResponse
+// @Router /trips/scheduled/available [get]
+// @Security BearerAuth
+func (h *ScheduledTripHandler) GetAvailableTrips(w http.ResponseWriter, r *http.Request) {
+	driverIDStr := r.Context().Value("user_id").(string)
+	driverID, err := utils.ParseUUID(driverIDStr)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	trips, err := h.scheduledTripService.GetAvailableTrips(r.Context(), driverID, queryInt(r, "limit", 20), queryInt(r, "offset", 0))
+	if err != nil {
+		handleScheduledTripError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Available scheduled trips retrieved successfully", trips)
+}
+
+// GetReservedTrips godoc
+// @Summary List the scheduled trips the current driver has reserved (driver)
+// @Tags scheduled-trips
+// @Produce json
+// @Success 200 {object} domain.SuccessResponse
+// @Router /trips/scheduled/reserved [get]
+// @Security BearerAuth
+func (h *ScheduledTripHandler) GetReservedTrips(w http.ResponseWriter, r *http.Request) {
+	driverIDStr := r.Context().Value("user_id").(string)
+	driverID, err := utils.ParseUUID(driverIDStr)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	trips, err := h.scheduledTripService.GetReservedTrips(r.Context(), driverID)
+	if err != nil {
+		handleScheduledTripError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Reserved trips retrieved successfully", trips)
+}
+
+// ReserveTrip godoc
+// @Summary Reserve a scheduled trip in advance (driver)
+// @Tags scheduled-trips
+// @Produce json
+// @Param id path string true "Trip ID"
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 409 {object} domain.ErrorResponse
+// @Router /trips/{id}/reservation [post]
+// @Security BearerAuth
+func (h *ScheduledTripHandler) ReserveTrip(w http.ResponseWriter, r *http.Request) {
+	tripID, err := utils.ParseUUID(mux.Vars(r)["id"])
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid trip ID")
+		return
+	}
+
+	driverIDStr := r.Context().Value("user_id").(string)
+	driverID, err := utils.ParseUUID(driverIDStr)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	if err := h.scheduledTripService.ReserveTrip(r.Context(), tripID, driverID); err != nil {
+		handleScheduledTripError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Trip reserved successfully", nil)
+}
+
+// ReleaseReservation godoc
+// @Summary Release a scheduled trip reservation (driver)
+// @Tags scheduled-trips
+// @Produce json
+// @Param id path string true "Trip ID"
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Router /trips/{id}/reservation [delete]
+// @Security BearerAuth
+func (h *ScheduledTripHandler) ReleaseReservation(w http.ResponseWriter, r *http.Request) {
+	tripID, err := utils.ParseUUID(mux.Vars(r)["id"])
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid trip ID")
+		return
+	}
+
+	driverIDStr := r.Context().Value("user_id").(string)
+	driverID, err := utils.ParseUUID(driverIDStr)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	if err := h.scheduledTripService.ReleaseReservation(r.Context(), tripID, driverID); err != nil {
+		handleScheduledTripError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Reservation released successfully", nil)
+}
+
+func handleScheduledTripError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrCannotReserveOwnTrip):
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, service.ErrVehicleNotEligible),
+		errors.Is(err, service.ErrDriverOutOfCity):
+		utils.ErrorResponse(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, service.ErrTripNotFound),
+		errors.Is(err, service.ErrReservationNotFound):
+		utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrTripNotScheduled),
+		errors.Is(err, service.ErrTripAlreadyReserved),
+		errors.Is(err, service.ErrReservationConflict),
+		errors.Is(err, service.ErrPickupTimePassed):
+		utils.ErrorResponse(w, http.StatusConflict, err.Error())
+	default:
+		utils.HandleServiceError(w, err)
+	}
+}
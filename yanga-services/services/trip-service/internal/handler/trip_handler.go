@@ -2,11 +2,13 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 
 	"github.com/gorilla/mux"
 	"github.com/namycodes/yanga-services/services/trip-service/internal/service"
 	"github.com/namycodes/yanga-services/shared-lib/domain"
+	"github.com/namycodes/yanga-services/shared-lib/middleware"
 	"github.com/namycodes/yanga-services/shared-lib/utils"
 )
 
@@ -47,13 +49,48 @@ func (h *TripHandler) CreateTrip(w http.ResponseWriter, r *http.Request) {
 
 	trip, err := h.tripService.CreateTrip(r.Context(), userID, &req)
 	if err != nil {
-		utils.HandleServiceError(w, err)
+		handlePromotionError(w, err)
 		return
 	}
 
 	utils.SuccessResponse(w, http.StatusCreated, "Trip created successfully", trip)
 }
 
+// QuoteFare godoc
+// @Summary Get a fare quote with any applicable promotion
+// @Tags trips
+// @Accept json
+// @Produce json
+// @Param request body domain.FareQuoteRequest true "Trip locations and optional promo code"
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 409 {object} domain.ErrorResponse
+// @Router /trips/quote [post]
+// @Security BearerAuth
+func (h *TripHandler) QuoteFare(w http.ResponseWriter, r *http.Request) {
+	var req domain.FareQuoteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	userIDStr := r.Context().Value("user_id").(string)
+	userID, err := utils.ParseUUID(userIDStr)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	quote, err := h.tripService.QuoteFare(r.Context(), userID, &req)
+	if err != nil {
+		handlePromotionError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Fare quoted successfully", quote)
+}
+
 // GetTrip godoc
 // @Summary Get trip by ID
 // @Tags trips
@@ -82,6 +119,41 @@ func (h *TripHandler) GetTrip(w http.ResponseWriter, r *http.Request) {
 	utils.SuccessResponse(w, http.StatusOK, "Trip retrieved successfully", trip)
 }
 
+// GetTripETA godoc
+// @Summary Estimate the driver's arrival (rider, assigned driver or admin)
+// @Description Before the trip starts the estimate is to the pickup, afterwards to the dropoff
+// @Tags trips
+// @Produce json
+// @Param id path string true "Trip ID"
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 403 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 409 {object} domain.ErrorResponse
+// @Router /trips/{id}/eta [get]
+// @Security BearerAuth
+func (h *TripHandler) GetTripETA(w http.ResponseWriter, r *http.Request) {
+	tripID, err := utils.ParseUUID(mux.Vars(r)["id"])
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid trip ID")
+		return
+	}
+
+	userIDStr := r.Context().Value("user_id").(string)
+	userID, err := utils.ParseUUID(userIDStr)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	eta, err := h.tripService.GetTripETA(r.Context(), tripID, userID, middleware.GetUserRole(r.Context()))
+	if err != nil {
+		handleTripETAError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Trip ETA retrieved successfully", eta)
+}
+
 // GetUserTrips godoc
 // @Summary Get user's trips
 // @Tags trips
@@ -108,18 +180,21 @@ func (h *TripHandler) GetUserTrips(w http.ResponseWriter, r *http.Request) {
 	utils.SuccessResponse(w, http.StatusOK, "Trips retrieved successfully", trips)
 }
 
-// CancelTrip godoc
-// @Summary Cancel a trip
+// CompleteTrip godoc
+// @Summary Complete a trip (assigned driver)
+// @Description Delivery trips can only be completed once the parcel's delivery is confirmed
 // @Tags trips
 // @Accept json
 // @Produce json
 // @Param id path string true "Trip ID"
-// @Param request body domain.CancelTripRequest true "Cancellation details"
+// @Param request body domain.CompleteTripRequest true "Completion details"
 // @Success 200 {object} domain.SuccessResponse
 // @Failure 400 {object} domain.ErrorResponse
-// @Router /trips/{id}/cancel [post]
+// @Failure 401 {object} domain.ErrorResponse
+// @Failure 409 {object} domain.ErrorResponse
+// @Router /trips/{id}/complete [post]
 // @Security BearerAuth
-func (h *TripHandler) CancelTrip(w http.ResponseWriter, r *http.Request) {
+func (h *TripHandler) CompleteTrip(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	tripIDStr := vars["id"]
 
@@ -129,17 +204,50 @@ func (h *TripHandler) CancelTrip(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var req domain.CancelTripRequest
+	var req domain.CompleteTripRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
 		return
 	}
 
-	err = h.tripService.CancelTrip(r.Context(), tripID, req.Reason)
+	if req.Tip < 0 {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Tip cannot be negative")
+		return
+	}
+	if req.ActualFare < 0 {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Fare cannot be negative")
+		return
+	}
+
+	driverIDStr := r.Context().Value("user_id").(string)
+	driverID, err := utils.ParseUUID(driverIDStr)
 	if err != nil {
-		utils.HandleServiceError(w, err)
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	err = h.tripService.CompleteTrip(r.Context(), tripID, driverID, req.ActualFare, req.Tip, int32(req.ActualDuration), req.PaymentStatus)
+	if errors.Is(err, service.ErrInvalidFare) {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		handleDeliveryError(w, err)
 		return
 	}
 
-	utils.SuccessResponse(w, http.StatusOK, "Trip cancelled successfully", nil)
+	utils.SuccessResponse(w, http.StatusOK, "Trip completed successfully", nil)
+}
+
+func handleTripETAError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrNotTripParticipant):
+		utils.ErrorResponse(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, service.ErrTripNotFound):
+		utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrETAUnavailable):
+		utils.ErrorResponse(w, http.StatusConflict, err.Error())
+	default:
+		utils.HandleServiceError(w, err)
+	}
 }
@@ -0,0 +1,202 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/namycodes/yanga-services/services/trip-service/internal/service"
+	"github.com/namycodes/yanga-services/shared-lib/domain"
+	"github.com/namycodes/yanga-services/shared-lib/middleware"
+	"github.com/namycodes/yanga-services/shared-lib/utils"
+)
+
+type TripStopHandler struct {
+	tripStopService *service.TripStopService
+}
+
+func NewTripStopHandler(tripStopService *service.TripStopService) *TripStopHandler {
+	return &TripStopHandler{
+		tripStopService: tripStopService,
+	}
+}
+
+// GetStops godoc
+// @Summary Get a trip's stops and current pricing (rider, assigned driver or admin)
+// @Tags trip-stops
+// @Produce json
+// @Param id path string true "Trip ID"
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 403 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Router /trips/{id}/stops [get]
+// @Security BearerAuth
+func (h *TripStopHandler) GetStops(w http.ResponseWriter, r *http.Request) {
+	tripID, err := utils.ParseUUID(mux.Vars(r)["id"])
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid trip ID")
+		return
+	}
+
+	userIDStr := r.Context().Value("user_id").(string)
+	userID, err := utils.ParseUUID(userIDStr)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	route, err := h.tripStopService.GetRoute(r.Context(), tripID, userID, middleware.GetUserRole(r.Context()))
+	if err != nil {
+		handleTripStopError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Trip stops retrieved successfully", route)
+}
+
+// AddStop godoc
+// @Summary Add a stop to a trip (rider)
+// @Description The trip is re-priced over all legs of the new route
+// @Tags trip-stops
+// @Accept json
+// @Produce json
+// @Param id path string true "Trip ID"
+// @Param request body domain.AddTripStopRequest true "Stop details"
+// @Success 201 {object} domain.SuccessResponse
+// @Failure 400 {object} domain.ErrorResponse
+// @Failure 403 {object} domain.ErrorResponse
+// @Failure 409 {object} domain.ErrorResponse
+// @Router /trips/{id}/stops [post]
+// @Security BearerAuth
+func (h *TripStopHandler) AddStop(w http.ResponseWriter, r *http.Request) {
+	tripID, err := utils.ParseUUID(mux.Vars(r)["id"])
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid trip ID")
+		return
+	}
+
+	var req domain.AddTripStopRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	userIDStr := r.Context().Value("user_id").(string)
+	userID, err := utils.ParseUUID(userIDStr)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	route, err := h.tripStopService.AddStop(r.Context(), tripID, userID, &req)
+	if err != nil {
+		handleTripStopError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusCreated, "Stop added successfully", route)
+}
+
+// RemoveStop godoc
+// @Summary Remove a stop that has not been reached yet (rider)
+// @Tags trip-stops
+// @Produce json
+// @Param id path string true "Trip ID"
+// @Param stop_id path string true "Stop ID"
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 403 {object} domain.ErrorResponse
+// @Failure 404 {object} domain.ErrorResponse
+// @Failure 409 {object} domain.ErrorResponse
+// @Router /trips/{id}/stops/{stop_id} [delete]
+// @Security BearerAuth
+func (h *TripStopHandler) RemoveStop(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	tripID, err := utils.ParseUUID(vars["id"])
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid trip ID")
+		return
+	}
+	stopID, err := utils.ParseUUID(vars["stop_id"])
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid stop ID")
+		return
+	}
+
+	userIDStr := r.Context().Value("user_id").(string)
+	userID, err := utils.ParseUUID(userIDStr)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	route, err := h.tripStopService.RemoveStop(r.Context(), tripID, stopID, userID)
+	if err != nil {
+		handleTripStopError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Stop removed successfully", route)
+}
+
+// MarkStopArrived godoc
+// @Summary Mark a stop as reached (assigned driver)
+// @Tags trip-stops
+// @Produce json
+// @Param id path string true "Trip ID"
+// @Param stop_id path string true "Stop ID"
+// @Success 200 {object} domain.SuccessResponse
+// @Failure 403 {object} domain.ErrorResponse
+// @Failure 409 {object} domain.ErrorResponse
+// @Router /trips/{id}/stops/{stop_id}/arrived [post]
+// @Security BearerAuth
+func (h *TripStopHandler) MarkStopArrived(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	tripID, err := utils.ParseUUID(vars["id"])
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid trip ID")
+		return
+	}
+	stopID, err := utils.ParseUUID(vars["stop_id"])
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid stop ID")
+		return
+	}
+
+	driverIDStr := r.Context().Value("user_id").(string)
+	driverID, err := utils.ParseUUID(driverIDStr)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	if err := h.tripStopService.MarkStopArrived(r.Context(), tripID, stopID, driverID); err != nil {
+		handleTripStopError(w, err)
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Stop marked as reached", nil)
+}
+
+func handleTripStopError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidStop),
+		errors.Is(err, service.ErrInvalidStopPosition),
+		errors.Is(err, service.ErrOutsideServiceArea),
+		errors.Is(err, service.ErrFeatureUnavailable):
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, service.ErrNotTripParticipant):
+		utils.ErrorResponse(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, service.ErrTripNotFound),
+		errors.Is(err, service.ErrTripStopNotFound):
+		utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrTooManyStops),
+		errors.Is(err, service.ErrStopAlreadyArrived),
+		errors.Is(err, service.ErrStopOutOfOrder),
+		errors.Is(err, service.ErrTripRouteLocked),
+		errors.Is(err, service.ErrTripNotInProgress):
+		utils.ErrorResponse(w, http.StatusConflict, err.Error())
+	default:
+		utils.HandleServiceError(w, err)
+	}
+}
@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/namycodes/yanga-services/services/trip-service/internal/db"
+)
+
+type AirportQueueRepository struct {
+	queries *db.Queries
+}
+
+func NewAirportQueueRepository(queries *db.Queries) *AirportQueueRepository {
+	return &AirportQueueRepository{
+		queries: queries,
+	}
+}
+
+func (r *AirportQueueRepository) JoinAirportQueue(ctx context.Context, params db.JoinAirportQueueParams) (int64, error) {
+	return r.queries.JoinAirportQueue(ctx, params)
+}
+
+func (r *AirportQueueRepository) LeaveStagingArea(ctx context.Context, params db.LeaveStagingAreaParams) (int64, error) {
+	return r.queries.LeaveStagingArea(ctx, params)
+}
+
+func (r *AirportQueueRepository) LeaveAirportQueue(ctx context.Context, driverID pgtype.UUID) (int64, error) {
+	return r.queries.LeaveAirportQueue(ctx, driverID)
+}
+
+func (r *AirportQueueRepository) GetAirportQueuePosition(ctx context.Context, driverID pgtype.UUID) (db.GetAirportQueuePositionRow, error) {
+	return r.queries.GetAirportQueuePosition(ctx, driverID)
+}
+
+func (r *AirportQueueRepository) ListAirportQueue(ctx context.Context, params db.ListAirportQueueParams) ([]db.AirportQueueEntry, error) {
+	return r.queries.ListAirportQueue(ctx, params)
+}
@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/namycodes/yanga-services/services/trip-service/internal/db"
+)
+
+type CancellationRepository struct {
+	queries *db.Queries
+}
+
+func NewCancellationRepository(queries *db.Queries) *CancellationRepository {
+	return &CancellationRepository{
+		queries: queries,
+	}
+}
+
+func (r *CancellationRepository) GetCancellationPolicy(ctx context.Context, cityCode string) (db.CancellationPolicy, error) {
+	return r.queries.GetCancellationPolicy(ctx, cityCode)
+}
+
+func (r *CancellationRepository) ListCancellationPolicies(ctx context.Context) ([]db.CancellationPolicy, error) {
+	return r.queries.ListCancellationPolicies(ctx)
+}
+
+func (r *CancellationRepository) UpsertCancellationPolicy(ctx context.Context, params db.UpsertCancellationPolicyParams) (db.CancellationPolicy, error) {
+	return r.queries.UpsertCancellationPolicy(ctx, params)
+}
+
+func (r *CancellationRepository) DeleteCancellationPolicy(ctx context.Context, cityCode string) (int64, error) {
+	return r.queries.DeleteCancellationPolicy(ctx, cityCode)
+}
+
+func (r *CancellationRepository) GetExpiredPendingTrips(ctx context.Context, limit int32) ([]db.Trip, error) {
+	return r.queries.GetExpiredPendingTrips(ctx, limit)
+}
+
+func (r *CancellationRepository) GetDriverLocation(ctx context.Context, driverID pgtype.UUID) (db.GetDriverLocationRow, error) {
+	return r.queries.GetDriverLocation(ctx, driverID)
+}
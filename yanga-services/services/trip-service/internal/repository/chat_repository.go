@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/namycodes/yanga-services/services/trip-service/internal/db"
+)
+
+type ChatRepository struct {
+	queries *db.Queries
+}
+
+func NewChatRepository(queries *db.Queries) *ChatRepository {
+	return &ChatRepository{
+		queries: queries,
+	}
+}
+
+func (r *ChatRepository) CreateTripMessage(ctx context.Context, params db.CreateTripMessageParams) (db.TripMessage, error) {
+	return r.queries.CreateTripMessage(ctx, params)
+}
+
+func (r *ChatRepository) GetTripMessage(ctx context.Context, params db.GetTripMessageParams) (db.TripMessage, error) {
+	return r.queries.GetTripMessage(ctx, params)
+}
+
+func (r *ChatRepository) GetTripMessageByClientID(ctx context.Context, params db.GetTripMessageByClientIDParams) (db.TripMessage, error) {
+	return r.queries.GetTripMessageByClientID(ctx, params)
+}
+
+func (r *ChatRepository) ListTripMessages(ctx context.Context, params db.ListTripMessagesParams) ([]db.TripMessage, error) {
+	return r.queries.ListTripMessages(ctx, params)
+}
+
+func (r *ChatRepository) MarkTripMessagesRead(ctx context.Context, params db.MarkTripMessagesReadParams) (int64, error) {
+	return r.queries.MarkTripMessagesRead(ctx, params)
+}
+
+func (r *ChatRepository) GetTripParticipantPhones(ctx context.Context, tripID pgtype.UUID) (db.GetTripParticipantPhonesRow, error) {
+	return r.queries.GetTripParticipantPhones(ctx, tripID)
+}
+
+func (r *ChatRepository) GetOpenTripCallSession(ctx context.Context, tripID pgtype.UUID) (db.TripCallSession, error) {
+	return r.queries.GetOpenTripCallSession(ctx, tripID)
+}
+
+func (r *ChatRepository) CreateTripCallSession(ctx context.Context, params db.CreateTripCallSessionParams) (db.TripCallSession, error) {
+	return r.queries.CreateTripCallSession(ctx, params)
+}
+
+func (r *ChatRepository) CloseTripCallSession(ctx context.Context, id pgtype.UUID) error {
+	return r.queries.CloseTripCallSession(ctx, id)
+}
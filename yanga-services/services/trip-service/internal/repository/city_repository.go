@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/namycodes/yanga-services/services/trip-service/internal/db"
+)
+
+type CityRepository struct {
+	queries *db.Queries
+}
+
+func NewCityRepository(queries *db.Queries) *CityRepository {
+	return &CityRepository{
+		queries: queries,
+	}
+}
+
+func (r *CityRepository) GetCity(ctx context.Context, code string) (db.City, error) {
+	return r.queries.GetCity(ctx, code)
+}
+
+func (r *CityRepository) ListCities(ctx context.Context) ([]db.City, error) {
+	return r.queries.ListCities(ctx)
+}
+
+func (r *CityRepository) UpsertCity(ctx context.Context, params db.UpsertCityParams) (db.City, error) {
+	return r.queries.UpsertCity(ctx, params)
+}
+
+func (r *CityRepository) GetDriverCityCode(ctx context.Context, driverID pgtype.UUID) (pgtype.Text, error) {
+	return r.queries.GetDriverCityCode(ctx, driverID)
+}
@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/namycodes/yanga-services/services/trip-service/internal/db"
+)
+
+type GeofenceRepository struct {
+	queries *db.Queries
+}
+
+func NewGeofenceRepository(queries *db.Queries) *GeofenceRepository {
+	return &GeofenceRepository{
+		queries: queries,
+	}
+}
+
+func (r *GeofenceRepository) CreateGeofence(ctx context.Context, params db.CreateGeofenceParams) (db.Geofence, error) {
+	return r.queries.CreateGeofence(ctx, params)
+}
+
+func (r *GeofenceRepository) GetGeofence(ctx context.Context, id pgtype.UUID) (db.Geofence, error) {
+	return r.queries.GetGeofence(ctx, id)
+}
+
+func (r *GeofenceRepository) ListGeofences(ctx context.Context, params db.ListGeofencesParams) ([]db.Geofence, error) {
+	return r.queries.ListGeofences(ctx, params)
+}
+
+func (r *GeofenceRepository) UpdateGeofence(ctx context.Context, params db.UpdateGeofenceParams) (db.Geofence, error) {
+	return r.queries.UpdateGeofence(ctx, params)
+}
+
+func (r *GeofenceRepository) DeleteGeofence(ctx context.Context, id pgtype.UUID) (int64, error) {
+	return r.queries.DeleteGeofence(ctx, id)
+}
+
+func (r *GeofenceRepository) GetGeofencesAround(ctx context.Context, params db.GetGeofencesAroundParams) ([]db.Geofence, error) {
+	return r.queries.GetGeofencesAround(ctx, params)
+}
+
+func (r *GeofenceRepository) CountActiveServiceAreas(ctx context.Context) (int64, error) {
+	return r.queries.CountActiveServiceAreas(ctx)
+}
+
+func (r *GeofenceRepository) GetDriverGeofences(ctx context.Context, driverID pgtype.UUID) ([]db.Geofence, error) {
+	return r.queries.GetDriverGeofences(ctx, driverID)
+}
+
+func (r *GeofenceRepository) EnterGeofence(ctx context.Context, params db.EnterGeofenceParams) (int64, error) {
+	return r.queries.EnterGeofence(ctx, params)
+}
+
+func (r *GeofenceRepository) ExitGeofence(ctx context.Context, params db.ExitGeofenceParams) (int64, error) {
+	return r.queries.ExitGeofence(ctx, params)
+}
@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/namycodes/yanga-services/services/trip-service/internal/db"
+)
+
+type PlaceRepository struct {
+	queries *db.Queries
+}
+
+func NewPlaceRepository(queries *db.Queries) *PlaceRepository {
+	return &PlaceRepository{
+		queries: queries,
+	}
+}
+
+func (r *PlaceRepository) ListSavedPlaces(ctx context.Context, userID pgtype.UUID) ([]db.SavedPlace, error) {
+	return r.queries.ListSavedPlaces(ctx, userID)
+}
+
+func (r *PlaceRepository) CreateSavedPlace(ctx context.Context, params db.CreateSavedPlaceParams) (db.SavedPlace, error) {
+	return r.queries.CreateSavedPlace(ctx, params)
+}
+
+func (r *PlaceRepository) UpsertSavedPlace(ctx context.Context, params db.UpsertSavedPlaceParams) (db.SavedPlace, error) {
+	return r.queries.UpsertSavedPlace(ctx, params)
+}
+
+func (r *PlaceRepository) CountSavedPlacesByLabel(ctx context.Context, params db.CountSavedPlacesByLabelParams) (int64, error) {
+	return r.queries.CountSavedPlacesByLabel(ctx, params)
+}
+
+func (r *PlaceRepository) DeleteSavedPlace(ctx context.Context, params db.DeleteSavedPlaceParams) (int64, error) {
+	return r.queries.DeleteSavedPlace(ctx, params)
+}
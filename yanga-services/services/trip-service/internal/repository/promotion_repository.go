@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/namycodes/yanga-services/services/trip-service/internal/db"
+)
+
+type PromotionRepository struct {
+	queries *db.Queries
+}
+
+func NewPromotionRepository(queries *db.Queries) *PromotionRepository {
+	return &PromotionRepository{
+		queries: queries,
+	}
+}
+
+// IsUniqueViolation reports whether err is a Postgres unique constraint
+// violation.
+func IsUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		// 23505 unique_violation
+		return pgErr.Code == "23505"
+	}
+	return false
+}
+
+// IsForeignKeyViolation reports whether err is a Postgres foreign key
+// violation, such as a row naming a city that doesn't exist.
+func IsForeignKeyViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		// 23503 foreign_key_violation
+		return pgErr.Code == "23503"
+	}
+	return false
+}
+
+func (r *PromotionRepository) CreatePromoCode(ctx context.Context, params db.CreatePromoCodeParams) (db.PromoCode, error) {
+	return r.queries.CreatePromoCode(ctx, params)
+}
+
+func (r *PromotionRepository) GetPromoCode(ctx context.Context, id pgtype.UUID) (db.PromoCode, error) {
+	return r.queries.GetPromoCode(ctx, id)
+}
+
+func (r *PromotionRepository) GetPromoCodeByCode(ctx context.Context, code string) (db.PromoCode, error) {
+	return r.queries.GetPromoCodeByCode(ctx, code)
+}
+
+func (r *PromotionRepository) ListPromoCodes(ctx context.Context, params db.ListPromoCodesParams) ([]db.PromoCode, error) {
+	return r.queries.ListPromoCodes(ctx, params)
+}
+
+func (r *PromotionRepository) UpdatePromoCodeStatus(ctx context.Context, params db.UpdatePromoCodeStatusParams) (db.PromoCode, error) {
+	return r.queries.UpdatePromoCodeStatus(ctx, params)
+}
+
+func (r *PromotionRepository) GetAutoApplyPromoCodes(ctx context.Context, at pgtype.Timestamp) ([]db.PromoCode, error) {
+	return r.queries.GetAutoApplyPromoCodes(ctx, at)
+}
+
+func (r *PromotionRepository) CountUserPromoRedemptions(ctx context.Context, params db.CountUserPromoRedemptionsParams) (int64, error) {
+	return r.queries.CountUserPromoRedemptions(ctx, params)
+}
+
+func (r *PromotionRepository) CreateReferralCode(ctx context.Context, params db.CreateReferralCodeParams) (db.ReferralCode, error) {
+	return r.queries.CreateReferralCode(ctx, params)
+}
+
+func (r *PromotionRepository) GetReferralCodeByUser(ctx context.Context, userID pgtype.UUID) (db.ReferralCode, error) {
+	return r.queries.GetReferralCodeByUser(ctx, userID)
+}
+
+func (r *PromotionRepository) GetReferralCodeByCode(ctx context.Context, code string) (db.ReferralCode, error) {
+	return r.queries.GetReferralCodeByCode(ctx, code)
+}
+
+func (r *PromotionRepository) CreateReferral(ctx context.Context, params db.CreateReferralParams) (db.Referral, error) {
+	return r.queries.CreateReferral(ctx, params)
+}
+
+func (r *PromotionRepository) GetReferralStats(ctx context.Context, referrerID pgtype.UUID) (db.GetReferralStatsRow, error) {
+	return r.queries.GetReferralStats(ctx, referrerID)
+}
@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/namycodes/yanga-services/services/trip-service/internal/db"
+)
+
+type SafetyRepository struct {
+	queries *db.Queries
+}
+
+func NewSafetyRepository(queries *db.Queries) *SafetyRepository {
+	return &SafetyRepository{
+		queries: queries,
+	}
+}
+
+func (r *SafetyRepository) ListEmergencyContacts(ctx context.Context, userID pgtype.UUID) ([]db.EmergencyContact, error) {
+	return r.queries.ListEmergencyContacts(ctx, userID)
+}
+
+func (r *SafetyRepository) CountEmergencyContacts(ctx context.Context, userID pgtype.UUID) (int64, error) {
+	return r.queries.CountEmergencyContacts(ctx, userID)
+}
+
+func (r *SafetyRepository) CreateEmergencyContact(ctx context.Context, params db.CreateEmergencyContactParams) (db.EmergencyContact, error) {
+	return r.queries.CreateEmergencyContact(ctx, params)
+}
+
+func (r *SafetyRepository) DeleteEmergencyContact(ctx context.Context, params db.DeleteEmergencyContactParams) (int64, error) {
+	return r.queries.DeleteEmergencyContact(ctx, params)
+}
+
+func (r *SafetyRepository) CreateTripShareLink(ctx context.Context, params db.CreateTripShareLinkParams) (db.TripShareLink, error) {
+	return r.queries.CreateTripShareLink(ctx, params)
+}
+
+func (r *SafetyRepository) GetTripShareLinkByToken(ctx context.Context, tokenHash string) (db.TripShareLink, error) {
+	return r.queries.GetTripShareLinkByToken(ctx, tokenHash)
+}
+
+func (r *SafetyRepository) RevokeTripShareLink(ctx context.Context, params db.RevokeTripShareLinkParams) (int64, error) {
+	return r.queries.RevokeTripShareLink(ctx, params)
+}
+
+func (r *SafetyRepository) GetSafetyDriver(ctx context.Context, id pgtype.UUID) (db.GetSafetyDriverRow, error) {
+	return r.queries.GetSafetyDriver(ctx, id)
+}
+
+func (r *SafetyRepository) CreateSafetyIncident(ctx context.Context, params db.CreateSafetyIncidentParams) (db.SafetyIncident, error) {
+	return r.queries.CreateSafetyIncident(ctx, params)
+}
+
+func (r *SafetyRepository) GetUnresolvedSafetyIncident(ctx context.Context, params db.GetUnresolvedSafetyIncidentParams) (db.SafetyIncident, error) {
+	return r.queries.GetUnresolvedSafetyIncident(ctx, params)
+}
+
+func (r *SafetyRepository) GetSafetyIncident(ctx context.Context, id pgtype.UUID) (db.SafetyIncident, error) {
+	return r.queries.GetSafetyIncident(ctx, id)
+}
+
+func (r *SafetyRepository) ListSafetyIncidents(ctx context.Context, params db.ListSafetyIncidentsParams) ([]db.SafetyIncident, error) {
+	return r.queries.ListSafetyIncidents(ctx, params)
+}
+
+func (r *SafetyRepository) AcknowledgeSafetyIncident(ctx context.Context, params db.AcknowledgeSafetyIncidentParams) (db.SafetyIncident, error) {
+	return r.queries.AcknowledgeSafetyIncident(ctx, params)
+}
+
+func (r *SafetyRepository) ResolveSafetyIncident(ctx context.Context, params db.ResolveSafetyIncidentParams) (db.SafetyIncident, error) {
+	return r.queries.ResolveSafetyIncident(ctx, params)
+}
+
+func (r *SafetyRepository) GetTripSafetyTrack(ctx context.Context, tripID pgtype.UUID) (db.TripSafetyTrack, error) {
+	return r.queries.GetTripSafetyTrack(ctx, tripID)
+}
+
+func (r *SafetyRepository) SaveTripSafetyTrack(ctx context.Context, params db.SaveTripSafetyTrackParams) error {
+	return r.queries.SaveTripSafetyTrack(ctx, params)
+}
+
+func (r *SafetyRepository) ClearTripPlannedRoute(ctx context.Context, tripID pgtype.UUID) error {
+	return r.queries.ClearTripPlannedRoute(ctx, tripID)
+}
+
+func (r *SafetyRepository) ListTrackedTrips(ctx context.Context) ([]db.ListTrackedTripsRow, error) {
+	return r.queries.ListTrackedTrips(ctx)
+}
@@ -3,20 +3,43 @@ package repository
 import (
 	"context"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/namycodes/yanga-services/services/trip-service/internal/db"
 )
 
+// TxBeginner starts transactions, as a *pgxpool.Pool does.
+type TxBeginner interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
 type TripRepository struct {
+	pool    TxBeginner
 	queries *db.Queries
 }
 
-func NewTripRepository(queries *db.Queries) *TripRepository {
+func NewTripRepository(pool TxBeginner, queries *db.Queries) *TripRepository {
 	return &TripRepository{
+		pool:    pool,
 		queries: queries,
 	}
 }
 
+// WithTx runs fn inside a database transaction, committing only if fn
+// returns nil.
+func (r *TripRepository) WithTx(ctx context.Context, fn func(q *db.Queries) error) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := fn(r.queries.WithTx(tx)); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
 func (r *TripRepository) CreateTrip(ctx context.Context, params db.CreateTripParams) (db.Trip, error) {
 	return r.queries.CreateTrip(ctx, params)
 }
@@ -45,7 +68,11 @@ func (r *TripRepository) CompleteTrip(ctx context.Context, params db.CompleteTri
 	return r.queries.CompleteTrip(ctx, params)
 }
 
-func (r *TripRepository) CancelTrip(ctx context.Context, params db.CancelTripParams) error {
+func (r *TripRepository) MarkDriverArrived(ctx context.Context, id pgtype.UUID) (int64, error) {
+	return r.queries.MarkDriverArrived(ctx, id)
+}
+
+func (r *TripRepository) CancelTrip(ctx context.Context, params db.CancelTripParams) (int64, error) {
 	return r.queries.CancelTrip(ctx, params)
 }
 
@@ -56,3 +83,99 @@ func (r *TripRepository) GetActiveTrip(ctx context.Context, userID pgtype.UUID)
 func (r *TripRepository) GetDriverActiveTrip(ctx context.Context, driverID pgtype.UUID) (db.Trip, error) {
 	return r.queries.GetDriverActiveTrip(ctx, driverID)
 }
+
+func (r *TripRepository) UpdateTripPaymentStatus(ctx context.Context, params db.UpdateTripPaymentStatusParams) error {
+	return r.queries.UpdateTripPaymentStatus(ctx, params)
+}
+
+func (r *TripRepository) CountUserCompletedTrips(ctx context.Context, userID pgtype.UUID) (int64, error) {
+	return r.queries.CountUserCompletedTrips(ctx, userID)
+}
+
+func (r *TripRepository) GetUserScheduledTrips(ctx context.Context, params db.GetUserScheduledTripsParams) ([]db.Trip, error) {
+	return r.queries.GetUserScheduledTrips(ctx, params)
+}
+
+func (r *TripRepository) GetAvailableScheduledTrips(ctx context.Context, params db.GetAvailableScheduledTripsParams) ([]db.Trip, error) {
+	return r.queries.GetAvailableScheduledTrips(ctx, params)
+}
+
+func (r *TripRepository) GetDriverReservedTrips(ctx context.Context, driverID pgtype.UUID) ([]db.Trip, error) {
+	return r.queries.GetDriverReservedTrips(ctx, driverID)
+}
+
+func (r *TripRepository) ReserveScheduledTrip(ctx context.Context, params db.ReserveScheduledTripParams) (int64, error) {
+	return r.queries.ReserveScheduledTrip(ctx, params)
+}
+
+func (r *TripRepository) ReleaseScheduledTrip(ctx context.Context, params db.ReleaseScheduledTripParams) (int64, error) {
+	return r.queries.ReleaseScheduledTrip(ctx, params)
+}
+
+func (r *TripRepository) GetTripsDueForReminder(ctx context.Context, params db.GetTripsDueForReminderParams) ([]db.Trip, error) {
+	return r.queries.GetTripsDueForReminder(ctx, params)
+}
+
+func (r *TripRepository) MarkTripReminderSent(ctx context.Context, id pgtype.UUID) (int64, error) {
+	return r.queries.MarkTripReminderSent(ctx, id)
+}
+
+func (r *TripRepository) GetTripsDueForDispatch(ctx context.Context, params db.GetTripsDueForDispatchParams) ([]db.Trip, error) {
+	return r.queries.GetTripsDueForDispatch(ctx, params)
+}
+
+func (r *TripRepository) DispatchScheduledTrip(ctx context.Context, id pgtype.UUID) (db.Trip, error) {
+	return r.queries.DispatchScheduledTrip(ctx, id)
+}
+
+func (r *TripRepository) GetTripStops(ctx context.Context, tripID pgtype.UUID) ([]db.TripStop, error) {
+	return r.queries.GetTripStops(ctx, tripID)
+}
+
+func (r *TripRepository) MarkTripStopArrived(ctx context.Context, params db.MarkTripStopArrivedParams) (int64, error) {
+	return r.queries.MarkTripStopArrived(ctx, params)
+}
+
+func (r *TripRepository) GetTripPool(ctx context.Context, id pgtype.UUID) (db.TripPool, error) {
+	return r.queries.GetTripPool(ctx, id)
+}
+
+func (r *TripRepository) GetPoolTrips(ctx context.Context, poolID pgtype.UUID) ([]db.Trip, error) {
+	return r.queries.GetPoolTrips(ctx, poolID)
+}
+
+func (r *TripRepository) GetPoolWaypoints(ctx context.Context, poolID pgtype.UUID) ([]db.PoolWaypoint, error) {
+	return r.queries.GetPoolWaypoints(ctx, poolID)
+}
+
+func (r *TripRepository) SetTripPoolDriver(ctx context.Context, params db.SetTripPoolDriverParams) (int64, error) {
+	return r.queries.SetTripPoolDriver(ctx, params)
+}
+
+func (r *TripRepository) CompletePoolWaypoint(ctx context.Context, params db.CompletePoolWaypointParams) error {
+	return r.queries.CompletePoolWaypoint(ctx, params)
+}
+
+func (r *TripRepository) GetParcelDelivery(ctx context.Context, tripID pgtype.UUID) (db.ParcelDelivery, error) {
+	return r.queries.GetParcelDelivery(ctx, tripID)
+}
+
+func (r *TripRepository) RecordParcelPickup(ctx context.Context, params db.RecordParcelPickupParams) (int64, error) {
+	return r.queries.RecordParcelPickup(ctx, params)
+}
+
+func (r *TripRepository) RecordParcelDelivery(ctx context.Context, params db.RecordParcelDeliveryParams) (int64, error) {
+	return r.queries.RecordParcelDelivery(ctx, params)
+}
+
+func (r *TripRepository) IncrementParcelPinAttempts(ctx context.Context, tripID pgtype.UUID) (int32, error) {
+	return r.queries.IncrementParcelPinAttempts(ctx, tripID)
+}
+
+func (r *TripRepository) GetDriverLocation(ctx context.Context, driverID pgtype.UUID) (db.GetDriverLocationRow, error) {
+	return r.queries.GetDriverLocation(ctx, driverID)
+}
+
+func (r *TripRepository) GetDriverVehicleType(ctx context.Context, driverID pgtype.UUID) (string, error) {
+	return r.queries.GetDriverVehicleType(ctx, driverID)
+}
@@ -8,16 +8,144 @@ import (
 	httpSwagger "github.com/swaggo/http-swagger"
 )
 
-func SetupTripRoutes(router *mux.Router, tripHandler *handler.TripHandler, jwtCfg config.JWTConfig) {
+func SetupTripRoutes(router *mux.Router, tripHandler *handler.TripHandler, promotionHandler *handler.PromotionHandler, cancellationHandler *handler.CancellationHandler, scheduledTripHandler *handler.ScheduledTripHandler, tripStopHandler *handler.TripStopHandler, poolHandler *handler.PoolHandler, deliveryHandler *handler.DeliveryHandler, placeHandler *handler.PlaceHandler, geofenceHandler *handler.GeofenceHandler, cityHandler *handler.CityHandler, airportQueueHandler *handler.AirportQueueHandler, chatHandler *handler.ChatHandler, safetyHandler *handler.SafetyHandler, jwtCfg config.JWTConfig) {
 	api := router.PathPrefix("/api/v1").Subrouter()
 
 	trips := api.PathPrefix("/trips").Subrouter()
-	trips.Use(middleware.AuthMiddleware(jwtCfg.Secret))
+	trips.Use(middleware.AuthMiddleware(jwtCfg.Secret, jwtCfg.IdentitySecret))
 
 	trips.HandleFunc("", tripHandler.CreateTrip).Methods("POST")
+	trips.HandleFunc("/quote", tripHandler.QuoteFare).Methods("POST")
+	trips.HandleFunc("/scheduled", scheduledTripHandler.GetScheduledTrips).Methods("GET")
 	trips.HandleFunc("/{id}", tripHandler.GetTrip).Methods("GET")
 	trips.HandleFunc("/user", tripHandler.GetUserTrips).Methods("GET")
-	trips.HandleFunc("/{id}/cancel", tripHandler.CancelTrip).Methods("POST")
+	trips.HandleFunc("/{id}/cancel", cancellationHandler.CancelTrip).Methods("POST")
+	trips.HandleFunc("/{id}/arrived", cancellationHandler.MarkDriverArrived).Methods("POST")
+	trips.HandleFunc("/{id}/no-show", cancellationHandler.ReportRiderNoShow).Methods("POST")
+	trips.HandleFunc("/{id}/complete", tripHandler.CompleteTrip).Methods("POST")
+	trips.HandleFunc("/{id}/stops", tripStopHandler.GetStops).Methods("GET")
+	trips.HandleFunc("/{id}/stops", tripStopHandler.AddStop).Methods("POST")
+	trips.HandleFunc("/{id}/stops/{stop_id}", tripStopHandler.RemoveStop).Methods("DELETE")
+	trips.HandleFunc("/{id}/stops/{stop_id}/arrived", tripStopHandler.MarkStopArrived).Methods("POST")
+	trips.HandleFunc("/{id}/eta", tripHandler.GetTripETA).Methods("GET")
+	trips.HandleFunc("/{id}/pool", poolHandler.GetPool).Methods("GET")
+	trips.HandleFunc("/{id}/delivery", deliveryHandler.GetDelivery).Methods("GET")
+
+	// Chat and masked calling between rider and driver
+	trips.HandleFunc("/chat/quick-replies", chatHandler.GetQuickReplies).Methods("GET")
+	trips.HandleFunc("/{id}/chat", chatHandler.Chat).Methods("GET")
+	trips.HandleFunc("/{id}/messages", chatHandler.GetMessages).Methods("GET")
+	trips.HandleFunc("/{id}/messages", chatHandler.SendMessage).Methods("POST")
+	trips.HandleFunc("/{id}/messages/read", chatHandler.MarkRead).Methods("POST")
+	trips.HandleFunc("/{id}/call", chatHandler.OpenCall).Methods("POST")
+
+	// SOS and trip sharing
+	trips.HandleFunc("/{id}/sos", safetyHandler.RaiseSOS).Methods("POST")
+	trips.HandleFunc("/{id}/share", safetyHandler.ShareTrip).Methods("POST")
+	trips.HandleFunc("/{id}/share/{link_id}", safetyHandler.RevokeShare).Methods("DELETE")
+
+	// Proof of pickup and delivery - drivers only
+	deliveries := trips.NewRoute().Subrouter()
+	deliveries.Use(middleware.RequireRole("driver"))
+
+	deliveries.HandleFunc("/{id}/delivery/pickup", deliveryHandler.ConfirmPickup).Methods("POST")
+	deliveries.HandleFunc("/{id}/delivery/confirm", deliveryHandler.ConfirmDelivery).Methods("POST")
+
+	// Advance reservations of scheduled trips - drivers only
+	reservations := trips.NewRoute().Subrouter()
+	reservations.Use(middleware.RequireRole("driver"))
+
+	reservations.HandleFunc("/scheduled/available", scheduledTripHandler.GetAvailableTrips).Methods("GET")
+	reservations.HandleFunc("/scheduled/reserved", scheduledTripHandler.GetReservedTrips).Methods("GET")
+	reservations.HandleFunc("/{id}/reservation", scheduledTripHandler.ReserveTrip).Methods("POST")
+	reservations.HandleFunc("/{id}/reservation", scheduledTripHandler.ReleaseReservation).Methods("DELETE")
+
+	places := api.PathPrefix("/places").Subrouter()
+	places.Use(middleware.AuthMiddleware(jwtCfg.Secret, jwtCfg.IdentitySecret))
+
+	places.HandleFunc("", placeHandler.ListSavedPlaces).Methods("GET")
+	places.HandleFunc("", placeHandler.SavePlace).Methods("POST")
+	places.HandleFunc("/search", placeHandler.SearchPlaces).Methods("GET")
+	places.HandleFunc("/reverse", placeHandler.ReverseGeocode).Methods("GET")
+	places.HandleFunc("/{id}", placeHandler.DeleteSavedPlace).Methods("DELETE")
+
+	promotions := api.PathPrefix("/promotions").Subrouter()
+	promotions.Use(middleware.AuthMiddleware(jwtCfg.Secret, jwtCfg.IdentitySecret))
+
+	promotions.HandleFunc("/referral", promotionHandler.GetReferral).Methods("GET")
+
+	// Promo code management - admin only
+	admin := promotions.NewRoute().Subrouter()
+	admin.Use(middleware.RequireRole("admin"))
+
+	admin.HandleFunc("", promotionHandler.ListPromoCodes).Methods("GET")
+	admin.HandleFunc("", promotionHandler.CreatePromoCode).Methods("POST")
+	admin.HandleFunc("/{id}/status", promotionHandler.UpdatePromoCodeStatus).Methods("PUT")
+
+	// Cancellation policies - admin only
+	policies := api.PathPrefix("/cancellation-policies").Subrouter()
+	policies.Use(middleware.AuthMiddleware(jwtCfg.Secret, jwtCfg.IdentitySecret))
+	policies.Use(middleware.RequireRole("admin"))
+
+	policies.HandleFunc("", cancellationHandler.ListPolicies).Methods("GET")
+	policies.HandleFunc("/{city_code}", cancellationHandler.UpsertPolicy).Methods("PUT")
+	policies.HandleFunc("/{city_code}", cancellationHandler.DeletePolicy).Methods("DELETE")
+
+	// Service areas and zones - admin only
+	geofences := api.PathPrefix("/geofences").Subrouter()
+	geofences.Use(middleware.AuthMiddleware(jwtCfg.Secret, jwtCfg.IdentitySecret))
+	geofences.Use(middleware.RequireRole("admin"))
+
+	geofences.HandleFunc("", geofenceHandler.ListGeofences).Methods("GET")
+	geofences.HandleFunc("", geofenceHandler.CreateGeofence).Methods("POST")
+	geofences.HandleFunc("/{id}", geofenceHandler.GetGeofence).Methods("GET")
+	geofences.HandleFunc("/{id}", geofenceHandler.UpdateGeofence).Methods("PUT")
+	geofences.HandleFunc("/{id}", geofenceHandler.DeleteGeofence).Methods("DELETE")
+
+	cities := api.PathPrefix("/cities").Subrouter()
+	cities.Use(middleware.AuthMiddleware(jwtCfg.Secret, jwtCfg.IdentitySecret))
+
+	cities.HandleFunc("", cityHandler.ListCities).Methods("GET")
+	cities.HandleFunc("/{code}", cityHandler.GetCity).Methods("GET")
+
+	// City settings - admin only
+	cityAdmin := cities.NewRoute().Subrouter()
+	cityAdmin.Use(middleware.RequireRole("admin"))
+
+	cityAdmin.HandleFunc("/{code}", cityHandler.UpsertCity).Methods("PUT")
+
+	airportQueues := api.PathPrefix("/airport-queues").Subrouter()
+	airportQueues.Use(middleware.AuthMiddleware(jwtCfg.Secret, jwtCfg.IdentitySecret))
+
+	drivers := airportQueues.NewRoute().Subrouter()
+	drivers.Use(middleware.RequireRole("driver"))
+
+	drivers.HandleFunc("/me", airportQueueHandler.GetMyQueuePosition).Methods("GET")
+
+	// Queue monitoring - admin only
+	queueAdmin := airportQueues.NewRoute().Subrouter()
+	queueAdmin.Use(middleware.RequireRole("admin"))
+
+	queueAdmin.HandleFunc("/{id}", airportQueueHandler.ListAirportQueue).Methods("GET")
+
+	safety := api.PathPrefix("/safety").Subrouter()
+	safety.Use(middleware.AuthMiddleware(jwtCfg.Secret, jwtCfg.IdentitySecret))
+
+	safety.HandleFunc("/contacts", safetyHandler.ListContacts).Methods("GET")
+	safety.HandleFunc("/contacts", safetyHandler.AddContact).Methods("POST")
+	safety.HandleFunc("/contacts/{id}", safetyHandler.RemoveContact).Methods("DELETE")
+
+	// Safety incident queue - admin only
+	incidents := safety.NewRoute().Subrouter()
+	incidents.Use(middleware.RequireRole("admin"))
+
+	incidents.HandleFunc("/incidents", safetyHandler.ListIncidents).Methods("GET")
+	incidents.HandleFunc("/incidents/{id}", safetyHandler.GetIncident).Methods("GET")
+	incidents.HandleFunc("/incidents/{id}/acknowledge", safetyHandler.AcknowledgeIncident).Methods("POST")
+	incidents.HandleFunc("/incidents/{id}/resolve", safetyHandler.ResolveIncident).Methods("POST")
+
+	// Shared trips are followed without an account; the token is the key
+	api.HandleFunc("/shared-trips/{token}", safetyHandler.GetSharedTrip).Methods("GET")
 
 	router.PathPrefix("/swagger/").Handler(httpSwagger.WrapHandler)
 }
@@ -0,0 +1,247 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/namycodes/yanga-services/services/trip-service/internal/db"
+	"github.com/namycodes/yanga-services/services/trip-service/internal/repository"
+	"github.com/namycodes/yanga-services/shared-lib/config"
+	"github.com/namycodes/yanga-services/shared-lib/domain"
+	"github.com/namycodes/yanga-services/shared-lib/events"
+	"github.com/namycodes/yanga-services/shared-lib/routing"
+	"github.com/namycodes/yanga-services/shared-lib/utils"
+)
+
+// maxQueueListing caps how much of a queue one listing returns.
+const maxQueueListing = 500
+
+var ErrNotInAirportQueue = errors.New("you are not waiting in an airport queue")
+
+// AirportQueueService queues drivers waiting in airport staging lots and
+// hands airport pickups to them first in, first out. Drivers join when they
+// enter a staging lot and leave when they drive out of it, go offline or
+// accept a trip.
+type AirportQueueService struct {
+	repo            *repository.AirportQueueRepository
+	geofenceService *GeofenceService
+	eventBus        events.EventBus
+	offerDepth      int32
+}
+
+func NewAirportQueueService(repo *repository.AirportQueueRepository, geofenceService *GeofenceService, eventBus events.EventBus, cfg *config.Config) *AirportQueueService {
+	return &AirportQueueService{
+		repo:            repo,
+		geofenceService: geofenceService,
+		eventBus:        eventBus,
+		offerDepth:      int32(max(cfg.AirportQueueOfferDepth, 1)),
+	}
+}
+
+// GetQueuePosition returns where a driver is in their airport's queue.
+func (s *AirportQueueService) GetQueuePosition(ctx context.Context, driverID uuid.UUID) (*domain.AirportQueuePositionResponse, error) {
+	entry, err := s.repo.GetAirportQueuePosition(ctx, utils.ToPgUUID(driverID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotInAirportQueue
+		}
+		return nil, fmt.Errorf("failed to get queue position: %w", err)
+	}
+	return &domain.AirportQueuePositionResponse{
+		AirportID:   utils.FromPgUUID(entry.AirportID).String(),
+		AirportName: entry.AirportName,
+		StagingID:   utils.FromPgUUID(entry.StagingID).String(),
+		Position:    entry.Position,
+		QueueLength: entry.QueueLength,
+		JoinedAt:    entry.JoinedAt.Time,
+	}, nil
+}
+
+// ListQueue returns the drivers waiting for an airport's pickups, head
+// first.
+func (s *AirportQueueService) ListQueue(ctx context.Context, airportID uuid.UUID, limit int32) ([]domain.AirportQueueEntryResponse, error) {
+	airport, err := s.geofenceService.GetGeofence(ctx, airportID)
+	if err != nil {
+		return nil, err
+	}
+	if airport.Kind != domain.GeofenceKindAirport {
+		return nil, ErrGeofenceNotFound
+	}
+	if limit <= 0 || limit > maxQueueListing {
+		limit = maxQueueListing
+	}
+
+	entries, err := s.repo.ListAirportQueue(ctx, db.ListAirportQueueParams{
+		AirportID: utils.ToPgUUID(airportID),
+		Limit:     limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list airport queue: %w", err)
+	}
+	resp := make([]domain.AirportQueueEntryResponse, len(entries))
+	for i, entry := range entries {
+		resp[i] = domain.AirportQueueEntryResponse{
+			DriverID:  utils.FromPgUUID(entry.DriverID).String(),
+			StagingID: utils.FromPgUUID(entry.StagingID).String(),
+			Position:  i + 1,
+			JoinedAt:  entry.JoinedAt.Time,
+		}
+	}
+	return resp, nil
+}
+
+// dispatchDrivers returns the drivers at the head of the queue for an
+// airport holding the trip's pickup, in the order they should be offered
+// it. Nil means the trip goes to the nearest drivers as usual: the pickup
+// isn't at an airport, nobody is queued there, or the trip is a delivery,
+// which only some vehicles can take.
+func (s *AirportQueueService) dispatchDrivers(ctx context.Context, trip db.Trip) []string {
+	if trip.TripType == domain.TripTypeDelivery {
+		return nil
+	}
+
+	pickup := routing.Point{
+		Lat: utils.NumericToFloat64(trip.PickupLatitude),
+		Lng: utils.NumericToFloat64(trip.PickupLongitude),
+	}
+	zones, err := s.geofenceService.zonesAt(ctx, pickup)
+	if err != nil {
+		log.Printf("Failed to look up airport for trip %s: %v", utils.FromPgUUID(trip.ID), err)
+		return nil
+	}
+
+	for _, g := range zones {
+		if g.Kind != domain.GeofenceKindAirport {
+			continue
+		}
+		entries, err := s.repo.ListAirportQueue(ctx, db.ListAirportQueueParams{
+			AirportID: g.ID,
+			Limit:     s.offerDepth,
+		})
+		if err != nil {
+			log.Printf("Failed to get queue for airport %s: %v", utils.FromPgUUID(g.ID), err)
+			continue
+		}
+		if len(entries) == 0 {
+			continue
+		}
+		driverIDs := make([]string, len(entries))
+		for i, entry := range entries {
+			driverIDs[i] = utils.FromPgUUID(entry.DriverID).String()
+		}
+		return driverIDs
+	}
+	return nil
+}
+
+// HandleGeofenceEntered queues a driver who has entered a staging lot.
+func (s *AirportQueueService) HandleGeofenceEntered(ctx context.Context, driverID, stagingID uuid.UUID) error {
+	staging, err := s.geofenceService.repo.GetGeofence(ctx, utils.ToPgUUID(stagingID))
+	if err != nil {
+		// Deleted since the driver entered it.
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		return fmt.Errorf("failed to get staging lot: %w", err)
+	}
+	if !staging.ParentID.Valid {
+		return nil
+	}
+
+	joined, err := s.repo.JoinAirportQueue(ctx, db.JoinAirportQueueParams{
+		DriverID:  utils.ToPgUUID(driverID),
+		AirportID: staging.ParentID,
+		StagingID: staging.ID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to join airport queue: %w", err)
+	}
+	if joined == 1 {
+		log.Printf("Driver %s joined the queue for airport %s", driverID, utils.FromPgUUID(staging.ParentID))
+	}
+	return nil
+}
+
+// HandleGeofenceExited takes a driver who has left a staging lot out of
+// the queue. Moving straight into another lot of the same airport keeps
+// their place, since the entry is recorded before the exit.
+func (s *AirportQueueService) HandleGeofenceExited(ctx context.Context, driverID, stagingID uuid.UUID) error {
+	left, err := s.repo.LeaveStagingArea(ctx, db.LeaveStagingAreaParams{
+		DriverID:  utils.ToPgUUID(driverID),
+		StagingID: utils.ToPgUUID(stagingID),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to leave airport queue: %w", err)
+	}
+	if left == 1 {
+		log.Printf("Driver %s left the airport queue", driverID)
+	}
+	return nil
+}
+
+// leave takes a driver out of any airport queue, such as once they have
+// accepted a trip.
+func (s *AirportQueueService) leave(ctx context.Context, driverID uuid.UUID) error {
+	if _, err := s.repo.LeaveAirportQueue(ctx, utils.ToPgUUID(driverID)); err != nil {
+		return fmt.Errorf("failed to leave airport queue: %w", err)
+	}
+	return nil
+}
+
+// SubscribeToEvents follows drivers in and out of staging lots. Going
+// offline takes a driver out of every geofence, so it arrives here as an
+// exit too.
+func (s *AirportQueueService) SubscribeToEvents() {
+	s.eventBus.QueueSubscribe(events.SubjectGeofenceEntered, "trip-service", func(ctx context.Context, data []byte) {
+		s.handleGeofenceEvent(ctx, data, s.HandleGeofenceEntered)
+	})
+	s.eventBus.QueueSubscribe(events.SubjectGeofenceExited, "trip-service", func(ctx context.Context, data []byte) {
+		s.handleGeofenceEvent(ctx, data, s.HandleGeofenceExited)
+	})
+
+	s.eventBus.QueueSubscribe(events.SubjectTripAccepted, "trip-service", func(ctx context.Context, data []byte) {
+		var event events.TripAcceptedEvent
+		if err := json.Unmarshal(data, &event); err != nil {
+			log.Printf("Failed to unmarshal trip accepted event: %v", err)
+			return
+		}
+		driverID, err := uuid.Parse(event.DriverID)
+		if err != nil {
+			log.Printf("Invalid driver ID in event: %v", err)
+			return
+		}
+		if err := s.leave(ctx, driverID); err != nil {
+			log.Printf("Failed to dequeue driver %s: %v", event.DriverID, err)
+		}
+	})
+}
+
+func (s *AirportQueueService) handleGeofenceEvent(ctx context.Context, data []byte, handle func(ctx context.Context, driverID, stagingID uuid.UUID) error) {
+	var event events.GeofenceEvent
+	if err := json.Unmarshal(data, &event); err != nil {
+		log.Printf("Failed to unmarshal geofence event: %v", err)
+		return
+	}
+	if event.Kind != domain.GeofenceKindStaging {
+		return
+	}
+	driverID, err := uuid.Parse(event.DriverID)
+	if err != nil {
+		log.Printf("Invalid driver ID in event: %v", err)
+		return
+	}
+	stagingID, err := uuid.Parse(event.GeofenceID)
+	if err != nil {
+		log.Printf("Invalid geofence ID in event: %v", err)
+		return
+	}
+
+	if err := handle(ctx, driverID, stagingID); err != nil {
+		log.Printf("Failed to update airport queue for driver %s: %v", event.DriverID, err)
+	}
+}
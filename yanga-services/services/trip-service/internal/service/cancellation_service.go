@@ -0,0 +1,447 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/namycodes/yanga-services/services/trip-service/internal/db"
+	"github.com/namycodes/yanga-services/services/trip-service/internal/repository"
+	"github.com/namycodes/yanga-services/shared-lib/domain"
+	"github.com/namycodes/yanga-services/shared-lib/events"
+	"github.com/namycodes/yanga-services/shared-lib/money"
+	"github.com/namycodes/yanga-services/shared-lib/utils"
+)
+
+const (
+	expirySweepInterval = 30 * time.Second
+	expirySweepBatch    = 100
+
+	matchTimeoutReason          = "No driver accepted the trip"
+	scheduledMatchTimeoutReason = "No driver accepted the scheduled trip"
+	riderNoShowReason           = "Rider did not show up"
+)
+
+var (
+	ErrTripNotFound          = errors.New("trip not found")
+	ErrNotTripParticipant    = errors.New("not a participant of this trip")
+	ErrTripNotCancellable    = errors.New("trip can no longer be cancelled")
+	ErrTripNotAccepted       = errors.New("trip has not been accepted by a driver")
+	ErrDriverAlreadyArrived  = errors.New("driver already marked as arrived")
+	ErrDriverNotArrived      = errors.New("driver has not arrived at pickup")
+	ErrNoShowWaitNotElapsed  = errors.New("no-show wait time has not elapsed")
+	ErrPolicyNotFound        = errors.New("cancellation policy not found")
+	ErrInvalidCityCode       = errors.New("invalid city code")
+	ErrInvalidPolicy         = errors.New("invalid cancellation policy")
+	ErrDefaultPolicyRequired = errors.New("the default cancellation policy cannot be deleted")
+)
+
+var cityCodePattern = regexp.MustCompile(`^[a-z0-9_-]{2,50}$`)
+
+// cancellation records who ended a trip and what it cost the rider.
+type cancellation struct {
+	cancelledBy string
+	noShowParty string
+	reason      string
+	fee         money.Money
+}
+
+type CancellationService struct {
+	repo             *repository.CancellationRepository
+	tripRepo         *repository.TripRepository
+	promotionService *PromotionService
+	poolService      *PoolService
+	cityService      *CityService
+	eventBus         events.EventBus
+}
+
+func NewCancellationService(repo *repository.CancellationRepository, tripRepo *repository.TripRepository, promotionService *PromotionService, poolService *PoolService, cityService *CityService, eventBus events.EventBus) *CancellationService {
+	return &CancellationService{
+		repo:             repo,
+		tripRepo:         tripRepo,
+		promotionService: promotionService,
+		poolService:      poolService,
+		cityService:      cityService,
+		eventBus:         eventBus,
+	}
+}
+
+// CancelTrip cancels a trip on behalf of its rider, its driver or an admin.
+// Riders are charged according to their city's cancellation policy; drivers
+// are never charged but the cancellation counts against their metrics.
+func (s *CancellationService) CancelTrip(ctx context.Context, tripID, callerID uuid.UUID, role, reason string) (*domain.CancelTripResponse, error) {
+	trip, err := s.getTrip(ctx, tripID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch trip.Status {
+	case domain.TripStatusScheduled, domain.TripStatusPending, domain.TripStatusAccepted:
+	default:
+		return nil, ErrTripNotCancellable
+	}
+
+	var c cancellation
+	switch {
+	case utils.FromPgUUID(trip.UserID) == callerID:
+		if c, err = s.riderCancellation(ctx, trip, time.Now().UTC()); err != nil {
+			return nil, err
+		}
+	case trip.DriverID.Valid && utils.FromPgUUID(trip.DriverID) == callerID:
+		c = cancellation{cancelledBy: domain.CancelledByDriver}
+	case role == "admin":
+		c = cancellation{cancelledBy: domain.CancelledBySystem}
+	default:
+		return nil, ErrNotTripParticipant
+	}
+	c.reason = reason
+
+	if err := s.cancel(ctx, trip, c); err != nil {
+		return nil, err
+	}
+	return toCancelTripResponse(trip, c), nil
+}
+
+// MarkDriverArrived records that the assigned driver is waiting at pickup,
+// which starts the rider no-show timer.
+func (s *CancellationService) MarkDriverArrived(ctx context.Context, tripID, driverID uuid.UUID) error {
+	trip, err := s.getTrip(ctx, tripID)
+	if err != nil {
+		return err
+	}
+
+	if !trip.DriverID.Valid || utils.FromPgUUID(trip.DriverID) != driverID {
+		return ErrNotTripParticipant
+	}
+	if trip.Status != domain.TripStatusAccepted {
+		return ErrTripNotAccepted
+	}
+
+	updated, err := s.tripRepo.MarkDriverArrived(ctx, trip.ID)
+	if err != nil {
+		return fmt.Errorf("failed to mark driver arrived: %w", err)
+	}
+	if updated == 0 {
+		return ErrDriverAlreadyArrived
+	}
+
+	policy, err := s.policyFor(ctx, trip)
+	if err != nil {
+		return err
+	}
+
+	s.eventBus.Publish(ctx, events.SubjectDriverArrived, events.DriverArrivedEvent{
+		TripID:          tripID.String(),
+		UserID:          utils.FromPgUUID(trip.UserID).String(),
+		DriverID:        driverID.String(),
+		WaitTimeSeconds: int(policy.RiderNoShowWaitSeconds),
+		Timestamp:       time.Now(),
+	})
+
+	return nil
+}
+
+// ReportRiderNoShow lets the driver cancel once they have waited at pickup
+// for the policy's wait time. The rider is charged the no-show fee.
+func (s *CancellationService) ReportRiderNoShow(ctx context.Context, tripID, driverID uuid.UUID) (*domain.CancelTripResponse, error) {
+	trip, err := s.getTrip(ctx, tripID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !trip.DriverID.Valid || utils.FromPgUUID(trip.DriverID) != driverID {
+		return nil, ErrNotTripParticipant
+	}
+	if trip.Status != domain.TripStatusAccepted {
+		return nil, ErrTripNotAccepted
+	}
+	if !trip.ArrivedAt.Valid {
+		return nil, ErrDriverNotArrived
+	}
+
+	policy, err := s.policyFor(ctx, trip)
+	if err != nil {
+		return nil, err
+	}
+
+	waited := time.Now().UTC().Sub(trip.ArrivedAt.Time)
+	if waited < seconds(policy.RiderNoShowWaitSeconds) {
+		return nil, fmt.Errorf("%w: %d seconds remaining", ErrNoShowWaitNotElapsed,
+			int((seconds(policy.RiderNoShowWaitSeconds) - waited).Seconds()))
+	}
+
+	c := cancellation{
+		cancelledBy: domain.CancelledByDriver,
+		noShowParty: domain.NoShowRider,
+		reason:      riderNoShowReason,
+		fee:         money.FromNumeric(policy.RiderNoShowFee, money.Currency(trip.Currency)),
+	}
+	if err := s.cancel(ctx, trip, c); err != nil {
+		return nil, err
+	}
+	return toCancelTripResponse(trip, c), nil
+}
+
+func (s *CancellationService) ListPolicies(ctx context.Context) ([]domain.CancellationPolicyResponse, error) {
+	policies, err := s.repo.ListCancellationPolicies(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cancellation policies: %w", err)
+	}
+
+	resp := make([]domain.CancellationPolicyResponse, 0, len(policies))
+	for _, p := range policies {
+		resp = append(resp, *toCancellationPolicyResponse(p))
+	}
+	return resp, nil
+}
+
+func (s *CancellationService) UpsertPolicy(ctx context.Context, adminID uuid.UUID, cityCode string, req *domain.UpsertCancellationPolicyRequest) (*domain.CancellationPolicyResponse, error) {
+	cityCode = strings.ToLower(strings.TrimSpace(cityCode))
+	if !cityCodePattern.MatchString(cityCode) {
+		return nil, ErrInvalidCityCode
+	}
+
+	if req.FreeCancellationSeconds < 0 || req.DriverNearbyMeters < 0 {
+		return nil, fmt.Errorf("%w: free cancellation window and nearby radius cannot be negative", ErrInvalidPolicy)
+	}
+	if req.LateCancellationFee < 0 || req.DriverArrivedFee < 0 || req.RiderNoShowFee < 0 {
+		return nil, fmt.Errorf("%w: cancellation fees cannot be negative", ErrInvalidPolicy)
+	}
+	if req.RiderNoShowWaitSeconds <= 0 || req.DriverNoShowWaitSeconds <= 0 || req.MatchTimeoutSeconds <= 0 {
+		return nil, fmt.Errorf("%w: wait times and match timeout must be positive", ErrInvalidPolicy)
+	}
+	// Fees are in the city's currency; the default policy's are in the
+	// default city's.
+	currency, err := s.cityService.currencyOf(ctx, cityCode)
+	if err != nil {
+		if errors.Is(err, ErrCityNotFound) {
+			return nil, fmt.Errorf("%w: city %q doesn't exist", ErrInvalidPolicy, cityCode)
+		}
+		return nil, err
+	}
+
+	policy, err := s.repo.UpsertCancellationPolicy(ctx, db.UpsertCancellationPolicyParams{
+		CityCode:                cityCode,
+		FreeCancellationSeconds: req.FreeCancellationSeconds,
+		LateCancellationFee:     money.FromFloat(req.LateCancellationFee, currency).Numeric(),
+		DriverNearbyMeters:      req.DriverNearbyMeters,
+		DriverArrivedFee:        money.FromFloat(req.DriverArrivedFee, currency).Numeric(),
+		RiderNoShowWaitSeconds:  req.RiderNoShowWaitSeconds,
+		RiderNoShowFee:          money.FromFloat(req.RiderNoShowFee, currency).Numeric(),
+		DriverNoShowWaitSeconds: req.DriverNoShowWaitSeconds,
+		MatchTimeoutSeconds:     req.MatchTimeoutSeconds,
+		UpdatedBy:               utils.ToPgUUID(adminID),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to save cancellation policy: %w", err)
+	}
+	return toCancellationPolicyResponse(policy), nil
+}
+
+func (s *CancellationService) DeletePolicy(ctx context.Context, cityCode string) error {
+	cityCode = strings.ToLower(strings.TrimSpace(cityCode))
+	if cityCode == domain.DefaultCityCode {
+		return ErrDefaultPolicyRequired
+	}
+
+	deleted, err := s.repo.DeleteCancellationPolicy(ctx, cityCode)
+	if err != nil {
+		return fmt.Errorf("failed to delete cancellation policy: %w", err)
+	}
+	if deleted == 0 {
+		return ErrPolicyNotFound
+	}
+	return nil
+}
+
+// RunExpiryWorker cancels pending trips that no driver accepted within their
+// city's match timeout. It blocks until ctx is cancelled.
+func (s *CancellationService) RunExpiryWorker(ctx context.Context) {
+	ticker := time.NewTicker(expirySweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.expireUnmatchedTrips(ctx)
+		}
+	}
+}
+
+func (s *CancellationService) expireUnmatchedTrips(ctx context.Context) {
+	trips, err := s.repo.GetExpiredPendingTrips(ctx, expirySweepBatch)
+	if err != nil {
+		log.Printf("Failed to load unmatched trips: %v", err)
+		return
+	}
+
+	for _, trip := range trips {
+		c := cancellation{
+			cancelledBy: domain.CancelledBySystem,
+			reason:      matchTimeoutReason,
+		}
+		if trip.PickupAt.Valid {
+			c.reason = scheduledMatchTimeoutReason
+		}
+		if err := s.cancel(ctx, trip, c); err != nil {
+			log.Printf("Failed to expire trip %s: %v", utils.FromPgUUID(trip.ID), err)
+			continue
+		}
+		log.Printf("Trip %s expired without a driver", utils.FromPgUUID(trip.ID))
+	}
+}
+
+// riderCancellation applies the city policy to a rider-initiated
+// cancellation. A rider whose driver never turned up within the driver
+// no-show wait is let off and the driver is recorded as the no-show.
+func (s *CancellationService) riderCancellation(ctx context.Context, trip db.Trip, now time.Time) (cancellation, error) {
+	c := cancellation{cancelledBy: domain.CancelledByRider}
+	if trip.Status == domain.TripStatusPending || !trip.AcceptedAt.Valid {
+		return c, nil
+	}
+
+	policy, err := s.policyFor(ctx, trip)
+	if err != nil {
+		return c, err
+	}
+
+	currency := money.Currency(trip.Currency)
+	sinceAccepted := now.Sub(trip.AcceptedAt.Time)
+	switch {
+	case !trip.ArrivedAt.Valid && sinceAccepted >= seconds(policy.DriverNoShowWaitSeconds):
+		c.noShowParty = domain.NoShowDriver
+	case trip.ArrivedAt.Valid:
+		c.fee = money.FromNumeric(policy.DriverArrivedFee, currency)
+	case sinceAccepted <= seconds(policy.FreeCancellationSeconds):
+		// Free grace period after acceptance.
+	case s.driverNearby(ctx, trip, policy):
+		c.fee = money.FromNumeric(policy.DriverArrivedFee, currency)
+	default:
+		c.fee = money.FromNumeric(policy.LateCancellationFee, currency)
+	}
+	return c, nil
+}
+
+// driverNearby reports whether the driver's last known position is within
+// the policy's nearby radius of the pickup point.
+func (s *CancellationService) driverNearby(ctx context.Context, trip db.Trip, policy db.CancellationPolicy) bool {
+	if policy.DriverNearbyMeters <= 0 {
+		return false
+	}
+
+	loc, err := s.repo.GetDriverLocation(ctx, trip.DriverID)
+	if err != nil || !loc.CurrentLatitude.Valid || !loc.CurrentLongitude.Valid {
+		return false
+	}
+
+	distanceKm := utils.CalculateDistance(
+		utils.NumericToFloat64(loc.CurrentLatitude), utils.NumericToFloat64(loc.CurrentLongitude),
+		utils.NumericToFloat64(trip.PickupLatitude), utils.NumericToFloat64(trip.PickupLongitude),
+	)
+	return distanceKm*1000 <= float64(policy.DriverNearbyMeters)
+}
+
+// cancel marks the trip cancelled and releases any reserved promo in one
+// transaction, then announces the cancellation.
+func (s *CancellationService) cancel(ctx context.Context, trip db.Trip, c cancellation) error {
+	err := s.tripRepo.WithTx(ctx, func(q *db.Queries) error {
+		updated, err := q.CancelTrip(ctx, db.CancelTripParams{
+			ID:                 trip.ID,
+			CancellationReason: pgtype.Text{String: c.reason, Valid: c.reason != ""},
+			CancelledBy:        pgtype.Text{String: c.cancelledBy, Valid: true},
+			NoShowParty:        pgtype.Text{String: c.noShowParty, Valid: c.noShowParty != ""},
+			CancellationFee:    c.fee.Numeric(),
+		})
+		if err != nil {
+			return err
+		}
+		if updated == 0 {
+			return ErrTripNotCancellable
+		}
+		if err := s.promotionService.releasePromo(ctx, q, trip.ID); err != nil {
+			return err
+		}
+		return s.poolService.leave(ctx, q, trip)
+	})
+	if err != nil {
+		if errors.Is(err, ErrTripNotCancellable) {
+			return err
+		}
+		return fmt.Errorf("failed to cancel trip: %w", err)
+	}
+
+	event := events.TripCancelledEvent{
+		TripID:          utils.FromPgUUID(trip.ID).String(),
+		UserID:          utils.FromPgUUID(trip.UserID).String(),
+		CancelledBy:     c.cancelledBy,
+		NoShowParty:     c.noShowParty,
+		Reason:          c.reason,
+		CancellationFee: c.fee.Float64(),
+		PaymentMethod:   trip.PaymentMethod.String,
+		CityCode:        trip.CityCode.String,
+		Currency:        trip.Currency,
+		Timestamp:       time.Now(),
+	}
+	switch {
+	case trip.DriverID.Valid:
+		event.DriverID = utils.FromPgUUID(trip.DriverID).String()
+	case trip.ReservedDriverID.Valid:
+		event.DriverID = utils.FromPgUUID(trip.ReservedDriverID).String()
+	}
+	s.eventBus.Publish(ctx, events.SubjectTripCancelled, event)
+	s.poolService.publishPoolUpdated(ctx, trip, poolChangeLeft)
+
+	return nil
+}
+
+func (s *CancellationService) policyFor(ctx context.Context, trip db.Trip) (db.CancellationPolicy, error) {
+	policy, err := s.repo.GetCancellationPolicy(ctx, trip.CityCode.String)
+	if err != nil {
+		return db.CancellationPolicy{}, fmt.Errorf("failed to load cancellation policy: %w", err)
+	}
+	return policy, nil
+}
+
+func (s *CancellationService) getTrip(ctx context.Context, tripID uuid.UUID) (db.Trip, error) {
+	trip, err := s.tripRepo.GetTrip(ctx, utils.ToPgUUID(tripID))
+	if err != nil {
+		return db.Trip{}, ErrTripNotFound
+	}
+	return trip, nil
+}
+
+func seconds(n int32) time.Duration {
+	return time.Duration(n) * time.Second
+}
+
+func toCancelTripResponse(trip db.Trip, c cancellation) *domain.CancelTripResponse {
+	return &domain.CancelTripResponse{
+		TripID:          utils.FromPgUUID(trip.ID).String(),
+		CancelledBy:     c.cancelledBy,
+		NoShowParty:     c.noShowParty,
+		CancellationFee: c.fee.Float64(),
+	}
+}
+
+func toCancellationPolicyResponse(p db.CancellationPolicy) *domain.CancellationPolicyResponse {
+	return &domain.CancellationPolicyResponse{
+		CityCode:                p.CityCode,
+		FreeCancellationSeconds: p.FreeCancellationSeconds,
+		LateCancellationFee:     utils.NumericToFloat64(p.LateCancellationFee),
+		DriverNearbyMeters:      p.DriverNearbyMeters,
+		DriverArrivedFee:        utils.NumericToFloat64(p.DriverArrivedFee),
+		RiderNoShowWaitSeconds:  p.RiderNoShowWaitSeconds,
+		RiderNoShowFee:          utils.NumericToFloat64(p.RiderNoShowFee),
+		DriverNoShowWaitSeconds: p.DriverNoShowWaitSeconds,
+		MatchTimeoutSeconds:     p.MatchTimeoutSeconds,
+		UpdatedAt:               p.UpdatedAt.Time,
+	}
+}
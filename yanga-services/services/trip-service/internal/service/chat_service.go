@@ -0,0 +1,530 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/namycodes/yanga-services/services/trip-service/internal/db"
+	"github.com/namycodes/yanga-services/services/trip-service/internal/repository"
+	"github.com/namycodes/yanga-services/shared-lib/config"
+	"github.com/namycodes/yanga-services/shared-lib/domain"
+	"github.com/namycodes/yanga-services/shared-lib/events"
+	"github.com/namycodes/yanga-services/shared-lib/moderation"
+	"github.com/namycodes/yanga-services/shared-lib/telephony"
+	"github.com/namycodes/yanga-services/shared-lib/utils"
+)
+
+const (
+	maxChatMessageLength  = 1000
+	maxChatClientIDLength = 64
+	// A listener that falls this many frames behind is disconnected; the
+	// app reconnects and catches up from the history.
+	chatListenerBuffer = 32
+)
+
+var (
+	ErrInvalidChatMessage  = errors.New("invalid chat message")
+	ErrChatMessageNotFound = errors.New("message not found")
+	ErrChatMessageRejected = errors.New("messages can't contain phone numbers, email addresses or abuse")
+	ErrTripNotUnderWay     = errors.New("trip is not under way")
+	ErrCallUnavailable     = errors.New("calling is not available right now")
+)
+
+// quickReplies are the canned messages each side of a trip can send with
+// one tap.
+var quickReplies = map[string][]domain.QuickReplyResponse{
+	domain.ChatSenderRider: {
+		{Code: "coming_now", Text: "I'm coming now"},
+		{Code: "at_pickup", Text: "I'm at the pickup point"},
+		{Code: "running_late", Text: "I'm running a few minutes late"},
+		{Code: "where_are_you", Text: "Where are you?"},
+		{Code: "call_me", Text: "Please call me"},
+	},
+	domain.ChatSenderDriver: {
+		{Code: "on_my_way", Text: "I'm on my way"},
+		{Code: "arrived", Text: "I've arrived"},
+		{Code: "in_traffic", Text: "I'm in traffic, I'll be there soon"},
+		{Code: "cant_find_you", Text: "I can't find you, where exactly are you?"},
+		{Code: "call_me", Text: "Please call me"},
+	},
+}
+
+// ChatService runs the chat between a trip's rider and driver, open from
+// when a driver accepts until the trip ends, and connects them by phone
+// through a proxy number so neither sees the other's real number.
+//
+// Messages are stored and then published, and every instance delivers
+// them to the WebSockets connected to it, so rider and driver can be
+// connected to different instances.
+type ChatService struct {
+	chatRepo    *repository.ChatRepository
+	tripRepo    *repository.TripRepository
+	filter      moderation.Filter
+	calls       telephony.ProxyProvider
+	eventBus    events.EventBus
+	callSession time.Duration
+
+	mu        sync.Mutex
+	listeners map[uuid.UUID]map[*chatListener]struct{}
+}
+
+type chatListener struct {
+	userID uuid.UUID
+	frames chan domain.ChatServerFrame
+}
+
+func NewChatService(chatRepo *repository.ChatRepository, tripRepo *repository.TripRepository, filter moderation.Filter, calls telephony.ProxyProvider, eventBus events.EventBus, cfg *config.Config) *ChatService {
+	return &ChatService{
+		chatRepo:    chatRepo,
+		tripRepo:    tripRepo,
+		filter:      filter,
+		calls:       calls,
+		eventBus:    eventBus,
+		callSession: time.Duration(cfg.CallSessionMinutes) * time.Minute,
+		listeners:   make(map[uuid.UUID]map[*chatListener]struct{}),
+	}
+}
+
+// QuickReplies returns the canned messages for a role.
+func (s *ChatService) QuickReplies(role string) []domain.QuickReplyResponse {
+	if role == "driver" {
+		return quickReplies[domain.ChatSenderDriver]
+	}
+	return quickReplies[domain.ChatSenderRider]
+}
+
+// SendMessage stores a message from one side of a trip to the other and
+// passes it on. Resending a message with the same client ID returns the
+// original rather than storing it again.
+func (s *ChatService) SendMessage(ctx context.Context, tripID, senderID uuid.UUID, req *domain.SendChatMessageRequest) (*domain.ChatMessageResponse, error) {
+	trip, senderRole, err := s.loadChat(ctx, tripID, senderID)
+	if err != nil {
+		return nil, err
+	}
+	if !chatOpen(trip) {
+		return nil, ErrTripNotUnderWay
+	}
+
+	params := db.CreateTripMessageParams{
+		TripID:     trip.ID,
+		SenderID:   utils.ToPgUUID(senderID),
+		SenderRole: senderRole,
+	}
+
+	clientID := strings.TrimSpace(req.ClientID)
+	if len(clientID) > maxChatClientIDLength {
+		return nil, fmt.Errorf("%w: client_id is limited to %d characters", ErrInvalidChatMessage, maxChatClientIDLength)
+	}
+	params.ClientID = pgtype.Text{String: clientID, Valid: clientID != ""}
+
+	if code := strings.TrimSpace(req.QuickReply); code != "" {
+		i := slices.IndexFunc(quickReplies[senderRole], func(reply domain.QuickReplyResponse) bool { return reply.Code == code })
+		if i < 0 {
+			return nil, fmt.Errorf("%w: unknown quick reply %s", ErrInvalidChatMessage, code)
+		}
+		params.Body = quickReplies[senderRole][i].Text
+		params.QuickReply = pgtype.Text{String: code, Valid: true}
+	} else {
+		body := strings.TrimSpace(req.Body)
+		if body == "" {
+			return nil, fmt.Errorf("%w: body or quick_reply is required", ErrInvalidChatMessage)
+		}
+		if len([]rune(body)) > maxChatMessageLength {
+			return nil, fmt.Errorf("%w: messages are limited to %d characters", ErrInvalidChatMessage, maxChatMessageLength)
+		}
+
+		// Numbers swapped in chat would undo masked calling. A filter
+		// outage shouldn't stop a driver finding their rider, so messages
+		// that can't be checked go through.
+		verdict, err := s.filter.Check(ctx, body)
+		if err != nil {
+			log.Printf("Failed to check chat message on trip %s: %v", tripID, err)
+		} else if verdict.Flagged {
+			return nil, ErrChatMessageRejected
+		}
+		params.Body = body
+	}
+
+	message, err := s.chatRepo.CreateTripMessage(ctx, params)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return s.resentMessage(ctx, params)
+		}
+		return nil, fmt.Errorf("failed to send message: %w", err)
+	}
+
+	recipientID := trip.UserID
+	if senderRole == domain.ChatSenderRider {
+		recipientID = trip.DriverID
+	}
+	response := toChatMessageResponse(message)
+	s.eventBus.Publish(ctx, events.SubjectTripChatMessage, events.TripChatMessageEvent{
+		MessageID:   response.ID,
+		TripID:      response.TripID,
+		SenderID:    response.SenderID,
+		SenderRole:  response.SenderRole,
+		RecipientID: utils.FromPgUUID(recipientID).String(),
+		Body:        response.Body,
+		QuickReply:  response.QuickReply,
+		ClientID:    response.ClientID,
+		CreatedAt:   response.CreatedAt,
+	})
+	return &response, nil
+}
+
+func (s *ChatService) resentMessage(ctx context.Context, params db.CreateTripMessageParams) (*domain.ChatMessageResponse, error) {
+	message, err := s.chatRepo.GetTripMessageByClientID(ctx, db.GetTripMessageByClientIDParams{
+		TripID:   params.TripID,
+		SenderID: params.SenderID,
+		ClientID: params.ClientID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get resent message: %w", err)
+	}
+	response := toChatMessageResponse(message)
+	return &response, nil
+}
+
+// ListMessages returns a trip's chat history, newest first. It stays
+// readable after the trip ends.
+func (s *ChatService) ListMessages(ctx context.Context, tripID, userID uuid.UUID, limit, offset int32) ([]domain.ChatMessageResponse, error) {
+	trip, _, err := s.loadChat(ctx, tripID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	messages, err := s.chatRepo.ListTripMessages(ctx, db.ListTripMessagesParams{
+		TripID: trip.ID,
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get messages: %w", err)
+	}
+
+	response := make([]domain.ChatMessageResponse, len(messages))
+	for i, message := range messages {
+		response[i] = toChatMessageResponse(message)
+	}
+	return response, nil
+}
+
+// MarkRead marks read everything the other side sent up to and including a
+// message, and tells them.
+func (s *ChatService) MarkRead(ctx context.Context, tripID, readerID uuid.UUID, req *domain.MarkChatReadRequest) (*domain.ChatReadReceipt, error) {
+	trip, _, err := s.loadChat(ctx, tripID, readerID)
+	if err != nil {
+		return nil, err
+	}
+
+	messageID, err := utils.ParseUUID(req.MessageID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid message_id", ErrInvalidChatMessage)
+	}
+	message, err := s.chatRepo.GetTripMessage(ctx, db.GetTripMessageParams{
+		ID:     utils.ToPgUUID(messageID),
+		TripID: trip.ID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrChatMessageNotFound
+		}
+		return nil, fmt.Errorf("failed to get message: %w", err)
+	}
+
+	receipt := &domain.ChatReadReceipt{
+		TripID:    tripID.String(),
+		ReaderID:  readerID.String(),
+		MessageID: messageID.String(),
+		ReadAt:    time.Now(),
+	}
+	updated, err := s.chatRepo.MarkTripMessagesRead(ctx, db.MarkTripMessagesReadParams{
+		TripID:   trip.ID,
+		ReaderID: utils.ToPgUUID(readerID),
+		UpTo:     message.CreatedAt,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to mark messages read: %w", err)
+	}
+
+	if updated > 0 {
+		s.eventBus.Publish(ctx, events.SubjectTripChatRead, events.TripChatReadEvent{
+			TripID:    receipt.TripID,
+			ReaderID:  receipt.ReaderID,
+			MessageID: receipt.MessageID,
+			ReadAt:    receipt.ReadAt,
+		})
+	}
+	return receipt, nil
+}
+
+// Listen checks a user can join a trip's chat and returns the frames to
+// send them: messages from the other side and read receipts for their own.
+// The channel is closed when stop is called or the listener falls too far
+// behind.
+func (s *ChatService) Listen(ctx context.Context, tripID, userID uuid.UUID) (<-chan domain.ChatServerFrame, func(), error) {
+	if _, _, err := s.loadChat(ctx, tripID, userID); err != nil {
+		return nil, nil, err
+	}
+
+	listener := &chatListener{
+		userID: userID,
+		frames: make(chan domain.ChatServerFrame, chatListenerBuffer),
+	}
+
+	s.mu.Lock()
+	if s.listeners[tripID] == nil {
+		s.listeners[tripID] = make(map[*chatListener]struct{})
+	}
+	s.listeners[tripID][listener] = struct{}{}
+	s.mu.Unlock()
+
+	stop := func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.removeListener(tripID, listener)
+	}
+	return listener.frames, stop, nil
+}
+
+// broadcast sends a frame to the listeners on a trip, other than from.
+func (s *ChatService) broadcast(tripID, from uuid.UUID, frame domain.ChatServerFrame) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for listener := range s.listeners[tripID] {
+		if listener.userID == from {
+			continue
+		}
+		select {
+		case listener.frames <- frame:
+		default:
+			log.Printf("Disconnecting slow chat listener on trip %s", tripID)
+			s.removeListener(tripID, listener)
+		}
+	}
+}
+
+// removeListener must be called with s.mu held.
+func (s *ChatService) removeListener(tripID uuid.UUID, listener *chatListener) {
+	listeners := s.listeners[tripID]
+	if _, ok := listeners[listener]; !ok {
+		return
+	}
+	delete(listeners, listener)
+	close(listener.frames)
+	if len(listeners) == 0 {
+		delete(s.listeners, tripID)
+	}
+}
+
+// OpenCall returns the proxy number a rider or driver calls to reach the
+// other, opening a session with the provider if the trip has none.
+func (s *ChatService) OpenCall(ctx context.Context, tripID, userID uuid.UUID) (*domain.MaskedCallResponse, error) {
+	trip, _, err := s.loadChat(ctx, tripID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !chatOpen(trip) {
+		return nil, ErrTripNotUnderWay
+	}
+
+	session, err := s.chatRepo.GetOpenTripCallSession(ctx, trip.ID)
+	switch {
+	case err == nil && session.ExpiresAt.Time.After(time.Now()):
+		return toMaskedCallResponse(session), nil
+	case err == nil:
+		s.closeCall(ctx, session)
+	case !errors.Is(err, pgx.ErrNoRows):
+		return nil, fmt.Errorf("failed to get call session: %w", err)
+	}
+
+	phones, err := s.chatRepo.GetTripParticipantPhones(ctx, trip.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get phone numbers: %w", err)
+	}
+
+	opened, err := s.calls.OpenSession(ctx, phones.RiderPhone, phones.DriverPhone, time.Now().Add(s.callSession))
+	if err != nil {
+		log.Printf("Failed to open call session for trip %s: %v", tripID, err)
+		return nil, ErrCallUnavailable
+	}
+
+	session, err = s.chatRepo.CreateTripCallSession(ctx, db.CreateTripCallSessionParams{
+		TripID:            trip.ID,
+		ProviderSessionID: opened.ID,
+		ProxyNumber:       opened.ProxyNumber,
+		ExpiresAt:         pgtype.Timestamp{Time: opened.ExpiresAt, Valid: true},
+	})
+	if err != nil {
+		// The other side opened one at the same time; use theirs.
+		if closeErr := s.calls.CloseSession(ctx, opened.ID); closeErr != nil {
+			log.Printf("Failed to close call session %s: %v", opened.ID, closeErr)
+		}
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("failed to save call session: %w", err)
+		}
+		if session, err = s.chatRepo.GetOpenTripCallSession(ctx, trip.ID); err != nil {
+			return nil, fmt.Errorf("failed to get call session: %w", err)
+		}
+	}
+	return toMaskedCallResponse(session), nil
+}
+
+// endTrip closes a trip's call session once it's over, so the proxy number
+// stops connecting rider and driver.
+func (s *ChatService) endTrip(ctx context.Context, tripID uuid.UUID) {
+	session, err := s.chatRepo.GetOpenTripCallSession(ctx, utils.ToPgUUID(tripID))
+	if err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			log.Printf("Failed to get call session for trip %s: %v", tripID, err)
+		}
+		return
+	}
+	s.closeCall(ctx, session)
+}
+
+func (s *ChatService) closeCall(ctx context.Context, session db.TripCallSession) {
+	if err := s.calls.CloseSession(ctx, session.ProviderSessionID); err != nil {
+		log.Printf("Failed to close call session %s: %v", session.ProviderSessionID, err)
+	}
+	if err := s.chatRepo.CloseTripCallSession(ctx, session.ID); err != nil {
+		log.Printf("Failed to mark call session %s closed: %v", session.ProviderSessionID, err)
+	}
+}
+
+// SubscribeToEvents delivers chat messages and read receipts to the
+// WebSockets connected to this instance, and closes call sessions when
+// trips end.
+func (s *ChatService) SubscribeToEvents() {
+	s.eventBus.Subscribe(events.SubjectTripChatMessage, func(ctx context.Context, data []byte) {
+		var event events.TripChatMessageEvent
+		if err := json.Unmarshal(data, &event); err != nil {
+			log.Printf("Failed to unmarshal chat message event: %v", err)
+			return
+		}
+		tripID, err := uuid.Parse(event.TripID)
+		if err != nil {
+			return
+		}
+		senderID, err := uuid.Parse(event.SenderID)
+		if err != nil {
+			return
+		}
+		s.broadcast(tripID, senderID, domain.ChatServerFrame{
+			Type: domain.ChatFrameMessage,
+			Message: &domain.ChatMessageResponse{
+				ID:         event.MessageID,
+				TripID:     event.TripID,
+				SenderID:   event.SenderID,
+				SenderRole: event.SenderRole,
+				Body:       event.Body,
+				QuickReply: event.QuickReply,
+				ClientID:   event.ClientID,
+				CreatedAt:  event.CreatedAt,
+			},
+		})
+	})
+
+	s.eventBus.Subscribe(events.SubjectTripChatRead, func(ctx context.Context, data []byte) {
+		var event events.TripChatReadEvent
+		if err := json.Unmarshal(data, &event); err != nil {
+			log.Printf("Failed to unmarshal chat read event: %v", err)
+			return
+		}
+		tripID, err := uuid.Parse(event.TripID)
+		if err != nil {
+			return
+		}
+		readerID, err := uuid.Parse(event.ReaderID)
+		if err != nil {
+			return
+		}
+		s.broadcast(tripID, readerID, domain.ChatServerFrame{
+			Type: domain.ChatFrameRead,
+			Read: &domain.ChatReadReceipt{
+				TripID:    event.TripID,
+				ReaderID:  event.ReaderID,
+				MessageID: event.MessageID,
+				ReadAt:    event.ReadAt,
+			},
+		})
+	})
+
+	for _, subject := range []string{events.SubjectTripCompleted, events.SubjectTripCancelled} {
+		s.eventBus.QueueSubscribe(subject, "trip-service-calls", func(ctx context.Context, data []byte) {
+			var event struct {
+				TripID string `json:"trip_id"`
+			}
+			if err := json.Unmarshal(data, &event); err != nil {
+				log.Printf("Failed to unmarshal trip event: %v", err)
+				return
+			}
+			tripID, err := uuid.Parse(event.TripID)
+			if err != nil {
+				return
+			}
+			s.endTrip(ctx, tripID)
+		})
+	}
+}
+
+// loadChat returns a trip with the caller's side of it. Only the rider and
+// the assigned driver are in a trip's chat.
+func (s *ChatService) loadChat(ctx context.Context, tripID, userID uuid.UUID) (db.Trip, string, error) {
+	trip, err := s.tripRepo.GetTrip(ctx, utils.ToPgUUID(tripID))
+	if err != nil {
+		return db.Trip{}, "", ErrTripNotFound
+	}
+	switch {
+	case utils.FromPgUUID(trip.UserID) == userID:
+		if !trip.DriverID.Valid {
+			return db.Trip{}, "", ErrTripNotAccepted
+		}
+		return trip, domain.ChatSenderRider, nil
+	case trip.DriverID.Valid && utils.FromPgUUID(trip.DriverID) == userID:
+		return trip, domain.ChatSenderDriver, nil
+	default:
+		return db.Trip{}, "", ErrNotTripParticipant
+	}
+}
+
+// chatOpen reports whether rider and driver can still message and call:
+// from when the driver accepts until the trip ends.
+func chatOpen(trip db.Trip) bool {
+	return trip.Status == domain.TripStatusAccepted || trip.Status == domain.TripStatusInProgress
+}
+
+func toChatMessageResponse(message db.TripMessage) domain.ChatMessageResponse {
+	response := domain.ChatMessageResponse{
+		ID:         utils.FromPgUUID(message.ID).String(),
+		TripID:     utils.FromPgUUID(message.TripID).String(),
+		SenderID:   utils.FromPgUUID(message.SenderID).String(),
+		SenderRole: message.SenderRole,
+		Body:       message.Body,
+		QuickReply: message.QuickReply.String,
+		ClientID:   message.ClientID.String,
+		CreatedAt:  message.CreatedAt.Time,
+	}
+	if message.ReadAt.Valid {
+		response.ReadAt = &message.ReadAt.Time
+	}
+	return response
+}
+
+func toMaskedCallResponse(session db.TripCallSession) *domain.MaskedCallResponse {
+	return &domain.MaskedCallResponse{
+		ProxyNumber: session.ProxyNumber,
+		ExpiresAt:   session.ExpiresAt.Time,
+	}
+}
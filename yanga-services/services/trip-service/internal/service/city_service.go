@@ -0,0 +1,262 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/namycodes/yanga-services/services/trip-service/internal/db"
+	"github.com/namycodes/yanga-services/services/trip-service/internal/repository"
+	"github.com/namycodes/yanga-services/shared-lib/config"
+	"github.com/namycodes/yanga-services/shared-lib/domain"
+	"github.com/namycodes/yanga-services/shared-lib/money"
+	"github.com/namycodes/yanga-services/shared-lib/utils"
+)
+
+var (
+	ErrInvalidCity        = errors.New("invalid city")
+	ErrCityNotFound       = errors.New("city not found")
+	ErrCityNotServed      = errors.New("we don't operate in this city yet")
+	ErrFeatureUnavailable = errors.New("not available in this city")
+	ErrDriverOutOfCity    = errors.New("trip is outside the driver's city")
+)
+
+// cityConfig is how a city prices its trips and which kinds of trip it
+// offers.
+type cityConfig struct {
+	code       string
+	currency   money.Currency
+	timezone   string
+	rates      rateCard
+	pooling    bool
+	deliveries bool
+	scheduling bool
+	stops      bool
+}
+
+// checkFeatures rejects a booking that uses something the city doesn't
+// offer.
+func (c cityConfig) checkFeatures(pooled, delivery, scheduled bool, stops int) error {
+	switch {
+	case pooled && !c.pooling:
+		return fmt.Errorf("pooled rides are %w", ErrFeatureUnavailable)
+	case delivery && !c.deliveries:
+		return fmt.Errorf("parcel deliveries are %w", ErrFeatureUnavailable)
+	case scheduled && !c.scheduling:
+		return fmt.Errorf("scheduled rides are %w", ErrFeatureUnavailable)
+	case stops > 0 && !c.stops:
+		return fmt.Errorf("extra stops are %w", ErrFeatureUnavailable)
+	}
+	return nil
+}
+
+// CityService manages the cities trips run in. Each trip belongs to the
+// city whose service area holds its pickup, and is priced, charged and
+// scoped by that city's settings.
+type CityService struct {
+	repo        *repository.CityRepository
+	defaultCode string
+}
+
+func NewCityService(repo *repository.CityRepository, cfg *config.Config) *CityService {
+	return &CityService{
+		repo:        repo,
+		defaultCode: strings.ToLower(strings.TrimSpace(cfg.DefaultCityCode)),
+	}
+}
+
+func (s *CityService) ListCities(ctx context.Context) ([]domain.CityResponse, error) {
+	cities, err := s.repo.ListCities(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cities: %w", err)
+	}
+	resp := make([]domain.CityResponse, len(cities))
+	for i, c := range cities {
+		resp[i] = *toCityResponse(c)
+	}
+	return resp, nil
+}
+
+func (s *CityService) GetCity(ctx context.Context, code string) (*domain.CityResponse, error) {
+	city, err := s.repo.GetCity(ctx, strings.ToLower(strings.TrimSpace(code)))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrCityNotFound
+		}
+		return nil, fmt.Errorf("failed to get city: %w", err)
+	}
+	return toCityResponse(city), nil
+}
+
+// UpsertCity creates a city or replaces its settings. New rates apply to
+// trips booked from then on.
+func (s *CityService) UpsertCity(ctx context.Context, code string, req *domain.UpsertCityRequest) (*domain.CityResponse, error) {
+	code = strings.ToLower(strings.TrimSpace(code))
+	if !cityCodePattern.MatchString(code) || code == domain.DefaultCityCode {
+		return nil, ErrInvalidCityCode
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" || len(name) > 100 {
+		return nil, fmt.Errorf("%w: name is required and at most 100 characters", ErrInvalidCity)
+	}
+	currency, err := money.ParseCurrency(req.Currency)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCity, err)
+	}
+	// Amounts are stored to two decimal places.
+	if currency.Digits() > 2 {
+		return nil, fmt.Errorf("%w: %s amounts have %d decimal places, at most 2 are supported", ErrInvalidCity, currency, currency.Digits())
+	}
+	if _, err := time.LoadLocation(req.Timezone); err != nil || req.Timezone == "" {
+		return nil, fmt.Errorf("%w: unknown timezone %q", ErrInvalidCity, req.Timezone)
+	}
+	for _, rate := range []float64{req.BaseFare, req.PerKmRate, req.PerStopFee, req.DeliveryBaseFare, req.DeliveryPerKmRate} {
+		if rate < 0 {
+			return nil, fmt.Errorf("%w: rates cannot be negative", ErrInvalidCity)
+		}
+	}
+
+	city, err := s.repo.UpsertCity(ctx, db.UpsertCityParams{
+		Code:              code,
+		Name:              name,
+		Currency:          currency.String(),
+		Timezone:          req.Timezone,
+		BaseFare:          money.FromFloat(req.BaseFare, currency).Numeric(),
+		PerKmRate:         money.FromFloat(req.PerKmRate, currency).Numeric(),
+		PerStopFee:        money.FromFloat(req.PerStopFee, currency).Numeric(),
+		DeliveryBaseFare:  money.FromFloat(req.DeliveryBaseFare, currency).Numeric(),
+		DeliveryPerKmRate: money.FromFloat(req.DeliveryPerKmRate, currency).Numeric(),
+		PoolingEnabled:    req.PoolingEnabled,
+		DeliveriesEnabled: req.DeliveriesEnabled,
+		SchedulingEnabled: req.SchedulingEnabled,
+		StopsEnabled:      req.StopsEnabled,
+		Active:            req.Active,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to save city: %w", err)
+	}
+	return toCityResponse(city), nil
+}
+
+// resolve returns the settings of the city a new booking belongs to, given
+// the city of the service area holding its pickup. Pickups outside every
+// city's service area belong to the default city.
+func (s *CityService) resolve(ctx context.Context, code string) (cityConfig, error) {
+	if code == "" {
+		code = s.defaultCode
+	}
+	city, err := s.repo.GetCity(ctx, code)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return cityConfig{}, ErrCityNotServed
+		}
+		return cityConfig{}, fmt.Errorf("failed to get city: %w", err)
+	}
+	if !city.Active {
+		return cityConfig{}, ErrCityNotServed
+	}
+	return toCityConfig(city), nil
+}
+
+// lookup returns the settings of the city an existing trip belongs to,
+// whether or not it still takes bookings.
+func (s *CityService) lookup(ctx context.Context, trip db.Trip) (cityConfig, error) {
+	code := trip.CityCode.String
+	if code == "" {
+		code = s.defaultCode
+	}
+	city, err := s.repo.GetCity(ctx, code)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return cityConfig{}, ErrCityNotFound
+		}
+		return cityConfig{}, fmt.Errorf("failed to get city: %w", err)
+	}
+	return toCityConfig(city), nil
+}
+
+// currencyOf returns the currency of a city, or of the default city for
+// settings that apply everywhere.
+func (s *CityService) currencyOf(ctx context.Context, code string) (money.Currency, error) {
+	if code == "" || code == domain.DefaultCityCode {
+		code = s.defaultCode
+	}
+	city, err := s.repo.GetCity(ctx, code)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", ErrCityNotFound
+		}
+		return "", fmt.Errorf("failed to get city: %w", err)
+	}
+	return money.Currency(city.Currency), nil
+}
+
+// driverCity returns the city a driver works in, or "" if they may take
+// trips anywhere.
+func (s *CityService) driverCity(ctx context.Context, driverID uuid.UUID) (string, error) {
+	code, err := s.repo.GetDriverCityCode(ctx, utils.ToPgUUID(driverID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to get driver city: %w", err)
+	}
+	return code.String, nil
+}
+
+// checkDriver rejects a driver from another city taking a trip.
+func (s *CityService) checkDriver(ctx context.Context, trip db.Trip, driverID uuid.UUID) error {
+	city, err := s.driverCity(ctx, driverID)
+	if err != nil {
+		return err
+	}
+	if city != "" && trip.CityCode.Valid && city != trip.CityCode.String {
+		return fmt.Errorf("%w: trip is in %s, driver works in %s", ErrDriverOutOfCity, trip.CityCode.String, city)
+	}
+	return nil
+}
+
+func toCityConfig(city db.City) cityConfig {
+	currency := money.Currency(city.Currency)
+	return cityConfig{
+		code:     city.Code,
+		currency: currency,
+		timezone: city.Timezone,
+		rates: rateCard{
+			baseFare:         money.FromNumeric(city.BaseFare, currency),
+			perKm:            money.FromNumeric(city.PerKmRate, currency),
+			perStop:          money.FromNumeric(city.PerStopFee, currency),
+			deliveryBaseFare: money.FromNumeric(city.DeliveryBaseFare, currency),
+			deliveryPerKm:    money.FromNumeric(city.DeliveryPerKmRate, currency),
+		},
+		pooling:    city.PoolingEnabled,
+		deliveries: city.DeliveriesEnabled,
+		scheduling: city.SchedulingEnabled,
+		stops:      city.StopsEnabled,
+	}
+}
+
+func toCityResponse(c db.City) *domain.CityResponse {
+	currency := money.Currency(c.Currency)
+	return &domain.CityResponse{
+		Code:              c.Code,
+		Name:              c.Name,
+		Currency:          c.Currency,
+		Timezone:          c.Timezone,
+		BaseFare:          money.FromNumeric(c.BaseFare, currency).Float64(),
+		PerKmRate:         money.FromNumeric(c.PerKmRate, currency).Float64(),
+		PerStopFee:        money.FromNumeric(c.PerStopFee, currency).Float64(),
+		DeliveryBaseFare:  money.FromNumeric(c.DeliveryBaseFare, currency).Float64(),
+		DeliveryPerKmRate: money.FromNumeric(c.DeliveryPerKmRate, currency).Float64(),
+		PoolingEnabled:    c.PoolingEnabled,
+		DeliveriesEnabled: c.DeliveriesEnabled,
+		SchedulingEnabled: c.SchedulingEnabled,
+		StopsEnabled:      c.StopsEnabled,
+		Active:            c.Active,
+		UpdatedAt:         c.UpdatedAt.Time,
+	}
+}
@@ -0,0 +1,375 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/namycodes/yanga-services/services/trip-service/internal/db"
+	"github.com/namycodes/yanga-services/services/trip-service/internal/repository"
+	"github.com/namycodes/yanga-services/shared-lib/domain"
+	"github.com/namycodes/yanga-services/shared-lib/events"
+	"github.com/namycodes/yanga-services/shared-lib/utils"
+)
+
+// After this many wrong PINs the parcel can only be handed over through
+// support.
+const maxPinAttempts = 5
+
+var (
+	ErrInvalidDelivery        = errors.New("invalid delivery")
+	ErrNotDeliveryTrip        = errors.New("trip is not a delivery")
+	ErrVehicleNotEligible     = errors.New("vehicle type can't carry this parcel")
+	ErrParcelAlreadyPickedUp  = errors.New("parcel has already been picked up")
+	ErrParcelNotPickedUp      = errors.New("parcel has not been picked up yet")
+	ErrParcelAlreadyDelivered = errors.New("parcel has already been delivered")
+	ErrParcelNotDelivered     = errors.New("parcel delivery has not been confirmed")
+	ErrInvalidDeliveryPin     = errors.New("incorrect recipient PIN")
+	ErrDeliveryPinLocked      = errors.New("too many incorrect PIN attempts, contact support")
+)
+
+// parcelCategory is what a parcel size allows and costs.
+type parcelCategory struct {
+	maxWeightKg  float64
+	surcharge    float64
+	vehicleTypes []string
+}
+
+var parcelCategories = map[string]parcelCategory{
+	domain.ParcelSizeSmall: {
+		maxWeightKg:  5,
+		surcharge:    0,
+		vehicleTypes: []string{domain.VehicleTypeMotorbike, domain.VehicleTypeSedan, domain.VehicleTypeSUV, domain.VehicleTypeVan},
+	},
+	domain.ParcelSizeMedium: {
+		maxWeightKg:  15,
+		surcharge:    30,
+		vehicleTypes: []string{domain.VehicleTypeSedan, domain.VehicleTypeSUV, domain.VehicleTypeVan},
+	},
+	domain.ParcelSizeLarge: {
+		maxWeightKg:  40,
+		surcharge:    80,
+		vehicleTypes: []string{domain.VehicleTypeSUV, domain.VehicleTypeVan},
+	},
+}
+
+func (c parcelCategory) allows(vehicleType string) bool {
+	return slices.Contains(c.vehicleTypes, strings.ToLower(strings.TrimSpace(vehicleType)))
+}
+
+// parcelFor returns the category for a parcel, checking its weight fits.
+func parcelFor(size string, weightKg float64) (parcelCategory, error) {
+	category, ok := parcelCategories[strings.ToLower(strings.TrimSpace(size))]
+	if !ok {
+		return parcelCategory{}, fmt.Errorf("%w: parcel size must be small, medium or large", ErrInvalidDelivery)
+	}
+	if weightKg <= 0 {
+		return parcelCategory{}, fmt.Errorf("%w: parcel weight is required", ErrInvalidDelivery)
+	}
+	if weightKg > category.maxWeightKg {
+		return parcelCategory{}, fmt.Errorf("%w: %s parcels can weigh at most %.0f kg", ErrInvalidDelivery, size, category.maxWeightKg)
+	}
+	return category, nil
+}
+
+type DeliveryService struct {
+	tripRepo *repository.TripRepository
+	eventBus events.EventBus
+}
+
+func NewDeliveryService(tripRepo *repository.TripRepository, eventBus events.EventBus) *DeliveryService {
+	return &DeliveryService{
+		tripRepo: tripRepo,
+		eventBus: eventBus,
+	}
+}
+
+// validateRequest checks a delivery booking and returns its parcel category.
+// Deliveries go straight from sender to recipient, so they can't be pooled or
+// have stops.
+func (s *DeliveryService) validateRequest(req *domain.CreateTripRequest, vehicleType string) (parcelCategory, error) {
+	parcel := req.Delivery
+	if parcel == nil {
+		return parcelCategory{}, fmt.Errorf("%w: parcel details are required", ErrInvalidDelivery)
+	}
+	if req.Pooled {
+		return parcelCategory{}, fmt.Errorf("%w: deliveries can't be pooled", ErrInvalidDelivery)
+	}
+	if len(req.Stops) > 0 {
+		return parcelCategory{}, fmt.Errorf("%w: deliveries can't have stops", ErrInvalidDelivery)
+	}
+	if strings.TrimSpace(parcel.SenderName) == "" || strings.TrimSpace(parcel.SenderPhone) == "" {
+		return parcelCategory{}, fmt.Errorf("%w: sender name and phone are required", ErrInvalidDelivery)
+	}
+	if strings.TrimSpace(parcel.RecipientName) == "" || strings.TrimSpace(parcel.RecipientPhone) == "" {
+		return parcelCategory{}, fmt.Errorf("%w: recipient name and phone are required", ErrInvalidDelivery)
+	}
+
+	category, err := parcelFor(parcel.ParcelSize, parcel.WeightKg)
+	if err != nil {
+		return parcelCategory{}, err
+	}
+	if vehicleType != "" && !category.allows(vehicleType) {
+		return parcelCategory{}, fmt.Errorf("%w: %s", ErrVehicleNotEligible, vehicleType)
+	}
+	return category, nil
+}
+
+// create stores the parcel details for a new delivery trip along with the
+// PIN the recipient gives the driver on handover.
+func (s *DeliveryService) create(ctx context.Context, q *db.Queries, tripID pgtype.UUID, parcel *domain.ParcelDeliveryRequest) error {
+	pin, err := newDeliveryPin()
+	if err != nil {
+		return fmt.Errorf("failed to generate delivery PIN: %w", err)
+	}
+
+	if _, err := q.CreateParcelDelivery(ctx, db.CreateParcelDeliveryParams{
+		TripID:         tripID,
+		SenderName:     strings.TrimSpace(parcel.SenderName),
+		SenderPhone:    strings.TrimSpace(parcel.SenderPhone),
+		RecipientName:  strings.TrimSpace(parcel.RecipientName),
+		RecipientPhone: strings.TrimSpace(parcel.RecipientPhone),
+		ParcelSize:     strings.ToLower(strings.TrimSpace(parcel.ParcelSize)),
+		WeightKg:       utils.Float64ToNumeric(parcel.WeightKg),
+		Description:    pgtype.Text{String: parcel.Description, Valid: parcel.Description != ""},
+		Instructions:   pgtype.Text{String: parcel.Instructions, Valid: parcel.Instructions != ""},
+		RecipientPin:   pin,
+	}); err != nil {
+		return fmt.Errorf("failed to create parcel delivery: %w", err)
+	}
+	return nil
+}
+
+// GetDelivery returns a delivery's parcel details. Only the sender and admins
+// see the recipient PIN; the driver must get it from the recipient.
+func (s *DeliveryService) GetDelivery(ctx context.Context, tripID, callerID uuid.UUID, role string) (*domain.ParcelDeliveryResponse, error) {
+	trip, err := s.tripRepo.GetTrip(ctx, utils.ToPgUUID(tripID))
+	if err != nil {
+		return nil, ErrTripNotFound
+	}
+	if !isTripParticipant(trip, callerID) && role != "admin" {
+		return nil, ErrNotTripParticipant
+	}
+	if trip.TripType != domain.TripTypeDelivery {
+		return nil, ErrNotDeliveryTrip
+	}
+
+	parcel, err := s.tripRepo.GetParcelDelivery(ctx, trip.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get parcel delivery: %w", err)
+	}
+
+	resp := toParcelDeliveryResponse(parcel)
+	if utils.FromPgUUID(trip.UserID) == callerID || role == "admin" {
+		resp.RecipientPin = parcel.RecipientPin
+	}
+	return resp, nil
+}
+
+// ConfirmPickup records the driver's photo of the parcel as proof of pickup.
+func (s *DeliveryService) ConfirmPickup(ctx context.Context, tripID, driverID uuid.UUID, photoRef string) error {
+	trip, err := s.loadDriverDelivery(ctx, tripID, driverID)
+	if err != nil {
+		return err
+	}
+	if trip.Status != domain.TripStatusAccepted && trip.Status != domain.TripStatusInProgress {
+		return ErrTripNotInProgress
+	}
+	if strings.TrimSpace(photoRef) == "" {
+		return fmt.Errorf("%w: a pickup photo is required", ErrInvalidDelivery)
+	}
+
+	updated, err := s.tripRepo.RecordParcelPickup(ctx, db.RecordParcelPickupParams{
+		TripID:         trip.ID,
+		PickupPhotoRef: pgtype.Text{String: photoRef, Valid: true},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record parcel pickup: %w", err)
+	}
+	if updated == 0 {
+		return ErrParcelAlreadyPickedUp
+	}
+
+	s.publishParcelEvent(ctx, events.SubjectParcelPickedUp, trip, photoRef)
+	return nil
+}
+
+// ConfirmDelivery records proof of delivery once the recipient's PIN checks
+// out. Repeated wrong PINs lock the handover.
+func (s *DeliveryService) ConfirmDelivery(ctx context.Context, tripID, driverID uuid.UUID, photoRef, pin string) error {
+	trip, err := s.loadDriverDelivery(ctx, tripID, driverID)
+	if err != nil {
+		return err
+	}
+	if trip.Status != domain.TripStatusInProgress {
+		return ErrTripNotInProgress
+	}
+	if strings.TrimSpace(photoRef) == "" {
+		return fmt.Errorf("%w: a delivery photo is required", ErrInvalidDelivery)
+	}
+
+	parcel, err := s.tripRepo.GetParcelDelivery(ctx, trip.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get parcel delivery: %w", err)
+	}
+	switch {
+	case parcel.DeliveredAt.Valid:
+		return ErrParcelAlreadyDelivered
+	case !parcel.PickedUpAt.Valid:
+		return ErrParcelNotPickedUp
+	case parcel.PinAttempts >= maxPinAttempts:
+		return ErrDeliveryPinLocked
+	}
+
+	if subtle.ConstantTimeCompare([]byte(strings.TrimSpace(pin)), []byte(parcel.RecipientPin)) != 1 {
+		attempts, err := s.tripRepo.IncrementParcelPinAttempts(ctx, trip.ID)
+		if err != nil {
+			return fmt.Errorf("failed to record PIN attempt: %w", err)
+		}
+		if attempts >= maxPinAttempts {
+			return ErrDeliveryPinLocked
+		}
+		return fmt.Errorf("%w: %d attempts left", ErrInvalidDeliveryPin, maxPinAttempts-attempts)
+	}
+
+	updated, err := s.tripRepo.RecordParcelDelivery(ctx, db.RecordParcelDeliveryParams{
+		TripID:           trip.ID,
+		DeliveryPhotoRef: pgtype.Text{String: photoRef, Valid: true},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record parcel delivery: %w", err)
+	}
+	if updated == 0 {
+		return ErrParcelAlreadyDelivered
+	}
+
+	s.publishParcelEvent(ctx, events.SubjectParcelDelivered, trip, photoRef)
+	return nil
+}
+
+// requireDelivered stops a delivery trip from completing before the parcel
+// has been handed over.
+func (s *DeliveryService) requireDelivered(ctx context.Context, q *db.Queries, trip db.Trip) error {
+	if trip.TripType != domain.TripTypeDelivery {
+		return nil
+	}
+	parcel, err := q.GetParcelDelivery(ctx, trip.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get parcel delivery: %w", err)
+	}
+	if !parcel.DeliveredAt.Valid {
+		return ErrParcelNotDelivered
+	}
+	return nil
+}
+
+func (s *DeliveryService) loadDriverDelivery(ctx context.Context, tripID, driverID uuid.UUID) (db.Trip, error) {
+	trip, err := s.tripRepo.GetTrip(ctx, utils.ToPgUUID(tripID))
+	if err != nil {
+		return db.Trip{}, ErrTripNotFound
+	}
+	if !trip.DriverID.Valid || utils.FromPgUUID(trip.DriverID) != driverID {
+		return db.Trip{}, ErrNotTripParticipant
+	}
+	if trip.TripType != domain.TripTypeDelivery {
+		return db.Trip{}, ErrNotDeliveryTrip
+	}
+	return trip, nil
+}
+
+func (s *DeliveryService) publishParcelEvent(ctx context.Context, subject string, trip db.Trip, photoRef string) {
+	s.eventBus.Publish(ctx, subject, events.TripParcelEvent{
+		TripID:    utils.FromPgUUID(trip.ID).String(),
+		UserID:    utils.FromPgUUID(trip.UserID).String(),
+		DriverID:  utils.FromPgUUID(trip.DriverID).String(),
+		PhotoRef:  photoRef,
+		Timestamp: time.Now(),
+	})
+}
+
+// checkVehicleEligibility makes sure a driver's vehicle can carry a delivery
+// trip's parcel and matches any vehicle type the sender asked for. Rides are
+// open to every vehicle.
+func checkVehicleEligibility(ctx context.Context, tripRepo *repository.TripRepository, trip db.Trip, driverID pgtype.UUID) error {
+	if trip.TripType != domain.TripTypeDelivery {
+		return nil
+	}
+
+	parcel, err := tripRepo.GetParcelDelivery(ctx, trip.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get parcel delivery: %w", err)
+	}
+	vehicleType, err := tripRepo.GetDriverVehicleType(ctx, driverID)
+	if err != nil {
+		return fmt.Errorf("failed to get driver vehicle: %w", err)
+	}
+
+	if !parcelCategories[parcel.ParcelSize].allows(vehicleType) {
+		return fmt.Errorf("%w: %s parcels need one of %s", ErrVehicleNotEligible, parcel.ParcelSize, strings.Join(parcelCategories[parcel.ParcelSize].vehicleTypes, ", "))
+	}
+	if trip.VehicleType.Valid && !strings.EqualFold(trip.VehicleType.String, vehicleType) {
+		return fmt.Errorf("%w: sender asked for a %s", ErrVehicleNotEligible, trip.VehicleType.String)
+	}
+	return nil
+}
+
+// dispatchVehicleTypes lists the vehicles a trip may be offered to: the one
+// the sender asked for, else every vehicle that can carry the parcel. Rides
+// return nil, meaning any vehicle.
+func dispatchVehicleTypes(ctx context.Context, tripRepo *repository.TripRepository, trip db.Trip) []string {
+	if trip.TripType != domain.TripTypeDelivery {
+		return nil
+	}
+	if trip.VehicleType.Valid {
+		return []string{trip.VehicleType.String}
+	}
+
+	parcel, err := tripRepo.GetParcelDelivery(ctx, trip.ID)
+	if err != nil {
+		// Drivers are checked again when they accept, so matching can go
+		// ahead unfiltered.
+		log.Printf("Failed to load parcel for trip %s: %v", utils.FromPgUUID(trip.ID), err)
+		return nil
+	}
+	return parcelCategories[parcel.ParcelSize].vehicleTypes
+}
+
+func newDeliveryPin() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(10000))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%04d", n.Int64()), nil
+}
+
+func toParcelDeliveryResponse(parcel db.ParcelDelivery) *domain.ParcelDeliveryResponse {
+	resp := &domain.ParcelDeliveryResponse{
+		TripID:           utils.FromPgUUID(parcel.TripID).String(),
+		SenderName:       parcel.SenderName,
+		SenderPhone:      parcel.SenderPhone,
+		RecipientName:    parcel.RecipientName,
+		RecipientPhone:   parcel.RecipientPhone,
+		ParcelSize:       parcel.ParcelSize,
+		WeightKg:         utils.NumericToFloat64(parcel.WeightKg),
+		Description:      parcel.Description.String,
+		Instructions:     parcel.Instructions.String,
+		PickupPhotoRef:   parcel.PickupPhotoRef.String,
+		DeliveryPhotoRef: parcel.DeliveryPhotoRef.String,
+	}
+	if parcel.PickedUpAt.Valid {
+		resp.PickedUpAt = &parcel.PickedUpAt.Time
+	}
+	if parcel.DeliveredAt.Valid {
+		resp.DeliveredAt = &parcel.DeliveredAt.Time
+	}
+	return resp
+}
@@ -0,0 +1,547 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/namycodes/yanga-services/services/trip-service/internal/db"
+	"github.com/namycodes/yanga-services/services/trip-service/internal/repository"
+	"github.com/namycodes/yanga-services/shared-lib/domain"
+	"github.com/namycodes/yanga-services/shared-lib/events"
+	"github.com/namycodes/yanga-services/shared-lib/geofence"
+	"github.com/namycodes/yanga-services/shared-lib/money"
+	"github.com/namycodes/yanga-services/shared-lib/routing"
+	"github.com/namycodes/yanga-services/shared-lib/utils"
+)
+
+var (
+	ErrInvalidGeofence    = errors.New("invalid geofence")
+	ErrGeofenceNotFound   = errors.New("geofence not found")
+	ErrOutsideServiceArea = errors.New("location is outside our service area")
+	ErrPickupRestricted   = errors.New("pickups are not allowed here")
+)
+
+// GeofenceService manages service areas and zones, checks trips against
+// them and tracks which geofences drivers are inside.
+type GeofenceService struct {
+	repo        *repository.GeofenceRepository
+	cityService *CityService
+	eventBus    events.EventBus
+}
+
+func NewGeofenceService(repo *repository.GeofenceRepository, cityService *CityService, eventBus events.EventBus) *GeofenceService {
+	return &GeofenceService{
+		repo:        repo,
+		cityService: cityService,
+		eventBus:    eventBus,
+	}
+}
+
+func (s *GeofenceService) ListGeofences(ctx context.Context, cityCode, kind string) ([]domain.GeofenceResponse, error) {
+	params := db.ListGeofencesParams{}
+	if cityCode = strings.ToLower(strings.TrimSpace(cityCode)); cityCode != "" {
+		params.CityCode = pgtype.Text{String: cityCode, Valid: true}
+	}
+	if kind = strings.TrimSpace(kind); kind != "" {
+		params.Kind = pgtype.Text{String: kind, Valid: true}
+	}
+
+	geofences, err := s.repo.ListGeofences(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list geofences: %w", err)
+	}
+	resp := make([]domain.GeofenceResponse, 0, len(geofences))
+	for _, g := range geofences {
+		resp = append(resp, *toGeofenceResponse(g))
+	}
+	return resp, nil
+}
+
+func (s *GeofenceService) GetGeofence(ctx context.Context, id uuid.UUID) (*domain.GeofenceResponse, error) {
+	g, err := s.repo.GetGeofence(ctx, utils.ToPgUUID(id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrGeofenceNotFound
+		}
+		return nil, fmt.Errorf("failed to get geofence: %w", err)
+	}
+	return toGeofenceResponse(g), nil
+}
+
+func (s *GeofenceService) CreateGeofence(ctx context.Context, adminID uuid.UUID, req *domain.GeofenceRequest) (*domain.GeofenceResponse, error) {
+	def, err := parseGeofenceRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkParent(ctx, def); err != nil {
+		return nil, err
+	}
+	currency, err := s.feeCurrency(ctx, def)
+	if err != nil {
+		return nil, err
+	}
+
+	g, err := s.repo.CreateGeofence(ctx, db.CreateGeofenceParams{
+		CityCode:     def.cityCode,
+		Name:         def.name,
+		Kind:         def.kind,
+		Polygon:      def.polygonJSON,
+		MinLatitude:  utils.Float64ToNumeric(def.min.Lat),
+		MaxLatitude:  utils.Float64ToNumeric(def.max.Lat),
+		MinLongitude: utils.Float64ToNumeric(def.min.Lng),
+		MaxLongitude: utils.Float64ToNumeric(def.max.Lng),
+		PickupFee:    money.FromFloat(def.pickupFee, currency).Numeric(),
+		DropoffFee:   money.FromFloat(def.dropoffFee, currency).Numeric(),
+		Active:       def.active,
+		CreatedBy:    utils.ToPgUUID(adminID),
+		ParentID:     def.parentID,
+	})
+	if err != nil {
+		if repository.IsForeignKeyViolation(err) {
+			return nil, fmt.Errorf("%w: city %q doesn't exist", ErrInvalidGeofence, def.cityCode)
+		}
+		return nil, fmt.Errorf("failed to create geofence: %w", err)
+	}
+	return toGeofenceResponse(g), nil
+}
+
+// UpdateGeofence replaces a geofence's definition. Drivers inside it are
+// re-evaluated on their next location update.
+func (s *GeofenceService) UpdateGeofence(ctx context.Context, id uuid.UUID, req *domain.GeofenceRequest) (*domain.GeofenceResponse, error) {
+	def, err := parseGeofenceRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	if def.parentID == utils.ToPgUUID(id) {
+		return nil, fmt.Errorf("%w: a geofence cannot be its own airport", ErrInvalidGeofence)
+	}
+	if err := s.checkParent(ctx, def); err != nil {
+		return nil, err
+	}
+	currency, err := s.feeCurrency(ctx, def)
+	if err != nil {
+		return nil, err
+	}
+
+	g, err := s.repo.UpdateGeofence(ctx, db.UpdateGeofenceParams{
+		ID:           utils.ToPgUUID(id),
+		CityCode:     def.cityCode,
+		Name:         def.name,
+		Kind:         def.kind,
+		Polygon:      def.polygonJSON,
+		MinLatitude:  utils.Float64ToNumeric(def.min.Lat),
+		MaxLatitude:  utils.Float64ToNumeric(def.max.Lat),
+		MinLongitude: utils.Float64ToNumeric(def.min.Lng),
+		MaxLongitude: utils.Float64ToNumeric(def.max.Lng),
+		PickupFee:    money.FromFloat(def.pickupFee, currency).Numeric(),
+		DropoffFee:   money.FromFloat(def.dropoffFee, currency).Numeric(),
+		Active:       def.active,
+		ParentID:     def.parentID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrGeofenceNotFound
+		}
+		if repository.IsForeignKeyViolation(err) {
+			return nil, fmt.Errorf("%w: city %q doesn't exist", ErrInvalidGeofence, def.cityCode)
+		}
+		return nil, fmt.Errorf("failed to update geofence: %w", err)
+	}
+	return toGeofenceResponse(g), nil
+}
+
+func (s *GeofenceService) DeleteGeofence(ctx context.Context, id uuid.UUID) error {
+	deleted, err := s.repo.DeleteGeofence(ctx, utils.ToPgUUID(id))
+	if err != nil {
+		return fmt.Errorf("failed to delete geofence: %w", err)
+	}
+	if deleted == 0 {
+		return ErrGeofenceNotFound
+	}
+	return nil
+}
+
+// checkParent checks a staging lot belongs to an airport in the same city.
+func (s *GeofenceService) checkParent(ctx context.Context, def geofenceDefinition) error {
+	if !def.parentID.Valid {
+		return nil
+	}
+	parent, err := s.repo.GetGeofence(ctx, def.parentID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("%w: airport not found", ErrInvalidGeofence)
+		}
+		return fmt.Errorf("failed to get airport: %w", err)
+	}
+	if parent.Kind != domain.GeofenceKindAirport || parent.CityCode != def.cityCode {
+		return fmt.Errorf("%w: a staging lot must belong to an airport in the same city", ErrInvalidGeofence)
+	}
+	return nil
+}
+
+// feeCurrency returns the currency of the geofence's city, which its fees
+// are charged in.
+func (s *GeofenceService) feeCurrency(ctx context.Context, def geofenceDefinition) (money.Currency, error) {
+	currency, err := s.cityService.currencyOf(ctx, def.cityCode)
+	if errors.Is(err, ErrCityNotFound) {
+		return "", fmt.Errorf("%w: city %q doesn't exist", ErrInvalidGeofence, def.cityCode)
+	}
+	return currency, err
+}
+
+// tripZones is what the geofences around a trip's route mean for it.
+type tripZones struct {
+	cityCode        string // city of the service area holding the pickup
+	pickupAirports  []db.Geofence
+	dropoffAirports []db.Geofence
+}
+
+// zoneFee returns the airport fees on the trip in its city's currency.
+// Where airports overlap, only the highest fee applies.
+func (z tripZones) zoneFee(currency money.Currency) money.Money {
+	pickupFee, dropoffFee := money.Zero(currency), money.Zero(currency)
+	for _, g := range z.pickupAirports {
+		pickupFee = money.Max(pickupFee, money.FromNumeric(g.PickupFee, currency))
+	}
+	for _, g := range z.dropoffAirports {
+		dropoffFee = money.Max(dropoffFee, money.FromNumeric(g.DropoffFee, currency))
+	}
+	return pickupFee.Add(dropoffFee)
+}
+
+// checkTrip checks a trip's pickup, stops and dropoff lie inside a service
+// area and that the pickup isn't in a restricted zone. Until any service
+// area has been drawn, trips may go anywhere.
+func (s *GeofenceService) checkTrip(ctx context.Context, pickup, dropoff routing.Point, stops []routing.Point) (tripZones, error) {
+	pickupZones, err := s.zonesAt(ctx, pickup)
+	if err != nil {
+		return tripZones{}, err
+	}
+	dropoffZones, err := s.zonesAt(ctx, dropoff)
+	if err != nil {
+		return tripZones{}, err
+	}
+
+	for _, g := range pickupZones {
+		if g.Kind == domain.GeofenceKindRestricted {
+			return tripZones{}, fmt.Errorf("%w: %s", ErrPickupRestricted, g.Name)
+		}
+	}
+
+	var zones tripZones
+	bounded, err := s.serviceAreasDefined(ctx)
+	if err != nil {
+		return tripZones{}, err
+	}
+	if bounded {
+		area, ok := findZone(pickupZones, domain.GeofenceKindServiceArea)
+		if !ok {
+			return tripZones{}, fmt.Errorf("%w: pickup", ErrOutsideServiceArea)
+		}
+		zones.cityCode = area.CityCode
+		if _, ok := findZone(dropoffZones, domain.GeofenceKindServiceArea); !ok {
+			return tripZones{}, fmt.Errorf("%w: dropoff", ErrOutsideServiceArea)
+		}
+		for i, stop := range stops {
+			if err := s.requireServiceArea(ctx, stop, fmt.Sprintf("stop %d", i+1)); err != nil {
+				return tripZones{}, err
+			}
+		}
+	}
+
+	for _, g := range pickupZones {
+		if g.Kind == domain.GeofenceKindAirport {
+			zones.pickupAirports = append(zones.pickupAirports, g)
+		}
+	}
+	for _, g := range dropoffZones {
+		if g.Kind == domain.GeofenceKindAirport {
+			zones.dropoffAirports = append(zones.dropoffAirports, g)
+		}
+	}
+	return zones, nil
+}
+
+// checkStop checks a stop added to a trip lies inside a service area.
+func (s *GeofenceService) checkStop(ctx context.Context, stop routing.Point) error {
+	bounded, err := s.serviceAreasDefined(ctx)
+	if err != nil || !bounded {
+		return err
+	}
+	return s.requireServiceArea(ctx, stop, "stop")
+}
+
+func (s *GeofenceService) requireServiceArea(ctx context.Context, at routing.Point, what string) error {
+	zones, err := s.zonesAt(ctx, at)
+	if err != nil {
+		return err
+	}
+	if _, ok := findZone(zones, domain.GeofenceKindServiceArea); !ok {
+		return fmt.Errorf("%w: %s", ErrOutsideServiceArea, what)
+	}
+	return nil
+}
+
+func (s *GeofenceService) serviceAreasDefined(ctx context.Context) (bool, error) {
+	count, err := s.repo.CountActiveServiceAreas(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to count service areas: %w", err)
+	}
+	return count > 0, nil
+}
+
+// zonesAt returns the active geofences containing a point.
+func (s *GeofenceService) zonesAt(ctx context.Context, at routing.Point) ([]db.Geofence, error) {
+	candidates, err := s.repo.GetGeofencesAround(ctx, db.GetGeofencesAroundParams{
+		Latitude:  utils.Float64ToNumeric(at.Lat),
+		Longitude: utils.Float64ToNumeric(at.Lng),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get geofences: %w", err)
+	}
+
+	var inside []db.Geofence
+	for _, g := range candidates {
+		polygon, err := decodePolygon(g.Polygon)
+		if err != nil {
+			log.Printf("Skipping geofence %s with unreadable polygon: %v", utils.FromPgUUID(g.ID), err)
+			continue
+		}
+		if polygon.Contains(at) {
+			inside = append(inside, g)
+		}
+	}
+	return inside, nil
+}
+
+// HandleDriverLocation records which geofences a driver is inside after a
+// location update, publishing an event for each one entered or left.
+func (s *GeofenceService) HandleDriverLocation(ctx context.Context, driverID uuid.UUID, at routing.Point) error {
+	current, err := s.zonesAt(ctx, at)
+	if err != nil {
+		return err
+	}
+	previous, err := s.repo.GetDriverGeofences(ctx, utils.ToPgUUID(driverID))
+	if err != nil {
+		return fmt.Errorf("failed to get driver geofences: %w", err)
+	}
+
+	inside := make(map[pgtype.UUID]bool, len(current))
+	for _, g := range current {
+		inside[g.ID] = true
+		entered, err := s.repo.EnterGeofence(ctx, db.EnterGeofenceParams{
+			DriverID:   utils.ToPgUUID(driverID),
+			GeofenceID: g.ID,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to record geofence entry: %w", err)
+		}
+		// Already inside; a concurrent update may also have recorded it.
+		if entered == 1 {
+			s.publishGeofenceEvent(ctx, events.SubjectGeofenceEntered, driverID, g)
+		}
+	}
+
+	for _, g := range previous {
+		if inside[g.ID] {
+			continue
+		}
+		if err := s.exitGeofence(ctx, driverID, g); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// HandleDriverOffline takes a driver out of every geofence they were in.
+func (s *GeofenceService) HandleDriverOffline(ctx context.Context, driverID uuid.UUID) error {
+	previous, err := s.repo.GetDriverGeofences(ctx, utils.ToPgUUID(driverID))
+	if err != nil {
+		return fmt.Errorf("failed to get driver geofences: %w", err)
+	}
+	for _, g := range previous {
+		if err := s.exitGeofence(ctx, driverID, g); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *GeofenceService) exitGeofence(ctx context.Context, driverID uuid.UUID, g db.Geofence) error {
+	exited, err := s.repo.ExitGeofence(ctx, db.ExitGeofenceParams{
+		DriverID:   utils.ToPgUUID(driverID),
+		GeofenceID: g.ID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record geofence exit: %w", err)
+	}
+	if exited == 1 {
+		s.publishGeofenceEvent(ctx, events.SubjectGeofenceExited, driverID, g)
+	}
+	return nil
+}
+
+func (s *GeofenceService) publishGeofenceEvent(ctx context.Context, subject string, driverID uuid.UUID, g db.Geofence) {
+	s.eventBus.Publish(ctx, subject, events.GeofenceEvent{
+		DriverID:   driverID.String(),
+		GeofenceID: utils.FromPgUUID(g.ID).String(),
+		Kind:       g.Kind,
+		CityCode:   g.CityCode,
+		Timestamp:  time.Now(),
+	})
+}
+
+func (s *GeofenceService) SubscribeToEvents() {
+	s.eventBus.QueueSubscribe(events.SubjectDriverLocation, "trip-service", func(ctx context.Context, data []byte) {
+		var payload events.DriverLocationPayload
+		if err := json.Unmarshal(data, &payload); err != nil {
+			log.Printf("Failed to unmarshal driver location event: %v", err)
+			return
+		}
+		driverID, err := uuid.Parse(payload.DriverID)
+		if err != nil {
+			log.Printf("Invalid driver ID in event: %v", err)
+			return
+		}
+
+		at := routing.Point{Lat: payload.Latitude, Lng: payload.Longitude}
+		if err := s.HandleDriverLocation(ctx, driverID, at); err != nil {
+			log.Printf("Failed to update geofences for driver %s: %v", payload.DriverID, err)
+		}
+	})
+
+	s.eventBus.QueueSubscribe(events.SubjectDriverOffline, "trip-service", func(ctx context.Context, data []byte) {
+		var payload events.DriverStatusPayload
+		if err := json.Unmarshal(data, &payload); err != nil {
+			log.Printf("Failed to unmarshal driver offline event: %v", err)
+			return
+		}
+		driverID, err := uuid.Parse(payload.DriverID)
+		if err != nil {
+			log.Printf("Invalid driver ID in event: %v", err)
+			return
+		}
+
+		if err := s.HandleDriverOffline(ctx, driverID); err != nil {
+			log.Printf("Failed to clear geofences for driver %s: %v", payload.DriverID, err)
+		}
+	})
+}
+
+// geofenceDefinition is a validated GeofenceRequest.
+type geofenceDefinition struct {
+	cityCode    string
+	name        string
+	kind        string
+	polygonJSON []byte
+	min, max    routing.Point
+	pickupFee   float64
+	dropoffFee  float64
+	active      bool
+	parentID    pgtype.UUID // airport a staging lot serves
+}
+
+func parseGeofenceRequest(req *domain.GeofenceRequest) (geofenceDefinition, error) {
+	def := geofenceDefinition{
+		cityCode: strings.ToLower(strings.TrimSpace(req.CityCode)),
+		name:     strings.TrimSpace(req.Name),
+		kind:     strings.TrimSpace(req.Kind),
+		active:   req.Active == nil || *req.Active,
+	}
+	if !cityCodePattern.MatchString(def.cityCode) {
+		return geofenceDefinition{}, ErrInvalidCityCode
+	}
+	if def.name == "" || len(def.name) > 100 {
+		return geofenceDefinition{}, fmt.Errorf("%w: name is required and at most 100 characters", ErrInvalidGeofence)
+	}
+	switch def.kind {
+	case domain.GeofenceKindServiceArea, domain.GeofenceKindRestricted, domain.GeofenceKindAirport, domain.GeofenceKindStaging:
+	default:
+		return geofenceDefinition{}, fmt.Errorf("%w: kind must be service_area, restricted, airport or staging", ErrInvalidGeofence)
+	}
+	if parentID := strings.TrimSpace(req.ParentID); parentID != "" {
+		id, err := uuid.Parse(parentID)
+		if err != nil {
+			return geofenceDefinition{}, fmt.Errorf("%w: invalid parent_id", ErrInvalidGeofence)
+		}
+		def.parentID = utils.ToPgUUID(id)
+	}
+	if (def.kind == domain.GeofenceKindStaging) != def.parentID.Valid {
+		return geofenceDefinition{}, fmt.Errorf("%w: staging lots, and only staging lots, need the parent_id of their airport", ErrInvalidGeofence)
+	}
+	if req.PickupFee < 0 || req.DropoffFee < 0 {
+		return geofenceDefinition{}, fmt.Errorf("%w: fees cannot be negative", ErrInvalidGeofence)
+	}
+	if def.kind != domain.GeofenceKindAirport && (req.PickupFee > 0 || req.DropoffFee > 0) {
+		return geofenceDefinition{}, fmt.Errorf("%w: only airports charge fees", ErrInvalidGeofence)
+	}
+	def.pickupFee = req.PickupFee
+	def.dropoffFee = req.DropoffFee
+
+	polygon := make(geofence.Polygon, len(req.Polygon))
+	for i, v := range req.Polygon {
+		polygon[i] = routing.Point{Lat: v.Latitude, Lng: v.Longitude}
+	}
+	if err := polygon.Validate(); err != nil {
+		return geofenceDefinition{}, fmt.Errorf("%w: %v", ErrInvalidGeofence, err)
+	}
+	def.min, def.max = polygon.Bounds()
+
+	polygonJSON, err := json.Marshal(req.Polygon)
+	if err != nil {
+		return geofenceDefinition{}, fmt.Errorf("failed to encode polygon: %w", err)
+	}
+	def.polygonJSON = polygonJSON
+	return def, nil
+}
+
+func findZone(zones []db.Geofence, kind string) (db.Geofence, bool) {
+	for _, g := range zones {
+		if g.Kind == kind {
+			return g, true
+		}
+	}
+	return db.Geofence{}, false
+}
+
+func decodePolygon(data []byte) (geofence.Polygon, error) {
+	var vertices []domain.GeoPoint
+	if err := json.Unmarshal(data, &vertices); err != nil {
+		return nil, err
+	}
+	polygon := make(geofence.Polygon, len(vertices))
+	for i, v := range vertices {
+		polygon[i] = routing.Point{Lat: v.Latitude, Lng: v.Longitude}
+	}
+	return polygon, nil
+}
+
+func toGeofenceResponse(g db.Geofence) *domain.GeofenceResponse {
+	resp := &domain.GeofenceResponse{
+		ID:         utils.FromPgUUID(g.ID).String(),
+		CityCode:   g.CityCode,
+		Name:       g.Name,
+		Kind:       g.Kind,
+		Polygon:    []domain.GeoPoint{},
+		PickupFee:  utils.NumericToFloat64(g.PickupFee),
+		DropoffFee: utils.NumericToFloat64(g.DropoffFee),
+		Active:     g.Active,
+		CreatedAt:  g.CreatedAt.Time,
+		UpdatedAt:  g.UpdatedAt.Time,
+	}
+	if g.ParentID.Valid {
+		resp.ParentID = utils.FromPgUUID(g.ParentID).String()
+	}
+	if err := json.Unmarshal(g.Polygon, &resp.Polygon); err != nil {
+		log.Printf("Failed to decode polygon of geofence %s: %v", resp.ID, err)
+	}
+	return resp
+}
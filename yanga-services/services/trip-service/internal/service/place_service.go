@@ -0,0 +1,276 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/namycodes/yanga-services/services/trip-service/internal/db"
+	"github.com/namycodes/yanga-services/services/trip-service/internal/repository"
+	"github.com/namycodes/yanga-services/shared-lib/config"
+	"github.com/namycodes/yanga-services/shared-lib/domain"
+	"github.com/namycodes/yanga-services/shared-lib/geocoding"
+	"github.com/namycodes/yanga-services/shared-lib/routing"
+	"github.com/namycodes/yanga-services/shared-lib/utils"
+)
+
+const (
+	maxFavoritePlaces  = 20
+	defaultSearchLimit = 5
+	maxSearchLimit     = 20
+)
+
+var (
+	ErrInvalidPlace    = errors.New("invalid place")
+	ErrPlaceNotFound   = errors.New("place not found")
+	ErrTooManyPlaces   = errors.New("too many saved places")
+	ErrAddressMismatch = errors.New("address does not match its coordinates")
+	ErrAddressUnknown  = errors.New("address is required")
+)
+
+// PlaceService looks places up through the geocoder and keeps each user's
+// saved places. It also checks the addresses sent with trip bookings.
+type PlaceService struct {
+	placeRepo   *repository.PlaceRepository
+	geocoder    geocoding.Provider
+	toleranceKm float64
+}
+
+func NewPlaceService(placeRepo *repository.PlaceRepository, geocoder geocoding.Provider, cfg *config.Config) *PlaceService {
+	return &PlaceService{
+		placeRepo:   placeRepo,
+		geocoder:    geocoder,
+		toleranceKm: float64(cfg.GeocodingAddressToleranceMeters) / 1000,
+	}
+}
+
+// Search autocompletes a partly typed place name, ranking places near the
+// bias point first when one is given.
+func (s *PlaceService) Search(ctx context.Context, query string, near *routing.Point, limit int) ([]domain.PlaceResponse, error) {
+	if strings.TrimSpace(query) == "" {
+		return nil, fmt.Errorf("%w: search query is required", ErrInvalidPlace)
+	}
+	if limit <= 0 {
+		limit = defaultSearchLimit
+	}
+	if limit > maxSearchLimit {
+		limit = maxSearchLimit
+	}
+
+	places, err := s.geocoder.Search(ctx, query, near, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search places: %w", err)
+	}
+	responses := make([]domain.PlaceResponse, len(places))
+	for i, place := range places {
+		responses[i] = toPlaceResponse(place)
+	}
+	return responses, nil
+}
+
+// Reverse names the place at a point.
+func (s *PlaceService) Reverse(ctx context.Context, lat, lng float64) (*domain.PlaceResponse, error) {
+	if err := validateCoordinates(lat, lng); err != nil {
+		return nil, err
+	}
+	place, err := s.geocoder.Reverse(ctx, routing.Point{Lat: lat, Lng: lng})
+	if errors.Is(err, geocoding.ErrNotFound) {
+		return nil, ErrPlaceNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up place: %w", err)
+	}
+	resp := toPlaceResponse(place)
+	return &resp, nil
+}
+
+func (s *PlaceService) ListSavedPlaces(ctx context.Context, userID uuid.UUID) ([]domain.SavedPlaceResponse, error) {
+	places, err := s.placeRepo.ListSavedPlaces(ctx, utils.ToPgUUID(userID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get saved places: %w", err)
+	}
+	responses := make([]domain.SavedPlaceResponse, len(places))
+	for i, place := range places {
+		responses[i] = toSavedPlaceResponse(place)
+	}
+	return responses, nil
+}
+
+// SavePlace saves a place for the user. Home and work replace the place
+// saved under the same label; favorites are added up to a limit.
+func (s *PlaceService) SavePlace(ctx context.Context, userID uuid.UUID, req *domain.SavePlaceRequest) (*domain.SavedPlaceResponse, error) {
+	label := strings.ToLower(strings.TrimSpace(req.Label))
+	name := strings.TrimSpace(req.Name)
+	address := strings.TrimSpace(req.Address)
+	switch label {
+	case domain.PlaceLabelHome, domain.PlaceLabelWork, domain.PlaceLabelFavorite:
+	default:
+		return nil, fmt.Errorf("%w: label must be home, work or favorite", ErrInvalidPlace)
+	}
+	if name == "" || len(name) > 100 {
+		return nil, fmt.Errorf("%w: name is required and at most 100 characters", ErrInvalidPlace)
+	}
+	if address == "" {
+		return nil, fmt.Errorf("%w: address is required", ErrInvalidPlace)
+	}
+	if err := validateCoordinates(req.Latitude, req.Longitude); err != nil {
+		return nil, err
+	}
+	if err := s.checkAddress(ctx, "place", address, req.Latitude, req.Longitude); err != nil {
+		return nil, err
+	}
+
+	pgUserID := utils.ToPgUUID(userID)
+	var (
+		place db.SavedPlace
+		err   error
+	)
+	if label == domain.PlaceLabelFavorite {
+		count, countErr := s.placeRepo.CountSavedPlacesByLabel(ctx, db.CountSavedPlacesByLabelParams{
+			UserID: pgUserID,
+			Label:  label,
+		})
+		if countErr != nil {
+			return nil, fmt.Errorf("failed to count saved places: %w", countErr)
+		}
+		if count >= maxFavoritePlaces {
+			return nil, fmt.Errorf("%w: at most %d favorites can be saved", ErrTooManyPlaces, maxFavoritePlaces)
+		}
+		place, err = s.placeRepo.CreateSavedPlace(ctx, db.CreateSavedPlaceParams{
+			UserID:    pgUserID,
+			Label:     label,
+			Name:      name,
+			Address:   address,
+			Latitude:  utils.Float64ToNumeric(req.Latitude),
+			Longitude: utils.Float64ToNumeric(req.Longitude),
+		})
+	} else {
+		place, err = s.placeRepo.UpsertSavedPlace(ctx, db.UpsertSavedPlaceParams{
+			UserID:    pgUserID,
+			Label:     label,
+			Name:      name,
+			Address:   address,
+			Latitude:  utils.Float64ToNumeric(req.Latitude),
+			Longitude: utils.Float64ToNumeric(req.Longitude),
+		})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to save place: %w", err)
+	}
+
+	resp := toSavedPlaceResponse(place)
+	return &resp, nil
+}
+
+func (s *PlaceService) DeleteSavedPlace(ctx context.Context, userID, placeID uuid.UUID) error {
+	deleted, err := s.placeRepo.DeleteSavedPlace(ctx, db.DeleteSavedPlaceParams{
+		ID:     utils.ToPgUUID(placeID),
+		UserID: utils.ToPgUUID(userID),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete place: %w", err)
+	}
+	if deleted == 0 {
+		return ErrPlaceNotFound
+	}
+	return nil
+}
+
+// resolveTripAddresses checks each address on a booking matches its
+// coordinates, filling in blank pickup and dropoff addresses by reverse
+// geocoding.
+func (s *PlaceService) resolveTripAddresses(ctx context.Context, req *domain.CreateTripRequest) error {
+	if err := s.resolveAddress(ctx, "pickup", &req.PickupAddress, req.PickupLatitude, req.PickupLongitude); err != nil {
+		return err
+	}
+	if err := s.resolveAddress(ctx, "dropoff", &req.DropoffAddress, req.DropoffLatitude, req.DropoffLongitude); err != nil {
+		return err
+	}
+	for i, stop := range req.Stops {
+		if err := s.checkAddress(ctx, fmt.Sprintf("stop %d", i+1), stop.Address, stop.Latitude, stop.Longitude); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *PlaceService) resolveAddress(ctx context.Context, what string, address *string, lat, lng float64) error {
+	*address = strings.TrimSpace(*address)
+	if *address != "" {
+		return s.checkAddress(ctx, what, *address, lat, lng)
+	}
+	if *address = s.describe(ctx, routing.Point{Lat: lat, Lng: lng}); *address == "" {
+		return fmt.Errorf("%w: no %s address given and none is known for its location", ErrAddressUnknown, what)
+	}
+	return nil
+}
+
+// checkAddress rejects an address that geocodes far from its coordinates.
+// The geocoder being unavailable shouldn't stop anyone booking, so lookup
+// failures let the address through.
+func (s *PlaceService) checkAddress(ctx context.Context, what, address string, lat, lng float64) error {
+	if s.toleranceKm <= 0 {
+		return nil
+	}
+	ok, err := geocoding.Corresponds(ctx, s.geocoder, address, routing.Point{Lat: lat, Lng: lng}, s.toleranceKm)
+	if err != nil {
+		log.Printf("Failed to check %s address %q: %v", what, address, err)
+		return nil
+	}
+	if !ok {
+		return fmt.Errorf("%w: %s address %q is not near its location", ErrAddressMismatch, what, address)
+	}
+	return nil
+}
+
+// describe returns the address of the place at a point, or "" if there is
+// none known.
+func (s *PlaceService) describe(ctx context.Context, at routing.Point) string {
+	place, err := s.geocoder.Reverse(ctx, at)
+	if err != nil {
+		if !errors.Is(err, geocoding.ErrNotFound) {
+			log.Printf("Failed to reverse geocode %.6f,%.6f: %v", at.Lat, at.Lng, err)
+		}
+		return ""
+	}
+	if place.Address == place.Name {
+		return place.Name
+	}
+	return place.Name + ", " + place.Address
+}
+
+func validateCoordinates(lat, lng float64) error {
+	if lat == 0 && lng == 0 {
+		return fmt.Errorf("%w: coordinates are required", ErrInvalidPlace)
+	}
+	if lat < -90 || lat > 90 || lng < -180 || lng > 180 {
+		return fmt.Errorf("%w: coordinates are out of range", ErrInvalidPlace)
+	}
+	return nil
+}
+
+func toPlaceResponse(place geocoding.Place) domain.PlaceResponse {
+	return domain.PlaceResponse{
+		Name:      place.Name,
+		Kind:      place.Kind,
+		Address:   place.Address,
+		Latitude:  place.Point.Lat,
+		Longitude: place.Point.Lng,
+	}
+}
+
+func toSavedPlaceResponse(place db.SavedPlace) domain.SavedPlaceResponse {
+	return domain.SavedPlaceResponse{
+		ID:        utils.FromPgUUID(place.ID).String(),
+		Label:     place.Label,
+		Name:      place.Name,
+		Address:   place.Address,
+		Latitude:  utils.NumericToFloat64(place.Latitude),
+		Longitude: utils.NumericToFloat64(place.Longitude),
+		CreatedAt: place.CreatedAt.Time,
+		UpdatedAt: place.UpdatedAt.Time,
+	}
+}
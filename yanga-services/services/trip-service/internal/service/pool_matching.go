@@ -0,0 +1,199 @@
+package service
+
+import (
+	"context"
+	"math"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/namycodes/yanga-services/shared-lib/domain"
+	"github.com/namycodes/yanga-services/shared-lib/routing"
+	"github.com/namycodes/yanga-services/shared-lib/utils"
+)
+
+// minDetourKm keeps very short rides poolable; a percentage of a 1 km ride
+// leaves no room to pick anyone up.
+const minDetourKm = 1.0
+
+// poolStop is a pickup or dropoff on a pool's route that the vehicle has not
+// reached yet.
+type poolStop struct {
+	tripID pgtype.UUID
+	kind   string
+	point  routePoint
+	seats  int
+}
+
+// poolRider is what the matcher needs to know about a rider in the pool.
+type poolRider struct {
+	direct float64 // km, by road from pickup to dropoff
+}
+
+// poolPlan is one ordering of a pool's remaining stops.
+type poolPlan struct {
+	stops    []poolStop
+	distance float64 // km, from the vehicle's position to the last stop
+}
+
+// poolMatcher inserts a rider's pickup and dropoff into a pool's remaining
+// route. Every rider's ride may be longer than their direct distance by at
+// most maxDetour of it, and the vehicle may never carry more than capacity
+// seats. Distances are by road.
+type poolMatcher struct {
+	capacity  int
+	maxDetour float64
+	roads     *roadDistances
+}
+
+// using returns a copy of the matcher that measures distances with roads.
+func (m poolMatcher) using(roads *roadDistances) poolMatcher {
+	m.roads = roads
+	return m
+}
+
+// insert returns the shortest plan that fits pickup and dropoff into the
+// route without breaking any rider's detour limit or the seat capacity.
+// start is the vehicle's position and onboard the seats already taken there.
+func (m poolMatcher) insert(start routePoint, remaining []poolStop, riders map[pgtype.UUID]poolRider, onboard int, pickup, dropoff poolStop) (poolPlan, bool) {
+	direct := m.roads.between(pickup.point, dropoff.point)
+
+	// Riders already over their limit because of earlier approximations can
+	// keep their current ride but mustn't be delayed further.
+	current := m.rideDistances(start, remaining, riders)
+
+	var (
+		best  poolPlan
+		found bool
+	)
+	for i := 0; i <= len(remaining); i++ {
+		for j := i; j <= len(remaining); j++ {
+			stops := make([]poolStop, 0, len(remaining)+2)
+			stops = append(stops, remaining[:i]...)
+			stops = append(stops, pickup)
+			stops = append(stops, remaining[i:j]...)
+			stops = append(stops, dropoff)
+			stops = append(stops, remaining[j:]...)
+
+			if !m.fitsCapacity(stops, onboard) {
+				continue
+			}
+
+			distance := m.routeDistance(start, stops)
+			if found && distance >= best.distance {
+				continue
+			}
+
+			rides := m.rideDistances(start, stops, riders)
+			if rides[pickup.tripID] > m.allowed(direct) {
+				continue
+			}
+			ok := true
+			for tripID, rider := range riders {
+				limit := math.Max(m.allowed(rider.direct), current[tripID])
+				if rides[tripID] > limit {
+					ok = false
+					break
+				}
+			}
+			if !ok {
+				continue
+			}
+
+			best = poolPlan{stops: stops, distance: distance}
+			found = true
+		}
+	}
+	return best, found
+}
+
+func (m poolMatcher) allowed(direct float64) float64 {
+	return direct + math.Max(direct*m.maxDetour, minDetourKm)
+}
+
+func (m poolMatcher) fitsCapacity(stops []poolStop, onboard int) bool {
+	load := onboard
+	for _, stop := range stops {
+		if stop.kind == domain.PoolWaypointPickup {
+			load += stop.seats
+		} else {
+			load -= stop.seats
+		}
+		if load > m.capacity {
+			return false
+		}
+	}
+	return true
+}
+
+// rideDistances returns how far each rider travels in the vehicle along
+// stops. For riders already on board the part of the ride behind them is
+// approximated as the direct distance they have closed so far.
+func (m poolMatcher) rideDistances(start routePoint, stops []poolStop, riders map[pgtype.UUID]poolRider) map[pgtype.UUID]float64 {
+	pickedUp := make(map[pgtype.UUID]float64)
+	rides := make(map[pgtype.UUID]float64)
+
+	var travelled float64
+	prev := start
+	for _, stop := range stops {
+		travelled += m.roads.between(prev, stop.point)
+		prev = stop.point
+
+		if stop.kind == domain.PoolWaypointPickup {
+			pickedUp[stop.tripID] = travelled
+			continue
+		}
+
+		if at, ok := pickedUp[stop.tripID]; ok {
+			rides[stop.tripID] = travelled - at
+			continue
+		}
+		covered := 0.0
+		if rider, ok := riders[stop.tripID]; ok {
+			covered = math.Max(rider.direct-m.roads.between(start, stop.point), 0)
+		}
+		rides[stop.tripID] = travelled + covered
+	}
+	return rides
+}
+
+func (m poolMatcher) routeDistance(start routePoint, stops []poolStop) float64 {
+	var distance float64
+	prev := start
+	for _, stop := range stops {
+		distance += m.roads.between(prev, stop.point)
+		prev = stop.point
+	}
+	return distance
+}
+
+// roadDistances remembers the road distances looked up while matching one
+// booking, since every candidate order reuses the same pairs of points.
+type roadDistances struct {
+	ctx    context.Context
+	router routing.Router
+	known  map[[2]routePoint]float64
+}
+
+func newRoadDistances(ctx context.Context, router routing.Router) *roadDistances {
+	return &roadDistances{
+		ctx:    ctx,
+		router: router,
+		known:  make(map[[2]routePoint]float64),
+	}
+}
+
+// between returns the road distance from a to b in km. If routing fails the
+// straight-line distance stands in rather than failing the booking.
+func (d *roadDistances) between(a, b routePoint) float64 {
+	key := [2]routePoint{a, b}
+	if distance, ok := d.known[key]; ok {
+		return distance
+	}
+
+	distance := utils.CalculateDistance(a.lat, a.lng, b.lat, b.lng)
+	route, err := d.router.Route(d.ctx, routing.Point{Lat: a.lat, Lng: a.lng}, routing.Point{Lat: b.lat, Lng: b.lng})
+	if err == nil {
+		distance = route.Distance
+	}
+	d.known[key] = distance
+	return distance
+}
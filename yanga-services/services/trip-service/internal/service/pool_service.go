@@ -0,0 +1,481 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/namycodes/yanga-services/services/trip-service/internal/db"
+	"github.com/namycodes/yanga-services/services/trip-service/internal/repository"
+	"github.com/namycodes/yanga-services/shared-lib/config"
+	"github.com/namycodes/yanga-services/shared-lib/domain"
+	"github.com/namycodes/yanga-services/shared-lib/events"
+	"github.com/namycodes/yanga-services/shared-lib/money"
+	"github.com/namycodes/yanga-services/shared-lib/routing"
+	"github.com/namycodes/yanga-services/shared-lib/utils"
+)
+
+const (
+	// maxPoolSeats is the most seats one rider can book in a pool.
+	maxPoolSeats = 2
+
+	poolCandidateLimit = 10
+
+	poolChangeJoined = "joined"
+	poolChangeLeft   = "left"
+)
+
+var (
+	ErrInvalidPoolRequest = errors.New("invalid pooled ride request")
+	ErrTripNotPooled      = errors.New("trip is not a pooled ride")
+)
+
+type PoolService struct {
+	tripRepo      *repository.TripRepository
+	router        routing.Router
+	eventBus      events.EventBus
+	matcher       poolMatcher
+	matchRadiusKm float64
+	discountRate  money.Rate
+}
+
+func NewPoolService(tripRepo *repository.TripRepository, router routing.Router, eventBus events.EventBus, cfg *config.Config) *PoolService {
+	return &PoolService{
+		tripRepo: tripRepo,
+		router:   router,
+		eventBus: eventBus,
+		matcher: poolMatcher{
+			capacity:  cfg.PoolSeatCapacity,
+			maxDetour: float64(cfg.PoolMaxDetourPercent) / 100,
+		},
+		matchRadiusKm: float64(cfg.PoolMatchRadiusMeters) / 1000,
+		discountRate:  money.Percent(float64(cfg.PoolDiscountPercent)),
+	}
+}
+
+// validateRequest checks a pooled booking and returns the seats it needs.
+func (s *PoolService) validateRequest(seats int, stops int, scheduled bool) (int, error) {
+	if seats == 0 {
+		seats = 1
+	}
+	if seats < 1 || seats > maxPoolSeats || seats > s.matcher.capacity {
+		return 0, fmt.Errorf("%w: seats must be between 1 and %d", ErrInvalidPoolRequest, min(maxPoolSeats, s.matcher.capacity))
+	}
+	if stops > 0 {
+		return 0, fmt.Errorf("%w: pooled rides can't have stops", ErrInvalidPoolRequest)
+	}
+	if scheduled {
+		return 0, fmt.Errorf("%w: pooled rides can't be scheduled", ErrInvalidPoolRequest)
+	}
+	return seats, nil
+}
+
+// discount returns the pooled discount on a solo subtotal, rounded down in
+// the platform's favour.
+func (s *PoolService) discount(subtotal money.Money) money.Money {
+	return subtotal.Apply(s.discountRate, money.Down)
+}
+
+// join puts a new pooled trip into the best open pool passing near its
+// pickup, or starts a new pool for it. A trip that joins a pool is assigned
+// to the pool's driver straight away. It must run in the transaction that
+// creates the trip.
+func (s *PoolService) join(ctx context.Context, q *db.Queries, trip *db.Trip, seats int) error {
+	pickup := poolStop{
+		tripID: trip.ID,
+		kind:   domain.PoolWaypointPickup,
+		point:  routePoint{utils.NumericToFloat64(trip.PickupLatitude), utils.NumericToFloat64(trip.PickupLongitude)},
+		seats:  seats,
+	}
+	dropoff := pickup
+	dropoff.kind = domain.PoolWaypointDropoff
+	dropoff.point = routePoint{utils.NumericToFloat64(trip.DropoffLatitude), utils.NumericToFloat64(trip.DropoffLongitude)}
+
+	pool, plan, err := s.findPool(ctx, q, trip.VehicleType.String, pickup, dropoff)
+	if err != nil {
+		return err
+	}
+
+	if pool == nil {
+		created, err := q.CreateTripPool(ctx, db.CreateTripPoolParams{
+			VehicleType:  trip.VehicleType.String,
+			SeatCapacity: int32(s.matcher.capacity),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create pool: %w", err)
+		}
+		pool = &created
+		plan = poolPlan{stops: []poolStop{pickup, dropoff}}
+	}
+
+	if *trip, err = q.SetTripPool(ctx, db.SetTripPoolParams{
+		ID:        trip.ID,
+		PoolID:    pool.ID,
+		SeatCount: int32(seats),
+	}); err != nil {
+		return fmt.Errorf("failed to add trip to pool: %w", err)
+	}
+	if err := s.saveRoute(ctx, q, *trip, pool.ID, plan); err != nil {
+		return err
+	}
+
+	if !pool.DriverID.Valid {
+		return nil
+	}
+	if err := q.AssignDriverToTrip(ctx, db.AssignDriverToTripParams{
+		ID:       trip.ID,
+		DriverID: pool.DriverID,
+	}); err != nil {
+		return fmt.Errorf("failed to assign pool driver: %w", err)
+	}
+	if *trip, err = q.GetTrip(ctx, trip.ID); err != nil {
+		return fmt.Errorf("failed to get trip: %w", err)
+	}
+	return nil
+}
+
+// findPool returns the open pool the rider fits into with the least extra
+// driving, or nil if none fits. The chosen pool is locked and re-planned so
+// concurrent bookings can't overfill it.
+func (s *PoolService) findPool(ctx context.Context, q *db.Queries, vehicleType string, pickup, dropoff poolStop) (*db.TripPool, poolPlan, error) {
+	// One degree of latitude is about 111 km; longitude degrees shrink
+	// towards the poles but this only needs to be a rough pre-filter.
+	delta := s.matchRadiusKm / 111
+	candidates, err := q.FindOpenTripPools(ctx, db.FindOpenTripPoolsParams{
+		VehicleType:  vehicleType,
+		MinLatitude:  utils.Float64ToNumeric(pickup.point.lat - delta),
+		MaxLatitude:  utils.Float64ToNumeric(pickup.point.lat + delta),
+		MinLongitude: utils.Float64ToNumeric(pickup.point.lng - delta),
+		MaxLongitude: utils.Float64ToNumeric(pickup.point.lng + delta),
+		Limit:        poolCandidateLimit,
+	})
+	if err != nil {
+		return nil, poolPlan{}, fmt.Errorf("failed to find pools: %w", err)
+	}
+
+	matcher := s.matcher.using(newRoadDistances(ctx, s.router))
+	var (
+		best      *db.TripPool
+		bestExtra float64
+	)
+	for i := range candidates {
+		_, extra, ok, err := s.plan(ctx, q, matcher, candidates[i], pickup, dropoff)
+		if err != nil {
+			return nil, poolPlan{}, err
+		}
+		if ok && (best == nil || extra < bestExtra) {
+			best, bestExtra = &candidates[i], extra
+		}
+	}
+	if best == nil {
+		return nil, poolPlan{}, nil
+	}
+
+	locked, err := q.LockTripPool(ctx, best.ID)
+	if err != nil {
+		return nil, poolPlan{}, fmt.Errorf("failed to lock pool: %w", err)
+	}
+	if locked.Status != domain.PoolStatusOpen || !locked.DriverID.Valid {
+		return nil, poolPlan{}, nil
+	}
+	plan, _, ok, err := s.plan(ctx, q, matcher, locked, pickup, dropoff)
+	if err != nil || !ok {
+		return nil, poolPlan{}, err
+	}
+	return &locked, plan, nil
+}
+
+// plan inserts the rider into a pool's remaining route and reports the extra
+// distance the vehicle has to cover.
+func (s *PoolService) plan(ctx context.Context, q *db.Queries, matcher poolMatcher, pool db.TripPool, pickup, dropoff poolStop) (poolPlan, float64, bool, error) {
+	trips, err := q.GetPoolTrips(ctx, pool.ID)
+	if err != nil {
+		return poolPlan{}, 0, false, fmt.Errorf("failed to get pool trips: %w", err)
+	}
+	waypoints, err := q.GetPoolWaypoints(ctx, pool.ID)
+	if err != nil {
+		return poolPlan{}, 0, false, fmt.Errorf("failed to get pool waypoints: %w", err)
+	}
+
+	riders := make(map[pgtype.UUID]poolRider, len(trips))
+	seats := make(map[pgtype.UUID]int, len(trips))
+	for _, t := range trips {
+		riders[t.ID] = poolRider{direct: matcher.roads.between(
+			routePoint{utils.NumericToFloat64(t.PickupLatitude), utils.NumericToFloat64(t.PickupLongitude)},
+			routePoint{utils.NumericToFloat64(t.DropoffLatitude), utils.NumericToFloat64(t.DropoffLongitude)},
+		)}
+		seats[t.ID] = int(t.SeatCount)
+	}
+
+	var (
+		remaining []poolStop
+		onboard   int
+	)
+	for _, w := range waypoints {
+		if _, active := riders[w.TripID]; !active {
+			continue
+		}
+		if w.CompletedAt.Valid {
+			if w.Kind == domain.PoolWaypointPickup {
+				onboard += seats[w.TripID]
+			} else {
+				onboard -= seats[w.TripID]
+			}
+			continue
+		}
+		remaining = append(remaining, poolStop{
+			tripID: w.TripID,
+			kind:   w.Kind,
+			point:  routePoint{utils.NumericToFloat64(w.Latitude), utils.NumericToFloat64(w.Longitude)},
+			seats:  seats[w.TripID],
+		})
+	}
+
+	start := pickup.point
+	if len(remaining) > 0 {
+		start = remaining[0].point
+	}
+	if location, err := q.GetDriverLocation(ctx, pool.DriverID); err == nil && location.CurrentLatitude.Valid && location.CurrentLongitude.Valid {
+		start = routePoint{utils.NumericToFloat64(location.CurrentLatitude), utils.NumericToFloat64(location.CurrentLongitude)}
+	}
+
+	plan, ok := matcher.insert(start, remaining, riders, onboard, pickup, dropoff)
+	if !ok {
+		return poolPlan{}, 0, false, nil
+	}
+	return plan, plan.distance - matcher.routeDistance(start, remaining), true, nil
+}
+
+// saveRoute stores a plan's order as the pool's remaining waypoints, adding
+// the waypoints of the trip that just joined. Waypoints already reached keep
+// their place at the front.
+func (s *PoolService) saveRoute(ctx context.Context, q *db.Queries, trip db.Trip, poolID pgtype.UUID, plan poolPlan) error {
+	waypoints, err := q.GetPoolWaypoints(ctx, poolID)
+	if err != nil {
+		return fmt.Errorf("failed to get pool waypoints: %w", err)
+	}
+
+	type waypointKey struct {
+		tripID pgtype.UUID
+		kind   string
+	}
+	// Renumbering starts after every current sequence so no two waypoints
+	// ever share one.
+	existing := make(map[waypointKey]pgtype.UUID, len(waypoints))
+	var sequence int32
+	for _, w := range waypoints {
+		sequence = max(sequence, w.Sequence)
+		if !w.CompletedAt.Valid {
+			existing[waypointKey{w.TripID, w.Kind}] = w.ID
+		}
+	}
+
+	for _, stop := range plan.stops {
+		sequence++
+		if id, ok := existing[waypointKey{stop.tripID, stop.kind}]; ok {
+			if err := q.UpdatePoolWaypointSequence(ctx, db.UpdatePoolWaypointSequenceParams{
+				ID:       id,
+				Sequence: sequence,
+			}); err != nil {
+				return fmt.Errorf("failed to reorder pool waypoints: %w", err)
+			}
+			continue
+		}
+
+		address := trip.PickupAddress
+		if stop.kind == domain.PoolWaypointDropoff {
+			address = trip.DropoffAddress
+		}
+		if _, err := q.CreatePoolWaypoint(ctx, db.CreatePoolWaypointParams{
+			PoolID:    poolID,
+			TripID:    stop.tripID,
+			Kind:      stop.kind,
+			Sequence:  sequence,
+			Latitude:  utils.Float64ToNumeric(stop.point.lat),
+			Longitude: utils.Float64ToNumeric(stop.point.lng),
+			Address:   address,
+		}); err != nil {
+			return fmt.Errorf("failed to create pool waypoint: %w", err)
+		}
+	}
+	return nil
+}
+
+// leave drops a cancelled trip's remaining waypoints and closes the pool if
+// nobody is left in it. It runs in the cancelling transaction.
+func (s *PoolService) leave(ctx context.Context, q *db.Queries, trip db.Trip) error {
+	if !trip.PoolID.Valid {
+		return nil
+	}
+	if err := q.DeleteTripPoolWaypoints(ctx, trip.ID); err != nil {
+		return fmt.Errorf("failed to remove pool waypoints: %w", err)
+	}
+	if _, err := q.CompleteTripPoolIfDone(ctx, trip.PoolID); err != nil {
+		return fmt.Errorf("failed to close pool: %w", err)
+	}
+	return nil
+}
+
+// dropOff marks a completed trip's dropoff as reached and closes the pool
+// once every rider is done. It runs in the completing transaction.
+func (s *PoolService) dropOff(ctx context.Context, q *db.Queries, trip db.Trip) error {
+	if !trip.PoolID.Valid {
+		return nil
+	}
+	if err := q.CompletePoolWaypoint(ctx, db.CompletePoolWaypointParams{
+		TripID: trip.ID,
+		Kind:   domain.PoolWaypointDropoff,
+	}); err != nil {
+		return fmt.Errorf("failed to complete pool waypoint: %w", err)
+	}
+	if _, err := q.CompleteTripPoolIfDone(ctx, trip.PoolID); err != nil {
+		return fmt.Errorf("failed to close pool: %w", err)
+	}
+	return nil
+}
+
+// HandleTripAccepted gives a pool to the driver who accepted its first trip.
+func (s *PoolService) HandleTripAccepted(ctx context.Context, tripID, driverID uuid.UUID) error {
+	trip, err := s.tripRepo.GetTrip(ctx, utils.ToPgUUID(tripID))
+	if err != nil {
+		return fmt.Errorf("failed to get trip: %w", err)
+	}
+	if !trip.PoolID.Valid || utils.FromPgUUID(trip.DriverID) != driverID {
+		return nil
+	}
+
+	if _, err := s.tripRepo.SetTripPoolDriver(ctx, db.SetTripPoolDriverParams{
+		ID:       trip.PoolID,
+		DriverID: trip.DriverID,
+	}); err != nil {
+		return fmt.Errorf("failed to set pool driver: %w", err)
+	}
+	return nil
+}
+
+// HandleTripStarted marks a pooled rider as picked up.
+func (s *PoolService) HandleTripStarted(ctx context.Context, tripID uuid.UUID) error {
+	return s.tripRepo.CompletePoolWaypoint(ctx, db.CompletePoolWaypointParams{
+		TripID: utils.ToPgUUID(tripID),
+		Kind:   domain.PoolWaypointPickup,
+	})
+}
+
+// GetPool returns the shared route of a pooled trip. The driver and admins
+// see every rider's waypoints; riders only see their own.
+func (s *PoolService) GetPool(ctx context.Context, tripID, callerID uuid.UUID, role string) (*domain.TripPoolResponse, error) {
+	trip, err := s.tripRepo.GetTrip(ctx, utils.ToPgUUID(tripID))
+	if err != nil {
+		return nil, ErrTripNotFound
+	}
+	if !isTripParticipant(trip, callerID) && role != "admin" {
+		return nil, ErrNotTripParticipant
+	}
+	if !trip.PoolID.Valid {
+		return nil, ErrTripNotPooled
+	}
+
+	pool, err := s.tripRepo.GetTripPool(ctx, trip.PoolID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pool: %w", err)
+	}
+	trips, err := s.tripRepo.GetPoolTrips(ctx, pool.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pool trips: %w", err)
+	}
+	waypoints, err := s.tripRepo.GetPoolWaypoints(ctx, pool.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pool waypoints: %w", err)
+	}
+
+	riderView := utils.FromPgUUID(trip.UserID) == callerID
+	resp := &domain.TripPoolResponse{
+		PoolID:       utils.FromPgUUID(pool.ID).String(),
+		Status:       pool.Status,
+		SeatCapacity: int(pool.SeatCapacity),
+		Waypoints:    make([]domain.PoolWaypointResponse, 0, len(waypoints)),
+	}
+	if pool.DriverID.Valid {
+		resp.DriverID = utils.FromPgUUID(pool.DriverID).String()
+	}
+	for _, t := range trips {
+		resp.SeatsBooked += int(t.SeatCount)
+	}
+	for _, w := range waypoints {
+		if riderView && w.TripID != trip.ID {
+			continue
+		}
+		item := domain.PoolWaypointResponse{
+			TripID:    utils.FromPgUUID(w.TripID).String(),
+			Kind:      w.Kind,
+			Sequence:  int(w.Sequence),
+			Latitude:  utils.NumericToFloat64(w.Latitude),
+			Longitude: utils.NumericToFloat64(w.Longitude),
+			Address:   w.Address,
+		}
+		if w.CompletedAt.Valid {
+			completedAt := w.CompletedAt.Time
+			item.CompletedAt = &completedAt
+		}
+		resp.Waypoints = append(resp.Waypoints, item)
+	}
+	return resp, nil
+}
+
+// publishPoolUpdated tells the pool's driver that its route changed.
+func (s *PoolService) publishPoolUpdated(ctx context.Context, trip db.Trip, change string) {
+	if !trip.PoolID.Valid {
+		return
+	}
+
+	pool, err := s.tripRepo.GetTripPool(ctx, trip.PoolID)
+	if err != nil {
+		log.Printf("Failed to load pool %s: %v", utils.FromPgUUID(trip.PoolID), err)
+		return
+	}
+	if !pool.DriverID.Valid {
+		return
+	}
+	trips, err := s.tripRepo.GetPoolTrips(ctx, pool.ID)
+	if err != nil {
+		log.Printf("Failed to load pool %s trips: %v", utils.FromPgUUID(pool.ID), err)
+		return
+	}
+	waypoints, err := s.tripRepo.GetPoolWaypoints(ctx, pool.ID)
+	if err != nil {
+		log.Printf("Failed to load pool %s waypoints: %v", utils.FromPgUUID(pool.ID), err)
+		return
+	}
+
+	event := events.TripPoolUpdatedEvent{
+		PoolID:    utils.FromPgUUID(pool.ID).String(),
+		DriverID:  utils.FromPgUUID(pool.DriverID).String(),
+		TripID:    utils.FromPgUUID(trip.ID).String(),
+		Change:    change,
+		Timestamp: time.Now(),
+	}
+	for _, t := range trips {
+		event.SeatsBooked += int(t.SeatCount)
+	}
+	for _, w := range waypoints {
+		if !w.CompletedAt.Valid {
+			event.WaypointCount++
+		}
+	}
+	s.eventBus.Publish(ctx, events.SubjectTripPoolUpdated, event)
+}
+
+// publishJoined announces a rider added to a pool that already has a driver.
+func (s *PoolService) publishJoined(ctx context.Context, trip db.Trip) {
+	s.eventBus.Publish(ctx, events.SubjectTripAccepted, events.TripAcceptedEvent{
+		TripID:    utils.FromPgUUID(trip.ID).String(),
+		DriverID:  utils.FromPgUUID(trip.DriverID).String(),
+		UserID:    utils.FromPgUUID(trip.UserID).String(),
+		Timestamp: time.Now(),
+	})
+	s.publishPoolUpdated(ctx, trip, poolChangeJoined)
+}
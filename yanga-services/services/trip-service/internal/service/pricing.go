@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/namycodes/yanga-services/services/trip-service/internal/db"
+	"github.com/namycodes/yanga-services/shared-lib/domain"
+	"github.com/namycodes/yanga-services/shared-lib/money"
+	"github.com/namycodes/yanga-services/shared-lib/routing"
+	"github.com/namycodes/yanga-services/shared-lib/utils"
+)
+
+const (
+	// Time allowed at each intermediate stop on top of driving time.
+	stopDwellMinute = 2
+
+	// maxTripStops caps the intermediate stops on a single trip.
+	maxTripStops = 3
+)
+
+var (
+	ErrInvalidStop  = errors.New("invalid stop")
+	ErrTooManyStops = fmt.Errorf("a trip can have at most %d stops", maxTripStops)
+)
+
+type routePoint struct {
+	lat float64
+	lng float64
+}
+
+func routingPoints(points []routePoint) []routing.Point {
+	out := make([]routing.Point, len(points))
+	for i, p := range points {
+		out[i] = routing.Point{Lat: p.lat, Lng: p.lng}
+	}
+	return out
+}
+
+// routePrice is a fare broken down into its parts.
+type routePrice struct {
+	distance     float64 // km
+	duration     int     // minutes
+	polyline     string
+	baseFare     money.Money
+	distanceFare money.Money
+	stopFare     money.Money
+	poolDiscount money.Money
+	parcelFare   money.Money
+	zoneFee      money.Money
+}
+
+func (p routePrice) subtotal() money.Money {
+	return p.baseFare.Add(p.distanceFare).Add(p.stopFare).Add(p.parcelFare).Add(p.zoneFee).Sub(p.poolDiscount)
+}
+
+// rateCard is what a city charges for trips, in its currency.
+type rateCard struct {
+	baseFare         money.Money
+	perKm            money.Money
+	perStop          money.Money // flat charge for each intermediate stop
+	deliveryBaseFare money.Money
+	deliveryPerKm    money.Money
+}
+
+func (r rateCard) currency() money.Currency {
+	return r.baseFare.Currency()
+}
+
+// priceRoute prices a trip over every leg of its road route at the city's
+// rates. points runs from pickup through each stop to dropoff.
+func priceRoute(ctx context.Context, router routing.Router, rates rateCard, points []routePoint) (routePrice, error) {
+	route, err := routing.RouteVia(ctx, router, routingPoints(points))
+	if err != nil {
+		return routePrice{}, fmt.Errorf("failed to route trip: %w", err)
+	}
+
+	stops := max(len(points)-2, 0)
+	return routePrice{
+		distance:     route.Distance,
+		duration:     route.Minutes() + stops*stopDwellMinute,
+		polyline:     route.Polyline(),
+		baseFare:     rates.baseFare,
+		distanceFare: rates.perKm.MulFloat(route.Distance, money.HalfUp),
+		stopFare:     rates.perStop.Mul(int64(stops)),
+	}, nil
+}
+
+// priceDelivery prices a parcel delivery over its road route at the city's
+// delivery rates plus the surcharge for the parcel's size.
+func priceDelivery(ctx context.Context, router routing.Router, rates rateCard, points []routePoint, parcel parcelCategory) (routePrice, error) {
+	route, err := routing.RouteVia(ctx, router, routingPoints(points))
+	if err != nil {
+		return routePrice{}, fmt.Errorf("failed to route delivery: %w", err)
+	}
+	return routePrice{
+		distance:     route.Distance,
+		duration:     route.Minutes(),
+		polyline:     route.Polyline(),
+		baseFare:     rates.deliveryBaseFare,
+		distanceFare: rates.deliveryPerKm.MulFloat(route.Distance, money.HalfUp),
+		parcelFare:   money.FromFloat(parcel.surcharge, rates.currency()),
+	}, nil
+}
+
+// requestRoute builds the route for a new trip from its pickup, stops and
+// dropoff.
+func requestRoute(pickupLat, pickupLng, dropoffLat, dropoffLng float64, stops []domain.TripStopRequest) ([]routePoint, error) {
+	if len(stops) > maxTripStops {
+		return nil, ErrTooManyStops
+	}
+
+	points := make([]routePoint, 0, len(stops)+2)
+	points = append(points, routePoint{pickupLat, pickupLng})
+	for _, stop := range stops {
+		if err := validateStop(stop.Latitude, stop.Longitude, stop.Address); err != nil {
+			return nil, err
+		}
+		points = append(points, routePoint{stop.Latitude, stop.Longitude})
+	}
+	return append(points, routePoint{dropoffLat, dropoffLng}), nil
+}
+
+// tripRoute builds the route for an existing trip and its ordered stops.
+func tripRoute(trip db.Trip, stops []db.TripStop) []routePoint {
+	points := make([]routePoint, 0, len(stops)+2)
+	points = append(points, routePoint{
+		utils.NumericToFloat64(trip.PickupLatitude),
+		utils.NumericToFloat64(trip.PickupLongitude),
+	})
+	for _, stop := range stops {
+		points = append(points, routePoint{
+			utils.NumericToFloat64(stop.Latitude),
+			utils.NumericToFloat64(stop.Longitude),
+		})
+	}
+	return append(points, routePoint{
+		utils.NumericToFloat64(trip.DropoffLatitude),
+		utils.NumericToFloat64(trip.DropoffLongitude),
+	})
+}
+
+func validateStop(lat, lng float64, address string) error {
+	if lat == 0 || lng == 0 {
+		return fmt.Errorf("%w: location is required", ErrInvalidStop)
+	}
+	if address == "" {
+		return fmt.Errorf("%w: address is required", ErrInvalidStop)
+	}
+	return nil
+}
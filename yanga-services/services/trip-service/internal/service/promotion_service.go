@@ -0,0 +1,697 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/namycodes/yanga-services/services/trip-service/internal/db"
+	"github.com/namycodes/yanga-services/services/trip-service/internal/repository"
+	"github.com/namycodes/yanga-services/shared-lib/config"
+	"github.com/namycodes/yanga-services/shared-lib/domain"
+	"github.com/namycodes/yanga-services/shared-lib/events"
+	"github.com/namycodes/yanga-services/shared-lib/money"
+	"github.com/namycodes/yanga-services/shared-lib/utils"
+)
+
+const (
+	redemptionStatusReserved = "reserved"
+
+	referralCodeLength      = 8
+	referralCodeAlphabet    = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+	maxReferralCodeAttempts = 5
+)
+
+var (
+	ErrPromoNotFound          = errors.New("promo code not found")
+	ErrPromoInactive          = errors.New("promo code is not active")
+	ErrPromoExpired           = errors.New("promo code is not valid at this time")
+	ErrPromoUsageLimitReached = errors.New("promo code usage limit reached")
+	ErrPromoAlreadyUsed       = errors.New("promo code already used")
+	ErrPromoNotEligible       = errors.New("trip is not eligible for this promo code")
+	ErrPromoCodeExists        = errors.New("promo code already exists")
+	ErrInvalidPromoCode       = errors.New("invalid promo code")
+)
+
+var promoCodePattern = regexp.MustCompile(`^[A-Z0-9]{3,30}$`)
+
+// fareContext is what a promo code's eligibility rules are checked against.
+type fareContext struct {
+	userID      uuid.UUID
+	cityCode    string
+	subtotal    money.Money
+	vehicleType string
+	pickupLat   float64
+	pickupLng   float64
+	at          time.Time
+}
+
+type PromotionService struct {
+	repo           *repository.PromotionRepository
+	tripRepo       *repository.TripRepository
+	cityService    *CityService
+	eventBus       events.EventBus
+	currency       money.Currency // of referral rewards
+	referrerReward money.Money
+	refereeReward  money.Money
+}
+
+func NewPromotionService(repo *repository.PromotionRepository, tripRepo *repository.TripRepository, cityService *CityService, eventBus events.EventBus, cfg *config.Config) *PromotionService {
+	currency := money.Currency(cfg.DefaultCurrency)
+	return &PromotionService{
+		repo:           repo,
+		tripRepo:       tripRepo,
+		cityService:    cityService,
+		eventBus:       eventBus,
+		currency:       currency,
+		referrerReward: money.FromFloat(float64(cfg.ReferrerReward), currency),
+		refereeReward:  money.FromFloat(float64(cfg.RefereeReward), currency),
+	}
+}
+
+func (s *PromotionService) CreatePromoCode(ctx context.Context, adminID uuid.UUID, req *domain.CreatePromoCodeRequest) (*domain.PromoCodeResponse, error) {
+	code := normalizeCode(req.Code)
+	if !promoCodePattern.MatchString(code) {
+		return nil, fmt.Errorf("%w: code must be 3-30 letters or digits", ErrInvalidPromoCode)
+	}
+
+	// Amounts are in the currency of the promo's city, or of the default
+	// city for promos that apply everywhere, and the code keeps it.
+	cityCode := strings.ToLower(strings.TrimSpace(req.CityCode))
+	if cityCode != "" && !cityCodePattern.MatchString(cityCode) {
+		return nil, ErrInvalidCityCode
+	}
+	currency, err := s.cityService.currencyOf(ctx, cityCode)
+	if err != nil {
+		if errors.Is(err, ErrCityNotFound) {
+			return nil, fmt.Errorf("%w: city %q doesn't exist", ErrInvalidPromoCode, cityCode)
+		}
+		return nil, err
+	}
+
+	var discountValue pgtype.Numeric
+	switch req.DiscountType {
+	case domain.DiscountTypePercentage:
+		rate := money.Percent(req.DiscountValue)
+		if rate <= 0 || rate > money.Percent(100) {
+			return nil, fmt.Errorf("%w: percentage must be between 0 and 100", ErrInvalidPromoCode)
+		}
+		discountValue = rate.PercentNumeric()
+	case domain.DiscountTypeFixed:
+		amount := money.FromFloat(req.DiscountValue, currency)
+		if amount.Sign() <= 0 {
+			return nil, fmt.Errorf("%w: discount value must be greater than zero", ErrInvalidPromoCode)
+		}
+		discountValue = amount.Numeric()
+	default:
+		return nil, fmt.Errorf("%w: discount type must be percentage or fixed", ErrInvalidPromoCode)
+	}
+
+	if req.MaxDiscount != nil && money.FromFloat(*req.MaxDiscount, currency).Sign() <= 0 {
+		return nil, fmt.Errorf("%w: max discount must be greater than zero", ErrInvalidPromoCode)
+	}
+	if req.MinFare != nil && *req.MinFare < 0 {
+		return nil, fmt.Errorf("%w: min fare cannot be negative", ErrInvalidPromoCode)
+	}
+	if req.UsageLimit != nil && *req.UsageLimit <= 0 {
+		return nil, fmt.Errorf("%w: usage limit must be greater than zero", ErrInvalidPromoCode)
+	}
+
+	perUserLimit := req.PerUserLimit
+	if perUserLimit == 0 {
+		perUserLimit = 1
+	}
+	if perUserLimit < 0 {
+		return nil, fmt.Errorf("%w: per-user limit must be greater than zero", ErrInvalidPromoCode)
+	}
+
+	// A geo restriction needs a centre and a radius
+	geoFields := 0
+	for _, v := range []*float64{req.CenterLatitude, req.CenterLongitude, req.RadiusKm} {
+		if v != nil {
+			geoFields++
+		}
+	}
+	if geoFields != 0 && geoFields != 3 {
+		return nil, fmt.Errorf("%w: center_latitude, center_longitude and radius_km must be set together", ErrInvalidPromoCode)
+	}
+	if req.RadiusKm != nil && *req.RadiusKm <= 0 {
+		return nil, fmt.Errorf("%w: radius must be greater than zero", ErrInvalidPromoCode)
+	}
+
+	validFrom := time.Now().UTC()
+	if req.ValidFrom != nil {
+		validFrom = req.ValidFrom.UTC()
+	}
+	var validUntil pgtype.Timestamp
+	if req.ValidUntil != nil {
+		if !req.ValidUntil.After(validFrom) {
+			return nil, fmt.Errorf("%w: valid_until must be after valid_from", ErrInvalidPromoCode)
+		}
+		validUntil = pgtype.Timestamp{Time: req.ValidUntil.UTC(), Valid: true}
+	}
+
+	var vehicleTypes []string
+	for _, v := range req.VehicleTypes {
+		if v = strings.ToLower(strings.TrimSpace(v)); v != "" {
+			vehicleTypes = append(vehicleTypes, v)
+		}
+	}
+
+	params := db.CreatePromoCodeParams{
+		Code:          code,
+		Description:   pgtype.Text{String: req.Description, Valid: req.Description != ""},
+		DiscountType:  req.DiscountType,
+		DiscountValue: discountValue,
+		PerUserLimit:  perUserLimit,
+		FirstRideOnly: req.FirstRideOnly,
+		AutoApply:     req.AutoApply,
+		VehicleTypes:  vehicleTypes,
+		ValidFrom:     pgtype.Timestamp{Time: validFrom, Valid: true},
+		ValidUntil:    validUntil,
+		CreatedBy:     utils.ToPgUUID(adminID),
+		CityCode:      pgtype.Text{String: cityCode, Valid: cityCode != ""},
+		Currency:      currency.String(),
+	}
+	if req.MaxDiscount != nil {
+		params.MaxDiscount = money.FromFloat(*req.MaxDiscount, currency).Numeric()
+	}
+	if req.MinFare != nil {
+		params.MinFare = money.FromFloat(*req.MinFare, currency).Numeric()
+	}
+	if req.UsageLimit != nil {
+		params.UsageLimit = pgtype.Int4{Int32: *req.UsageLimit, Valid: true}
+	}
+	if geoFields == 3 {
+		params.CenterLatitude = utils.Float64ToNumeric(*req.CenterLatitude)
+		params.CenterLongitude = utils.Float64ToNumeric(*req.CenterLongitude)
+		params.RadiusKm = utils.Float64ToNumeric(*req.RadiusKm)
+	}
+
+	promo, err := s.repo.CreatePromoCode(ctx, params)
+	if err != nil {
+		if repository.IsUniqueViolation(err) {
+			return nil, ErrPromoCodeExists
+		}
+		if repository.IsForeignKeyViolation(err) {
+			return nil, fmt.Errorf("%w: city %q doesn't exist", ErrInvalidPromoCode, cityCode)
+		}
+		return nil, fmt.Errorf("failed to create promo code: %w", err)
+	}
+
+	return toPromoCodeResponse(promo), nil
+}
+
+func (s *PromotionService) ListPromoCodes(ctx context.Context, cityCode string, limit, offset int32) ([]domain.PromoCodeResponse, error) {
+	cityCode = strings.ToLower(strings.TrimSpace(cityCode))
+	promos, err := s.repo.ListPromoCodes(ctx, db.ListPromoCodesParams{
+		CityCode: pgtype.Text{String: cityCode, Valid: cityCode != ""},
+		Limit:    limit,
+		Offset:   offset,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list promo codes: %w", err)
+	}
+
+	response := make([]domain.PromoCodeResponse, 0, len(promos))
+	for _, p := range promos {
+		response = append(response, *toPromoCodeResponse(p))
+	}
+	return response, nil
+}
+
+func (s *PromotionService) SetPromoCodeActive(ctx context.Context, promoID uuid.UUID, active bool) (*domain.PromoCodeResponse, error) {
+	promo, err := s.repo.UpdatePromoCodeStatus(ctx, db.UpdatePromoCodeStatusParams{
+		ID:       utils.ToPgUUID(promoID),
+		IsActive: active,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrPromoNotFound
+		}
+		return nil, fmt.Errorf("failed to update promo code: %w", err)
+	}
+
+	return toPromoCodeResponse(promo), nil
+}
+
+// GetReferral returns the user's referral code and how many referrals it has
+// earned. Users who signed up before referrals existed get a code on first
+// request.
+func (s *PromotionService) GetReferral(ctx context.Context, userID uuid.UUID) (*domain.ReferralResponse, error) {
+	code, err := s.ensureReferralCode(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	stats, err := s.repo.GetReferralStats(ctx, utils.ToPgUUID(userID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get referral stats: %w", err)
+	}
+
+	return &domain.ReferralResponse{
+		Code:              code.Code,
+		ReferrerReward:    s.referrerReward.Float64(),
+		RefereeReward:     s.refereeReward.Float64(),
+		TotalReferrals:    stats.TotalReferrals,
+		RewardedReferrals: stats.RewardedReferrals,
+		TotalEarned:       money.FromNumeric(stats.TotalEarned, s.currency).Float64(),
+	}, nil
+}
+
+// HandleUserCreated gives every new user a referral code and, if they signed
+// up with someone else's code, records a pending referral.
+func (s *PromotionService) HandleUserCreated(ctx context.Context, payload events.UserCreatedPayload) error {
+	userID, err := uuid.Parse(payload.UserID)
+	if err != nil {
+		return fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	if _, err := s.ensureReferralCode(ctx, userID); err != nil {
+		return err
+	}
+
+	code := normalizeCode(payload.ReferralCode)
+	if code == "" {
+		return nil
+	}
+
+	referrer, err := s.repo.GetReferralCodeByCode(ctx, code)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			log.Printf("User %s signed up with unknown referral code %s", payload.UserID, code)
+			return nil
+		}
+		return fmt.Errorf("failed to look up referral code: %w", err)
+	}
+	if utils.FromPgUUID(referrer.UserID) == userID {
+		return nil
+	}
+
+	_, err = s.repo.CreateReferral(ctx, db.CreateReferralParams{
+		ReferrerID:     referrer.UserID,
+		RefereeID:      utils.ToPgUUID(userID),
+		ReferrerReward: s.referrerReward.Numeric(),
+		RefereeReward:  s.refereeReward.Numeric(),
+	})
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("failed to create referral: %w", err)
+	}
+	return nil
+}
+
+func (s *PromotionService) SubscribeToEvents() {
+	s.eventBus.QueueSubscribe(events.SubjectUserCreated, "trip-service", func(ctx context.Context, data []byte) {
+		var payload events.UserCreatedPayload
+		if err := json.Unmarshal(data, &payload); err != nil {
+			log.Printf("Failed to unmarshal user created event: %v", err)
+			return
+		}
+
+		if err := s.HandleUserCreated(ctx, payload); err != nil {
+			log.Printf("Failed to set up referral for user %s: %v", payload.UserID, err)
+			return
+		}
+	})
+}
+
+// resolvePromo finds the promo code to apply to a fare. An explicit code must
+// be eligible; otherwise the best eligible auto-apply promotion is used, if
+// any.
+func (s *PromotionService) resolvePromo(ctx context.Context, fc fareContext, code string) (*db.PromoCode, money.Money, error) {
+	if code = normalizeCode(code); code != "" {
+		promo, err := s.repo.GetPromoCodeByCode(ctx, code)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return nil, money.Money{}, ErrPromoNotFound
+			}
+			return nil, money.Money{}, fmt.Errorf("failed to get promo code: %w", err)
+		}
+		if err := s.checkEligibility(ctx, promo, fc); err != nil {
+			return nil, money.Money{}, err
+		}
+		return &promo, discountFor(promo, fc.subtotal), nil
+	}
+
+	candidates, err := s.repo.GetAutoApplyPromoCodes(ctx, pgtype.Timestamp{Time: fc.at, Valid: true})
+	if err != nil {
+		return nil, money.Money{}, fmt.Errorf("failed to get promotions: %w", err)
+	}
+
+	var best *db.PromoCode
+	bestDiscount := money.Zero(fc.subtotal.Currency())
+	for i := range candidates {
+		if err := s.checkEligibility(ctx, candidates[i], fc); err != nil {
+			continue
+		}
+		if discount := discountFor(candidates[i], fc.subtotal); discount.Cmp(bestDiscount) > 0 {
+			best = &candidates[i]
+			bestDiscount = discount
+		}
+	}
+	return best, bestDiscount, nil
+}
+
+func (s *PromotionService) checkEligibility(ctx context.Context, promo db.PromoCode, fc fareContext) error {
+	if !promo.IsActive {
+		return ErrPromoInactive
+	}
+	if fc.at.Before(promo.ValidFrom.Time) || (promo.ValidUntil.Valid && !fc.at.Before(promo.ValidUntil.Time)) {
+		return ErrPromoExpired
+	}
+	if promo.UsageLimit.Valid && promo.TimesUsed >= promo.UsageLimit.Int32 {
+		return ErrPromoUsageLimitReached
+	}
+	if !appliesIn(promo, fc.subtotal.Currency()) {
+		return ErrPromoNotEligible
+	}
+	if promo.MinFare.Valid && fc.subtotal.Cmp(money.FromNumeric(promo.MinFare, fc.subtotal.Currency())) < 0 {
+		return ErrPromoNotEligible
+	}
+	if promo.CityCode.Valid && promo.CityCode.String != fc.cityCode {
+		return ErrPromoNotEligible
+	}
+
+	if len(promo.VehicleTypes) > 0 {
+		allowed := false
+		for _, v := range promo.VehicleTypes {
+			if v == fc.vehicleType {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return ErrPromoNotEligible
+		}
+	}
+
+	if promo.RadiusKm.Valid {
+		distance := utils.CalculateDistance(
+			utils.NumericToFloat64(promo.CenterLatitude), utils.NumericToFloat64(promo.CenterLongitude),
+			fc.pickupLat, fc.pickupLng,
+		)
+		if distance > utils.NumericToFloat64(promo.RadiusKm) {
+			return ErrPromoNotEligible
+		}
+	}
+
+	userID := utils.ToPgUUID(fc.userID)
+
+	used, err := s.repo.CountUserPromoRedemptions(ctx, db.CountUserPromoRedemptionsParams{
+		PromoCodeID: promo.ID,
+		UserID:      userID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to count promo redemptions: %w", err)
+	}
+	if used >= int64(promo.PerUserLimit) {
+		return ErrPromoAlreadyUsed
+	}
+
+	if promo.FirstRideOnly {
+		completed, err := s.tripRepo.CountUserCompletedTrips(ctx, userID)
+		if err != nil {
+			return fmt.Errorf("failed to count completed trips: %w", err)
+		}
+		if completed > 0 {
+			return ErrPromoNotEligible
+		}
+	}
+
+	return nil
+}
+
+// reservePromo claims one use of the promo code for a new trip. It must run
+// in the same transaction that creates the trip.
+func (s *PromotionService) reservePromo(ctx context.Context, q *db.Queries, promo db.PromoCode, userID uuid.UUID, tripID pgtype.UUID, discount money.Money) error {
+	claimed, err := q.ClaimPromoCodeUse(ctx, promo.ID)
+	if err != nil {
+		return fmt.Errorf("failed to claim promo code: %w", err)
+	}
+	if claimed == 0 {
+		return ErrPromoUsageLimitReached
+	}
+
+	// The claim holds the promo code's row lock until commit, so concurrent
+	// bookings by the same user are counted one at a time.
+	used, err := q.CountUserPromoRedemptions(ctx, db.CountUserPromoRedemptionsParams{
+		PromoCodeID: promo.ID,
+		UserID:      utils.ToPgUUID(userID),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to count promo redemptions: %w", err)
+	}
+	if used >= int64(promo.PerUserLimit) {
+		return ErrPromoAlreadyUsed
+	}
+
+	_, err = q.CreatePromoRedemption(ctx, db.CreatePromoRedemptionParams{
+		PromoCodeID:    promo.ID,
+		UserID:         utils.ToPgUUID(userID),
+		TripID:         tripID,
+		DiscountAmount: discount.Numeric(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to reserve promo code: %w", err)
+	}
+	return nil
+}
+
+// redeemPromo settles a trip's reserved promo against the actual fare and
+// returns the discount.
+func (s *PromotionService) redeemPromo(ctx context.Context, q *db.Queries, tripID pgtype.UUID, fare money.Money) (money.Money, error) {
+	none := money.Zero(fare.Currency())
+	redemption, err := q.GetPromoRedemptionByTrip(ctx, tripID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return none, nil
+		}
+		return none, fmt.Errorf("failed to get promo redemption: %w", err)
+	}
+	if redemption.Status != redemptionStatusReserved {
+		return none, nil
+	}
+
+	promo, err := q.GetPromoCode(ctx, redemption.PromoCodeID)
+	if err != nil {
+		return none, fmt.Errorf("failed to get promo code: %w", err)
+	}
+
+	discount := discountFor(promo, fare)
+	if _, err := q.RedeemPromoRedemption(ctx, db.RedeemPromoRedemptionParams{
+		TripID:         tripID,
+		DiscountAmount: discount.Numeric(),
+	}); err != nil {
+		return none, fmt.Errorf("failed to redeem promo code: %w", err)
+	}
+	return discount, nil
+}
+
+// estimateDiscount re-prices a trip's reserved promo against a new subtotal
+// without redeeming it, and returns the discount.
+func (s *PromotionService) estimateDiscount(ctx context.Context, q *db.Queries, tripID pgtype.UUID, subtotal money.Money) (money.Money, error) {
+	none := money.Zero(subtotal.Currency())
+	redemption, err := q.GetPromoRedemptionByTrip(ctx, tripID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return none, nil
+		}
+		return none, fmt.Errorf("failed to get promo redemption: %w", err)
+	}
+	if redemption.Status != redemptionStatusReserved {
+		return none, nil
+	}
+
+	promo, err := q.GetPromoCode(ctx, redemption.PromoCodeID)
+	if err != nil {
+		return none, fmt.Errorf("failed to get promo code: %w", err)
+	}
+	return discountFor(promo, subtotal), nil
+}
+
+// releasePromo gives a cancelled trip's promo use back.
+func (s *PromotionService) releasePromo(ctx context.Context, q *db.Queries, tripID pgtype.UUID) error {
+	redemption, err := q.ReleasePromoRedemption(ctx, tripID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		return fmt.Errorf("failed to release promo redemption: %w", err)
+	}
+
+	if err := q.ReleasePromoCodeUse(ctx, redemption.PromoCodeID); err != nil {
+		return fmt.Errorf("failed to release promo code: %w", err)
+	}
+	return nil
+}
+
+// rewardReferral marks the rider's pending referral as rewarded. Only the
+// first completed trip finds it pending, so it returns nil afterwards.
+func (s *PromotionService) rewardReferral(ctx context.Context, q *db.Queries, refereeID, tripID pgtype.UUID) (*db.Referral, error) {
+	referral, err := q.MarkReferralRewarded(ctx, db.MarkReferralRewardedParams{
+		RefereeID:        refereeID,
+		QualifyingTripID: tripID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to reward referral: %w", err)
+	}
+	return &referral, nil
+}
+
+// publishReferralRewarded asks payment-service to credit both wallets.
+func (s *PromotionService) publishReferralRewarded(ctx context.Context, referral *db.Referral) {
+	s.eventBus.Publish(ctx, events.SubjectReferralRewarded, events.ReferralRewardedEvent{
+		ReferralID:     utils.FromPgUUID(referral.ID).String(),
+		ReferrerID:     utils.FromPgUUID(referral.ReferrerID).String(),
+		RefereeID:      utils.FromPgUUID(referral.RefereeID).String(),
+		TripID:         utils.FromPgUUID(referral.QualifyingTripID).String(),
+		ReferrerReward: money.FromNumeric(referral.ReferrerReward, s.currency).Float64(),
+		RefereeReward:  money.FromNumeric(referral.RefereeReward, s.currency).Float64(),
+		Timestamp:      time.Now(),
+	})
+}
+
+func (s *PromotionService) ensureReferralCode(ctx context.Context, userID uuid.UUID) (db.ReferralCode, error) {
+	pgUserID := utils.ToPgUUID(userID)
+
+	existing, err := s.repo.GetReferralCodeByUser(ctx, pgUserID)
+	if err == nil {
+		return existing, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return db.ReferralCode{}, fmt.Errorf("failed to get referral code: %w", err)
+	}
+
+	for attempt := 0; attempt < maxReferralCodeAttempts; attempt++ {
+		code, err := generateReferralCode()
+		if err != nil {
+			return db.ReferralCode{}, fmt.Errorf("failed to generate referral code: %w", err)
+		}
+
+		created, err := s.repo.CreateReferralCode(ctx, db.CreateReferralCodeParams{
+			UserID: pgUserID,
+			Code:   code,
+		})
+		switch {
+		case err == nil:
+			return created, nil
+		case errors.Is(err, pgx.ErrNoRows):
+			// ON CONFLICT (user_id) DO NOTHING: a concurrent request won
+			return s.repo.GetReferralCodeByUser(ctx, pgUserID)
+		case repository.IsUniqueViolation(err):
+			// Code collision, try another one
+			continue
+		default:
+			return db.ReferralCode{}, fmt.Errorf("failed to create referral code: %w", err)
+		}
+	}
+
+	return db.ReferralCode{}, errors.New("failed to generate a unique referral code")
+}
+
+func generateReferralCode() (string, error) {
+	alphabetSize := big.NewInt(int64(len(referralCodeAlphabet)))
+	code := make([]byte, referralCodeLength)
+	for i := range code {
+		n, err := rand.Int(rand.Reader, alphabetSize)
+		if err != nil {
+			return "", err
+		}
+		code[i] = referralCodeAlphabet[n.Int64()]
+	}
+	return string(code), nil
+}
+
+// appliesIn reports whether a promo can be used on fares in a currency. Its
+// fixed amounts are in the currency it was created in, so elsewhere only a
+// plain percentage off, with no cap or minimum fare, still means the same.
+func appliesIn(promo db.PromoCode, currency money.Currency) bool {
+	if promo.Currency == currency.String() {
+		return true
+	}
+	return promo.DiscountType == domain.DiscountTypePercentage && !promo.MaxDiscount.Valid && !promo.MinFare.Valid
+}
+
+// discountFor returns the promo's discount on a fare, in the fare's
+// currency and never more than the fare itself. Promos that don't apply in
+// the fare's currency give nothing.
+func discountFor(promo db.PromoCode, fare money.Money) money.Money {
+	currency := fare.Currency()
+	discount := money.Zero(currency)
+	if !appliesIn(promo, currency) {
+		return discount
+	}
+	switch promo.DiscountType {
+	case domain.DiscountTypePercentage:
+		discount = fare.Apply(money.PercentFromNumeric(promo.DiscountValue), money.HalfUp)
+	case domain.DiscountTypeFixed:
+		discount = money.FromNumeric(promo.DiscountValue, currency)
+	}
+
+	if promo.MaxDiscount.Valid {
+		discount = money.Min(discount, money.FromNumeric(promo.MaxDiscount, currency))
+	}
+	return money.Max(money.Min(discount, fare), money.Zero(currency))
+}
+
+func normalizeCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+func toPromoCodeResponse(p db.PromoCode) *domain.PromoCodeResponse {
+	resp := &domain.PromoCodeResponse{
+		ID:            utils.FromPgUUID(p.ID).String(),
+		Code:          p.Code,
+		Description:   p.Description.String,
+		DiscountType:  p.DiscountType,
+		DiscountValue: utils.NumericToFloat64(p.DiscountValue),
+		PerUserLimit:  p.PerUserLimit,
+		TimesUsed:     p.TimesUsed,
+		FirstRideOnly: p.FirstRideOnly,
+		AutoApply:     p.AutoApply,
+		VehicleTypes:  p.VehicleTypes,
+		CityCode:      p.CityCode.String,
+		Currency:      p.Currency,
+		ValidFrom:     p.ValidFrom.Time,
+		IsActive:      p.IsActive,
+		CreatedAt:     p.CreatedAt.Time,
+	}
+	if p.MaxDiscount.Valid {
+		v := utils.NumericToFloat64(p.MaxDiscount)
+		resp.MaxDiscount = &v
+	}
+	if p.MinFare.Valid {
+		v := utils.NumericToFloat64(p.MinFare)
+		resp.MinFare = &v
+	}
+	if p.UsageLimit.Valid {
+		v := p.UsageLimit.Int32
+		resp.UsageLimit = &v
+	}
+	if p.RadiusKm.Valid {
+		lat := utils.NumericToFloat64(p.CenterLatitude)
+		lng := utils.NumericToFloat64(p.CenterLongitude)
+		radius := utils.NumericToFloat64(p.RadiusKm)
+		resp.CenterLatitude = &lat
+		resp.CenterLongitude = &lng
+		resp.RadiusKm = &radius
+	}
+	if p.ValidUntil.Valid {
+		t := p.ValidUntil.Time
+		resp.ValidUntil = &t
+	}
+	return resp
+}
@@ -0,0 +1,791 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/namycodes/yanga-services/services/trip-service/internal/db"
+	"github.com/namycodes/yanga-services/services/trip-service/internal/repository"
+	"github.com/namycodes/yanga-services/shared-lib/config"
+	"github.com/namycodes/yanga-services/shared-lib/domain"
+	"github.com/namycodes/yanga-services/shared-lib/events"
+	"github.com/namycodes/yanga-services/shared-lib/routing"
+	"github.com/namycodes/yanga-services/shared-lib/utils"
+)
+
+const (
+	maxEmergencyContacts = 5
+	maxSOSMessageLength  = 500
+	maxContactNameLength = 100
+	shareTokenBytes      = 24
+	// A driver who stays within this distance of where they stopped hasn't
+	// moved, however much their GPS wanders.
+	stationaryRadiusKm = 0.05
+	// A trip isn't flagged as running over until it's at least this far
+	// past its estimate, so short trips aren't flagged for a red light.
+	minOverETA = 10 * time.Minute
+)
+
+var (
+	ErrInvalidEmergencyContact   = errors.New("invalid emergency contact")
+	ErrTooManyEmergencyContacts  = fmt.Errorf("at most %d emergency contacts can be added", maxEmergencyContacts)
+	ErrDuplicateEmergencyContact = errors.New("emergency contact already added")
+	ErrEmergencyContactNotFound  = errors.New("emergency contact not found")
+	ErrInvalidSOS                = errors.New("invalid SOS")
+	ErrShareLinkNotFound         = errors.New("share link not found")
+	ErrShareLinkExpired          = errors.New("share link has expired")
+	ErrInvalidIncidentStatus     = errors.New("status must be open, acknowledged or resolved")
+	ErrIncidentNotFound          = errors.New("safety incident not found")
+	ErrIncidentNotOpen           = errors.New("safety incident is not open")
+	ErrIncidentResolved          = errors.New("safety incident is already resolved")
+	ErrResolutionNoteRequired    = errors.New("a note of how the incident was resolved is required")
+)
+
+var contactPhonePattern = regexp.MustCompile(`^\+?[0-9]{7,15}$`)
+
+// SafetyService looks after riders and drivers on a trip: SOS alerts to
+// their emergency contacts and the safety team, links that let friends
+// follow a trip without an account, and checks on trips under way that
+// flag route deviations, long stops and trips running far over their
+// estimate.
+//
+// Everything that needs the safety team's attention becomes an incident
+// in their queue; a trip has at most one unresolved incident of each kind,
+// so a trip that stays off route isn't flagged again on every location
+// update.
+type SafetyService struct {
+	safetyRepo     *repository.SafetyRepository
+	tripRepo       *repository.TripRepository
+	router         routing.Router
+	eventBus       events.EventBus
+	shareBaseURL   string
+	shareLinkTTL   time.Duration
+	deviationKm    float64
+	longStop       time.Duration
+	overETAPercent int
+	checkInterval  time.Duration
+}
+
+func NewSafetyService(safetyRepo *repository.SafetyRepository, tripRepo *repository.TripRepository, router routing.Router, eventBus events.EventBus, cfg *config.Config) *SafetyService {
+	return &SafetyService{
+		safetyRepo:     safetyRepo,
+		tripRepo:       tripRepo,
+		router:         router,
+		eventBus:       eventBus,
+		shareBaseURL:   cfg.SafetyShareBaseURL,
+		shareLinkTTL:   time.Duration(cfg.SafetyShareLinkHours) * time.Hour,
+		deviationKm:    float64(cfg.SafetyRouteDeviationMeters) / 1000,
+		longStop:       time.Duration(cfg.SafetyLongStopMinutes) * time.Minute,
+		overETAPercent: cfg.SafetyOverETAPercent,
+		checkInterval:  time.Duration(cfg.SafetyCheckIntervalSeconds) * time.Second,
+	}
+}
+
+// ListContacts returns a user's emergency contacts.
+func (s *SafetyService) ListContacts(ctx context.Context, userID uuid.UUID) ([]domain.EmergencyContactResponse, error) {
+	contacts, err := s.safetyRepo.ListEmergencyContacts(ctx, utils.ToPgUUID(userID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get emergency contacts: %w", err)
+	}
+
+	response := make([]domain.EmergencyContactResponse, len(contacts))
+	for i, contact := range contacts {
+		response[i] = toEmergencyContactResponse(contact)
+	}
+	return response, nil
+}
+
+// AddContact adds someone to be told when the user raises an SOS.
+func (s *SafetyService) AddContact(ctx context.Context, userID uuid.UUID, req *domain.EmergencyContactRequest) (*domain.EmergencyContactResponse, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" || len([]rune(name)) > maxContactNameLength {
+		return nil, fmt.Errorf("%w: name is required and limited to %d characters", ErrInvalidEmergencyContact, maxContactNameLength)
+	}
+	phone := strings.NewReplacer(" ", "", "-", "", "(", "", ")", "").Replace(req.PhoneNumber)
+	if !contactPhonePattern.MatchString(phone) {
+		return nil, fmt.Errorf("%w: invalid phone number", ErrInvalidEmergencyContact)
+	}
+
+	pgUserID := utils.ToPgUUID(userID)
+	count, err := s.safetyRepo.CountEmergencyContacts(ctx, pgUserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count emergency contacts: %w", err)
+	}
+	if count >= maxEmergencyContacts {
+		return nil, ErrTooManyEmergencyContacts
+	}
+
+	contact, err := s.safetyRepo.CreateEmergencyContact(ctx, db.CreateEmergencyContactParams{
+		UserID:      pgUserID,
+		Name:        name,
+		PhoneNumber: phone,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrDuplicateEmergencyContact
+		}
+		return nil, fmt.Errorf("failed to add emergency contact: %w", err)
+	}
+
+	response := toEmergencyContactResponse(contact)
+	return &response, nil
+}
+
+func (s *SafetyService) RemoveContact(ctx context.Context, userID, contactID uuid.UUID) error {
+	deleted, err := s.safetyRepo.DeleteEmergencyContact(ctx, db.DeleteEmergencyContactParams{
+		ID:     utils.ToPgUUID(contactID),
+		UserID: utils.ToPgUUID(userID),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to remove emergency contact: %w", err)
+	}
+	if deleted == 0 {
+		return ErrEmergencyContactNotFound
+	}
+	return nil
+}
+
+// ShareTrip creates a link that anyone can open to follow a trip, without
+// an account, until it expires or is revoked.
+func (s *SafetyService) ShareTrip(ctx context.Context, tripID, userID uuid.UUID) (*domain.ShareTripResponse, error) {
+	trip, err := s.participantTrip(ctx, tripID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if trip.Status != domain.TripStatusPending && !chatOpen(trip) {
+		return nil, ErrTripNotUnderWay
+	}
+	return s.createShareLink(ctx, trip.ID, userID)
+}
+
+func (s *SafetyService) createShareLink(ctx context.Context, tripID pgtype.UUID, userID uuid.UUID) (*domain.ShareTripResponse, error) {
+	raw := make([]byte, shareTokenBytes)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, fmt.Errorf("failed to generate share token: %w", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+
+	link, err := s.safetyRepo.CreateTripShareLink(ctx, db.CreateTripShareLinkParams{
+		TripID:    tripID,
+		CreatedBy: utils.ToPgUUID(userID),
+		TokenHash: hashShareToken(token),
+		ExpiresAt: pgtype.Timestamp{Time: time.Now().UTC().Add(s.shareLinkTTL), Valid: true},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create share link: %w", err)
+	}
+
+	return &domain.ShareTripResponse{
+		ID:        utils.FromPgUUID(link.ID).String(),
+		URL:       s.shareBaseURL + token,
+		ExpiresAt: link.ExpiresAt.Time,
+	}, nil
+}
+
+// RevokeShare stops a share link working before it expires.
+func (s *SafetyService) RevokeShare(ctx context.Context, tripID, linkID, userID uuid.UUID) error {
+	trip, err := s.participantTrip(ctx, tripID, userID)
+	if err != nil {
+		return err
+	}
+
+	revoked, err := s.safetyRepo.RevokeTripShareLink(ctx, db.RevokeTripShareLinkParams{
+		ID:     utils.ToPgUUID(linkID),
+		TripID: trip.ID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to revoke share link: %w", err)
+	}
+	if revoked == 0 {
+		return ErrShareLinkNotFound
+	}
+	return nil
+}
+
+// GetSharedTrip returns what someone following a share link sees.
+func (s *SafetyService) GetSharedTrip(ctx context.Context, token string) (*domain.SharedTripResponse, error) {
+	link, err := s.safetyRepo.GetTripShareLinkByToken(ctx, hashShareToken(token))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrShareLinkNotFound
+		}
+		return nil, fmt.Errorf("failed to get share link: %w", err)
+	}
+	if link.RevokedAt.Valid || !link.ExpiresAt.Time.After(time.Now().UTC()) {
+		return nil, ErrShareLinkExpired
+	}
+
+	trip, err := s.tripRepo.GetTrip(ctx, link.TripID)
+	if err != nil {
+		return nil, ErrTripNotFound
+	}
+
+	response := &domain.SharedTripResponse{
+		Status:         trip.Status,
+		PickupAddress:  trip.PickupAddress,
+		DropoffAddress: trip.DropoffAddress,
+		ExpiresAt:      link.ExpiresAt.Time,
+	}
+	if trip.StartedAt.Valid {
+		response.StartedAt = &trip.StartedAt.Time
+	}
+	if trip.DriverID.Valid {
+		driver, err := s.safetyRepo.GetSafetyDriver(ctx, trip.DriverID)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("failed to get driver: %w", err)
+		}
+		if err == nil {
+			response.Driver = toSharedTripDriver(driver)
+			if chatOpen(trip) {
+				response.DriverLatitude, response.DriverLongitude = numericPoint(driver.CurrentLatitude, driver.CurrentLongitude)
+			}
+		}
+	}
+	return response, nil
+}
+
+// RaiseSOS puts an SOS at the top of the safety team's queue with a
+// snapshot of the trip, and sends the reporter's emergency contacts a link
+// to follow it. Raising an SOS again while the first is unresolved returns
+// the first without alerting anyone again.
+func (s *SafetyService) RaiseSOS(ctx context.Context, tripID, userID uuid.UUID, req *domain.SOSRequest) (*domain.SOSResponse, error) {
+	trip, err := s.participantTrip(ctx, tripID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !chatOpen(trip) {
+		return nil, ErrTripNotUnderWay
+	}
+
+	if (req.Latitude == nil) != (req.Longitude == nil) {
+		return nil, fmt.Errorf("%w: latitude and longitude must be given together", ErrInvalidSOS)
+	}
+	if req.Latitude != nil && (*req.Latitude < -90 || *req.Latitude > 90 || *req.Longitude < -180 || *req.Longitude > 180) {
+		return nil, fmt.Errorf("%w: invalid location", ErrInvalidSOS)
+	}
+	message := strings.TrimSpace(req.Message)
+	if len([]rune(message)) > maxSOSMessageLength {
+		return nil, fmt.Errorf("%w: message is limited to %d characters", ErrInvalidSOS, maxSOSMessageLength)
+	}
+
+	params := db.CreateSafetyIncidentParams{
+		TripID:     trip.ID,
+		ReporterID: utils.ToPgUUID(userID),
+		Kind:       domain.SafetyIncidentSOS,
+		Details:    pgtype.Text{String: message, Valid: message != ""},
+	}
+	if req.Latitude != nil {
+		params.Latitude = utils.Float64ToNumeric(*req.Latitude)
+		params.Longitude = utils.Float64ToNumeric(*req.Longitude)
+	}
+	incident, created, err := s.raise(ctx, trip, params)
+	if err != nil {
+		return nil, err
+	}
+	if !created {
+		return &domain.SOSResponse{Incident: toSafetyIncidentResponse(incident)}, nil
+	}
+
+	contacts, err := s.safetyRepo.ListEmergencyContacts(ctx, utils.ToPgUUID(userID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get emergency contacts: %w", err)
+	}
+	link, err := s.createShareLink(ctx, trip.ID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	reporterRole := domain.ChatSenderRider
+	if utils.FromPgUUID(trip.DriverID) == userID {
+		reporterRole = domain.ChatSenderDriver
+	}
+	event := events.SafetySOSEvent{
+		IncidentID:   utils.FromPgUUID(incident.ID).String(),
+		TripID:       tripID.String(),
+		ReporterID:   userID.String(),
+		ReporterRole: reporterRole,
+		Latitude:     req.Latitude,
+		Longitude:    req.Longitude,
+		ShareURL:     link.URL,
+		Contacts:     make([]events.EmergencyContactPayload, len(contacts)),
+		Timestamp:    time.Now(),
+	}
+	for i, contact := range contacts {
+		event.Contacts[i] = events.EmergencyContactPayload{Name: contact.Name, PhoneNumber: contact.PhoneNumber}
+	}
+	s.eventBus.Publish(ctx, events.SubjectSafetySOS, event)
+	log.Printf("SOS raised on trip %s by %s %s", tripID, reporterRole, userID)
+
+	return &domain.SOSResponse{
+		Incident:         toSafetyIncidentResponse(incident),
+		ShareURL:         link.URL,
+		ContactsNotified: len(contacts),
+	}, nil
+}
+
+// raise snapshots a trip and opens an incident on it, or returns the
+// unresolved incident of the same kind it already has.
+func (s *SafetyService) raise(ctx context.Context, trip db.Trip, params db.CreateSafetyIncidentParams) (db.SafetyIncident, bool, error) {
+	snapshot, err := json.Marshal(s.snapshot(ctx, trip))
+	if err != nil {
+		return db.SafetyIncident{}, false, fmt.Errorf("failed to encode trip snapshot: %w", err)
+	}
+	params.Snapshot = snapshot
+
+	incident, err := s.safetyRepo.CreateSafetyIncident(ctx, params)
+	if err == nil {
+		return incident, true, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return db.SafetyIncident{}, false, fmt.Errorf("failed to create safety incident: %w", err)
+	}
+
+	incident, err = s.safetyRepo.GetUnresolvedSafetyIncident(ctx, db.GetUnresolvedSafetyIncidentParams{
+		TripID: trip.ID,
+		Kind:   params.Kind,
+	})
+	if err != nil {
+		return db.SafetyIncident{}, false, fmt.Errorf("failed to get safety incident: %w", err)
+	}
+	return incident, false, nil
+}
+
+// snapshot records a trip as it stands for the safety team. What can't be
+// looked up is left out rather than holding up an SOS.
+func (s *SafetyService) snapshot(ctx context.Context, trip db.Trip) domain.SafetySnapshot {
+	snapshot := domain.SafetySnapshot{
+		TripID:            utils.FromPgUUID(trip.ID).String(),
+		Status:            trip.Status,
+		RiderID:           utils.FromPgUUID(trip.UserID).String(),
+		PickupAddress:     trip.PickupAddress,
+		DropoffAddress:    trip.DropoffAddress,
+		EstimatedDuration: trip.EstimatedDuration.Int32,
+		CapturedAt:        time.Now(),
+	}
+	if trip.StartedAt.Valid {
+		snapshot.StartedAt = &trip.StartedAt.Time
+	}
+	if trip.DriverID.Valid {
+		snapshot.DriverID = utils.FromPgUUID(trip.DriverID).String()
+		driver, err := s.safetyRepo.GetSafetyDriver(ctx, trip.DriverID)
+		if err != nil {
+			log.Printf("Failed to get driver for safety snapshot of trip %s: %v", snapshot.TripID, err)
+		} else {
+			snapshot.Driver = toSharedTripDriver(driver)
+			snapshot.DriverLatitude, snapshot.DriverLongitude = numericPoint(driver.CurrentLatitude, driver.CurrentLongitude)
+		}
+	}
+	return snapshot
+}
+
+// ListIncidents returns the safety team's queue, oldest first, optionally
+// only incidents with a status.
+func (s *SafetyService) ListIncidents(ctx context.Context, status string, limit, offset int32) ([]domain.SafetyIncidentResponse, error) {
+	params := db.ListSafetyIncidentsParams{Limit: limit, Offset: offset}
+	switch status {
+	case "":
+	case domain.SafetyIncidentStatusOpen, domain.SafetyIncidentStatusAcknowledged, domain.SafetyIncidentStatusResolved:
+		params.Status = pgtype.Text{String: status, Valid: true}
+	default:
+		return nil, ErrInvalidIncidentStatus
+	}
+
+	incidents, err := s.safetyRepo.ListSafetyIncidents(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get safety incidents: %w", err)
+	}
+
+	response := make([]domain.SafetyIncidentResponse, len(incidents))
+	for i, incident := range incidents {
+		response[i] = toSafetyIncidentResponse(incident)
+	}
+	return response, nil
+}
+
+func (s *SafetyService) GetIncident(ctx context.Context, incidentID uuid.UUID) (*domain.SafetyIncidentResponse, error) {
+	incident, err := s.safetyRepo.GetSafetyIncident(ctx, utils.ToPgUUID(incidentID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrIncidentNotFound
+		}
+		return nil, fmt.Errorf("failed to get safety incident: %w", err)
+	}
+	response := toSafetyIncidentResponse(incident)
+	return &response, nil
+}
+
+// AcknowledgeIncident marks an open incident as being handled by an admin.
+func (s *SafetyService) AcknowledgeIncident(ctx context.Context, incidentID, adminID uuid.UUID) (*domain.SafetyIncidentResponse, error) {
+	incident, err := s.safetyRepo.AcknowledgeSafetyIncident(ctx, db.AcknowledgeSafetyIncidentParams{
+		ID:             utils.ToPgUUID(incidentID),
+		AcknowledgedBy: utils.ToPgUUID(adminID),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			if _, err := s.GetIncident(ctx, incidentID); err != nil {
+				return nil, err
+			}
+			return nil, ErrIncidentNotOpen
+		}
+		return nil, fmt.Errorf("failed to acknowledge safety incident: %w", err)
+	}
+	response := toSafetyIncidentResponse(incident)
+	return &response, nil
+}
+
+// ResolveIncident closes an incident with a note of what was done. Once
+// resolved, the same kind of incident can be raised on the trip again.
+func (s *SafetyService) ResolveIncident(ctx context.Context, incidentID, adminID uuid.UUID, req *domain.ResolveSafetyIncidentRequest) (*domain.SafetyIncidentResponse, error) {
+	note := strings.TrimSpace(req.Note)
+	if note == "" {
+		return nil, ErrResolutionNoteRequired
+	}
+
+	incident, err := s.safetyRepo.ResolveSafetyIncident(ctx, db.ResolveSafetyIncidentParams{
+		ID:             utils.ToPgUUID(incidentID),
+		ResolvedBy:     utils.ToPgUUID(adminID),
+		ResolutionNote: pgtype.Text{String: note, Valid: true},
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			if _, err := s.GetIncident(ctx, incidentID); err != nil {
+				return nil, err
+			}
+			return nil, ErrIncidentResolved
+		}
+		return nil, fmt.Errorf("failed to resolve safety incident: %w", err)
+	}
+	response := toSafetyIncidentResponse(incident)
+	return &response, nil
+}
+
+// HandleDriverLocation follows the driver of a trip under way: it notes
+// whether they've moved, and flags them when they're too far from the
+// planned route.
+func (s *SafetyService) HandleDriverLocation(ctx context.Context, driverID uuid.UUID, at routing.Point) error {
+	trip, err := s.tripRepo.GetDriverActiveTrip(ctx, utils.ToPgUUID(driverID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		return fmt.Errorf("failed to get active trip: %w", err)
+	}
+	if trip.Status != domain.TripStatusInProgress {
+		return nil
+	}
+
+	now := time.Now().UTC()
+	params := db.SaveTripSafetyTrackParams{
+		TripID:          trip.ID,
+		AnchorLatitude:  utils.Float64ToNumeric(at.Lat),
+		AnchorLongitude: utils.Float64ToNumeric(at.Lng),
+		AnchoredAt:      pgtype.Timestamp{Time: now, Valid: true},
+		LastSeenAt:      pgtype.Timestamp{Time: now, Valid: true},
+	}
+	track, err := s.safetyRepo.GetTripSafetyTrack(ctx, trip.ID)
+	switch {
+	case err == nil:
+		params.PlannedPolyline = track.PlannedPolyline
+		anchor := routing.Point{Lat: utils.NumericToFloat64(track.AnchorLatitude), Lng: utils.NumericToFloat64(track.AnchorLongitude)}
+		if routing.Distance(anchor, at) <= stationaryRadiusKm {
+			params.AnchorLatitude = track.AnchorLatitude
+			params.AnchorLongitude = track.AnchorLongitude
+			params.AnchoredAt = track.AnchoredAt
+		}
+	case !errors.Is(err, pgx.ErrNoRows):
+		return fmt.Errorf("failed to get safety track: %w", err)
+	}
+
+	if !params.PlannedPolyline.Valid {
+		params.PlannedPolyline = s.plannedRoute(ctx, trip)
+	}
+	if err := s.safetyRepo.SaveTripSafetyTrack(ctx, params); err != nil {
+		return fmt.Errorf("failed to save safety track: %w", err)
+	}
+
+	if !params.PlannedPolyline.Valid || params.PlannedPolyline.String == "" {
+		return nil
+	}
+	path, err := routing.DecodePolyline(params.PlannedPolyline.String)
+	if err != nil {
+		return fmt.Errorf("failed to decode planned route: %w", err)
+	}
+	if off := routing.DistanceToPath(at, path); off > s.deviationKm {
+		s.alert(ctx, trip, domain.SafetyIncidentRouteDeviation, at, fmt.Sprintf("Driver is %.0f m from the planned route", off*1000))
+	}
+	return nil
+}
+
+// plannedRoute is the road route a trip is expected to follow, from pickup
+// through its stops to dropoff. Pooled trips follow the pool's route, and
+// without a road graph routes are straight lines, so neither is checked;
+// they get an empty route rather than being routed on every update.
+func (s *SafetyService) plannedRoute(ctx context.Context, trip db.Trip) pgtype.Text {
+	if trip.PoolID.Valid {
+		return pgtype.Text{Valid: true}
+	}
+	stops, err := s.tripRepo.GetTripStops(ctx, trip.ID)
+	if err != nil {
+		log.Printf("Failed to get stops of trip %s: %v", utils.FromPgUUID(trip.ID), err)
+		return pgtype.Text{}
+	}
+	route, err := routing.RouteVia(ctx, s.router, routingPoints(tripRoute(trip, stops)))
+	if err != nil {
+		log.Printf("Failed to route trip %s: %v", utils.FromPgUUID(trip.ID), err)
+		return pgtype.Text{}
+	}
+	if len(route.Path) <= len(stops)+2 {
+		return pgtype.Text{Valid: true}
+	}
+	return pgtype.Text{String: route.Polyline(), Valid: true}
+}
+
+// RunAnomalyWorker checks trips under way for drivers stopped too long and
+// trips running far over their estimate. It blocks until ctx is cancelled.
+func (s *SafetyService) RunAnomalyWorker(ctx context.Context) {
+	ticker := time.NewTicker(s.checkInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.checkTrips(ctx)
+		}
+	}
+}
+
+func (s *SafetyService) checkTrips(ctx context.Context) {
+	trips, err := s.safetyRepo.ListTrackedTrips(ctx)
+	if err != nil {
+		log.Printf("Failed to load trips under way: %v", err)
+		return
+	}
+
+	now := time.Now().UTC()
+	for _, row := range trips {
+		trip := trackedTrip(row)
+		at := routing.Point{Lat: utils.NumericToFloat64(row.AnchorLatitude), Lng: utils.NumericToFloat64(row.AnchorLongitude)}
+
+		if stopped := now.Sub(row.AnchoredAt.Time); stopped >= s.longStop {
+			s.alert(ctx, trip, domain.SafetyIncidentLongStop, at, fmt.Sprintf("Driver has not moved for %d minutes", int(stopped.Minutes())))
+		}
+
+		if !trip.StartedAt.Valid || trip.EstimatedDuration.Int32 <= 0 {
+			continue
+		}
+		estimate := time.Duration(trip.EstimatedDuration.Int32) * time.Minute
+		allowed := max(estimate*time.Duration(100+s.overETAPercent)/100, estimate+minOverETA)
+		if taken := now.Sub(trip.StartedAt.Time); taken > allowed {
+			s.alert(ctx, trip, domain.SafetyIncidentOverETA, at, fmt.Sprintf("Trip has taken %d minutes against an estimate of %d", int(taken.Minutes()), trip.EstimatedDuration.Int32))
+		}
+	}
+}
+
+// alert opens an incident for an anomaly on a trip and tells the rider and
+// the safety team, unless the trip already has one of the same kind.
+func (s *SafetyService) alert(ctx context.Context, trip db.Trip, kind string, at routing.Point, details string) {
+	incident, created, err := s.raise(ctx, trip, db.CreateSafetyIncidentParams{
+		TripID:    trip.ID,
+		Kind:      kind,
+		Latitude:  utils.Float64ToNumeric(at.Lat),
+		Longitude: utils.Float64ToNumeric(at.Lng),
+		Details:   pgtype.Text{String: details, Valid: true},
+	})
+	if err != nil {
+		log.Printf("Failed to raise %s on trip %s: %v", kind, utils.FromPgUUID(trip.ID), err)
+		return
+	}
+	if !created {
+		return
+	}
+
+	log.Printf("Safety alert on trip %s: %s", utils.FromPgUUID(trip.ID), details)
+	s.eventBus.Publish(ctx, events.SubjectSafetyAlert, events.SafetyAlertEvent{
+		IncidentID: utils.FromPgUUID(incident.ID).String(),
+		TripID:     utils.FromPgUUID(trip.ID).String(),
+		UserID:     utils.FromPgUUID(trip.UserID).String(),
+		DriverID:   utils.FromPgUUID(trip.DriverID).String(),
+		Kind:       kind,
+		Details:    details,
+		Latitude:   at.Lat,
+		Longitude:  at.Lng,
+		Timestamp:  time.Now(),
+	})
+}
+
+// SubscribeToEvents follows drivers on trips under way, and re-plans a
+// trip's route when the rider changes their stops.
+func (s *SafetyService) SubscribeToEvents() {
+	s.eventBus.QueueSubscribe(events.SubjectDriverLocation, "trip-service-safety", func(ctx context.Context, data []byte) {
+		var payload events.DriverLocationPayload
+		if err := json.Unmarshal(data, &payload); err != nil {
+			log.Printf("Failed to unmarshal driver location event: %v", err)
+			return
+		}
+		driverID, err := uuid.Parse(payload.DriverID)
+		if err != nil {
+			log.Printf("Invalid driver ID in event: %v", err)
+			return
+		}
+
+		at := routing.Point{Lat: payload.Latitude, Lng: payload.Longitude}
+		if err := s.HandleDriverLocation(ctx, driverID, at); err != nil {
+			log.Printf("Failed to check safety of driver %s: %v", payload.DriverID, err)
+		}
+	})
+
+	s.eventBus.QueueSubscribe(events.SubjectTripStarted, "trip-service-safety", func(ctx context.Context, data []byte) {
+		var event events.TripStartedEvent
+		if err := json.Unmarshal(data, &event); err != nil {
+			log.Printf("Failed to unmarshal trip started event: %v", err)
+			return
+		}
+		tripID, err := uuid.Parse(event.TripID)
+		if err != nil {
+			return
+		}
+		if err := s.startTracking(ctx, tripID); err != nil {
+			log.Printf("Failed to start safety tracking of trip %s: %v", event.TripID, err)
+		}
+	})
+
+	s.eventBus.QueueSubscribe(events.SubjectTripRouteUpdated, "trip-service-safety", func(ctx context.Context, data []byte) {
+		var event events.TripRouteUpdatedEvent
+		if err := json.Unmarshal(data, &event); err != nil {
+			log.Printf("Failed to unmarshal trip route updated event: %v", err)
+			return
+		}
+		tripID, err := uuid.Parse(event.TripID)
+		if err != nil {
+			return
+		}
+		if err := s.safetyRepo.ClearTripPlannedRoute(ctx, utils.ToPgUUID(tripID)); err != nil {
+			log.Printf("Failed to clear planned route of trip %s: %v", event.TripID, err)
+		}
+	})
+}
+
+// startTracking anchors a trip at its pickup when it starts, so a trip
+// whose driver stops reporting their location is still checked.
+func (s *SafetyService) startTracking(ctx context.Context, tripID uuid.UUID) error {
+	trip, err := s.tripRepo.GetTrip(ctx, utils.ToPgUUID(tripID))
+	if err != nil {
+		return fmt.Errorf("failed to get trip: %w", err)
+	}
+	now := time.Now().UTC()
+	return s.safetyRepo.SaveTripSafetyTrack(ctx, db.SaveTripSafetyTrackParams{
+		TripID:          trip.ID,
+		AnchorLatitude:  trip.PickupLatitude,
+		AnchorLongitude: trip.PickupLongitude,
+		AnchoredAt:      pgtype.Timestamp{Time: now, Valid: true},
+		LastSeenAt:      pgtype.Timestamp{Time: now, Valid: true},
+	})
+}
+
+// participantTrip returns a trip the user is the rider or driver of.
+func (s *SafetyService) participantTrip(ctx context.Context, tripID, userID uuid.UUID) (db.Trip, error) {
+	trip, err := s.tripRepo.GetTrip(ctx, utils.ToPgUUID(tripID))
+	if err != nil {
+		return db.Trip{}, ErrTripNotFound
+	}
+	if !isTripParticipant(trip, userID) {
+		return db.Trip{}, ErrNotTripParticipant
+	}
+	return trip, nil
+}
+
+// hashShareToken is how share tokens are stored, so the table can't be
+// used to follow anyone's trip.
+func hashShareToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func numericPoint(lat, lng pgtype.Numeric) (*float64, *float64) {
+	if !lat.Valid || !lng.Valid {
+		return nil, nil
+	}
+	latitude, longitude := utils.NumericToFloat64(lat), utils.NumericToFloat64(lng)
+	return &latitude, &longitude
+}
+
+func trackedTrip(row db.ListTrackedTripsRow) db.Trip {
+	return db.Trip{
+		ID:                row.ID,
+		UserID:            row.UserID,
+		DriverID:          row.DriverID,
+		PickupAddress:     row.PickupAddress,
+		DropoffAddress:    row.DropoffAddress,
+		EstimatedDuration: row.EstimatedDuration,
+		Status:            row.Status,
+		StartedAt:         row.StartedAt,
+	}
+}
+
+func toEmergencyContactResponse(contact db.EmergencyContact) domain.EmergencyContactResponse {
+	return domain.EmergencyContactResponse{
+		ID:          utils.FromPgUUID(contact.ID).String(),
+		Name:        contact.Name,
+		PhoneNumber: contact.PhoneNumber,
+		CreatedAt:   contact.CreatedAt.Time,
+	}
+}
+
+func toSharedTripDriver(driver db.GetSafetyDriverRow) *domain.SharedTripDriver {
+	// Only the driver's first name is shown.
+	name, _, _ := strings.Cut(driver.FullName, " ")
+	return &domain.SharedTripDriver{
+		Name:         name,
+		VehicleModel: driver.VehicleModel,
+		VehicleColor: driver.VehicleColor,
+		PlateNumber:  driver.VehiclePlateNumber,
+	}
+}
+
+func toSafetyIncidentResponse(incident db.SafetyIncident) domain.SafetyIncidentResponse {
+	response := domain.SafetyIncidentResponse{
+		ID:             utils.FromPgUUID(incident.ID).String(),
+		TripID:         utils.FromPgUUID(incident.TripID).String(),
+		Kind:           incident.Kind,
+		Status:         incident.Status,
+		Details:        incident.Details.String,
+		ResolutionNote: incident.ResolutionNote.String,
+		CreatedAt:      incident.CreatedAt.Time,
+	}
+	response.Latitude, response.Longitude = numericPoint(incident.Latitude, incident.Longitude)
+	if incident.ReporterID.Valid {
+		response.ReporterID = utils.FromPgUUID(incident.ReporterID).String()
+	}
+	var snapshot domain.SafetySnapshot
+	if err := json.Unmarshal(incident.Snapshot, &snapshot); err == nil {
+		response.Snapshot = &snapshot
+	}
+	if incident.AcknowledgedBy.Valid {
+		response.AcknowledgedBy = utils.FromPgUUID(incident.AcknowledgedBy).String()
+	}
+	if incident.AcknowledgedAt.Valid {
+		response.AcknowledgedAt = &incident.AcknowledgedAt.Time
+	}
+	if incident.ResolvedBy.Valid {
+		response.ResolvedBy = utils.FromPgUUID(incident.ResolvedBy).String()
+	}
+	if incident.ResolvedAt.Valid {
+		response.ResolvedAt = &incident.ResolvedAt.Time
+	}
+	return response
+}
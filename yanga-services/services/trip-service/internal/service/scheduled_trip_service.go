@@ -0,0 +1,262 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/namycodes/yanga-services/services/trip-service/internal/db"
+	"github.com/namycodes/yanga-services/services/trip-service/internal/repository"
+	"github.com/namycodes/yanga-services/shared-lib/config"
+	"github.com/namycodes/yanga-services/shared-lib/domain"
+	"github.com/namycodes/yanga-services/shared-lib/events"
+	"github.com/namycodes/yanga-services/shared-lib/money"
+	"github.com/namycodes/yanga-services/shared-lib/utils"
+)
+
+const (
+	schedulerInterval  = 30 * time.Second
+	schedulerBatchSize = 100
+
+	// A driver can't hold two reservations whose pickups are closer than this.
+	reservationGap = time.Hour
+)
+
+var (
+	ErrInvalidPickupTime    = errors.New("invalid pickup time")
+	ErrTripNotScheduled     = errors.New("trip is not a scheduled trip awaiting dispatch")
+	ErrTripAlreadyReserved  = errors.New("trip is already reserved by another driver")
+	ErrReservationConflict  = errors.New("you already have a reservation close to this pickup time")
+	ErrReservationNotFound  = errors.New("you have not reserved this trip")
+	ErrCannotReserveOwnTrip = errors.New("cannot reserve your own trip")
+	ErrPickupTimePassed     = errors.New("trip pickup time has passed")
+)
+
+type ScheduledTripService struct {
+	tripRepo     *repository.TripRepository
+	cityService  *CityService
+	queueService *AirportQueueService
+	eventBus     events.EventBus
+	dispatchLead time.Duration
+	reminderLead time.Duration
+}
+
+func NewScheduledTripService(tripRepo *repository.TripRepository, cityService *CityService, queueService *AirportQueueService, eventBus events.EventBus, cfg *config.Config) *ScheduledTripService {
+	return &ScheduledTripService{
+		tripRepo:     tripRepo,
+		cityService:  cityService,
+		queueService: queueService,
+		eventBus:     eventBus,
+		dispatchLead: time.Duration(cfg.ScheduleDispatchMinutes) * time.Minute,
+		reminderLead: time.Duration(cfg.ScheduleReminderMinutes) * time.Minute,
+	}
+}
+
+func (s *ScheduledTripService) GetUserScheduledTrips(ctx context.Context, userID uuid.UUID, limit, offset int32) ([]db.Trip, error) {
+	return s.tripRepo.GetUserScheduledTrips(ctx, db.GetUserScheduledTripsParams{
+		UserID: utils.ToPgUUID(userID),
+		Limit:  limit,
+		Offset: offset,
+	})
+}
+
+// GetAvailableTrips lists upcoming scheduled trips no driver has reserved,
+// in the driver's city if they have one.
+func (s *ScheduledTripService) GetAvailableTrips(ctx context.Context, driverID uuid.UUID, limit, offset int32) ([]db.Trip, error) {
+	city, err := s.cityService.driverCity(ctx, driverID)
+	if err != nil {
+		return nil, err
+	}
+	return s.tripRepo.GetAvailableScheduledTrips(ctx, db.GetAvailableScheduledTripsParams{
+		CityCode: pgtype.Text{String: city, Valid: city != ""},
+		Limit:    limit,
+		Offset:   offset,
+	})
+}
+
+func (s *ScheduledTripService) GetReservedTrips(ctx context.Context, driverID uuid.UUID) ([]db.Trip, error) {
+	return s.tripRepo.GetDriverReservedTrips(ctx, utils.ToPgUUID(driverID))
+}
+
+// ReserveTrip lets a driver claim a scheduled trip in advance. The trip is
+// assigned to them when it is dispatched.
+func (s *ScheduledTripService) ReserveTrip(ctx context.Context, tripID, driverID uuid.UUID) error {
+	trip, err := s.tripRepo.GetTrip(ctx, utils.ToPgUUID(tripID))
+	if err != nil {
+		return ErrTripNotFound
+	}
+
+	if trip.Status != domain.TripStatusScheduled {
+		return ErrTripNotScheduled
+	}
+	if utils.FromPgUUID(trip.UserID) == driverID {
+		return ErrCannotReserveOwnTrip
+	}
+	if !trip.PickupAt.Time.After(time.Now().UTC()) {
+		return ErrPickupTimePassed
+	}
+
+	if err := checkVehicleEligibility(ctx, s.tripRepo, trip, utils.ToPgUUID(driverID)); err != nil {
+		return err
+	}
+	if err := s.cityService.checkDriver(ctx, trip, driverID); err != nil {
+		return err
+	}
+
+	reserved, err := s.tripRepo.GetDriverReservedTrips(ctx, utils.ToPgUUID(driverID))
+	if err != nil {
+		return fmt.Errorf("failed to get reserved trips: %w", err)
+	}
+	for _, r := range reserved {
+		gap := r.PickupAt.Time.Sub(trip.PickupAt.Time)
+		if gap > -reservationGap && gap < reservationGap {
+			return ErrReservationConflict
+		}
+	}
+
+	updated, err := s.tripRepo.ReserveScheduledTrip(ctx, db.ReserveScheduledTripParams{
+		ID:               trip.ID,
+		ReservedDriverID: utils.ToPgUUID(driverID),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to reserve trip: %w", err)
+	}
+	if updated == 0 {
+		return ErrTripAlreadyReserved
+	}
+	return nil
+}
+
+// ReleaseReservation hands a reserved trip back so other drivers can claim it.
+func (s *ScheduledTripService) ReleaseReservation(ctx context.Context, tripID, driverID uuid.UUID) error {
+	updated, err := s.tripRepo.ReleaseScheduledTrip(ctx, db.ReleaseScheduledTripParams{
+		ID:               utils.ToPgUUID(tripID),
+		ReservedDriverID: utils.ToPgUUID(driverID),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to release reservation: %w", err)
+	}
+	if updated == 0 {
+		return ErrReservationNotFound
+	}
+	return nil
+}
+
+// RunScheduler sends pickup reminders and dispatches scheduled trips as
+// their pickup time approaches. It blocks until ctx is cancelled.
+func (s *ScheduledTripService) RunScheduler(ctx context.Context) {
+	ticker := time.NewTicker(schedulerInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.sendReminders(ctx)
+			s.dispatchDueTrips(ctx)
+		}
+	}
+}
+
+func (s *ScheduledTripService) sendReminders(ctx context.Context) {
+	trips, err := s.tripRepo.GetTripsDueForReminder(ctx, db.GetTripsDueForReminderParams{
+		RemindBefore: pgtype.Timestamp{Time: time.Now().UTC().Add(s.reminderLead), Valid: true},
+		Limit:        schedulerBatchSize,
+	})
+	if err != nil {
+		log.Printf("Failed to load trips due for reminder: %v", err)
+		return
+	}
+
+	for _, trip := range trips {
+		// Claiming the reminder first keeps a second instance from sending it
+		// again.
+		claimed, err := s.tripRepo.MarkTripReminderSent(ctx, trip.ID)
+		if err != nil {
+			log.Printf("Failed to mark reminder for trip %s: %v", utils.FromPgUUID(trip.ID), err)
+			continue
+		}
+		if claimed == 0 {
+			continue
+		}
+
+		event := events.TripReminderEvent{
+			TripID:        utils.FromPgUUID(trip.ID).String(),
+			UserID:        utils.FromPgUUID(trip.UserID).String(),
+			PickupAddress: trip.PickupAddress,
+			PickupAt:      trip.PickupAt.Time,
+			Timestamp:     time.Now(),
+		}
+		if trip.ReservedDriverID.Valid {
+			event.ReservedDriverID = utils.FromPgUUID(trip.ReservedDriverID).String()
+		}
+		s.eventBus.Publish(ctx, events.SubjectTripReminder, event)
+	}
+}
+
+func (s *ScheduledTripService) dispatchDueTrips(ctx context.Context) {
+	trips, err := s.tripRepo.GetTripsDueForDispatch(ctx, db.GetTripsDueForDispatchParams{
+		DispatchBefore: pgtype.Timestamp{Time: time.Now().UTC().Add(s.dispatchLead), Valid: true},
+		Limit:          schedulerBatchSize,
+	})
+	if err != nil {
+		log.Printf("Failed to load trips due for dispatch: %v", err)
+		return
+	}
+
+	for _, due := range trips {
+		trip, err := s.tripRepo.DispatchScheduledTrip(ctx, due.ID)
+		if err != nil {
+			// Cancelled or dispatched by another instance in the meantime.
+			if !errors.Is(err, pgx.ErrNoRows) {
+				log.Printf("Failed to dispatch trip %s: %v", utils.FromPgUUID(due.ID), err)
+			}
+			continue
+		}
+
+		if trip.Status == domain.TripStatusAccepted {
+			s.eventBus.Publish(ctx, events.SubjectTripAccepted, events.TripAcceptedEvent{
+				TripID:    utils.FromPgUUID(trip.ID).String(),
+				DriverID:  utils.FromPgUUID(trip.DriverID).String(),
+				UserID:    utils.FromPgUUID(trip.UserID).String(),
+				Timestamp: time.Now(),
+			})
+			log.Printf("Scheduled trip %s dispatched to reserved driver %s", utils.FromPgUUID(trip.ID), utils.FromPgUUID(trip.DriverID))
+			continue
+		}
+
+		publishTripCreated(ctx, s.eventBus, trip, dispatchVehicleTypes(ctx, s.tripRepo, trip), s.queueService.dispatchDrivers(ctx, trip))
+		log.Printf("Scheduled trip %s dispatched for matching", utils.FromPgUUID(trip.ID))
+	}
+}
+
+// publishTripCreated puts a trip out for matching with nearby drivers whose
+// vehicle is one of vehicleTypes, or any driver when it is empty. Airport
+// pickups go to queuedDrivers first when any are waiting.
+func publishTripCreated(ctx context.Context, eventBus events.EventBus, trip db.Trip, vehicleTypes, queuedDrivers []string) {
+	event := events.TripCreatedEvent{
+		TripID:           utils.FromPgUUID(trip.ID).String(),
+		UserID:           utils.FromPgUUID(trip.UserID).String(),
+		PickupLatitude:   utils.NumericToFloat64(trip.PickupLatitude),
+		PickupLongitude:  utils.NumericToFloat64(trip.PickupLongitude),
+		DropoffLatitude:  utils.NumericToFloat64(trip.DropoffLatitude),
+		DropoffLongitude: utils.NumericToFloat64(trip.DropoffLongitude),
+		TripType:         trip.TripType,
+		VehicleTypes:     vehicleTypes,
+		DriverIDs:        queuedDrivers,
+		CityCode:         trip.CityCode.String,
+		Currency:         trip.Currency,
+		CreatedAt:        trip.CreatedAt.Time,
+	}
+	if trip.EstimatedFare.Valid {
+		fare := money.FromNumeric(trip.EstimatedFare, money.Currency(trip.Currency)).Float64()
+		event.EstimatedFare = &fare
+	}
+	eventBus.Publish(ctx, events.SubjectTripCreated, event)
+}
@@ -6,83 +6,395 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/namycodes/yanga-services/services/trip-service/internal/db"
 	"github.com/namycodes/yanga-services/services/trip-service/internal/repository"
+	"github.com/namycodes/yanga-services/shared-lib/config"
 	"github.com/namycodes/yanga-services/shared-lib/domain"
 	"github.com/namycodes/yanga-services/shared-lib/events"
+	"github.com/namycodes/yanga-services/shared-lib/money"
+	"github.com/namycodes/yanga-services/shared-lib/routing"
 	"github.com/namycodes/yanga-services/shared-lib/utils"
 )
 
+// ErrETAUnavailable is returned for trips that have no driver on the way.
+var ErrETAUnavailable = errors.New("no driver is on the way for this trip")
+
+// ErrInvalidFare is returned when a driver completes a trip with a fare that
+// can't be charged.
+var ErrInvalidFare = errors.New("invalid fare")
+
 type TripService struct {
-	tripRepo        *repository.TripRepository
-	rideRequestRepo *repository.RideRequestRepository
-	eventBus        events.EventBus
+	tripRepo         *repository.TripRepository
+	rideRequestRepo  *repository.RideRequestRepository
+	promotionService *PromotionService
+	poolService      *PoolService
+	deliveryService  *DeliveryService
+	placeService     *PlaceService
+	geofenceService  *GeofenceService
+	cityService      *CityService
+	queueService     *AirportQueueService
+	router           routing.Router
+	eventBus         events.EventBus
+	minScheduleLead  time.Duration
+	maxScheduleLead  time.Duration
+	fareMargin       money.Rate
 }
 
-func NewTripService(tripRepo *repository.TripRepository, rideRequestRepo *repository.RideRequestRepository, eventBus events.EventBus) *TripService {
+func NewTripService(tripRepo *repository.TripRepository, rideRequestRepo *repository.RideRequestRepository, promotionService *PromotionService, poolService *PoolService, deliveryService *DeliveryService, placeService *PlaceService, geofenceService *GeofenceService, cityService *CityService, queueService *AirportQueueService, router routing.Router, eventBus events.EventBus, cfg *config.Config) *TripService {
 	return &TripService{
-		tripRepo:        tripRepo,
-		rideRequestRepo: rideRequestRepo,
-		eventBus:        eventBus,
+		tripRepo:         tripRepo,
+		rideRequestRepo:  rideRequestRepo,
+		promotionService: promotionService,
+		poolService:      poolService,
+		deliveryService:  deliveryService,
+		placeService:     placeService,
+		geofenceService:  geofenceService,
+		cityService:      cityService,
+		queueService:     queueService,
+		router:           router,
+		eventBus:         eventBus,
+		minScheduleLead:  time.Duration(cfg.ScheduleMinLeadMinutes) * time.Minute,
+		maxScheduleLead:  time.Duration(cfg.ScheduleMaxLeadHours) * time.Hour,
+		fareMargin:       money.Percent(float64(cfg.FareMaxOverEstimatePercent)),
 	}
 }
 
+// CreateTrip books a trip. Trips with a pickup_at are held as scheduled and
+// dispatched shortly before pickup; all others are dispatched immediately.
+// Pooled trips join a nearby pool when one fits and are dispatched only when
+// they start a new one. Deliveries carry a parcel instead of the rider and
+// are priced at the delivery rates.
 func (s *TripService) CreateTrip(ctx context.Context, userID uuid.UUID, req *domain.CreateTripRequest) (*db.Trip, error) {
 	if err := s.validateCreateTripRequest(req); err != nil {
 		return nil, err
 	}
+	if err := s.placeService.resolveTripAddresses(ctx, req); err != nil {
+		return nil, err
+	}
 
-	// Calculate distance and estimated fare
-	distance := utils.CalculateDistance(
-		req.PickupLatitude, req.PickupLongitude,
-		req.DropoffLatitude, req.DropoffLongitude,
-	)
-	estimatedFare := s.calculateFare(distance)
+	now := time.Now().UTC()
+	status := domain.TripStatusPending
+	var pickupAt pgtype.Timestamp
+	if req.PickupAt != nil {
+		lead := req.PickupAt.Sub(now)
+		if lead < s.minScheduleLead || lead > s.maxScheduleLead {
+			return nil, fmt.Errorf("%w: pickup must be between %s and %s from now",
+				ErrInvalidPickupTime, s.minScheduleLead, s.maxScheduleLead)
+		}
+		status = domain.TripStatusScheduled
+		pickupAt = pgtype.Timestamp{Time: req.PickupAt.UTC(), Valid: true}
+	}
 
-	// Convert UUID to pgtype.UUID
-	var userPGUUID pgtype.UUID
-	if err := userPGUUID.Scan(userID); err != nil {
-		return nil, fmt.Errorf("invalid user ID: %w", err)
+	vehicleType := strings.ToLower(strings.TrimSpace(req.VehicleType))
+	tripType := domain.TripTypeRide
+	var parcel *parcelCategory
+	if req.TripType == domain.TripTypeDelivery {
+		category, err := s.deliveryService.validateRequest(req, vehicleType)
+		if err != nil {
+			return nil, err
+		}
+		tripType = domain.TripTypeDelivery
+		parcel = &category
 	}
 
-	// Convert to pgtype.Numeric
-	var pickupLat, pickupLng, dropoffLat, dropoffLng, estFare, dist pgtype.Numeric
-	pickupLat.Scan(req.PickupLatitude)
-	pickupLng.Scan(req.PickupLongitude)
-	dropoffLat.Scan(req.DropoffLatitude)
-	dropoffLng.Scan(req.DropoffLongitude)
-	estFare.Scan(estimatedFare)
-	dist.Scan(distance)
+	var poolSeats int
+	if req.Pooled {
+		var err error
+		if poolSeats, err = s.poolService.validateRequest(req.Seats, len(req.Stops), req.PickupAt != nil); err != nil {
+			return nil, err
+		}
+	}
+
+	zones, err := s.checkServiceArea(ctx, req.PickupLatitude, req.PickupLongitude, req.DropoffLatitude, req.DropoffLongitude, req.Stops)
+	if err != nil {
+		return nil, err
+	}
+	city, err := s.cityService.resolve(ctx, zones.cityCode)
+	if err != nil {
+		return nil, err
+	}
+	if err := city.checkFeatures(req.Pooled, parcel != nil, req.PickupAt != nil, len(req.Stops)); err != nil {
+		return nil, err
+	}
+	price, err := s.priceRequest(ctx, city.rates, req.PickupLatitude, req.PickupLongitude, req.DropoffLatitude, req.DropoffLongitude, req.Stops, req.Pooled, parcel)
+	if err != nil {
+		return nil, err
+	}
+	price.zoneFee = zones.zoneFee(city.currency)
+	subtotal := price.subtotal()
+
+	promo, discount, err := s.promotionService.resolvePromo(ctx, fareContext{
+		userID:      userID,
+		cityCode:    city.code,
+		subtotal:    subtotal,
+		vehicleType: vehicleType,
+		pickupLat:   req.PickupLatitude,
+		pickupLng:   req.PickupLongitude,
+		at:          now,
+	}, req.PromoCode)
+	if err != nil {
+		return nil, err
+	}
+
+	paymentMethod := req.PaymentMethod
+	if paymentMethod == "" {
+		paymentMethod = domain.PaymentMethodCash
+	}
 
 	params := db.CreateTripParams{
-		UserID:           userPGUUID,
-		PickupLatitude:   pickupLat,
-		PickupLongitude:  pickupLng,
-		PickupAddress:    req.PickupAddress,
-		DropoffLatitude:  dropoffLat,
-		DropoffLongitude: dropoffLng,
-		DropoffAddress:   req.DropoffAddress,
-		EstimatedFare:    estFare,
-		Distance:         dist,
-	}
-
-	trip, err := s.tripRepo.CreateTrip(ctx, params)
+		UserID:            utils.ToPgUUID(userID),
+		PickupLatitude:    utils.Float64ToNumeric(req.PickupLatitude),
+		PickupLongitude:   utils.Float64ToNumeric(req.PickupLongitude),
+		PickupAddress:     req.PickupAddress,
+		DropoffLatitude:   utils.Float64ToNumeric(req.DropoffLatitude),
+		DropoffLongitude:  utils.Float64ToNumeric(req.DropoffLongitude),
+		DropoffAddress:    req.DropoffAddress,
+		EstimatedFare:     subtotal.Numeric(),
+		EstimatedDuration: pgtype.Int4{Int32: int32(price.duration), Valid: true},
+		Distance:          utils.Float64ToNumeric(price.distance),
+		PaymentMethod:     pgtype.Text{String: paymentMethod, Valid: true},
+		VehicleType:       pgtype.Text{String: vehicleType, Valid: vehicleType != ""},
+		SubtotalFare:      subtotal.Numeric(),
+		DiscountAmount:    money.Zero(city.currency).Numeric(),
+		Status:            status,
+		PickupAt:          pickupAt,
+		TripType:          tripType,
+		CityCode:          pgtype.Text{String: city.code, Valid: true},
+		ZoneFee:           price.zoneFee.Numeric(),
+		Currency:          city.currency.String(),
+	}
+	booking := tripBooking{stops: req.Stops, poolSeats: poolSeats}
+	if parcel != nil {
+		booking.parcel = req.Delivery
+	}
+
+	if promo == nil {
+		trip, err := s.createTrip(ctx, params, booking, nil)
+		if err != nil {
+			return nil, err
+		}
+		s.publishTripBooked(ctx, trip, city)
+		return &trip, nil
+	}
+
+	discounted := params
+	discounted.EstimatedFare = subtotal.Sub(discount).Numeric()
+	discounted.DiscountAmount = discount.Numeric()
+	discounted.PromoCode = pgtype.Text{String: promo.Code, Valid: true}
+
+	trip, err := s.createTrip(ctx, discounted, booking, func(q *db.Queries, trip db.Trip) error {
+		return s.promotionService.reservePromo(ctx, q, *promo, userID, trip.ID, discount)
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to create trip: %w", err)
+		// An auto-applied promotion can run out between quoting and booking;
+		// the rider didn't ask for it, so book at the full fare instead.
+		autoApplied := req.PromoCode == ""
+		if !autoApplied || !(errors.Is(err, ErrPromoUsageLimitReached) || errors.Is(err, ErrPromoAlreadyUsed)) {
+			return nil, err
+		}
+		if trip, err = s.createTrip(ctx, params, booking, nil); err != nil {
+			return nil, err
+		}
 	}
 
+	s.publishTripBooked(ctx, trip, city)
 	return &trip, nil
 }
 
-func (s *TripService) GetTripByID(ctx context.Context, tripID uuid.UUID) (*db.Trip, error) {
-	var pgUUID pgtype.UUID
-	if err := pgUUID.Scan(tripID); err != nil {
-		return nil, fmt.Errorf("invalid trip ID: %w", err)
+// tripBooking is what gets stored alongside a new trip.
+type tripBooking struct {
+	stops     []domain.TripStopRequest
+	poolSeats int
+	parcel    *domain.ParcelDeliveryRequest
+}
+
+// createTrip inserts a trip with its stops or parcel in one transaction,
+// adding it to a pool when poolSeats is set. then, if set, runs in the same
+// transaction once the trip exists.
+func (s *TripService) createTrip(ctx context.Context, params db.CreateTripParams, booking tripBooking, then func(q *db.Queries, trip db.Trip) error) (db.Trip, error) {
+	var trip db.Trip
+	err := s.tripRepo.WithTx(ctx, func(q *db.Queries) error {
+		var err error
+		if trip, err = q.CreateTrip(ctx, params); err != nil {
+			return fmt.Errorf("failed to create trip: %w", err)
+		}
+		for i, stop := range booking.stops {
+			if _, err := q.CreateTripStop(ctx, db.CreateTripStopParams{
+				TripID:    trip.ID,
+				StopOrder: int32(i + 1),
+				Latitude:  utils.Float64ToNumeric(stop.Latitude),
+				Longitude: utils.Float64ToNumeric(stop.Longitude),
+				Address:   stop.Address,
+			}); err != nil {
+				return fmt.Errorf("failed to create trip stop: %w", err)
+			}
+		}
+		if booking.parcel != nil {
+			if err := s.deliveryService.create(ctx, q, trip.ID, booking.parcel); err != nil {
+				return err
+			}
+		}
+		if booking.poolSeats > 0 {
+			if err := s.poolService.join(ctx, q, &trip, booking.poolSeats); err != nil {
+				return err
+			}
+		}
+		if then != nil {
+			return then(q, trip)
+		}
+		return nil
+	})
+	return trip, err
+}
+
+// publishTripBooked announces a new trip: immediate trips go out for
+// matching, first to the airport queue for airport pickups, scheduled trips
+// are only confirmed until they are dispatched and pooled trips that joined
+// a pool go straight to its driver.
+func (s *TripService) publishTripBooked(ctx context.Context, trip db.Trip, city cityConfig) {
+	switch trip.Status {
+	case domain.TripStatusScheduled:
+	case domain.TripStatusAccepted:
+		s.poolService.publishJoined(ctx, trip)
+		return
+	default:
+		publishTripCreated(ctx, s.eventBus, trip, dispatchVehicleTypes(ctx, s.tripRepo, trip), s.queueService.dispatchDrivers(ctx, trip))
+		return
+	}
+
+	s.eventBus.Publish(ctx, events.SubjectTripScheduled, events.TripScheduledEvent{
+		TripID:         utils.FromPgUUID(trip.ID).String(),
+		UserID:         utils.FromPgUUID(trip.UserID).String(),
+		PickupAddress:  trip.PickupAddress,
+		DropoffAddress: trip.DropoffAddress,
+		EstimatedFare:  money.FromNumeric(trip.EstimatedFare, money.Currency(trip.Currency)).Float64(),
+		Currency:       trip.Currency,
+		PickupAt:       trip.PickupAt.Time,
+		Timezone:       city.timezone,
+		Timestamp:      time.Now(),
+	})
+}
+
+// QuoteFare prices a trip without booking it, applying the requested promo
+// code or the best eligible automatic promotion.
+func (s *TripService) QuoteFare(ctx context.Context, userID uuid.UUID, req *domain.FareQuoteRequest) (*domain.FareQuoteResponse, error) {
+	if err := validateLocations(req.PickupLatitude, req.PickupLongitude, req.DropoffLatitude, req.DropoffLongitude); err != nil {
+		return nil, err
+	}
+
+	var parcel *parcelCategory
+	if req.TripType == domain.TripTypeDelivery {
+		if req.Pooled || len(req.Stops) > 0 {
+			return nil, fmt.Errorf("%w: deliveries can't be pooled or have stops", ErrInvalidDelivery)
+		}
+		category, err := parcelFor(req.ParcelSize, req.WeightKg)
+		if err != nil {
+			return nil, err
+		}
+		parcel = &category
+	}
+
+	if req.Pooled {
+		if _, err := s.poolService.validateRequest(req.Seats, len(req.Stops), false); err != nil {
+			return nil, err
+		}
+	}
+
+	zones, err := s.checkServiceArea(ctx, req.PickupLatitude, req.PickupLongitude, req.DropoffLatitude, req.DropoffLongitude, req.Stops)
+	if err != nil {
+		return nil, err
+	}
+	city, err := s.cityService.resolve(ctx, zones.cityCode)
+	if err != nil {
+		return nil, err
+	}
+	if err := city.checkFeatures(req.Pooled, parcel != nil, false, len(req.Stops)); err != nil {
+		return nil, err
+	}
+	price, err := s.priceRequest(ctx, city.rates, req.PickupLatitude, req.PickupLongitude, req.DropoffLatitude, req.DropoffLongitude, req.Stops, req.Pooled, parcel)
+	if err != nil {
+		return nil, err
+	}
+	price.zoneFee = zones.zoneFee(city.currency)
+	subtotal := price.subtotal()
+
+	promo, discount, err := s.promotionService.resolvePromo(ctx, fareContext{
+		userID:      userID,
+		cityCode:    city.code,
+		subtotal:    subtotal,
+		vehicleType: strings.ToLower(strings.TrimSpace(req.VehicleType)),
+		pickupLat:   req.PickupLatitude,
+		pickupLng:   req.PickupLongitude,
+		at:          time.Now().UTC(),
+	}, req.PromoCode)
+	if err != nil {
+		return nil, err
 	}
 
+	quote := &domain.FareQuoteResponse{
+		Distance:          price.distance,
+		EstimatedDuration: price.duration,
+		Polyline:          price.polyline,
+		BaseFare:          price.baseFare.Float64(),
+		DistanceFare:      price.distanceFare.Float64(),
+		StopFare:          price.stopFare.Float64(),
+		PoolDiscount:      price.poolDiscount.Float64(),
+		ParcelFare:        price.parcelFare.Float64(),
+		ZoneFee:           price.zoneFee.Float64(),
+		Subtotal:          subtotal.Float64(),
+		Discount:          discount.Float64(),
+		Total:             subtotal.Sub(discount).Float64(),
+		CityCode:          city.code,
+		Currency:          city.currency.String(),
+	}
+	if promo != nil {
+		quote.PromoCode = promo.Code
+		quote.PromoDescription = promo.Description.String
+	}
+	return quote, nil
+}
+
+// checkServiceArea checks a requested route against the service areas and
+// zones, returning the trip's city and airport fees.
+func (s *TripService) checkServiceArea(ctx context.Context, pickupLat, pickupLng, dropoffLat, dropoffLng float64, stops []domain.TripStopRequest) (tripZones, error) {
+	points := make([]routing.Point, len(stops))
+	for i, stop := range stops {
+		points[i] = routing.Point{Lat: stop.Latitude, Lng: stop.Longitude}
+	}
+	return s.geofenceService.checkTrip(ctx,
+		routing.Point{Lat: pickupLat, Lng: pickupLng},
+		routing.Point{Lat: dropoffLat, Lng: dropoffLng},
+		points)
+}
+
+// priceRequest prices a requested route at its city's rates, discounting
+// pooled rides. Parcel deliveries are priced at the delivery rates instead.
+func (s *TripService) priceRequest(ctx context.Context, rates rateCard, pickupLat, pickupLng, dropoffLat, dropoffLng float64, stops []domain.TripStopRequest, pooled bool, parcel *parcelCategory) (routePrice, error) {
+	route, err := requestRoute(pickupLat, pickupLng, dropoffLat, dropoffLng, stops)
+	if err != nil {
+		return routePrice{}, err
+	}
+	if parcel != nil {
+		return priceDelivery(ctx, s.router, rates, route, *parcel)
+	}
+	price, err := priceRoute(ctx, s.router, rates, route)
+	if err != nil {
+		return routePrice{}, err
+	}
+	if pooled {
+		price.poolDiscount = s.poolService.discount(price.subtotal())
+	}
+	return price, nil
+}
+
+func (s *TripService) GetTripByID(ctx context.Context, tripID uuid.UUID) (*db.Trip, error) {
+	pgUUID := utils.ToPgUUID(tripID)
+
 	trip, err := s.tripRepo.GetTrip(ctx, pgUUID)
 	if err != nil {
 		return nil, errors.New("trip not found")
@@ -91,10 +403,7 @@ func (s *TripService) GetTripByID(ctx context.Context, tripID uuid.UUID) (*db.Tr
 }
 
 func (s *TripService) GetUserTrips(ctx context.Context, userID uuid.UUID) ([]db.Trip, error) {
-	var pgUUID pgtype.UUID
-	if err := pgUUID.Scan(userID); err != nil {
-		return nil, fmt.Errorf("invalid user ID: %w", err)
-	}
+	pgUUID := utils.ToPgUUID(userID)
 
 	return s.tripRepo.GetUserTrips(ctx, db.GetUserTripsParams{
 		UserID: pgUUID,
@@ -104,10 +413,7 @@ func (s *TripService) GetUserTrips(ctx context.Context, userID uuid.UUID) ([]db.
 }
 
 func (s *TripService) GetActiveTrip(ctx context.Context, userID uuid.UUID) (*db.Trip, error) {
-	var pgUUID pgtype.UUID
-	if err := pgUUID.Scan(userID); err != nil {
-		return nil, fmt.Errorf("invalid user ID: %w", err)
-	}
+	pgUUID := utils.ToPgUUID(userID)
 
 	trip, err := s.tripRepo.GetActiveTrip(ctx, pgUUID)
 	if err != nil {
@@ -116,70 +422,174 @@ func (s *TripService) GetActiveTrip(ctx context.Context, userID uuid.UUID) (*db.
 	return &trip, nil
 }
 
-func (s *TripService) CancelTrip(ctx context.Context, tripID uuid.UUID, reason string) error {
-	var pgUUID pgtype.UUID
-	if err := pgUUID.Scan(tripID); err != nil {
-		return fmt.Errorf("invalid trip ID: %w", err)
-	}
-
-	trip, err := s.tripRepo.GetTrip(ctx, pgUUID)
+// GetTripETA estimates when the driver will reach the pickup, or the
+// dropoff once the trip is under way, from their last known position.
+func (s *TripService) GetTripETA(ctx context.Context, tripID, callerID uuid.UUID, role string) (*domain.TripETAResponse, error) {
+	trip, err := s.tripRepo.GetTrip(ctx, utils.ToPgUUID(tripID))
 	if err != nil {
-		return errors.New("trip not found")
+		return nil, ErrTripNotFound
+	}
+	if !isTripParticipant(trip, callerID) && role != "admin" {
+		return nil, ErrNotTripParticipant
 	}
 
-	if trip.Status == domain.TripStatusCompleted || trip.Status == domain.TripStatusCancelled {
-		return errors.New("cannot cancel completed or already cancelled trip")
+	target := "pickup"
+	destination := routing.Point{Lat: utils.NumericToFloat64(trip.PickupLatitude), Lng: utils.NumericToFloat64(trip.PickupLongitude)}
+	switch trip.Status {
+	case domain.TripStatusAccepted:
+	case domain.TripStatusInProgress:
+		target = "dropoff"
+		destination = routing.Point{Lat: utils.NumericToFloat64(trip.DropoffLatitude), Lng: utils.NumericToFloat64(trip.DropoffLongitude)}
+	default:
+		return nil, ErrETAUnavailable
 	}
 
-	reasonPtr := pgtype.Text{String: reason, Valid: true}
+	location, err := s.tripRepo.GetDriverLocation(ctx, trip.DriverID)
+	if err != nil || !location.CurrentLatitude.Valid || !location.CurrentLongitude.Valid {
+		return nil, fmt.Errorf("%w: driver location unknown", ErrETAUnavailable)
+	}
 
-	err = s.tripRepo.CancelTrip(ctx, db.CancelTripParams{
-		ID:                 pgUUID,
-		CancellationReason: reasonPtr,
-	})
+	driverAt := routing.Point{
+		Lat: utils.NumericToFloat64(location.CurrentLatitude),
+		Lng: utils.NumericToFloat64(location.CurrentLongitude),
+	}
+	route, err := s.router.Route(ctx, driverAt, destination)
 	if err != nil {
-		return fmt.Errorf("failed to cancel trip: %w", err)
+		return nil, fmt.Errorf("failed to route driver: %w", err)
 	}
 
-	return nil
+	return &domain.TripETAResponse{
+		TripID:        tripID.String(),
+		Target:        target,
+		Distance:      route.Distance,
+		EtaMinutes:    route.Minutes(),
+		Polyline:      route.Polyline(),
+		DriverAddress: s.placeService.describe(ctx, driverAt),
+	}, nil
 }
 
-func (s *TripService) CompleteTrip(ctx context.Context, tripID uuid.UUID, actualFare float64, actualDuration int32, paymentStatus string) error {
-	var pgUUID pgtype.UUID
-	if err := pgUUID.Scan(tripID); err != nil {
-		return fmt.Errorf("invalid trip ID: %w", err)
-	}
+func (s *TripService) CompleteTrip(ctx context.Context, tripID, driverID uuid.UUID, actualFare, tip float64, actualDuration int32, paymentStatus string) error {
+	pgUUID := utils.ToPgUUID(tripID)
 
 	trip, err := s.tripRepo.GetTrip(ctx, pgUUID)
 	if err != nil {
 		return errors.New("trip not found")
 	}
 
+	if utils.FromPgUUID(trip.DriverID) != driverID {
+		return errors.New("unauthorized")
+	}
+
 	if trip.Status != domain.TripStatusInProgress {
 		return errors.New("only in-progress trips can be completed")
 	}
 
-	var fareNumeric pgtype.Numeric
-	fareNumeric.Scan(actualFare)
+	if tip < 0 {
+		return errors.New("tip cannot be negative")
+	}
+	if actualFare < 0 {
+		return fmt.Errorf("%w: fare cannot be negative", ErrInvalidFare)
+	}
+	currency := money.Currency(trip.Currency)
+	fare := s.capFare(trip, money.FromFloat(actualFare, currency))
+	tipAmount := money.FromFloat(tip, currency)
+
+	// Wallet trips are settled by payment-service, which reports back through
+	// payment.completed / payment.failed.
+	if trip.PaymentMethod.String == domain.PaymentMethodWallet {
+		paymentStatus = domain.PaymentStatusPending
+	}
+
 	durationPtr := pgtype.Int4{Int32: actualDuration, Valid: true}
 	paymentStatusPtr := pgtype.Text{String: paymentStatus, Valid: true}
 
-	err = s.tripRepo.CompleteTrip(ctx, db.CompleteTripParams{
-		ID:             pgUUID,
-		ActualFare:     fareNumeric,
-		ActualDuration: durationPtr,
-		PaymentStatus:  paymentStatusPtr,
+	// The promo discount is settled against the actual fare, and the rider's
+	// first completed trip qualifies their referral.
+	var discount money.Money
+	var referral *db.Referral
+	err = s.tripRepo.WithTx(ctx, func(q *db.Queries) error {
+		var err error
+		if discount, err = s.promotionService.redeemPromo(ctx, q, pgUUID, fare); err != nil {
+			return err
+		}
+
+		if err := s.deliveryService.requireDelivered(ctx, q, trip); err != nil {
+			return err
+		}
+
+		if err := q.CompleteTrip(ctx, db.CompleteTripParams{
+			ID:             pgUUID,
+			ActualFare:     fare.Numeric(),
+			ActualDuration: durationPtr,
+			PaymentStatus:  paymentStatusPtr,
+			Tip:            tipAmount.Numeric(),
+			DiscountAmount: discount.Numeric(),
+		}); err != nil {
+			return err
+		}
+		if err := s.poolService.dropOff(ctx, q, trip); err != nil {
+			return err
+		}
+
+		referral, err = s.promotionService.rewardReferral(ctx, q, trip.UserID, pgUUID)
+		return err
 	})
 	if err != nil {
 		return fmt.Errorf("failed to complete trip: %w", err)
 	}
 
+	// Publish trip completed event
+	now := time.Now()
+	s.eventBus.Publish(ctx, events.SubjectTripCompleted, events.TripCompletedEvent{
+		TripID:          tripID.String(),
+		DriverID:        driverID.String(),
+		UserID:          utils.FromPgUUID(trip.UserID).String(),
+		ActualFare:      fare.Float64(),
+		ActualDuration:  int(actualDuration),
+		Tip:             tipAmount.Float64(),
+		Discount:        discount.Float64(),
+		PaymentMethod:   trip.PaymentMethod.String,
+		PaymentStatus:   paymentStatus,
+		CityCode:        trip.CityCode.String,
+		Currency:        trip.Currency,
+		PickupLatitude:  utils.NumericToFloat64(trip.PickupLatitude),
+		PickupLongitude: utils.NumericToFloat64(trip.PickupLongitude),
+		CompletedAt:     now,
+		Timestamp:       now,
+	})
+
+	if referral != nil {
+		s.promotionService.publishReferralRewarded(ctx, referral)
+	}
+
 	return nil
 }
 
+// capFare holds the fare a driver reports to the trip's estimate plus the
+// allowed margin, so riders aren't charged for a mistyped or inflated fare.
+// Drivers report the fare before any promo discount, so it is held to the
+// subtotal; trips priced before subtotals were kept have no discount and
+// are held to their estimate.
+func (s *TripService) capFare(trip db.Trip, fare money.Money) money.Money {
+	gross := trip.SubtotalFare
+	if !gross.Valid {
+		gross = trip.EstimatedFare
+	}
+	if !gross.Valid {
+		return fare
+	}
+	estimate := money.FromNumeric(gross, fare.Currency())
+	limit := estimate.Add(estimate.Apply(s.fareMargin, money.HalfUp))
+	if fare.Cmp(limit) > 0 {
+		log.Printf("Capping fare %s for trip %s at %s", fare, utils.FromPgUUID(trip.ID), limit)
+		return limit
+	}
+	return fare
+}
+
 func (s *TripService) SubscribeToEvents() {
 	// Subscribe to trip accepted event from driver service
-	s.eventBus.Subscribe(events.SubjectTripAccepted, func(data []byte) {
+	s.eventBus.Subscribe(events.SubjectTripAccepted, func(ctx context.Context, data []byte) {
 		var event struct {
 			TripID   string `json:"trip_id"`
 			DriverID string `json:"driver_id"`
@@ -201,24 +611,38 @@ func (s *TripService) SubscribeToEvents() {
 			return
 		}
 
-		var tripPGUUID, driverPGUUID pgtype.UUID
-		tripPGUUID.Scan(tripID)
-		driverPGUUID.Scan(driverID)
+		trip, err := s.tripRepo.GetTrip(ctx, utils.ToPgUUID(tripID))
+		if err != nil {
+			log.Printf("Failed to get accepted trip %s: %v", event.TripID, err)
+			return
+		}
+		// Deliveries only go to drivers whose vehicle can carry the parcel,
+		// and trips only to drivers working in their city.
+		if err := checkVehicleEligibility(ctx, s.tripRepo, trip, utils.ToPgUUID(driverID)); err != nil {
+			log.Printf("Driver %s can't take trip %s: %v", event.DriverID, event.TripID, err)
+			return
+		}
+		if err := s.cityService.checkDriver(ctx, trip, driverID); err != nil {
+			log.Printf("Driver %s can't take trip %s: %v", event.DriverID, event.TripID, err)
+			return
+		}
 
-		ctx := context.Background()
 		if err := s.tripRepo.AssignDriverToTrip(ctx, db.AssignDriverToTripParams{
-			ID:       tripPGUUID,
-			DriverID: driverPGUUID,
+			ID:       utils.ToPgUUID(tripID),
+			DriverID: utils.ToPgUUID(driverID),
 		}); err != nil {
 			log.Printf("Failed to assign driver to trip: %v", err)
 			return
 		}
+		if err := s.poolService.HandleTripAccepted(ctx, tripID, driverID); err != nil {
+			log.Printf("Failed to assign driver to pool: %v", err)
+		}
 
 		log.Printf("Trip %s assigned to driver %s", event.TripID, event.DriverID)
 	})
 
 	// Subscribe to trip started event
-	s.eventBus.Subscribe(events.SubjectTripStarted, func(data []byte) {
+	s.eventBus.Subscribe(events.SubjectTripStarted, func(ctx context.Context, data []byte) {
 		var event struct {
 			TripID string `json:"trip_id"`
 		}
@@ -233,35 +657,78 @@ func (s *TripService) SubscribeToEvents() {
 			return
 		}
 
-		var tripPGUUID pgtype.UUID
-		tripPGUUID.Scan(tripID)
-
-		ctx := context.Background()
 		if err := s.tripRepo.UpdateTripStatus(ctx, db.UpdateTripStatusParams{
-			ID:     tripPGUUID,
+			ID:     utils.ToPgUUID(tripID),
 			Status: domain.TripStatusInProgress,
 		}); err != nil {
 			log.Printf("Failed to update trip status: %v", err)
 			return
 		}
+		if err := s.poolService.HandleTripStarted(ctx, tripID); err != nil {
+			log.Printf("Failed to mark pool pickup: %v", err)
+		}
 
 		log.Printf("Trip %s started", event.TripID)
 	})
+
+	// Subscribe to wallet payment outcomes from payment service
+	s.eventBus.Subscribe(events.SubjectPaymentCompleted, func(ctx context.Context, data []byte) {
+		s.handlePaymentEvent(ctx, data, domain.PaymentStatusPaid)
+	})
+	s.eventBus.Subscribe(events.SubjectPaymentFailed, func(ctx context.Context, data []byte) {
+		s.handlePaymentEvent(ctx, data, domain.PaymentStatusFailed)
+	})
+}
+
+func (s *TripService) handlePaymentEvent(ctx context.Context, data []byte, paymentStatus string) {
+	var event struct {
+		TripID string `json:"trip_id"`
+	}
+	if err := json.Unmarshal(data, &event); err != nil {
+		log.Printf("Failed to unmarshal payment event: %v", err)
+		return
+	}
+
+	tripID, err := uuid.Parse(event.TripID)
+	if err != nil {
+		log.Printf("Invalid trip ID in event: %v", err)
+		return
+	}
+
+	if err := s.tripRepo.UpdateTripPaymentStatus(ctx, db.UpdateTripPaymentStatusParams{
+		ID:            utils.ToPgUUID(tripID),
+		PaymentStatus: pgtype.Text{String: paymentStatus, Valid: true},
+	}); err != nil {
+		log.Printf("Failed to update trip payment status: %v", err)
+		return
+	}
+
+	log.Printf("Trip %s payment %s", event.TripID, paymentStatus)
 }
 
 func (s *TripService) validateCreateTripRequest(req *domain.CreateTripRequest) error {
-	if req.PickupLatitude == 0 || req.PickupLongitude == 0 {
-		return errors.New("pickup location is required")
+	if err := validateLocations(req.PickupLatitude, req.PickupLongitude, req.DropoffLatitude, req.DropoffLongitude); err != nil {
+		return err
 	}
-	if req.DropoffLatitude == 0 || req.DropoffLongitude == 0 {
-		return errors.New("dropoff location is required")
+	switch req.PaymentMethod {
+	case "", domain.PaymentMethodCash, domain.PaymentMethodCard, domain.PaymentMethodWallet:
+	default:
+		return errors.New("invalid payment method")
+	}
+	switch req.TripType {
+	case "", domain.TripTypeRide, domain.TripTypeDelivery:
+	default:
+		return errors.New("invalid trip type")
 	}
 	return nil
 }
 
-func (s *TripService) calculateFare(distance float64) float64 {
-	// Simple fare calculation: base fare + per km rate
-	baseFare := 50.0  // Base fare
-	perKmRate := 20.0 // Rate per kilometer
-	return baseFare + (distance * perKmRate)
+func validateLocations(pickupLat, pickupLng, dropoffLat, dropoffLng float64) error {
+	if pickupLat == 0 || pickupLng == 0 {
+		return errors.New("pickup location is required")
+	}
+	if dropoffLat == 0 || dropoffLng == 0 {
+		return errors.New("dropoff location is required")
+	}
+	return nil
 }
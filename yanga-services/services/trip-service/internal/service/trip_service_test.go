@@ -0,0 +1,204 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/namycodes/yanga-services/services/trip-service/internal/db"
+	"github.com/namycodes/yanga-services/services/trip-service/internal/repository"
+	"github.com/namycodes/yanga-services/shared-lib/domain"
+	"github.com/namycodes/yanga-services/shared-lib/events"
+	"github.com/namycodes/yanga-services/shared-lib/money"
+	"github.com/namycodes/yanga-services/shared-lib/utils"
+)
+
+// fakeDB stands in for the pool and its transactions. It answers sqlc
+// queries by name with canned rows, and queries it has no row for with
+// pgx.ErrNoRows.
+type fakeDB struct {
+	pgx.Tx
+	rows  map[string]any
+	execs map[string][]any
+}
+
+func newFakeDB(rows map[string]any) *fakeDB {
+	return &fakeDB{rows: rows, execs: make(map[string][]any)}
+}
+
+// queryName reads the name sqlc puts at the top of every query.
+func queryName(sql string) string {
+	fields := strings.Fields(strings.TrimPrefix(sql, "-- name:"))
+	if len(fields) == 0 {
+		return ""
+	}
+	return fields[0]
+}
+
+func (f *fakeDB) Begin(ctx context.Context) (pgx.Tx, error) {
+	return f, nil
+}
+
+func (f *fakeDB) Commit(ctx context.Context) error {
+	return nil
+}
+
+func (f *fakeDB) Rollback(ctx context.Context) error {
+	return nil
+}
+
+func (f *fakeDB) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	f.execs[queryName(sql)] = args
+	return pgconn.NewCommandTag("UPDATE 1"), nil
+}
+
+func (f *fakeDB) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	return nil, fmt.Errorf("unexpected query %s", queryName(sql))
+}
+
+func (f *fakeDB) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	row, ok := f.rows[queryName(sql)]
+	if !ok {
+		return fakeRow{err: pgx.ErrNoRows}
+	}
+	return fakeRow{value: reflect.ValueOf(row)}
+}
+
+// fakeRow scans a sqlc model's fields, in order, into the query's columns.
+type fakeRow struct {
+	value reflect.Value
+	err   error
+}
+
+func (r fakeRow) Scan(dest ...any) error {
+	if r.err != nil {
+		return r.err
+	}
+	if r.value.NumField() != len(dest) {
+		return fmt.Errorf("scanning %d columns from a %s", len(dest), r.value.Type())
+	}
+	for i, d := range dest {
+		reflect.ValueOf(d).Elem().Set(r.value.Field(i))
+	}
+	return nil
+}
+
+// fakeEventBus keeps what is published.
+type fakeEventBus struct {
+	events.EventBus
+	published map[string]any
+}
+
+func (b *fakeEventBus) Publish(ctx context.Context, subject string, data interface{}) error {
+	b.published[subject] = data
+	return nil
+}
+
+func TestCompleteTripFare(t *testing.T) {
+	const currency = money.Currency("KES")
+	kes := func(major float64) pgtype.Numeric {
+		return money.FromFloat(major, currency).Numeric()
+	}
+
+	tripID, driverID, promoID := uuid.New(), uuid.New(), uuid.New()
+	trip := db.Trip{
+		ID:            utils.ToPgUUID(tripID),
+		UserID:        utils.ToPgUUID(uuid.New()),
+		DriverID:      utils.ToPgUUID(driverID),
+		Status:        domain.TripStatusInProgress,
+		PaymentMethod: pgtype.Text{String: domain.PaymentMethodCash, Valid: true},
+		TripType:      domain.TripTypeRide,
+		Currency:      string(currency),
+	}
+	promo := db.PromoCode{
+		ID:            utils.ToPgUUID(promoID),
+		DiscountType:  domain.DiscountTypePercentage,
+		DiscountValue: money.Percent(20).PercentNumeric(),
+		Currency:      string(currency),
+	}
+	redemption := db.PromoRedemption{
+		PromoCodeID: promo.ID,
+		TripID:      trip.ID,
+		Status:      redemptionStatusReserved,
+	}
+
+	// Fares are held to the subtotal plus 20%. Trips with a subtotal of 0
+	// were priced before subtotals were kept.
+	tests := []struct {
+		name         string
+		subtotal     float64
+		estimate     float64
+		promo        bool
+		actualFare   float64
+		wantFare     float64
+		wantDiscount float64
+		wantErr      error
+	}{
+		{"promo trip at its subtotal", 500, 400, true, 500, 500, 100, nil},
+		{"promo trip within the margin", 500, 400, true, 580, 580, 116, nil},
+		{"promo trip over the margin", 500, 400, true, 700, 600, 120, nil},
+		{"trip without promo", 500, 500, false, 550, 550, 0, nil},
+		{"trip without subtotal", 0, 500, false, 650, 600, 0, nil},
+		{"negative fare", 500, 500, false, -1, 0, 0, ErrInvalidFare},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trip := trip
+			if tt.subtotal > 0 {
+				trip.SubtotalFare = kes(tt.subtotal)
+			}
+			trip.EstimatedFare = kes(tt.estimate)
+			rows := map[string]any{"GetTrip": trip}
+			if tt.promo {
+				rows["GetPromoRedemptionByTrip"] = redemption
+				rows["GetPromoCode"] = promo
+				rows["RedeemPromoRedemption"] = redemption
+			}
+			fake := newFakeDB(rows)
+			bus := &fakeEventBus{published: make(map[string]any)}
+			tripRepo := repository.NewTripRepository(fake, db.New(fake))
+			s := &TripService{
+				tripRepo:         tripRepo,
+				promotionService: &PromotionService{tripRepo: tripRepo, eventBus: bus, currency: currency},
+				poolService:      &PoolService{tripRepo: tripRepo, eventBus: bus},
+				deliveryService:  &DeliveryService{tripRepo: tripRepo, eventBus: bus},
+				eventBus:         bus,
+				fareMargin:       money.Percent(20),
+			}
+
+			err := s.CompleteTrip(context.Background(), tripID, driverID, tt.actualFare, 0, 30, domain.PaymentStatusPaid)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got error %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+
+			args := fake.execs["CompleteTrip"]
+			if args == nil {
+				t.Fatal("trip not completed")
+			}
+			fare := money.FromNumeric(args[1].(pgtype.Numeric), currency)
+			discount := money.FromNumeric(args[5].(pgtype.Numeric), currency)
+			if fare.Float64() != tt.wantFare || discount.Float64() != tt.wantDiscount {
+				t.Errorf("got fare %s and discount %s, want %.2f and %.2f", fare, discount, tt.wantFare, tt.wantDiscount)
+			}
+
+			event, ok := bus.published[events.SubjectTripCompleted].(events.TripCompletedEvent)
+			if !ok {
+				t.Fatal("trip completed event not published")
+			}
+			if event.ActualFare != tt.wantFare || event.Discount != tt.wantDiscount {
+				t.Errorf("published fare %v and discount %v, want %v and %v", event.ActualFare, event.Discount, tt.wantFare, tt.wantDiscount)
+			}
+		})
+	}
+}
@@ -0,0 +1,381 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/namycodes/yanga-services/services/trip-service/internal/db"
+	"github.com/namycodes/yanga-services/services/trip-service/internal/repository"
+	"github.com/namycodes/yanga-services/shared-lib/domain"
+	"github.com/namycodes/yanga-services/shared-lib/events"
+	"github.com/namycodes/yanga-services/shared-lib/money"
+	"github.com/namycodes/yanga-services/shared-lib/routing"
+	"github.com/namycodes/yanga-services/shared-lib/utils"
+)
+
+var (
+	ErrTripStopNotFound    = errors.New("trip stop not found")
+	ErrInvalidStopPosition = errors.New("invalid stop position")
+	ErrStopAlreadyArrived  = errors.New("stop has already been reached")
+	ErrTripRouteLocked     = errors.New("stops can no longer be changed on this trip")
+	ErrTripNotInProgress   = errors.New("trip is not in progress")
+	ErrStopOutOfOrder      = errors.New("earlier stops have not been reached yet")
+)
+
+type TripStopService struct {
+	tripRepo         *repository.TripRepository
+	promotionService *PromotionService
+	geofenceService  *GeofenceService
+	cityService      *CityService
+	router           routing.Router
+	eventBus         events.EventBus
+}
+
+func NewTripStopService(tripRepo *repository.TripRepository, promotionService *PromotionService, geofenceService *GeofenceService, cityService *CityService, router routing.Router, eventBus events.EventBus) *TripStopService {
+	return &TripStopService{
+		tripRepo:         tripRepo,
+		promotionService: promotionService,
+		geofenceService:  geofenceService,
+		cityService:      cityService,
+		router:           router,
+		eventBus:         eventBus,
+	}
+}
+
+// GetRoute returns a trip's stops and current pricing. Only the rider, the
+// assigned driver and admins can see it.
+func (s *TripStopService) GetRoute(ctx context.Context, tripID, callerID uuid.UUID, role string) (*domain.TripRouteResponse, error) {
+	trip, err := s.getTrip(ctx, tripID)
+	if err != nil {
+		return nil, err
+	}
+	if !isTripParticipant(trip, callerID) && role != "admin" {
+		return nil, ErrNotTripParticipant
+	}
+
+	stops, err := s.tripRepo.GetTripStops(ctx, trip.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trip stops: %w", err)
+	}
+	return toTripRouteResponse(trip, stops), nil
+}
+
+// AddStop inserts a stop into the rider's trip and re-prices it. Stops can
+// be added until the trip is completed, but never ahead of a stop the driver
+// has already reached.
+func (s *TripStopService) AddStop(ctx context.Context, tripID, userID uuid.UUID, req *domain.AddTripStopRequest) (*domain.TripRouteResponse, error) {
+	if err := validateStop(req.Latitude, req.Longitude, req.Address); err != nil {
+		return nil, err
+	}
+	if err := s.geofenceService.checkStop(ctx, routing.Point{Lat: req.Latitude, Lng: req.Longitude}); err != nil {
+		return nil, err
+	}
+
+	var (
+		trip  db.Trip
+		stops []db.TripStop
+	)
+	err := s.tripRepo.WithTx(ctx, func(q *db.Queries) error {
+		var err error
+		if trip, stops, err = s.loadEditableRoute(ctx, q, tripID, userID); err != nil {
+			return err
+		}
+		if len(stops) >= maxTripStops {
+			return ErrTooManyStops
+		}
+		city, err := s.cityService.lookup(ctx, trip)
+		if err != nil {
+			return err
+		}
+		if err := city.checkFeatures(false, false, false, len(stops)+1); err != nil {
+			return err
+		}
+
+		// A new stop can't go before one the driver has already passed.
+		minPosition := 1
+		for _, stop := range stops {
+			if stop.ArrivedAt.Valid {
+				minPosition = int(stop.StopOrder) + 1
+			}
+		}
+		position := req.Position
+		if position == 0 {
+			position = len(stops) + 1
+		}
+		if position < minPosition || position > len(stops)+1 {
+			return fmt.Errorf("%w: must be between %d and %d", ErrInvalidStopPosition, minPosition, len(stops)+1)
+		}
+
+		if err := q.ShiftTripStopsBack(ctx, db.ShiftTripStopsBackParams{
+			TripID:    trip.ID,
+			StopOrder: int32(position),
+		}); err != nil {
+			return fmt.Errorf("failed to reorder trip stops: %w", err)
+		}
+		if _, err := q.CreateTripStop(ctx, db.CreateTripStopParams{
+			TripID:    trip.ID,
+			StopOrder: int32(position),
+			Latitude:  utils.Float64ToNumeric(req.Latitude),
+			Longitude: utils.Float64ToNumeric(req.Longitude),
+			Address:   req.Address,
+		}); err != nil {
+			return fmt.Errorf("failed to create trip stop: %w", err)
+		}
+
+		trip, stops, err = s.reprice(ctx, q, trip)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.publishRouteUpdated(ctx, trip, stops)
+	return toTripRouteResponse(trip, stops), nil
+}
+
+// RemoveStop drops a stop the driver has not reached yet and re-prices the
+// trip.
+func (s *TripStopService) RemoveStop(ctx context.Context, tripID, stopID, userID uuid.UUID) (*domain.TripRouteResponse, error) {
+	var (
+		trip  db.Trip
+		stops []db.TripStop
+	)
+	err := s.tripRepo.WithTx(ctx, func(q *db.Queries) error {
+		var err error
+		if trip, _, err = s.loadEditableRoute(ctx, q, tripID, userID); err != nil {
+			return err
+		}
+
+		stop, err := q.GetTripStop(ctx, db.GetTripStopParams{
+			ID:     utils.ToPgUUID(stopID),
+			TripID: trip.ID,
+		})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrTripStopNotFound
+			}
+			return fmt.Errorf("failed to get trip stop: %w", err)
+		}
+
+		deleted, err := q.DeleteTripStop(ctx, db.DeleteTripStopParams{
+			ID:     stop.ID,
+			TripID: trip.ID,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to delete trip stop: %w", err)
+		}
+		if deleted == 0 {
+			return ErrStopAlreadyArrived
+		}
+		if err := q.ShiftTripStopsForward(ctx, db.ShiftTripStopsForwardParams{
+			TripID:    trip.ID,
+			StopOrder: stop.StopOrder,
+		}); err != nil {
+			return fmt.Errorf("failed to reorder trip stops: %w", err)
+		}
+
+		trip, stops, err = s.reprice(ctx, q, trip)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.publishRouteUpdated(ctx, trip, stops)
+	return toTripRouteResponse(trip, stops), nil
+}
+
+// MarkStopArrived records that the assigned driver reached a stop. Stops
+// must be reached in order.
+func (s *TripStopService) MarkStopArrived(ctx context.Context, tripID, stopID, driverID uuid.UUID) error {
+	trip, err := s.getTrip(ctx, tripID)
+	if err != nil {
+		return err
+	}
+	if !trip.DriverID.Valid || utils.FromPgUUID(trip.DriverID) != driverID {
+		return ErrNotTripParticipant
+	}
+	if trip.Status != domain.TripStatusInProgress {
+		return ErrTripNotInProgress
+	}
+
+	stops, err := s.tripRepo.GetTripStops(ctx, trip.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get trip stops: %w", err)
+	}
+
+	var stop *db.TripStop
+	for i := range stops {
+		if utils.FromPgUUID(stops[i].ID) == stopID {
+			stop = &stops[i]
+			break
+		}
+		if !stops[i].ArrivedAt.Valid {
+			return ErrStopOutOfOrder
+		}
+	}
+	if stop == nil {
+		return ErrTripStopNotFound
+	}
+
+	updated, err := s.tripRepo.MarkTripStopArrived(ctx, db.MarkTripStopArrivedParams{
+		ID:     stop.ID,
+		TripID: trip.ID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to mark stop arrived: %w", err)
+	}
+	if updated == 0 {
+		return ErrStopAlreadyArrived
+	}
+
+	s.eventBus.Publish(ctx, events.SubjectTripStopArrived, events.TripStopArrivedEvent{
+		TripID:    tripID.String(),
+		UserID:    utils.FromPgUUID(trip.UserID).String(),
+		DriverID:  driverID.String(),
+		StopID:    stopID.String(),
+		StopOrder: int(stop.StopOrder),
+		Address:   stop.Address,
+		Timestamp: time.Now(),
+	})
+	return nil
+}
+
+// loadEditableRoute loads a rider's trip and its stops inside a
+// transaction, checking the route can still be changed.
+func (s *TripStopService) loadEditableRoute(ctx context.Context, q *db.Queries, tripID, userID uuid.UUID) (db.Trip, []db.TripStop, error) {
+	trip, err := q.GetTrip(ctx, utils.ToPgUUID(tripID))
+	if err != nil {
+		return db.Trip{}, nil, ErrTripNotFound
+	}
+	if utils.FromPgUUID(trip.UserID) != userID {
+		return db.Trip{}, nil, ErrNotTripParticipant
+	}
+	if trip.PoolID.Valid {
+		return db.Trip{}, nil, fmt.Errorf("%w: pooled rides can't have stops", ErrTripRouteLocked)
+	}
+	if trip.TripType == domain.TripTypeDelivery {
+		return db.Trip{}, nil, fmt.Errorf("%w: deliveries can't have stops", ErrTripRouteLocked)
+	}
+
+	switch trip.Status {
+	case domain.TripStatusScheduled, domain.TripStatusPending, domain.TripStatusAccepted, domain.TripStatusInProgress:
+	default:
+		return db.Trip{}, nil, ErrTripRouteLocked
+	}
+
+	stops, err := q.GetTripStops(ctx, trip.ID)
+	if err != nil {
+		return db.Trip{}, nil, fmt.Errorf("failed to get trip stops: %w", err)
+	}
+	return trip, stops, nil
+}
+
+// reprice recalculates a trip's distance, duration and fare from its current
+// stops at its city's rates. A reserved promo is re-applied to the new
+// subtotal.
+func (s *TripStopService) reprice(ctx context.Context, q *db.Queries, trip db.Trip) (db.Trip, []db.TripStop, error) {
+	stops, err := q.GetTripStops(ctx, trip.ID)
+	if err != nil {
+		return db.Trip{}, nil, fmt.Errorf("failed to get trip stops: %w", err)
+	}
+	city, err := s.cityService.lookup(ctx, trip)
+	if err != nil {
+		return db.Trip{}, nil, err
+	}
+
+	price, err := priceRoute(ctx, s.router, city.rates, tripRoute(trip, stops))
+	if err != nil {
+		return db.Trip{}, nil, err
+	}
+	// Pickup and dropoff don't move, so neither do their airport fees.
+	price.zoneFee = money.FromNumeric(trip.ZoneFee, city.currency)
+	subtotal := price.subtotal()
+	discount, err := s.promotionService.estimateDiscount(ctx, q, trip.ID, subtotal)
+	if err != nil {
+		return db.Trip{}, nil, err
+	}
+
+	params := db.UpdateTripRouteParams{
+		ID:                trip.ID,
+		Distance:          utils.Float64ToNumeric(price.distance),
+		EstimatedDuration: pgtype.Int4{Int32: int32(price.duration), Valid: true},
+		SubtotalFare:      subtotal.Numeric(),
+		DiscountAmount:    discount.Numeric(),
+		EstimatedFare:     subtotal.Sub(discount).Numeric(),
+	}
+	if err := q.UpdateTripRoute(ctx, params); err != nil {
+		return db.Trip{}, nil, fmt.Errorf("failed to update trip route: %w", err)
+	}
+
+	trip.Distance = params.Distance
+	trip.EstimatedDuration = params.EstimatedDuration
+	trip.SubtotalFare = params.SubtotalFare
+	trip.DiscountAmount = params.DiscountAmount
+	trip.EstimatedFare = params.EstimatedFare
+	return trip, stops, nil
+}
+
+func (s *TripStopService) publishRouteUpdated(ctx context.Context, trip db.Trip, stops []db.TripStop) {
+	event := events.TripRouteUpdatedEvent{
+		TripID:            utils.FromPgUUID(trip.ID).String(),
+		UserID:            utils.FromPgUUID(trip.UserID).String(),
+		StopCount:         len(stops),
+		Distance:          utils.NumericToFloat64(trip.Distance),
+		EstimatedDuration: int(trip.EstimatedDuration.Int32),
+		EstimatedFare:     money.FromNumeric(trip.EstimatedFare, money.Currency(trip.Currency)).Float64(),
+		Timestamp:         time.Now(),
+	}
+	if trip.DriverID.Valid {
+		event.DriverID = utils.FromPgUUID(trip.DriverID).String()
+	}
+	s.eventBus.Publish(ctx, events.SubjectTripRouteUpdated, event)
+}
+
+func (s *TripStopService) getTrip(ctx context.Context, tripID uuid.UUID) (db.Trip, error) {
+	trip, err := s.tripRepo.GetTrip(ctx, utils.ToPgUUID(tripID))
+	if err != nil {
+		return db.Trip{}, ErrTripNotFound
+	}
+	return trip, nil
+}
+
+func isTripParticipant(trip db.Trip, userID uuid.UUID) bool {
+	if utils.FromPgUUID(trip.UserID) == userID {
+		return true
+	}
+	return trip.DriverID.Valid && utils.FromPgUUID(trip.DriverID) == userID
+}
+
+func toTripRouteResponse(trip db.Trip, stops []db.TripStop) *domain.TripRouteResponse {
+	currency := money.Currency(trip.Currency)
+	resp := &domain.TripRouteResponse{
+		TripID:            utils.FromPgUUID(trip.ID).String(),
+		Stops:             make([]domain.TripStopResponse, 0, len(stops)),
+		Distance:          utils.NumericToFloat64(trip.Distance),
+		EstimatedDuration: int(trip.EstimatedDuration.Int32),
+		Subtotal:          money.FromNumeric(trip.SubtotalFare, currency).Float64(),
+		Discount:          money.FromNumeric(trip.DiscountAmount, currency).Float64(),
+		EstimatedFare:     money.FromNumeric(trip.EstimatedFare, currency).Float64(),
+	}
+	for _, stop := range stops {
+		item := domain.TripStopResponse{
+			ID:        utils.FromPgUUID(stop.ID).String(),
+			StopOrder: int(stop.StopOrder),
+			Latitude:  utils.NumericToFloat64(stop.Latitude),
+			Longitude: utils.NumericToFloat64(stop.Longitude),
+			Address:   stop.Address,
+		}
+		if stop.ArrivedAt.Valid {
+			arrivedAt := stop.ArrivedAt.Time
+			item.ArrivedAt = &arrivedAt
+		}
+		resp.Stops = append(resp.Stops, item)
+	}
+	return resp
+}
@@ -14,7 +14,7 @@ NC='\033[0m' # No Color
 
 # Step 1: Copy environment files
 echo -e "${BLUE}📝 Step 1: Setting up environment files...${NC}"
-for service in services/auth-service services/trip-service services/driver-service services/rating-service api-gateway; do
+for service in services/auth-service services/trip-service services/driver-service services/rating-service services/payment-service api-gateway; do
     if [ ! -f "$service/.env" ]; then
         cp "$service/.env.example" "$service/.env"
         echo -e "${GREEN}✅ Created $service/.env${NC}"
@@ -26,7 +26,7 @@ done
 # Step 2: Install Go dependencies
 echo -e "\n${BLUE}📦 Step 2: Installing Go dependencies...${NC}"
 cd shared-lib && go mod tidy && cd ..
-for service in services/auth-service services/trip-service services/driver-service services/rating-service api-gateway; do
+for service in services/auth-service services/trip-service services/driver-service services/rating-service services/payment-service api-gateway; do
     echo "Installing dependencies for $service..."
     cd "$service" && go mod tidy && cd ../..
 done
@@ -49,7 +49,7 @@ echo -e "${GREEN}✅ Database initialized${NC}"
 
 # Step 5: Generate sqlc code
 echo -e "\n${BLUE}⚙️  Step 5: Generating sqlc code...${NC}"
-for service in services/auth-service services/trip-service services/driver-service services/rating-service; do
+for service in services/auth-service services/trip-service services/driver-service services/rating-service services/payment-service; do
     echo "Generating sqlc for $service..."
     cd "$service" && sqlc generate && cd ../..
 done
@@ -62,7 +62,8 @@ go build -o bin/auth-service services/auth-service/cmd/main.go
 go build -o bin/trip-service services/trip-service/cmd/main.go
 go build -o bin/driver-service services/driver-service/cmd/main.go
 go build -o bin/rating-service services/rating-service/cmd/main.go
-go build -o bin/api-gateway api-gateway/cmd/main.go
+go build -o bin/payment-service services/payment-service/cmd/main.go
+(cd api-gateway && go build -o ../bin/api-gateway ./cmd)
 echo -e "${GREEN}✅ All services built${NC}"
 
 echo -e "\n${GREEN}✨ Setup complete!${NC}"
@@ -73,6 +74,9 @@ echo -e "  ${YELLOW}make run-auth${NC}      # Auth Service (8081)"
 echo -e "  ${YELLOW}make run-trip${NC}      # Trip Service (8082)"
 echo -e "  ${YELLOW}make run-driver${NC}    # Driver Service (8083)"
 echo -e "  ${YELLOW}make run-rating${NC}    # Rating Service (8084)"
+echo -e "  ${YELLOW}make run-payment${NC}   # Payment Service (8085)"
+echo -e "  ${YELLOW}make run-notification${NC} # Notification Service (8086)"
+echo -e "  ${YELLOW}make run-support${NC}   # Support Service (8087)"
 echo -e "  ${YELLOW}make run-gateway${NC}   # API Gateway (8080)"
 echo -e "\n${BLUE}Access the API:${NC}"
 echo -e "  ${YELLOW}http://localhost:8080${NC}           # API Gateway"
@@ -21,7 +21,114 @@ type Config struct {
 	TwilioSID      string
 	TwilioToken    string
 	TwilioPhone    string
-	Service        ServiceConfig
+	// Referral rewards in whole currency units, credited once the referee
+	// completes their first trip
+	ReferrerReward int
+	RefereeReward  int
+	// Scheduled rides: how far ahead a ride may be booked, and how long
+	// before pickup the rider is reminded and the trip is dispatched
+	ScheduleMinLeadMinutes  int
+	ScheduleMaxLeadHours    int
+	ScheduleDispatchMinutes int
+	ScheduleReminderMinutes int
+	// Pooled rides: riders per vehicle, how far a rider's ride may grow to
+	// pick up others, how close a pool must pass to the pickup, and the
+	// discount on the solo fare
+	PoolSeatCapacity      int
+	PoolMaxDetourPercent  int
+	PoolMatchRadiusMeters int
+	PoolDiscountPercent   int
+	// How far over its estimate, before any promo discount, the fare a
+	// driver reports at completion may go; higher fares are capped there
+	FareMaxOverEstimatePercent int
+	// Routing: an OpenStreetMap XML extract to route over, the speed used
+	// for straight-line estimates when there is none, and how far a point
+	// may be from the nearest road to snap onto it
+	RoutingGraphPath        string
+	RoutingFallbackSpeedKmh int
+	RoutingMaxSnapMeters    int
+	// Geocoding: a CSV gazetteer of known places, how close a point must be
+	// to one for a reverse lookup to name it, and how far an address may
+	// geocode from the coordinates sent with it (0 turns the check off)
+	GeocodingGazetteerPath          string
+	GeocodingReverseRadiusMeters    int
+	GeocodingAddressToleranceMeters int
+	// How many drivers at the head of an airport queue an airport pickup is
+	// offered to, one after another
+	AirportQueueOfferDepth int
+	// How many of a driver's latest ratings make up the rating shown on
+	// their profile
+	DriverRatingWindow int
+	// How long after a trip completes its rider and driver may rate each
+	// other
+	RatingWindowHours int
+	// Words that get rating feedback and other user-written text flagged,
+	// one per line; empty uses a built-in list
+	ModerationWordListPath string
+	// Driver quality: days of activity scored, trips a driver needs in
+	// that time before the score is acted on, and the scores (0-100) below
+	// which a driver is warned, suspended from dispatch for
+	// QualitySuspensionHours, or deactivated pending admin review
+	QualityWindowDays           int
+	QualityMinTrips             int
+	QualityWarningScore         int
+	QualitySuspensionScore      int
+	QualityReviewScore          int
+	QualitySuspensionHours      int
+	QualityCheckIntervalMinutes int
+	// Notifications: locale and timezone for users who haven't chosen one,
+	// how many times and how often a failed push or SMS is retried (the
+	// wait doubles after each attempt), and how many drivers within
+	// NotificationOfferRadiusMeters of a pickup are told about a new ride
+	// request
+	NotificationDefaultLocale     string
+	NotificationDefaultTimezone   string
+	NotificationMaxAttempts       int
+	NotificationRetrySeconds      int
+	NotificationOfferRadiusMeters int
+	NotificationOfferLimit        int
+	// Masked calling: the proxy number riders and drivers call each other
+	// through (empty disables calling), and how long a call session stays
+	// open before the app has to ask for it again
+	CallProxyNumber    string
+	CallSessionMinutes int
+	// Safety: where share links point (the token is appended), how long
+	// they last, and when a trip under way is flagged to the safety team:
+	// the driver further than SafetyRouteDeviationMeters from the planned
+	// route, stopped for SafetyLongStopMinutes, or the trip running
+	// SafetyOverETAPercent over its estimate
+	SafetyShareBaseURL         string
+	SafetyShareLinkHours       int
+	SafetyRouteDeviationMeters int
+	SafetyLongStopMinutes      int
+	SafetyOverETAPercent       int
+	SafetyCheckIntervalSeconds int
+	// Support: the directory ticket attachments are stored under, the
+	// largest attachment accepted, and how often tickets are checked for
+	// missed first response and resolution deadlines
+	BlobStoreDir                   string
+	SupportAttachmentMaxMB         int
+	SupportSLACheckIntervalSeconds int
+	// Signs the identity headers the gateway adds once it has validated a
+	// request's token; the gateway and every service must share it
+	GatewayIdentitySecret string
+	// Rate limiting at the gateway: the Redis its counters are shared in
+	// (empty, or Redis being unreachable, keeps them in memory), a JSON
+	// file of per-route policies (empty uses built-in ones), and whether
+	// the client's IP is taken from X-Forwarded-For, for a gateway behind
+	// a load balancer
+	RedisAddr                  string
+	RedisPassword              string
+	RedisDB                    int
+	RateLimitPolicyPath        string
+	RateLimitTrustForwardedFor bool
+	// The city trips belong to when their pickup isn't inside any city's
+	// service area, such as before any service areas are drawn
+	DefaultCityCode string
+	// Currency of referral rewards, and of wallets and earnings where no
+	// other currency is given
+	DefaultCurrency string
+	Service         ServiceConfig
 }
 
 type ServiceConfig struct {
@@ -30,7 +137,8 @@ type ServiceConfig struct {
 }
 
 type JWTConfig struct {
-	Secret string
+	Secret         string
+	IdentitySecret string
 }
 
 func Load() *Config {
@@ -49,6 +157,75 @@ func Load() *Config {
 		TwilioSID:      getEnv("TWILIO_ACCOUNT_SID", ""),
 		TwilioToken:    getEnv("TWILIO_AUTH_TOKEN", ""),
 		TwilioPhone:    getEnv("TWILIO_PHONE_NUMBER", ""),
+		ReferrerReward: getEnvAsInt("REFERRAL_REFERRER_REWARD", 200),
+		RefereeReward:  getEnvAsInt("REFERRAL_REFEREE_REWARD", 200),
+
+		ScheduleMinLeadMinutes:  getEnvAsInt("SCHEDULED_RIDE_MIN_LEAD_MINUTES", 30),
+		ScheduleMaxLeadHours:    getEnvAsInt("SCHEDULED_RIDE_MAX_LEAD_HOURS", 168),
+		ScheduleDispatchMinutes: getEnvAsInt("SCHEDULED_RIDE_DISPATCH_MINUTES", 15),
+		ScheduleReminderMinutes: getEnvAsInt("SCHEDULED_RIDE_REMINDER_MINUTES", 60),
+
+		PoolSeatCapacity:      getEnvAsInt("POOL_SEAT_CAPACITY", 3),
+		PoolMaxDetourPercent:  getEnvAsInt("POOL_MAX_DETOUR_PERCENT", 40),
+		PoolMatchRadiusMeters: getEnvAsInt("POOL_MATCH_RADIUS_METERS", 2000),
+		PoolDiscountPercent:   getEnvAsInt("POOL_DISCOUNT_PERCENT", 25),
+
+		FareMaxOverEstimatePercent: getEnvAsInt("FARE_MAX_OVER_ESTIMATE_PERCENT", 50),
+
+		RoutingGraphPath:        getEnv("ROUTING_GRAPH_PATH", ""),
+		RoutingFallbackSpeedKmh: getEnvAsInt("ROUTING_FALLBACK_SPEED_KMH", 30),
+		RoutingMaxSnapMeters:    getEnvAsInt("ROUTING_MAX_SNAP_METERS", 500),
+
+		GeocodingGazetteerPath:          getEnv("GEOCODING_GAZETTEER_PATH", ""),
+		GeocodingReverseRadiusMeters:    getEnvAsInt("GEOCODING_REVERSE_RADIUS_METERS", 300),
+		GeocodingAddressToleranceMeters: getEnvAsInt("GEOCODING_ADDRESS_TOLERANCE_METERS", 2000),
+
+		AirportQueueOfferDepth: getEnvAsInt("AIRPORT_QUEUE_OFFER_DEPTH", 3),
+
+		DriverRatingWindow: getEnvAsInt("DRIVER_RATING_WINDOW", 100),
+		RatingWindowHours:  getEnvAsInt("RATING_WINDOW_HOURS", 72),
+
+		ModerationWordListPath: getEnv("MODERATION_WORDLIST_PATH", ""),
+
+		QualityWindowDays:           getEnvAsInt("QUALITY_WINDOW_DAYS", 30),
+		QualityMinTrips:             getEnvAsInt("QUALITY_MIN_TRIPS", 20),
+		QualityWarningScore:         getEnvAsInt("QUALITY_WARNING_SCORE", 75),
+		QualitySuspensionScore:      getEnvAsInt("QUALITY_SUSPENSION_SCORE", 60),
+		QualityReviewScore:          getEnvAsInt("QUALITY_REVIEW_SCORE", 45),
+		QualitySuspensionHours:      getEnvAsInt("QUALITY_SUSPENSION_HOURS", 24),
+		QualityCheckIntervalMinutes: getEnvAsInt("QUALITY_CHECK_INTERVAL_MINUTES", 60),
+
+		NotificationDefaultLocale:     getEnv("NOTIFICATION_DEFAULT_LOCALE", "en-KE"),
+		NotificationDefaultTimezone:   getEnv("NOTIFICATION_DEFAULT_TIMEZONE", "Africa/Nairobi"),
+		NotificationMaxAttempts:       getEnvAsInt("NOTIFICATION_MAX_ATTEMPTS", 5),
+		NotificationRetrySeconds:      getEnvAsInt("NOTIFICATION_RETRY_SECONDS", 30),
+		NotificationOfferRadiusMeters: getEnvAsInt("NOTIFICATION_OFFER_RADIUS_METERS", 3000),
+		NotificationOfferLimit:        getEnvAsInt("NOTIFICATION_OFFER_LIMIT", 10),
+
+		CallProxyNumber:    getEnv("CALL_PROXY_NUMBER", ""),
+		CallSessionMinutes: getEnvAsInt("CALL_SESSION_MINUTES", 60),
+
+		SafetyShareBaseURL:         getEnv("SAFETY_SHARE_BASE_URL", "http://localhost:8080/api/v1/shared-trips/"),
+		SafetyShareLinkHours:       getEnvAsInt("SAFETY_SHARE_LINK_HOURS", 4),
+		SafetyRouteDeviationMeters: getEnvAsInt("SAFETY_ROUTE_DEVIATION_METERS", 500),
+		SafetyLongStopMinutes:      getEnvAsInt("SAFETY_LONG_STOP_MINUTES", 5),
+		SafetyOverETAPercent:       getEnvAsInt("SAFETY_OVER_ETA_PERCENT", 50),
+		SafetyCheckIntervalSeconds: getEnvAsInt("SAFETY_CHECK_INTERVAL_SECONDS", 60),
+
+		BlobStoreDir:                   getEnv("BLOB_STORE_DIR", "./data/blobs"),
+		SupportAttachmentMaxMB:         getEnvAsInt("SUPPORT_ATTACHMENT_MAX_MB", 10),
+		SupportSLACheckIntervalSeconds: getEnvAsInt("SUPPORT_SLA_CHECK_INTERVAL_SECONDS", 60),
+
+		GatewayIdentitySecret: getEnv("GATEWAY_IDENTITY_SECRET", "your-identity-secret"),
+
+		RedisAddr:                  getEnv("REDIS_ADDR", "localhost:6379"),
+		RedisPassword:              getEnv("REDIS_PASSWORD", ""),
+		RedisDB:                    getEnvAsInt("REDIS_DB", 0),
+		RateLimitPolicyPath:        getEnv("RATE_LIMIT_POLICY_PATH", ""),
+		RateLimitTrustForwardedFor: getEnvAsBool("RATE_LIMIT_TRUST_FORWARDED_FOR", false),
+
+		DefaultCityCode: getEnv("DEFAULT_CITY_CODE", "nairobi"),
+		DefaultCurrency: getEnv("DEFAULT_CURRENCY", "KES"),
 	}
 }
 
@@ -87,3 +264,11 @@ func getEnvAsInt(key string, defaultValue int) int {
 	}
 	return defaultValue
 }
+
+func getEnvAsBool(key string, defaultValue bool) bool {
+	valueStr := getEnv(key, "")
+	if value, err := strconv.ParseBool(valueStr); err == nil {
+		return value
+	}
+	return defaultValue
+}
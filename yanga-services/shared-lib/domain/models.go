@@ -104,11 +104,12 @@ type ResetPasswordRequest struct {
 }
 
 type RegisterRequest struct {
-	PhoneNumber string `json:"phone_number" validate:"required" example:"+254712345678"`
-	Email       string `json:"email" validate:"omitempty,email" example:"user@example.com"`
-	Password    string `json:"password" validate:"required,min=6" example:"password123"`
-	FullName    string `json:"full_name" validate:"required" example:"John Doe"`
-	Role        string `json:"role" validate:"required,oneof=user driver" example:"user"`
+	PhoneNumber  string `json:"phone_number" validate:"required" example:"+254712345678"`
+	Email        string `json:"email" validate:"omitempty,email" example:"user@example.com"`
+	Password     string `json:"password" validate:"required,min=6" example:"password123"`
+	FullName     string `json:"full_name" validate:"required" example:"John Doe"`
+	Role         string `json:"role" validate:"required,oneof=user driver" example:"user"`
+	ReferralCode string `json:"referral_code,omitempty" example:"K7QX2M9A"`
 }
 
 type LoginRequest struct {
@@ -164,6 +165,9 @@ type CreateDriverProfileRequest struct {
 	VehicleModel       string `json:"vehicle_model" validate:"required"`
 	VehicleColor       string `json:"vehicle_color" validate:"required"`
 	VehiclePlateNumber string `json:"vehicle_plate_number" validate:"required"`
+	// CityCode is the city the driver works in; drivers without one may
+	// take trips in any city
+	CityCode string `json:"city_code,omitempty" example:"nairobi"`
 }
 
 type DriverProfileResponse struct {
@@ -176,6 +180,7 @@ type DriverProfileResponse struct {
 	VehiclePlateNumber string  `json:"vehicle_plate_number"`
 	IsOnline           bool    `json:"is_online"`
 	IsApproved         bool    `json:"is_approved"`
+	CityCode           string  `json:"city_code,omitempty"`
 	Rating             float64 `json:"rating"`
 	TotalTrips         int32   `json:"total_trips"`
 	CurrentLatitude    float64 `json:"current_latitude,omitempty"`
@@ -186,7 +191,6 @@ type NearbyDriverResponse struct {
 	DriverID           string  `json:"driver_id"`
 	UserID             string  `json:"user_id"`
 	FullName           string  `json:"full_name"`
-	PhoneNumber        string  `json:"phone_number"`
 	VehicleType        string  `json:"vehicle_type"`
 	VehicleModel       string  `json:"vehicle_model"`
 	VehicleColor       string  `json:"vehicle_color"`
@@ -195,28 +199,139 @@ type NearbyDriverResponse struct {
 	CurrentLatitude    float64 `json:"current_latitude"`
 	CurrentLongitude   float64 `json:"current_longitude"`
 	Distance           float64 `json:"distance"`
+	// EtaMinutes is the estimated drive to the pickup by road.
+	EtaMinutes int `json:"eta_minutes"`
 }
 
 type RatingResponse struct {
-	ID        string    `json:"id"`
-	TripID    string    `json:"trip_id"`
-	RaterID   string    `json:"rater_id"`
-	RatedID   string    `json:"rated_id"`
-	RaterType string    `json:"rater_type"`
-	Rating    int32     `json:"rating"`
-	Comment   string    `json:"comment,omitempty"`
-	CreatedAt time.Time `json:"created_at"`
+	ID        string   `json:"id"`
+	TripID    string   `json:"trip_id"`
+	RaterID   string   `json:"rater_id"`
+	RaterName string   `json:"rater_name,omitempty"`
+	RatedID   string   `json:"rated_id"`
+	RaterType string   `json:"rater_type"`
+	Rating    int32    `json:"rating"`
+	Feedback  string   `json:"feedback,omitempty"`
+	Tags      []string `json:"tags"`
+	// FeedbackFlagged is set when the content filter flagged the feedback.
+	// Flagged feedback is only shown to admins until it's cleared.
+	FeedbackFlagged bool      `json:"feedback_flagged,omitempty"`
+	FlagReasons     []string  `json:"flag_reasons,omitempty"`
+	Excluded        bool      `json:"excluded,omitempty"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// RatingDisputeResponse is a driver's challenge of a rating they received
+// and, once reviewed, its outcome.
+type RatingDisputeResponse struct {
+	ID             string     `json:"id"`
+	RatingID       string     `json:"rating_id"`
+	TripID         string     `json:"trip_id,omitempty"`
+	RaterID        string     `json:"rater_id,omitempty"`
+	DisputedBy     string     `json:"disputed_by"`
+	Rating         int32      `json:"rating,omitempty"`
+	Feedback       string     `json:"feedback,omitempty"`
+	Reason         string     `json:"reason"`
+	Status         string     `json:"status"`
+	ResolutionNote string     `json:"resolution_note,omitempty"`
+	ReviewedBy     string     `json:"reviewed_by,omitempty"`
+	ReviewedAt     *time.Time `json:"reviewed_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// RatingSummaryResponse is how a user has been rated as a driver or as a
+// rider, with how often each tag was given.
+type RatingSummaryResponse struct {
+	UserID        string           `json:"user_id"`
+	RatedAs       string           `json:"rated_as"`
+	AverageRating float64          `json:"average_rating"`
+	TotalRatings  int64            `json:"total_ratings"`
+	Tags          []RatingTagCount `json:"tags"`
+}
+
+type RatingTagCount struct {
+	Tag   string `json:"tag"`
+	Count int64  `json:"count"`
 }
 
 // Trip DTOs
 type CreateTripRequest struct {
-	UserID           uuid.UUID `json:"-"`
-	PickupLatitude   float64   `json:"pickup_latitude" validate:"required" example:"-1.286389"`
-	PickupLongitude  float64   `json:"pickup_longitude" validate:"required" example:"36.817223"`
-	PickupAddress    string    `json:"pickup_address" validate:"required" example:"Nairobi CBD"`
-	DropoffLatitude  float64   `json:"dropoff_latitude" validate:"required" example:"-1.292066"`
-	DropoffLongitude float64   `json:"dropoff_longitude" validate:"required" example:"36.821945"`
-	DropoffAddress   string    `json:"dropoff_address" validate:"required" example:"Westlands"`
+	UserID uuid.UUID `json:"-"`
+	// Addresses must match their coordinates. A blank address is filled in
+	// by reverse geocoding the coordinates.
+	PickupLatitude   float64    `json:"pickup_latitude" validate:"required" example:"-1.286389"`
+	PickupLongitude  float64    `json:"pickup_longitude" validate:"required" example:"36.817223"`
+	PickupAddress    string     `json:"pickup_address,omitempty" example:"Nairobi CBD"`
+	DropoffLatitude  float64    `json:"dropoff_latitude" validate:"required" example:"-1.292066"`
+	DropoffLongitude float64    `json:"dropoff_longitude" validate:"required" example:"36.821945"`
+	DropoffAddress   string     `json:"dropoff_address,omitempty" example:"Westlands"`
+	PaymentMethod    string     `json:"payment_method" validate:"omitempty,oneof=cash card wallet" example:"wallet"`
+	VehicleType      string     `json:"vehicle_type,omitempty" example:"sedan"`
+	PromoCode        string     `json:"promo_code,omitempty" example:"WELCOME50"`
+	PickupAt         *time.Time `json:"pickup_at,omitempty" example:"2026-01-15T07:30:00Z"`
+	// Stops are intermediate waypoints visited in order between pickup and
+	// dropoff.
+	Stops []TripStopRequest `json:"stops,omitempty"`
+	// Pooled rides share the vehicle with other riders heading the same way
+	// for a lower fare. Seats defaults to one.
+	Pooled bool `json:"pooled,omitempty" example:"false"`
+	Seats  int  `json:"seats,omitempty" example:"1"`
+	// TripType is "ride" (the default) or "delivery". Deliveries carry a
+	// parcel instead of the rider and need Delivery set.
+	TripType string                 `json:"trip_type,omitempty" validate:"omitempty,oneof=ride delivery" example:"ride"`
+	Delivery *ParcelDeliveryRequest `json:"delivery,omitempty"`
+}
+
+type ParcelDeliveryRequest struct {
+	SenderName     string  `json:"sender_name" validate:"required" example:"Jane Doe"`
+	SenderPhone    string  `json:"sender_phone" validate:"required" example:"+254712345678"`
+	RecipientName  string  `json:"recipient_name" validate:"required" example:"John Doe"`
+	RecipientPhone string  `json:"recipient_phone" validate:"required" example:"+254787654321"`
+	ParcelSize     string  `json:"parcel_size" validate:"required,oneof=small medium large" example:"small"`
+	WeightKg       float64 `json:"weight_kg" validate:"required,gt=0" example:"2.5"`
+	Description    string  `json:"description,omitempty" example:"Documents"`
+	Instructions   string  `json:"instructions,omitempty" example:"Leave with reception"`
+}
+
+type TripStopRequest struct {
+	Latitude  float64 `json:"latitude" validate:"required" example:"-1.289012"`
+	Longitude float64 `json:"longitude" validate:"required" example:"36.819876"`
+	Address   string  `json:"address" validate:"required" example:"Kenyatta Avenue"`
+}
+
+type FareQuoteRequest struct {
+	PickupLatitude   float64           `json:"pickup_latitude" validate:"required" example:"-1.286389"`
+	PickupLongitude  float64           `json:"pickup_longitude" validate:"required" example:"36.817223"`
+	DropoffLatitude  float64           `json:"dropoff_latitude" validate:"required" example:"-1.292066"`
+	DropoffLongitude float64           `json:"dropoff_longitude" validate:"required" example:"36.821945"`
+	VehicleType      string            `json:"vehicle_type,omitempty" example:"sedan"`
+	PromoCode        string            `json:"promo_code,omitempty" example:"WELCOME50"`
+	Stops            []TripStopRequest `json:"stops,omitempty"`
+	Pooled           bool              `json:"pooled,omitempty" example:"false"`
+	Seats            int               `json:"seats,omitempty" example:"1"`
+	TripType         string            `json:"trip_type,omitempty" example:"ride"`
+	ParcelSize       string            `json:"parcel_size,omitempty" example:"small"`
+	WeightKg         float64           `json:"weight_kg,omitempty" example:"2.5"`
+}
+
+type FareQuoteResponse struct {
+	Distance          float64 `json:"distance"`
+	EstimatedDuration int     `json:"estimated_duration"`
+	BaseFare          float64 `json:"base_fare"`
+	DistanceFare      float64 `json:"distance_fare"`
+	StopFare          float64 `json:"stop_fare"`
+	PoolDiscount      float64 `json:"pool_discount,omitempty"`
+	ParcelFare        float64 `json:"parcel_fare,omitempty"`
+	ZoneFee           float64 `json:"zone_fee,omitempty"`
+	Subtotal          float64 `json:"subtotal"`
+	Discount          float64 `json:"discount"`
+	Total             float64 `json:"total"`
+	PromoCode         string  `json:"promo_code,omitempty"`
+	PromoDescription  string  `json:"promo_description,omitempty"`
+	CityCode          string  `json:"city_code"`
+	Currency          string  `json:"currency"`
+	// Polyline is the road route in Google's encoded polyline format.
+	Polyline string `json:"polyline,omitempty"`
 }
 
 type TripResponse struct {
@@ -248,12 +363,354 @@ type CompleteTripRequest struct {
 	ActualFare     float64 `json:"actual_fare" validate:"required" example:"450.00"`
 	ActualDuration int     `json:"actual_duration" validate:"required" example:"25"`
 	PaymentStatus  string  `json:"payment_status" validate:"required,oneof=paid pending failed" example:"paid"`
+	Tip            float64 `json:"tip,omitempty" validate:"gte=0" example:"50.00"`
 }
 
 type CancelTripRequest struct {
 	Reason string `json:"reason" validate:"required" example:"Changed my mind"`
 }
 
+type CancelTripResponse struct {
+	TripID          string  `json:"trip_id"`
+	CancelledBy     string  `json:"cancelled_by"`
+	NoShowParty     string  `json:"no_show_party,omitempty"`
+	CancellationFee float64 `json:"cancellation_fee"`
+}
+
+type AddTripStopRequest struct {
+	Latitude  float64 `json:"latitude" validate:"required" example:"-1.289012"`
+	Longitude float64 `json:"longitude" validate:"required" example:"36.819876"`
+	Address   string  `json:"address" validate:"required" example:"Kenyatta Avenue"`
+	// Position is the 1-based place in the stop list; zero appends the stop.
+	Position int `json:"position,omitempty" example:"1"`
+}
+
+type TripStopResponse struct {
+	ID        string     `json:"id"`
+	StopOrder int        `json:"stop_order"`
+	Latitude  float64    `json:"latitude"`
+	Longitude float64    `json:"longitude"`
+	Address   string     `json:"address"`
+	ArrivedAt *time.Time `json:"arrived_at,omitempty"`
+}
+
+type ConfirmParcelPickupRequest struct {
+	PhotoRef string `json:"photo_ref" validate:"required" example:"uploads/pickups/3f2a.jpg"`
+}
+
+type ConfirmParcelDeliveryRequest struct {
+	PhotoRef string `json:"photo_ref" validate:"required" example:"uploads/deliveries/9c1b.jpg"`
+	// Pin is the code the recipient received from the sender.
+	Pin string `json:"pin" validate:"required" example:"4821"`
+}
+
+type ParcelDeliveryResponse struct {
+	TripID           string     `json:"trip_id"`
+	SenderName       string     `json:"sender_name"`
+	SenderPhone      string     `json:"sender_phone"`
+	RecipientName    string     `json:"recipient_name"`
+	RecipientPhone   string     `json:"recipient_phone"`
+	ParcelSize       string     `json:"parcel_size"`
+	WeightKg         float64    `json:"weight_kg"`
+	Description      string     `json:"description,omitempty"`
+	Instructions     string     `json:"instructions,omitempty"`
+	RecipientPin     string     `json:"recipient_pin,omitempty"`
+	PickupPhotoRef   string     `json:"pickup_photo_ref,omitempty"`
+	PickedUpAt       *time.Time `json:"picked_up_at,omitempty"`
+	DeliveryPhotoRef string     `json:"delivery_photo_ref,omitempty"`
+	DeliveredAt      *time.Time `json:"delivered_at,omitempty"`
+}
+
+// TripETAResponse estimates when the driver reaches the pickup, or the
+// dropoff once the trip has started.
+type TripETAResponse struct {
+	TripID     string  `json:"trip_id"`
+	Target     string  `json:"target" example:"pickup"`
+	Distance   float64 `json:"distance"`
+	EtaMinutes int     `json:"eta_minutes"`
+	Polyline   string  `json:"polyline"`
+	// DriverAddress names where the driver is now, when it is known.
+	DriverAddress string `json:"driver_address,omitempty" example:"Kenyatta Avenue"`
+}
+
+type GeoPoint struct {
+	Latitude  float64 `json:"latitude" example:"-1.3192"`
+	Longitude float64 `json:"longitude" example:"36.9278"`
+}
+
+// GeofenceRequest defines a service area, restricted zone, airport or
+// airport staging lot. Airports charge PickupFee on trips starting inside
+// them and DropoffFee on trips ending inside them. Staging lots name their
+// airport in ParentID; drivers waiting in one are queued for its pickups.
+type GeofenceRequest struct {
+	CityCode   string     `json:"city_code" validate:"required" example:"nairobi"`
+	Name       string     `json:"name" validate:"required" example:"JKIA"`
+	Kind       string     `json:"kind" validate:"required,oneof=service_area restricted airport staging" example:"airport"`
+	ParentID   string     `json:"parent_id,omitempty"`
+	Polygon    []GeoPoint `json:"polygon" validate:"required,min=3"`
+	PickupFee  float64    `json:"pickup_fee,omitempty" example:"150"`
+	DropoffFee float64    `json:"dropoff_fee,omitempty" example:"100"`
+	// Active defaults to true.
+	Active *bool `json:"active,omitempty" example:"true"`
+}
+
+type GeofenceResponse struct {
+	ID         string     `json:"id"`
+	CityCode   string     `json:"city_code"`
+	Name       string     `json:"name"`
+	Kind       string     `json:"kind"`
+	ParentID   string     `json:"parent_id,omitempty"`
+	Polygon    []GeoPoint `json:"polygon"`
+	PickupFee  float64    `json:"pickup_fee"`
+	DropoffFee float64    `json:"dropoff_fee"`
+	Active     bool       `json:"active"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// AirportQueuePositionResponse is a driver's place in an airport's queue.
+// Position 1 is offered the next pickup.
+type AirportQueuePositionResponse struct {
+	AirportID   string    `json:"airport_id"`
+	AirportName string    `json:"airport_name"`
+	StagingID   string    `json:"staging_id"`
+	Position    int64     `json:"position"`
+	QueueLength int64     `json:"queue_length"`
+	JoinedAt    time.Time `json:"joined_at"`
+}
+
+type AirportQueueEntryResponse struct {
+	DriverID  string    `json:"driver_id"`
+	StagingID string    `json:"staging_id"`
+	Position  int       `json:"position"`
+	JoinedAt  time.Time `json:"joined_at"`
+}
+
+// PlaceResponse is a geocoding result.
+type PlaceResponse struct {
+	Name      string  `json:"name" example:"Sarit Centre"`
+	Kind      string  `json:"kind,omitempty" example:"landmark"`
+	Address   string  `json:"address" example:"Parklands Road, Westlands, Nairobi"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+type SavePlaceRequest struct {
+	// Label is "home", "work" or "favorite". Saving home or work replaces
+	// the one saved before.
+	Label     string  `json:"label" validate:"required,oneof=home work favorite" example:"home"`
+	Name      string  `json:"name" validate:"required" example:"Home"`
+	Address   string  `json:"address" validate:"required" example:"Lavington, Nairobi"`
+	Latitude  float64 `json:"latitude" validate:"required" example:"-1.2780"`
+	Longitude float64 `json:"longitude" validate:"required" example:"36.7707"`
+}
+
+type SavedPlaceResponse struct {
+	ID        string    `json:"id"`
+	Label     string    `json:"label"`
+	Name      string    `json:"name"`
+	Address   string    `json:"address"`
+	Latitude  float64   `json:"latitude"`
+	Longitude float64   `json:"longitude"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type TripRouteResponse struct {
+	TripID            string             `json:"trip_id"`
+	Stops             []TripStopResponse `json:"stops"`
+	Distance          float64            `json:"distance"`
+	EstimatedDuration int                `json:"estimated_duration"`
+	Subtotal          float64            `json:"subtotal"`
+	Discount          float64            `json:"discount"`
+	EstimatedFare     float64            `json:"estimated_fare"`
+}
+
+type TripPoolResponse struct {
+	PoolID       string                 `json:"pool_id"`
+	DriverID     string                 `json:"driver_id,omitempty"`
+	Status       string                 `json:"status"`
+	SeatCapacity int                    `json:"seat_capacity"`
+	SeatsBooked  int                    `json:"seats_booked"`
+	Waypoints    []PoolWaypointResponse `json:"waypoints"`
+}
+
+type PoolWaypointResponse struct {
+	TripID      string     `json:"trip_id"`
+	Kind        string     `json:"kind"`
+	Sequence    int        `json:"sequence"`
+	Latitude    float64    `json:"latitude"`
+	Longitude   float64    `json:"longitude"`
+	Address     string     `json:"address"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+// SendChatMessageRequest sends a message in a trip's chat: free text in
+// Body, or the code of one of the sender's quick replies. ClientID, chosen
+// by the app, makes resending a message after a dropped connection safe.
+type SendChatMessageRequest struct {
+	Body       string `json:"body,omitempty" example:"I'm at the main gate"`
+	QuickReply string `json:"quick_reply,omitempty" example:"at_pickup"`
+	ClientID   string `json:"client_id,omitempty" example:"7c0e2a9f"`
+}
+
+type ChatMessageResponse struct {
+	ID         string     `json:"id"`
+	TripID     string     `json:"trip_id"`
+	SenderID   string     `json:"sender_id"`
+	SenderRole string     `json:"sender_role"`
+	Body       string     `json:"body"`
+	QuickReply string     `json:"quick_reply,omitempty"`
+	ClientID   string     `json:"client_id,omitempty"`
+	ReadAt     *time.Time `json:"read_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// MarkChatReadRequest marks read everything the other party sent up to and
+// including MessageID.
+type MarkChatReadRequest struct {
+	MessageID string `json:"message_id" validate:"required"`
+}
+
+// ChatReadReceipt tells a sender the reader has seen their messages up to
+// MessageID.
+type ChatReadReceipt struct {
+	TripID    string    `json:"trip_id"`
+	ReaderID  string    `json:"reader_id"`
+	MessageID string    `json:"message_id"`
+	ReadAt    time.Time `json:"read_at"`
+}
+
+type QuickReplyResponse struct {
+	Code string `json:"code"`
+	Text string `json:"text"`
+}
+
+// ChatClientFrame is a frame the app sends on the chat WebSocket: a
+// "message" frame with the fields of SendChatMessageRequest, or a "read"
+// frame with MessageID.
+type ChatClientFrame struct {
+	Type string `json:"type"`
+	SendChatMessageRequest
+	MessageID string `json:"message_id,omitempty"`
+}
+
+// ChatServerFrame is a frame sent to the app on the chat WebSocket: a new
+// message, a read receipt, or an error with a frame the app sent.
+type ChatServerFrame struct {
+	Type    string               `json:"type"`
+	Message *ChatMessageResponse `json:"message,omitempty"`
+	Read    *ChatReadReceipt     `json:"read,omitempty"`
+	Error   string               `json:"error,omitempty"`
+}
+
+// MaskedCallResponse is the number a rider or driver calls to reach the
+// other. It works from their own phone until ExpiresAt.
+type MaskedCallResponse struct {
+	ProxyNumber string    `json:"proxy_number"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// EmergencyContactRequest adds someone to be told when the user raises an
+// SOS.
+type EmergencyContactRequest struct {
+	Name        string `json:"name" validate:"required" example:"Mary Wanjiku"`
+	PhoneNumber string `json:"phone_number" validate:"required" example:"+254712345678"`
+}
+
+type EmergencyContactResponse struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	PhoneNumber string    `json:"phone_number"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// SOSRequest raises an SOS on a trip. The location is the reporter's own,
+// from their phone, when they have one.
+type SOSRequest struct {
+	Latitude  *float64 `json:"latitude,omitempty" example:"-1.2921"`
+	Longitude *float64 `json:"longitude,omitempty" example:"36.8219"`
+	Message   string   `json:"message,omitempty"`
+}
+
+// SOSResponse is the incident raised for an SOS, with the link the
+// reporter's emergency contacts were sent.
+type SOSResponse struct {
+	Incident         SafetyIncidentResponse `json:"incident"`
+	ShareURL         string                 `json:"share_url"`
+	ContactsNotified int                    `json:"contacts_notified"`
+}
+
+// SafetySnapshot is a trip as it stood when a safety incident was raised.
+type SafetySnapshot struct {
+	TripID            string            `json:"trip_id"`
+	Status            string            `json:"status"`
+	RiderID           string            `json:"rider_id"`
+	DriverID          string            `json:"driver_id,omitempty"`
+	Driver            *SharedTripDriver `json:"driver,omitempty"`
+	PickupAddress     string            `json:"pickup_address"`
+	DropoffAddress    string            `json:"dropoff_address"`
+	StartedAt         *time.Time        `json:"started_at,omitempty"`
+	EstimatedDuration int32             `json:"estimated_duration,omitempty"`
+	DriverLatitude    *float64          `json:"driver_latitude,omitempty"`
+	DriverLongitude   *float64          `json:"driver_longitude,omitempty"`
+	CapturedAt        time.Time         `json:"captured_at"`
+}
+
+// SafetyIncidentResponse is an SOS or a detected anomaly in the safety
+// team's queue.
+type SafetyIncidentResponse struct {
+	ID             string          `json:"id"`
+	TripID         string          `json:"trip_id"`
+	ReporterID     string          `json:"reporter_id,omitempty"`
+	Kind           string          `json:"kind"`
+	Status         string          `json:"status"`
+	Latitude       *float64        `json:"latitude,omitempty"`
+	Longitude      *float64        `json:"longitude,omitempty"`
+	Details        string          `json:"details,omitempty"`
+	Snapshot       *SafetySnapshot `json:"snapshot,omitempty"`
+	AcknowledgedBy string          `json:"acknowledged_by,omitempty"`
+	AcknowledgedAt *time.Time      `json:"acknowledged_at,omitempty"`
+	ResolvedBy     string          `json:"resolved_by,omitempty"`
+	ResolvedAt     *time.Time      `json:"resolved_at,omitempty"`
+	ResolutionNote string          `json:"resolution_note,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+type ResolveSafetyIncidentRequest struct {
+	Note string `json:"note" validate:"required"`
+}
+
+// ShareTripResponse is a link anyone can open to follow a trip until
+// ExpiresAt.
+type ShareTripResponse struct {
+	ID        string    `json:"id"`
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// SharedTripResponse is what someone following a share link sees. The
+// driver's location is only shown while the trip is under way.
+type SharedTripResponse struct {
+	Status          string            `json:"status"`
+	PickupAddress   string            `json:"pickup_address"`
+	DropoffAddress  string            `json:"dropoff_address"`
+	Driver          *SharedTripDriver `json:"driver,omitempty"`
+	DriverLatitude  *float64          `json:"driver_latitude,omitempty"`
+	DriverLongitude *float64          `json:"driver_longitude,omitempty"`
+	StartedAt       *time.Time        `json:"started_at,omitempty"`
+	ExpiresAt       time.Time         `json:"expires_at"`
+}
+
+// SharedTripDriver is how a driver and their vehicle are shown to someone
+// following a trip.
+type SharedTripDriver struct {
+	Name         string `json:"name"`
+	VehicleModel string `json:"vehicle_model"`
+	VehicleColor string `json:"vehicle_color"`
+	PlateNumber  string `json:"plate_number"`
+}
+
 type UpdateDriverProfileRequest struct {
 	LicenseNumber      string `json:"license_number,omitempty" example:"DL123456789"`
 	VehicleType        string `json:"vehicle_type,omitempty" example:"sedan"`
@@ -263,11 +720,751 @@ type UpdateDriverProfileRequest struct {
 }
 
 // Rating DTOs
+// CreateRatingRequest rates the other party of a completed trip: riders
+// rate their driver and drivers rate their rider.
 type CreateRatingRequest struct {
 	TripID   uuid.UUID `json:"trip_id" validate:"required"`
-	RatedID  uuid.UUID `json:"rated_id" validate:"required"`
-	Rating   int       `json:"rating" validate:"required,min=1,max=5" example:"5"`
+	Rating   int32     `json:"rating" validate:"required,min=1,max=5" example:"5"`
 	Feedback string    `json:"feedback" example:"Great driver!"`
+	Tags     []string  `json:"tags" example:"clean_car,safe_driving"`
+}
+
+// Who gave a rating
+const (
+	RaterTypeRider  = "rider"
+	RaterTypeDriver = "driver"
+)
+
+type CreateRatingDisputeRequest struct {
+	Reason string `json:"reason" validate:"required" example:"The rider rated me one star because of traffic"`
+}
+
+// ResolveRatingDisputeRequest decides a dispute. Accepting it excludes the
+// rating from the driver's averages.
+type ResolveRatingDisputeRequest struct {
+	Decision string `json:"decision" validate:"required,oneof=accepted rejected" example:"accepted"`
+	Note     string `json:"note" example:"Rating was about traffic, not the driver"`
+}
+
+// ModerateRatingRequest excludes a rating from averages, restores an
+// excluded one, or clears the content filter's flag on its feedback.
+type ModerateRatingRequest struct {
+	Action string `json:"action" validate:"required,oneof=exclude restore clear_flag" example:"exclude"`
+	Reason string `json:"reason" example:"Abusive feedback"`
+}
+
+// Rating dispute statuses
+const (
+	DisputeStatusPending  = "pending"
+	DisputeStatusAccepted = "accepted"
+	DisputeStatusRejected = "rejected"
+)
+
+// Rating moderation actions
+const (
+	ModerationActionExclude   = "exclude"
+	ModerationActionRestore   = "restore"
+	ModerationActionClearFlag = "clear_flag"
+)
+
+// RatingTags lists the tags each kind of rater may choose from.
+var RatingTags = map[string][]string{
+	RaterTypeRider: {
+		"clean_car", "safe_driving", "good_navigation", "friendly", "great_conversation",
+		"late", "unsafe_driving", "dirty_car", "wrong_route", "rude",
+	},
+	RaterTypeDriver: {
+		"polite", "on_time", "respectful", "clean",
+		"late", "wrong_pickup", "messy", "rude",
+	},
+}
+
+// Wallet DTOs
+type TopUpWalletRequest struct {
+	UserID           uuid.UUID `json:"user_id" validate:"required"`
+	Amount           float64   `json:"amount" validate:"required,gt=0" example:"500.00"`
+	PaymentReference string    `json:"payment_reference" validate:"required" example:"QGH7XK2P1L"`
+	Currency         string    `json:"currency,omitempty" example:"KES"`
+}
+
+type TransferWalletRequest struct {
+	RecipientID uuid.UUID `json:"recipient_id" validate:"required"`
+	Amount      float64   `json:"amount" validate:"required,gt=0" example:"200.00"`
+	Note        string    `json:"note" example:"Lunch money"`
+	Currency    string    `json:"currency,omitempty" example:"KES"`
+}
+
+type RefundRequest struct {
+	TripID uuid.UUID `json:"trip_id" validate:"required"`
+	Amount float64   `json:"amount" validate:"required,gt=0" example:"150.00"`
+	Reason string    `json:"reason" validate:"required" example:"Driver took a longer route"`
+}
+
+type PromoCreditRequest struct {
+	UserID      uuid.UUID `json:"user_id" validate:"required"`
+	Amount      float64   `json:"amount" validate:"required,gt=0" example:"100.00"`
+	Description string    `json:"description" example:"Welcome bonus"`
+	Currency    string    `json:"currency,omitempty" example:"KES"`
+}
+
+type WalletResponse struct {
+	AccountID string  `json:"account_id,omitempty"`
+	UserID    string  `json:"user_id"`
+	Currency  string  `json:"currency"`
+	Balance   float64 `json:"balance"`
+}
+
+type WalletTransactionResponse struct {
+	ID              string    `json:"id"`
+	TransactionID   string    `json:"transaction_id"`
+	TransactionType string    `json:"transaction_type"`
+	Direction       string    `json:"direction"`
+	Amount          float64   `json:"amount"`
+	TripID          string    `json:"trip_id,omitempty"`
+	Description     string    `json:"description,omitempty"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+type LedgerTransactionResponse struct {
+	ID              string    `json:"id"`
+	TransactionType string    `json:"transaction_type"`
+	IdempotencyKey  string    `json:"idempotency_key"`
+	TripID          string    `json:"trip_id,omitempty"`
+	Description     string    `json:"description,omitempty"`
+	Amount          float64   `json:"amount"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+type ReconciliationReport struct {
+	PeriodStart            time.Time                `json:"period_start"`
+	PeriodEnd              time.Time                `json:"period_end"`
+	Balanced               bool                     `json:"balanced"`
+	CurrencyTotals         []CurrencyTotal          `json:"currency_totals"`
+	TransactionTotals      []TransactionTypeTotal   `json:"transaction_totals"`
+	AccountTotals          []AccountTypeTotal       `json:"account_totals"`
+	UnbalancedTransactions []UnbalancedTransaction  `json:"unbalanced_transactions"`
+	NegativeBalances       []NegativeBalanceAccount `json:"negative_balances"`
+}
+
+// CurrencyTotal is every debit and credit in one currency. Amounts in
+// different currencies are never added together.
+type CurrencyTotal struct {
+	Currency     string  `json:"currency"`
+	Balanced     bool    `json:"balanced"`
+	TotalDebits  float64 `json:"total_debits"`
+	TotalCredits float64 `json:"total_credits"`
+}
+
+type TransactionTypeTotal struct {
+	TransactionType  string  `json:"transaction_type"`
+	Currency         string  `json:"currency"`
+	TransactionCount int64   `json:"transaction_count"`
+	TotalAmount      float64 `json:"total_amount"`
+}
+
+type AccountTypeTotal struct {
+	AccountType  string  `json:"account_type"`
+	Currency     string  `json:"currency"`
+	AccountCount int64   `json:"account_count"`
+	TotalDebits  float64 `json:"total_debits"`
+	TotalCredits float64 `json:"total_credits"`
+}
+
+type UnbalancedTransaction struct {
+	TransactionID   string    `json:"transaction_id"`
+	TransactionType string    `json:"transaction_type"`
+	IdempotencyKey  string    `json:"idempotency_key"`
+	TotalDebits     float64   `json:"total_debits"`
+	TotalCredits    float64   `json:"total_credits"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+type NegativeBalanceAccount struct {
+	AccountID   string  `json:"account_id"`
+	OwnerID     string  `json:"owner_id"`
+	AccountType string  `json:"account_type"`
+	Currency    string  `json:"currency"`
+	Balance     float64 `json:"balance"`
+}
+
+// Earnings DTOs
+type CreateCommissionPlanRequest struct {
+	Name           string     `json:"name" validate:"required" example:"Standard sedan"`
+	VehicleType    string     `json:"vehicle_type,omitempty" example:"sedan"`
+	CityCode       string     `json:"city_code,omitempty" example:"nairobi"`
+	CommissionRate float64    `json:"commission_rate" validate:"gte=0,lte=1" example:"0.20"`
+	TaxRate        float64    `json:"tax_rate" validate:"gte=0,lte=1" example:"0.16"`
+	EffectiveFrom  *time.Time `json:"effective_from,omitempty"`
+	EffectiveTo    *time.Time `json:"effective_to,omitempty"`
+}
+
+type UpdateCommissionPlanStatusRequest struct {
+	IsActive bool `json:"is_active" example:"false"`
+}
+
+type CommissionPlanResponse struct {
+	ID             string     `json:"id"`
+	Name           string     `json:"name"`
+	VehicleType    string     `json:"vehicle_type,omitempty"`
+	CityCode       string     `json:"city_code,omitempty"`
+	CommissionRate float64    `json:"commission_rate"`
+	TaxRate        float64    `json:"tax_rate"`
+	IsActive       bool       `json:"is_active"`
+	EffectiveFrom  time.Time  `json:"effective_from"`
+	EffectiveTo    *time.Time `json:"effective_to,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+type TripEarningResponse struct {
+	TripID         string    `json:"trip_id"`
+	GrossFare      float64   `json:"gross_fare"`
+	CommissionRate float64   `json:"commission_rate"`
+	Commission     float64   `json:"commission"`
+	Tax            float64   `json:"tax"`
+	Tip            float64   `json:"tip"`
+	NetEarnings    float64   `json:"net_earnings"`
+	Currency       string    `json:"currency"`
+	PaymentMethod  string    `json:"payment_method,omitempty"`
+	PaidOut        bool      `json:"paid_out"`
+	EarnedAt       time.Time `json:"earned_at"`
+}
+
+type EarningsTotals struct {
+	TripCount   int64   `json:"trip_count"`
+	GrossFare   float64 `json:"gross_fare"`
+	Commission  float64 `json:"commission"`
+	Tax         float64 `json:"tax"`
+	Tips        float64 `json:"tips"`
+	NetEarnings float64 `json:"net_earnings"`
+	// Incentive bonuses, included in NetEarnings
+	Bonuses float64 `json:"bonuses"`
+}
+
+type EarningsStatement struct {
+	PeriodStart time.Time `json:"period_start"`
+	PeriodEnd   time.Time `json:"period_end"`
+	EarningsTotals
+}
+
+type DriverEarningsResponse struct {
+	From   time.Time             `json:"from"`
+	To     time.Time             `json:"to"`
+	Totals EarningsTotals        `json:"totals"`
+	Daily  []EarningsStatement   `json:"daily"`
+	Weekly []EarningsStatement   `json:"weekly"`
+	Trips  []TripEarningResponse `json:"trips"`
+}
+
+type CreatePayoutBatchRequest struct {
+	PeriodEnd time.Time `json:"period_end" validate:"required" example:"2026-01-05T00:00:00Z"`
+}
+
+type UpdatePayoutStatusRequest struct {
+	Status           string `json:"status" validate:"required,oneof=processing paid failed" example:"paid"`
+	PaymentReference string `json:"payment_reference,omitempty" example:"MPESA-QGH7XK2P1L"`
+	FailureReason    string `json:"failure_reason,omitempty" example:"Recipient account closed"`
+}
+
+type PayoutBatchResponse struct {
+	ID            string               `json:"id"`
+	Status        string               `json:"status"`
+	PeriodEnd     time.Time            `json:"period_end"`
+	TotalAmount   float64              `json:"total_amount"`
+	DriverCount   int32                `json:"driver_count"`
+	FailureReason string               `json:"failure_reason,omitempty"`
+	ProcessedAt   *time.Time           `json:"processed_at,omitempty"`
+	CreatedAt     time.Time            `json:"created_at"`
+	Items         []PayoutItemResponse `json:"items,omitempty"`
+}
+
+type PayoutItemResponse struct {
+	ID               string    `json:"id"`
+	BatchID          string    `json:"batch_id"`
+	DriverID         string    `json:"driver_id"`
+	Amount           float64   `json:"amount"`
+	EarningsCount    int32     `json:"earnings_count"`
+	Status           string    `json:"status"`
+	PaymentReference string    `json:"payment_reference,omitempty"`
+	FailureReason    string    `json:"failure_reason,omitempty"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+// Incentive DTOs
+type CreateIncentiveProgramRequest struct {
+	Name        string `json:"name" validate:"required" example:"Weekday quest"`
+	Description string `json:"description,omitempty" example:"Complete 20 trips Monday to Friday and earn KSh 1,500"`
+	Kind        string `json:"kind" validate:"required,oneof=quest peak_guarantee area_boost" example:"quest"`
+	CityCode    string `json:"city_code,omitempty" example:"nairobi"`
+	VehicleType string `json:"vehicle_type,omitempty" example:"sedan"`
+	// Area boosts only: trips picked up inside this geofence are boosted
+	GeofenceID string `json:"geofence_id,omitempty"`
+	// Quests and peak guarantees: trips needed to qualify
+	TargetTrips int32 `json:"target_trips,omitempty" example:"20"`
+	// Quest bonus, or the fares a peak guarantee tops drivers up to
+	RewardAmount float64 `json:"reward_amount,omitempty" example:"1500"`
+	// Area boosts only: share of the fare paid as a bonus
+	BoostRate float64   `json:"boost_rate,omitempty" example:"0.25"`
+	StartsAt  time.Time `json:"starts_at" validate:"required" example:"2026-01-05T00:00:00Z"`
+	EndsAt    time.Time `json:"ends_at" validate:"required" example:"2026-01-10T00:00:00Z"`
+}
+
+type UpdateIncentiveProgramStatusRequest struct {
+	IsActive bool `json:"is_active" example:"false"`
+}
+
+type IncentiveProgramResponse struct {
+	ID           string     `json:"id"`
+	Name         string     `json:"name"`
+	Description  string     `json:"description,omitempty"`
+	Kind         string     `json:"kind"`
+	CityCode     string     `json:"city_code,omitempty"`
+	VehicleType  string     `json:"vehicle_type,omitempty"`
+	GeofenceID   string     `json:"geofence_id,omitempty"`
+	Currency     string     `json:"currency"`
+	TargetTrips  int32      `json:"target_trips,omitempty"`
+	RewardAmount float64    `json:"reward_amount,omitempty"`
+	BoostRate    float64    `json:"boost_rate,omitempty"`
+	StartsAt     time.Time  `json:"starts_at"`
+	EndsAt       time.Time  `json:"ends_at"`
+	SettledAt    *time.Time `json:"settled_at,omitempty"`
+	IsActive     bool       `json:"is_active"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// IncentiveProgressResponse is a driver's standing in one program.
+type IncentiveProgressResponse struct {
+	Program        IncentiveProgramResponse `json:"program"`
+	TripsCompleted int32                    `json:"trips_completed"`
+	TripsRemaining int32                    `json:"trips_remaining"`
+	FaresEarned    float64                  `json:"fares_earned"`
+	BonusEarned    float64                  `json:"bonus_earned"`
+	CompletedAt    *time.Time               `json:"completed_at,omitempty"`
+}
+
+// Promotion DTOs
+type CreatePromoCodeRequest struct {
+	Code            string     `json:"code" validate:"required" example:"WELCOME50"`
+	Description     string     `json:"description" example:"50% off your first ride"`
+	DiscountType    string     `json:"discount_type" validate:"required,oneof=percentage fixed" example:"percentage"`
+	DiscountValue   float64    `json:"discount_value" validate:"required,gt=0" example:"50"`
+	MaxDiscount     *float64   `json:"max_discount,omitempty" example:"200.00"`
+	MinFare         *float64   `json:"min_fare,omitempty" example:"100.00"`
+	UsageLimit      *int32     `json:"usage_limit,omitempty" example:"1000"`
+	PerUserLimit    int32      `json:"per_user_limit,omitempty" example:"1"`
+	FirstRideOnly   bool       `json:"first_ride_only" example:"true"`
+	AutoApply       bool       `json:"auto_apply" example:"false"`
+	VehicleTypes    []string   `json:"vehicle_types,omitempty"`
+	CenterLatitude  *float64   `json:"center_latitude,omitempty" example:"-1.286389"`
+	CenterLongitude *float64   `json:"center_longitude,omitempty" example:"36.817223"`
+	RadiusKm        *float64   `json:"radius_km,omitempty" example:"15"`
+	CityCode        string     `json:"city_code,omitempty" example:"nairobi"`
+	ValidFrom       *time.Time `json:"valid_from,omitempty"`
+	ValidUntil      *time.Time `json:"valid_until,omitempty"`
+}
+
+type UpdatePromoCodeStatusRequest struct {
+	IsActive bool `json:"is_active" example:"false"`
+}
+
+type PromoCodeResponse struct {
+	ID              string     `json:"id"`
+	Code            string     `json:"code"`
+	Description     string     `json:"description,omitempty"`
+	DiscountType    string     `json:"discount_type"`
+	DiscountValue   float64    `json:"discount_value"`
+	MaxDiscount     *float64   `json:"max_discount,omitempty"`
+	MinFare         *float64   `json:"min_fare,omitempty"`
+	UsageLimit      *int32     `json:"usage_limit,omitempty"`
+	PerUserLimit    int32      `json:"per_user_limit"`
+	TimesUsed       int32      `json:"times_used"`
+	FirstRideOnly   bool       `json:"first_ride_only"`
+	AutoApply       bool       `json:"auto_apply"`
+	VehicleTypes    []string   `json:"vehicle_types,omitempty"`
+	CenterLatitude  *float64   `json:"center_latitude,omitempty"`
+	CenterLongitude *float64   `json:"center_longitude,omitempty"`
+	RadiusKm        *float64   `json:"radius_km,omitempty"`
+	CityCode        string     `json:"city_code,omitempty"`
+	Currency        string     `json:"currency"`
+	ValidFrom       time.Time  `json:"valid_from"`
+	ValidUntil      *time.Time `json:"valid_until,omitempty"`
+	IsActive        bool       `json:"is_active"`
+	CreatedAt       time.Time  `json:"created_at"`
+}
+
+// Cancellation policy DTOs
+type UpsertCancellationPolicyRequest struct {
+	FreeCancellationSeconds int32   `json:"free_cancellation_seconds" validate:"gte=0" example:"120"`
+	LateCancellationFee     float64 `json:"late_cancellation_fee" validate:"gte=0" example:"50.00"`
+	DriverNearbyMeters      int32   `json:"driver_nearby_meters" validate:"gte=0" example:"300"`
+	DriverArrivedFee        float64 `json:"driver_arrived_fee" validate:"gte=0" example:"100.00"`
+	RiderNoShowWaitSeconds  int32   `json:"rider_no_show_wait_seconds" validate:"gt=0" example:"300"`
+	RiderNoShowFee          float64 `json:"rider_no_show_fee" validate:"gte=0" example:"100.00"`
+	DriverNoShowWaitSeconds int32   `json:"driver_no_show_wait_seconds" validate:"gt=0" example:"900"`
+	MatchTimeoutSeconds     int32   `json:"match_timeout_seconds" validate:"gt=0" example:"600"`
+}
+
+type CancellationPolicyResponse struct {
+	CityCode                string    `json:"city_code"`
+	FreeCancellationSeconds int32     `json:"free_cancellation_seconds"`
+	LateCancellationFee     float64   `json:"late_cancellation_fee"`
+	DriverNearbyMeters      int32     `json:"driver_nearby_meters"`
+	DriverArrivedFee        float64   `json:"driver_arrived_fee"`
+	RiderNoShowWaitSeconds  int32     `json:"rider_no_show_wait_seconds"`
+	RiderNoShowFee          float64   `json:"rider_no_show_fee"`
+	DriverNoShowWaitSeconds int32     `json:"driver_no_show_wait_seconds"`
+	MatchTimeoutSeconds     int32     `json:"match_timeout_seconds"`
+	UpdatedAt               time.Time `json:"updated_at"`
+}
+
+// City DTOs
+type UpsertCityRequest struct {
+	Name     string `json:"name" validate:"required" example:"Nairobi"`
+	Currency string `json:"currency" validate:"required,len=3" example:"KES"`
+	// Timezone is an IANA zone name
+	Timezone          string  `json:"timezone" validate:"required" example:"Africa/Nairobi"`
+	BaseFare          float64 `json:"base_fare" validate:"gte=0" example:"50.00"`
+	PerKmRate         float64 `json:"per_km_rate" validate:"gte=0" example:"20.00"`
+	PerStopFee        float64 `json:"per_stop_fee" validate:"gte=0" example:"20.00"`
+	DeliveryBaseFare  float64 `json:"delivery_base_fare" validate:"gte=0" example:"40.00"`
+	DeliveryPerKmRate float64 `json:"delivery_per_km_rate" validate:"gte=0" example:"18.00"`
+	PoolingEnabled    bool    `json:"pooling_enabled" example:"true"`
+	DeliveriesEnabled bool    `json:"deliveries_enabled" example:"true"`
+	SchedulingEnabled bool    `json:"scheduling_enabled" example:"true"`
+	StopsEnabled      bool    `json:"stops_enabled" example:"true"`
+	// Active cities take bookings; inactive ones keep their history
+	Active bool `json:"active" example:"true"`
+}
+
+type CityResponse struct {
+	Code              string    `json:"code"`
+	Name              string    `json:"name"`
+	Currency          string    `json:"currency"`
+	Timezone          string    `json:"timezone"`
+	BaseFare          float64   `json:"base_fare"`
+	PerKmRate         float64   `json:"per_km_rate"`
+	PerStopFee        float64   `json:"per_stop_fee"`
+	DeliveryBaseFare  float64   `json:"delivery_base_fare"`
+	DeliveryPerKmRate float64   `json:"delivery_per_km_rate"`
+	PoolingEnabled    bool      `json:"pooling_enabled"`
+	DeliveriesEnabled bool      `json:"deliveries_enabled"`
+	SchedulingEnabled bool      `json:"scheduling_enabled"`
+	StopsEnabled      bool      `json:"stops_enabled"`
+	Active            bool      `json:"active"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+// Driver metrics DTOs
+type DriverMetricsResponse struct {
+	DriverID            string    `json:"driver_id"`
+	PeriodStart         time.Time `json:"period_start"`
+	PeriodEnd           time.Time `json:"period_end"`
+	RequestsOffered     int64     `json:"requests_offered"`
+	RequestsAccepted    int64     `json:"requests_accepted"`
+	RequestsRejected    int64     `json:"requests_rejected"`
+	RequestsExpired     int64     `json:"requests_expired"`
+	AcceptanceRate      float64   `json:"acceptance_rate"`
+	AcceptedTrips       int64     `json:"accepted_trips"`
+	CompletedTrips      int64     `json:"completed_trips"`
+	DriverCancellations int64     `json:"driver_cancellations"`
+	DriverNoShows       int64     `json:"driver_no_shows"`
+	RiderCancellations  int64     `json:"rider_cancellations"`
+	RiderNoShows        int64     `json:"rider_no_shows"`
+	CancellationRate    float64   `json:"cancellation_rate"`
+}
+
+// DriverRatingResponse summarises the ratings riders have given a driver.
+// Rating is the average of the latest RollingCount ratings; Distribution
+// counts every rating by stars.
+type DriverRatingResponse struct {
+	DriverID        string        `json:"driver_id"`
+	Rating          float64       `json:"rating"`
+	RollingCount    int32         `json:"rolling_count"`
+	LifetimeAverage float64       `json:"lifetime_average"`
+	RatingCount     int32         `json:"rating_count"`
+	Distribution    map[int]int32 `json:"distribution"`
+	TotalTrips      int64         `json:"total_trips"`
+	UpdatedAt       time.Time     `json:"updated_at"`
+}
+
+// DriverQualityResponse is a driver's latest quality score. Score runs
+// from 0 to 100 and combines the rating average, acceptance rate,
+// cancellation rate and complaints since PeriodStart.
+type DriverQualityResponse struct {
+	DriverID               string                        `json:"driver_id"`
+	FullName               string                        `json:"full_name,omitempty"`
+	Score                  float64                       `json:"score"`
+	Level                  string                        `json:"level"`
+	RatingAverage          *float64                      `json:"rating_average,omitempty"`
+	AcceptanceRate         *float64                      `json:"acceptance_rate,omitempty"`
+	CancellationRate       *float64                      `json:"cancellation_rate,omitempty"`
+	ComplaintCount         int32                         `json:"complaint_count"`
+	CompletedTrips         int32                         `json:"completed_trips"`
+	PeriodStart            time.Time                     `json:"period_start"`
+	ComputedAt             time.Time                     `json:"computed_at"`
+	DispatchSuspendedUntil *time.Time                    `json:"dispatch_suspended_until,omitempty"`
+	Actions                []DriverQualityActionResponse `json:"actions,omitempty"`
+}
+
+type DriverQualityActionResponse struct {
+	ID             string     `json:"id"`
+	Action         string     `json:"action"`
+	Score          *float64   `json:"score,omitempty"`
+	Reasons        []string   `json:"reasons,omitempty"`
+	SuspendedUntil *time.Time `json:"suspended_until,omitempty"`
+	Note           string     `json:"note,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// ReinstateDriverRequest clears a quality suspension or review.
+type ReinstateDriverRequest struct {
+	Note string `json:"note" validate:"required" example:"Reviewed trips with the driver, retraining completed"`
+}
+
+type CreateDriverComplaintRequest struct {
+	TripID      *uuid.UUID `json:"trip_id,omitempty"`
+	Category    string     `json:"category" validate:"required" example:"unsafe_driving"`
+	Description string     `json:"description" example:"Rider reported speeding on Mombasa Road"`
+}
+
+type DriverComplaintResponse struct {
+	ID          string    `json:"id"`
+	DriverID    string    `json:"driver_id"`
+	TripID      string    `json:"trip_id,omitempty"`
+	Source      string    `json:"source"`
+	Category    string    `json:"category"`
+	Description string    `json:"description,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Driver quality levels, from best to worst
+const (
+	QualityLevelGood      = "good"
+	QualityLevelWarning   = "warning"
+	QualityLevelSuspended = "suspended"
+	QualityLevelReview    = "review"
+)
+
+// Driver quality actions
+const (
+	QualityActionWarning       = "warning"
+	QualityActionSuspension    = "suspension"
+	QualityActionReview        = "review"
+	QualityActionReinstatement = "reinstatement"
+)
+
+// ComplaintCategories are what a complaint against a driver can be about.
+// Rider rating tags that are also complaint categories raise a complaint.
+var ComplaintCategories = []string{
+	"unsafe_driving", "rude", "harassment", "fraud", "vehicle_condition", "wrong_route", "other",
+}
+
+// Notification DTOs
+type RegisterDeviceRequest struct {
+	Token    string `json:"token" validate:"required" example:"fcm-or-apns-device-token"`
+	Platform string `json:"platform" validate:"required,oneof=ios android" example:"android"`
+}
+
+type DeviceResponse struct {
+	ID        string    `json:"id"`
+	Platform  string    `json:"platform"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// NotificationPreferencesRequest replaces a user's preferences. Quiet hours
+// are "HH:MM" in the user's timezone; leave both empty for none.
+type NotificationPreferencesRequest struct {
+	PushEnabled     bool     `json:"push_enabled" example:"true"`
+	SMSEnabled      bool     `json:"sms_enabled" example:"true"`
+	Locale          string   `json:"locale,omitempty" example:"sw-KE"`
+	Timezone        string   `json:"timezone,omitempty" example:"Africa/Nairobi"`
+	QuietHoursStart string   `json:"quiet_hours_start,omitempty" example:"22:00"`
+	QuietHoursEnd   string   `json:"quiet_hours_end,omitempty" example:"07:00"`
+	MutedCategories []string `json:"muted_categories,omitempty" example:"promotions"`
+}
+
+type NotificationPreferencesResponse struct {
+	PushEnabled     bool     `json:"push_enabled"`
+	SMSEnabled      bool     `json:"sms_enabled"`
+	Locale          string   `json:"locale"`
+	Timezone        string   `json:"timezone"`
+	QuietHoursStart string   `json:"quiet_hours_start,omitempty"`
+	QuietHoursEnd   string   `json:"quiet_hours_end,omitempty"`
+	MutedCategories []string `json:"muted_categories"`
+}
+
+type InboxMessageResponse struct {
+	ID        string            `json:"id"`
+	Category  string            `json:"category"`
+	Title     string            `json:"title"`
+	Body      string            `json:"body"`
+	Data      map[string]string `json:"data,omitempty"`
+	ReadAt    *time.Time        `json:"read_at,omitempty"`
+	CreatedAt time.Time         `json:"created_at"`
+}
+
+type InboxResponse struct {
+	Messages    []InboxMessageResponse `json:"messages"`
+	UnreadCount int64                  `json:"unread_count"`
+}
+
+// Notification categories. Trip and account notifications can't be muted
+// and ignore quiet hours.
+const (
+	NotificationCategoryTrip       = "trip"
+	NotificationCategoryAccount    = "account"
+	NotificationCategoryPayment    = "payment"
+	NotificationCategoryEarnings   = "earnings"
+	NotificationCategoryPromotions = "promotions"
+)
+
+// MutableNotificationCategories are the categories users may mute.
+var MutableNotificationCategories = []string{
+	NotificationCategoryPayment, NotificationCategoryEarnings, NotificationCategoryPromotions,
+}
+
+// Notification channels and device platforms
+const (
+	NotificationChannelPush  = "push"
+	NotificationChannelSMS   = "sms"
+	NotificationChannelInbox = "inbox"
+
+	DevicePlatformIOS     = "ios"
+	DevicePlatformAndroid = "android"
+)
+
+// Support DTOs
+type CreateSupportTicketRequest struct {
+	TripID      *uuid.UUID `json:"trip_id,omitempty"`
+	Category    string     `json:"category" validate:"required" example:"fare_dispute"`
+	Subject     string     `json:"subject" validate:"required,max=200" example:"Charged twice for my trip"`
+	Description string     `json:"description" validate:"required" example:"I was charged KES 850 but the app quoted KES 600"`
+}
+
+// SupportReplyRequest adds a message to a ticket. Only agents may post
+// internal notes, which the rider or driver never sees.
+type SupportReplyRequest struct {
+	Body     string `json:"body" validate:"required"`
+	Internal bool   `json:"internal,omitempty"`
+}
+
+// AssignSupportTicketRequest assigns a ticket to an agent, the caller when
+// AgentID is omitted.
+type AssignSupportTicketRequest struct {
+	AgentID *uuid.UUID `json:"agent_id,omitempty"`
+}
+
+// ResolveSupportTicketRequest closes a ticket. A refund gives Amount back
+// to the rider; a fare adjustment sets the trip's fare to Amount and
+// refunds the difference. ComplaintCategory upholds a complaint against
+// the driver on driver behaviour and safety tickets.
+type ResolveSupportTicketRequest struct {
+	Resolution        string  `json:"resolution" validate:"required" example:"refund"`
+	Amount            float64 `json:"amount,omitempty" example:"250"`
+	Note              string  `json:"note" validate:"required"`
+	ComplaintCategory string  `json:"complaint_category,omitempty" example:"rude"`
+}
+
+type SupportTicketResponse struct {
+	ID                 string     `json:"id"`
+	UserID             string     `json:"user_id"`
+	TripID             string     `json:"trip_id,omitempty"`
+	DriverID           string     `json:"driver_id,omitempty"`
+	Category           string     `json:"category"`
+	Priority           string     `json:"priority"`
+	Status             string     `json:"status"`
+	Subject            string     `json:"subject"`
+	AssignedTo         string     `json:"assigned_to,omitempty"`
+	FirstResponseDueAt time.Time  `json:"first_response_due_at"`
+	FirstRespondedAt   *time.Time `json:"first_responded_at,omitempty"`
+	ResolutionDueAt    time.Time  `json:"resolution_due_at"`
+	SLABreached        bool       `json:"sla_breached"`
+	Resolution         string     `json:"resolution,omitempty"`
+	ResolutionNote     string     `json:"resolution_note,omitempty"`
+	AdjustmentAmount   *float64   `json:"adjustment_amount,omitempty"`
+	AdjustmentStatus   string     `json:"adjustment_status,omitempty"`
+	AdjustmentError    string     `json:"adjustment_error,omitempty"`
+	ResolvedAt         *time.Time `json:"resolved_at,omitempty"`
+	CreatedAt          time.Time  `json:"created_at"`
+	UpdatedAt          time.Time  `json:"updated_at"`
+}
+
+// SupportTicketDetailResponse is a ticket with its thread. Internal notes
+// are only included for agents.
+type SupportTicketDetailResponse struct {
+	SupportTicketResponse
+	Messages    []SupportMessageResponse    `json:"messages"`
+	Attachments []SupportAttachmentResponse `json:"attachments"`
+}
+
+type SupportMessageResponse struct {
+	ID         string    `json:"id"`
+	AuthorID   string    `json:"author_id,omitempty"`
+	AuthorRole string    `json:"author_role"`
+	Body       string    `json:"body"`
+	Internal   bool      `json:"internal,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type SupportAttachmentResponse struct {
+	ID          string    `json:"id"`
+	FileName    string    `json:"file_name"`
+	ContentType string    `json:"content_type"`
+	SizeBytes   int64     `json:"size_bytes"`
+	UploadedBy  string    `json:"uploaded_by,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Support ticket categories, priorities and statuses
+const (
+	SupportCategoryFareDispute    = "fare_dispute"
+	SupportCategoryLostItem       = "lost_item"
+	SupportCategorySafety         = "safety"
+	SupportCategoryDriverBehavior = "driver_behavior"
+	SupportCategoryRiderBehavior  = "rider_behavior"
+	SupportCategoryPayment        = "payment"
+	SupportCategoryAccount        = "account"
+	SupportCategoryOther          = "other"
+
+	SupportPriorityLow    = "low"
+	SupportPriorityNormal = "normal"
+	SupportPriorityHigh   = "high"
+	SupportPriorityUrgent = "urgent"
+
+	SupportStatusOpen         = "open"
+	SupportStatusAwaitingUser = "awaiting_user"
+	SupportStatusResolved     = "resolved"
+)
+
+// SupportCategories are what a support ticket can be about.
+var SupportCategories = []string{
+	SupportCategoryFareDispute, SupportCategoryLostItem, SupportCategorySafety,
+	SupportCategoryDriverBehavior, SupportCategoryRiderBehavior, SupportCategoryPayment,
+	SupportCategoryAccount, SupportCategoryOther,
+}
+
+// Support resolutions, adjustment statuses and message authors
+const (
+	SupportResolutionNone           = "none"
+	SupportResolutionRefund         = "refund"
+	SupportResolutionFareAdjustment = "fare_adjustment"
+
+	SupportAdjustmentPending = "pending"
+	SupportAdjustmentApplied = "applied"
+	SupportAdjustmentFailed  = "failed"
+
+	SupportAuthorUser   = "user"
+	SupportAuthorDriver = "driver"
+	SupportAuthorAgent  = "agent"
+)
+
+type ReferralResponse struct {
+	Code              string  `json:"code"`
+	ReferrerReward    float64 `json:"referrer_reward"`
+	RefereeReward     float64 `json:"referee_reward"`
+	TotalReferrals    int64   `json:"total_referrals"`
+	RewardedReferrals int64   `json:"rewarded_referrals"`
+	TotalEarned       float64 `json:"total_earned"`
 }
 
 // Response DTOs
@@ -283,6 +1480,7 @@ type SuccessResponse struct {
 
 // Trip status constants
 const (
+	TripStatusScheduled  = "scheduled"
 	TripStatusPending    = "pending"
 	TripStatusRequested  = "requested"
 	TripStatusAccepted   = "accepted"
@@ -291,6 +1489,114 @@ const (
 	TripStatusCancelled  = "cancelled"
 )
 
+// Pooled ride constants
+const (
+	PoolStatusOpen      = "open"
+	PoolStatusCompleted = "completed"
+
+	PoolWaypointPickup  = "pickup"
+	PoolWaypointDropoff = "dropoff"
+)
+
+// Trip type constants
+const (
+	TripTypeRide     = "ride"
+	TripTypeDelivery = "delivery"
+)
+
+// Parcel delivery constants
+const (
+	ParcelSizeSmall  = "small"
+	ParcelSizeMedium = "medium"
+	ParcelSizeLarge  = "large"
+
+	VehicleTypeMotorbike = "motorbike"
+	VehicleTypeSedan     = "sedan"
+	VehicleTypeSUV       = "suv"
+	VehicleTypeVan       = "van"
+)
+
+// Trip chat constants
+const (
+	ChatSenderRider  = "rider"
+	ChatSenderDriver = "driver"
+
+	ChatFrameMessage = "message"
+	ChatFrameRead    = "read"
+	ChatFrameError   = "error"
+)
+
+// Safety incident kinds and statuses
+const (
+	SafetyIncidentSOS            = "sos"
+	SafetyIncidentRouteDeviation = "route_deviation"
+	SafetyIncidentLongStop       = "long_stop"
+	SafetyIncidentOverETA        = "over_eta"
+
+	SafetyIncidentStatusOpen         = "open"
+	SafetyIncidentStatusAcknowledged = "acknowledged"
+	SafetyIncidentStatusResolved     = "resolved"
+)
+
+// Geofence kinds
+const (
+	GeofenceKindServiceArea = "service_area"
+	GeofenceKindRestricted  = "restricted"
+	GeofenceKindAirport     = "airport"
+	GeofenceKindStaging     = "staging"
+)
+
+// Saved place labels
+const (
+	PlaceLabelHome     = "home"
+	PlaceLabelWork     = "work"
+	PlaceLabelFavorite = "favorite"
+)
+
+// Payment constants
+const (
+	PaymentMethodCash   = "cash"
+	PaymentMethodCard   = "card"
+	PaymentMethodWallet = "wallet"
+
+	PaymentStatusPending = "pending"
+	PaymentStatusPaid    = "paid"
+	PaymentStatusFailed  = "failed"
+)
+
+// Payout status constants
+const (
+	PayoutStatusPending    = "pending"
+	PayoutStatusProcessing = "processing"
+	PayoutStatusPaid       = "paid"
+	PayoutStatusFailed     = "failed"
+)
+
+// Incentive program kinds
+const (
+	IncentiveKindQuest         = "quest"
+	IncentiveKindPeakGuarantee = "peak_guarantee"
+	IncentiveKindAreaBoost     = "area_boost"
+)
+
+// Cancellation constants
+const (
+	CancelledByRider  = "rider"
+	CancelledByDriver = "driver"
+	CancelledBySystem = "system"
+
+	NoShowRider  = "rider"
+	NoShowDriver = "driver"
+
+	DefaultCityCode = "default"
+)
+
+// Promotion constants
+const (
+	DiscountTypePercentage = "percentage"
+	DiscountTypeFixed      = "fixed"
+)
+
 // RideRequest represents a ride request
 type RideRequest struct {
 	ID               uuid.UUID `json:"id"`
@@ -1,31 +1,80 @@
 package events
 
 import (
+	"context"
 	"encoding/json"
 	"log"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/nats-io/nats.go"
 )
 
 // Event subjects
 const (
-	SubjectUserCreated    = "user.created"
-	SubjectTripCreated    = "trip.created"
-	SubjectTripAccepted   = "trip.accepted"
-	SubjectTripStarted    = "trip.started"
-	SubjectTripCompleted  = "trip.completed"
-	SubjectTripCancelled  = "trip.cancelled"
-	SubjectDriverOnline   = "driver.online"
-	SubjectDriverOffline  = "driver.offline"
-	SubjectDriverLocation = "driver.location"
-	SubjectRatingCreated  = "rating.created"
+	SubjectUserCreated      = "user.created"
+	SubjectTripCreated      = "trip.created"
+	SubjectTripAccepted     = "trip.accepted"
+	SubjectTripStarted      = "trip.started"
+	SubjectTripCompleted    = "trip.completed"
+	SubjectTripCancelled    = "trip.cancelled"
+	SubjectDriverArrived    = "trip.driver_arrived"
+	SubjectTripScheduled    = "trip.scheduled"
+	SubjectTripReminder     = "trip.reminder"
+	SubjectTripStopArrived  = "trip.stop_arrived"
+	SubjectTripRouteUpdated = "trip.route_updated"
+	SubjectTripPoolUpdated  = "trip.pool_updated"
+	SubjectParcelPickedUp   = "trip.parcel_picked_up"
+	SubjectParcelDelivered  = "trip.parcel_delivered"
+	SubjectTripChatMessage  = "trip.chat_message"
+	SubjectTripChatRead     = "trip.chat_read"
+	SubjectDriverOnline     = "driver.online"
+	SubjectDriverOffline    = "driver.offline"
+	SubjectDriverLocation   = "driver.location"
+	SubjectGeofenceEntered  = "geofence.entered"
+	SubjectGeofenceExited   = "geofence.exited"
+	SubjectRatingCreated    = "rating.created"
+	SubjectRatingExcluded   = "rating.excluded"
+	SubjectRatingRestored   = "rating.restored"
+	SubjectDisputeResolved  = "rating.dispute_resolved"
+
+	SubjectPaymentCompleted = "payment.completed"
+	SubjectPaymentFailed    = "payment.failed"
+	SubjectWalletToppedUp   = "wallet.topped_up"
+
+	SubjectEarningsRecorded = "earnings.recorded"
+	SubjectIncentiveEarned  = "incentive.earned"
+
+	SubjectDriverQualityWarning    = "driver.quality_warning"
+	SubjectDriverQualitySuspended  = "driver.quality_suspended"
+	SubjectDriverQualityReview     = "driver.quality_review"
+	SubjectDriverQualityReinstated = "driver.quality_reinstated"
+
+	SubjectSafetySOS   = "safety.sos"
+	SubjectSafetyAlert = "safety.alert"
+
+	SubjectSupportTicketCreated     = "support.ticket_created"
+	SubjectSupportTicketReplied     = "support.ticket_replied"
+	SubjectSupportTicketResolved    = "support.ticket_resolved"
+	SubjectSupportSLABreached       = "support.sla_breached"
+	SubjectSupportAdjustmentSettled = "support.adjustment_settled"
+
+	SubjectReferralRewarded = "referral.rewarded"
+)
+
+// Message headers carrying the request an event was published under.
+// Handlers get the request ID back in their context, so whatever they
+// publish in turn is logged under the same ID.
+const (
+	HeaderRequestID = "X-Request-ID"
+	HeaderUserID    = "X-User-ID"
+	HeaderUserRole  = "X-User-Role"
 )
 
 type EventBus interface {
-	Publish(subject string, data interface{}) error
-	Subscribe(subject string, handler func([]byte)) (*nats.Subscription, error)
-	QueueSubscribe(subject, queue string, handler func([]byte)) (*nats.Subscription, error)
+	Publish(ctx context.Context, subject string, data interface{}) error
+	Subscribe(subject string, handler func(ctx context.Context, data []byte)) (*nats.Subscription, error)
+	QueueSubscribe(subject, queue string, handler func(ctx context.Context, data []byte)) (*nats.Subscription, error)
 	Close()
 }
 
@@ -53,27 +102,56 @@ func NewNATSEventBus(natsURL string) (EventBus, error) {
 	return &NATSEventBus{conn: nc}, nil
 }
 
-func (eb *NATSEventBus) Publish(subject string, data interface{}) error {
+// Publish sends data with the request ID, user and role from ctx as
+// message headers. Events published outside a request, such as by
+// workers, get a request ID of their own.
+func (eb *NATSEventBus) Publish(ctx context.Context, subject string, data interface{}) error {
 	payload, err := json.Marshal(data)
 	if err != nil {
 		return err
 	}
 
-	return eb.conn.Publish(subject, payload)
+	msg := nats.NewMsg(subject)
+	msg.Data = payload
+
+	requestID, _ := ctx.Value("request_id").(string)
+	if requestID == "" {
+		requestID = uuid.NewString()
+	}
+	msg.Header.Set(HeaderRequestID, requestID)
+	if userID, ok := ctx.Value("user_id").(string); ok && userID != "" {
+		msg.Header.Set(HeaderUserID, userID)
+	}
+	if role, ok := ctx.Value("role").(string); ok && role != "" {
+		msg.Header.Set(HeaderUserRole, role)
+	}
+
+	return eb.conn.PublishMsg(msg)
 }
 
-func (eb *NATSEventBus) Subscribe(subject string, handler func([]byte)) (*nats.Subscription, error) {
+func (eb *NATSEventBus) Subscribe(subject string, handler func(ctx context.Context, data []byte)) (*nats.Subscription, error) {
 	return eb.conn.Subscribe(subject, func(msg *nats.Msg) {
-		handler(msg.Data)
+		handler(messageContext(msg), msg.Data)
 	})
 }
 
-func (eb *NATSEventBus) QueueSubscribe(subject, queue string, handler func([]byte)) (*nats.Subscription, error) {
+func (eb *NATSEventBus) QueueSubscribe(subject, queue string, handler func(ctx context.Context, data []byte)) (*nats.Subscription, error) {
 	return eb.conn.QueueSubscribe(subject, queue, func(msg *nats.Msg) {
-		handler(msg.Data)
+		handler(messageContext(msg), msg.Data)
 	})
 }
 
+// messageContext carries a message's request ID into its handler. The
+// publisher's user is left out: a handler acts for the service, not for
+// whoever caused the event.
+func messageContext(msg *nats.Msg) context.Context {
+	ctx := context.Background()
+	if requestID := msg.Header.Get(HeaderRequestID); requestID != "" {
+		ctx = context.WithValue(ctx, "request_id", requestID)
+	}
+	return ctx
+}
+
 func (eb *NATSEventBus) Close() {
 	if eb.conn != nil {
 		eb.conn.Close()
@@ -88,6 +166,10 @@ type UserCreatedEvent struct {
 	Role        string `json:"role"`
 }
 
+// TripCreatedEvent puts a trip out for matching. VehicleTypes limits which
+// drivers may be offered it; empty means any vehicle. DriverIDs, when set,
+// are the head of an airport queue: the trip is offered to them one at a
+// time in that order instead of to the nearest drivers.
 type TripCreatedEvent struct {
 	TripID           string    `json:"trip_id"`
 	UserID           string    `json:"user_id"`
@@ -96,9 +178,37 @@ type TripCreatedEvent struct {
 	DropoffLatitude  float64   `json:"dropoff_latitude"`
 	DropoffLongitude float64   `json:"dropoff_longitude"`
 	EstimatedFare    *float64  `json:"estimated_fare,omitempty"`
+	TripType         string    `json:"trip_type"`
+	VehicleTypes     []string  `json:"vehicle_types,omitempty"`
+	DriverIDs        []string  `json:"driver_ids,omitempty"`
+	CityCode         string    `json:"city_code"`
+	Currency         string    `json:"currency"`
 	CreatedAt        time.Time `json:"created_at"`
 }
 
+type TripScheduledEvent struct {
+	TripID         string    `json:"trip_id"`
+	UserID         string    `json:"user_id"`
+	PickupAddress  string    `json:"pickup_address"`
+	DropoffAddress string    `json:"dropoff_address"`
+	EstimatedFare  float64   `json:"estimated_fare"`
+	Currency       string    `json:"currency"`
+	PickupAt       time.Time `json:"pickup_at"`
+	// Timezone is the IANA zone of the trip's city, for showing the pickup
+	// time in local time
+	Timezone  string    `json:"timezone"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+type TripReminderEvent struct {
+	TripID           string    `json:"trip_id"`
+	UserID           string    `json:"user_id"`
+	ReservedDriverID string    `json:"reserved_driver_id,omitempty"`
+	PickupAddress    string    `json:"pickup_address"`
+	PickupAt         time.Time `json:"pickup_at"`
+	Timestamp        time.Time `json:"timestamp"`
+}
+
 type TripAcceptedEvent struct {
 	TripID    string    `json:"trip_id"`
 	DriverID  string    `json:"driver_id"`
@@ -114,13 +224,111 @@ type TripStartedEvent struct {
 }
 
 type TripCompletedEvent struct {
-	TripID         string    `json:"trip_id"`
-	DriverID       string    `json:"driver_id"`
-	ActualFare     float64   `json:"actual_fare"`
-	ActualDuration int       `json:"actual_duration"`
-	PaymentStatus  string    `json:"payment_status"`
-	CompletedAt    time.Time `json:"completed_at"`
-	Timestamp      time.Time `json:"timestamp"`
+	TripID          string    `json:"trip_id"`
+	DriverID        string    `json:"driver_id"`
+	UserID          string    `json:"user_id"`
+	ActualFare      float64   `json:"actual_fare"`
+	ActualDuration  int       `json:"actual_duration"`
+	Tip             float64   `json:"tip"`
+	Discount        float64   `json:"discount"`
+	PaymentMethod   string    `json:"payment_method"`
+	PaymentStatus   string    `json:"payment_status"`
+	CityCode        string    `json:"city_code"`
+	Currency        string    `json:"currency"`
+	PickupLatitude  float64   `json:"pickup_latitude"`
+	PickupLongitude float64   `json:"pickup_longitude"`
+	CompletedAt     time.Time `json:"completed_at"`
+	Timestamp       time.Time `json:"timestamp"`
+}
+
+type TripCancelledEvent struct {
+	TripID          string    `json:"trip_id"`
+	UserID          string    `json:"user_id"`
+	DriverID        string    `json:"driver_id,omitempty"`
+	CancelledBy     string    `json:"cancelled_by"`
+	NoShowParty     string    `json:"no_show_party,omitempty"`
+	Reason          string    `json:"reason"`
+	CancellationFee float64   `json:"cancellation_fee"`
+	PaymentMethod   string    `json:"payment_method"`
+	CityCode        string    `json:"city_code"`
+	Currency        string    `json:"currency"`
+	Timestamp       time.Time `json:"timestamp"`
+}
+
+type DriverArrivedEvent struct {
+	TripID          string    `json:"trip_id"`
+	UserID          string    `json:"user_id"`
+	DriverID        string    `json:"driver_id"`
+	WaitTimeSeconds int       `json:"wait_time_seconds"`
+	Timestamp       time.Time `json:"timestamp"`
+}
+
+type TripStopArrivedEvent struct {
+	TripID    string    `json:"trip_id"`
+	UserID    string    `json:"user_id"`
+	DriverID  string    `json:"driver_id"`
+	StopID    string    `json:"stop_id"`
+	StopOrder int       `json:"stop_order"`
+	Address   string    `json:"address"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// TripRouteUpdatedEvent is published when a rider adds or removes a stop and
+// the trip is re-priced.
+type TripRouteUpdatedEvent struct {
+	TripID            string    `json:"trip_id"`
+	UserID            string    `json:"user_id"`
+	DriverID          string    `json:"driver_id,omitempty"`
+	StopCount         int       `json:"stop_count"`
+	Distance          float64   `json:"distance"`
+	EstimatedDuration int       `json:"estimated_duration"`
+	EstimatedFare     float64   `json:"estimated_fare"`
+	Timestamp         time.Time `json:"timestamp"`
+}
+
+// TripPoolUpdatedEvent tells a pool's driver that riders joined or left and
+// the pickup and dropoff order changed.
+type TripPoolUpdatedEvent struct {
+	PoolID        string    `json:"pool_id"`
+	DriverID      string    `json:"driver_id"`
+	TripID        string    `json:"trip_id"`
+	Change        string    `json:"change"`
+	SeatsBooked   int       `json:"seats_booked"`
+	WaypointCount int       `json:"waypoint_count"`
+	Timestamp     time.Time `json:"timestamp"`
+}
+
+// TripParcelEvent records proof of pickup or delivery for a delivery trip.
+type TripParcelEvent struct {
+	TripID    string    `json:"trip_id"`
+	UserID    string    `json:"user_id"`
+	DriverID  string    `json:"driver_id"`
+	PhotoRef  string    `json:"photo_ref"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// TripChatMessageEvent is published for each message sent in a trip's chat.
+// Every trip-service instance receives it, so the message reaches the
+// recipient whichever instance their WebSocket is connected to.
+type TripChatMessageEvent struct {
+	MessageID   string    `json:"message_id"`
+	TripID      string    `json:"trip_id"`
+	SenderID    string    `json:"sender_id"`
+	SenderRole  string    `json:"sender_role"`
+	RecipientID string    `json:"recipient_id"`
+	Body        string    `json:"body"`
+	QuickReply  string    `json:"quick_reply,omitempty"`
+	ClientID    string    `json:"client_id,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// TripChatReadEvent is published when a rider or driver reads the other's
+// messages up to MessageID.
+type TripChatReadEvent struct {
+	TripID    string    `json:"trip_id"`
+	ReaderID  string    `json:"reader_id"`
+	MessageID string    `json:"message_id"`
+	ReadAt    time.Time `json:"read_at"`
 }
 
 type TripStatusEvent struct {
@@ -137,6 +345,16 @@ type DriverLocationEvent struct {
 	Timestamp time.Time `json:"timestamp"`
 }
 
+// GeofenceEvent is published when a driver's reported location enters or
+// leaves a geofence. Going offline exits every geofence.
+type GeofenceEvent struct {
+	DriverID   string    `json:"driver_id"`
+	GeofenceID string    `json:"geofence_id"`
+	Kind       string    `json:"kind"`
+	CityCode   string    `json:"city_code"`
+	Timestamp  time.Time `json:"timestamp"`
+}
+
 type DriverStatusEvent struct {
 	DriverID  string    `json:"driver_id"`
 	UserID    string    `json:"user_id"`
@@ -153,12 +371,210 @@ type RatingCreatedEvent struct {
 	Rating   int    `json:"rating"`
 }
 
+// RatingModeratedEvent is published when an admin excludes a rating from
+// averages, or restores one, so aggregates kept elsewhere can be recomputed.
+type RatingModeratedEvent struct {
+	RatingID  string    `json:"rating_id"`
+	RatedID   string    `json:"rated_id"`
+	RaterType string    `json:"rater_type"`
+	Reason    string    `json:"reason,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+type RatingDisputeResolvedEvent struct {
+	DisputeID  string    `json:"dispute_id"`
+	RatingID   string    `json:"rating_id"`
+	DisputedBy string    `json:"disputed_by"`
+	Status     string    `json:"status"`
+	Note       string    `json:"note,omitempty"`
+	Timestamp  time.Time `json:"timestamp"`
+}
+
+type PaymentCompletedEvent struct {
+	TripID        string    `json:"trip_id"`
+	UserID        string    `json:"user_id"`
+	TransactionID string    `json:"transaction_id"`
+	Amount        float64   `json:"amount"`
+	PaymentMethod string    `json:"payment_method"`
+	Timestamp     time.Time `json:"timestamp"`
+}
+
+type PaymentFailedEvent struct {
+	TripID        string    `json:"trip_id"`
+	UserID        string    `json:"user_id"`
+	Amount        float64   `json:"amount"`
+	PaymentMethod string    `json:"payment_method"`
+	Reason        string    `json:"reason"`
+	Timestamp     time.Time `json:"timestamp"`
+}
+
+type WalletToppedUpEvent struct {
+	UserID        string    `json:"user_id"`
+	TransactionID string    `json:"transaction_id"`
+	Amount        float64   `json:"amount"`
+	Balance       float64   `json:"balance"`
+	Timestamp     time.Time `json:"timestamp"`
+}
+
+type EarningsRecordedEvent struct {
+	TripID      string    `json:"trip_id"`
+	DriverID    string    `json:"driver_id"`
+	GrossFare   float64   `json:"gross_fare"`
+	Commission  float64   `json:"commission"`
+	Tax         float64   `json:"tax"`
+	Tip         float64   `json:"tip"`
+	NetEarnings float64   `json:"net_earnings"`
+	Currency    string    `json:"currency"`
+	Timestamp   time.Time `json:"timestamp"`
+}
+
+// DriverQualityEvent is published when the quality check warns, suspends
+// or deactivates a driver, and when an admin reinstates one.
+type DriverQualityEvent struct {
+	DriverID       string     `json:"driver_id"`
+	Action         string     `json:"action"`
+	Score          float64    `json:"score"`
+	Reasons        []string   `json:"reasons,omitempty"`
+	SuspendedUntil *time.Time `json:"suspended_until,omitempty"`
+	Note           string     `json:"note,omitempty"`
+	Timestamp      time.Time  `json:"timestamp"`
+}
+
+// SafetySOSEvent is published when a rider or driver raises an SOS on a
+// trip. Contacts are the reporter's emergency contacts, to be sent
+// ShareURL so they can follow the trip.
+type SafetySOSEvent struct {
+	IncidentID   string                    `json:"incident_id"`
+	TripID       string                    `json:"trip_id"`
+	ReporterID   string                    `json:"reporter_id"`
+	ReporterRole string                    `json:"reporter_role"`
+	Latitude     *float64                  `json:"latitude,omitempty"`
+	Longitude    *float64                  `json:"longitude,omitempty"`
+	ShareURL     string                    `json:"share_url"`
+	Contacts     []EmergencyContactPayload `json:"contacts"`
+	Timestamp    time.Time                 `json:"timestamp"`
+}
+
+type EmergencyContactPayload struct {
+	Name        string `json:"name"`
+	PhoneNumber string `json:"phone_number"`
+}
+
+// SafetyAlertEvent is published when a trip under way looks wrong: the
+// driver has left the planned route, stopped for too long, or the trip is
+// running far over its estimate.
+type SafetyAlertEvent struct {
+	IncidentID string    `json:"incident_id"`
+	TripID     string    `json:"trip_id"`
+	UserID     string    `json:"user_id"`
+	DriverID   string    `json:"driver_id"`
+	Kind       string    `json:"kind"`
+	Details    string    `json:"details"`
+	Latitude   float64   `json:"latitude"`
+	Longitude  float64   `json:"longitude"`
+	Timestamp  time.Time `json:"timestamp"`
+}
+
+// SupportTicketCreatedEvent is published when a rider or driver opens a
+// support ticket. DriverID is set on lost item reports, so the driver can
+// be told.
+type SupportTicketCreatedEvent struct {
+	TicketID  string    `json:"ticket_id"`
+	UserID    string    `json:"user_id"`
+	TripID    string    `json:"trip_id,omitempty"`
+	DriverID  string    `json:"driver_id,omitempty"`
+	Category  string    `json:"category"`
+	Priority  string    `json:"priority"`
+	Subject   string    `json:"subject"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// SupportTicketRepliedEvent is published when a message is added to a
+// ticket that its recipients can see. Internal notes aren't published.
+type SupportTicketRepliedEvent struct {
+	TicketID     string    `json:"ticket_id"`
+	MessageID    string    `json:"message_id"`
+	AuthorRole   string    `json:"author_role"`
+	RecipientIDs []string  `json:"recipient_ids"`
+	Subject      string    `json:"subject"`
+	Timestamp    time.Time `json:"timestamp"`
+}
+
+// SupportTicketResolvedEvent is published when an agent resolves a ticket.
+// A refund or fare adjustment is applied by payment-service, which answers
+// with a SupportAdjustmentSettledEvent, and Paid is what the rider paid for
+// the trip, which all credits on it together are capped at. ComplaintCategory
+// is set when the agent upheld a complaint against the driver.
+type SupportTicketResolvedEvent struct {
+	TicketID          string    `json:"ticket_id"`
+	UserID            string    `json:"user_id"`
+	TripID            string    `json:"trip_id,omitempty"`
+	DriverID          string    `json:"driver_id,omitempty"`
+	Category          string    `json:"category"`
+	Resolution        string    `json:"resolution"`
+	Amount            float64   `json:"amount,omitempty"`
+	Currency          string    `json:"currency,omitempty"`
+	Paid              float64   `json:"paid,omitempty"`
+	ComplaintCategory string    `json:"complaint_category,omitempty"`
+	Note              string    `json:"note"`
+	ResolvedBy        string    `json:"resolved_by"`
+	Timestamp         time.Time `json:"timestamp"`
+}
+
+// SupportAdjustmentSettledEvent reports whether the refund or fare
+// adjustment of a resolved ticket made it into the ledger.
+type SupportAdjustmentSettledEvent struct {
+	TicketID      string    `json:"ticket_id"`
+	UserID        string    `json:"user_id"`
+	Resolution    string    `json:"resolution"`
+	TransactionID string    `json:"transaction_id,omitempty"`
+	Amount        float64   `json:"amount"`
+	Currency      string    `json:"currency"`
+	Error         string    `json:"error,omitempty"`
+	Timestamp     time.Time `json:"timestamp"`
+}
+
+// SupportSLABreachedEvent is published once for each deadline a ticket
+// misses, either "first_response" or "resolution".
+type SupportSLABreachedEvent struct {
+	TicketID   string    `json:"ticket_id"`
+	AssignedTo string    `json:"assigned_to,omitempty"`
+	Category   string    `json:"category"`
+	Priority   string    `json:"priority"`
+	Deadline   string    `json:"deadline"`
+	DueAt      time.Time `json:"due_at"`
+	Timestamp  time.Time `json:"timestamp"`
+}
+
+// IncentiveEarnedEvent is published when a bonus is credited to a driver's
+// earnings. TripID is set for area boosts, which are paid per trip.
+type IncentiveEarnedEvent struct {
+	ProgramID string    `json:"program_id"`
+	DriverID  string    `json:"driver_id"`
+	Kind      string    `json:"kind"`
+	TripID    string    `json:"trip_id,omitempty"`
+	Amount    float64   `json:"amount"`
+	Currency  string    `json:"currency"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+type ReferralRewardedEvent struct {
+	ReferralID     string    `json:"referral_id"`
+	ReferrerID     string    `json:"referrer_id"`
+	RefereeID      string    `json:"referee_id"`
+	TripID         string    `json:"trip_id"`
+	ReferrerReward float64   `json:"referrer_reward"`
+	RefereeReward  float64   `json:"referee_reward"`
+	Timestamp      time.Time `json:"timestamp"`
+}
+
 // Additional payload types for compatibility
 type UserCreatedPayload struct {
-	UserID      string    `json:"user_id"`
-	PhoneNumber string    `json:"phone_number"`
-	Role        string    `json:"role"`
-	CreatedAt   time.Time `json:"created_at"`
+	UserID       string    `json:"user_id"`
+	PhoneNumber  string    `json:"phone_number"`
+	Role         string    `json:"role"`
+	ReferralCode string    `json:"referral_code,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
 }
 
 type DriverStatusPayload struct {
@@ -173,9 +589,11 @@ type DriverLocationPayload struct {
 }
 
 type RatingCreatedPayload struct {
-	RatingID  string `json:"rating_id"`
-	TripID    string `json:"trip_id"`
-	RatedID   string `json:"rated_id"`
-	RaterType string `json:"rater_type"`
-	Rating    int32  `json:"rating"`
+	RatingID  string   `json:"rating_id"`
+	TripID    string   `json:"trip_id"`
+	RaterID   string   `json:"rater_id"`
+	RatedID   string   `json:"rated_id"`
+	RaterType string   `json:"rater_type"`
+	Rating    int32    `json:"rating"`
+	Tags      []string `json:"tags,omitempty"`
 }
@@ -0,0 +1,213 @@
+package geocoding
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/namycodes/yanga-services/shared-lib/routing"
+)
+
+// Gazetteer is an offline Provider over a fixed list of places, such as the
+// landmarks, streets and estates of the cities we operate in. It is small
+// enough to scan in full on every lookup.
+type Gazetteer struct {
+	entries  []gazetteerEntry
+	radiusKm float64
+}
+
+type gazetteerEntry struct {
+	place      Place
+	name       string   // normalised name
+	nameTokens []string // tokens of the name
+	tokens     []string // tokens of the name and address
+}
+
+// NewGazetteer returns a gazetteer over places. Reverse lookups match the
+// nearest place within radiusKm.
+func NewGazetteer(places []Place, radiusKm float64) *Gazetteer {
+	g := &Gazetteer{radiusKm: radiusKm}
+	for _, place := range places {
+		g.Add(place)
+	}
+	return g
+}
+
+func (g *Gazetteer) Len() int {
+	return len(g.entries)
+}
+
+func (g *Gazetteer) Add(place Place) {
+	if place.Address == "" {
+		place.Address = place.Name
+	}
+	nameTokens := tokenize(place.Name)
+	g.entries = append(g.entries, gazetteerEntry{
+		place:      place,
+		name:       strings.Join(nameTokens, " "),
+		nameTokens: nameTokens,
+		tokens:     append(append([]string(nil), nameTokens...), tokenize(place.Address)...),
+	})
+}
+
+// LoadGazetteer reads a gazetteer from a CSV file with the header
+// name,kind,address,latitude,longitude.
+func LoadGazetteer(path string, radiusKm float64) (*Gazetteer, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadGazetteer(f, radiusKm)
+}
+
+// ReadGazetteer reads a gazetteer from CSV. See LoadGazetteer.
+func ReadGazetteer(r io.Reader, radiusKm float64) (*Gazetteer, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 5
+	reader.TrimLeadingSpace = true
+
+	if _, err := reader.Read(); err != nil {
+		return nil, fmt.Errorf("failed to read gazetteer header: %w", err)
+	}
+
+	g := NewGazetteer(nil, radiusKm)
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read gazetteer: %w", err)
+		}
+		line, _ := reader.FieldPos(0)
+
+		lat, err := strconv.ParseFloat(record[3], 64)
+		if err != nil || lat < -90 || lat > 90 {
+			return nil, fmt.Errorf("invalid latitude %q on line %d", record[3], line)
+		}
+		lng, err := strconv.ParseFloat(record[4], 64)
+		if err != nil || lng < -180 || lng > 180 {
+			return nil, fmt.Errorf("invalid longitude %q on line %d", record[4], line)
+		}
+		if strings.TrimSpace(record[0]) == "" {
+			return nil, fmt.Errorf("missing name on line %d", line)
+		}
+
+		g.Add(Place{
+			Name:    strings.TrimSpace(record[0]),
+			Kind:    strings.TrimSpace(record[1]),
+			Address: strings.TrimSpace(record[2]),
+			Point:   routing.Point{Lat: lat, Lng: lng},
+		})
+	}
+	return g, nil
+}
+
+// Search matches places whose name or address has a word starting with
+// each word of the query, so partly typed queries match as the user types.
+// Names starting with the query rank first, then places matched on their
+// name alone, then those matched on their address.
+func (g *Gazetteer) Search(ctx context.Context, query string, near *routing.Point, limit int) ([]Place, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	words := tokenize(query)
+	if len(words) == 0 || limit <= 0 {
+		return []Place{}, nil
+	}
+	prefix := strings.Join(words, " ")
+
+	type match struct {
+		entry    *gazetteerEntry
+		rank     int
+		distance float64
+	}
+	var matches []match
+	for i := range g.entries {
+		entry := &g.entries[i]
+		if !containsPrefixes(entry.tokens, words) {
+			continue
+		}
+
+		m := match{entry: entry, rank: 2}
+		switch {
+		case strings.HasPrefix(entry.name, prefix):
+			m.rank = 0
+		case containsPrefixes(entry.nameTokens, words):
+			m.rank = 1
+		}
+		if near != nil {
+			m.distance = routing.Distance(*near, entry.place.Point)
+		}
+		matches = append(matches, m)
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+		if a.rank != b.rank {
+			return a.rank < b.rank
+		}
+		if a.distance != b.distance {
+			return a.distance < b.distance
+		}
+		return a.entry.place.Name < b.entry.place.Name
+	})
+
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+	places := make([]Place, len(matches))
+	for i, m := range matches {
+		places[i] = m.entry.place
+	}
+	return places, nil
+}
+
+func (g *Gazetteer) Reverse(ctx context.Context, at routing.Point) (Place, error) {
+	if err := ctx.Err(); err != nil {
+		return Place{}, err
+	}
+	best, bestDistance := -1, math.Inf(1)
+	for i, entry := range g.entries {
+		if d := routing.Distance(at, entry.place.Point); d < bestDistance {
+			best, bestDistance = i, d
+		}
+	}
+	if best < 0 || bestDistance > g.radiusKm {
+		return Place{}, ErrNotFound
+	}
+	return g.entries[best].place, nil
+}
+
+// containsPrefixes reports whether every word starts one of the tokens.
+func containsPrefixes(tokens, words []string) bool {
+	for _, word := range words {
+		found := false
+		for _, token := range tokens {
+			if strings.HasPrefix(token, word) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// tokenize lowercases s and splits it into words, dropping punctuation.
+func tokenize(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
@@ -0,0 +1,75 @@
+// Package geocoding turns addresses into coordinates and back: autocomplete
+// search for places, reverse lookups of where a point is, and checks that an
+// address a client sent belongs to the coordinates sent with it.
+package geocoding
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/namycodes/yanga-services/shared-lib/config"
+	"github.com/namycodes/yanga-services/shared-lib/routing"
+)
+
+var ErrNotFound = errors.New("no place found")
+
+// Place is a named location.
+type Place struct {
+	Name    string
+	Kind    string // e.g. "landmark", "street", "area"
+	Address string
+	Point   routing.Point
+}
+
+// Provider looks places up by name and by position.
+type Provider interface {
+	// Search returns up to limit places matching a free-text query, best
+	// match first. Places near the bias point, when given, rank ahead of
+	// equally good matches further away.
+	Search(ctx context.Context, query string, near *routing.Point, limit int) ([]Place, error)
+	// Reverse returns the known place nearest a point, or ErrNotFound if
+	// there is none close enough.
+	Reverse(ctx context.Context, at routing.Point) (Place, error)
+}
+
+// New returns a provider over the gazetteer configured in
+// GEOCODING_GAZETTEER_PATH. Without one, or if it can't be loaded, nothing
+// is found: searches come back empty and reverse lookups fail.
+func New(cfg *config.Config) Provider {
+	radiusKm := float64(cfg.GeocodingReverseRadiusMeters) / 1000
+	if cfg.GeocodingGazetteerPath == "" {
+		log.Printf("No gazetteer configured, geocoding is disabled")
+		return NewGazetteer(nil, radiusKm)
+	}
+
+	gazetteer, err := LoadGazetteer(cfg.GeocodingGazetteerPath, radiusKm)
+	if err != nil {
+		log.Printf("Failed to load gazetteer %s, geocoding is disabled: %v", cfg.GeocodingGazetteerPath, err)
+		return NewGazetteer(nil, radiusKm)
+	}
+	log.Printf("Loaded gazetteer %s: %d places", cfg.GeocodingGazetteerPath, gazetteer.Len())
+	return gazetteer
+}
+
+// Corresponds reports whether address plausibly describes the location at.
+// It does when any place the address geocodes to lies within toleranceKm of
+// the point. Addresses the provider knows nothing about, such as a house
+// number it has no record of, can't be disproved and are accepted.
+func Corresponds(ctx context.Context, provider Provider, address string, at routing.Point, toleranceKm float64) (bool, error) {
+	// Candidates come back nearest first among equal matches, so a handful
+	// is enough to find one close by if it exists.
+	candidates, err := provider.Search(ctx, address, &at, 5)
+	if err != nil {
+		return false, err
+	}
+	if len(candidates) == 0 {
+		return true, nil
+	}
+	for _, place := range candidates {
+		if routing.Distance(place.Point, at) <= toleranceKm {
+			return true, nil
+		}
+	}
+	return false, nil
+}
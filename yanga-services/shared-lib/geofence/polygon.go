@@ -0,0 +1,91 @@
+// Package geofence tests points against the polygons drawn around service
+// areas and zones.
+package geofence
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/namycodes/yanga-services/shared-lib/routing"
+)
+
+// MaxVertices caps the size of a single polygon.
+const MaxVertices = 500
+
+var ErrInvalidPolygon = errors.New("invalid polygon")
+
+// Polygon is a simple polygon given by its vertices in order. The last
+// vertex joins back to the first; repeating the first vertex at the end is
+// allowed but not needed.
+type Polygon []routing.Point
+
+// Validate checks the polygon has enough distinct, in-range vertices to
+// enclose an area.
+func (p Polygon) Validate() error {
+	ring := p.ring()
+	if len(ring) < 3 {
+		return fmt.Errorf("%w: at least 3 vertices are required", ErrInvalidPolygon)
+	}
+	if len(ring) > MaxVertices {
+		return fmt.Errorf("%w: at most %d vertices are allowed", ErrInvalidPolygon, MaxVertices)
+	}
+	for _, v := range ring {
+		if v.Lat < -90 || v.Lat > 90 || v.Lng < -180 || v.Lng > 180 {
+			return fmt.Errorf("%w: vertex %.6f,%.6f is out of range", ErrInvalidPolygon, v.Lat, v.Lng)
+		}
+	}
+	if p.area() == 0 {
+		return fmt.Errorf("%w: vertices don't enclose an area", ErrInvalidPolygon)
+	}
+	return nil
+}
+
+// Contains reports whether pt lies inside the polygon, by counting how many
+// edges a ray cast east from it crosses.
+func (p Polygon) Contains(pt routing.Point) bool {
+	ring := p.ring()
+	inside := false
+	for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+		a, b := ring[i], ring[j]
+		if (a.Lat > pt.Lat) == (b.Lat > pt.Lat) {
+			continue
+		}
+		crossLng := a.Lng + (pt.Lat-a.Lat)/(b.Lat-a.Lat)*(b.Lng-a.Lng)
+		if pt.Lng < crossLng {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// Bounds returns the south-west and north-east corners of the polygon's
+// bounding box.
+func (p Polygon) Bounds() (routing.Point, routing.Point) {
+	min := routing.Point{Lat: math.Inf(1), Lng: math.Inf(1)}
+	max := routing.Point{Lat: math.Inf(-1), Lng: math.Inf(-1)}
+	for _, v := range p {
+		min.Lat, min.Lng = math.Min(min.Lat, v.Lat), math.Min(min.Lng, v.Lng)
+		max.Lat, max.Lng = math.Max(max.Lat, v.Lat), math.Max(max.Lng, v.Lng)
+	}
+	return min, max
+}
+
+// ring drops a closing vertex that repeats the first.
+func (p Polygon) ring() Polygon {
+	if n := len(p); n > 1 && p[0] == p[n-1] {
+		return p[:n-1]
+	}
+	return p
+}
+
+// area returns the polygon's unsigned area in square degrees, which is only
+// good for telling degenerate polygons apart.
+func (p Polygon) area() float64 {
+	ring := p.ring()
+	sum := 0.0
+	for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+		sum += ring[j].Lng*ring[i].Lat - ring[i].Lng*ring[j].Lat
+	}
+	return math.Abs(sum) / 2
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/namycodes/yanga-services/shared-lib/utils"
 )
@@ -12,22 +13,38 @@ type contextKey string
 
 const UserContextKey contextKey = "user"
 
-func AuthMiddleware(jwtSecret string) func(http.Handler) http.Handler {
+// AuthMiddleware authenticates a request by the identity headers the
+// gateway signed with identitySecret. Requests that don't come through the
+// gateway have their bearer token validated here instead.
+func AuthMiddleware(jwtSecret, identitySecret string) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get(HeaderIdentitySignature) != "" {
+				userID, role, err := VerifyIdentity(r.Header, identitySecret, time.Now())
+				if err != nil {
+					utils.ErrorResponse(w, http.StatusUnauthorized, "Invalid identity headers")
+					return
+				}
+
+				ctx := context.WithValue(r.Context(), "user_id", userID)
+				ctx = context.WithValue(ctx, "role", role)
+				next.ServeHTTP(w, r.WithContext(ctx))
+				return
+			}
+
 			authHeader := r.Header.Get("Authorization")
 			if authHeader == "" {
 				utils.ErrorResponse(w, http.StatusUnauthorized, "Authorization header required")
 				return
 			}
 
-			bearerToken := strings.Split(authHeader, " ")
-			if len(bearerToken) != 2 || bearerToken[0] != "Bearer" {
+			token, ok := BearerToken(authHeader)
+			if !ok {
 				utils.ErrorResponse(w, http.StatusUnauthorized, "Invalid authorization header format")
 				return
 			}
 
-			claims, err := utils.ValidateJWT(bearerToken[1], jwtSecret)
+			claims, err := utils.ValidateJWT(token, jwtSecret)
 			if err != nil {
 				utils.ErrorResponse(w, http.StatusUnauthorized, "Invalid or expired token")
 				return
@@ -40,6 +57,32 @@ func AuthMiddleware(jwtSecret string) func(http.Handler) http.Handler {
 	}
 }
 
+// BearerToken returns the token from an "Authorization: Bearer" header.
+func BearerToken(authHeader string) (string, bool) {
+	bearerToken := strings.Split(authHeader, " ")
+	if len(bearerToken) != 2 || bearerToken[0] != "Bearer" {
+		return "", false
+	}
+	return bearerToken[1], true
+}
+
+// RequireRole rejects requests whose authenticated role is not one of roles.
+// It must be chained after AuthMiddleware.
+func RequireRole(roles ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			role := GetUserRole(r.Context())
+			for _, allowed := range roles {
+				if role == allowed {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+			utils.ErrorResponse(w, http.StatusForbidden, "Insufficient permissions")
+		})
+	}
+}
+
 func GetUserID(ctx context.Context) string {
 	userID, ok := ctx.Value("user_id").(string)
 	if !ok {
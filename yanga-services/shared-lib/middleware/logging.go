@@ -1,7 +1,10 @@
 package middleware
 
 import (
+	"bufio"
+	"errors"
 	"log"
+	"net"
 	"net/http"
 	"time"
 )
@@ -10,8 +13,10 @@ func LoggingMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
 
+		requestID := GetRequestID(r.Context())
+
 		// Log request
-		log.Printf("Started %s %s", r.Method, r.URL.Path)
+		log.Printf("Started %s %s [%s]", r.Method, r.URL.Path, requestID)
 
 		// Create a custom ResponseWriter to capture the status code
 		rw := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
@@ -20,7 +25,7 @@ func LoggingMiddleware(next http.Handler) http.Handler {
 		next.ServeHTTP(rw, r)
 
 		// Log response
-		log.Printf("Completed %s %s %d in %v", r.Method, r.URL.Path, rw.statusCode, time.Since(start))
+		log.Printf("Completed %s %s %d in %v [%s]", r.Method, r.URL.Path, rw.statusCode, time.Since(start), requestID)
 	})
 }
 
@@ -33,3 +38,19 @@ func (rw *responseWriter) WriteHeader(code int) {
 	rw.statusCode = code
 	rw.ResponseWriter.WriteHeader(code)
 }
+
+// Hijack lets WebSocket handlers behind the logger take over the
+// connection.
+func (rw *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := rw.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("response writer does not support hijacking")
+	}
+	rw.statusCode = http.StatusSwitchingProtocols
+	return hijacker.Hijack()
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController.
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
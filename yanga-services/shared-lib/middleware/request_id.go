@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+// HeaderRequestID carries the ID a request is logged under. The gateway
+// assigns it and every service passes it on, including into the events it
+// publishes.
+const HeaderRequestID = "X-Request-ID"
+
+const maxRequestIDLength = 128
+
+// RequestIDMiddleware keeps the request's X-Request-ID, or assigns a new
+// one when it is missing or malformed, and echoes it on the response.
+// It must run before LoggingMiddleware.
+func RequestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(HeaderRequestID)
+		if !validRequestID(requestID) {
+			requestID = uuid.NewString()
+			r.Header.Set(HeaderRequestID, requestID)
+		}
+		w.Header().Set(HeaderRequestID, requestID)
+
+		ctx := context.WithValue(r.Context(), "request_id", requestID)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func GetRequestID(ctx context.Context) string {
+	requestID, ok := ctx.Value("request_id").(string)
+	if !ok {
+		return ""
+	}
+	return requestID
+}
+
+// validRequestID accepts IDs of letters, digits, '-', '_' and '.', so a
+// client-supplied one can't break log lines or headers.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-', c == '_', c == '.':
+		default:
+			return false
+		}
+	}
+	return true
+}
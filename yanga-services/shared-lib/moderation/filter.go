@@ -0,0 +1,138 @@
+// Package moderation screens free text users send each other, such as
+// rating feedback, for abuse and for contact details that should stay
+// private.
+package moderation
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/namycodes/yanga-services/shared-lib/config"
+)
+
+// Reasons text can be flagged for
+const (
+	ReasonAbuse          = "abuse"
+	ReasonContactDetails = "contact_details"
+)
+
+// Verdict is the outcome of screening a piece of text. Reasons is empty
+// unless Flagged.
+type Verdict struct {
+	Flagged bool
+	Reasons []string
+}
+
+// Filter screens text. Implementations may call out to an external
+// moderation service, so Check takes a context and can fail.
+type Filter interface {
+	Check(ctx context.Context, text string) (Verdict, error)
+}
+
+// defaultWords is used when no word list is configured.
+var defaultWords = []string{
+	"asshole", "bastard", "bitch", "bullshit", "crap", "dickhead", "dumbass",
+	"fuck", "fucker", "fucking", "idiot", "moron", "motherfucker", "pig",
+	"scum", "shit", "stupid", "useless", "wanker",
+}
+
+// New returns a word list filter over MODERATION_WORDLIST_PATH, or over a
+// built-in list when none is configured or it can't be loaded.
+func New(cfg *config.Config) Filter {
+	if cfg.ModerationWordListPath == "" {
+		return NewWordList(defaultWords)
+	}
+
+	words, err := loadWords(cfg.ModerationWordListPath)
+	if err != nil {
+		log.Printf("Failed to load word list %s, using the built-in list: %v", cfg.ModerationWordListPath, err)
+		return NewWordList(defaultWords)
+	}
+	log.Printf("Loaded word list %s: %d words", cfg.ModerationWordListPath, len(words))
+	return NewWordList(words)
+}
+
+// WordList flags text containing any of a set of words, after undoing
+// common letter substitutions such as "1d10t", and text containing a phone
+// number or email address.
+type WordList struct {
+	words map[string]bool
+}
+
+func NewWordList(words []string) *WordList {
+	w := &WordList{words: make(map[string]bool, len(words))}
+	for _, word := range words {
+		if word = normalize(word); word != "" {
+			w.words[word] = true
+		}
+	}
+	return w
+}
+
+var (
+	phonePattern = regexp.MustCompile(`\+?\d[\d\s().-]{7,}\d`)
+	emailPattern = regexp.MustCompile(`[^\s@]+@[^\s@]+\.[a-zA-Z]{2,}`)
+)
+
+func (w *WordList) Check(ctx context.Context, text string) (Verdict, error) {
+	if err := ctx.Err(); err != nil {
+		return Verdict{}, err
+	}
+
+	var verdict Verdict
+	for _, token := range strings.FieldsFunc(normalize(text), func(r rune) bool { return !unicode.IsLetter(r) }) {
+		if w.words[token] {
+			verdict.Reasons = append(verdict.Reasons, ReasonAbuse)
+			break
+		}
+	}
+	if phonePattern.MatchString(text) || emailPattern.MatchString(text) {
+		verdict.Reasons = append(verdict.Reasons, ReasonContactDetails)
+	}
+	verdict.Flagged = len(verdict.Reasons) > 0
+	return verdict, nil
+}
+
+var substitutions = strings.NewReplacer("0", "o", "1", "i", "3", "e", "4", "a", "5", "s", "7", "t", "@", "a", "$", "s")
+
+// normalize lower-cases text and undoes letter substitutions in words.
+// Numbers, which have no letters, are left alone.
+func normalize(text string) string {
+	fields := strings.Fields(strings.ToLower(text))
+	for i, field := range fields {
+		if strings.IndexFunc(field, unicode.IsLetter) >= 0 {
+			fields[i] = substitutions.Replace(field)
+		}
+	}
+	return strings.Join(fields, " ")
+}
+
+// loadWords reads one word per line, skipping blank lines and lines starting
+// with #.
+func loadWords(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var words []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		words = append(words, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read word list: %w", err)
+	}
+	return words, nil
+}
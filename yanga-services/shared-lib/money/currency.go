@@ -0,0 +1,45 @@
+package money
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+var ErrInvalidCurrency = errors.New("currency must be a 3-letter ISO 4217 code")
+
+var currencyPattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// Currency is an ISO 4217 currency code such as KES.
+type Currency string
+
+// minorDigits lists the currencies whose minor unit isn't a hundredth of
+// the major unit. Every other currency has two decimal places.
+var minorDigits = map[Currency]int32{
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0,
+	"KRW": 0, "PYG": 0, "RWF": 0, "UGX": 0, "UYI": 0, "VND": 0, "VUV": 0,
+	"XAF": 0, "XOF": 0, "XPF": 0,
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+}
+
+// ParseCurrency normalises a currency code, rejecting anything that isn't
+// three letters.
+func ParseCurrency(code string) (Currency, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if !currencyPattern.MatchString(code) {
+		return "", ErrInvalidCurrency
+	}
+	return Currency(code), nil
+}
+
+// Digits returns how many decimal places the currency's minor unit has.
+func (c Currency) Digits() int32 {
+	if d, ok := minorDigits[c]; ok {
+		return d
+	}
+	return 2
+}
+
+func (c Currency) String() string {
+	return string(c)
+}
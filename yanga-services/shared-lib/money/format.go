@@ -0,0 +1,102 @@
+package money
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// symbols are the local signs for currencies we show. Others are shown by
+// their ISO code.
+var symbols = map[Currency]string{
+	"EUR": "€",
+	"GBP": "£",
+	"GHS": "GH₵",
+	"KES": "KSh",
+	"NGN": "₦",
+	"RWF": "FRw",
+	"TZS": "TSh",
+	"UGX": "USh",
+	"USD": "$",
+	"ZAR": "R",
+	"ZMW": "K",
+}
+
+// numberFormat is how a locale writes amounts.
+type numberFormat struct {
+	decimal     string
+	group       string
+	symbolAfter bool
+}
+
+// formats are keyed by language, or language and region where the region
+// writes amounts differently.
+var formats = map[string]numberFormat{
+	"en": {decimal: ".", group: ","},
+	"sw": {decimal: ".", group: ","},
+	"fr": {decimal: ",", group: " ", symbolAfter: true},
+	"de": {decimal: ",", group: ".", symbolAfter: true},
+	"pt": {decimal: ",", group: "."},
+}
+
+// Symbol returns the currency's local sign, or its ISO code if it has none
+// we know of.
+func (c Currency) Symbol() string {
+	if s, ok := symbols[c]; ok {
+		return s
+	}
+	return string(c)
+}
+
+// Format writes the amount for people in a locale such as "en-KE" or
+// "fr_RW", e.g. "KSh 1,250.50" or "1 250 FRw". Unknown locales are
+// written as English.
+func (m Money) Format(locale string) string {
+	f := lookupFormat(locale)
+	number := m.grouped(f)
+	symbol := m.currency.Symbol()
+
+	sign := ""
+	if m.amount < 0 {
+		sign = "-"
+	}
+	if f.symbolAfter {
+		return sign + number + " " + symbol
+	}
+	// Letter symbols such as KSh need a space before the number; signs
+	// such as $ don't.
+	if last, _ := utf8.DecodeLastRuneInString(symbol); unicode.IsLetter(last) && utf8.RuneCountInString(symbol) > 1 {
+		symbol += " "
+	}
+	return sign + symbol + number
+}
+
+// grouped writes the absolute amount with the locale's separators.
+func (m Money) grouped(f numberFormat) string {
+	whole, frac, _ := strings.Cut(strings.TrimPrefix(m.decimal(), "-"), ".")
+
+	var b strings.Builder
+	for i, r := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteString(f.group)
+		}
+		b.WriteRune(r)
+	}
+	if frac != "" {
+		b.WriteString(f.decimal)
+		b.WriteString(frac)
+	}
+	return b.String()
+}
+
+func lookupFormat(locale string) numberFormat {
+	tag := strings.ToLower(strings.ReplaceAll(locale, "_", "-"))
+	if f, ok := formats[tag]; ok {
+		return f
+	}
+	lang, _, _ := strings.Cut(tag, "-")
+	if f, ok := formats[lang]; ok {
+		return f
+	}
+	return formats["en"]
+}
@@ -0,0 +1,46 @@
+package money
+
+import "testing"
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		m      Money
+		locale string
+		want   string
+	}{
+		{New(125050, "KES"), "en-KE", "KSh 1,250.50"},
+		{New(125050, "KES"), "sw_KE", "KSh 1,250.50"},
+		{New(-500, "USD"), "en", "-$5.00"},
+		{New(1250, "RWF"), "fr_RW", "1\u202f250 FRw"},
+		{New(123456789, "EUR"), "de-DE", "1.234.567,89 €"},
+		{New(100, "XYZ"), "en", "XYZ 1.00"},
+		{New(1200, "UGX"), "zz", "USh 1,200"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.m.Format(tt.locale); got != tt.want {
+			t.Errorf("%v in %s: got %q, want %q", tt.m, tt.locale, got, tt.want)
+		}
+	}
+}
+
+func TestParseCurrency(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Currency
+		ok   bool
+	}{
+		{"KES", "KES", true},
+		{" kes ", "KES", true},
+		{"KE", "", false},
+		{"KES1", "", false},
+		{"", "", false},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseCurrency(tt.in)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("ParseCurrency(%q) = %q, %v", tt.in, got, err)
+		}
+	}
+}
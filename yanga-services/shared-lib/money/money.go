@@ -0,0 +1,197 @@
+// Package money holds amounts of money exactly, as whole minor units of a
+// currency, and converts them to and from floats, Postgres numerics and
+// display strings without drift.
+package money
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+)
+
+var (
+	ErrCurrencyMismatch = errors.New("currency mismatch")
+	ErrOverflow         = errors.New("amount out of range")
+)
+
+// Money is an amount in minor units of a currency, e.g. 12050 KES is
+// KSh 120.50 and 1200 UGX is USh 1,200. The zero value is zero in no
+// particular currency, and takes on the currency of whatever it is added
+// to.
+type Money struct {
+	amount   int64
+	currency Currency
+}
+
+// New returns an amount given in minor units.
+func New(minor int64, c Currency) Money {
+	return Money{amount: minor, currency: c}
+}
+
+// Zero returns nothing of a currency.
+func Zero(c Currency) Money {
+	return Money{currency: c}
+}
+
+// FromFloat converts an amount in major units, such as a fare from a JSON
+// request, rounding half away from zero. The float is read as the shortest
+// decimal that round-trips, so 0.285 becomes 0.29 rather than the 0.28 that
+// multiplying by 100 gives.
+func FromFloat(f float64, c Currency) Money {
+	m, err := Parse(strconv.FormatFloat(f, 'f', -1, 64), c, HalfUp)
+	if err != nil {
+		return Zero(c)
+	}
+	return m
+}
+
+// Parse reads a plain decimal amount in major units, such as "120.5".
+func Parse(s string, c Currency, mode RoundingMode) (Money, error) {
+	coef, exp, err := parseDecimal(s)
+	if err != nil {
+		return Money{}, err
+	}
+	minor := rescale(coef, exp, c.Digits(), mode)
+	if !minor.IsInt64() {
+		return Money{}, fmt.Errorf("%w: %s", ErrOverflow, s)
+	}
+	return New(minor.Int64(), c), nil
+}
+
+// Minor returns the amount in minor units.
+func (m Money) Minor() int64 {
+	return m.amount
+}
+
+func (m Money) Currency() Currency {
+	return m.currency
+}
+
+func (m Money) IsZero() bool {
+	return m.amount == 0
+}
+
+// Sign returns -1, 0 or 1 as the amount is negative, zero or positive.
+func (m Money) Sign() int {
+	switch {
+	case m.amount < 0:
+		return -1
+	case m.amount > 0:
+		return 1
+	}
+	return 0
+}
+
+// Add returns m + o. Adding different currencies is a bug in the caller,
+// so it panics rather than return an error every caller would have to
+// check.
+func (m Money) Add(o Money) Money {
+	return Money{amount: m.amount + o.amount, currency: m.common(o)}
+}
+
+// Sub returns m - o, panicking like Add on different currencies.
+func (m Money) Sub(o Money) Money {
+	return Money{amount: m.amount - o.amount, currency: m.common(o)}
+}
+
+func (m Money) Neg() Money {
+	return Money{amount: -m.amount, currency: m.currency}
+}
+
+// Mul returns m × n.
+func (m Money) Mul(n int64) Money {
+	return Money{amount: m.amount * n, currency: m.currency}
+}
+
+// MulRatio returns m × num / den rounded to a minor unit. den must be
+// positive.
+func (m Money) MulRatio(num, den int64, mode RoundingMode) Money {
+	product := new(big.Int).Mul(big.NewInt(m.amount), big.NewInt(num))
+	return Money{amount: roundQuo(product, big.NewInt(den), mode).Int64(), currency: m.currency}
+}
+
+// MulFloat returns m × f rounded to a minor unit, such as a per-km rate
+// over a distance. f is read as its shortest decimal, as in FromFloat.
+func (m Money) MulFloat(f float64, mode RoundingMode) Money {
+	coef, exp, err := parseDecimal(strconv.FormatFloat(f, 'f', -1, 64))
+	if err != nil {
+		return Zero(m.currency)
+	}
+	product := new(big.Int).Mul(big.NewInt(m.amount), coef)
+	return Money{amount: rescale(product, exp, 0, mode).Int64(), currency: m.currency}
+}
+
+// Cmp returns -1, 0 or 1 as m is less than, equal to or greater than o,
+// panicking like Add on different currencies.
+func (m Money) Cmp(o Money) int {
+	m.common(o)
+	switch {
+	case m.amount < o.amount:
+		return -1
+	case m.amount > o.amount:
+		return 1
+	}
+	return 0
+}
+
+// Max returns the larger of two amounts.
+func Max(a, b Money) Money {
+	if a.Cmp(b) >= 0 {
+		return a
+	}
+	return b
+}
+
+// Min returns the smaller of two amounts.
+func Min(a, b Money) Money {
+	if a.Cmp(b) <= 0 {
+		return a
+	}
+	return b
+}
+
+// Float64 returns the amount in major units, for JSON responses. Do
+// arithmetic on Money, not on the float.
+func (m Money) Float64() float64 {
+	f, _ := strconv.ParseFloat(m.decimal(), 64)
+	return f
+}
+
+// String formats the amount for logs, e.g. "120.50 KES".
+func (m Money) String() string {
+	if m.currency == "" {
+		return m.decimal()
+	}
+	return m.decimal() + " " + string(m.currency)
+}
+
+// decimal formats the amount in major units with every minor digit, e.g.
+// "-120.50".
+func (m Money) decimal() string {
+	digits := m.currency.Digits()
+	s := strconv.FormatInt(m.amount, 10)
+	if digits == 0 {
+		return s
+	}
+
+	sign := ""
+	if s[0] == '-' {
+		sign, s = "-", s[1:]
+	}
+	for int32(len(s)) <= digits {
+		s = "0" + s
+	}
+	cut := len(s) - int(digits)
+	return sign + s[:cut] + "." + s[cut:]
+}
+
+func (m Money) common(o Money) Currency {
+	switch {
+	case m.currency == "":
+		return o.currency
+	case o.currency == "" || o.currency == m.currency:
+		return m.currency
+	}
+	panic(fmt.Sprintf("money: %v: %s and %s", ErrCurrencyMismatch, m.currency, o.currency))
+}
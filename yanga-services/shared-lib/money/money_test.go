@@ -0,0 +1,159 @@
+package money
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in      string
+		c       Currency
+		mode    RoundingMode
+		want    int64
+		wantErr error
+	}{
+		{"120.5", "KES", HalfUp, 12050, nil},
+		{"120", "KES", HalfUp, 12000, nil},
+		{"-0.5", "KES", HalfUp, -50, nil},
+		{"1200", "UGX", HalfUp, 1200, nil},
+		{"1.2345", "KWD", HalfUp, 1235, nil},
+		{"0.125", "KES", HalfUp, 13, nil},
+		{"0.125", "KES", HalfEven, 12, nil},
+		{"0.135", "KES", HalfEven, 14, nil},
+		{"0.129", "KES", Down, 12, nil},
+		{"0.121", "KES", Up, 13, nil},
+		{"-0.121", "KES", Floor, -13, nil},
+		{"-0.129", "KES", Ceiling, -12, nil},
+		{"", "KES", HalfUp, 0, ErrInvalidAmount},
+		{"1.2.3", "KES", HalfUp, 0, ErrInvalidAmount},
+		{"1-2", "KES", HalfUp, 0, ErrInvalidAmount},
+		{"abc", "KES", HalfUp, 0, ErrInvalidAmount},
+		{"100000000000000000000", "KES", HalfUp, 0, ErrOverflow},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			m, err := Parse(tt.in, tt.c, tt.mode)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got error %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && (m.Minor() != tt.want || m.Currency() != tt.c) {
+				t.Errorf("got %d %s, want %d %s", m.Minor(), m.Currency(), tt.want, tt.c)
+			}
+		})
+	}
+}
+
+func TestFromFloat(t *testing.T) {
+	tests := []struct {
+		f    float64
+		c    Currency
+		want int64
+	}{
+		{0.285, "KES", 29},
+		{1.005, "KES", 101},
+		{-0.285, "KES", -29},
+		{120.5, "KES", 12050},
+		{1250.4, "UGX", 1250},
+		{1250.5, "UGX", 1251},
+		{0.1 + 0.2, "KES", 30},
+	}
+
+	for _, tt := range tests {
+		if got := FromFloat(tt.f, tt.c).Minor(); got != tt.want {
+			t.Errorf("FromFloat(%v, %s) = %d, want %d", tt.f, tt.c, got, tt.want)
+		}
+	}
+}
+
+func TestMulRatio(t *testing.T) {
+	tests := []struct {
+		name     string
+		amount   int64
+		num, den int64
+		mode     RoundingMode
+		want     int64
+	}{
+		{"exact", 1000, 1, 4, HalfUp, 250},
+		{"half up", 5, 1, 2, HalfUp, 3},
+		{"half even down", 5, 1, 2, HalfEven, 2},
+		{"half even up", 7, 1, 2, HalfEven, 4},
+		{"negative half up", -5, 1, 2, HalfUp, -3},
+		{"down", 10, 1, 3, Down, 3},
+		{"up", 10, 1, 3, Up, 4},
+		{"floor", -10, 1, 3, Floor, -4},
+		{"ceiling", -10, 1, 3, Ceiling, -3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := New(tt.amount, "KES").MulRatio(tt.num, tt.den, tt.mode).Minor(); got != tt.want {
+				t.Errorf("got %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMulFloat(t *testing.T) {
+	// A 35.50/km rate over 12.3km
+	if got := New(3550, "KES").MulFloat(12.3, HalfUp).Minor(); got != 43665 {
+		t.Errorf("got %d, want 43665", got)
+	}
+}
+
+func TestArithmeticCurrency(t *testing.T) {
+	tests := []struct {
+		name  string
+		a, b  Money
+		want  Money
+		panic bool
+	}{
+		{"same currency", New(100, "KES"), New(50, "KES"), New(150, "KES"), false},
+		{"zero value takes on currency", Money{}, New(50, "KES"), New(50, "KES"), false},
+		{"adding zero value keeps currency", New(100, "KES"), Money{}, New(100, "KES"), false},
+		{"different currencies", New(100, "KES"), New(50, "UGX"), Money{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if r := recover(); (r != nil) != tt.panic {
+					t.Errorf("got panic %v, want panic %v", r, tt.panic)
+				}
+			}()
+			if got := tt.a.Add(tt.b); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMinMax(t *testing.T) {
+	a, b := New(100, "KES"), New(250, "KES")
+	if got := Min(a, b); got != a {
+		t.Errorf("Min = %v, want %v", got, a)
+	}
+	if got := Max(a, b); got != b {
+		t.Errorf("Max = %v, want %v", got, b)
+	}
+}
+
+func TestString(t *testing.T) {
+	tests := []struct {
+		m    Money
+		want string
+	}{
+		{New(12050, "KES"), "120.50 KES"},
+		{New(-5, "KES"), "-0.05 KES"},
+		{New(1200, "UGX"), "1200 UGX"},
+		{New(1500, "KWD"), "1.500 KWD"},
+		{New(7, ""), "0.07"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.m.String(); got != tt.want {
+			t.Errorf("got %q, want %q", got, tt.want)
+		}
+	}
+}
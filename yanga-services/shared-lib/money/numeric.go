@@ -0,0 +1,63 @@
+package money
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var ErrInexact = errors.New("amount has more decimal places than the currency")
+
+// Numeric converts the amount to a Postgres numeric with the currency's
+// decimal places. The conversion is exact.
+func (m Money) Numeric() pgtype.Numeric {
+	return pgtype.Numeric{Int: big.NewInt(m.amount), Exp: -m.currency.Digits(), Valid: true}
+}
+
+// FromNumeric reads a Postgres numeric, rounding half to even should it
+// hold more decimal places than the currency has. Amounts written with
+// Numeric always read back exactly. NULL reads as zero.
+func FromNumeric(n pgtype.Numeric, c Currency) Money {
+	return RoundNumeric(n, c, HalfEven)
+}
+
+// RoundNumeric reads a Postgres numeric, rounding by mode to the currency's
+// minor unit. NULL, NaN and infinite values read as zero, and values
+// beyond the range of Money are clamped to it.
+func RoundNumeric(n pgtype.Numeric, c Currency, mode RoundingMode) Money {
+	if !n.Valid || n.NaN || n.InfinityModifier != pgtype.Finite || n.Int == nil {
+		return Zero(c)
+	}
+	minor := rescale(n.Int, n.Exp, c.Digits(), mode)
+	switch {
+	case minor.IsInt64():
+		return New(minor.Int64(), c)
+	case minor.Sign() < 0:
+		return New(math.MinInt64, c)
+	}
+	return New(math.MaxInt64, c)
+}
+
+// ExactNumeric reads a Postgres numeric without rounding, failing if it
+// holds a fraction of the currency's minor unit or is out of range. NULL
+// reads as zero.
+func ExactNumeric(n pgtype.Numeric, c Currency) (Money, error) {
+	if !n.Valid {
+		return Zero(c), nil
+	}
+	if n.NaN || n.InfinityModifier != pgtype.Finite || n.Int == nil {
+		return Money{}, fmt.Errorf("%w: not a finite number", ErrInvalidAmount)
+	}
+	down := rescale(n.Int, n.Exp, c.Digits(), Down)
+	up := rescale(n.Int, n.Exp, c.Digits(), Up)
+	if down.Cmp(up) != 0 {
+		return Money{}, fmt.Errorf("%w (%s has %d)", ErrInexact, c, c.Digits())
+	}
+	if !down.IsInt64() {
+		return Money{}, ErrOverflow
+	}
+	return New(down.Int64(), c), nil
+}
@@ -0,0 +1,79 @@
+package money
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func numeric(coef int64, exp int32) pgtype.Numeric {
+	return pgtype.Numeric{Int: big.NewInt(coef), Exp: exp, Valid: true}
+}
+
+func TestNumericRoundTrip(t *testing.T) {
+	for _, m := range []Money{New(12050, "KES"), New(-1, "KES"), New(1200, "UGX"), New(1234, "KWD")} {
+		if got := FromNumeric(m.Numeric(), m.Currency()); got != m {
+			t.Errorf("got %v, want %v", got, m)
+		}
+	}
+}
+
+func TestFromNumeric(t *testing.T) {
+	tests := []struct {
+		name string
+		n    pgtype.Numeric
+		c    Currency
+		want int64
+	}{
+		{"exact", numeric(12050, -2), "KES", 12050},
+		{"fewer places", numeric(1205, -1), "KES", 12050},
+		{"more places rounds half even", numeric(12345, -3), "KES", 1234},
+		{"more places rounds up", numeric(12355, -3), "KES", 1236},
+		{"no minor unit", numeric(12505, -1), "UGX", 1250},
+		{"null", pgtype.Numeric{}, "KES", 0},
+		{"nan", pgtype.Numeric{NaN: true, Valid: true}, "KES", 0},
+		{"too large", numeric(math.MaxInt64, 1), "KES", math.MaxInt64},
+		{"too small", numeric(math.MinInt64, 1), "KES", math.MinInt64},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FromNumeric(tt.n, tt.c); got.Minor() != tt.want || got.Currency() != tt.c {
+				t.Errorf("got %d %s, want %d %s", got.Minor(), got.Currency(), tt.want, tt.c)
+			}
+		})
+	}
+}
+
+func TestExactNumeric(t *testing.T) {
+	tests := []struct {
+		name    string
+		n       pgtype.Numeric
+		c       Currency
+		want    int64
+		wantErr error
+	}{
+		{"exact", numeric(12050, -2), "KES", 12050, nil},
+		{"trailing zeros", numeric(120500, -3), "KES", 12050, nil},
+		{"fraction of a cent", numeric(12055, -3), "KES", 0, ErrInexact},
+		{"fraction of a shilling", numeric(12505, -1), "UGX", 0, ErrInexact},
+		{"null", pgtype.Numeric{}, "KES", 0, nil},
+		{"nan", pgtype.Numeric{NaN: true, Valid: true}, "KES", 0, ErrInvalidAmount},
+		{"too large", numeric(math.MaxInt64, 1), "KES", 0, ErrOverflow},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ExactNumeric(tt.n, tt.c)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got error %v, want %v", err, tt.wantErr)
+			}
+			if got.Minor() != tt.want {
+				t.Errorf("got %d, want %d", got.Minor(), tt.want)
+			}
+		})
+	}
+}
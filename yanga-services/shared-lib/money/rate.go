@@ -0,0 +1,82 @@
+package money
+
+import (
+	"math/big"
+	"strconv"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// basisPointsPerUnit is how many basis points make 100%.
+const basisPointsPerUnit = 10000
+
+// Rate is a proportion in basis points, hundredths of a percent, such as a
+// commission, tax or discount rate. 2000 is 20%.
+type Rate int64
+
+// RateFromFloat converts a fraction such as 0.2, rounding half away from
+// zero to a basis point.
+func RateFromFloat(f float64) Rate {
+	return Rate(decimalBasisPoints(strconv.FormatFloat(f, 'f', -1, 64), 4))
+}
+
+// Percent converts a percentage such as 20 or 12.5, rounding half away
+// from zero to a basis point.
+func Percent(p float64) Rate {
+	return Rate(decimalBasisPoints(strconv.FormatFloat(p, 'f', -1, 64), 2))
+}
+
+// RateFromNumeric reads a fraction stored as a Postgres numeric, such as
+// 0.2000. NULL reads as zero.
+func RateFromNumeric(n pgtype.Numeric) Rate {
+	return numericBasisPoints(n, 4)
+}
+
+// PercentFromNumeric reads a percentage stored as a Postgres numeric, such
+// as 12.50. NULL reads as zero.
+func PercentFromNumeric(n pgtype.Numeric) Rate {
+	return numericBasisPoints(n, 2)
+}
+
+// Float64 returns the rate as a fraction, e.g. 0.2.
+func (r Rate) Float64() float64 {
+	return float64(r) / basisPointsPerUnit
+}
+
+// PercentFloat64 returns the rate as a percentage, e.g. 20.
+func (r Rate) PercentFloat64() float64 {
+	return float64(r) / 100
+}
+
+// Numeric converts the rate to a fraction with four decimal places.
+func (r Rate) Numeric() pgtype.Numeric {
+	return pgtype.Numeric{Int: big.NewInt(int64(r)), Exp: -4, Valid: true}
+}
+
+// PercentNumeric converts the rate to a percentage with two decimal places.
+func (r Rate) PercentNumeric() pgtype.Numeric {
+	return pgtype.Numeric{Int: big.NewInt(int64(r)), Exp: -2, Valid: true}
+}
+
+// Apply returns the rate's share of m rounded to a minor unit, such as the
+// commission on a fare.
+func (m Money) Apply(r Rate, mode RoundingMode) Money {
+	return m.MulRatio(int64(r), basisPointsPerUnit, mode)
+}
+
+// decimalBasisPoints reads a decimal string with scale places per unit,
+// 4 for fractions and 2 for percentages.
+func decimalBasisPoints(s string, scale int32) int64 {
+	coef, exp, err := parseDecimal(s)
+	if err != nil {
+		return 0
+	}
+	return rescale(coef, exp, scale, HalfUp).Int64()
+}
+
+func numericBasisPoints(n pgtype.Numeric, scale int32) Rate {
+	if !n.Valid || n.NaN || n.InfinityModifier != pgtype.Finite || n.Int == nil {
+		return 0
+	}
+	return Rate(rescale(n.Int, n.Exp, scale, HalfUp).Int64())
+}
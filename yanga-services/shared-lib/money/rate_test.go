@@ -0,0 +1,51 @@
+package money
+
+import "testing"
+
+func TestRates(t *testing.T) {
+	tests := []struct {
+		name string
+		got  Rate
+		want Rate
+	}{
+		{"fraction", RateFromFloat(0.2), 2000},
+		{"fraction rounded", RateFromFloat(0.12345), 1235},
+		{"percent", Percent(20), 2000},
+		{"fractional percent", Percent(12.5), 1250},
+		{"percent rounded", Percent(0.015), 2},
+		{"numeric fraction", RateFromNumeric(RateFromFloat(0.15).Numeric()), 1500},
+		{"numeric percent", PercentFromNumeric(Percent(7.25).PercentNumeric()), 725},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("got %d, want %d", tt.got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name   string
+		amount int64
+		rate   Rate
+		mode   RoundingMode
+		want   int64
+	}{
+		{"commission", 125050, Percent(20), HalfUp, 25010},
+		{"rounds half up", 1005, Percent(50), HalfUp, 503},
+		{"rounds down", 1005, Percent(50), Down, 502},
+		{"nothing", 125050, 0, HalfUp, 0},
+		{"all of it", 125050, Percent(100), HalfUp, 125050},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := New(tt.amount, "KES").Apply(tt.rate, tt.mode).Minor(); got != tt.want {
+				t.Errorf("got %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
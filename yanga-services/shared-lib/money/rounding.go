@@ -0,0 +1,99 @@
+package money
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// RoundingMode decides which way an amount between two minor units goes.
+type RoundingMode int
+
+const (
+	// HalfUp rounds to the nearest minor unit, ties away from zero.
+	HalfUp RoundingMode = iota
+	// HalfEven rounds to the nearest minor unit, ties to the even one.
+	HalfEven
+	// Down rounds toward zero.
+	Down
+	// Up rounds away from zero.
+	Up
+	// Floor rounds toward negative infinity.
+	Floor
+	// Ceiling rounds toward positive infinity.
+	Ceiling
+)
+
+var ErrInvalidAmount = errors.New("invalid amount")
+
+var (
+	bigOne = big.NewInt(1)
+	bigTen = big.NewInt(10)
+)
+
+// roundQuo returns num/den rounded by mode. den must be positive.
+func roundQuo(num, den *big.Int, mode RoundingMode) *big.Int {
+	q, r := new(big.Int).QuoRem(num, den, new(big.Int))
+	if r.Sign() == 0 {
+		return q
+	}
+
+	negative := num.Sign() < 0
+	half := new(big.Int).Abs(r)
+	half.Lsh(half, 1)
+	cmp := half.Cmp(den)
+
+	var away bool
+	switch mode {
+	case HalfUp:
+		away = cmp >= 0
+	case HalfEven:
+		away = cmp > 0 || (cmp == 0 && q.Bit(0) == 1)
+	case Down:
+		away = false
+	case Up:
+		away = true
+	case Floor:
+		away = negative
+	case Ceiling:
+		away = !negative
+	}
+	if away {
+		if negative {
+			q.Sub(q, bigOne)
+		} else {
+			q.Add(q, bigOne)
+		}
+	}
+	return q
+}
+
+// rescale turns the decimal coef×10^exp into a whole number of 10^-digits
+// units, rounding by mode.
+func rescale(coef *big.Int, exp, digits int32, mode RoundingMode) *big.Int {
+	shift := exp + digits
+	if shift >= 0 {
+		return new(big.Int).Mul(coef, pow10(shift))
+	}
+	return roundQuo(coef, pow10(-shift), mode)
+}
+
+func pow10(n int32) *big.Int {
+	return new(big.Int).Exp(bigTen, big.NewInt(int64(n)), nil)
+}
+
+// parseDecimal splits a plain decimal string such as "-12.345" into its
+// coefficient and exponent, here -12345 and -3.
+func parseDecimal(s string) (*big.Int, int32, error) {
+	whole, frac, _ := strings.Cut(s, ".")
+	digits := whole + frac
+	if digits == "" || digits == "-" || digits == "+" || strings.ContainsAny(digits[1:], "+-") {
+		return nil, 0, fmt.Errorf("%w: %q", ErrInvalidAmount, s)
+	}
+	coef, ok := new(big.Int).SetString(digits, 10)
+	if !ok {
+		return nil, 0, fmt.Errorf("%w: %q", ErrInvalidAmount, s)
+	}
+	return coef, -int32(len(frac)), nil
+}
@@ -0,0 +1,161 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often a memory store drops counters that have
+// expired and buckets that have filled up again.
+const sweepInterval = time.Minute
+
+// Memory keeps counters in this process. Each gateway instance counts on
+// its own, so limits are per instance.
+type Memory struct {
+	mu        sync.Mutex
+	buckets   map[string]bucket
+	counters  map[string]counter
+	locks     map[string]time.Time
+	lastSweep time.Time
+}
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	full    time.Time
+}
+
+type counter struct {
+	count   int
+	expires time.Time
+}
+
+func NewMemory() *Memory {
+	return &Memory{
+		buckets:   make(map[string]bucket),
+		counters:  make(map[string]counter),
+		locks:     make(map[string]time.Time),
+		lastSweep: time.Now(),
+	}
+}
+
+func (m *Memory) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	if err := ctx.Err(); err != nil {
+		return Result{}, err
+	}
+	if err := limit.Validate(); err != nil {
+		return Result{}, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	m.sweep(now)
+
+	capacity := float64(limit.Burst)
+	b, ok := m.buckets[key]
+	if !ok {
+		b = bucket{tokens: capacity, updated: now}
+	}
+	elapsed := float64(now.Sub(b.updated).Milliseconds())
+	b.tokens = math.Min(capacity, b.tokens+math.Max(0, elapsed)*limit.perMilli())
+	b.updated = now
+
+	allowed := b.tokens >= 1
+	if allowed {
+		b.tokens--
+	}
+	res := result(limit, allowed, b.tokens)
+	b.full = now.Add(res.ResetAfter)
+	m.buckets[key] = b
+	return res, nil
+}
+
+func (m *Memory) Increment(ctx context.Context, key string, window time.Duration) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	m.sweep(now)
+
+	c, ok := m.counters[key]
+	if !ok || !now.Before(c.expires) {
+		c = counter{expires: now.Add(window)}
+	}
+	c.count++
+	m.counters[key] = c
+	return c.count, nil
+}
+
+func (m *Memory) Lock(ctx context.Context, key string, d time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.locks[key] = time.Now().Add(d)
+	return nil
+}
+
+func (m *Memory) LockedFor(ctx context.Context, key string) (time.Duration, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	until, ok := m.locks[key]
+	if !ok {
+		return 0, nil
+	}
+	if remaining := time.Until(until); remaining > 0 {
+		return remaining, nil
+	}
+	delete(m.locks, key)
+	return 0, nil
+}
+
+func (m *Memory) Clear(ctx context.Context, keys ...string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, key := range keys {
+		delete(m.buckets, key)
+		delete(m.counters, key)
+		delete(m.locks, key)
+	}
+	return nil
+}
+
+// sweep drops what no longer affects any limit. The caller holds m.mu.
+func (m *Memory) sweep(now time.Time) {
+	if now.Sub(m.lastSweep) < sweepInterval {
+		return
+	}
+	m.lastSweep = now
+
+	for key, b := range m.buckets {
+		if !now.Before(b.full) {
+			delete(m.buckets, key)
+		}
+	}
+	for key, c := range m.counters {
+		if !now.Before(c.expires) {
+			delete(m.counters, key)
+		}
+	}
+	for key, until := range m.locks {
+		if !now.Before(until) {
+			delete(m.locks, key)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestMemoryTake(t *testing.T) {
+	tests := []struct {
+		name      string
+		limit     Limit
+		taken     int
+		wait      time.Duration
+		allowed   bool
+		remaining int
+	}{
+		{"within burst", Limit{Requests: 1, Per: time.Hour, Burst: 3}, 2, 0, true, 0},
+		{"burst spent", Limit{Requests: 1, Per: time.Hour, Burst: 3}, 3, 0, false, 0},
+		{"not refilled yet", Limit{Requests: 1, Per: time.Hour, Burst: 1}, 1, 20 * time.Millisecond, false, 0},
+		{"refilled after per", Limit{Requests: 1, Per: 50 * time.Millisecond, Burst: 1}, 1, 80 * time.Millisecond, true, 0},
+		{"refill stops at burst", Limit{Requests: 10, Per: 50 * time.Millisecond, Burst: 2}, 2, 200 * time.Millisecond, true, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			store := NewMemory()
+			for i := 0; i < tt.taken; i++ {
+				if res, err := store.Take(ctx, "key", tt.limit); err != nil || !res.Allowed {
+					t.Fatalf("take %d: allowed %v, err %v", i+1, res.Allowed, err)
+				}
+			}
+			time.Sleep(tt.wait)
+
+			res, err := store.Take(ctx, "key", tt.limit)
+			if err != nil {
+				t.Fatalf("Take: %v", err)
+			}
+			if res.Allowed != tt.allowed || res.Remaining != tt.remaining {
+				t.Errorf("got allowed %v remaining %d, want %v and %d", res.Allowed, res.Remaining, tt.allowed, tt.remaining)
+			}
+			if res.Limit != tt.limit.Burst {
+				t.Errorf("got limit %d, want %d", res.Limit, tt.limit.Burst)
+			}
+			if !res.Allowed && res.RetryAfter <= 0 {
+				t.Errorf("got retry after %v for a refused request", res.RetryAfter)
+			}
+		})
+	}
+}
+
+func TestMemoryTakeInvalidLimit(t *testing.T) {
+	_, err := NewMemory().Take(context.Background(), "key", Limit{Requests: 1, Per: time.Microsecond, Burst: 1})
+	if !errors.Is(err, ErrInvalidLimit) {
+		t.Errorf("got %v, want ErrInvalidLimit", err)
+	}
+}
+
+func TestMemoryLock(t *testing.T) {
+	tests := []struct {
+		name   string
+		lock   time.Duration
+		wait   time.Duration
+		clear  bool
+		locked bool
+	}{
+		{"locked", time.Hour, 0, false, true},
+		{"expired", 20 * time.Millisecond, 40 * time.Millisecond, false, false},
+		{"cleared", time.Hour, 0, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			store := NewMemory()
+			if err := store.Lock(ctx, "key", tt.lock); err != nil {
+				t.Fatalf("Lock: %v", err)
+			}
+			if tt.clear {
+				if err := store.Clear(ctx, "key"); err != nil {
+					t.Fatalf("Clear: %v", err)
+				}
+			}
+			time.Sleep(tt.wait)
+
+			lockedFor, err := store.LockedFor(ctx, "key")
+			if err != nil {
+				t.Fatalf("LockedFor: %v", err)
+			}
+			if (lockedFor > 0) != tt.locked {
+				t.Errorf("got locked for %v, want locked %v", lockedFor, tt.locked)
+			}
+			if lockedFor > tt.lock {
+				t.Errorf("got locked for %v, longer than the %v lock", lockedFor, tt.lock)
+			}
+		})
+	}
+}
+
+func TestMemoryLockedForUnknownKey(t *testing.T) {
+	lockedFor, err := NewMemory().LockedFor(context.Background(), "key")
+	if err != nil || lockedFor != 0 {
+		t.Errorf("got %v, %v, want 0 and no error", lockedFor, err)
+	}
+}
+
+func TestMemoryIncrement(t *testing.T) {
+	tests := []struct {
+		name   string
+		window time.Duration
+		counts int
+		wait   time.Duration
+		want   int
+	}{
+		{"counts up", time.Hour, 2, 0, 3},
+		{"starts over after the window", 20 * time.Millisecond, 2, 40 * time.Millisecond, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			store := NewMemory()
+			for i := 0; i < tt.counts; i++ {
+				if _, err := store.Increment(ctx, "key", tt.window); err != nil {
+					t.Fatalf("Increment: %v", err)
+				}
+			}
+			time.Sleep(tt.wait)
+
+			n, err := store.Increment(ctx, "key", tt.window)
+			if err != nil {
+				t.Fatalf("Increment: %v", err)
+			}
+			if n != tt.want {
+				t.Errorf("got %d, want %d", n, tt.want)
+			}
+		})
+	}
+}
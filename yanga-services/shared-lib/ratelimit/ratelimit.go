@@ -0,0 +1,202 @@
+// Package ratelimit meters requests with token buckets and counts failures
+// for lockouts. Counters live in Redis so every gateway instance shares
+// them, or in memory when Redis isn't configured or can't be reached.
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/namycodes/yanga-services/shared-lib/config"
+)
+
+var ErrInvalidLimit = errors.New("ratelimit: limits need positive requests and burst, per at least 1ms")
+
+// Limit is a token bucket holding up to Burst requests, refilled at
+// Requests every Per.
+type Limit struct {
+	Requests int
+	Per      time.Duration
+	Burst    int
+}
+
+// Validate checks that the bucket can be metered. Buckets refill by the
+// millisecond, so Per can't be any shorter.
+func (l Limit) Validate() error {
+	if l.Requests <= 0 || l.Burst <= 0 || l.Per < time.Millisecond {
+		return ErrInvalidLimit
+	}
+	return nil
+}
+
+// perMilli is how many tokens the bucket gains each millisecond.
+func (l Limit) perMilli() float64 {
+	return float64(l.Requests) / float64(l.Per.Milliseconds())
+}
+
+// Result is the state of a bucket after taking a token from it. RetryAfter
+// is how long until a token is available when the request wasn't allowed;
+// ResetAfter is how long until the bucket is full again.
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	RetryAfter time.Duration
+	ResetAfter time.Duration
+}
+
+// result works out a Result from the tokens left in a bucket.
+func result(l Limit, allowed bool, tokens float64) Result {
+	res := Result{
+		Allowed:    allowed,
+		Limit:      l.Burst,
+		Remaining:  int(tokens),
+		ResetAfter: time.Duration((float64(l.Burst) - tokens) / l.perMilli() * float64(time.Millisecond)),
+	}
+	if !allowed {
+		res.RetryAfter = time.Duration((1 - tokens) / l.perMilli() * float64(time.Millisecond))
+	}
+	return res
+}
+
+// Store keeps the counters. Implementations may call out to Redis, so
+// every method takes a context and can fail.
+type Store interface {
+	// Take takes a token from the bucket at key. It fails with
+	// ErrInvalidLimit unless limit is valid.
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+	// Increment counts an event at key and returns the count so far. The
+	// count starts over window after the first event.
+	Increment(ctx context.Context, key string, window time.Duration) (int, error)
+	// Lock locks key for d.
+	Lock(ctx context.Context, key string, d time.Duration) error
+	// LockedFor returns how much longer key is locked, or 0.
+	LockedFor(ctx context.Context, key string) (time.Duration, error)
+	// Clear removes keys, whatever they hold.
+	Clear(ctx context.Context, keys ...string) error
+}
+
+// New returns a store over Redis at REDIS_ADDR that falls back to memory
+// while Redis can't be reached, or an in-memory store when no address is
+// configured.
+func New(cfg *config.Config) Store {
+	if cfg.RedisAddr == "" {
+		log.Printf("No Redis configured, rate limits are kept in memory")
+		return NewMemory()
+	}
+	return NewFallback(NewRedis(cfg.RedisAddr, cfg.RedisPassword, cfg.RedisDB), NewMemory())
+}
+
+// fallbackRetry is how long a failed primary store is left alone before
+// it is tried again.
+const fallbackRetry = 30 * time.Second
+
+// Fallback uses a primary store, switching to a secondary one while the
+// primary is failing. Counters don't carry over between them, so limits
+// are looser while switching, never stricter.
+type Fallback struct {
+	primary   Store
+	secondary Store
+
+	mu        sync.Mutex
+	downUntil time.Time
+	down      bool
+}
+
+func NewFallback(primary, secondary Store) *Fallback {
+	return &Fallback{primary: primary, secondary: secondary}
+}
+
+func (f *Fallback) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	if err := limit.Validate(); err != nil {
+		return Result{}, err
+	}
+	if f.primaryUp() {
+		res, err := f.primary.Take(ctx, key, limit)
+		if err == nil || ctx.Err() != nil {
+			f.recovered(err)
+			return res, err
+		}
+		f.failed(err)
+	}
+	return f.secondary.Take(ctx, key, limit)
+}
+
+func (f *Fallback) Increment(ctx context.Context, key string, window time.Duration) (int, error) {
+	if f.primaryUp() {
+		n, err := f.primary.Increment(ctx, key, window)
+		if err == nil || ctx.Err() != nil {
+			f.recovered(err)
+			return n, err
+		}
+		f.failed(err)
+	}
+	return f.secondary.Increment(ctx, key, window)
+}
+
+func (f *Fallback) Lock(ctx context.Context, key string, d time.Duration) error {
+	if f.primaryUp() {
+		err := f.primary.Lock(ctx, key, d)
+		if err == nil || ctx.Err() != nil {
+			f.recovered(err)
+			return err
+		}
+		f.failed(err)
+	}
+	return f.secondary.Lock(ctx, key, d)
+}
+
+func (f *Fallback) LockedFor(ctx context.Context, key string) (time.Duration, error) {
+	if f.primaryUp() {
+		ttl, err := f.primary.LockedFor(ctx, key)
+		if err == nil || ctx.Err() != nil {
+			f.recovered(err)
+			return ttl, err
+		}
+		f.failed(err)
+	}
+	return f.secondary.LockedFor(ctx, key)
+}
+
+func (f *Fallback) Clear(ctx context.Context, keys ...string) error {
+	if f.primaryUp() {
+		err := f.primary.Clear(ctx, keys...)
+		if err == nil || ctx.Err() != nil {
+			f.recovered(err)
+			return err
+		}
+		f.failed(err)
+	}
+	return f.secondary.Clear(ctx, keys...)
+}
+
+func (f *Fallback) primaryUp() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return time.Now().After(f.downUntil)
+}
+
+func (f *Fallback) failed(err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !f.down {
+		log.Printf("Rate limit store unavailable, counting in memory: %v", err)
+	}
+	f.down = true
+	f.downUntil = time.Now().Add(fallbackRetry)
+}
+
+func (f *Fallback) recovered(err error) {
+	if err != nil {
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.down {
+		log.Printf("Rate limit store available again")
+	}
+	f.down = false
+}
@@ -0,0 +1,193 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestLimitValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		limit Limit
+		valid bool
+	}{
+		{"valid", Limit{Requests: 10, Per: time.Minute, Burst: 20}, true},
+		{"one per millisecond", Limit{Requests: 1, Per: time.Millisecond, Burst: 1}, true},
+		{"per under a millisecond", Limit{Requests: 1, Per: time.Microsecond, Burst: 1}, false},
+		{"no per", Limit{Requests: 1, Burst: 1}, false},
+		{"no burst", Limit{Requests: 10, Per: time.Minute}, false},
+		{"negative burst", Limit{Requests: 10, Per: time.Minute, Burst: -1}, false},
+		{"no requests", Limit{Per: time.Minute, Burst: 1}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.limit.Validate()
+			if tt.valid && err != nil {
+				t.Errorf("got %v, want no error", err)
+			}
+			if !tt.valid && !errors.Is(err, ErrInvalidLimit) {
+				t.Errorf("got %v, want ErrInvalidLimit", err)
+			}
+		})
+	}
+}
+
+var errStoreDown = errors.New("store down")
+
+// fakeStore counts calls and fails them all while down.
+type fakeStore struct {
+	down  bool
+	calls int
+}
+
+func (s *fakeStore) call(ctx context.Context) error {
+	s.calls++
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if s.down {
+		return errStoreDown
+	}
+	return nil
+}
+
+func (s *fakeStore) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	if err := s.call(ctx); err != nil {
+		return Result{}, err
+	}
+	return Result{Allowed: true, Limit: limit.Burst}, nil
+}
+
+func (s *fakeStore) Increment(ctx context.Context, key string, window time.Duration) (int, error) {
+	return 1, s.call(ctx)
+}
+
+func (s *fakeStore) Lock(ctx context.Context, key string, d time.Duration) error {
+	return s.call(ctx)
+}
+
+func (s *fakeStore) LockedFor(ctx context.Context, key string) (time.Duration, error) {
+	return 0, s.call(ctx)
+}
+
+func (s *fakeStore) Clear(ctx context.Context, keys ...string) error {
+	return s.call(ctx)
+}
+
+func TestFallback(t *testing.T) {
+	limit := Limit{Requests: 1, Per: time.Second, Burst: 1}
+
+	tests := []struct {
+		name string
+		// primaryDown is whether the primary fails, for each call in turn
+		primaryDown []bool
+		// retry lets fallbackRetry pass before each call after the first
+		retry         bool
+		cancelled     bool
+		limit         Limit
+		wantErr       error
+		wantPrimary   int
+		wantSecondary int
+	}{
+		{
+			name:        "primary up",
+			primaryDown: []bool{false, false},
+			limit:       limit,
+			wantPrimary: 2,
+		},
+		{
+			name:          "switches over while primary is down",
+			primaryDown:   []bool{true, false, false},
+			limit:         limit,
+			wantPrimary:   1,
+			wantSecondary: 3,
+		},
+		{
+			name:          "retries primary after fallbackRetry",
+			primaryDown:   []bool{true, true, false, false},
+			retry:         true,
+			limit:         limit,
+			wantPrimary:   4,
+			wantSecondary: 2,
+		},
+		{
+			name:        "cancelled context doesn't switch over",
+			primaryDown: []bool{false},
+			cancelled:   true,
+			limit:       limit,
+			wantErr:     context.Canceled,
+			wantPrimary: 1,
+		},
+		{
+			name:        "invalid limit doesn't switch over",
+			primaryDown: []bool{false},
+			limit:       Limit{Requests: 1, Per: time.Microsecond, Burst: 1},
+			wantErr:     ErrInvalidLimit,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if tt.cancelled {
+				cancel()
+			}
+
+			primary, secondary := &fakeStore{}, &fakeStore{}
+			f := NewFallback(primary, secondary)
+			for i, down := range tt.primaryDown {
+				if tt.retry && i > 0 {
+					f.mu.Lock()
+					f.downUntil = f.downUntil.Add(-fallbackRetry)
+					f.mu.Unlock()
+				}
+				primary.down = down
+
+				_, err := f.Take(ctx, "key", tt.limit)
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("take %d: got %v, want %v", i+1, err, tt.wantErr)
+				}
+			}
+
+			if primary.calls != tt.wantPrimary || secondary.calls != tt.wantSecondary {
+				t.Errorf("got %d primary and %d secondary calls, want %d and %d",
+					primary.calls, secondary.calls, tt.wantPrimary, tt.wantSecondary)
+			}
+			if !f.primaryUp() && tt.wantErr != nil {
+				t.Errorf("primary marked down after %v", tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFallbackMethods(t *testing.T) {
+	ctx := context.Background()
+	calls := []struct {
+		name string
+		call func(Store) error
+	}{
+		{"Increment", func(s Store) error { _, err := s.Increment(ctx, "key", time.Minute); return err }},
+		{"Lock", func(s Store) error { return s.Lock(ctx, "key", time.Minute) }},
+		{"LockedFor", func(s Store) error { _, err := s.LockedFor(ctx, "key"); return err }},
+		{"Clear", func(s Store) error { return s.Clear(ctx, "key") }},
+	}
+
+	for _, c := range calls {
+		t.Run(c.name, func(t *testing.T) {
+			primary, secondary := &fakeStore{down: true}, &fakeStore{}
+			f := NewFallback(primary, secondary)
+			for i := 0; i < 2; i++ {
+				if err := c.call(f); err != nil {
+					t.Fatalf("call %d: %v", i+1, err)
+				}
+			}
+			if primary.calls != 1 || secondary.calls != 2 {
+				t.Errorf("got %d primary and %d secondary calls, want 1 and 2", primary.calls, secondary.calls)
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// redisTimeout bounds every command, so a slow Redis fails over to memory
+// instead of holding up requests.
+const redisTimeout = 250 * time.Millisecond
+
+// takeScript refills and takes from a token bucket in one step, using
+// Redis's clock so gateway instances agree on it. It returns whether a
+// token was taken and how many are left.
+var takeScript = redis.NewScript(`
+local capacity = tonumber(ARGV[1])
+local rate = tonumber(ARGV[2])
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1])
+local ts = tonumber(state[2])
+if tokens == nil or ts == nil then
+	tokens = capacity
+	ts = now
+end
+tokens = math.min(capacity, tokens + math.max(0, now - ts) * rate)
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', tostring(now))
+redis.call('PEXPIRE', KEYS[1], math.ceil((capacity - tokens) / rate) + 1000)
+return {allowed, tostring(tokens)}
+`)
+
+// incrementScript counts an event, starting the window on the first one.
+var incrementScript = redis.NewScript(`
+local n = redis.call('INCR', KEYS[1])
+if n == 1 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[1])
+end
+return n
+`)
+
+var errMalformedReply = errors.New("redis: malformed reply")
+
+// Redis keeps counters in Redis, shared by every gateway instance. Scripts
+// are sent by their SHA once Redis has them cached.
+type Redis struct {
+	client *redis.Client
+}
+
+func NewRedis(addr, password string, db int) *Redis {
+	return &Redis{client: redis.NewClient(&redis.Options{
+		Addr:         addr,
+		Password:     password,
+		DB:           db,
+		DialTimeout:  redisTimeout,
+		ReadTimeout:  redisTimeout,
+		WriteTimeout: redisTimeout,
+		// Failing over is quicker than retrying
+		MaxRetries: -1,
+	})}
+}
+
+func (s *Redis) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	if err := limit.Validate(); err != nil {
+		return Result{}, err
+	}
+	items, err := takeScript.Run(ctx, s.client, []string{key},
+		limit.Burst, strconv.FormatFloat(limit.perMilli(), 'f', -1, 64)).Slice()
+	if err != nil {
+		return Result{}, err
+	}
+
+	if len(items) != 2 {
+		return Result{}, errMalformedReply
+	}
+	allowed, ok := items[0].(int64)
+	if !ok {
+		return Result{}, errMalformedReply
+	}
+	tokensText, ok := items[1].(string)
+	if !ok {
+		return Result{}, errMalformedReply
+	}
+	tokens, err := strconv.ParseFloat(tokensText, 64)
+	if err != nil {
+		return Result{}, errMalformedReply
+	}
+	return result(limit, allowed == 1, tokens), nil
+}
+
+func (s *Redis) Increment(ctx context.Context, key string, window time.Duration) (int, error) {
+	return incrementScript.Run(ctx, s.client, []string{key}, window.Milliseconds()).Int()
+}
+
+func (s *Redis) Lock(ctx context.Context, key string, d time.Duration) error {
+	return s.client.Set(ctx, key, 1, d).Err()
+}
+
+func (s *Redis) LockedFor(ctx context.Context, key string) (time.Duration, error) {
+	ttl, err := s.client.PTTL(ctx, key).Result()
+	if err != nil {
+		return 0, err
+	}
+	// Negative when there's no lock, or a lock without an expiry, which
+	// Lock never sets
+	if ttl <= 0 {
+		return 0, nil
+	}
+	return ttl, nil
+}
+
+func (s *Redis) Clear(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	return s.client.Del(ctx, keys...).Err()
+}
@@ -0,0 +1,246 @@
+package routing
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"math"
+	"time"
+)
+
+// gridCellDegrees sizes the spatial index used to snap points to the
+// nearest road node, roughly 1.1 km at the equator.
+const gridCellDegrees = 0.01
+
+// Graph is a directed road network. Edges are weighted by travel time.
+type Graph struct {
+	nodes    []Point
+	edges    [][]edge
+	grid     map[gridCell][]int32
+	maxSpeed float64 // km/h, the fastest edge, bounding the A* heuristic
+}
+
+type edge struct {
+	to       int32
+	distance float64 // km
+	seconds  float64
+}
+
+type gridCell struct {
+	lat int32
+	lng int32
+}
+
+func NewGraph() *Graph {
+	return &Graph{grid: make(map[gridCell][]int32)}
+}
+
+func (g *Graph) NodeCount() int {
+	return len(g.nodes)
+}
+
+func (g *Graph) EdgeCount() int {
+	count := 0
+	for _, out := range g.edges {
+		count += len(out)
+	}
+	return count
+}
+
+// AddNode adds a road node and returns its index.
+func (g *Graph) AddNode(p Point) int32 {
+	id := int32(len(g.nodes))
+	g.nodes = append(g.nodes, p)
+	g.edges = append(g.edges, nil)
+	cell := cellOf(p)
+	g.grid[cell] = append(g.grid[cell], id)
+	return id
+}
+
+// AddEdge adds a one-way road segment driven at speedKmh.
+func (g *Graph) AddEdge(from, to int32, speedKmh float64) {
+	distance := Distance(g.nodes[from], g.nodes[to])
+	g.edges[from] = append(g.edges[from], edge{
+		to:       to,
+		distance: distance,
+		seconds:  distance / speedKmh * 3600,
+	})
+	g.maxSpeed = math.Max(g.maxSpeed, speedKmh)
+}
+
+// nearest returns the road node closest to p within maxKm.
+func (g *Graph) nearest(p Point, maxKm float64) (int32, float64, bool) {
+	// Longitude cells narrow away from the equator, so search enough of
+	// them to cover maxKm east and west.
+	latRings := int(math.Ceil(maxKm / (111.32 * gridCellDegrees)))
+	lngRings := int(math.Ceil(maxKm / (111.32 * gridCellDegrees * math.Max(math.Cos(p.Lat*math.Pi/180), 0.01))))
+	center := cellOf(p)
+
+	best, bestDistance := int32(-1), math.Inf(1)
+	for dLat := -latRings; dLat <= latRings; dLat++ {
+		for dLng := -lngRings; dLng <= lngRings; dLng++ {
+			cell := gridCell{lat: center.lat + int32(dLat), lng: center.lng + int32(dLng)}
+			for _, id := range g.grid[cell] {
+				if d := Distance(p, g.nodes[id]); d < bestDistance {
+					best, bestDistance = id, d
+				}
+			}
+		}
+	}
+	if best < 0 || bestDistance > maxKm {
+		return 0, 0, false
+	}
+	return best, bestDistance, true
+}
+
+// shortestPath runs A* from one node to another, minimising travel time.
+func (g *Graph) shortestPath(ctx context.Context, from, to int32) ([]int32, float64, float64, error) {
+	if from == to {
+		return []int32{from}, 0, 0, nil
+	}
+
+	target := g.nodes[to]
+	heuristic := func(id int32) float64 {
+		return Distance(g.nodes[id], target) / g.maxSpeed * 3600
+	}
+
+	seconds := map[int32]float64{from: 0}
+	distance := map[int32]float64{from: 0}
+	prev := make(map[int32]int32)
+	closed := make(map[int32]bool)
+	open := &nodeQueue{{id: from, priority: heuristic(from)}}
+
+	for steps := 0; open.Len() > 0; steps++ {
+		if steps%1024 == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, 0, 0, err
+			}
+		}
+
+		current := heap.Pop(open).(queuedNode).id
+		if current == to {
+			break
+		}
+		if closed[current] {
+			continue
+		}
+		closed[current] = true
+
+		for _, e := range g.edges[current] {
+			if closed[e.to] {
+				continue
+			}
+			cost := seconds[current] + e.seconds
+			if known, ok := seconds[e.to]; ok && cost >= known {
+				continue
+			}
+			seconds[e.to] = cost
+			distance[e.to] = distance[current] + e.distance
+			prev[e.to] = current
+			heap.Push(open, queuedNode{id: e.to, priority: cost + heuristic(e.to)})
+		}
+	}
+
+	if _, ok := seconds[to]; !ok {
+		return nil, 0, 0, ErrNoRoute
+	}
+
+	path := []int32{to}
+	for id := to; id != from; {
+		id = prev[id]
+		path = append(path, id)
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path, distance[to], seconds[to], nil
+}
+
+func cellOf(p Point) gridCell {
+	return gridCell{
+		lat: int32(math.Floor(p.Lat / gridCellDegrees)),
+		lng: int32(math.Floor(p.Lng / gridCellDegrees)),
+	}
+}
+
+type queuedNode struct {
+	id       int32
+	priority float64
+}
+
+type nodeQueue []queuedNode
+
+func (q nodeQueue) Len() int           { return len(q) }
+func (q nodeQueue) Less(i, j int) bool { return q[i].priority < q[j].priority }
+func (q nodeQueue) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+func (q *nodeQueue) Push(x any)        { *q = append(*q, x.(queuedNode)) }
+func (q *nodeQueue) Pop() any {
+	old := *q
+	n := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return n
+}
+
+// GraphRouter routes over a road graph. Points are snapped to the nearest
+// road node within maxSnapKm; points off the graph, or with no road between
+// them, are routed by the fallback.
+type GraphRouter struct {
+	graph     *Graph
+	fallback  Router
+	maxSnapKm float64
+}
+
+func NewGraphRouter(graph *Graph, fallback Router, maxSnapKm float64) *GraphRouter {
+	return &GraphRouter{
+		graph:     graph,
+		fallback:  fallback,
+		maxSnapKm: maxSnapKm,
+	}
+}
+
+func (r *GraphRouter) Route(ctx context.Context, from, to Point) (Route, error) {
+	src, srcGap, ok := r.graph.nearest(from, r.maxSnapKm)
+	if !ok {
+		return r.fallback.Route(ctx, from, to)
+	}
+	dst, dstGap, ok := r.graph.nearest(to, r.maxSnapKm)
+	if !ok {
+		return r.fallback.Route(ctx, from, to)
+	}
+
+	nodes, distance, seconds, err := r.graph.shortestPath(ctx, src, dst)
+	if errors.Is(err, ErrNoRoute) {
+		return r.fallback.Route(ctx, from, to)
+	}
+	if err != nil {
+		return Route{}, err
+	}
+
+	path := make([]Point, 0, len(nodes)+2)
+	if srcGap > 0 {
+		path = append(path, from)
+	}
+	for _, id := range nodes {
+		path = append(path, r.graph.nodes[id])
+	}
+	if dstGap > 0 {
+		path = append(path, to)
+	}
+
+	// The stretches to and from the road are short; price them at the
+	// fallback's straight-line estimate.
+	head, err := r.fallback.Route(ctx, from, r.graph.nodes[src])
+	if err != nil {
+		return Route{}, err
+	}
+	tail, err := r.fallback.Route(ctx, r.graph.nodes[dst], to)
+	if err != nil {
+		return Route{}, err
+	}
+
+	return Route{
+		Distance: distance + srcGap + dstGap,
+		Duration: time.Duration(seconds*float64(time.Second)) + head.Duration + tail.Duration,
+		Path:     path,
+	}, nil
+}
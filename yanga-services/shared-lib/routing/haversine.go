@@ -0,0 +1,67 @@
+package routing
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/namycodes/yanga-services/shared-lib/utils"
+)
+
+// HaversineRouter treats every route as a straight line driven at a flat
+// average speed. It is the fallback when no road graph is available.
+type HaversineRouter struct {
+	speedKmh float64
+}
+
+func NewHaversineRouter(speedKmh float64) *HaversineRouter {
+	if speedKmh <= 0 {
+		speedKmh = 30
+	}
+	return &HaversineRouter{speedKmh: speedKmh}
+}
+
+func (r *HaversineRouter) Route(ctx context.Context, from, to Point) (Route, error) {
+	if err := ctx.Err(); err != nil {
+		return Route{}, err
+	}
+	distance := Distance(from, to)
+	return Route{
+		Distance: distance,
+		Duration: travelTime(distance, r.speedKmh),
+		Path:     []Point{from, to},
+	}, nil
+}
+
+// Distance returns the great-circle distance between two points in km.
+func Distance(a, b Point) float64 {
+	return utils.CalculateDistance(a.Lat, a.Lng, b.Lat, b.Lng)
+}
+
+// DistanceToPath returns how far a point is from the nearest part of a
+// path, in km. Over the short segments of a road route the earth is flat
+// enough to project each one onto a plane.
+func DistanceToPath(p Point, path []Point) float64 {
+	if len(path) == 0 {
+		return math.Inf(1)
+	}
+	nearest := Distance(p, path[0])
+	for i := 1; i < len(path); i++ {
+		a, b := path[i-1], path[i]
+		scale := math.Cos(p.Lat * math.Pi / 180)
+		ax, ay := (a.Lng-p.Lng)*scale, a.Lat-p.Lat
+		bx, by := (b.Lng-p.Lng)*scale, b.Lat-p.Lat
+		dx, dy := bx-ax, by-ay
+		t := 0.0
+		if length := dx*dx + dy*dy; length > 0 {
+			t = math.Max(0, math.Min(1, -(ax*dx+ay*dy)/length))
+		}
+		closest := Point{Lat: a.Lat + t*(b.Lat-a.Lat), Lng: a.Lng + t*(b.Lng-a.Lng)}
+		nearest = math.Min(nearest, Distance(p, closest))
+	}
+	return nearest
+}
+
+func travelTime(distanceKm, speedKmh float64) time.Duration {
+	return time.Duration(distanceKm / speedKmh * float64(time.Hour))
+}
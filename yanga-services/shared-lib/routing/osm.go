@@ -0,0 +1,193 @@
+package routing
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Default speeds in km/h by OSM highway class, used when a way has no
+// usable maxspeed tag. Classes not listed here aren't drivable.
+var highwaySpeeds = map[string]float64{
+	"motorway":       100,
+	"motorway_link":  60,
+	"trunk":          80,
+	"trunk_link":     50,
+	"primary":        60,
+	"primary_link":   40,
+	"secondary":      50,
+	"secondary_link": 35,
+	"tertiary":       40,
+	"tertiary_link":  30,
+	"unclassified":   30,
+	"residential":    25,
+	"living_street":  10,
+	"service":        15,
+	"road":           30,
+}
+
+type osmTag struct {
+	Key   string `xml:"k,attr"`
+	Value string `xml:"v,attr"`
+}
+
+type osmNode struct {
+	ID  int64   `xml:"id,attr"`
+	Lat float64 `xml:"lat,attr"`
+	Lon float64 `xml:"lon,attr"`
+}
+
+type osmWay struct {
+	Refs []struct {
+		Ref int64 `xml:"ref,attr"`
+	} `xml:"nd"`
+	Tags []osmTag `xml:"tag"`
+}
+
+// LoadOSM builds a road graph from an OpenStreetMap XML extract, such as
+// one converted from a .pbf download with `osmium cat extract.osm.pbf -o
+// extract.osm`.
+func LoadOSM(path string) (*Graph, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadOSM(f)
+}
+
+// ReadOSM builds a road graph from OpenStreetMap XML.
+func ReadOSM(r io.Reader) (*Graph, error) {
+	coords := make(map[int64]Point)
+	var ways []osmWay
+
+	decoder := xml.NewDecoder(r)
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read OSM data: %w", err)
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		switch start.Name.Local {
+		case "node":
+			var node osmNode
+			if err := decoder.DecodeElement(&node, &start); err != nil {
+				return nil, fmt.Errorf("failed to read OSM node: %w", err)
+			}
+			coords[node.ID] = Point{Lat: node.Lat, Lng: node.Lon}
+		case "way":
+			var way osmWay
+			if err := decoder.DecodeElement(&way, &start); err != nil {
+				return nil, fmt.Errorf("failed to read OSM way: %w", err)
+			}
+			if _, drivable := wayProfile(way.Tags); drivable {
+				ways = append(ways, way)
+			}
+		}
+	}
+
+	graph := NewGraph()
+	ids := make(map[int64]int32)
+	nodeID := func(ref int64) (int32, bool) {
+		if id, ok := ids[ref]; ok {
+			return id, true
+		}
+		p, ok := coords[ref]
+		if !ok {
+			// Extracts cut ways at their boundary.
+			return 0, false
+		}
+		id := graph.AddNode(p)
+		ids[ref] = id
+		return id, true
+	}
+
+	for _, way := range ways {
+		profile, _ := wayProfile(way.Tags)
+		for i := 1; i < len(way.Refs); i++ {
+			a, okA := nodeID(way.Refs[i-1].Ref)
+			b, okB := nodeID(way.Refs[i].Ref)
+			if !okA || !okB {
+				continue
+			}
+			if profile.forward {
+				graph.AddEdge(a, b, profile.speed)
+			}
+			if profile.backward {
+				graph.AddEdge(b, a, profile.speed)
+			}
+		}
+	}
+
+	if graph.NodeCount() == 0 {
+		return nil, fmt.Errorf("no drivable roads found")
+	}
+	return graph, nil
+}
+
+type wayTraffic struct {
+	speed    float64
+	forward  bool
+	backward bool
+}
+
+// wayProfile reads how a way can be driven from its tags.
+func wayProfile(tags []osmTag) (wayTraffic, bool) {
+	values := make(map[string]string, len(tags))
+	for _, tag := range tags {
+		values[tag.Key] = tag.Value
+	}
+
+	highway := values["highway"]
+	speed, ok := highwaySpeeds[highway]
+	if !ok {
+		return wayTraffic{}, false
+	}
+	for _, key := range []string{"access", "motor_vehicle", "motorcar"} {
+		if v := values[key]; v == "no" || v == "private" {
+			return wayTraffic{}, false
+		}
+	}
+	if maxSpeed, ok := parseMaxSpeed(values["maxspeed"]); ok {
+		speed = maxSpeed
+	}
+
+	traffic := wayTraffic{speed: speed, forward: true, backward: true}
+	switch values["oneway"] {
+	case "yes", "true", "1":
+		traffic.backward = false
+	case "-1", "reverse":
+		traffic.forward = false
+	case "no", "false", "0":
+	default:
+		if highway == "motorway" || values["junction"] == "roundabout" {
+			traffic.backward = false
+		}
+	}
+	return traffic, true
+}
+
+// parseMaxSpeed reads a maxspeed tag such as "50" or "30 mph".
+func parseMaxSpeed(value string) (float64, bool) {
+	value = strings.TrimSpace(value)
+	factor := 1.0
+	if strings.HasSuffix(value, "mph") {
+		factor = 1.609
+		value = strings.TrimSpace(strings.TrimSuffix(value, "mph"))
+	}
+	speed, err := strconv.ParseFloat(value, 64)
+	if err != nil || speed <= 0 {
+		return 0, false
+	}
+	return speed * factor, true
+}
@@ -0,0 +1,75 @@
+package routing
+
+import (
+	"errors"
+	"math"
+	"strings"
+)
+
+var ErrInvalidPolyline = errors.New("invalid polyline")
+
+// EncodePolyline encodes a path in Google's polyline format with five
+// decimal places, as understood by map SDKs.
+func EncodePolyline(path []Point) string {
+	var b strings.Builder
+	var prevLat, prevLng int64
+	for _, p := range path {
+		lat := int64(math.Round(p.Lat * 1e5))
+		lng := int64(math.Round(p.Lng * 1e5))
+		encodeSigned(&b, lat-prevLat)
+		encodeSigned(&b, lng-prevLng)
+		prevLat, prevLng = lat, lng
+	}
+	return b.String()
+}
+
+func encodeSigned(b *strings.Builder, v int64) {
+	u := v << 1
+	if v < 0 {
+		u = ^u
+	}
+	for u >= 0x20 {
+		b.WriteByte(byte((0x20 | (u & 0x1f)) + 63))
+		u >>= 5
+	}
+	b.WriteByte(byte(u + 63))
+}
+
+// DecodePolyline decodes a path encoded by EncodePolyline.
+func DecodePolyline(encoded string) ([]Point, error) {
+	var path []Point
+	var lat, lng int64
+	for i := 0; i < len(encoded); {
+		dLat, n, err := decodeSigned(encoded[i:])
+		if err != nil {
+			return nil, err
+		}
+		i += n
+		dLng, n, err := decodeSigned(encoded[i:])
+		if err != nil {
+			return nil, err
+		}
+		i += n
+		lat, lng = lat+dLat, lng+dLng
+		path = append(path, Point{Lat: float64(lat) / 1e5, Lng: float64(lng) / 1e5})
+	}
+	return path, nil
+}
+
+func decodeSigned(s string) (int64, int, error) {
+	var u int64
+	for i, shift := 0, uint(0); i < len(s) && shift < 64; i, shift = i+1, shift+5 {
+		c := int64(s[i]) - 63
+		if c < 0 || c > 0x3f {
+			return 0, 0, ErrInvalidPolyline
+		}
+		u |= (c & 0x1f) << shift
+		if c < 0x20 {
+			if u&1 != 0 {
+				return ^(u >> 1), i + 1, nil
+			}
+			return u >> 1, i + 1, nil
+		}
+	}
+	return 0, 0, ErrInvalidPolyline
+}
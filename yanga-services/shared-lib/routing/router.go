@@ -0,0 +1,82 @@
+// Package routing finds routes between points over the road network:
+// distance, travel time and the path to draw on a map.
+package routing
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math"
+	"time"
+
+	"github.com/namycodes/yanga-services/shared-lib/config"
+)
+
+var ErrNoRoute = errors.New("no route between points")
+
+type Point struct {
+	Lat float64
+	Lng float64
+}
+
+type Route struct {
+	Distance float64 // km
+	Duration time.Duration
+	Path     []Point
+}
+
+// Minutes returns the route's duration rounded up to whole minutes.
+func (r Route) Minutes() int {
+	return int(math.Ceil(r.Duration.Minutes()))
+}
+
+// Polyline returns the route's path in Google's encoded polyline format.
+func (r Route) Polyline() string {
+	return EncodePolyline(r.Path)
+}
+
+// Router finds the route a vehicle would drive between two points.
+type Router interface {
+	Route(ctx context.Context, from, to Point) (Route, error)
+}
+
+// New returns a router over the OpenStreetMap extract configured in
+// ROUTING_GRAPH_PATH. Without a graph, or if it can't be loaded, routes are
+// straight lines at ROUTING_FALLBACK_SPEED_KMH.
+func New(cfg *config.Config) Router {
+	fallback := NewHaversineRouter(float64(cfg.RoutingFallbackSpeedKmh))
+	if cfg.RoutingGraphPath == "" {
+		log.Printf("No routing graph configured, using straight-line routing")
+		return fallback
+	}
+
+	graph, err := LoadOSM(cfg.RoutingGraphPath)
+	if err != nil {
+		log.Printf("Failed to load routing graph %s, using straight-line routing: %v", cfg.RoutingGraphPath, err)
+		return fallback
+	}
+	log.Printf("Loaded routing graph %s: %d nodes, %d edges", cfg.RoutingGraphPath, graph.NodeCount(), graph.EdgeCount())
+	return NewGraphRouter(graph, fallback, float64(cfg.RoutingMaxSnapMeters)/1000)
+}
+
+// RouteVia routes through points in order and joins the legs into one
+// route.
+func RouteVia(ctx context.Context, router Router, points []Point) (Route, error) {
+	var route Route
+	if len(points) > 0 {
+		route.Path = []Point{points[0]}
+	}
+	for i := 1; i < len(points); i++ {
+		leg, err := router.Route(ctx, points[i-1], points[i])
+		if err != nil {
+			return Route{}, err
+		}
+		route.Distance += leg.Distance
+		route.Duration += leg.Duration
+		if len(leg.Path) > 0 {
+			// Each leg starts where the previous one ended.
+			route.Path = append(route.Path, leg.Path[1:]...)
+		}
+	}
+	return route, nil
+}
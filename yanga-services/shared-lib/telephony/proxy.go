@@ -0,0 +1,80 @@
+// Package telephony lets riders and drivers call each other without either
+// seeing the other's phone number, through a proxy number they both call
+// instead.
+package telephony
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/namycodes/yanga-services/shared-lib/config"
+)
+
+var ErrUnavailable = errors.New("masked calling is not available")
+
+// Session pairs two phone numbers behind a proxy number until it expires.
+// A call from either number to the proxy number is put through to the
+// other, showing the proxy number as the caller.
+type Session struct {
+	ID          string
+	ProxyNumber string
+	ExpiresAt   time.Time
+}
+
+// ProxyProvider opens and closes sessions with a telephony provider.
+// Implementations usually call out to the provider, so both can fail.
+type ProxyProvider interface {
+	OpenSession(ctx context.Context, a, b string, expiresAt time.Time) (Session, error)
+	CloseSession(ctx context.Context, id string) error
+}
+
+// New returns a stub provider over CALL_PROXY_NUMBER. Without one, masked
+// calling is disabled and every session fails with ErrUnavailable.
+func New(cfg *config.Config) ProxyProvider {
+	if cfg.CallProxyNumber == "" {
+		log.Printf("No proxy number configured, masked calling is disabled")
+		return disabled{}
+	}
+	return NewStub(cfg.CallProxyNumber)
+}
+
+// Stub stands in for a provider in development. Every session gets the
+// same proxy number and nothing is actually connected.
+type Stub struct {
+	number string
+}
+
+func NewStub(number string) *Stub {
+	return &Stub{number: number}
+}
+
+func (s *Stub) OpenSession(ctx context.Context, a, b string, expiresAt time.Time) (Session, error) {
+	if err := ctx.Err(); err != nil {
+		return Session{}, err
+	}
+	session := Session{
+		ID:          uuid.NewString(),
+		ProxyNumber: s.number,
+		ExpiresAt:   expiresAt,
+	}
+	log.Printf("[proxy] opened session %s on %s until %s", session.ID, s.number, expiresAt.Format(time.RFC3339))
+	return session, nil
+}
+
+func (s *Stub) CloseSession(ctx context.Context, id string) error {
+	log.Printf("[proxy] closed session %s", id)
+	return nil
+}
+
+type disabled struct{}
+
+func (disabled) OpenSession(ctx context.Context, a, b string, expiresAt time.Time) (Session, error) {
+	return Session{}, ErrUnavailable
+}
+
+func (disabled) CloseSession(ctx context.Context, id string) error {
+	return nil
+}
@@ -0,0 +1,43 @@
+package utils
+
+import (
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// ToPgUUID converts a uuid.UUID into a valid pgtype.UUID
+func ToPgUUID(id uuid.UUID) pgtype.UUID {
+	pgUUID := pgtype.UUID{}
+	copy(pgUUID.Bytes[:], id[:])
+	pgUUID.Valid = true
+	return pgUUID
+}
+
+// FromPgUUID converts a pgtype.UUID into a uuid.UUID, returning uuid.Nil for NULL
+func FromPgUUID(id pgtype.UUID) uuid.UUID {
+	if !id.Valid {
+		return uuid.Nil
+	}
+	return uuid.UUID(id.Bytes)
+}
+
+// Float64ToNumeric converts a float64 into a pgtype.Numeric.
+// pgtype.Numeric.Scan only accepts strings, so the value is formatted first.
+func Float64ToNumeric(f float64) pgtype.Numeric {
+	var n pgtype.Numeric
+	if err := n.Scan(strconv.FormatFloat(f, 'f', -1, 64)); err != nil {
+		return pgtype.Numeric{}
+	}
+	return n
+}
+
+// NumericToFloat64 converts a pgtype.Numeric into a float64, returning 0 for NULL
+func NumericToFloat64(n pgtype.Numeric) float64 {
+	f, err := n.Float64Value()
+	if err != nil || !f.Valid {
+		return 0
+	}
+	return f.Float64
+}